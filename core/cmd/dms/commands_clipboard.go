@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
@@ -23,13 +24,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/mattn/go-isatty"
 	bolt "go.etcd.io/bbolt"
 	_ "golang.org/x/image/bmp"
 	_ "golang.org/x/image/tiff"
 	_ "golang.org/x/image/webp"
+	"golang.org/x/sys/unix"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	clipboardserver "github.com/AvengeMedia/DankMaterialShell/core/internal/server/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
 	"github.com/spf13/cobra"
 )
@@ -186,6 +190,34 @@ var clipMigrateCmd = &cobra.Command{
 
 var clipMigrateDelete bool
 
+var clipRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt clipboard history",
+	Long: `Re-encrypt clipboard history with a new key, or switch how it is encrypted (requires server).
+
+Modes:
+  keyring     key stored in the Secret Service (gnome-keyring, KeePassXC, ...)
+  passphrase  key derived from a passphrase; history stays locked until 'dms cl unlock'
+  none        store history unencrypted
+
+Examples:
+  dms cl rekey --mode keyring
+  dms cl rekey                  # new key, same mode
+  dms cl rekey --mode none`,
+	Args: cobra.NoArgs,
+	Run:  runClipRekey,
+}
+
+var clipRekeyMode string
+
+var clipUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock encrypted clipboard history",
+	Long:  "Unlock clipboard history encrypted with a passphrase, or retry the keyring (requires server).",
+	Args:  cobra.NoArgs,
+	Run:   runClipUnlock,
+}
+
 func init() {
 	clipCopyCmd.Flags().BoolVarP(&clipCopyForeground, "foreground", "f", false, "Stay in foreground instead of forking")
 	clipCopyCmd.Flags().BoolVarP(&clipCopyPasteOnce, "paste-once", "o", false, "Exit after first paste")
@@ -216,8 +248,10 @@ func init() {
 
 	clipMigrateCmd.Flags().BoolVar(&clipMigrateDelete, "delete", false, "Delete cliphist db after successful migration")
 
+	clipRekeyCmd.Flags().StringVar(&clipRekeyMode, "mode", "", "Encryption mode: keyring, passphrase or none (default: current mode)")
+
 	clipConfigCmd.AddCommand(clipConfigGetCmd, clipConfigSetCmd)
-	clipboardCmd.AddCommand(clipCopyCmd, clipPasteCmd, clipWatchCmd, clipHistoryCmd, clipGetCmd, clipDeleteCmd, clipClearCmd, clipSearchCmd, clipConfigCmd, clipExportCmd, clipImportCmd, clipMigrateCmd)
+	clipboardCmd.AddCommand(clipCopyCmd, clipPasteCmd, clipWatchCmd, clipHistoryCmd, clipGetCmd, clipDeleteCmd, clipClearCmd, clipSearchCmd, clipConfigCmd, clipExportCmd, clipImportCmd, clipMigrateCmd, clipRekeyCmd, clipUnlockCmd)
 }
 
 func runClipCopy(cmd *cobra.Command, args []string) {
@@ -333,8 +367,8 @@ func runClipWatch(cmd *cobra.Command, args []string) {
 			log.Fatalf("Watch error: %v", err)
 		}
 	case clipWatchStore:
-		if err := clipboard.Watch(ctx, func(data []byte, mimeType string) {
-			if err := clipboard.Store(data, mimeType); err != nil {
+		if err := clipboard.WatchAll(ctx, func(data []byte, mimeType string, allMimes []string) {
+			if err := clipboardserver.StoreOffline(data, mimeType, allMimes); err != nil {
 				log.Errorf("store: %v", err)
 			}
 		}); err != nil && err != context.Canceled {
@@ -804,7 +838,7 @@ func runClipImport(cmd *cobra.Command, args []string) {
 			entryData = []byte(dataStr)
 		}
 
-		if err := clipboard.Store(entryData, mimeType); err != nil {
+		if err := clipboardserver.StoreOffline(entryData, mimeType, nil); err != nil {
 			log.Errorf("Failed to store entry: %v", err)
 			continue
 		}
@@ -847,7 +881,7 @@ func runClipMigrate(cmd *cobra.Command, args []string) {
 			}
 
 			mimeType := detectMimeType(v)
-			if err := clipboard.Store(v, mimeType); err != nil {
+			if err := clipboardserver.StoreOffline(v, mimeType, nil); err != nil {
 				log.Errorf("Failed to store entry %d: %v", btoi(k), err)
 				continue
 			}
@@ -1002,3 +1036,147 @@ func copyFileToClipboard(filePath string) error {
 	}
 	return nil
 }
+
+func getClipEncryption() (mode string, locked bool) {
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "clipboard.getEncryption"})
+	if err != nil {
+		log.Fatalf("Failed to get encryption status: %v", err)
+	}
+	if resp.Error != "" {
+		log.Fatalf("Error: %s", resp.Error)
+	}
+	if resp.Result == nil {
+		log.Fatal("No encryption status returned")
+	}
+
+	status, ok := (*resp.Result).(map[string]any)
+	if !ok {
+		log.Fatal("Invalid response format")
+	}
+	mode, _ = status["mode"].(string)
+	locked, _ = status["locked"].(bool)
+	return mode, locked
+}
+
+func unlockClipHistory(mode string) {
+	params := map[string]any{}
+	if mode == "passphrase" {
+		passphrase, err := readPassphrase("Current passphrase: ")
+		if err != nil {
+			log.Fatalf("Failed to read passphrase: %v", err)
+		}
+		params["passphrase"] = passphrase
+	}
+
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "clipboard.unlock", Params: params})
+	if err != nil {
+		log.Fatalf("Failed to unlock clipboard history: %v", err)
+	}
+	if resp.Error != "" {
+		log.Fatalf("Error: %s", resp.Error)
+	}
+}
+
+func runClipUnlock(cmd *cobra.Command, args []string) {
+	mode, locked := getClipEncryption()
+	if !locked {
+		fmt.Println("Clipboard history is not locked")
+		return
+	}
+
+	unlockClipHistory(mode)
+	fmt.Println("Clipboard history unlocked")
+}
+
+func runClipRekey(cmd *cobra.Command, args []string) {
+	current, locked := getClipEncryption()
+
+	mode := clipRekeyMode
+	if mode == "" {
+		mode = current
+	}
+	switch mode {
+	case "keyring", "passphrase":
+	case "none":
+		if current == "none" {
+			fmt.Println("Clipboard history is not encrypted")
+			return
+		}
+	default:
+		log.Fatalf("Unknown mode %q (use keyring, passphrase or none)", mode)
+	}
+
+	if locked {
+		unlockClipHistory(current)
+	}
+
+	params := map[string]any{"mode": mode}
+	if mode == "passphrase" {
+		passphrase, err := readPassphrase("New passphrase: ")
+		if err != nil {
+			log.Fatalf("Failed to read passphrase: %v", err)
+		}
+		if passphrase == "" {
+			log.Fatal("Passphrase must not be empty")
+		}
+		if isatty.IsTerminal(os.Stdin.Fd()) {
+			confirm, err := readPassphrase("Confirm passphrase: ")
+			if err != nil {
+				log.Fatalf("Failed to read passphrase: %v", err)
+			}
+			if confirm != passphrase {
+				log.Fatal("Passphrases do not match")
+			}
+		}
+		params["passphrase"] = passphrase
+	}
+
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "clipboard.rekey", Params: params})
+	if err != nil {
+		log.Fatalf("Failed to rekey clipboard history: %v", err)
+	}
+	if resp.Error != "" {
+		log.Fatalf("Error: %s", resp.Error)
+	}
+
+	switch mode {
+	case "none":
+		fmt.Println("Clipboard history decrypted")
+	default:
+		fmt.Printf("Clipboard history re-encrypted (%s)\n", mode)
+	}
+}
+
+var passphraseReader = bufio.NewReader(os.Stdin)
+
+// readPassphrase reads a line from stdin, with echo turned off when stdin
+// is a terminal. Piped input is read as-is for scripting.
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		line, err := passphraseReader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return "", err
+	}
+	noEcho := *termios
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ECHONL
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &noEcho); err != nil {
+		return "", err
+	}
+	defer unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := passphraseReader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	colorHistoryLimit   int
+	colorHistoryJSON    bool
+	colorExportFormat   string
+	colorExportOutput   string
+	colorExportName     string
+	colorContrastJSON   bool
+	colorContrastRecord bool
+)
+
+var colorHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List previously picked colors",
+	Long: `List previously picked colors, most recent first.
+
+Colors can be referenced in other commands by their position in this list,
+their hex value or their name.
+
+Examples:
+  dms color history                          # List all picked colors
+  dms color history -n 10                    # Only the last 10
+  dms color history name 1 accent            # Name the most recent color
+  dms color history remove '#ff0000'         # Remove a color
+  dms color history export --format gpl      # Export as a GIMP palette
+  dms color history export --format css -o colors.css`,
+	Args: cobra.NoArgs,
+	Run:  runColorHistory,
+}
+
+var colorHistoryNameCmd = &cobra.Command{
+	Use:   "name <ref> <name>",
+	Short: "Name a color in the history",
+	Long:  "Name a color in the history. An empty name clears it. <ref> is a list position, hex value or current name.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateColorHistory(func(h *colorpicker.History) error {
+			return h.SetName(args[0], args[1])
+		})
+	},
+}
+
+var colorHistoryRemoveCmd = &cobra.Command{
+	Use:   "remove <ref>...",
+	Short: "Remove colors from the history",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateColorHistory(func(h *colorpicker.History) error {
+			// Resolve everything first so positions don't shift between removals.
+			hexes := make([]string, 0, len(args))
+			for _, ref := range args {
+				i, err := h.Find(ref)
+				if err != nil {
+					return err
+				}
+				hexes = append(hexes, h.Entries[i].Hex)
+			}
+			for _, hex := range hexes {
+				if err := h.Remove(hex); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var colorHistoryClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the color history",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		updateColorHistory(func(h *colorpicker.History) error {
+			h.Entries = nil
+			return nil
+		})
+	},
+}
+
+var colorHistoryExportCmd = &cobra.Command{
+	Use:   "export [ref...]",
+	Short: "Export the color history as a palette",
+	Long: `Export the color history, or just the given colors, as a palette.
+
+Formats:
+  gpl  - GIMP/Inkscape palette (default)
+  css  - CSS custom properties on :root, named after the color names
+  json - JSON with name, hex and RGB values`,
+	Run: runColorHistoryExport,
+}
+
+var colorContrastCmd = &cobra.Command{
+	Use:   "contrast [foreground] [background]",
+	Short: "Check the contrast between two colors",
+	Long: `Check the contrast between a foreground and a background color.
+
+Reports the WCAG 2 contrast ratio with AA/AAA results, and the APCA
+lightness contrast (Lc) with the kind of content it is sufficient for.
+
+Colors are hex values or history references. Missing colors are picked
+from the screen: first the foreground, then the background.
+
+Examples:
+  dms color contrast '#ffffff' '#1e1e2e'     # Compare two hex colors
+  dms color contrast 1 2                     # Compare the last two picks
+  dms color contrast '#ffffff'               # Pick the background
+  dms color contrast                         # Pick both colors`,
+	Args: cobra.MaximumNArgs(2),
+	Run:  runColorContrast,
+}
+
+func init() {
+	colorHistoryCmd.Flags().IntVarP(&colorHistoryLimit, "limit", "n", 0, "Maximum number of colors to list (0 for all)")
+	colorHistoryCmd.Flags().BoolVar(&colorHistoryJSON, "json", false, "Output as JSON")
+
+	colorHistoryExportCmd.Flags().StringVarP(&colorExportFormat, "format", "f", "gpl", "Palette format: gpl, css, json")
+	colorHistoryExportCmd.Flags().StringVarP(&colorExportOutput, "output", "o", "", "Write to a file instead of stdout")
+	colorHistoryExportCmd.Flags().StringVar(&colorExportName, "name", "DankMaterialShell", "Palette name")
+
+	colorContrastCmd.Flags().BoolVar(&colorContrastJSON, "json", false, "Output as JSON")
+	colorContrastCmd.Flags().BoolVar(&colorContrastRecord, "history", false, "Record picked colors in the history")
+
+	colorHistoryCmd.AddCommand(colorHistoryNameCmd, colorHistoryRemoveCmd, colorHistoryClearCmd, colorHistoryExportCmd)
+	colorCmd.AddCommand(colorHistoryCmd, colorContrastCmd)
+}
+
+func updateColorHistory(fn func(h *colorpicker.History) error) {
+	if err := colorpicker.UpdateHistory(fn); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func loadColorHistory() *colorpicker.History {
+	h, err := colorpicker.LoadHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return h
+}
+
+func runColorHistory(cmd *cobra.Command, args []string) {
+	entries := loadColorHistory().Entries
+	if colorHistoryLimit > 0 && len(entries) > colorHistoryLimit {
+		entries = entries[:colorHistoryLimit]
+	}
+
+	if colorHistoryJSON {
+		data, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No colors picked yet")
+		return
+	}
+
+	for i, e := range entries {
+		c := e.Color()
+		fmt.Printf("%3d  \033[48;2;%d;%d;%dm    \033[0m  %s", i+1, c.R, c.G, c.B, e.Hex)
+		if e.Name != "" {
+			fmt.Printf("  %s", e.Name)
+		}
+		fmt.Println()
+	}
+}
+
+func runColorHistoryExport(cmd *cobra.Command, args []string) {
+	format, err := colorpicker.ParseExportFormat(colorExportFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	h := loadColorHistory()
+	entries := h.Entries
+	if len(args) > 0 {
+		entries = make([]colorpicker.HistoryEntry, 0, len(args))
+		for _, ref := range args {
+			i, err := h.Find(ref)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			entries = append(entries, h.Entries[i])
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if colorExportOutput != "" {
+		f, err := os.Create(colorExportOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := colorpicker.Export(w, entries, format, colorExportName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if colorExportOutput != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d colors to %s\n", len(entries), colorExportOutput)
+	}
+}
+
+func runColorContrast(cmd *cobra.Command, args []string) {
+	colors := make([]colorpicker.Color, 0, 2)
+	for _, ref := range args {
+		c, err := colorpicker.ResolveColor(ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		colors = append(colors, c)
+	}
+
+	var picked []colorpicker.Color
+	for len(colors) < 2 {
+		c, err := colorpicker.New(colorpicker.Config{Format: colorpicker.FormatHex}).Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if c == nil {
+			os.Exit(0)
+		}
+		colors = append(colors, *c)
+		picked = append(picked, *c)
+	}
+
+	if colorContrastRecord && len(picked) > 0 {
+		if err := colorpicker.AddHistory(picked...); err != nil {
+			fmt.Fprintln(os.Stderr, "color history:", err)
+		}
+	}
+
+	result := colorpicker.CheckContrast(colors[0], colors[1])
+	if colorContrastJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fg, bg := colors[0], colors[1]
+	fmt.Printf("\033[48;2;%d;%d;%dm\033[38;2;%d;%d;%dm  Sample text %s on %s  \033[0m\n\n",
+		bg.R, bg.G, bg.B, fg.R, fg.G, fg.B, result.Foreground, result.Background)
+	fmt.Printf("WCAG 2 ratio:  %.2f:1\n", result.Ratio)
+	fmt.Printf("  AA   normal: %s   large: %s\n", passFail(result.AA), passFail(result.AALarge))
+	fmt.Printf("  AAA  normal: %s   large: %s\n", passFail(result.AAA), passFail(result.AAALarge))
+	fmt.Printf("APCA Lc:       %.1f (%s)\n", result.Lc, result.APCA)
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "fail"
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	colorNameSources []string
+	colorNameCount   int
+	colorNameJSON    bool
+)
+
+var colorNameCmd = &cobra.Command{
+	Use:   "name [color...]",
+	Short: "Find the closest named colors",
+	Long: `Find the closest named colors using the CIEDE2000 color difference.
+
+Colors are hex values or history references. Without arguments a color is
+picked from the screen.
+
+Sources:
+  css      - CSS named colors
+  tailwind - Tailwind CSS default palette
+  theme    - Current matugen and dank16 palette from dms-colors.json
+
+A deltaE below 1 is not noticeable; 2-3 is a close match.
+
+Examples:
+  dms color name '#3b82f5'                   # Closest name from each source
+  dms color name -s tailwind -n 5 1          # Top 5 Tailwind matches for the last pick`,
+	Run: runColorName,
+}
+
+func init() {
+	colorNameCmd.Flags().StringSliceVarP(&colorNameSources, "source", "s", colorpicker.AllSources, "Palettes to search: css, tailwind, theme")
+	colorNameCmd.Flags().IntVarP(&colorNameCount, "count", "n", 1, "Matches to show per source")
+	colorNameCmd.Flags().BoolVar(&colorNameJSON, "json", false, "Output as JSON")
+
+	colorCmd.AddCommand(colorNameCmd)
+}
+
+func runColorName(cmd *cobra.Command, args []string) {
+	var colors []colorpicker.Color
+	for _, ref := range args {
+		c, err := colorpicker.ResolveColor(ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		colors = append(colors, c)
+	}
+	if len(colors) == 0 {
+		c, err := colorpicker.New(colorpicker.Config{Format: colorpicker.FormatHex}).Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if c == nil {
+			os.Exit(0)
+		}
+		colors = append(colors, *c)
+	}
+
+	if _, err := colorpicker.NamedColors(colorNameSources...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if colorNameJSON {
+		type result struct {
+			Hex     string                   `json:"hex"`
+			Matches []colorpicker.ColorMatch `json:"matches"`
+		}
+		results := make([]result, 0, len(colors))
+		for _, c := range colors {
+			results = append(results, result{Hex: c.ToHex(false), Matches: nearestColorNames(c, colorNameSources, colorNameCount)})
+		}
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	for _, c := range colors {
+		printColorSwatch(c, c.ToHex(false))
+		printColorNames(c, colorNameSources, colorNameCount, false)
+	}
+}
+
+// nearestColorNames searches each source separately so one large palette
+// can't crowd out the others.
+func nearestColorNames(c colorpicker.Color, sources []string, n int) []colorpicker.ColorMatch {
+	var matches []colorpicker.ColorMatch
+	for _, source := range sources {
+		palette, err := colorpicker.NamedColors(source)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, colorpicker.NearestNamed(c, palette, n)...)
+	}
+	return matches
+}
+
+func printColorNames(c colorpicker.Color, sources []string, n int, raw bool) {
+	for _, m := range nearestColorNames(c, sources, n) {
+		mc, _ := colorpicker.ParseHex(m.Hex)
+		swatch := ""
+		if !raw {
+			swatch = fmt.Sprintf("\033[48;2;%d;%d;%dm  \033[0m ", mc.R, mc.G, mc.B)
+		}
+		fmt.Printf("  %s%-9s %s %s (ΔE %.2f)\n", swatch, m.Source+":", strings.ToLower(m.Hex), m.Name, m.DeltaE)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
@@ -14,8 +15,32 @@ var (
 	colorAutocopy  bool
 	colorNotify    bool
 	colorLowercase bool
+	colorMulti     bool
+	colorNoHistory bool
+	colorCSS       bool
+	colorShowName  bool
 )
 
+// colorFormatFlags maps the pick output flags to formats, in help order.
+var colorFormatFlags = []struct {
+	flag   string
+	format colorpicker.OutputFormat
+	usage  string
+}{
+	{"hex", colorpicker.FormatHex, "Output as hexadecimal (#RRGGBB)"},
+	{"rgb", colorpicker.FormatRGB, "Output as RGB (R G B)"},
+	{"hsl", colorpicker.FormatHSL, "Output as HSL (H S% L%)"},
+	{"hsv", colorpicker.FormatHSV, "Output as HSV (H S% V%)"},
+	{"cmyk", colorpicker.FormatCMYK, "Output as CMYK (C% M% Y% K%)"},
+	{"oklch", colorpicker.FormatOKLCH, "Output as OKLCH (L% C H)"},
+	{"oklab", colorpicker.FormatOKLab, "Output as OKLab (L% a b)"},
+	{"lab", colorpicker.FormatLab, "Output as CIE Lab, D50 (L a b)"},
+	{"lch", colorpicker.FormatLCh, "Output as CIE LCh, D50 (L C H)"},
+	{"hwb", colorpicker.FormatHWB, "Output as HWB (H W% B%)"},
+	{"p3", colorpicker.FormatP3, "Output as Display P3 (R G B, 0-1)"},
+	{"linear", colorpicker.FormatLinear, "Output as linear sRGB (R G B, 0-1)"},
+}
+
 var colorCmd = &cobra.Command{
 	Use:   "color",
 	Short: "Color utilities",
@@ -29,39 +54,59 @@ var colorPickCmd = &cobra.Command{
 
 Click on any pixel to capture its color, or press Escape to cancel.
 
+With --multi, every click adds a color to the swatch strip in the top-left
+corner. Press Enter, Escape or right-click to finish; each color is printed
+on its own line. Picked colors are recorded in the history unless
+--no-history is given.
+
 Output format flags (mutually exclusive, default: --hex):
-  --hex  - Hexadecimal (#RRGGBB)
-  --rgb  - RGB values (R G B)
-  --hsl  - HSL values (H S% L%)
-  --hsv  - HSV values (H S% V%)
-  --cmyk - CMYK values (C% M% Y% K%)
-  --json - JSON with all formats
+  --hex    - Hexadecimal (#RRGGBB)
+  --rgb    - RGB values (R G B)
+  --hsl    - HSL values (H S% L%)
+  --hsv    - HSV values (H S% V%)
+  --cmyk   - CMYK values (C% M% Y% K%)
+  --oklch  - OKLCH values (L% C H)
+  --oklab  - OKLab values (L% a b)
+  --lab    - CIE Lab values, D50 white point as in CSS (L a b)
+  --lch    - CIE LCh values, D50 white point as in CSS (L C H)
+  --hwb    - HWB values (H W% B%)
+  --p3     - Display P3 values (R G B, 0-1)
+  --linear - Linear sRGB values (R G B, 0-1)
+  --json   - JSON with all formats
 
 Optional:
-  --raw - Removes ANSI escape codes and background colors. Use this when piping to other commands
+  --css  - Use CSS function syntax, e.g. oklch(62.8% 0.2576 29.23)
+  --name - Also print the closest CSS, Tailwind and theme color names
+  --raw  - Removes ANSI escape codes and background colors. Use this when piping to other commands
 
 Examples:
   dms color pick                # Pick color, output as hex
   dms color pick --rgb          # Output as RGB
+  dms color pick --oklch --css  # Output as oklch(...)
   dms color pick --json         # Output all formats as JSON
   dms color pick --hex -l       # Output hex in lowercase
-  dms color pick -a             # Auto-copy result to clipboard`,
+  dms color pick -a             # Auto-copy result to clipboard
+  dms color pick --multi        # Pick several colors in one session`,
 	Run: runColorPick,
 }
 
 func init() {
-	colorPickCmd.Flags().Bool("hex", false, "Output as hexadecimal (#RRGGBB)")
-	colorPickCmd.Flags().Bool("rgb", false, "Output as RGB (R G B)")
-	colorPickCmd.Flags().Bool("hsl", false, "Output as HSL (H S% L%)")
-	colorPickCmd.Flags().Bool("hsv", false, "Output as HSV (H S% V%)")
-	colorPickCmd.Flags().Bool("cmyk", false, "Output as CMYK (C% M% Y% K%)")
+	formatFlags := make([]string, 0, len(colorFormatFlags)+1)
+	for _, f := range colorFormatFlags {
+		colorPickCmd.Flags().Bool(f.flag, false, f.usage)
+		formatFlags = append(formatFlags, f.flag)
+	}
 	colorPickCmd.Flags().Bool("json", false, "Output all formats as JSON")
 	colorPickCmd.Flags().Bool("raw", false, "Removes ANSI escape codes and background colors. Use this when piping to other commands")
 	colorPickCmd.Flags().StringVarP(&colorOutputFmt, "output-format", "o", "", "Custom output format template")
 	colorPickCmd.Flags().BoolVarP(&colorAutocopy, "autocopy", "a", false, "Copy result to clipboard")
 	colorPickCmd.Flags().BoolVarP(&colorLowercase, "lowercase", "l", false, "Output hex in lowercase")
+	colorPickCmd.Flags().BoolVarP(&colorMulti, "multi", "m", false, "Pick several colors until Enter, Escape or right-click")
+	colorPickCmd.Flags().BoolVar(&colorNoHistory, "no-history", false, "Don't record picked colors in the history")
+	colorPickCmd.Flags().BoolVar(&colorCSS, "css", false, "Use CSS function syntax")
+	colorPickCmd.Flags().BoolVar(&colorShowName, "name", false, "Also print the closest named colors")
 
-	colorPickCmd.MarkFlagsMutuallyExclusive("hex", "rgb", "hsl", "hsv", "cmyk", "json")
+	colorPickCmd.MarkFlagsMutuallyExclusive(append(formatFlags, "json")...)
 
 	colorCmd.AddCommand(colorPickCmd)
 }
@@ -70,14 +115,10 @@ func runColorPick(cmd *cobra.Command, args []string) {
 	format := colorpicker.FormatHex // default
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	if rgb, _ := cmd.Flags().GetBool("rgb"); rgb {
-		format = colorpicker.FormatRGB
-	} else if hsl, _ := cmd.Flags().GetBool("hsl"); hsl {
-		format = colorpicker.FormatHSL
-	} else if hsv, _ := cmd.Flags().GetBool("hsv"); hsv {
-		format = colorpicker.FormatHSV
-	} else if cmyk, _ := cmd.Flags().GetBool("cmyk"); cmyk {
-		format = colorpicker.FormatCMYK
+	for _, f := range colorFormatFlags {
+		if set, _ := cmd.Flags().GetBool(f.flag); set {
+			format = f.format
+		}
 	}
 
 	config := colorpicker.Config{
@@ -89,46 +130,74 @@ func runColorPick(cmd *cobra.Command, args []string) {
 	}
 
 	picker := colorpicker.New(config)
-	color, err := picker.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-
-	if color == nil {
-		os.Exit(0)
-	}
-
-	var output string
-	if jsonOutput {
-		jsonStr, err := color.ToJSON()
+	var colors []colorpicker.Color
+	if colorMulti {
+		picked, err := picker.RunMulti()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		output = jsonStr
+		colors = picked
 	} else {
-		output = color.Format(config.Format, config.Lowercase, config.CustomFormat)
+		color, err := picker.Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if color != nil {
+			colors = append(colors, *color)
+		}
 	}
 
-	if colorAutocopy {
-		copyToClipboard(output)
+	if len(colors) == 0 {
+		os.Exit(0)
 	}
 
-	if jsonOutput {
-		fmt.Println(output)
-		return
+	if !colorNoHistory {
+		if err := colorpicker.AddHistory(colors...); err != nil {
+			fmt.Fprintln(os.Stderr, "color history:", err)
+		}
 	}
 
-	if raw, _ := cmd.Flags().GetBool("raw"); raw {
-		fmt.Printf("%s\n", output)
-		return
+	raw, _ := cmd.Flags().GetBool("raw")
+	outputs := make([]string, 0, len(colors))
+	for _, color := range colors {
+		var output string
+		if jsonOutput {
+			jsonStr, err := color.ToJSON()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			output = jsonStr
+		} else if colorCSS && config.CustomFormat == "" {
+			output = color.CSS(config.Format, config.Lowercase)
+		} else {
+			output = color.Format(config.Format, config.Lowercase, config.CustomFormat)
+		}
+		outputs = append(outputs, output)
+
+		if jsonOutput || raw {
+			fmt.Println(output)
+		} else {
+			printColorSwatch(color, output)
+		}
+		if colorShowName && !jsonOutput {
+			printColorNames(color, colorpicker.AllSources, 1, raw)
+		}
+	}
+
+	if colorAutocopy {
+		copyToClipboard(strings.Join(outputs, "\n"))
 	}
+}
 
+// printColorSwatch prints text on a background of the color itself.
+func printColorSwatch(color colorpicker.Color, text string) {
 	if color.IsDark() {
-		fmt.Printf("\033[48;2;%d;%d;%dm\033[97m %s \033[0m\n", color.R, color.G, color.B, output)
+		fmt.Printf("\033[48;2;%d;%d;%dm\033[97m %s \033[0m\n", color.R, color.G, color.B, text)
 	} else {
-		fmt.Printf("\033[48;2;%d;%d;%dm\033[30m %s \033[0m\n", color.R, color.G, color.B, output)
+		fmt.Printf("\033[48;2;%d;%d;%dm\033[30m %s \033[0m\n", color.R, color.G, color.B, text)
 	}
 }
 
@@ -528,6 +528,7 @@ func getCommonCommands() []*cobra.Command {
 		setupCmd,
 		colorCmd,
 		screenshotCmd,
+		recordCmd,
 		notifyActionCmd,
 		notifyCmd,
 		genericNotifyActionCmd,
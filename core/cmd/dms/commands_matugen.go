@@ -17,7 +17,11 @@ import (
 var matugenCmd = &cobra.Command{
 	Use:   "matugen",
 	Short: "Generate Material Design themes",
-	Long:  "Generate Material Design themes using matugen with dank16 color integration",
+	Long: `Generate Material Design themes with dank16 color integration.
+
+The native backend builds the Material You scheme and renders the matugen
+templates in-process, so the matugen binary is not needed. Use
+--backend matugen to run the external matugen instead.`,
 }
 
 var matugenGenerateCmd = &cobra.Command{
@@ -35,7 +39,13 @@ var matugenQueueCmd = &cobra.Command{
 var matugenCheckCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check which template apps are detected",
-	Run:   runMatugenCheck,
+	Long: `Check which template apps are detected.
+
+Besides the built-in templates, the result includes templates registered
+through JSON manifests in ~/.config/matugen/dms/templates/ and in the
+matugen/ directory of installed plugins. These carry a "source" field with
+the manifest path.`,
+	Run: runMatugenCheck,
 }
 
 func init() {
@@ -51,7 +61,8 @@ func init() {
 		cmd.Flags().String("value", "", "Wallpaper path or hex color")
 		cmd.Flags().String("mode", "dark", "Color mode: dark or light")
 		cmd.Flags().String("icon-theme", "System Default", "Icon theme name")
-		cmd.Flags().String("matugen-type", "scheme-tonal-spot", "Matugen scheme type")
+		cmd.Flags().String("matugen-type", "scheme-tonal-spot", "Scheme type: scheme-tonal-spot, -vibrant, -expressive, -fidelity, -content, -monochrome, -neutral, -rainbow, -fruit-salad")
+		cmd.Flags().String("backend", "", "Generator: native or matugen (default: matugen when installed, else native)")
 		cmd.Flags().Bool("run-user-templates", true, "Run user matugen templates")
 		cmd.Flags().String("stock-colors", "", "Stock theme colors JSON")
 		cmd.Flags().Bool("sync-mode-with-portal", false, "Sync color scheme with GNOME portal")
@@ -62,6 +73,7 @@ func init() {
 
 	matugenQueueCmd.Flags().Bool("wait", true, "Wait for completion")
 	matugenQueueCmd.Flags().Duration("timeout", 90*time.Second, "Timeout for waiting")
+	matugenCheckCmd.Flags().String("config-dir", "", "User config directory")
 }
 
 func buildMatugenOptions(cmd *cobra.Command) matugen.Options {
@@ -79,6 +91,12 @@ func buildMatugenOptions(cmd *cobra.Command) matugen.Options {
 	terminalsAlwaysDark, _ := cmd.Flags().GetBool("terminals-always-dark")
 	skipTemplates, _ := cmd.Flags().GetString("skip-templates")
 	contrast, _ := cmd.Flags().GetFloat64("contrast")
+	backendName, _ := cmd.Flags().GetString("backend")
+
+	backend, err := matugen.ParseBackend(backendName)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	return matugen.Options{
 		StateDir:            stateDir,
@@ -89,6 +107,7 @@ func buildMatugenOptions(cmd *cobra.Command) matugen.Options {
 		Mode:                matugen.ColorMode(mode),
 		IconTheme:           iconTheme,
 		MatugenType:         matugenType,
+		Backend:             backend,
 		Contrast:            contrast,
 		RunUserTemplates:    runUserTemplates,
 		StockColors:         stockColors,
@@ -126,6 +145,7 @@ func runMatugenQueue(cmd *cobra.Command, args []string) {
 			"mode":                opts.Mode,
 			"iconTheme":           opts.IconTheme,
 			"matugenType":         opts.MatugenType,
+			"backend":             opts.Backend,
 			"runUserTemplates":    opts.RunUserTemplates,
 			"stockColors":         opts.StockColors,
 			"syncModeWithPortal":  opts.SyncModeWithPortal,
@@ -193,7 +213,8 @@ func runMatugenQueue(cmd *cobra.Command, args []string) {
 }
 
 func runMatugenCheck(cmd *cobra.Command, args []string) {
-	checks := matugen.CheckTemplates(nil)
+	configDir, _ := cmd.Flags().GetString("config-dir")
+	checks := matugen.CheckTemplates(nil, configDir)
 	data, err := json.Marshal(checks)
 	if err != nil {
 		log.Fatalf("Failed to marshal check results: %v", err)
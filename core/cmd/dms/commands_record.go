@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recOutputName string
+	recCursor     string
+	recFormat     string
+	recFPS        int
+	recOutputDir  string
+	recFilename   string
+	recDuration   time.Duration
+	recReset      bool
+	recToggle     bool
+	recForeground bool
+	recJSON       bool
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the screen",
+	Long: `Record the screen to an animated image or video.
+
+Recordings run inside the DMS server so the bar can show an indicator and
+'dms record stop' can end them. Without a running server, or with
+--foreground, the recording runs in this process until Ctrl+C.
+
+Modes:
+  region      - Select a region interactively (default)
+  full        - Record the focused output
+  output      - Record a specific output by name
+  window      - Record the focused window's area
+  last        - Record the last selected region
+
+Formats (--format):
+  auto        - mp4 when ffmpeg is installed, otherwise gif (default)
+  gif, apng   - Encoded natively
+  mp4, webm, mkv - Encoded by piping frames to ffmpeg
+
+Examples:
+  dms record                         # Select a region and start recording
+  dms record stop                    # Stop and print the file path
+  dms record --toggle                # Start, or stop if already recording
+  dms record full -f gif --fps 10    # Focused output as a 10 fps GIF
+  dms record output DP-1 -d 30s      # Record DP-1 for 30 seconds
+  dms record status                  # Show whether a recording is running`,
+}
+
+var recRegionCmd = &cobra.Command{
+	Use:   "region",
+	Short: "Record a region selected interactively",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeRegion) },
+}
+
+var recFullCmd = &cobra.Command{
+	Use:   "full",
+	Short: "Record the focused output",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeFullScreen) },
+}
+
+var recOutputCmd = &cobra.Command{
+	Use:   "output [name]",
+	Short: "Record a specific output",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if recOutputName == "" && len(args) > 0 {
+			recOutputName = args[0]
+		}
+		if recOutputName == "" {
+			fmt.Fprintln(os.Stderr, "Error: output name required (use -o or provide as argument)")
+			os.Exit(1)
+		}
+		runRecord(screenshot.ModeOutput)
+	},
+}
+
+var recWindowCmd = &cobra.Command{
+	Use:   "window",
+	Short: "Record the focused window's area",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeWindow) },
+}
+
+var recLastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Record the last selected region",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeLastRegion) },
+}
+
+var recStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running recording",
+	Run:   runRecordStop,
+}
+
+var recStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show recording state",
+	Run:   runRecordStatus,
+}
+
+func init() {
+	recordCmd.PersistentFlags().StringVarP(&recOutputName, "output", "o", "", "Output name for 'output' mode")
+	recordCmd.PersistentFlags().StringVar(&recCursor, "cursor", "on", "Include cursor in recording (on/off)")
+	recordCmd.PersistentFlags().StringVarP(&recFormat, "format", "f", "auto", "Format (auto, gif, apng, mp4, webm, mkv)")
+	recordCmd.PersistentFlags().IntVar(&recFPS, "fps", 0, "Frames per second (default 15 for gif/apng, 30 for video)")
+	recordCmd.PersistentFlags().StringVar(&recOutputDir, "dir", "", "Output directory")
+	recordCmd.PersistentFlags().StringVar(&recFilename, "filename", "", "Output filename (auto-generated if empty)")
+	recordCmd.PersistentFlags().DurationVarP(&recDuration, "duration", "d", 0, "Stop automatically after this long (e.g. 30s)")
+	recordCmd.PersistentFlags().BoolVar(&recReset, "reset", false, "Reset saved last-region preselection before selecting")
+	recordCmd.PersistentFlags().BoolVar(&recToggle, "toggle", false, "Stop the running recording instead of starting a new one")
+	recordCmd.PersistentFlags().BoolVar(&recForeground, "foreground", false, "Record in this process until interrupted")
+	recStatusCmd.Flags().BoolVar(&recJSON, "json", false, "Output as JSON")
+
+	recordCmd.AddCommand(recRegionCmd, recFullCmd, recOutputCmd, recWindowCmd, recLastCmd, recStopCmd, recStatusCmd)
+	recordCmd.Run = func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeRegion) }
+}
+
+func recordPath(format screenshot.RecordFormat) string {
+	if recOutputDir == "" && recFilename == "" {
+		return ""
+	}
+	dir := recOutputDir
+	if dir == "" {
+		dir = screenshot.GetRecordingDir()
+	}
+	name := recFilename
+	if name == "" {
+		name = screenshot.GenerateRecordingFilename(screenshot.ResolveRecordFormat(format))
+	}
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		return filepath.Join(dir, name)
+	}
+	return path
+}
+
+func recordParams(mode string, format screenshot.RecordFormat) map[string]any {
+	params := map[string]any{
+		"mode":   mode,
+		"format": recFormat,
+		"cursor": !strings.EqualFold(recCursor, "off"),
+		"reset":  recReset,
+	}
+	if recOutputName != "" {
+		params["output"] = recOutputName
+	}
+	if recFPS > 0 {
+		params["fps"] = recFPS
+	}
+	if path := recordPath(format); path != "" {
+		params["path"] = path
+	}
+	if recDuration > 0 {
+		params["duration"] = recDuration.Seconds()
+	}
+	return params
+}
+
+func recordModeName(mode screenshot.Mode) string {
+	switch mode {
+	case screenshot.ModeLastRegion:
+		return "last"
+	case screenshot.ModeFullScreen:
+		return "full"
+	case screenshot.ModeOutput:
+		return "output"
+	case screenshot.ModeWindow:
+		return "window"
+	default:
+		return "region"
+	}
+}
+
+func runRecord(mode screenshot.Mode) {
+	format, err := screenshot.ParseRecordFormat(recFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if recForeground {
+		runRecordForeground(mode, format)
+		return
+	}
+
+	method := "screenrecord.start"
+	if recToggle {
+		method = "screenrecord.toggle"
+	}
+
+	resp, err := sendServerRequest(models.Request{
+		ID:     1,
+		Method: method,
+		Params: recordParams(recordModeName(mode), format),
+	})
+	if err != nil {
+		if recToggle {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runRecordForeground(mode, format)
+		return
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	state := decodeRecordState(resp.Result)
+	switch {
+	case state["recording"] == true:
+		fmt.Printf("Recording to %v\n", state["path"])
+	case state["lastRecording"] != nil:
+		last, _ := state["lastRecording"].(map[string]any)
+		fmt.Println(last["path"])
+	}
+}
+
+func runRecordForeground(mode screenshot.Mode, format screenshot.RecordFormat) {
+	cfg := screenshot.DefaultRecordConfig()
+	cfg.Mode = mode
+	cfg.OutputName = recOutputName
+	if strings.EqualFold(recCursor, "off") {
+		cfg.Cursor = screenshot.CursorOff
+	}
+	cfg.Format = format
+	cfg.FPS = recFPS
+	cfg.Path = recordPath(format)
+	cfg.MaxDuration = recDuration
+	cfg.Reset = recReset
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := screenshot.NewRecorder(cfg).Run(ctx, func(rec screenshot.Recording) {
+		fmt.Fprintf(os.Stderr, "Recording to %s (Ctrl+C to stop)\n", rec.Path)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if result == nil {
+		os.Exit(0)
+	}
+	fmt.Println(result.Path)
+}
+
+func runRecordStop(cmd *cobra.Command, args []string) {
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "screenrecord.stop"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	result := decodeRecordState(resp.Result)
+	fmt.Println(result["path"])
+}
+
+func runRecordStatus(cmd *cobra.Command, args []string) {
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "screenrecord.getState"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	state := decodeRecordState(resp.Result)
+	if recJSON {
+		out, _ := json.MarshalIndent(state, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if state["recording"] != true {
+		fmt.Println("Not recording")
+		return
+	}
+
+	elapsed := ""
+	if s, ok := state["startedAt"].(string); ok {
+		if started, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			elapsed = fmt.Sprintf(" for %s", time.Since(started).Round(time.Second))
+		}
+	}
+	fmt.Printf("Recording %v%s: %v\n", state["format"], elapsed, state["path"])
+}
+
+func decodeRecordState(result *any) map[string]any {
+	if result == nil {
+		return map[string]any{}
+	}
+	m, _ := (*result).(map[string]any)
+	if m == nil {
+		return map[string]any{}
+	}
+	return m
+}
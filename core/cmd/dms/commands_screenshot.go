@@ -1,13 +1,12 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
 	"github.com/spf13/cobra"
 )
@@ -19,12 +18,22 @@ var (
 	ssQuality     int
 	ssOutputDir   string
 	ssFilename    string
+	ssTemplate    string
+	ssLossless    bool
+	ssMaxSize     string
+	ssMetadata    bool
+	ssDelay       float64
+	ssNoCountdown bool
+	ssRepeat      int
+	ssInterval    float64
 	ssNoClipboard bool
 	ssNoFile      bool
 	ssNoNotify    bool
 	ssNoConfirm   bool
 	ssReset       bool
 	ssStdout      bool
+	ssAnnotate    bool
+	ssNoHistory   bool
 )
 
 var screenshotCmd = &cobra.Command{
@@ -43,8 +52,18 @@ Modes:
 Output format (--format):
   png         - PNG format (default)
   jpg/jpeg    - JPEG format
+  webp        - WebP format (lossy needs cwebp or ffmpeg, else lossless)
+  qoi         - QOI format
+  avif        - AVIF format (needs avifenc)
+  jxl         - JPEG XL format (needs cjxl)
   ppm         - PPM format
 
+Filename template (--template, DMS_SCREENSHOT_TEMPLATE):
+  strftime conversions (%Y %m %d %H %M %S ...) plus {output}, {mode},
+  {w} and {h}. The extension is added when missing and the template may
+  contain subdirectories. {n} is the position in a --repeat burst.
+  Default: screenshot_%Y-%m-%d_%H-%M-%S
+
 Examples:
   dms screenshot                     # Region select, save file + clipboard
   dms screenshot full                # Full screen of focused output
@@ -57,7 +76,19 @@ Examples:
   dms screenshot --no-file           # Clipboard only
   dms screenshot --no-confirm        # Region capture on mouse release
   dms screenshot --cursor=on         # Include cursor
-  dms screenshot -f jpg -q 85        # JPEG with quality 85`,
+  dms screenshot -f jpg -q 85        # JPEG with quality 85
+  dms screenshot -f webp --lossless  # Lossless WebP
+  dms screenshot --max-size 1M      # Shrink to fit in 1 MiB
+  dms screenshot --template '%F/{output}_{w}x{h}_%H%M%S'
+  dms screenshot --annotate          # Mark up before saving
+  dms screenshot full --delay 5      # Countdown, then capture (menus, hover)
+  dms screenshot --repeat 5 --interval 0.5  # Burst of 5 from one selection
+
+Annotation editor (--annotate):
+  A arrow, R rectangle, F freehand, T text, B blur, X pixelate,
+  N numbered callout, C cycle color, -/+ or scroll to resize,
+  Ctrl+Z undo, Ctrl+Y redo, Enter save and copy, Ctrl+S save only,
+  Ctrl+C copy only, Esc discard`,
 }
 
 var ssRegionCmd = &cobra.Command{
@@ -116,16 +147,26 @@ var notifyActionCmd = &cobra.Command{
 func init() {
 	screenshotCmd.PersistentFlags().StringVarP(&ssOutputName, "output", "o", "", "Output name for 'output' mode")
 	screenshotCmd.PersistentFlags().StringVar(&ssCursor, "cursor", "off", "Include cursor in screenshot (on/off)")
-	screenshotCmd.PersistentFlags().StringVarP(&ssFormat, "format", "f", "png", "Output format (png, jpg, ppm)")
-	screenshotCmd.PersistentFlags().IntVarP(&ssQuality, "quality", "q", 90, "JPEG quality (1-100)")
+	screenshotCmd.PersistentFlags().StringVarP(&ssFormat, "format", "f", "png", "Output format (png, jpg, webp, qoi, avif, jxl, ppm)")
+	screenshotCmd.PersistentFlags().IntVarP(&ssQuality, "quality", "q", 90, "Quality for lossy formats (1-100)")
+	screenshotCmd.PersistentFlags().BoolVar(&ssLossless, "lossless", false, "Use lossless encoding for webp, avif and jxl")
+	screenshotCmd.PersistentFlags().StringVar(&ssMaxSize, "max-size", "", "Fit the image in a byte budget, e.g. 800K or 8M")
+	screenshotCmd.PersistentFlags().BoolVar(&ssMetadata, "metadata", false, "Embed capture time, output and region")
 	screenshotCmd.PersistentFlags().StringVarP(&ssOutputDir, "dir", "d", "", "Output directory")
 	screenshotCmd.PersistentFlags().StringVar(&ssFilename, "filename", "", "Output filename (auto-generated if empty)")
+	screenshotCmd.PersistentFlags().StringVar(&ssTemplate, "template", "", "Filename template (see help)")
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoClipboard, "no-clipboard", false, "Don't copy to clipboard")
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoFile, "no-file", false, "Don't save to file")
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoNotify, "no-notify", false, "Don't show notification")
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoConfirm, "no-confirm", false, "Region mode: capture on mouse release without Enter/Space confirmation")
 	screenshotCmd.PersistentFlags().BoolVar(&ssReset, "reset", false, "Reset saved last-region preselection before capturing")
 	screenshotCmd.PersistentFlags().BoolVar(&ssStdout, "stdout", false, "Output image to stdout (for piping to swappy, etc.)")
+	screenshotCmd.PersistentFlags().Float64Var(&ssDelay, "delay", 0, "Seconds to wait before capturing, with an on-screen countdown")
+	screenshotCmd.PersistentFlags().BoolVar(&ssNoCountdown, "no-countdown", false, "Wait for --delay without showing the countdown")
+	screenshotCmd.PersistentFlags().IntVar(&ssRepeat, "repeat", 1, "Number of captures to take (region mode selects once)")
+	screenshotCmd.PersistentFlags().Float64Var(&ssInterval, "interval", 1, "Seconds between --repeat captures")
+	screenshotCmd.PersistentFlags().BoolVar(&ssNoHistory, "no-history", false, "Don't record the screenshot in the history")
+	screenshotCmd.PersistentFlags().BoolVar(&ssAnnotate, "annotate", false, "Open the annotation editor before saving")
 
 	screenshotCmd.AddCommand(ssRegionCmd)
 	screenshotCmd.AddCommand(ssFullCmd)
@@ -151,6 +192,8 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 	config.NoConfirm = ssNoConfirm
 	config.Reset = ssReset
 	config.Stdout = ssStdout
+	config.Annotate = ssAnnotate
+	config.History = !ssNoHistory
 
 	if ssOutputDir != "" {
 		config.OutputDir = ssOutputDir
@@ -159,14 +202,30 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 		config.Filename = ssFilename
 	}
 
-	switch strings.ToLower(ssFormat) {
-	case "jpg", "jpeg":
-		config.Format = screenshot.FormatJPEG
-	case "ppm":
-		config.Format = screenshot.FormatPPM
-	default:
-		config.Format = screenshot.FormatPNG
+	config.Template = ssTemplate
+	if config.Template == "" {
+		config.Template = screenshot.FilenameTemplate()
+	}
+
+	format, err := screenshot.ParseFormat(ssFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.Format = format
+
+	maxSize, err := screenshot.ParseSize(ssMaxSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	config.MaxSize = maxSize
+	config.Delay = time.Duration(ssDelay * float64(time.Second))
+	config.Countdown = !ssNoCountdown
+	config.Repeat = max(ssRepeat, 1)
+	config.Interval = time.Duration(ssInterval * float64(time.Second))
+	config.Lossless = ssLossless
+	config.Metadata = ssMetadata
 
 	if ssQuality < 1 {
 		ssQuality = 1
@@ -180,165 +239,24 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 }
 
 func runScreenshot(config screenshot.Config) {
-	sc := screenshot.New(config)
-	result, err := sc.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if config.Stdout && config.Repeat > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --repeat cannot be combined with --stdout")
 		os.Exit(1)
 	}
 
-	if result == nil {
-		os.Exit(0)
-	}
-
-	defer result.Buffer.Close()
-
-	if result.YInverted {
-		result.Buffer.FlipVertical()
-	}
-
-	if config.Stdout {
-		if err := writeImageToStdout(result.Buffer, config.Format, config.Quality, result.Format); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing to stdout: %v\n", err)
-			os.Exit(1)
-		}
-		return
-	}
-
-	var filePath string
-
-	if config.SaveFile {
-		outputDir := config.OutputDir
-		if outputDir == "" {
-			outputDir = screenshot.GetOutputDir()
-		}
-
-		filename := config.Filename
-		if filename == "" {
-			filename = screenshot.GenerateFilename(config.Format)
-		}
-
-		filePath = filepath.Join(outputDir, filename)
-		if err := screenshot.WriteToFileWithFormat(result.Buffer, filePath, config.Format, config.Quality, result.Format); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println(filePath)
-	}
-
-	if config.Clipboard {
-		if err := copyImageToClipboard(result.Buffer, config.Format, config.Quality, result.Format); err != nil {
-			fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
-			os.Exit(1)
-		}
-		if !config.SaveFile {
+	shots, err := screenshot.Take(context.Background(), config)
+	for _, shot := range shots {
+		switch {
+		case shot.Path != "":
+			fmt.Println(shot.Path)
+		case shot.Clipboard:
 			fmt.Println("Copied to clipboard")
 		}
 	}
-
-	if config.Notify {
-		thumbData, thumbW, thumbH := bufferToRGBThumbnail(result.Buffer, 256, result.Format)
-		screenshot.SendNotification(screenshot.NotifyResult{
-			FilePath:  filePath,
-			Clipboard: config.Clipboard,
-			ImageData: thumbData,
-			Width:     thumbW,
-			Height:    thumbH,
-		})
-	}
-}
-
-func copyImageToClipboard(buf *screenshot.ShmBuffer, format screenshot.Format, quality int, pixelFormat uint32) error {
-	var mimeType string
-	var data bytes.Buffer
-
-	img := screenshot.BufferToImageWithFormat(buf, pixelFormat)
-
-	switch format {
-	case screenshot.FormatJPEG:
-		mimeType = "image/jpeg"
-		if err := screenshot.EncodeJPEG(&data, img, quality); err != nil {
-			return err
-		}
-	default:
-		mimeType = "image/png"
-		if err := screenshot.EncodePNG(&data, img); err != nil {
-			return err
-		}
-	}
-
-	return clipboard.Copy(data.Bytes(), mimeType)
-}
-
-func writeImageToStdout(buf *screenshot.ShmBuffer, format screenshot.Format, quality int, pixelFormat uint32) error {
-	img := screenshot.BufferToImageWithFormat(buf, pixelFormat)
-
-	switch format {
-	case screenshot.FormatJPEG:
-		return screenshot.EncodeJPEG(os.Stdout, img, quality)
-	default:
-		return screenshot.EncodePNG(os.Stdout, img)
-	}
-}
-
-func bufferToRGBThumbnail(buf *screenshot.ShmBuffer, maxSize int, pixelFormat uint32) ([]byte, int, int) {
-	srcW, srcH := buf.Width, buf.Height
-	scale := 1.0
-	if srcW > maxSize || srcH > maxSize {
-		if srcW > srcH {
-			scale = float64(maxSize) / float64(srcW)
-		} else {
-			scale = float64(maxSize) / float64(srcH)
-		}
-	}
-
-	dstW := int(float64(srcW) * scale)
-	dstH := int(float64(srcH) * scale)
-	if dstW < 1 {
-		dstW = 1
-	}
-	if dstH < 1 {
-		dstH = 1
-	}
-
-	data := buf.Data()
-	rgb := make([]byte, dstW*dstH*3)
-
-	var swapRB bool
-	switch pixelFormat {
-	case uint32(screenshot.FormatABGR8888), uint32(screenshot.FormatXBGR8888):
-		swapRB = false
-	default:
-		swapRB = true
-	}
-
-	for y := 0; y < dstH; y++ {
-		srcY := int(float64(y) / scale)
-		if srcY >= srcH {
-			srcY = srcH - 1
-		}
-		for x := 0; x < dstW; x++ {
-			srcX := int(float64(x) / scale)
-			if srcX >= srcW {
-				srcX = srcW - 1
-			}
-			si := srcY*buf.Stride + srcX*4
-			di := (y*dstW + x) * 3
-			if si+3 >= len(data) {
-				continue
-			}
-			if swapRB {
-				rgb[di+0] = data[si+2]
-				rgb[di+1] = data[si+1]
-				rgb[di+2] = data[si+0]
-			} else {
-				rgb[di+0] = data[si+0]
-				rgb[di+1] = data[si+1]
-				rgb[di+2] = data[si+2]
-			}
-		}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-	return rgb, dstW, dstH
 }
 
 func runScreenshotRegion(cmd *cobra.Command, args []string) {
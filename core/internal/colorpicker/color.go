@@ -19,21 +19,50 @@ const (
 	FormatHSL
 	FormatHSV
 	FormatCMYK
+	FormatOKLCH
+	FormatOKLab
+	FormatLab
+	FormatLCh
+	FormatHWB
+	FormatP3
+	FormatLinear
 )
 
+var formatNames = map[OutputFormat]string{
+	FormatHex:    "hex",
+	FormatRGB:    "rgb",
+	FormatHSL:    "hsl",
+	FormatHSV:    "hsv",
+	FormatCMYK:   "cmyk",
+	FormatOKLCH:  "oklch",
+	FormatOKLab:  "oklab",
+	FormatLab:    "lab",
+	FormatLCh:    "lch",
+	FormatHWB:    "hwb",
+	FormatP3:     "p3",
+	FormatLinear: "linear",
+}
+
+func (f OutputFormat) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return "hex"
+}
+
 func ParseFormat(s string) OutputFormat {
 	switch strings.ToLower(s) {
-	case "rgb":
-		return FormatRGB
-	case "hsl":
-		return FormatHSL
-	case "hsv":
-		return FormatHSV
-	case "cmyk":
-		return FormatCMYK
-	default:
-		return FormatHex
+	case "display-p3":
+		return FormatP3
+	case "srgb-linear":
+		return FormatLinear
 	}
+	for f, name := range formatNames {
+		if strings.EqualFold(s, name) {
+			return f
+		}
+	}
+	return FormatHex
 }
 
 func (c Color) ToHex(lowercase bool) string {
@@ -76,6 +105,8 @@ func (c Color) Format(format OutputFormat, lowercase bool, customFmt string) str
 		return c.ToHSV()
 	case FormatCMYK:
 		return c.ToCMYK()
+	case FormatOKLCH, FormatOKLab, FormatLab, FormatLCh, FormatHWB, FormatP3, FormatLinear:
+		return strings.Join(c.components(format), " ")
 	default:
 		return c.ToHex(lowercase)
 	}
@@ -94,6 +125,9 @@ func (c Color) formatCustom(format OutputFormat, customFmt string) string {
 	case FormatCMYK:
 		cy, m, y, k := rgbToCMYK(c.R, c.G, c.B)
 		return replaceArgs4(customFmt, cy, m, y, k)
+	case FormatOKLCH, FormatOKLab, FormatLab, FormatLCh, FormatHWB, FormatP3, FormatLinear:
+		v := c.components(format)
+		return replaceArgsStr(customFmt, v[0], v[1], v[2])
 	default:
 		if strings.Contains(customFmt, "{0}") {
 			r := fmt.Sprintf("%02X", c.R)
@@ -274,6 +308,41 @@ type ColorJSON struct {
 		Y int `json:"y"`
 		K int `json:"k"`
 	} `json:"cmyk"`
+	OKLCH struct {
+		L float64 `json:"l"`
+		C float64 `json:"c"`
+		H float64 `json:"h"`
+	} `json:"oklch"`
+	OKLab struct {
+		L float64 `json:"l"`
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	} `json:"oklab"`
+	Lab struct {
+		L float64 `json:"l"`
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	} `json:"lab"`
+	LCh struct {
+		L float64 `json:"l"`
+		C float64 `json:"c"`
+		H float64 `json:"h"`
+	} `json:"lch"`
+	HWB struct {
+		H int `json:"h"`
+		W int `json:"w"`
+		B int `json:"b"`
+	} `json:"hwb"`
+	DisplayP3 struct {
+		R float64 `json:"r"`
+		G float64 `json:"g"`
+		B float64 `json:"b"`
+	} `json:"displayP3"`
+	LinearRGB struct {
+		R float64 `json:"r"`
+		G float64 `json:"g"`
+		B float64 `json:"b"`
+	} `json:"linearRgb"`
 }
 
 func (c Color) ToJSON() (string, error) {
@@ -298,9 +367,22 @@ func (c Color) ToJSON() (string, error) {
 	data.CMYK.Y = y
 	data.CMYK.K = k
 
+	data.OKLCH.L, data.OKLCH.C, data.OKLCH.H = roundComponents(c.OKLCH())
+	data.OKLab.L, data.OKLab.A, data.OKLab.B = roundComponents(c.OKLab())
+	data.Lab.L, data.Lab.A, data.Lab.B = roundComponents(c.Lab())
+	data.LCh.L, data.LCh.C, data.LCh.H = roundComponents(c.LCh())
+	data.HWB.H, data.HWB.W, data.HWB.B = c.HWB()
+	data.DisplayP3.R, data.DisplayP3.G, data.DisplayP3.B = roundComponents(c.DisplayP3())
+	data.LinearRGB.R, data.LinearRGB.G, data.LinearRGB.B = roundComponents(c.LinearRGB())
+
 	bytes, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), nil
 }
+
+func roundComponents(a, b, c float64) (float64, float64, float64) {
+	r := func(v float64) float64 { return math.Round(v*1e4) / 1e4 }
+	return r(a), r(b), r(c)
+}
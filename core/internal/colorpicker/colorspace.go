@@ -0,0 +1,150 @@
+package colorpicker
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Bradford adaptation from D65 to D50, as used by CSS Color 4 for lab()
+// and lch(). go-colorful's Lab is relative to D65, which is not what
+// browsers and design tools expect.
+var d65ToD50 = [3][3]float64{
+	{1.0479297925449969, 0.022946870601609652, -0.05019226628920524},
+	{0.02962780877005599, 0.9904344267538799, -0.017073799063418826},
+	{-0.009243040646204504, 0.015055191490298152, 0.7518742814281371},
+}
+
+func (c Color) colorful() colorful.Color {
+	return colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}
+}
+
+// OKLab returns lightness in 0-1 and the a/b axes, roughly -0.4 to 0.4.
+func (c Color) OKLab() (l, a, b float64) {
+	return c.colorful().OkLab()
+}
+
+// OKLCH returns lightness in 0-1, chroma and hue in degrees. Achromatic
+// colors get a hue of 0 rather than whatever rounding noise produces.
+func (c Color) OKLCH() (l, ch, h float64) {
+	l, a, b := c.OKLab()
+	return toPolar(l, a, b, 5e-4)
+}
+
+// Lab returns CIE Lab relative to D50 with lightness in 0-100.
+func (c Color) Lab() (l, a, b float64) {
+	x, y, z := c.colorful().Xyz()
+	m := d65ToD50
+	xd := m[0][0]*x + m[0][1]*y + m[0][2]*z
+	yd := m[1][0]*x + m[1][1]*y + m[1][2]*z
+	zd := m[2][0]*x + m[2][1]*y + m[2][2]*z
+	l, a, b = colorful.XyzToLabWhiteRef(xd, yd, zd, colorful.D50)
+	return l * 100, a * 100, b * 100
+}
+
+// LCh is the polar form of Lab.
+func (c Color) LCh() (l, ch, h float64) {
+	l, a, b := c.Lab()
+	return toPolar(l, a, b, 0.02)
+}
+
+// HWB returns hue in degrees and whiteness/blackness in 0-100.
+func (c Color) HWB() (h, w, b int) {
+	h, _, _ = rgbToHSV(c.R, c.G, c.B)
+	minVal := min(c.R, c.G, c.B)
+	maxVal := max(c.R, c.G, c.B)
+	w = int(math.Round(float64(minVal) / 255 * 100))
+	b = int(math.Round((1 - float64(maxVal)/255) * 100))
+	return h, w, b
+}
+
+// DisplayP3 returns gamma-encoded Display P3 components in 0-1.
+func (c Color) DisplayP3() (r, g, b float64) {
+	return c.colorful().DisplayP3()
+}
+
+// LinearRGB returns linear-light sRGB components in 0-1.
+func (c Color) LinearRGB() (r, g, b float64) {
+	return c.colorful().LinearRgb()
+}
+
+func toPolar(l, a, b, achromatic float64) (float64, float64, float64) {
+	ch := math.Hypot(a, b)
+	if ch < achromatic {
+		return l, 0, 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return l, ch, h
+}
+
+// components returns the values placed into a custom format's {0}..{n}
+// for the color-space formats, already rounded for display.
+func (c Color) components(format OutputFormat) []string {
+	switch format {
+	case FormatOKLCH:
+		l, ch, h := c.OKLCH()
+		return []string{num(l*100, 2) + "%", num(ch, 4), num(h, 2)}
+	case FormatOKLab:
+		l, a, b := c.OKLab()
+		return []string{num(l*100, 2) + "%", num(a, 4), num(b, 4)}
+	case FormatLab:
+		l, a, b := c.Lab()
+		return []string{num(l, 2), num(a, 2), num(b, 2)}
+	case FormatLCh:
+		l, ch, h := c.LCh()
+		return []string{num(l, 2), num(ch, 2), num(h, 2)}
+	case FormatHWB:
+		h, w, b := c.HWB()
+		return []string{strconv.Itoa(h), strconv.Itoa(w) + "%", strconv.Itoa(b) + "%"}
+	case FormatP3:
+		r, g, b := c.DisplayP3()
+		return []string{num(r, 4), num(g, 4), num(b, 4)}
+	case FormatLinear:
+		r, g, b := c.LinearRGB()
+		return []string{num(r, 4), num(g, 4), num(b, 4)}
+	}
+	return nil
+}
+
+// CSS formats the color with CSS Color 4 function syntax, e.g.
+// rgb(255 0 0) or oklch(62.8% 0.2576 29.23). HSV has no CSS function and
+// is printed as hsv(...) for symmetry.
+func (c Color) CSS(format OutputFormat, lowercase bool) string {
+	switch format {
+	case FormatRGB:
+		return fmt.Sprintf("rgb(%d %d %d)", c.R, c.G, c.B)
+	case FormatHSL:
+		h, s, l := rgbToHSL(c.R, c.G, c.B)
+		return fmt.Sprintf("hsl(%d %d%% %d%%)", h, s, l)
+	case FormatHSV:
+		h, s, v := rgbToHSV(c.R, c.G, c.B)
+		return fmt.Sprintf("hsv(%d %d%% %d%%)", h, s, v)
+	case FormatCMYK:
+		cy, m, y, k := rgbToCMYK(c.R, c.G, c.B)
+		return fmt.Sprintf("device-cmyk(%d%% %d%% %d%% %d%%)", cy, m, y, k)
+	case FormatOKLCH, FormatOKLab, FormatLab, FormatLCh, FormatHWB:
+		return fmt.Sprintf("%s(%s)", format, strings.Join(c.components(format), " "))
+	case FormatP3:
+		return "color(display-p3 " + strings.Join(c.components(format), " ") + ")"
+	case FormatLinear:
+		return "color(srgb-linear " + strings.Join(c.components(format), " ") + ")"
+	default:
+		return c.ToHex(lowercase)
+	}
+}
+
+// num rounds v to prec decimals and drops trailing zeros.
+func num(v float64, prec int) string {
+	p := math.Pow(10, float64(prec))
+	v = math.Round(v*p) / p
+	if v == 0 {
+		v = 0 // no "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
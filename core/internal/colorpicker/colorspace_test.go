@@ -0,0 +1,111 @@
+package colorpicker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var red = Color{R: 255, A: 255}
+
+func TestColorSpaces(t *testing.T) {
+	l, c, h := red.OKLCH()
+	assert.InDelta(t, 0.628, l, 0.001)
+	assert.InDelta(t, 0.2577, c, 0.001)
+	assert.InDelta(t, 29.23, h, 0.05)
+
+	l, a, b := red.Lab()
+	assert.InDelta(t, 54.29, l, 0.05)
+	assert.InDelta(t, 80.8, a, 0.1)
+	assert.InDelta(t, 69.89, b, 0.1)
+
+	l, c, h = red.LCh()
+	assert.InDelta(t, 106.84, c, 0.1)
+	assert.InDelta(t, 40.85, h, 0.1)
+
+	pr, pg, pb := red.DisplayP3()
+	assert.InDelta(t, 0.9175, pr, 0.001)
+	assert.InDelta(t, 0.2003, pg, 0.001)
+	assert.InDelta(t, 0.1386, pb, 0.001)
+
+	gray := Color{R: 128, G: 128, B: 128, A: 255}
+	lr, _, _ := gray.LinearRGB()
+	assert.InDelta(t, 0.2159, lr, 0.0001)
+
+	hh, w, bl := gray.HWB()
+	assert.Equal(t, []int{0, 50, 50}, []int{hh, w, bl})
+
+	_, c, h = Color{R: 255, G: 255, B: 255, A: 255}.OKLCH()
+	assert.Zero(t, c)
+	assert.Zero(t, h)
+}
+
+func TestFormatColorSpaces(t *testing.T) {
+	assert.Equal(t, "62.8% 0.2576 29.23", red.Format(FormatOKLCH, false, ""))
+	assert.Equal(t, "oklch(62.8% 0.2576 29.23)", red.CSS(FormatOKLCH, false))
+	assert.Equal(t, "rgb(255 0 0)", red.CSS(FormatRGB, false))
+	assert.Equal(t, "hsl(0 100% 50%)", red.CSS(FormatHSL, false))
+	assert.Equal(t, "hwb(0 0% 0%)", red.CSS(FormatHWB, false))
+	assert.Equal(t, "color(srgb-linear 1 0 0)", red.CSS(FormatLinear, false))
+	assert.Equal(t, "#ff0000", red.CSS(FormatHex, true))
+	assert.Equal(t, "L=62.8%", red.Format(FormatOKLCH, false, "L={0}"))
+
+	assert.Equal(t, FormatP3, ParseFormat("display-p3"))
+	assert.Equal(t, FormatOKLCH, ParseFormat("OKLCH"))
+	assert.Equal(t, FormatHex, ParseFormat("nope"))
+
+	_, err := red.ToJSON()
+	require.NoError(t, err)
+}
+
+func TestNearestNamed(t *testing.T) {
+	palette, err := NamedColors(SourceCSS)
+	require.NoError(t, err)
+
+	m := NearestNamed(Color{R: 0xff, G: 0x63, B: 0x48, A: 255}, palette, 1)
+	require.Len(t, m, 1)
+	assert.Equal(t, "tomato", m[0].Name)
+	assert.Less(t, m[0].DeltaE, 1.0)
+
+	m = NearestNamed(Color{G: 255, B: 255, A: 255}, palette, 2)
+	assert.Equal(t, "aqua / cyan", m[0].Name)
+	assert.Zero(t, m[0].DeltaE)
+	assert.Len(t, m, 2)
+
+	palette, err = NamedColors(SourceTailwind)
+	require.NoError(t, err)
+	m = NearestNamed(Color{R: 0x3b, G: 0x82, B: 0xf5, A: 255}, palette, 1)
+	assert.Equal(t, "blue-500", m[0].Name)
+
+	_, err = NamedColors("pantone")
+	assert.Error(t, err)
+}
+
+func TestLoadThemeColors(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	assert.Empty(t, LoadThemeColors())
+
+	dir := filepath.Join(cache, "DankMaterialShell")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dms-colors.json"), []byte(`{
+		"dank16": {"color10": {"dark": "#00ff00", "light": "#008800"}, "color1": {"dark": "#ff0000", "light": "#880000"}},
+		"colors": {"dark": {"primary": "#d0bcff", "surface": "#141218"}, "light": {"primary": "#6750a4"}}
+	}`), 0o644))
+
+	colors := LoadThemeColors()
+	names := make([]string, len(colors))
+	for i, c := range colors {
+		names[i] = c.Name
+	}
+	assert.Equal(t, []string{
+		"dark.primary", "dark.surface", "light.primary",
+		"dank16.color1.dark", "dank16.color1.light", "dank16.color10.dark", "dank16.color10.light",
+	}, names)
+
+	m := NearestNamed(Color{R: 0x67, G: 0x50, B: 0xa4, A: 255}, colors, 1)
+	assert.Equal(t, "light.primary", m[0].Name)
+}
@@ -0,0 +1,62 @@
+package colorpicker
+
+import (
+	"math"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/dank16"
+)
+
+// Contrast compares a foreground and background color with both the WCAG
+// 2 ratio and the APCA-style lightness contrast dank16 uses for palettes.
+type Contrast struct {
+	Foreground string  `json:"foreground"`
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"`
+	AA         bool    `json:"aa"`
+	AALarge    bool    `json:"aaLarge"`
+	AAA        bool    `json:"aaa"`
+	AAALarge   bool    `json:"aaaLarge"`
+	Lc         float64 `json:"lc"`
+	APCA       string  `json:"apca"`
+}
+
+func CheckContrast(fg, bg Color) Contrast {
+	fgHex, bgHex := fg.ToHex(false), bg.ToHex(false)
+	ratio := dank16.ContrastRatio(fgHex, bgHex)
+
+	// Light text on a dark background is the negative polarity case.
+	lc := math.Max(dank16.DeltaPhiStar(fgHex, bgHex, fg.Luminance() > bg.Luminance()), 0)
+
+	return Contrast{
+		Foreground: fgHex,
+		Background: bgHex,
+		Ratio:      math.Round(ratio*100) / 100,
+		AA:         ratio >= 4.5,
+		AALarge:    ratio >= 3,
+		AAA:        ratio >= 7,
+		AAALarge:   ratio >= 4.5,
+		Lc:         math.Round(lc*10) / 10,
+		APCA:       apcaLevel(lc),
+	}
+}
+
+// apcaLevel names the use the lightness contrast is sufficient for, using
+// the APCA bronze level thresholds.
+func apcaLevel(lc float64) string {
+	switch {
+	case lc >= 90:
+		return "preferred body text"
+	case lc >= 75:
+		return "body text"
+	case lc >= 60:
+		return "content text"
+	case lc >= 45:
+		return "large text"
+	case lc >= 30:
+		return "non-text elements"
+	case lc >= 15:
+		return "decorative only"
+	default:
+		return "insufficient"
+	}
+}
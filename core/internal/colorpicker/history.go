@@ -0,0 +1,240 @@
+package colorpicker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+const maxHistory = 500
+
+type HistoryEntry struct {
+	Hex  string    `json:"hex"`
+	Name string    `json:"name,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+func (e HistoryEntry) Color() Color {
+	c, _ := ParseHex(e.Hex)
+	return c
+}
+
+// History holds picked colors, most recent first. Picking a color that is
+// already there moves it to the top and keeps its name.
+type History struct {
+	Entries []HistoryEntry `json:"entries"`
+}
+
+func getHistoryFilePath() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "color-history.json")
+}
+
+func LoadHistory() (*History, error) {
+	return utils.LoadJSONState(getHistoryFilePath(), History{})
+}
+
+// UpdateHistory runs fn on the loaded history under an exclusive lock and
+// saves the result.
+func UpdateHistory(fn func(h *History) error) error {
+	return utils.UpdateJSONState(getHistoryFilePath(), History{}, fn)
+}
+
+// AddHistory records picked colors.
+func AddHistory(colors ...Color) error {
+	return UpdateHistory(func(h *History) error {
+		h.Add(colors...)
+		return nil
+	})
+}
+
+func (h *History) Add(colors ...Color) {
+	now := time.Now()
+	for _, c := range colors {
+		entry := HistoryEntry{Hex: c.ToHex(false), Time: now}
+		if i := h.indexOfHex(entry.Hex); i >= 0 {
+			entry.Name = h.Entries[i].Name
+			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+		}
+		h.Entries = append([]HistoryEntry{entry}, h.Entries...)
+	}
+	if len(h.Entries) > maxHistory {
+		h.Entries = h.Entries[:maxHistory]
+	}
+}
+
+func (h *History) indexOfHex(hex string) int {
+	for i, e := range h.Entries {
+		if strings.EqualFold(e.Hex, hex) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find resolves ref to an entry index. A ref is either the 1-based
+// position shown by `dms color history`, a hex color or a name.
+func (h *History) Find(ref string) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(h.Entries) {
+			return -1, fmt.Errorf("no color #%d in history (%d entries)", n, len(h.Entries))
+		}
+		return n - 1, nil
+	}
+	if c, err := ParseHex(ref); err == nil {
+		if i := h.indexOfHex(c.ToHex(false)); i >= 0 {
+			return i, nil
+		}
+	}
+	for i, e := range h.Entries {
+		if e.Name != "" && strings.EqualFold(e.Name, ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("color %q not found in history", ref)
+}
+
+func (h *History) SetName(ref, name string) error {
+	i, err := h.Find(ref)
+	if err != nil {
+		return err
+	}
+	h.Entries[i].Name = strings.TrimSpace(name)
+	return nil
+}
+
+func (h *History) Remove(ref string) error {
+	i, err := h.Find(ref)
+	if err != nil {
+		return err
+	}
+	h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+	return nil
+}
+
+// ResolveColor accepts a hex color or a history reference. Values with a
+// leading # are always hex; bare digits are tried as a position first.
+func ResolveColor(ref string) (Color, error) {
+	if strings.HasPrefix(ref, "#") {
+		return ParseHex(ref)
+	}
+	h, err := LoadHistory()
+	if err != nil {
+		return Color{}, err
+	}
+	if i, err := h.Find(ref); err == nil {
+		return h.Entries[i].Color(), nil
+	}
+	if c, err := ParseHex(ref); err == nil {
+		return c, nil
+	}
+	return Color{}, fmt.Errorf("%q is neither a hex color nor in the history", ref)
+}
+
+var hexColorRe = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParseHex parses #RGB or #RRGGBB, with or without the hash.
+func ParseHex(s string) (Color, error) {
+	m := hexColorRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	hex := m[1]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, _ := strconv.ParseUint(hex, 16, 32)
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+type ExportFormat int
+
+const (
+	ExportGPL ExportFormat = iota
+	ExportCSS
+	ExportJSON
+)
+
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch strings.ToLower(s) {
+	case "gpl", "gimp":
+		return ExportGPL, nil
+	case "css":
+		return ExportCSS, nil
+	case "json":
+		return ExportJSON, nil
+	}
+	return ExportGPL, fmt.Errorf("unknown export format %q (gpl, css, json)", s)
+}
+
+// Export writes entries as a GIMP palette, CSS custom properties or JSON.
+// Unnamed colors get positional names so every format stays addressable.
+func Export(w io.Writer, entries []HistoryEntry, format ExportFormat, paletteName string) error {
+	switch format {
+	case ExportCSS:
+		var b strings.Builder
+		b.WriteString(":root {\n")
+		seen := make(map[string]int)
+		for i, e := range entries {
+			prop := cssIdent(e.Name)
+			if prop == "" {
+				prop = fmt.Sprintf("color-%d", i+1)
+			}
+			if seen[prop]++; seen[prop] > 1 {
+				prop = fmt.Sprintf("%s-%d", prop, seen[prop])
+			}
+			fmt.Fprintf(&b, "  --%s: %s;\n", prop, strings.ToLower(e.Hex))
+		}
+		b.WriteString("}\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+
+	case ExportJSON:
+		type jsonColor struct {
+			Name string `json:"name,omitempty"`
+			Hex  string `json:"hex"`
+			R    uint8  `json:"r"`
+			G    uint8  `json:"g"`
+			B    uint8  `json:"b"`
+		}
+		out := struct {
+			Name   string      `json:"name"`
+			Colors []jsonColor `json:"colors"`
+		}{Name: paletteName, Colors: make([]jsonColor, 0, len(entries))}
+		for _, e := range entries {
+			c := e.Color()
+			out.Colors = append(out.Colors, jsonColor{Name: e.Name, Hex: c.ToHex(false), R: c.R, G: c.G, B: c.B})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+
+	default:
+		var b strings.Builder
+		b.WriteString("GIMP Palette\n")
+		fmt.Fprintf(&b, "Name: %s\n", paletteName)
+		b.WriteString("Columns: 8\n#\n")
+		for i, e := range entries {
+			c := e.Color()
+			name := e.Name
+			if name == "" {
+				name = fmt.Sprintf("Color %d", i+1)
+			}
+			fmt.Fprintf(&b, "%3d %3d %3d\t%s\n", c.R, c.G, c.B, name)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+}
+
+var cssIdentRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func cssIdent(name string) string {
+	return strings.Trim(cssIdentRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
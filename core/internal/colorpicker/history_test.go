@@ -0,0 +1,131 @@
+package colorpicker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHex(t *testing.T) {
+	c, err := ParseHex("#1E90ff")
+	require.NoError(t, err)
+	assert.Equal(t, Color{R: 0x1e, G: 0x90, B: 0xff, A: 255}, c)
+
+	c, err = ParseHex("f80")
+	require.NoError(t, err)
+	assert.Equal(t, Color{R: 0xff, G: 0x88, B: 0x00, A: 255}, c)
+
+	_, err = ParseHex("#12345")
+	assert.Error(t, err)
+}
+
+func TestHistory_AddMovesRepeatsToFront(t *testing.T) {
+	h := &History{}
+	red := Color{R: 255, A: 255}
+	blue := Color{B: 255, A: 255}
+
+	h.Add(red, blue)
+	require.NoError(t, h.SetName("#ff0000", "alert"))
+	h.Add(red)
+
+	require.Len(t, h.Entries, 2)
+	assert.Equal(t, "#FF0000", h.Entries[0].Hex)
+	assert.Equal(t, "alert", h.Entries[0].Name)
+	assert.Equal(t, "#0000FF", h.Entries[1].Hex)
+}
+
+func TestHistory_Find(t *testing.T) {
+	h := &History{}
+	h.Add(Color{R: 255, A: 255}, Color{G: 255, A: 255})
+	require.NoError(t, h.SetName("1", "Leaf"))
+
+	for ref, want := range map[string]int{"1": 0, "2": 1, "leaf": 0, "#ff0000": 1, "FF0000": 1} {
+		i, err := h.Find(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, i, ref)
+	}
+
+	_, err := h.Find("3")
+	assert.Error(t, err)
+	_, err = h.Find("missing")
+	assert.Error(t, err)
+}
+
+func TestUpdateHistory_Persists(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	require.NoError(t, AddHistory(Color{R: 10, G: 20, B: 30, A: 255}))
+	require.NoError(t, UpdateHistory(func(h *History) error {
+		return h.SetName("1", "Navy")
+	}))
+
+	h, err := LoadHistory()
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 1)
+	assert.Equal(t, "#0A141E", h.Entries[0].Hex)
+	assert.Equal(t, "Navy", h.Entries[0].Name)
+
+	c, err := ResolveColor("navy")
+	require.NoError(t, err)
+	assert.Equal(t, uint8(30), c.B)
+
+	c, err = ResolveColor("#123")
+	require.NoError(t, err)
+	assert.Equal(t, Color{R: 0x11, G: 0x22, B: 0x33, A: 255}, c)
+}
+
+func TestExport(t *testing.T) {
+	entries := []HistoryEntry{
+		{Hex: "#FF0000", Name: "Primary Red"},
+		{Hex: "#00FF00"},
+		{Hex: "#0000FF", Name: "primary red"},
+	}
+
+	var gpl strings.Builder
+	require.NoError(t, Export(&gpl, entries, ExportGPL, "Test"))
+	assert.True(t, strings.HasPrefix(gpl.String(), "GIMP Palette\nName: Test\n"))
+	assert.Contains(t, gpl.String(), "255   0   0\tPrimary Red\n")
+	assert.Contains(t, gpl.String(), "  0 255   0\tColor 2\n")
+
+	var css strings.Builder
+	require.NoError(t, Export(&css, entries, ExportCSS, "Test"))
+	assert.Equal(t, ":root {\n  --primary-red: #ff0000;\n  --color-2: #00ff00;\n  --primary-red-2: #0000ff;\n}\n", css.String())
+
+	var js strings.Builder
+	require.NoError(t, Export(&js, entries, ExportJSON, "Test"))
+	var out struct {
+		Name   string `json:"name"`
+		Colors []struct {
+			Name string `json:"name"`
+			Hex  string `json:"hex"`
+			G    uint8  `json:"g"`
+		} `json:"colors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(js.String()), &out))
+	assert.Equal(t, "Test", out.Name)
+	require.Len(t, out.Colors, 3)
+	assert.Equal(t, uint8(255), out.Colors[1].G)
+}
+
+func TestCheckContrast(t *testing.T) {
+	white := Color{R: 255, G: 255, B: 255, A: 255}
+	black := Color{A: 255}
+
+	c := CheckContrast(black, white)
+	assert.Equal(t, 21.0, c.Ratio)
+	assert.True(t, c.AA && c.AAA && c.AALarge && c.AAALarge)
+	assert.True(t, c.Lc > 90, "Lc %v", c.Lc)
+
+	grey := Color{R: 119, G: 119, B: 119, A: 255}
+	c = CheckContrast(grey, white)
+	assert.True(t, c.AALarge)
+	assert.False(t, c.AAA)
+
+	c = CheckContrast(white, white)
+	assert.Equal(t, 1.0, c.Ratio)
+	assert.False(t, c.AALarge)
+	assert.Equal(t, "insufficient", c.APCA)
+}
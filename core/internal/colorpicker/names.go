@@ -0,0 +1,158 @@
+package colorpicker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	SourceCSS      = "css"
+	SourceTailwind = "tailwind"
+	SourceTheme    = "theme"
+)
+
+var AllSources = []string{SourceCSS, SourceTailwind, SourceTheme}
+
+type NamedColor struct {
+	Name   string `json:"name"`
+	Hex    string `json:"hex"`
+	Source string `json:"source"`
+}
+
+// ColorMatch is a named color and its CIEDE2000 distance from the query.
+// Below about 1 the difference is not noticeable; 2-3 is a close match.
+type ColorMatch struct {
+	NamedColor
+	DeltaE float64 `json:"deltaE"`
+}
+
+// NamedColors returns the palettes for the given sources. A missing theme
+// palette is not an error, since matugen may not have run yet.
+func NamedColors(sources ...string) ([]NamedColor, error) {
+	var colors []NamedColor
+	for _, source := range sources {
+		switch strings.ToLower(source) {
+		case SourceCSS:
+			colors = append(colors, cssNamedColors...)
+		case SourceTailwind:
+			colors = append(colors, tailwindColors...)
+		case SourceTheme:
+			colors = append(colors, LoadThemeColors()...)
+		default:
+			return nil, fmt.Errorf("unknown color source %q (%s)", source, strings.Join(AllSources, ", "))
+		}
+	}
+	return colors, nil
+}
+
+// NearestNamed returns up to n palette colors closest to c. Entries from
+// the same source with the same value, such as aqua and cyan, are merged.
+func NearestNamed(c Color, palette []NamedColor, n int) []ColorMatch {
+	target := c.colorful()
+	matches := make([]ColorMatch, 0, len(palette))
+	for _, named := range palette {
+		nc, err := ParseHex(named.Hex)
+		if err != nil {
+			continue
+		}
+		d := target.DistanceCIEDE2000(nc.colorful()) * 100
+		matches = append(matches, ColorMatch{NamedColor: named, DeltaE: math.Round(d*100) / 100})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].DeltaE < matches[j].DeltaE
+	})
+
+	merged := make([]ColorMatch, 0, n)
+	index := make(map[string]int)
+	for _, m := range matches {
+		key := m.Source + strings.ToLower(m.Hex)
+		if i, ok := index[key]; ok {
+			merged[i].Name += " / " + m.Name
+			continue
+		}
+		if len(merged) == n {
+			break
+		}
+		index[key] = len(merged)
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+func getThemeColorsPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheDir, "DankMaterialShell", "dms-colors.json")
+}
+
+// themeColorsFile mirrors the parts of dms-colors.json written by the
+// dank.json matugen template.
+type themeColorsFile struct {
+	Dank16 map[string]struct {
+		Dark  string `json:"dark"`
+		Light string `json:"light"`
+	} `json:"dank16"`
+	Colors struct {
+		Dark  map[string]string `json:"dark"`
+		Light map[string]string `json:"light"`
+	} `json:"colors"`
+}
+
+// LoadThemeColors returns the current matugen and dank16 palette, named
+// like "dark.primary" and "dank16.color4.light".
+func LoadThemeColors() []NamedColor {
+	data, err := os.ReadFile(getThemeColorsPath())
+	if err != nil {
+		return nil
+	}
+	var file themeColorsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil
+	}
+
+	var colors []NamedColor
+	add := func(name, hex string) {
+		if hex != "" {
+			colors = append(colors, NamedColor{Name: name, Hex: hex, Source: SourceTheme})
+		}
+	}
+	for _, mode := range []struct {
+		name   string
+		colors map[string]string
+	}{{"dark", file.Colors.Dark}, {"light", file.Colors.Light}} {
+		for _, key := range sortedKeys(mode.colors) {
+			add(mode.name+"."+key, mode.colors[key])
+		}
+	}
+	keys := make([]string, 0, len(file.Dank16))
+	for key := range file.Dank16 {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		add("dank16."+key+".dark", file.Dank16[key].Dark)
+		add("dank16."+key+".light", file.Dank16[key].Light)
+	}
+	return colors
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
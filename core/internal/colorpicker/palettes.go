@@ -0,0 +1,393 @@
+package colorpicker
+
+// cssNamedColors are the CSS Color 4 named colors. The grey spellings are
+// left out since they only duplicate the gray ones.
+var cssNamedColors = []NamedColor{
+	{Name: "aliceblue", Hex: "#f0f8ff", Source: SourceCSS},
+	{Name: "antiquewhite", Hex: "#faebd7", Source: SourceCSS},
+	{Name: "aqua", Hex: "#00ffff", Source: SourceCSS},
+	{Name: "aquamarine", Hex: "#7fffd4", Source: SourceCSS},
+	{Name: "azure", Hex: "#f0ffff", Source: SourceCSS},
+	{Name: "beige", Hex: "#f5f5dc", Source: SourceCSS},
+	{Name: "bisque", Hex: "#ffe4c4", Source: SourceCSS},
+	{Name: "black", Hex: "#000000", Source: SourceCSS},
+	{Name: "blanchedalmond", Hex: "#ffebcd", Source: SourceCSS},
+	{Name: "blue", Hex: "#0000ff", Source: SourceCSS},
+	{Name: "blueviolet", Hex: "#8a2be2", Source: SourceCSS},
+	{Name: "brown", Hex: "#a52a2a", Source: SourceCSS},
+	{Name: "burlywood", Hex: "#deb887", Source: SourceCSS},
+	{Name: "cadetblue", Hex: "#5f9ea0", Source: SourceCSS},
+	{Name: "chartreuse", Hex: "#7fff00", Source: SourceCSS},
+	{Name: "chocolate", Hex: "#d2691e", Source: SourceCSS},
+	{Name: "coral", Hex: "#ff7f50", Source: SourceCSS},
+	{Name: "cornflowerblue", Hex: "#6495ed", Source: SourceCSS},
+	{Name: "cornsilk", Hex: "#fff8dc", Source: SourceCSS},
+	{Name: "crimson", Hex: "#dc143c", Source: SourceCSS},
+	{Name: "cyan", Hex: "#00ffff", Source: SourceCSS},
+	{Name: "darkblue", Hex: "#00008b", Source: SourceCSS},
+	{Name: "darkcyan", Hex: "#008b8b", Source: SourceCSS},
+	{Name: "darkgoldenrod", Hex: "#b8860b", Source: SourceCSS},
+	{Name: "darkgray", Hex: "#a9a9a9", Source: SourceCSS},
+	{Name: "darkgreen", Hex: "#006400", Source: SourceCSS},
+	{Name: "darkkhaki", Hex: "#bdb76b", Source: SourceCSS},
+	{Name: "darkmagenta", Hex: "#8b008b", Source: SourceCSS},
+	{Name: "darkolivegreen", Hex: "#556b2f", Source: SourceCSS},
+	{Name: "darkorange", Hex: "#ff8c00", Source: SourceCSS},
+	{Name: "darkorchid", Hex: "#9932cc", Source: SourceCSS},
+	{Name: "darkred", Hex: "#8b0000", Source: SourceCSS},
+	{Name: "darksalmon", Hex: "#e9967a", Source: SourceCSS},
+	{Name: "darkseagreen", Hex: "#8fbc8f", Source: SourceCSS},
+	{Name: "darkslateblue", Hex: "#483d8b", Source: SourceCSS},
+	{Name: "darkslategray", Hex: "#2f4f4f", Source: SourceCSS},
+	{Name: "darkturquoise", Hex: "#00ced1", Source: SourceCSS},
+	{Name: "darkviolet", Hex: "#9400d3", Source: SourceCSS},
+	{Name: "deeppink", Hex: "#ff1493", Source: SourceCSS},
+	{Name: "deepskyblue", Hex: "#00bfff", Source: SourceCSS},
+	{Name: "dimgray", Hex: "#696969", Source: SourceCSS},
+	{Name: "dodgerblue", Hex: "#1e90ff", Source: SourceCSS},
+	{Name: "firebrick", Hex: "#b22222", Source: SourceCSS},
+	{Name: "floralwhite", Hex: "#fffaf0", Source: SourceCSS},
+	{Name: "forestgreen", Hex: "#228b22", Source: SourceCSS},
+	{Name: "fuchsia", Hex: "#ff00ff", Source: SourceCSS},
+	{Name: "gainsboro", Hex: "#dcdcdc", Source: SourceCSS},
+	{Name: "ghostwhite", Hex: "#f8f8ff", Source: SourceCSS},
+	{Name: "gold", Hex: "#ffd700", Source: SourceCSS},
+	{Name: "goldenrod", Hex: "#daa520", Source: SourceCSS},
+	{Name: "gray", Hex: "#808080", Source: SourceCSS},
+	{Name: "green", Hex: "#008000", Source: SourceCSS},
+	{Name: "greenyellow", Hex: "#adff2f", Source: SourceCSS},
+	{Name: "honeydew", Hex: "#f0fff0", Source: SourceCSS},
+	{Name: "hotpink", Hex: "#ff69b4", Source: SourceCSS},
+	{Name: "indianred", Hex: "#cd5c5c", Source: SourceCSS},
+	{Name: "indigo", Hex: "#4b0082", Source: SourceCSS},
+	{Name: "ivory", Hex: "#fffff0", Source: SourceCSS},
+	{Name: "khaki", Hex: "#f0e68c", Source: SourceCSS},
+	{Name: "lavender", Hex: "#e6e6fa", Source: SourceCSS},
+	{Name: "lavenderblush", Hex: "#fff0f5", Source: SourceCSS},
+	{Name: "lawngreen", Hex: "#7cfc00", Source: SourceCSS},
+	{Name: "lemonchiffon", Hex: "#fffacd", Source: SourceCSS},
+	{Name: "lightblue", Hex: "#add8e6", Source: SourceCSS},
+	{Name: "lightcoral", Hex: "#f08080", Source: SourceCSS},
+	{Name: "lightcyan", Hex: "#e0ffff", Source: SourceCSS},
+	{Name: "lightgoldenrodyellow", Hex: "#fafad2", Source: SourceCSS},
+	{Name: "lightgray", Hex: "#d3d3d3", Source: SourceCSS},
+	{Name: "lightgreen", Hex: "#90ee90", Source: SourceCSS},
+	{Name: "lightpink", Hex: "#ffb6c1", Source: SourceCSS},
+	{Name: "lightsalmon", Hex: "#ffa07a", Source: SourceCSS},
+	{Name: "lightseagreen", Hex: "#20b2aa", Source: SourceCSS},
+	{Name: "lightskyblue", Hex: "#87cefa", Source: SourceCSS},
+	{Name: "lightslategray", Hex: "#778899", Source: SourceCSS},
+	{Name: "lightsteelblue", Hex: "#b0c4de", Source: SourceCSS},
+	{Name: "lightyellow", Hex: "#ffffe0", Source: SourceCSS},
+	{Name: "lime", Hex: "#00ff00", Source: SourceCSS},
+	{Name: "limegreen", Hex: "#32cd32", Source: SourceCSS},
+	{Name: "linen", Hex: "#faf0e6", Source: SourceCSS},
+	{Name: "magenta", Hex: "#ff00ff", Source: SourceCSS},
+	{Name: "maroon", Hex: "#800000", Source: SourceCSS},
+	{Name: "mediumaquamarine", Hex: "#66cdaa", Source: SourceCSS},
+	{Name: "mediumblue", Hex: "#0000cd", Source: SourceCSS},
+	{Name: "mediumorchid", Hex: "#ba55d3", Source: SourceCSS},
+	{Name: "mediumpurple", Hex: "#9370db", Source: SourceCSS},
+	{Name: "mediumseagreen", Hex: "#3cb371", Source: SourceCSS},
+	{Name: "mediumslateblue", Hex: "#7b68ee", Source: SourceCSS},
+	{Name: "mediumspringgreen", Hex: "#00fa9a", Source: SourceCSS},
+	{Name: "mediumturquoise", Hex: "#48d1cc", Source: SourceCSS},
+	{Name: "mediumvioletred", Hex: "#c71585", Source: SourceCSS},
+	{Name: "midnightblue", Hex: "#191970", Source: SourceCSS},
+	{Name: "mintcream", Hex: "#f5fffa", Source: SourceCSS},
+	{Name: "mistyrose", Hex: "#ffe4e1", Source: SourceCSS},
+	{Name: "moccasin", Hex: "#ffe4b5", Source: SourceCSS},
+	{Name: "navajowhite", Hex: "#ffdead", Source: SourceCSS},
+	{Name: "navy", Hex: "#000080", Source: SourceCSS},
+	{Name: "oldlace", Hex: "#fdf5e6", Source: SourceCSS},
+	{Name: "olive", Hex: "#808000", Source: SourceCSS},
+	{Name: "olivedrab", Hex: "#6b8e23", Source: SourceCSS},
+	{Name: "orange", Hex: "#ffa500", Source: SourceCSS},
+	{Name: "orangered", Hex: "#ff4500", Source: SourceCSS},
+	{Name: "orchid", Hex: "#da70d6", Source: SourceCSS},
+	{Name: "palegoldenrod", Hex: "#eee8aa", Source: SourceCSS},
+	{Name: "palegreen", Hex: "#98fb98", Source: SourceCSS},
+	{Name: "paleturquoise", Hex: "#afeeee", Source: SourceCSS},
+	{Name: "palevioletred", Hex: "#db7093", Source: SourceCSS},
+	{Name: "papayawhip", Hex: "#ffefd5", Source: SourceCSS},
+	{Name: "peachpuff", Hex: "#ffdab9", Source: SourceCSS},
+	{Name: "peru", Hex: "#cd853f", Source: SourceCSS},
+	{Name: "pink", Hex: "#ffc0cb", Source: SourceCSS},
+	{Name: "plum", Hex: "#dda0dd", Source: SourceCSS},
+	{Name: "powderblue", Hex: "#b0e0e6", Source: SourceCSS},
+	{Name: "purple", Hex: "#800080", Source: SourceCSS},
+	{Name: "rebeccapurple", Hex: "#663399", Source: SourceCSS},
+	{Name: "red", Hex: "#ff0000", Source: SourceCSS},
+	{Name: "rosybrown", Hex: "#bc8f8f", Source: SourceCSS},
+	{Name: "royalblue", Hex: "#4169e1", Source: SourceCSS},
+	{Name: "saddlebrown", Hex: "#8b4513", Source: SourceCSS},
+	{Name: "salmon", Hex: "#fa8072", Source: SourceCSS},
+	{Name: "sandybrown", Hex: "#f4a460", Source: SourceCSS},
+	{Name: "seagreen", Hex: "#2e8b57", Source: SourceCSS},
+	{Name: "seashell", Hex: "#fff5ee", Source: SourceCSS},
+	{Name: "sienna", Hex: "#a0522d", Source: SourceCSS},
+	{Name: "silver", Hex: "#c0c0c0", Source: SourceCSS},
+	{Name: "skyblue", Hex: "#87ceeb", Source: SourceCSS},
+	{Name: "slateblue", Hex: "#6a5acd", Source: SourceCSS},
+	{Name: "slategray", Hex: "#708090", Source: SourceCSS},
+	{Name: "snow", Hex: "#fffafa", Source: SourceCSS},
+	{Name: "springgreen", Hex: "#00ff7f", Source: SourceCSS},
+	{Name: "steelblue", Hex: "#4682b4", Source: SourceCSS},
+	{Name: "tan", Hex: "#d2b48c", Source: SourceCSS},
+	{Name: "teal", Hex: "#008080", Source: SourceCSS},
+	{Name: "thistle", Hex: "#d8bfd8", Source: SourceCSS},
+	{Name: "tomato", Hex: "#ff6347", Source: SourceCSS},
+	{Name: "turquoise", Hex: "#40e0d0", Source: SourceCSS},
+	{Name: "violet", Hex: "#ee82ee", Source: SourceCSS},
+	{Name: "wheat", Hex: "#f5deb3", Source: SourceCSS},
+	{Name: "white", Hex: "#ffffff", Source: SourceCSS},
+	{Name: "whitesmoke", Hex: "#f5f5f5", Source: SourceCSS},
+	{Name: "yellow", Hex: "#ffff00", Source: SourceCSS},
+	{Name: "yellowgreen", Hex: "#9acd32", Source: SourceCSS},
+}
+
+// tailwindColors is the Tailwind CSS v3 default palette.
+var tailwindColors = []NamedColor{
+	{Name: "slate-50", Hex: "#f8fafc", Source: SourceTailwind},
+	{Name: "slate-100", Hex: "#f1f5f9", Source: SourceTailwind},
+	{Name: "slate-200", Hex: "#e2e8f0", Source: SourceTailwind},
+	{Name: "slate-300", Hex: "#cbd5e1", Source: SourceTailwind},
+	{Name: "slate-400", Hex: "#94a3b8", Source: SourceTailwind},
+	{Name: "slate-500", Hex: "#64748b", Source: SourceTailwind},
+	{Name: "slate-600", Hex: "#475569", Source: SourceTailwind},
+	{Name: "slate-700", Hex: "#334155", Source: SourceTailwind},
+	{Name: "slate-800", Hex: "#1e293b", Source: SourceTailwind},
+	{Name: "slate-900", Hex: "#0f172a", Source: SourceTailwind},
+	{Name: "slate-950", Hex: "#020617", Source: SourceTailwind},
+	{Name: "gray-50", Hex: "#f9fafb", Source: SourceTailwind},
+	{Name: "gray-100", Hex: "#f3f4f6", Source: SourceTailwind},
+	{Name: "gray-200", Hex: "#e5e7eb", Source: SourceTailwind},
+	{Name: "gray-300", Hex: "#d1d5db", Source: SourceTailwind},
+	{Name: "gray-400", Hex: "#9ca3af", Source: SourceTailwind},
+	{Name: "gray-500", Hex: "#6b7280", Source: SourceTailwind},
+	{Name: "gray-600", Hex: "#4b5563", Source: SourceTailwind},
+	{Name: "gray-700", Hex: "#374151", Source: SourceTailwind},
+	{Name: "gray-800", Hex: "#1f2937", Source: SourceTailwind},
+	{Name: "gray-900", Hex: "#111827", Source: SourceTailwind},
+	{Name: "gray-950", Hex: "#030712", Source: SourceTailwind},
+	{Name: "zinc-50", Hex: "#fafafa", Source: SourceTailwind},
+	{Name: "zinc-100", Hex: "#f4f4f5", Source: SourceTailwind},
+	{Name: "zinc-200", Hex: "#e4e4e7", Source: SourceTailwind},
+	{Name: "zinc-300", Hex: "#d4d4d8", Source: SourceTailwind},
+	{Name: "zinc-400", Hex: "#a1a1aa", Source: SourceTailwind},
+	{Name: "zinc-500", Hex: "#71717a", Source: SourceTailwind},
+	{Name: "zinc-600", Hex: "#52525b", Source: SourceTailwind},
+	{Name: "zinc-700", Hex: "#3f3f46", Source: SourceTailwind},
+	{Name: "zinc-800", Hex: "#27272a", Source: SourceTailwind},
+	{Name: "zinc-900", Hex: "#18181b", Source: SourceTailwind},
+	{Name: "zinc-950", Hex: "#09090b", Source: SourceTailwind},
+	{Name: "neutral-50", Hex: "#fafafa", Source: SourceTailwind},
+	{Name: "neutral-100", Hex: "#f5f5f5", Source: SourceTailwind},
+	{Name: "neutral-200", Hex: "#e5e5e5", Source: SourceTailwind},
+	{Name: "neutral-300", Hex: "#d4d4d4", Source: SourceTailwind},
+	{Name: "neutral-400", Hex: "#a3a3a3", Source: SourceTailwind},
+	{Name: "neutral-500", Hex: "#737373", Source: SourceTailwind},
+	{Name: "neutral-600", Hex: "#525252", Source: SourceTailwind},
+	{Name: "neutral-700", Hex: "#404040", Source: SourceTailwind},
+	{Name: "neutral-800", Hex: "#262626", Source: SourceTailwind},
+	{Name: "neutral-900", Hex: "#171717", Source: SourceTailwind},
+	{Name: "neutral-950", Hex: "#0a0a0a", Source: SourceTailwind},
+	{Name: "stone-50", Hex: "#fafaf9", Source: SourceTailwind},
+	{Name: "stone-100", Hex: "#f5f5f4", Source: SourceTailwind},
+	{Name: "stone-200", Hex: "#e7e5e4", Source: SourceTailwind},
+	{Name: "stone-300", Hex: "#d6d3d1", Source: SourceTailwind},
+	{Name: "stone-400", Hex: "#a8a29e", Source: SourceTailwind},
+	{Name: "stone-500", Hex: "#78716c", Source: SourceTailwind},
+	{Name: "stone-600", Hex: "#57534e", Source: SourceTailwind},
+	{Name: "stone-700", Hex: "#44403c", Source: SourceTailwind},
+	{Name: "stone-800", Hex: "#292524", Source: SourceTailwind},
+	{Name: "stone-900", Hex: "#1c1917", Source: SourceTailwind},
+	{Name: "stone-950", Hex: "#0c0a09", Source: SourceTailwind},
+	{Name: "red-50", Hex: "#fef2f2", Source: SourceTailwind},
+	{Name: "red-100", Hex: "#fee2e2", Source: SourceTailwind},
+	{Name: "red-200", Hex: "#fecaca", Source: SourceTailwind},
+	{Name: "red-300", Hex: "#fca5a5", Source: SourceTailwind},
+	{Name: "red-400", Hex: "#f87171", Source: SourceTailwind},
+	{Name: "red-500", Hex: "#ef4444", Source: SourceTailwind},
+	{Name: "red-600", Hex: "#dc2626", Source: SourceTailwind},
+	{Name: "red-700", Hex: "#b91c1c", Source: SourceTailwind},
+	{Name: "red-800", Hex: "#991b1b", Source: SourceTailwind},
+	{Name: "red-900", Hex: "#7f1d1d", Source: SourceTailwind},
+	{Name: "red-950", Hex: "#450a0a", Source: SourceTailwind},
+	{Name: "orange-50", Hex: "#fff7ed", Source: SourceTailwind},
+	{Name: "orange-100", Hex: "#ffedd5", Source: SourceTailwind},
+	{Name: "orange-200", Hex: "#fed7aa", Source: SourceTailwind},
+	{Name: "orange-300", Hex: "#fdba74", Source: SourceTailwind},
+	{Name: "orange-400", Hex: "#fb923c", Source: SourceTailwind},
+	{Name: "orange-500", Hex: "#f97316", Source: SourceTailwind},
+	{Name: "orange-600", Hex: "#ea580c", Source: SourceTailwind},
+	{Name: "orange-700", Hex: "#c2410c", Source: SourceTailwind},
+	{Name: "orange-800", Hex: "#9a3412", Source: SourceTailwind},
+	{Name: "orange-900", Hex: "#7c2d12", Source: SourceTailwind},
+	{Name: "orange-950", Hex: "#431407", Source: SourceTailwind},
+	{Name: "amber-50", Hex: "#fffbeb", Source: SourceTailwind},
+	{Name: "amber-100", Hex: "#fef3c7", Source: SourceTailwind},
+	{Name: "amber-200", Hex: "#fde68a", Source: SourceTailwind},
+	{Name: "amber-300", Hex: "#fcd34d", Source: SourceTailwind},
+	{Name: "amber-400", Hex: "#fbbf24", Source: SourceTailwind},
+	{Name: "amber-500", Hex: "#f59e0b", Source: SourceTailwind},
+	{Name: "amber-600", Hex: "#d97706", Source: SourceTailwind},
+	{Name: "amber-700", Hex: "#b45309", Source: SourceTailwind},
+	{Name: "amber-800", Hex: "#92400e", Source: SourceTailwind},
+	{Name: "amber-900", Hex: "#78350f", Source: SourceTailwind},
+	{Name: "amber-950", Hex: "#451a03", Source: SourceTailwind},
+	{Name: "yellow-50", Hex: "#fefce8", Source: SourceTailwind},
+	{Name: "yellow-100", Hex: "#fef9c3", Source: SourceTailwind},
+	{Name: "yellow-200", Hex: "#fef08a", Source: SourceTailwind},
+	{Name: "yellow-300", Hex: "#fde047", Source: SourceTailwind},
+	{Name: "yellow-400", Hex: "#facc15", Source: SourceTailwind},
+	{Name: "yellow-500", Hex: "#eab308", Source: SourceTailwind},
+	{Name: "yellow-600", Hex: "#ca8a04", Source: SourceTailwind},
+	{Name: "yellow-700", Hex: "#a16207", Source: SourceTailwind},
+	{Name: "yellow-800", Hex: "#854d0e", Source: SourceTailwind},
+	{Name: "yellow-900", Hex: "#713f12", Source: SourceTailwind},
+	{Name: "yellow-950", Hex: "#422006", Source: SourceTailwind},
+	{Name: "lime-50", Hex: "#f7fee7", Source: SourceTailwind},
+	{Name: "lime-100", Hex: "#ecfccb", Source: SourceTailwind},
+	{Name: "lime-200", Hex: "#d9f99d", Source: SourceTailwind},
+	{Name: "lime-300", Hex: "#bef264", Source: SourceTailwind},
+	{Name: "lime-400", Hex: "#a3e635", Source: SourceTailwind},
+	{Name: "lime-500", Hex: "#84cc16", Source: SourceTailwind},
+	{Name: "lime-600", Hex: "#65a30d", Source: SourceTailwind},
+	{Name: "lime-700", Hex: "#4d7c0f", Source: SourceTailwind},
+	{Name: "lime-800", Hex: "#3f6212", Source: SourceTailwind},
+	{Name: "lime-900", Hex: "#365314", Source: SourceTailwind},
+	{Name: "lime-950", Hex: "#1a2e05", Source: SourceTailwind},
+	{Name: "green-50", Hex: "#f0fdf4", Source: SourceTailwind},
+	{Name: "green-100", Hex: "#dcfce7", Source: SourceTailwind},
+	{Name: "green-200", Hex: "#bbf7d0", Source: SourceTailwind},
+	{Name: "green-300", Hex: "#86efac", Source: SourceTailwind},
+	{Name: "green-400", Hex: "#4ade80", Source: SourceTailwind},
+	{Name: "green-500", Hex: "#22c55e", Source: SourceTailwind},
+	{Name: "green-600", Hex: "#16a34a", Source: SourceTailwind},
+	{Name: "green-700", Hex: "#15803d", Source: SourceTailwind},
+	{Name: "green-800", Hex: "#166534", Source: SourceTailwind},
+	{Name: "green-900", Hex: "#14532d", Source: SourceTailwind},
+	{Name: "green-950", Hex: "#052e16", Source: SourceTailwind},
+	{Name: "emerald-50", Hex: "#ecfdf5", Source: SourceTailwind},
+	{Name: "emerald-100", Hex: "#d1fae5", Source: SourceTailwind},
+	{Name: "emerald-200", Hex: "#a7f3d0", Source: SourceTailwind},
+	{Name: "emerald-300", Hex: "#6ee7b7", Source: SourceTailwind},
+	{Name: "emerald-400", Hex: "#34d399", Source: SourceTailwind},
+	{Name: "emerald-500", Hex: "#10b981", Source: SourceTailwind},
+	{Name: "emerald-600", Hex: "#059669", Source: SourceTailwind},
+	{Name: "emerald-700", Hex: "#047857", Source: SourceTailwind},
+	{Name: "emerald-800", Hex: "#065f46", Source: SourceTailwind},
+	{Name: "emerald-900", Hex: "#064e3b", Source: SourceTailwind},
+	{Name: "emerald-950", Hex: "#022c22", Source: SourceTailwind},
+	{Name: "teal-50", Hex: "#f0fdfa", Source: SourceTailwind},
+	{Name: "teal-100", Hex: "#ccfbf1", Source: SourceTailwind},
+	{Name: "teal-200", Hex: "#99f6e4", Source: SourceTailwind},
+	{Name: "teal-300", Hex: "#5eead4", Source: SourceTailwind},
+	{Name: "teal-400", Hex: "#2dd4bf", Source: SourceTailwind},
+	{Name: "teal-500", Hex: "#14b8a6", Source: SourceTailwind},
+	{Name: "teal-600", Hex: "#0d9488", Source: SourceTailwind},
+	{Name: "teal-700", Hex: "#0f766e", Source: SourceTailwind},
+	{Name: "teal-800", Hex: "#115e59", Source: SourceTailwind},
+	{Name: "teal-900", Hex: "#134e4a", Source: SourceTailwind},
+	{Name: "teal-950", Hex: "#042f2e", Source: SourceTailwind},
+	{Name: "cyan-50", Hex: "#ecfeff", Source: SourceTailwind},
+	{Name: "cyan-100", Hex: "#cffafe", Source: SourceTailwind},
+	{Name: "cyan-200", Hex: "#a5f3fc", Source: SourceTailwind},
+	{Name: "cyan-300", Hex: "#67e8f9", Source: SourceTailwind},
+	{Name: "cyan-400", Hex: "#22d3ee", Source: SourceTailwind},
+	{Name: "cyan-500", Hex: "#06b6d4", Source: SourceTailwind},
+	{Name: "cyan-600", Hex: "#0891b2", Source: SourceTailwind},
+	{Name: "cyan-700", Hex: "#0e7490", Source: SourceTailwind},
+	{Name: "cyan-800", Hex: "#155e75", Source: SourceTailwind},
+	{Name: "cyan-900", Hex: "#164e63", Source: SourceTailwind},
+	{Name: "cyan-950", Hex: "#083344", Source: SourceTailwind},
+	{Name: "sky-50", Hex: "#f0f9ff", Source: SourceTailwind},
+	{Name: "sky-100", Hex: "#e0f2fe", Source: SourceTailwind},
+	{Name: "sky-200", Hex: "#bae6fd", Source: SourceTailwind},
+	{Name: "sky-300", Hex: "#7dd3fc", Source: SourceTailwind},
+	{Name: "sky-400", Hex: "#38bdf8", Source: SourceTailwind},
+	{Name: "sky-500", Hex: "#0ea5e9", Source: SourceTailwind},
+	{Name: "sky-600", Hex: "#0284c7", Source: SourceTailwind},
+	{Name: "sky-700", Hex: "#0369a1", Source: SourceTailwind},
+	{Name: "sky-800", Hex: "#075985", Source: SourceTailwind},
+	{Name: "sky-900", Hex: "#0c4a6e", Source: SourceTailwind},
+	{Name: "sky-950", Hex: "#082f49", Source: SourceTailwind},
+	{Name: "blue-50", Hex: "#eff6ff", Source: SourceTailwind},
+	{Name: "blue-100", Hex: "#dbeafe", Source: SourceTailwind},
+	{Name: "blue-200", Hex: "#bfdbfe", Source: SourceTailwind},
+	{Name: "blue-300", Hex: "#93c5fd", Source: SourceTailwind},
+	{Name: "blue-400", Hex: "#60a5fa", Source: SourceTailwind},
+	{Name: "blue-500", Hex: "#3b82f6", Source: SourceTailwind},
+	{Name: "blue-600", Hex: "#2563eb", Source: SourceTailwind},
+	{Name: "blue-700", Hex: "#1d4ed8", Source: SourceTailwind},
+	{Name: "blue-800", Hex: "#1e40af", Source: SourceTailwind},
+	{Name: "blue-900", Hex: "#1e3a8a", Source: SourceTailwind},
+	{Name: "blue-950", Hex: "#172554", Source: SourceTailwind},
+	{Name: "indigo-50", Hex: "#eef2ff", Source: SourceTailwind},
+	{Name: "indigo-100", Hex: "#e0e7ff", Source: SourceTailwind},
+	{Name: "indigo-200", Hex: "#c7d2fe", Source: SourceTailwind},
+	{Name: "indigo-300", Hex: "#a5b4fc", Source: SourceTailwind},
+	{Name: "indigo-400", Hex: "#818cf8", Source: SourceTailwind},
+	{Name: "indigo-500", Hex: "#6366f1", Source: SourceTailwind},
+	{Name: "indigo-600", Hex: "#4f46e5", Source: SourceTailwind},
+	{Name: "indigo-700", Hex: "#4338ca", Source: SourceTailwind},
+	{Name: "indigo-800", Hex: "#3730a3", Source: SourceTailwind},
+	{Name: "indigo-900", Hex: "#312e81", Source: SourceTailwind},
+	{Name: "indigo-950", Hex: "#1e1b4b", Source: SourceTailwind},
+	{Name: "violet-50", Hex: "#f5f3ff", Source: SourceTailwind},
+	{Name: "violet-100", Hex: "#ede9fe", Source: SourceTailwind},
+	{Name: "violet-200", Hex: "#ddd6fe", Source: SourceTailwind},
+	{Name: "violet-300", Hex: "#c4b5fd", Source: SourceTailwind},
+	{Name: "violet-400", Hex: "#a78bfa", Source: SourceTailwind},
+	{Name: "violet-500", Hex: "#8b5cf6", Source: SourceTailwind},
+	{Name: "violet-600", Hex: "#7c3aed", Source: SourceTailwind},
+	{Name: "violet-700", Hex: "#6d28d9", Source: SourceTailwind},
+	{Name: "violet-800", Hex: "#5b21b6", Source: SourceTailwind},
+	{Name: "violet-900", Hex: "#4c1d95", Source: SourceTailwind},
+	{Name: "violet-950", Hex: "#2e1065", Source: SourceTailwind},
+	{Name: "purple-50", Hex: "#faf5ff", Source: SourceTailwind},
+	{Name: "purple-100", Hex: "#f3e8ff", Source: SourceTailwind},
+	{Name: "purple-200", Hex: "#e9d5ff", Source: SourceTailwind},
+	{Name: "purple-300", Hex: "#d8b4fe", Source: SourceTailwind},
+	{Name: "purple-400", Hex: "#c084fc", Source: SourceTailwind},
+	{Name: "purple-500", Hex: "#a855f7", Source: SourceTailwind},
+	{Name: "purple-600", Hex: "#9333ea", Source: SourceTailwind},
+	{Name: "purple-700", Hex: "#7e22ce", Source: SourceTailwind},
+	{Name: "purple-800", Hex: "#6b21a8", Source: SourceTailwind},
+	{Name: "purple-900", Hex: "#581c87", Source: SourceTailwind},
+	{Name: "purple-950", Hex: "#3b0764", Source: SourceTailwind},
+	{Name: "fuchsia-50", Hex: "#fdf4ff", Source: SourceTailwind},
+	{Name: "fuchsia-100", Hex: "#fae8ff", Source: SourceTailwind},
+	{Name: "fuchsia-200", Hex: "#f5d0fe", Source: SourceTailwind},
+	{Name: "fuchsia-300", Hex: "#f0abfc", Source: SourceTailwind},
+	{Name: "fuchsia-400", Hex: "#e879f9", Source: SourceTailwind},
+	{Name: "fuchsia-500", Hex: "#d946ef", Source: SourceTailwind},
+	{Name: "fuchsia-600", Hex: "#c026d3", Source: SourceTailwind},
+	{Name: "fuchsia-700", Hex: "#a21caf", Source: SourceTailwind},
+	{Name: "fuchsia-800", Hex: "#86198f", Source: SourceTailwind},
+	{Name: "fuchsia-900", Hex: "#701a75", Source: SourceTailwind},
+	{Name: "fuchsia-950", Hex: "#4a044e", Source: SourceTailwind},
+	{Name: "pink-50", Hex: "#fdf2f8", Source: SourceTailwind},
+	{Name: "pink-100", Hex: "#fce7f3", Source: SourceTailwind},
+	{Name: "pink-200", Hex: "#fbcfe8", Source: SourceTailwind},
+	{Name: "pink-300", Hex: "#f9a8d4", Source: SourceTailwind},
+	{Name: "pink-400", Hex: "#f472b6", Source: SourceTailwind},
+	{Name: "pink-500", Hex: "#ec4899", Source: SourceTailwind},
+	{Name: "pink-600", Hex: "#db2777", Source: SourceTailwind},
+	{Name: "pink-700", Hex: "#be185d", Source: SourceTailwind},
+	{Name: "pink-800", Hex: "#9d174d", Source: SourceTailwind},
+	{Name: "pink-900", Hex: "#831843", Source: SourceTailwind},
+	{Name: "pink-950", Hex: "#500724", Source: SourceTailwind},
+	{Name: "rose-50", Hex: "#fff1f2", Source: SourceTailwind},
+	{Name: "rose-100", Hex: "#ffe4e6", Source: SourceTailwind},
+	{Name: "rose-200", Hex: "#fecdd3", Source: SourceTailwind},
+	{Name: "rose-300", Hex: "#fda4af", Source: SourceTailwind},
+	{Name: "rose-400", Hex: "#fb7185", Source: SourceTailwind},
+	{Name: "rose-500", Hex: "#f43f5e", Source: SourceTailwind},
+	{Name: "rose-600", Hex: "#e11d48", Source: SourceTailwind},
+	{Name: "rose-700", Hex: "#be123c", Source: SourceTailwind},
+	{Name: "rose-800", Hex: "#9f1239", Source: SourceTailwind},
+	{Name: "rose-900", Hex: "#881337", Source: SourceTailwind},
+	{Name: "rose-950", Hex: "#4c0519", Source: SourceTailwind},
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wp_viewporter"
 	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/imagecopy"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
@@ -62,6 +63,7 @@ type Picker struct {
 	keyboard   *client.Keyboard
 	layerShell *wlr_layer_shell.ZwlrLayerShellV1
 	screencopy *wlr_screencopy.ZwlrScreencopyManagerV1
+	imageCopy  *imagecopy.Capturer
 	viewporter *wp_viewporter.WpViewporter
 
 	shortcutsInhibitMgr *keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitManagerV1
@@ -76,6 +78,9 @@ type Picker struct {
 	running     bool
 	pickedColor *Color
 	err         error
+
+	multi  bool
+	picked []Color
 }
 
 func New(config Config) *Picker {
@@ -86,46 +91,63 @@ func New(config Config) *Picker {
 }
 
 func (p *Picker) Run() (*Color, error) {
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+	return p.pickedColor, nil
+}
+
+// RunMulti keeps the picker open so several colors can be collected in one
+// session. It returns them in pick order, or nil if none were picked.
+func (p *Picker) RunMulti() ([]Color, error) {
+	p.multi = true
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+	return p.picked, nil
+}
+
+func (p *Picker) run() error {
 	if err := p.connect(); err != nil {
-		return nil, fmt.Errorf("wayland connect: %w", err)
+		return fmt.Errorf("wayland connect: %w", err)
 	}
 	defer p.cleanup()
 
 	if err := p.setupRegistry(); err != nil {
-		return nil, fmt.Errorf("registry setup: %w", err)
+		return fmt.Errorf("registry setup: %w", err)
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
-	if p.screencopy == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+	if p.screencopy == nil && !p.imageCopy.Available() {
+		return fmt.Errorf("compositor supports neither ext-image-copy-capture-v1 nor wlr-screencopy-unstable-v1")
 	}
 
 	if p.layerShell == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
+		return fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
 	}
 
 	if p.seat == nil {
-		return nil, fmt.Errorf("no seat available")
+		return fmt.Errorf("no seat available")
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
 	// Extra roundtrip to ensure pointer/keyboard from seat capabilities are registered
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip after seat: %w", err)
+		return fmt.Errorf("roundtrip after seat: %w", err)
 	}
 
 	if err := p.createSurfaces(); err != nil {
-		return nil, fmt.Errorf("create surfaces: %w", err)
+		return fmt.Errorf("create surfaces: %w", err)
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
 	p.running = true
@@ -139,14 +161,15 @@ func (p *Picker) Run() (*Color, error) {
 		p.checkDone()
 	}
 
-	if p.err != nil {
-		return nil, p.err
-	}
-
-	return p.pickedColor, nil
+	return p.err
 }
 
 func (p *Picker) checkDone() {
+	if p.multi {
+		p.checkMultiDone()
+		return
+	}
+
 	for _, ls := range p.surfaces {
 		picked, cancelled := ls.state.IsDone()
 		switch {
@@ -164,6 +187,21 @@ func (p *Picker) checkDone() {
 	}
 }
 
+func (p *Picker) checkMultiDone() {
+	for _, ls := range p.surfaces {
+		if color, ok := ls.state.TakePick(); ok {
+			p.picked = append(p.picked, color)
+			for _, other := range p.surfaces {
+				other.state.SetSwatches(p.picked)
+				other.needsRedraw = true
+			}
+		}
+		if ls.state.IsFinished() {
+			p.running = false
+		}
+	}
+}
+
 func (p *Picker) flushRedraws() {
 	for _, ls := range p.surfaces {
 		if !ls.needsRedraw {
@@ -180,6 +218,7 @@ func (p *Picker) connect() error {
 	}
 	p.display = display
 	p.ctx = display.Context()
+	p.imageCopy = imagecopy.New(p.ctx)
 	return nil
 }
 
@@ -208,6 +247,10 @@ func (p *Picker) setupRegistry() error {
 }
 
 func (p *Picker) handleGlobal(e client.RegistryGlobalEvent) {
+	if p.imageCopy.HandleGlobal(p.registry, e) {
+		return
+	}
+
 	switch e.Interface {
 	case client.CompositorInterfaceName:
 		compositor := client.NewCompositor(p.ctx)
@@ -354,6 +397,7 @@ func (p *Picker) createLayerSurface(output *Output) (*LayerSurface, error) {
 		layerSurf: layerSurf,
 		hidden:    true, // Start hidden, will show overlay when pointer enters
 	}
+	ls.state.SetMulti(p.multi)
 
 	if p.viewporter != nil {
 		vp, err := p.viewporter.GetViewport(surface)
@@ -440,6 +484,18 @@ func (p *Picker) ensureShortcutsInhibitor(ls *LayerSurface) {
 }
 
 func (p *Picker) captureForSurface(ls *LayerSurface) {
+	if p.imageCopy.Available() {
+		p.imageCopy.CaptureOutputAsync(p.shm, ls.output.wlOutput, false, func(buf *ShmBuffer, err error) {
+			if err != nil {
+				log.Error("image copy capture failed", "err", err)
+				return
+			}
+			ls.state.OnCaptureReady(buf)
+			p.onCaptured(ls)
+		})
+		return
+	}
+
 	frame, err := p.screencopy.CaptureOutput(0, ls.output.wlOutput)
 	if err != nil {
 		return
@@ -498,16 +554,8 @@ func (p *Picker) captureForSurface(ls *LayerSurface) {
 			}
 		}
 
-		logicalW, _ := ls.state.LogicalSize()
-		screenBuf = ls.state.ScreenBuffer()
-		if logicalW > 0 && screenBuf != nil {
-			ls.output.fractionalScale = float64(screenBuf.Width) / float64(logicalW)
-		}
-
-		scale := p.computeSurfaceScale(ls)
-		ls.state.SetScale(scale)
 		frame.Destroy()
-		p.redrawSurface(ls)
+		p.onCaptured(ls)
 	})
 
 	frame.SetFailedHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1FailedEvent) {
@@ -515,6 +563,17 @@ func (p *Picker) captureForSurface(ls *LayerSurface) {
 	})
 }
 
+func (p *Picker) onCaptured(ls *LayerSurface) {
+	logicalW, _ := ls.state.LogicalSize()
+	screenBuf := ls.state.ScreenBuffer()
+	if logicalW > 0 && screenBuf != nil {
+		ls.output.fractionalScale = float64(screenBuf.Width) / float64(logicalW)
+	}
+
+	ls.state.SetScale(p.computeSurfaceScale(ls))
+	p.redrawSurface(ls)
+}
+
 func (p *Picker) redrawSurface(ls *LayerSurface) {
 	slot := ls.state.FrontIndex()
 	if ls.slotBusy[slot] {
@@ -730,6 +789,10 @@ func (p *Picker) cleanup() {
 		p.screencopy.Destroy()
 	}
 
+	if p.imageCopy != nil {
+		p.imageCopy.Destroy()
+	}
+
 	if p.pointer != nil {
 		p.pointer.Release()
 	}
@@ -46,6 +46,12 @@ type SurfaceState struct {
 	readyForDisplay bool
 	colorPicked     bool
 	cancelled       bool
+
+	// In multi mode a click adds a color and Enter, Escape or a right
+	// click ends the session; swatches shows what was collected so far.
+	multi    bool
+	finished bool
+	swatches []Color
 }
 
 func NewSurfaceState(format OutputFormat, lowercase bool) *SurfaceState {
@@ -157,6 +163,28 @@ func (s *SurfaceState) OnScreencopyReady() {
 	s.readyForDisplay = true
 }
 
+// OnCaptureReady adopts a frame from ext-image-copy-capture, which arrives
+// already upright and 32-bit.
+func (s *SurfaceState) OnCaptureReady(buf *ShmBuffer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.screenBuf != nil {
+		s.screenBuf.Close()
+	}
+	s.screenBuf = buf
+	s.screenFormat = buf.Format
+	s.yInverted = false
+
+	if s.logicalW == 0 || s.logicalH == 0 {
+		return
+	}
+
+	s.recomputeScale()
+	s.ensureRenderBuffers()
+	s.readyForDisplay = true
+}
+
 func (s *SurfaceState) OnLayerConfigure(width, height int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -235,6 +263,10 @@ func (s *SurfaceState) OnPointerButton(button, state uint32) {
 		if s.readyForDisplay && s.screenBuf != nil {
 			s.colorPicked = true
 		}
+	case 0x111: // BTN_RIGHT
+		if s.multi {
+			s.finished = true
+		}
 	}
 }
 
@@ -246,16 +278,50 @@ func (s *SurfaceState) OnKey(key, state uint32) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	switch key {
-	case 1: // KEY_ESC
+	switch {
+	case s.multi && (key == 1 || key == 28): // KEY_ESC, KEY_ENTER
+		s.finished = true
+	case key == 1:
 		s.cancelled = true
-	case 28: // KEY_ENTER
+	case key == 28:
 		if s.readyForDisplay && s.screenBuf != nil {
 			s.colorPicked = true
 		}
 	}
 }
 
+func (s *SurfaceState) SetMulti(multi bool) {
+	s.mu.Lock()
+	s.multi = multi
+	s.mu.Unlock()
+}
+
+// TakePick returns the color under the pointer if one was picked since the
+// last call, and re-arms picking.
+func (s *SurfaceState) TakePick() (Color, bool) {
+	s.mu.Lock()
+	picked := s.colorPicked
+	s.colorPicked = false
+	s.mu.Unlock()
+
+	if !picked {
+		return Color{}, false
+	}
+	return s.PickColor()
+}
+
+func (s *SurfaceState) IsFinished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finished
+}
+
+func (s *SurfaceState) SetSwatches(colors []Color) {
+	s.mu.Lock()
+	s.swatches = colors
+	s.mu.Unlock()
+}
+
 func (s *SurfaceState) IsDone() (picked, cancelled bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -321,10 +387,29 @@ func (s *SurfaceState) Redraw() *ShmBuffer {
 	)
 
 	drawColorPreview(dst.Data(), dst.Stride, dst.Width, dst.Height, px, py, picked, s.displayFormat, s.lowercase, s.screenFormat)
+	drawSwatches(dst.Data(), dst.Stride, dst.Width, dst.Height, s.swatches, int(math.Round(24*s.scaleX)), s.screenFormat)
 
 	return dst
 }
 
+// drawSwatches lays out the colors collected in multi mode along the top
+// left edge, wrapping to further rows as needed.
+func drawSwatches(data []byte, stride, width, height int, colors []Color, size int, format PixelFormat) {
+	if len(colors) == 0 || size <= 0 {
+		return
+	}
+	gap := max(size/6, 1)
+	perRow := max((width-gap)/(size+gap), 1)
+	border := Color{R: 255, G: 255, B: 255, A: 255}
+
+	for i, c := range colors {
+		x := gap + (i%perRow)*(size+gap)
+		y := gap + (i/perRow)*(size+gap)
+		drawFilledRect(data, stride, width, height, x, y, size, size, border, format)
+		drawFilledRect(data, stride, width, height, x+1, y+1, size-2, size-2, c, format)
+	}
+}
+
 // RedrawScreenOnly renders just the screenshot without any overlay (magnifier, preview).
 // Used for when pointer leaves the surface.
 func (s *SurfaceState) RedrawScreenOnly() *ShmBuffer {
@@ -1044,6 +1129,104 @@ var fontGlyphs = map[rune][fontH]uint8{
 		0b00000000,
 		0b00000000,
 	},
+	'O': {
+		0b00111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'W': {
+		0b01100011,
+		0b01100011,
+		0b01100011,
+		0b01100011,
+		0b01101011,
+		0b01101011,
+		0b01111111,
+		0b01110111,
+		0b01100011,
+		0b01000001,
+		0b00000000,
+		0b00000000,
+	},
+	'P': {
+		0b01111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01111100,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b00000000,
+		0b00000000,
+	},
+	'I': {
+		0b00111100,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'N': {
+		0b01100110,
+		0b01100110,
+		0b01110110,
+		0b01110110,
+		0b01111110,
+		0b01101110,
+		0b01101110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b00000000,
+		0b00000000,
+	},
+	'.': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+	},
+	'-': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b01111110,
+		0b01111110,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+	},
 }
 
 func drawColorPreview(data []byte, stride, width, height int, cx, cy int, c Color, format OutputFormat, lowercase bool, pixelFormat PixelFormat) {
@@ -1103,6 +1286,10 @@ func formatColorForPreview(c Color, format OutputFormat, lowercase bool) string
 		return strings.ToUpper(c.ToHSV())
 	case FormatCMYK:
 		return strings.ToUpper(c.ToCMYK())
+	case FormatOKLCH, FormatOKLab, FormatLab, FormatLCh, FormatHWB, FormatP3:
+		return strings.ToUpper(format.String()) + " " + c.Format(format, false, "")
+	case FormatLinear:
+		return "LIN " + c.Format(format, false, "")
 	default:
 		if lowercase {
 			return c.ToHex(true)
@@ -312,3 +312,25 @@ func TestBlendColors(t *testing.T) {
 	result = blendColors(bg, fg, 2.0)
 	assert.Equal(t, fg.R, result.R)
 }
+
+func TestSurfaceState_OnCaptureReady(t *testing.T) {
+	s := NewSurfaceState(FormatHex, false)
+
+	buf, err := CreateShmBuffer(200, 100, 800)
+	assert.NoError(t, err)
+	buf.Format = FormatXBGR8888
+
+	s.OnCaptureReady(buf)
+	assert.False(t, s.IsReady(), "not ready until the layer surface is configured")
+
+	assert.NoError(t, s.OnLayerConfigure(100, 50))
+	replacement, err := CreateShmBuffer(200, 100, 800)
+	assert.NoError(t, err)
+	replacement.Format = FormatXRGB8888
+	s.OnCaptureReady(replacement)
+
+	assert.True(t, s.IsReady())
+	assert.Equal(t, FormatXRGB8888, s.ScreenFormat())
+	assert.Same(t, replacement, s.ScreenBuffer())
+	s.Destroy()
+}
@@ -189,7 +189,9 @@ func getLstar(hex string) float64 {
 	rgb := HexToRGB(hex)
 	col := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}
 	L, _, _ := col.Lab()
-	return L * 100.0 // go-colorful uses 0-1, we need 0-100 for DPS
+	// go-colorful uses 0-1, we need 0-100 for DPS. Black can come out a
+	// hair below zero, which would make the fractional powers NaN.
+	return math.Max(L*100.0, 0)
 }
 
 // Lab to hex, clamping if needed
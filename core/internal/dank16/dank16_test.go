@@ -458,6 +458,9 @@ func TestDeltaPhiStar(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := DeltaPhiStar(tt.fg, tt.bg, tt.negativePolarity)
+			if math.IsNaN(result) {
+				t.Fatalf("DeltaPhiStar(%s, %s, %v) = NaN", tt.fg, tt.bg, tt.negativePolarity)
+			}
 			if result < tt.minExpected {
 				t.Errorf("DeltaPhiStar(%s, %s, %v) = %f, expected >= %f",
 					tt.fg, tt.bg, tt.negativePolarity, result, tt.minExpected)
@@ -466,6 +469,14 @@ func TestDeltaPhiStar(t *testing.T) {
 	}
 }
 
+func TestGetLstarBlack(t *testing.T) {
+	// go-colorful returns a tiny negative L for pure black; unclamped it
+	// turns every DPS contrast against black into NaN.
+	if L := getLstar("#000000"); L != 0 {
+		t.Errorf("getLstar(#000000) = %v, expected 0", L)
+	}
+}
+
 func TestDeltaPhiStarContrast(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,110 @@
+package material
+
+import "math"
+
+// viewingConditions describes the environment a color is seen in. Only the
+// default sRGB conditions are used: a 200 lux room, mid-gray background
+// and average surround.
+type viewingConditions struct {
+	n, aw, nbb, ncb, c, nc float64
+	rgbD                   [3]float64
+	fl, fLRoot, z          float64
+}
+
+var xyzToCAM16RGB = [3][3]float64{
+	{0.401288, 0.650173, -0.051461},
+	{-0.250268, 1.204414, 0.045854},
+	{-0.002079, 0.048952, 0.953127},
+}
+
+var defaultViewingConditions = makeViewingConditions(whitePointD65, 200/math.Pi*yFromLStar(50)/100, 50, 2, false)
+
+func makeViewingConditions(whitePoint [3]float64, adaptingLuminance, backgroundLStar, surround float64, discountingIlluminant bool) viewingConditions {
+	backgroundLStar = math.Max(0.1, backgroundLStar)
+	rgbW := matrixMultiply(whitePoint, xyzToCAM16RGB)
+
+	f := 0.8 + surround/10
+	var c float64
+	if f >= 0.9 {
+		c = lerp(0.59, 0.69, (f-0.9)*10)
+	} else {
+		c = lerp(0.525, 0.59, (f-0.8)*10)
+	}
+	d := 1.0
+	if !discountingIlluminant {
+		d = f * (1 - (1/3.6)*math.Exp((-adaptingLuminance-42)/92))
+	}
+	d = clampFloat(0, 1, d)
+
+	var rgbD [3]float64
+	for i := range rgbD {
+		rgbD[i] = d*(100/rgbW[i]) + 1 - d
+	}
+
+	k := 1 / (5*adaptingLuminance + 1)
+	k4 := k * k * k * k
+	k4F := 1 - k4
+	fl := k4*adaptingLuminance + 0.1*k4F*k4F*math.Cbrt(5*adaptingLuminance)
+	n := yFromLStar(backgroundLStar) / whitePoint[1]
+	z := 1.48 + math.Sqrt(n)
+	nbb := 0.725 / math.Pow(n, 0.2)
+
+	var rgbA [3]float64
+	for i := range rgbA {
+		factor := math.Pow(fl*rgbD[i]*rgbW[i]/100, 0.42)
+		rgbA[i] = 400 * factor / (factor + 27.13)
+	}
+	aw := (2*rgbA[0] + rgbA[1] + 0.05*rgbA[2]) * nbb
+
+	return viewingConditions{
+		n: n, aw: aw, nbb: nbb, ncb: nbb, c: c, nc: f,
+		rgbD: rgbD, fl: fl, fLRoot: math.Pow(fl, 0.25), z: z,
+	}
+}
+
+// cam16 holds the CAM16 appearance correlates HCT is built on.
+type cam16 struct {
+	hue, chroma, j float64
+}
+
+func cam16FromARGB(c ARGB) cam16 {
+	return cam16FromXYZ(xyzFromARGB(c), defaultViewingConditions)
+}
+
+func cam16FromXYZ(xyz [3]float64, vc viewingConditions) cam16 {
+	rgbC := matrixMultiply(xyz, xyzToCAM16RGB)
+
+	var rgbA [3]float64
+	for i := range rgbA {
+		d := vc.rgbD[i] * rgbC[i]
+		af := math.Pow(vc.fl*math.Abs(d)/100, 0.42)
+		rgbA[i] = signum(d) * 400 * af / (af + 27.13)
+	}
+	rA, gA, bA := rgbA[0], rgbA[1], rgbA[2]
+
+	a := (11*rA - 12*gA + bA) / 11
+	b := (rA + gA - 2*bA) / 9
+	u := (20*rA + 20*gA + 21*bA) / 20
+	p2 := (40*rA + 20*gA + bA) / 20
+
+	hue := math.Atan2(b, a) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	} else if hue >= 360 {
+		hue -= 360
+	}
+
+	ac := p2 * vc.nbb
+	j := 100 * math.Pow(ac/vc.aw, vc.c*vc.z)
+
+	huePrime := hue
+	if hue < 20.14 {
+		huePrime += 360
+	}
+	eHue := 0.25 * (math.Cos(huePrime*math.Pi/180+2) + 3.8)
+	p1 := 50000.0 / 13 * eHue * vc.nc * vc.ncb
+	t := p1 * math.Hypot(a, b) / (u + 0.305)
+	alpha := math.Pow(1.64-math.Pow(0.29, vc.n), 0.73) * math.Pow(t, 0.9)
+
+	return cam16{hue: hue, chroma: alpha * math.Sqrt(j/100), j: j}
+}
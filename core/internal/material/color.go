@@ -0,0 +1,198 @@
+// Package material is a Go port of Material Color Utilities: the HCT color
+// space, tonal palettes, dynamic schemes and image quantization used to
+// build Material You themes.
+package material
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ARGB is a color packed as 0xAARRGGBB.
+type ARGB uint32
+
+var (
+	srgbToXYZ = [3][3]float64{
+		{0.41233895, 0.35762064, 0.18051042},
+		{0.2126, 0.7152, 0.0722},
+		{0.01932141, 0.11916382, 0.95034478},
+	}
+	xyzToSRGB = [3][3]float64{
+		{3.2413774792388685, -1.5376652402851851, -0.49885366846268053},
+		{-0.9691452513005321, 1.8758853451067872, 0.04156585616912061},
+		{0.05562093689691305, -0.20395524564742123, 1.0571799111220335},
+	}
+	whitePointD65 = [3]float64{95.047, 100.0, 108.883}
+)
+
+func ARGBFromRGB(r, g, b uint8) ARGB {
+	return ARGB(0xff000000 | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
+}
+
+func (c ARGB) Alpha() uint8 { return uint8(c >> 24) }
+func (c ARGB) Red() uint8   { return uint8(c >> 16) }
+func (c ARGB) Green() uint8 { return uint8(c >> 8) }
+func (c ARGB) Blue() uint8  { return uint8(c) }
+
+// Hex returns the color as lowercase #rrggbb.
+func (c ARGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.Red(), c.Green(), c.Blue())
+}
+
+// ParseHex parses #rgb or #rrggbb, with or without the hash.
+func ParseHex(s string) (ARGB, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex color %q", s)
+	}
+	return ARGB(0xff000000 | uint32(v)), nil
+}
+
+// linearized converts an 8-bit sRGB component to linear RGB in 0-100.
+func linearized(component uint8) float64 {
+	normalized := float64(component) / 255
+	if normalized <= 0.040449936 {
+		return normalized / 12.92 * 100
+	}
+	return math.Pow((normalized+0.055)/1.055, 2.4) * 100
+}
+
+// delinearized converts a linear RGB component in 0-100 to 8-bit sRGB.
+func delinearized(component float64) uint8 {
+	normalized := component / 100
+	var v float64
+	if normalized <= 0.0031308 {
+		v = normalized * 12.92
+	} else {
+		v = 1.055*math.Pow(normalized, 1/2.4) - 0.055
+	}
+	return uint8(clampInt(0, 255, int(math.Round(v*255))))
+}
+
+func argbFromLinRGB(linrgb [3]float64) ARGB {
+	return ARGBFromRGB(delinearized(linrgb[0]), delinearized(linrgb[1]), delinearized(linrgb[2]))
+}
+
+func argbFromXYZ(x, y, z float64) ARGB {
+	lin := matrixMultiply([3]float64{x, y, z}, xyzToSRGB)
+	return argbFromLinRGB(lin)
+}
+
+func xyzFromARGB(c ARGB) [3]float64 {
+	return matrixMultiply([3]float64{linearized(c.Red()), linearized(c.Green()), linearized(c.Blue())}, srgbToXYZ)
+}
+
+func labFromARGB(c ARGB) [3]float64 {
+	xyz := xyzFromARGB(c)
+	fx := labF(xyz[0] / whitePointD65[0])
+	fy := labF(xyz[1] / whitePointD65[1])
+	fz := labF(xyz[2] / whitePointD65[2])
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+func argbFromLab(lab [3]float64) ARGB {
+	fy := (lab[0] + 16) / 116
+	fx := lab[1]/500 + fy
+	fz := fy - lab[2]/200
+	return argbFromXYZ(
+		labInvf(fx)*whitePointD65[0],
+		labInvf(fy)*whitePointD65[1],
+		labInvf(fz)*whitePointD65[2],
+	)
+}
+
+// LStar returns the L* of the color, which is what HCT calls tone.
+func (c ARGB) LStar() float64 {
+	return 116*labF(xyzFromARGB(c)[1]/100) - 16
+}
+
+func argbFromLStar(lstar float64) ARGB {
+	component := delinearized(yFromLStar(lstar))
+	return ARGBFromRGB(component, component, component)
+}
+
+func yFromLStar(lstar float64) float64 {
+	return 100 * labInvf((lstar+16)/116)
+}
+
+func lstarFromY(y float64) float64 {
+	return labF(y/100)*116 - 16
+}
+
+func labF(t float64) float64 {
+	const e = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+	if t > e {
+		return math.Cbrt(t)
+	}
+	return (kappa*t + 16) / 116
+}
+
+func labInvf(ft float64) float64 {
+	const e = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+	ft3 := ft * ft * ft
+	if ft3 > e {
+		return ft3
+	}
+	return (116*ft - 16) / kappa
+}
+
+func matrixMultiply(v [3]float64, m [3][3]float64) [3]float64 {
+	return [3]float64{
+		v[0]*m[0][0] + v[1]*m[0][1] + v[2]*m[0][2],
+		v[0]*m[1][0] + v[1]*m[1][1] + v[2]*m[1][2],
+		v[0]*m[2][0] + v[1]*m[2][1] + v[2]*m[2][2],
+	}
+}
+
+func signum(v float64) float64 {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func lerp(start, stop, amount float64) float64 {
+	return (1-amount)*start + amount*stop
+}
+
+func clampInt(lo, hi, v int) int {
+	return min(max(v, lo), hi)
+}
+
+func clampFloat(lo, hi, v float64) float64 {
+	return min(max(v, lo), hi)
+}
+
+func sanitizeDegreesInt(degrees int) int {
+	degrees %= 360
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+func sanitizeDegrees(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+func differenceDegrees(a, b float64) float64 {
+	return 180 - math.Abs(math.Abs(a-b)-180)
+}
@@ -0,0 +1,89 @@
+package material
+
+import "math"
+
+// ratioOfTones returns the WCAG contrast ratio between two tones.
+func ratioOfTones(a, b float64) float64 {
+	return ratioOfYs(yFromLStar(clampFloat(0, 100, a)), yFromLStar(clampFloat(0, 100, b)))
+}
+
+func ratioOfYs(y1, y2 float64) float64 {
+	lighter := math.Max(y1, y2)
+	darker := y2
+	if lighter == y2 {
+		darker = y1
+	}
+	return (lighter + 5) / (darker + 5)
+}
+
+// lighterTone returns a tone >= tone with at least the given contrast, or
+// -1 if none exists.
+func lighterTone(tone, ratio float64) float64 {
+	if tone < 0 || tone > 100 {
+		return -1
+	}
+	darkY := yFromLStar(tone)
+	lightY := ratio*(darkY+5) - 5
+	real := ratioOfYs(lightY, darkY)
+	if real < ratio && math.Abs(real-ratio) > 0.04 {
+		return -1
+	}
+	v := lstarFromY(lightY) + 0.4
+	if v < 0 || v > 100 {
+		return -1
+	}
+	return v
+}
+
+// darkerTone returns a tone <= tone with at least the given contrast, or
+// -1 if none exists.
+func darkerTone(tone, ratio float64) float64 {
+	if tone < 0 || tone > 100 {
+		return -1
+	}
+	lightY := yFromLStar(tone)
+	darkY := (lightY+5)/ratio - 5
+	real := ratioOfYs(lightY, darkY)
+	if real < ratio && math.Abs(real-ratio) > 0.04 {
+		return -1
+	}
+	v := lstarFromY(darkY) - 0.4
+	if v < 0 || v > 100 {
+		return -1
+	}
+	return v
+}
+
+func lighterToneUnsafe(tone, ratio float64) float64 {
+	if v := lighterTone(tone, ratio); v >= 0 {
+		return v
+	}
+	return 100
+}
+
+func darkerToneUnsafe(tone, ratio float64) float64 {
+	if v := darkerTone(tone, ratio); v >= 0 {
+		return v
+	}
+	return 0
+}
+
+// contrastCurve is the contrast ratio a color needs against its background
+// at contrast levels -1, 0, 0.5 and 1.
+type contrastCurve struct {
+	low, normal, medium, high float64
+}
+
+func (c contrastCurve) get(level float64) float64 {
+	switch {
+	case level <= -1:
+		return c.low
+	case level < 0:
+		return lerp(c.low, c.normal, level+1)
+	case level < 0.5:
+		return lerp(c.normal, c.medium, level/0.5)
+	case level < 1:
+		return lerp(c.medium, c.high, (level-0.5)/0.5)
+	}
+	return c.high
+}
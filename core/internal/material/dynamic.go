@@ -0,0 +1,436 @@
+package material
+
+import "math"
+
+type tonePolarity int
+
+const (
+	polarityNearer tonePolarity = iota
+	polarityLighter
+	polarityDarker
+)
+
+// toneDeltaPair keeps two roles, such as a color and its container, at
+// least delta tones apart regardless of contrast adjustments.
+type toneDeltaPair struct {
+	roleA, roleB *dynamicColor
+	delta        float64
+	polarity     tonePolarity
+	stayTogether bool
+}
+
+// dynamicColor is a color role whose tone depends on the scheme: its
+// palette, a default tone, and the backgrounds it must contrast with.
+type dynamicColor struct {
+	name             string
+	palette          func(*Scheme) *TonalPalette
+	tone             func(*Scheme) float64
+	isBackground     bool
+	background       func(*Scheme) *dynamicColor
+	secondBackground func(*Scheme) *dynamicColor
+	contrast         *contrastCurve
+	pair             func(*Scheme) toneDeltaPair
+}
+
+func (dc *dynamicColor) argb(s *Scheme) ARGB {
+	return dc.palette(s).Tone(dc.getTone(s))
+}
+
+func (dc *dynamicColor) getTone(s *Scheme) float64 {
+	decreasingContrast := s.ContrastLevel < 0
+
+	if dc.pair != nil {
+		pair := dc.pair(s)
+		bgTone := dc.background(s).getTone(s)
+
+		aIsNearer := pair.polarity == polarityNearer ||
+			(pair.polarity == polarityLighter && !s.IsDark) ||
+			(pair.polarity == polarityDarker && s.IsDark)
+		nearer, farther := pair.roleA, pair.roleB
+		if !aIsNearer {
+			nearer, farther = farther, nearer
+		}
+		amNearer := dc.name == nearer.name
+		expansionDir := -1.0
+		if s.IsDark {
+			expansionDir = 1
+		}
+
+		nContrast := nearer.contrast.get(s.ContrastLevel)
+		fContrast := farther.contrast.get(s.ContrastLevel)
+
+		nTone := nearer.tone(s)
+		if ratioOfTones(bgTone, nTone) < nContrast {
+			nTone = foregroundTone(bgTone, nContrast)
+		}
+		fTone := farther.tone(s)
+		if ratioOfTones(bgTone, fTone) < fContrast {
+			fTone = foregroundTone(bgTone, fContrast)
+		}
+		if decreasingContrast {
+			nTone = foregroundTone(bgTone, nContrast)
+			fTone = foregroundTone(bgTone, fContrast)
+		}
+
+		delta := pair.delta
+		if (fTone-nTone)*expansionDir < delta {
+			fTone = clampFloat(0, 100, nTone+delta*expansionDir)
+			if (fTone-nTone)*expansionDir < delta {
+				nTone = clampFloat(0, 100, fTone-delta*expansionDir)
+			}
+		}
+
+		// Avoid the 50-59 band, which has poor contrast against both
+		// black and white.
+		if nTone >= 50 && nTone < 60 {
+			if expansionDir > 0 {
+				nTone = 60
+				fTone = math.Max(fTone, nTone+delta*expansionDir)
+			} else {
+				nTone = 49
+				fTone = math.Min(fTone, nTone+delta*expansionDir)
+			}
+		} else if fTone >= 50 && fTone < 60 {
+			switch {
+			case pair.stayTogether && expansionDir > 0:
+				nTone = 60
+				fTone = math.Max(fTone, nTone+delta*expansionDir)
+			case pair.stayTogether:
+				nTone = 49
+				fTone = math.Min(fTone, nTone+delta*expansionDir)
+			case expansionDir > 0:
+				fTone = 60
+			default:
+				fTone = 49
+			}
+		}
+
+		if amNearer {
+			return nTone
+		}
+		return fTone
+	}
+
+	answer := dc.tone(s)
+	if dc.background == nil {
+		return answer
+	}
+
+	bgTone := dc.background(s).getTone(s)
+	desired := dc.contrast.get(s.ContrastLevel)
+	if ratioOfTones(bgTone, answer) < desired || decreasingContrast {
+		answer = foregroundTone(bgTone, desired)
+	}
+	if dc.isBackground && answer >= 50 && answer < 60 {
+		if ratioOfTones(49, bgTone) >= desired {
+			answer = 49
+		} else {
+			answer = 60
+		}
+	}
+
+	if dc.secondBackground == nil {
+		return answer
+	}
+
+	bgTone1 := dc.background(s).getTone(s)
+	bgTone2 := dc.secondBackground(s).getTone(s)
+	upper, lower := math.Max(bgTone1, bgTone2), math.Min(bgTone1, bgTone2)
+	if ratioOfTones(upper, answer) >= desired && ratioOfTones(lower, answer) >= desired {
+		return answer
+	}
+
+	lightOption := lighterTone(upper, desired)
+	darkOption := darkerTone(lower, desired)
+	if tonePrefersLightForeground(bgTone1) || tonePrefersLightForeground(bgTone2) {
+		if lightOption < 0 {
+			return 100
+		}
+		return lightOption
+	}
+	if lightOption >= 0 && darkOption < 0 {
+		return lightOption
+	}
+	if darkOption < 0 {
+		return 0
+	}
+	return darkOption
+}
+
+// foregroundTone picks the lighter or darker tone that reaches ratio
+// against bgTone, preferring whichever direction has more room.
+func foregroundTone(bgTone, ratio float64) float64 {
+	lighter := lighterToneUnsafe(bgTone, ratio)
+	darker := darkerToneUnsafe(bgTone, ratio)
+	lighterRatio := ratioOfTones(lighter, bgTone)
+	darkerRatio := ratioOfTones(darker, bgTone)
+
+	if tonePrefersLightForeground(bgTone) {
+		negligible := math.Abs(lighterRatio-darkerRatio) < 0.1 && lighterRatio < ratio && darkerRatio < ratio
+		if lighterRatio >= ratio || lighterRatio >= darkerRatio || negligible {
+			return lighter
+		}
+		return darker
+	}
+	if darkerRatio >= ratio || darkerRatio >= lighterRatio {
+		return darker
+	}
+	return lighter
+}
+
+func tonePrefersLightForeground(tone float64) bool {
+	return math.Round(tone) < 60
+}
+
+// findDesiredChromaByTone walks tones from the starting point until the
+// palette can reach the requested chroma, for the fidelity variants.
+func findDesiredChromaByTone(hue, chroma, tone float64, byDecreasingTone bool) float64 {
+	answer := tone
+	closest := NewHct(hue, chroma, tone)
+	if closest.chroma >= chroma {
+		return answer
+	}
+	peak := closest.chroma
+	for closest.chroma < chroma {
+		if byDecreasingTone {
+			answer--
+		} else {
+			answer++
+		}
+		candidate := NewHct(hue, chroma, answer)
+		if peak > candidate.chroma || math.Abs(candidate.chroma-chroma) < 0.4 {
+			break
+		}
+		if math.Abs(candidate.chroma-chroma) < math.Abs(closest.chroma-chroma) {
+			closest = candidate
+		}
+		peak = math.Max(peak, candidate.chroma)
+	}
+	return answer
+}
+
+var (
+	materialColors       []*dynamicColor
+	materialColorsByName map[string]*dynamicColor
+)
+
+func init() {
+	primaryPalette := func(s *Scheme) *TonalPalette { return s.Primary }
+	secondaryPalette := func(s *Scheme) *TonalPalette { return s.Secondary }
+	tertiaryPalette := func(s *Scheme) *TonalPalette { return s.Tertiary }
+	neutralPalette := func(s *Scheme) *TonalPalette { return s.Neutral }
+	neutralVariantPalette := func(s *Scheme) *TonalPalette { return s.NeutralVariant }
+	errorPalette := func(s *Scheme) *TonalPalette { return s.Error }
+
+	dark := func(d, l float64) func(*Scheme) float64 {
+		return func(s *Scheme) float64 {
+			if s.IsDark {
+				return d
+			}
+			return l
+		}
+	}
+	pick := func(s *Scheme, d, l float64) float64 {
+		if s.IsDark {
+			return d
+		}
+		return l
+	}
+	isFidelity := func(s *Scheme) bool { return s.Variant == VariantFidelity || s.Variant == VariantContent }
+	isMonochrome := func(s *Scheme) bool { return s.Variant == VariantMonochrome }
+	cc := func(low, normal, medium, high float64) *contrastCurve {
+		return &contrastCurve{low, normal, medium, high}
+	}
+
+	surface := func(name string, d, l float64) *dynamicColor {
+		return &dynamicColor{name: name, palette: neutralPalette, tone: dark(d, l), isBackground: true}
+	}
+	background := surface("background", 6, 98)
+	surfaceColor := surface("surface", 6, 98)
+	surfaceDim := surface("surface_dim", 6, 87)
+	surfaceBright := surface("surface_bright", 24, 98)
+	surfaceContainerLowest := surface("surface_container_lowest", 4, 100)
+	surfaceContainerLow := surface("surface_container_low", 10, 96)
+	surfaceContainer := surface("surface_container", 12, 94)
+	surfaceContainerHigh := surface("surface_container_high", 17, 92)
+	surfaceContainerHighest := surface("surface_container_highest", 22, 90)
+	surfaceVariant := &dynamicColor{name: "surface_variant", palette: neutralVariantPalette, tone: dark(30, 90), isBackground: true}
+	inverseSurface := &dynamicColor{name: "inverse_surface", palette: neutralPalette, tone: dark(90, 20)}
+
+	highestSurface := func(s *Scheme) *dynamicColor {
+		if s.IsDark {
+			return surfaceBright
+		}
+		return surfaceDim
+	}
+	on := func(bg *dynamicColor) func(*Scheme) *dynamicColor {
+		return func(*Scheme) *dynamicColor { return bg }
+	}
+
+	onBackground := &dynamicColor{name: "on_background", palette: neutralPalette, tone: dark(90, 10), background: on(background), contrast: cc(3, 3, 4.5, 7)}
+	onSurface := &dynamicColor{name: "on_surface", palette: neutralPalette, tone: dark(90, 10), background: highestSurface, contrast: cc(4.5, 7, 11, 21)}
+	onSurfaceVariant := &dynamicColor{name: "on_surface_variant", palette: neutralVariantPalette, tone: dark(80, 30), background: highestSurface, contrast: cc(3, 4.5, 7, 11)}
+	inverseOnSurface := &dynamicColor{name: "inverse_on_surface", palette: neutralPalette, tone: dark(20, 95), background: on(inverseSurface), contrast: cc(4.5, 7, 11, 21)}
+	outline := &dynamicColor{name: "outline", palette: neutralVariantPalette, tone: dark(60, 50), background: highestSurface, contrast: cc(1.5, 3, 4.5, 7)}
+	outlineVariant := &dynamicColor{name: "outline_variant", palette: neutralVariantPalette, tone: dark(30, 80), background: highestSurface, contrast: cc(1, 1, 3, 4.5)}
+	shadow := &dynamicColor{name: "shadow", palette: neutralPalette, tone: dark(0, 0)}
+	scrim := &dynamicColor{name: "scrim", palette: neutralPalette, tone: dark(0, 0)}
+	surfaceTint := &dynamicColor{name: "surface_tint", palette: primaryPalette, tone: dark(80, 40), isBackground: true}
+
+	// Accent roles. Each color/container pair is declared first so the
+	// tone delta pairs can refer to both.
+	primary := &dynamicColor{name: "primary", palette: primaryPalette, isBackground: true, background: highestSurface, contrast: cc(3, 4.5, 7, 7)}
+	primaryContainer := &dynamicColor{name: "primary_container", palette: primaryPalette, isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5)}
+	primary.tone = func(s *Scheme) float64 {
+		if isMonochrome(s) {
+			return pick(s, 100, 0)
+		}
+		return pick(s, 80, 40)
+	}
+	primaryContainer.tone = func(s *Scheme) float64 {
+		switch {
+		case isFidelity(s):
+			return s.Source.tone
+		case isMonochrome(s):
+			return pick(s, 85, 25)
+		}
+		return pick(s, 30, 90)
+	}
+	primaryPair := func(*Scheme) toneDeltaPair {
+		return toneDeltaPair{roleA: primaryContainer, roleB: primary, delta: 10, polarity: polarityNearer}
+	}
+	primary.pair, primaryContainer.pair = primaryPair, primaryPair
+
+	onPrimary := &dynamicColor{name: "on_primary", palette: primaryPalette, background: on(primary), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		if isMonochrome(s) {
+			return pick(s, 10, 90)
+		}
+		return pick(s, 20, 100)
+	}}
+	onPrimaryContainer := &dynamicColor{name: "on_primary_container", palette: primaryPalette, background: on(primaryContainer), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		switch {
+		case isFidelity(s):
+			return foregroundTone(primaryContainer.tone(s), 4.5)
+		case isMonochrome(s):
+			return pick(s, 0, 100)
+		}
+		return pick(s, 90, 10)
+	}}
+	inversePrimary := &dynamicColor{name: "inverse_primary", palette: primaryPalette, tone: dark(40, 80), background: on(inverseSurface), contrast: cc(3, 4.5, 7, 7)}
+
+	secondary := &dynamicColor{name: "secondary", palette: secondaryPalette, tone: dark(80, 40), isBackground: true, background: highestSurface, contrast: cc(3, 4.5, 7, 7)}
+	secondaryContainer := &dynamicColor{name: "secondary_container", palette: secondaryPalette, isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5), tone: func(s *Scheme) float64 {
+		initial := pick(s, 30, 90)
+		switch {
+		case isMonochrome(s):
+			return pick(s, 30, 85)
+		case !isFidelity(s):
+			return initial
+		}
+		return findDesiredChromaByTone(s.Secondary.Hue, s.Secondary.Chroma, initial, !s.IsDark)
+	}}
+	secondaryPair := func(*Scheme) toneDeltaPair {
+		return toneDeltaPair{roleA: secondaryContainer, roleB: secondary, delta: 10, polarity: polarityNearer}
+	}
+	secondary.pair, secondaryContainer.pair = secondaryPair, secondaryPair
+
+	onSecondary := &dynamicColor{name: "on_secondary", palette: secondaryPalette, background: on(secondary), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		if isMonochrome(s) {
+			return pick(s, 10, 100)
+		}
+		return pick(s, 20, 100)
+	}}
+	onSecondaryContainer := &dynamicColor{name: "on_secondary_container", palette: secondaryPalette, background: on(secondaryContainer), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		if !isFidelity(s) {
+			return pick(s, 90, 10)
+		}
+		return foregroundTone(secondaryContainer.tone(s), 4.5)
+	}}
+
+	tertiary := &dynamicColor{name: "tertiary", palette: tertiaryPalette, isBackground: true, background: highestSurface, contrast: cc(3, 4.5, 7, 7), tone: func(s *Scheme) float64 {
+		if isMonochrome(s) {
+			return pick(s, 90, 25)
+		}
+		return pick(s, 80, 40)
+	}}
+	tertiaryContainer := &dynamicColor{name: "tertiary_container", palette: tertiaryPalette, isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5), tone: func(s *Scheme) float64 {
+		switch {
+		case isMonochrome(s):
+			return pick(s, 60, 49)
+		case !isFidelity(s):
+			return pick(s, 30, 90)
+		}
+		return fixIfDisliked(s.Tertiary.Hct(s.Source.tone)).tone
+	}}
+	tertiaryPair := func(*Scheme) toneDeltaPair {
+		return toneDeltaPair{roleA: tertiaryContainer, roleB: tertiary, delta: 10, polarity: polarityNearer}
+	}
+	tertiary.pair, tertiaryContainer.pair = tertiaryPair, tertiaryPair
+
+	onTertiary := &dynamicColor{name: "on_tertiary", palette: tertiaryPalette, background: on(tertiary), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		if isMonochrome(s) {
+			return pick(s, 10, 90)
+		}
+		return pick(s, 20, 100)
+	}}
+	onTertiaryContainer := &dynamicColor{name: "on_tertiary_container", palette: tertiaryPalette, background: on(tertiaryContainer), contrast: cc(4.5, 7, 11, 21), tone: func(s *Scheme) float64 {
+		switch {
+		case isMonochrome(s):
+			return pick(s, 0, 100)
+		case !isFidelity(s):
+			return pick(s, 90, 10)
+		}
+		return foregroundTone(tertiaryContainer.tone(s), 4.5)
+	}}
+
+	errorColor := &dynamicColor{name: "error", palette: errorPalette, tone: dark(80, 40), isBackground: true, background: highestSurface, contrast: cc(3, 4.5, 7, 7)}
+	errorContainer := &dynamicColor{name: "error_container", palette: errorPalette, tone: dark(30, 90), isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5)}
+	errorPair := func(*Scheme) toneDeltaPair {
+		return toneDeltaPair{roleA: errorContainer, roleB: errorColor, delta: 10, polarity: polarityNearer}
+	}
+	errorColor.pair, errorContainer.pair = errorPair, errorPair
+	onError := &dynamicColor{name: "on_error", palette: errorPalette, tone: dark(20, 100), background: on(errorColor), contrast: cc(4.5, 7, 11, 21)}
+	onErrorContainer := &dynamicColor{name: "on_error_container", palette: errorPalette, tone: dark(90, 10), background: on(errorContainer), contrast: cc(4.5, 7, 11, 21)}
+
+	// Fixed roles keep the same tone in light and dark themes.
+	fixed := func(prefix string, palette func(*Scheme) *TonalPalette, tone, dimTone, onTone, onVariantTone [2]float64) []*dynamicColor {
+		monoOr := func(t [2]float64) func(*Scheme) float64 {
+			return func(s *Scheme) float64 {
+				if isMonochrome(s) {
+					return t[0]
+				}
+				return t[1]
+			}
+		}
+		f := &dynamicColor{name: prefix + "_fixed", palette: palette, tone: monoOr(tone), isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5)}
+		dim := &dynamicColor{name: prefix + "_fixed_dim", palette: palette, tone: monoOr(dimTone), isBackground: true, background: highestSurface, contrast: cc(1, 1, 3, 4.5)}
+		pair := func(*Scheme) toneDeltaPair {
+			return toneDeltaPair{roleA: f, roleB: dim, delta: 10, polarity: polarityLighter, stayTogether: true}
+		}
+		f.pair, dim.pair = pair, pair
+		onF := &dynamicColor{name: "on_" + prefix + "_fixed", palette: palette, tone: monoOr(onTone), background: on(dim), secondBackground: on(f), contrast: cc(4.5, 7, 11, 21)}
+		onV := &dynamicColor{name: "on_" + prefix + "_fixed_variant", palette: palette, tone: monoOr(onVariantTone), background: on(dim), secondBackground: on(f), contrast: cc(3, 4.5, 7, 11)}
+		return []*dynamicColor{f, dim, onF, onV}
+	}
+
+	materialColors = []*dynamicColor{
+		background, onBackground,
+		surfaceColor, surfaceDim, surfaceBright,
+		surfaceContainerLowest, surfaceContainerLow, surfaceContainer, surfaceContainerHigh, surfaceContainerHighest,
+		onSurface, surfaceVariant, onSurfaceVariant,
+		inverseSurface, inverseOnSurface,
+		outline, outlineVariant, shadow, scrim, surfaceTint,
+		primary, onPrimary, primaryContainer, onPrimaryContainer, inversePrimary,
+		secondary, onSecondary, secondaryContainer, onSecondaryContainer,
+		tertiary, onTertiary, tertiaryContainer, onTertiaryContainer,
+		errorColor, onError, errorContainer, onErrorContainer,
+	}
+	materialColors = append(materialColors, fixed("primary", primaryPalette, [2]float64{40, 90}, [2]float64{30, 80}, [2]float64{100, 10}, [2]float64{90, 30})...)
+	materialColors = append(materialColors, fixed("secondary", secondaryPalette, [2]float64{80, 90}, [2]float64{70, 80}, [2]float64{10, 10}, [2]float64{25, 30})...)
+	materialColors = append(materialColors, fixed("tertiary", tertiaryPalette, [2]float64{40, 90}, [2]float64{30, 80}, [2]float64{100, 10}, [2]float64{90, 30})...)
+
+	materialColorsByName = make(map[string]*dynamicColor, len(materialColors))
+	for _, dc := range materialColors {
+		materialColorsByName[dc.name] = dc
+	}
+}
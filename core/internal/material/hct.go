@@ -0,0 +1,283 @@
+package material
+
+import "math"
+
+// Hct is a color in the HCT space: CAM16 hue and chroma with L* as tone.
+// Tone maps directly to contrast, which is what makes it suitable for
+// building accessible schemes.
+type Hct struct {
+	hue, chroma, tone float64
+	argb              ARGB
+}
+
+// HctFromARGB converts an sRGB color to HCT.
+func HctFromARGB(c ARGB) Hct {
+	cam := cam16FromARGB(c)
+	return Hct{hue: cam.hue, chroma: cam.chroma, tone: c.LStar(), argb: c}
+}
+
+// NewHct returns the sRGB color closest to the requested hue, chroma and
+// tone. Chroma is reduced when the combination is out of gamut.
+func NewHct(hue, chroma, tone float64) Hct {
+	return HctFromARGB(solveToARGB(hue, chroma, tone))
+}
+
+func (h Hct) Hue() float64    { return h.hue }
+func (h Hct) Chroma() float64 { return h.chroma }
+func (h Hct) Tone() float64   { return h.tone }
+func (h Hct) ARGB() ARGB      { return h.argb }
+
+var (
+	yFromLinRGB = [3]float64{0.2126, 0.7152, 0.0722}
+
+	scaledDiscountFromLinRGB [3][3]float64
+	linRGBFromScaledDiscount [3][3]float64
+
+	// criticalPlanes are the linear values halfway between two adjacent
+	// 8-bit sRGB values, where rounding to an integer channel flips.
+	criticalPlanes [255]float64
+)
+
+func init() {
+	vc := defaultViewingConditions
+	for i := range 3 {
+		for j := range 3 {
+			var v float64
+			for k := range 3 {
+				v += xyzToCAM16RGB[i][k] * srgbToXYZ[k][j]
+			}
+			scaledDiscountFromLinRGB[i][j] = vc.fl * vc.rgbD[i] * v / 100
+		}
+	}
+	linRGBFromScaledDiscount = invert3(scaledDiscountFromLinRGB)
+
+	for i := range criticalPlanes {
+		normalized := (float64(i) + 0.5) / 255
+		if normalized <= 0.040449936 {
+			criticalPlanes[i] = normalized / 12.92 * 100
+		} else {
+			criticalPlanes[i] = math.Pow((normalized+0.055)/1.055, 2.4) * 100
+		}
+	}
+}
+
+func invert3(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	return [3][3]float64{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}
+}
+
+// solveToARGB finds the color for an HCT triple. It first solves for J
+// with Newton's method; when that lands out of gamut it bisects along the
+// edges of the RGB cube at the requested luminance for the hue instead.
+func solveToARGB(hue, chroma, lstar float64) ARGB {
+	if chroma < 0.0001 || lstar < 0.0001 || lstar > 99.9999 {
+		return argbFromLStar(lstar)
+	}
+	hueRadians := sanitizeDegrees(hue) / 180 * math.Pi
+	y := yFromLStar(lstar)
+	if exact, ok := findResultByJ(hueRadians, chroma, y); ok {
+		return exact
+	}
+	return argbFromLinRGB(bisectToLimit(y, hueRadians))
+}
+
+func findResultByJ(hueRadians, chroma, y float64) (ARGB, bool) {
+	vc := defaultViewingConditions
+	j := math.Sqrt(y) * 11
+	tInnerCoeff := 1 / math.Pow(1.64-math.Pow(0.29, vc.n), 0.73)
+	eHue := 0.25 * (math.Cos(hueRadians+2) + 3.8)
+	p1 := eHue * (50000.0 / 13) * vc.nc * vc.ncb
+	hSin, hCos := math.Sin(hueRadians), math.Cos(hueRadians)
+
+	for round := range 5 {
+		jNormalized := j / 100
+		alpha := 0.0
+		if chroma != 0 && j != 0 {
+			alpha = chroma / math.Sqrt(jNormalized)
+		}
+		t := math.Pow(alpha*tInnerCoeff, 1/0.9)
+		ac := vc.aw * math.Pow(jNormalized, 1/vc.c/vc.z)
+		p2 := ac / vc.nbb
+		gamma := 23 * (p2 + 0.305) * t / (23*p1 + 11*t*hCos + 108*t*hSin)
+		a, b := gamma*hCos, gamma*hSin
+		rA := (460*p2 + 451*a + 288*b) / 1403
+		gA := (460*p2 - 891*a - 261*b) / 1403
+		bA := (460*p2 - 220*a - 6300*b) / 1403
+		scaled := [3]float64{inverseChromaticAdaptation(rA), inverseChromaticAdaptation(gA), inverseChromaticAdaptation(bA)}
+		linrgb := matrixMultiply(scaled, linRGBFromScaledDiscount)
+		if linrgb[0] < 0 || linrgb[1] < 0 || linrgb[2] < 0 {
+			return 0, false
+		}
+		fnj := yFromLinRGB[0]*linrgb[0] + yFromLinRGB[1]*linrgb[1] + yFromLinRGB[2]*linrgb[2]
+		if fnj <= 0 {
+			return 0, false
+		}
+		if round == 4 || math.Abs(fnj-y) < 0.002 {
+			if linrgb[0] > 100.01 || linrgb[1] > 100.01 || linrgb[2] > 100.01 {
+				return 0, false
+			}
+			return argbFromLinRGB(linrgb), true
+		}
+		j -= (fnj - y) * j / (2 * fnj)
+	}
+	return 0, false
+}
+
+func chromaticAdaptation(component float64) float64 {
+	af := math.Pow(math.Abs(component), 0.42)
+	return signum(component) * 400 * af / (af + 27.13)
+}
+
+func inverseChromaticAdaptation(adapted float64) float64 {
+	abs := math.Abs(adapted)
+	base := math.Max(0, 27.13*abs/(400-abs))
+	return signum(adapted) * math.Pow(base, 1/0.42)
+}
+
+func sanitizeRadians(angle float64) float64 {
+	return math.Mod(angle+math.Pi*8, math.Pi*2)
+}
+
+func trueDelinearized(component float64) float64 {
+	normalized := component / 100
+	if normalized <= 0.0031308 {
+		return normalized * 12.92 * 255
+	}
+	return (1.055*math.Pow(normalized, 1/2.4) - 0.055) * 255
+}
+
+func hueOf(linrgb [3]float64) float64 {
+	scaled := matrixMultiply(linrgb, scaledDiscountFromLinRGB)
+	rA := chromaticAdaptation(scaled[0])
+	gA := chromaticAdaptation(scaled[1])
+	bA := chromaticAdaptation(scaled[2])
+	a := (11*rA - 12*gA + bA) / 11
+	b := (rA + gA - 2*bA) / 9
+	return math.Atan2(b, a)
+}
+
+func areInCyclicOrder(a, b, c float64) bool {
+	return sanitizeRadians(b-a) < sanitizeRadians(c-a)
+}
+
+func setCoordinate(source [3]float64, coordinate float64, target [3]float64, axis int) [3]float64 {
+	t := (coordinate - source[axis]) / (target[axis] - source[axis])
+	return [3]float64{
+		source[0] + (target[0]-source[0])*t,
+		source[1] + (target[1]-source[1])*t,
+		source[2] + (target[2]-source[2])*t,
+	}
+}
+
+// nthVertex returns the nth of the 12 edges of the RGB cube intersected
+// with the plane of constant luminance y, or false if it misses.
+func nthVertex(y float64, n int) ([3]float64, bool) {
+	kR, kG, kB := yFromLinRGB[0], yFromLinRGB[1], yFromLinRGB[2]
+	coordA := 0.0
+	if n%4 > 1 {
+		coordA = 100
+	}
+	coordB := 0.0
+	if n%2 != 0 {
+		coordB = 100
+	}
+	bounded := func(x float64) bool { return x >= 0 && x <= 100 }
+	switch {
+	case n < 4:
+		g, b := coordA, coordB
+		r := (y - g*kG - b*kB) / kR
+		return [3]float64{r, g, b}, bounded(r)
+	case n < 8:
+		b, r := coordA, coordB
+		g := (y - r*kR - b*kB) / kG
+		return [3]float64{r, g, b}, bounded(g)
+	default:
+		r, g := coordA, coordB
+		b := (y - r*kR - g*kG) / kB
+		return [3]float64{r, g, b}, bounded(b)
+	}
+}
+
+func bisectToSegment(y, targetHue float64) (left, right [3]float64) {
+	var leftHue, rightHue float64
+	initialized, uncut := false, true
+	for n := range 12 {
+		mid, ok := nthVertex(y, n)
+		if !ok {
+			continue
+		}
+		midHue := hueOf(mid)
+		if !initialized {
+			left, right = mid, mid
+			leftHue, rightHue = midHue, midHue
+			initialized = true
+			continue
+		}
+		if uncut || areInCyclicOrder(leftHue, midHue, rightHue) {
+			uncut = false
+			if areInCyclicOrder(leftHue, targetHue, midHue) {
+				right, rightHue = mid, midHue
+			} else {
+				left, leftHue = mid, midHue
+			}
+		}
+	}
+	return left, right
+}
+
+func bisectToLimit(y, targetHue float64) [3]float64 {
+	left, right := bisectToSegment(y, targetHue)
+	leftHue := hueOf(left)
+	for axis := range 3 {
+		if left[axis] == right[axis] {
+			continue
+		}
+		var lPlane, rPlane int
+		if left[axis] < right[axis] {
+			lPlane = int(math.Floor(trueDelinearized(left[axis]) - 0.5))
+			rPlane = int(math.Ceil(trueDelinearized(right[axis]) - 0.5))
+		} else {
+			lPlane = int(math.Ceil(trueDelinearized(left[axis]) - 0.5))
+			rPlane = int(math.Floor(trueDelinearized(right[axis]) - 0.5))
+		}
+		for range 8 {
+			if abs(rPlane-lPlane) <= 1 {
+				break
+			}
+			mPlane := int(math.Floor(float64(lPlane+rPlane) / 2))
+			mid := setCoordinate(left, criticalPlanes[mPlane], right, axis)
+			midHue := hueOf(mid)
+			if areInCyclicOrder(leftHue, targetHue, midHue) {
+				right, rPlane = mid, mPlane
+			} else {
+				left, leftHue, lPlane = mid, midHue, mPlane
+			}
+		}
+	}
+	return [3]float64{(left[0] + right[0]) / 2, (left[1] + right[1]) / 2, (left[2] + right[2]) / 2}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
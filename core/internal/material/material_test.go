@@ -0,0 +1,145 @@
+package material
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHct(t *testing.T) {
+	red := HctFromARGB(0xffff0000)
+	assert.InDelta(t, 27.408, red.Hue(), 0.001)
+	assert.InDelta(t, 113.357, red.Chroma(), 0.001)
+	assert.InDelta(t, 53.233, red.Tone(), 0.001)
+
+	blue := HctFromARGB(0xff0000ff)
+	assert.InDelta(t, 282.788, blue.Hue(), 0.001)
+	assert.InDelta(t, 87.230, blue.Chroma(), 0.001)
+	assert.InDelta(t, 32.302, blue.Tone(), 0.001)
+
+	for _, c := range []ARGB{0xffff0000, 0xff00ff00, 0xff0000ff, 0xff6750a4, 0xff808080} {
+		h := HctFromARGB(c)
+		assert.Equal(t, c, NewHct(h.Hue(), h.Chroma(), h.Tone()).ARGB(), c.Hex())
+	}
+
+	// Out of gamut chroma is reduced, tone is kept.
+	h := NewHct(120, 200, 50)
+	assert.Less(t, h.Chroma(), 200.0)
+	assert.InDelta(t, 50, h.Tone(), 0.5)
+}
+
+func TestTonalPalette(t *testing.T) {
+	p := NewTonalPalette(270, 36)
+	assert.Equal(t, ARGB(0xff000000), p.Tone(0))
+	assert.Equal(t, ARGB(0xffffffff), p.Tone(100))
+	assert.InDelta(t, 40, p.Hct(40).Tone(), 0.5)
+}
+
+func TestSchemeTonalSpot(t *testing.T) {
+	light := NewScheme(0xff0000ff, VariantTonalSpot, false, 0).Colors()
+	assert.Equal(t, "#555992", light["primary"].Hex())
+	assert.Equal(t, "#e0e0ff", light["primary_container"].Hex())
+	assert.Equal(t, "#0000ff", light["source_color"].Hex())
+
+	dark := NewScheme(0xff0000ff, VariantTonalSpot, true, 0).Colors()
+	assert.Equal(t, "#bec2ff", dark["primary"].Hex())
+	assert.Equal(t, "#3e4278", dark["primary_container"].Hex())
+
+	for _, name := range ColorNames() {
+		_, ok := light[name]
+		assert.True(t, ok, name)
+	}
+}
+
+func TestSchemeVariants(t *testing.T) {
+	content := NewScheme(0xff0000ff, VariantContent, false, 0).Colors()
+	assert.Equal(t, "#0000ff", content["primary_container"].Hex())
+
+	mono := NewScheme(0xff0000ff, VariantMonochrome, true, 0).Colors()
+	assert.Equal(t, "#ffffff", mono["primary"].Hex())
+	assert.Equal(t, "#000000", mono["on_primary_container"].Hex())
+
+	for v := range variantNames {
+		for _, dark := range []bool{false, true} {
+			for _, contrast := range []float64{-1, 0, 1} {
+				s := NewScheme(0xff6750a4, v, dark, contrast)
+				on, _ := s.Color("on_surface")
+				bg, _ := s.Color("surface")
+				ratio := ratioOfTones(on.LStar(), bg.LStar())
+				assert.True(t, ratio >= 3, "%s dark=%v contrast=%v ratio=%.2f", v, dark, contrast, ratio)
+			}
+		}
+	}
+
+	primaryContrast := func(level float64) float64 {
+		s := NewScheme(0xff6750a4, VariantTonalSpot, false, level)
+		on, _ := s.Color("on_primary")
+		bg, _ := s.Color("primary")
+		return ratioOfTones(on.LStar(), bg.LStar())
+	}
+	assert.True(t, primaryContrast(1) > primaryContrast(0))
+	assert.True(t, primaryContrast(0) > primaryContrast(-1))
+}
+
+func TestParseVariant(t *testing.T) {
+	v, err := ParseVariant("scheme-tonal-spot")
+	require.NoError(t, err)
+	assert.Equal(t, VariantTonalSpot, v)
+
+	v, err = ParseVariant("Fruit-Salad")
+	require.NoError(t, err)
+	assert.Equal(t, VariantFruitSalad, v)
+
+	_, err = ParseVariant("scheme-pastel")
+	assert.Error(t, err)
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, []ARGB{0xff0000ff}, Score([]Population{
+		{0xff000000, 1}, {0xffffffff, 1}, {0xff0000ff, 1},
+	}, 4, true))
+
+	assert.Equal(t, []ARGB{0xffff0000, 0xff00ff00, 0xff0000ff}, Score([]Population{
+		{0xffff0000, 1}, {0xff00ff00, 1}, {0xff0000ff, 1},
+	}, 4, true))
+
+	assert.Equal(t, []ARGB{FallbackColor}, Score([]Population{{0xff000000, 1}}, 4, true))
+}
+
+func TestQuantize(t *testing.T) {
+	result := QuantizeCelebi([]ARGB{0xffff0000}, 128)
+	require.Len(t, result, 1)
+	assert.Equal(t, ARGB(0xffff0000), result[0].Color)
+
+	pixels := []ARGB{0xffff0000, 0xffff0000, 0xff00ff00, 0xff0000ff}
+	result = QuantizeCelebi(pixels, 128)
+	assert.Len(t, result, 3)
+	total := 0
+	for _, p := range result {
+		total += p.Count
+	}
+	assert.Equal(t, len(pixels), total)
+
+	assert.Empty(t, QuantizeCelebi(nil, 128))
+}
+
+func TestSourceColorFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := range 300 {
+		for x := range 400 {
+			c := color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+			if x < 150 {
+				c = color.RGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	source := HctFromARGB(SourceColorFromImage(img))
+	assert.InDelta(t, HctFromARGB(0xff1e88e5).Hue(), source.Hue(), 2)
+
+	gray := image.NewGray(image.Rect(0, 0, 10, 10))
+	assert.Equal(t, FallbackColor, SourceColorFromImage(gray))
+}
@@ -0,0 +1,37 @@
+package material
+
+import "sync"
+
+// TonalPalette is a hue and chroma from which colors of any tone are
+// derived.
+type TonalPalette struct {
+	Hue, Chroma float64
+
+	mu    sync.Mutex
+	cache map[float64]ARGB
+}
+
+func NewTonalPalette(hue, chroma float64) *TonalPalette {
+	return &TonalPalette{Hue: hue, Chroma: chroma, cache: make(map[float64]ARGB)}
+}
+
+func TonalPaletteFromHct(h Hct) *TonalPalette {
+	return NewTonalPalette(h.hue, h.chroma)
+}
+
+// Tone returns the palette color at the given tone, 0 being black and 100
+// white.
+func (p *TonalPalette) Tone(tone float64) ARGB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.cache[tone]; ok {
+		return c
+	}
+	c := solveToARGB(p.Hue, p.Chroma, tone)
+	p.cache[tone] = c
+	return c
+}
+
+func (p *TonalPalette) Hct(tone float64) Hct {
+	return HctFromARGB(p.Tone(tone))
+}
@@ -0,0 +1,421 @@
+package material
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Population is a quantized color and the number of pixels it stands for.
+type Population struct {
+	Color ARGB
+	Count int
+}
+
+// QuantizeCelebi reduces pixels to at most maxColors. Wu's quantizer
+// supplies the starting clusters, which weighted k-means in Lab then
+// refines; this is the quantizer Material You uses for wallpapers.
+func QuantizeCelebi(pixels []ARGB, maxColors int) []Population {
+	return quantizeWsmeans(pixels, quantizeWu(pixels, maxColors), maxColors)
+}
+
+const (
+	wuIndexBits  = 5
+	wuSideLength = 33
+	wuTotalSize  = wuSideLength * wuSideLength * wuSideLength
+)
+
+type wuBox struct {
+	r0, r1, g0, g1, b0, b1, vol int
+}
+
+type wuQuantizer struct {
+	weights, momentsR, momentsG, momentsB, moments []float64
+	cubes                                          []wuBox
+}
+
+type wuDirection int
+
+const (
+	wuRed wuDirection = iota
+	wuGreen
+	wuBlue
+)
+
+func wuIndex(r, g, b int) int {
+	return r*wuSideLength*wuSideLength + g*wuSideLength + b
+}
+
+func quantizeWu(pixels []ARGB, maxColors int) []ARGB {
+	q := &wuQuantizer{
+		weights:  make([]float64, wuTotalSize),
+		momentsR: make([]float64, wuTotalSize),
+		momentsG: make([]float64, wuTotalSize),
+		momentsB: make([]float64, wuTotalSize),
+		moments:  make([]float64, wuTotalSize),
+	}
+	q.histogram(pixels)
+	q.computeMoments()
+	return q.result(q.createBoxes(maxColors))
+}
+
+func (q *wuQuantizer) histogram(pixels []ARGB) {
+	counts := make(map[ARGB]int)
+	order := make([]ARGB, 0)
+	for _, p := range pixels {
+		if counts[p] == 0 {
+			order = append(order, p)
+		}
+		counts[p]++
+	}
+	const shift = 8 - wuIndexBits
+	for _, p := range order {
+		count := float64(counts[p])
+		r, g, b := int(p.Red()), int(p.Green()), int(p.Blue())
+		i := wuIndex(r>>shift+1, g>>shift+1, b>>shift+1)
+		q.weights[i] += count
+		q.momentsR[i] += count * float64(r)
+		q.momentsG[i] += count * float64(g)
+		q.momentsB[i] += count * float64(b)
+		q.moments[i] += count * float64(r*r+g*g+b*b)
+	}
+}
+
+// computeMoments turns the histogram into cumulative moments so any box's
+// totals can be read with eight lookups.
+func (q *wuQuantizer) computeMoments() {
+	for r := 1; r < wuSideLength; r++ {
+		var area, areaR, areaG, areaB, area2 [wuSideLength]float64
+		for g := 1; g < wuSideLength; g++ {
+			var line, lineR, lineG, lineB, line2 float64
+			for b := 1; b < wuSideLength; b++ {
+				i := wuIndex(r, g, b)
+				line += q.weights[i]
+				lineR += q.momentsR[i]
+				lineG += q.momentsG[i]
+				lineB += q.momentsB[i]
+				line2 += q.moments[i]
+
+				area[b] += line
+				areaR[b] += lineR
+				areaG[b] += lineG
+				areaB[b] += lineB
+				area2[b] += line2
+
+				prev := wuIndex(r-1, g, b)
+				q.weights[i] = q.weights[prev] + area[b]
+				q.momentsR[i] = q.momentsR[prev] + areaR[b]
+				q.momentsG[i] = q.momentsG[prev] + areaG[b]
+				q.momentsB[i] = q.momentsB[prev] + areaB[b]
+				q.moments[i] = q.moments[prev] + area2[b]
+			}
+		}
+	}
+}
+
+func (q *wuQuantizer) createBoxes(maxColors int) int {
+	q.cubes = make([]wuBox, maxColors)
+	variance := make([]float64, maxColors)
+	q.cubes[0] = wuBox{r1: wuSideLength - 1, g1: wuSideLength - 1, b1: wuSideLength - 1}
+
+	generated := maxColors
+	next := 0
+	for i := 1; i < maxColors; i++ {
+		if q.cut(&q.cubes[next], &q.cubes[i]) {
+			variance[next] = 0
+			if q.cubes[next].vol > 1 {
+				variance[next] = q.variance(q.cubes[next])
+			}
+			variance[i] = 0
+			if q.cubes[i].vol > 1 {
+				variance[i] = q.variance(q.cubes[i])
+			}
+		} else {
+			variance[next] = 0
+			i--
+		}
+
+		next = 0
+		temp := variance[0]
+		for j := 1; j <= i; j++ {
+			if variance[j] > temp {
+				temp = variance[j]
+				next = j
+			}
+		}
+		if temp <= 0 {
+			generated = i + 1
+			break
+		}
+	}
+	return generated
+}
+
+func (q *wuQuantizer) result(count int) []ARGB {
+	var colors []ARGB
+	for _, cube := range q.cubes[:count] {
+		weight := volume(cube, q.weights)
+		if weight <= 0 {
+			continue
+		}
+		r := math.Round(volume(cube, q.momentsR) / weight)
+		g := math.Round(volume(cube, q.momentsG) / weight)
+		b := math.Round(volume(cube, q.momentsB) / weight)
+		colors = append(colors, ARGBFromRGB(uint8(r), uint8(g), uint8(b)))
+	}
+	return colors
+}
+
+func (q *wuQuantizer) variance(cube wuBox) float64 {
+	dr := volume(cube, q.momentsR)
+	dg := volume(cube, q.momentsG)
+	db := volume(cube, q.momentsB)
+	xx := volume(cube, q.moments)
+	return xx - (dr*dr+dg*dg+db*db)/volume(cube, q.weights)
+}
+
+func (q *wuQuantizer) cut(one, two *wuBox) bool {
+	wholeR := volume(*one, q.momentsR)
+	wholeG := volume(*one, q.momentsG)
+	wholeB := volume(*one, q.momentsB)
+	wholeW := volume(*one, q.weights)
+
+	cutR, maxR := q.maximize(*one, wuRed, one.r0+1, one.r1, wholeR, wholeG, wholeB, wholeW)
+	cutG, maxG := q.maximize(*one, wuGreen, one.g0+1, one.g1, wholeR, wholeG, wholeB, wholeW)
+	cutB, maxB := q.maximize(*one, wuBlue, one.b0+1, one.b1, wholeR, wholeG, wholeB, wholeW)
+
+	var direction wuDirection
+	switch {
+	case maxR >= maxG && maxR >= maxB:
+		if cutR < 0 {
+			return false
+		}
+		direction = wuRed
+	case maxG >= maxR && maxG >= maxB:
+		direction = wuGreen
+	default:
+		direction = wuBlue
+	}
+
+	two.r1, two.g1, two.b1 = one.r1, one.g1, one.b1
+	switch direction {
+	case wuRed:
+		one.r1 = cutR
+		two.r0, two.g0, two.b0 = one.r1, one.g0, one.b0
+	case wuGreen:
+		one.g1 = cutG
+		two.r0, two.g0, two.b0 = one.r0, one.g1, one.b0
+	case wuBlue:
+		one.b1 = cutB
+		two.r0, two.g0, two.b0 = one.r0, one.g0, one.b1
+	}
+	one.vol = (one.r1 - one.r0) * (one.g1 - one.g0) * (one.b1 - one.b0)
+	two.vol = (two.r1 - two.r0) * (two.g1 - two.g0) * (two.b1 - two.b0)
+	return true
+}
+
+func (q *wuQuantizer) maximize(cube wuBox, direction wuDirection, first, last int, wholeR, wholeG, wholeB, wholeW float64) (int, float64) {
+	bottomR := bottom(cube, direction, q.momentsR)
+	bottomG := bottom(cube, direction, q.momentsG)
+	bottomB := bottom(cube, direction, q.momentsB)
+	bottomW := bottom(cube, direction, q.weights)
+
+	maxVal, cut := 0.0, -1
+	for i := first; i < last; i++ {
+		halfR := bottomR + top(cube, direction, i, q.momentsR)
+		halfG := bottomG + top(cube, direction, i, q.momentsG)
+		halfB := bottomB + top(cube, direction, i, q.momentsB)
+		halfW := bottomW + top(cube, direction, i, q.weights)
+		if halfW == 0 {
+			continue
+		}
+		temp := (halfR*halfR + halfG*halfG + halfB*halfB) / halfW
+
+		halfR, halfG, halfB, halfW = wholeR-halfR, wholeG-halfG, wholeB-halfB, wholeW-halfW
+		if halfW == 0 {
+			continue
+		}
+		temp += (halfR*halfR + halfG*halfG + halfB*halfB) / halfW
+
+		if temp > maxVal {
+			maxVal = temp
+			cut = i
+		}
+	}
+	return cut, maxVal
+}
+
+func volume(c wuBox, m []float64) float64 {
+	return m[wuIndex(c.r1, c.g1, c.b1)] - m[wuIndex(c.r1, c.g1, c.b0)] -
+		m[wuIndex(c.r1, c.g0, c.b1)] + m[wuIndex(c.r1, c.g0, c.b0)] -
+		m[wuIndex(c.r0, c.g1, c.b1)] + m[wuIndex(c.r0, c.g1, c.b0)] +
+		m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+}
+
+func bottom(c wuBox, direction wuDirection, m []float64) float64 {
+	switch direction {
+	case wuRed:
+		return -m[wuIndex(c.r0, c.g1, c.b1)] + m[wuIndex(c.r0, c.g1, c.b0)] +
+			m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	case wuGreen:
+		return -m[wuIndex(c.r1, c.g0, c.b1)] + m[wuIndex(c.r1, c.g0, c.b0)] +
+			m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	default:
+		return -m[wuIndex(c.r1, c.g1, c.b0)] + m[wuIndex(c.r1, c.g0, c.b0)] +
+			m[wuIndex(c.r0, c.g1, c.b0)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	}
+}
+
+func top(c wuBox, direction wuDirection, position int, m []float64) float64 {
+	switch direction {
+	case wuRed:
+		return m[wuIndex(position, c.g1, c.b1)] - m[wuIndex(position, c.g1, c.b0)] -
+			m[wuIndex(position, c.g0, c.b1)] + m[wuIndex(position, c.g0, c.b0)]
+	case wuGreen:
+		return m[wuIndex(c.r1, position, c.b1)] - m[wuIndex(c.r1, position, c.b0)] -
+			m[wuIndex(c.r0, position, c.b1)] + m[wuIndex(c.r0, position, c.b0)]
+	default:
+		return m[wuIndex(c.r1, c.g1, position)] - m[wuIndex(c.r1, c.g0, position)] -
+			m[wuIndex(c.r0, c.g1, position)] + m[wuIndex(c.r0, c.g0, position)]
+	}
+}
+
+const (
+	wsmeansMaxIterations       = 10
+	wsmeansMinMovementDistance = 3.0
+)
+
+type distanceAndIndex struct {
+	distance float64
+	index    int
+}
+
+func labDistance(a, b [3]float64) float64 {
+	dl, da, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dl*dl + da*da + db*db
+}
+
+// quantizeWsmeans runs weighted k-means in Lab, seeded with the starting
+// clusters. The random source is fixed so results are reproducible.
+func quantizeWsmeans(pixels, startingClusters []ARGB, maxColors int) []Population {
+	rng := rand.New(rand.NewSource(0x42688))
+
+	counts := make(map[ARGB]int)
+	var unique []ARGB
+	for _, p := range pixels {
+		if counts[p] == 0 {
+			unique = append(unique, p)
+		}
+		counts[p]++
+	}
+	points := make([][3]float64, len(unique))
+	weights := make([]int, len(unique))
+	for i, p := range unique {
+		points[i] = labFromARGB(p)
+		weights[i] = counts[p]
+	}
+
+	clusterCount := min(maxColors, len(unique))
+	if len(startingClusters) > 0 {
+		clusterCount = min(clusterCount, len(startingClusters))
+	}
+	if clusterCount == 0 {
+		return nil
+	}
+
+	clusters := make([][3]float64, 0, clusterCount)
+	for _, c := range startingClusters[:min(len(startingClusters), clusterCount)] {
+		clusters = append(clusters, labFromARGB(c))
+	}
+	if len(startingClusters) == 0 {
+		for len(clusters) < clusterCount {
+			clusters = append(clusters, [3]float64{rng.Float64() * 100, rng.Float64()*200 - 100, rng.Float64()*200 - 100})
+		}
+	}
+
+	clusterIndices := make([]int, len(points))
+	for i := range clusterIndices {
+		clusterIndices[i] = rng.Intn(clusterCount)
+	}
+
+	distances := make([][]distanceAndIndex, clusterCount)
+	for i := range distances {
+		distances[i] = make([]distanceAndIndex, clusterCount)
+		for j := range distances[i] {
+			distances[i][j] = distanceAndIndex{distance: -1, index: -1}
+		}
+	}
+	pixelCountSums := make([]int, clusterCount)
+
+	for iteration := range wsmeansMaxIterations {
+		for i := range clusterCount {
+			for j := i + 1; j < clusterCount; j++ {
+				d := labDistance(clusters[i], clusters[j])
+				distances[j][i] = distanceAndIndex{distance: d, index: i}
+				distances[i][j] = distanceAndIndex{distance: d, index: j}
+			}
+			sort.SliceStable(distances[i], func(a, b int) bool {
+				return distances[i][a].distance < distances[i][b].distance
+			})
+		}
+
+		moved := 0
+		for i, point := range points {
+			prev := clusterIndices[i]
+			prevDistance := labDistance(point, clusters[prev])
+			minDistance := prevDistance
+			newIndex := -1
+			for j := range clusterCount {
+				if distances[prev][j].distance >= 4*prevDistance {
+					continue
+				}
+				d := labDistance(point, clusters[j])
+				if d < minDistance {
+					minDistance = d
+					newIndex = j
+				}
+			}
+			if newIndex != -1 && math.Abs(math.Sqrt(minDistance)-math.Sqrt(prevDistance)) > wsmeansMinMovementDistance {
+				moved++
+				clusterIndices[i] = newIndex
+			}
+		}
+		if moved == 0 && iteration != 0 {
+			break
+		}
+
+		sums := make([][3]float64, clusterCount)
+		clear(pixelCountSums)
+		for i, point := range points {
+			c := clusterIndices[i]
+			w := weights[i]
+			pixelCountSums[c] += w
+			sums[c][0] += point[0] * float64(w)
+			sums[c][1] += point[1] * float64(w)
+			sums[c][2] += point[2] * float64(w)
+		}
+		for i := range clusters {
+			n := float64(pixelCountSums[i])
+			if n == 0 {
+				clusters[i] = [3]float64{}
+				continue
+			}
+			clusters[i] = [3]float64{sums[i][0] / n, sums[i][1] / n, sums[i][2] / n}
+		}
+	}
+
+	var result []Population
+	seen := make(map[ARGB]bool)
+	for i, cluster := range clusters {
+		if pixelCountSums[i] == 0 {
+			continue
+		}
+		c := argbFromLab(cluster)
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		result = append(result, Population{Color: c, Count: pixelCountSums[i]})
+	}
+	return result
+}
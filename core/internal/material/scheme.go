@@ -0,0 +1,193 @@
+package material
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Variant int
+
+const (
+	VariantTonalSpot Variant = iota
+	VariantVibrant
+	VariantExpressive
+	VariantFidelity
+	VariantContent
+	VariantMonochrome
+	VariantNeutral
+	VariantRainbow
+	VariantFruitSalad
+)
+
+var variantNames = map[Variant]string{
+	VariantTonalSpot:  "tonal-spot",
+	VariantVibrant:    "vibrant",
+	VariantExpressive: "expressive",
+	VariantFidelity:   "fidelity",
+	VariantContent:    "content",
+	VariantMonochrome: "monochrome",
+	VariantNeutral:    "neutral",
+	VariantRainbow:    "rainbow",
+	VariantFruitSalad: "fruit-salad",
+}
+
+func (v Variant) String() string {
+	return variantNames[v]
+}
+
+// ParseVariant accepts both the bare name and matugen's scheme- prefixed
+// form, e.g. "tonal-spot" or "scheme-tonal-spot".
+func ParseVariant(s string) (Variant, error) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "scheme-")
+	for v, n := range variantNames {
+		if n == name {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown scheme type %q", s)
+}
+
+// Scheme is a set of tonal palettes derived from a source color. The
+// named roles such as primary or on_surface are resolved from it by
+// Colors.
+type Scheme struct {
+	Source        Hct
+	Variant       Variant
+	IsDark        bool
+	ContrastLevel float64
+
+	Primary        *TonalPalette
+	Secondary      *TonalPalette
+	Tertiary       *TonalPalette
+	Neutral        *TonalPalette
+	NeutralVariant *TonalPalette
+	Error          *TonalPalette
+}
+
+var (
+	vibrantHues              = []float64{0, 41, 61, 101, 131, 181, 251, 301, 360}
+	vibrantSecondaryRotation = []float64{18, 15, 10, 12, 15, 18, 15, 12, 12}
+	vibrantTertiaryRotation  = []float64{35, 30, 20, 25, 30, 35, 30, 25, 25}
+
+	expressiveHues              = []float64{0, 21, 51, 121, 151, 191, 271, 321, 360}
+	expressiveSecondaryRotation = []float64{45, 95, 45, 20, 45, 90, 45, 45, 45}
+	expressiveTertiaryRotation  = []float64{120, 120, 20, 45, 20, 15, 20, 120, 120}
+)
+
+// NewScheme builds a scheme for the source color. contrastLevel ranges
+// from -1 (reduced) through 0 (standard) to 1 (high).
+func NewScheme(source ARGB, variant Variant, isDark bool, contrastLevel float64) *Scheme {
+	src := HctFromARGB(source)
+	hue, chroma := src.hue, src.chroma
+	s := &Scheme{
+		Source:        src,
+		Variant:       variant,
+		IsDark:        isDark,
+		ContrastLevel: clampFloat(-1, 1, contrastLevel),
+		Error:         NewTonalPalette(25, 84),
+	}
+
+	switch variant {
+	case VariantVibrant:
+		s.Primary = NewTonalPalette(hue, 200)
+		s.Secondary = NewTonalPalette(rotatedHue(src, vibrantHues, vibrantSecondaryRotation), 24)
+		s.Tertiary = NewTonalPalette(rotatedHue(src, vibrantHues, vibrantTertiaryRotation), 32)
+		s.Neutral = NewTonalPalette(hue, 10)
+		s.NeutralVariant = NewTonalPalette(hue, 12)
+	case VariantExpressive:
+		s.Primary = NewTonalPalette(sanitizeDegrees(hue+240), 40)
+		s.Secondary = NewTonalPalette(rotatedHue(src, expressiveHues, expressiveSecondaryRotation), 24)
+		s.Tertiary = NewTonalPalette(rotatedHue(src, expressiveHues, expressiveTertiaryRotation), 32)
+		s.Neutral = NewTonalPalette(sanitizeDegrees(hue+15), 8)
+		s.NeutralVariant = NewTonalPalette(sanitizeDegrees(hue+15), 12)
+	case VariantFidelity, VariantContent:
+		s.Primary = NewTonalPalette(hue, chroma)
+		s.Secondary = NewTonalPalette(hue, math.Max(chroma-32, chroma*0.5))
+		var tertiary Hct
+		if variant == VariantFidelity {
+			tertiary = newTemperatureCache(src).complement()
+		} else {
+			tertiary = newTemperatureCache(src).analogous(3, 6)[2]
+		}
+		s.Tertiary = TonalPaletteFromHct(fixIfDisliked(tertiary))
+		s.Neutral = NewTonalPalette(hue, chroma/8)
+		s.NeutralVariant = NewTonalPalette(hue, chroma/8+4)
+	case VariantMonochrome:
+		s.Primary = NewTonalPalette(hue, 0)
+		s.Secondary = NewTonalPalette(hue, 0)
+		s.Tertiary = NewTonalPalette(hue, 0)
+		s.Neutral = NewTonalPalette(hue, 0)
+		s.NeutralVariant = NewTonalPalette(hue, 0)
+	case VariantNeutral:
+		s.Primary = NewTonalPalette(hue, 12)
+		s.Secondary = NewTonalPalette(hue, 8)
+		s.Tertiary = NewTonalPalette(hue, 16)
+		s.Neutral = NewTonalPalette(hue, 2)
+		s.NeutralVariant = NewTonalPalette(hue, 2)
+	case VariantRainbow:
+		s.Primary = NewTonalPalette(hue, 48)
+		s.Secondary = NewTonalPalette(hue, 16)
+		s.Tertiary = NewTonalPalette(sanitizeDegrees(hue+60), 24)
+		s.Neutral = NewTonalPalette(hue, 0)
+		s.NeutralVariant = NewTonalPalette(hue, 0)
+	case VariantFruitSalad:
+		s.Primary = NewTonalPalette(sanitizeDegrees(hue-50), 48)
+		s.Secondary = NewTonalPalette(sanitizeDegrees(hue-50), 36)
+		s.Tertiary = NewTonalPalette(hue, 36)
+		s.Neutral = NewTonalPalette(hue, 10)
+		s.NeutralVariant = NewTonalPalette(hue, 16)
+	default:
+		s.Variant = VariantTonalSpot
+		s.Primary = NewTonalPalette(hue, 36)
+		s.Secondary = NewTonalPalette(hue, 16)
+		s.Tertiary = NewTonalPalette(sanitizeDegrees(hue+60), 24)
+		s.Neutral = NewTonalPalette(hue, 6)
+		s.NeutralVariant = NewTonalPalette(hue, 8)
+	}
+	return s
+}
+
+// rotatedHue rotates the source hue by the amount for the range it falls
+// in.
+func rotatedHue(source Hct, hues, rotations []float64) float64 {
+	for i := 0; i < len(hues)-1; i++ {
+		if hues[i] < source.hue && source.hue < hues[i+1] {
+			return sanitizeDegrees(source.hue + rotations[i])
+		}
+	}
+	return source.hue
+}
+
+// Colors resolves every Material color role, keyed by the snake_case names
+// matugen uses (primary, on_primary_container, surface_container_high...).
+func (s *Scheme) Colors() map[string]ARGB {
+	colors := make(map[string]ARGB, len(materialColors)+1)
+	for _, dc := range materialColors {
+		colors[dc.name] = dc.argb(s)
+	}
+	colors["source_color"] = s.Source.argb
+	return colors
+}
+
+// Color resolves a single role by name.
+func (s *Scheme) Color(name string) (ARGB, bool) {
+	if name == "source_color" {
+		return s.Source.argb, true
+	}
+	dc, ok := materialColorsByName[name]
+	if !ok {
+		return 0, false
+	}
+	return dc.argb(s), true
+}
+
+// ColorNames lists the roles returned by Colors in a stable order.
+func ColorNames() []string {
+	names := make([]string, 0, len(materialColors)+1)
+	names = append(names, "source_color")
+	for _, dc := range materialColors {
+		names = append(names, dc.name)
+	}
+	return names
+}
@@ -0,0 +1,154 @@
+package material
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// FallbackColor is Google Blue, used when an image has no usable color.
+const FallbackColor ARGB = 0xff4285f4
+
+const (
+	scoreTargetChroma            = 48.0
+	scoreWeightProportion        = 0.7
+	scoreWeightChromaAbove       = 0.3
+	scoreWeightChromaBelow       = 0.1
+	scoreCutoffChroma            = 5.0
+	scoreCutoffExcitedProportion = 0.01
+)
+
+// Score ranks quantized colors by how suitable they are as a theme
+// source: colorful, and common either by themselves or together with
+// nearby hues. Up to desired colors are returned, spread apart in hue.
+// With filter set, grays and rare hues are dropped.
+func Score(populations []Population, desired int, filter bool) []ARGB {
+	var huePopulation [360]float64
+	var total float64
+	hcts := make([]Hct, len(populations))
+	for i, p := range populations {
+		hcts[i] = HctFromARGB(p.Color)
+		huePopulation[int(math.Floor(hcts[i].hue))%360] += float64(p.Count)
+		total += float64(p.Count)
+	}
+
+	var excited [360]float64
+	if total > 0 {
+		for hue := range 360 {
+			proportion := huePopulation[hue] / total
+			for i := hue - 14; i < hue+16; i++ {
+				excited[sanitizeDegreesInt(i)] += proportion
+			}
+		}
+	}
+
+	type scored struct {
+		hct   Hct
+		score float64
+	}
+	var candidates []scored
+	for _, h := range hcts {
+		proportion := excited[sanitizeDegreesInt(int(math.Round(h.hue)))]
+		if filter && (h.chroma < scoreCutoffChroma || proportion <= scoreCutoffExcitedProportion) {
+			continue
+		}
+		chromaWeight := scoreWeightChromaAbove
+		if h.chroma < scoreTargetChroma {
+			chromaWeight = scoreWeightChromaBelow
+		}
+		score := proportion*100*scoreWeightProportion + (h.chroma-scoreTargetChroma)*chromaWeight
+		candidates = append(candidates, scored{h, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var chosen []Hct
+	for minDifference := 90; minDifference >= 15; minDifference-- {
+		chosen = chosen[:0]
+		for _, c := range candidates {
+			duplicate := false
+			for _, ch := range chosen {
+				if differenceDegrees(c.hct.hue, ch.hue) < float64(minDifference) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				chosen = append(chosen, c.hct)
+			}
+			if len(chosen) >= desired {
+				break
+			}
+		}
+		if len(chosen) >= desired {
+			break
+		}
+	}
+
+	if len(chosen) == 0 {
+		return []ARGB{FallbackColor}
+	}
+	colors := make([]ARGB, len(chosen))
+	for i, h := range chosen {
+		colors[i] = h.argb
+	}
+	return colors
+}
+
+// maxSourceImageSide bounds the image before quantizing. Wallpapers are
+// large and the dominant colors survive downscaling, so there is no point
+// clustering millions of pixels.
+const maxSourceImageSide = 128
+
+// SourceColorsFromImage returns up to desired theme source colors from an
+// image, best first.
+func SourceColorsFromImage(img image.Image, desired int) []ARGB {
+	pixels := imagePixels(img, maxSourceImageSide)
+	return Score(QuantizeCelebi(pixels, 128), desired, true)
+}
+
+// SourceColorFromImage returns the best theme source color for an image.
+func SourceColorFromImage(img image.Image) ARGB {
+	return SourceColorsFromImage(img, 4)[0]
+}
+
+// imagePixels box-filters the image down so neither side exceeds maxSide
+// and returns its opaque pixels.
+func imagePixels(img image.Image, maxSide int) []ARGB {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+	scale := math.Max(1, float64(max(w, h))/float64(maxSide))
+	outW := max(1, int(float64(w)/scale))
+	outH := max(1, int(float64(h)/scale))
+
+	pixels := make([]ARGB, 0, outW*outH)
+	for oy := range outH {
+		y0 := bounds.Min.Y + oy*h/outH
+		y1 := max(y0+1, bounds.Min.Y+(oy+1)*h/outH)
+		for ox := range outW {
+			x0 := bounds.Min.X + ox*w/outW
+			x1 := max(x0+1, bounds.Min.X+(ox+1)*w/outW)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := img.At(x, y).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			if a/n < 0xffff {
+				continue
+			}
+			pixels = append(pixels, ARGBFromRGB(uint8(r/n>>8), uint8(g/n>>8), uint8(b/n>>8)))
+		}
+	}
+	return pixels
+}
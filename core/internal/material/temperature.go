@@ -0,0 +1,161 @@
+package material
+
+import (
+	"math"
+	"sort"
+)
+
+// temperatureCache finds complementary and analogous colors by warmth
+// rather than by hue angle, which matches how people perceive them.
+type temperatureCache struct {
+	input      Hct
+	hctsByHue  []Hct
+	hctsByTemp []Hct
+	temps      map[ARGB]float64
+}
+
+func newTemperatureCache(input Hct) *temperatureCache {
+	tc := &temperatureCache{input: input, temps: make(map[ARGB]float64)}
+	for hue := 0; hue <= 360; hue++ {
+		tc.hctsByHue = append(tc.hctsByHue, NewHct(float64(hue), input.chroma, input.tone))
+	}
+
+	tc.hctsByTemp = append(append([]Hct(nil), tc.hctsByHue...), input)
+	for _, h := range tc.hctsByTemp {
+		tc.temps[h.argb] = rawTemperature(h)
+	}
+	sort.SliceStable(tc.hctsByTemp, func(i, j int) bool {
+		return tc.temps[tc.hctsByTemp[i].argb] < tc.temps[tc.hctsByTemp[j].argb]
+	})
+	return tc
+}
+
+// rawTemperature is warmth on a scale of roughly -0.5 (cool) to 1.9
+// (warm), from Ou, Woodcock and Wright's color-emotion model.
+func rawTemperature(h Hct) float64 {
+	lab := labFromARGB(h.argb)
+	hue := sanitizeDegrees(math.Atan2(lab[2], lab[1]) * 180 / math.Pi)
+	chroma := math.Hypot(lab[1], lab[2])
+	return -0.5 + 0.02*math.Pow(chroma, 1.07)*math.Cos(sanitizeDegrees(hue-50)*math.Pi/180)
+}
+
+func (tc *temperatureCache) coldest() Hct { return tc.hctsByTemp[0] }
+func (tc *temperatureCache) warmest() Hct { return tc.hctsByTemp[len(tc.hctsByTemp)-1] }
+
+func (tc *temperatureCache) relativeTemperature(h Hct) float64 {
+	coldest := tc.temps[tc.coldest().argb]
+	rng := tc.temps[tc.warmest().argb] - coldest
+	if rng == 0 {
+		return 0.5
+	}
+	return (tc.temps[h.argb] - coldest) / rng
+}
+
+func isBetween(angle, a, b float64) bool {
+	if a < b {
+		return a <= angle && angle <= b
+	}
+	return a <= angle || angle <= b
+}
+
+// complement returns the color of opposite relative temperature.
+func (tc *temperatureCache) complement() Hct {
+	coldestHue := tc.coldest().hue
+	coldestTemp := tc.temps[tc.coldest().argb]
+	warmestHue := tc.warmest().hue
+	rng := tc.temps[tc.warmest().argb] - coldestTemp
+
+	startHue, endHue := coldestHue, warmestHue
+	if isBetween(tc.input.hue, coldestHue, warmestHue) {
+		startHue, endHue = warmestHue, coldestHue
+	}
+
+	smallestError := 1000.0
+	answer := tc.hctsByHue[int(math.Round(tc.input.hue))]
+	complementTemp := 1 - tc.relativeTemperature(tc.input)
+	for addend := 0; addend <= 360; addend++ {
+		hue := sanitizeDegrees(startHue + float64(addend))
+		if !isBetween(hue, startHue, endHue) {
+			continue
+		}
+		candidate := tc.hctsByHue[int(math.Round(hue))]
+		err := math.Abs(complementTemp - (tc.temps[candidate.argb]-coldestTemp)/rng)
+		if err < smallestError {
+			smallestError = err
+			answer = candidate
+		}
+	}
+	return answer
+}
+
+// analogous returns count colors spread evenly in temperature around the
+// input, with the input in the middle.
+func (tc *temperatureCache) analogous(count, divisions int) []Hct {
+	startHue := int(math.Round(tc.input.hue))
+	startHct := tc.hctsByHue[startHue]
+	lastTemp := tc.relativeTemperature(startHct)
+	allColors := []Hct{startHct}
+
+	var totalDelta float64
+	for i := range 360 {
+		temp := tc.relativeTemperature(tc.hctsByHue[sanitizeDegreesInt(startHue+i)])
+		totalDelta += math.Abs(temp - lastTemp)
+		lastTemp = temp
+	}
+
+	tempStep := totalDelta / float64(divisions)
+	var walked float64
+	lastTemp = tc.relativeTemperature(startHct)
+	for addend := 1; len(allColors) < divisions; addend++ {
+		h := tc.hctsByHue[sanitizeDegreesInt(startHue+addend)]
+		temp := tc.relativeTemperature(h)
+		walked += math.Abs(temp - lastTemp)
+
+		desired := float64(len(allColors)) * tempStep
+		satisfied := walked >= desired
+		for indexAddend := 1; satisfied && len(allColors) < divisions; indexAddend++ {
+			allColors = append(allColors, h)
+			desired = float64(len(allColors)+indexAddend) * tempStep
+			satisfied = walked >= desired
+		}
+		lastTemp = temp
+
+		if addend+1 > 360 {
+			for len(allColors) < divisions {
+				allColors = append(allColors, h)
+			}
+			break
+		}
+	}
+
+	index := func(i int) int {
+		for i < 0 {
+			i += len(allColors)
+		}
+		return i % len(allColors)
+	}
+	answers := []Hct{tc.input}
+	increase := (count - 1) / 2
+	for i := 1; i <= increase; i++ {
+		answers = append([]Hct{allColors[index(-i)]}, answers...)
+	}
+	decrease := count - increase - 1
+	for i := 1; i <= decrease; i++ {
+		answers = append(answers, allColors[index(i)])
+	}
+	return answers
+}
+
+// isDisliked reports whether a color falls in the dark yellow-green range
+// that is consistently rated unpleasant.
+func isDisliked(h Hct) bool {
+	hue := math.Round(h.hue)
+	return hue >= 90 && hue <= 111 && math.Round(h.chroma) > 16 && math.Round(h.tone) < 65
+}
+
+func fixIfDisliked(h Hct) Hct {
+	if isDisliked(h) {
+		return NewHct(h.hue, h.chroma, 70)
+	}
+	return h
+}
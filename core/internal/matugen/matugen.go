@@ -38,6 +38,7 @@ const (
 	TemplateKindGTK
 	TemplateKindVSCode
 	TemplateKindEmacs
+	TemplateKindManifest
 )
 
 type TemplateDef struct {
@@ -47,6 +48,13 @@ type TemplateDef struct {
 	ConfigFile         string
 	Kind               TemplateKind
 	RunUnconditionally bool
+
+	// Set for templates loaded from a manifest, see TemplateManifest.
+	Input    string
+	Output   string
+	PostHook string
+	Terminal bool
+	Manifest string
 }
 
 var templateRegistry = []TemplateDef{
@@ -100,6 +108,7 @@ type Options struct {
 	Mode                ColorMode
 	IconTheme           string
 	MatugenType         string
+	Backend             Backend
 	Contrast            float64
 	RunUserTemplates    bool
 	ColorsOnly          bool
@@ -158,6 +167,9 @@ func Run(opts Options) error {
 	if opts.IconTheme == "" {
 		opts.IconTheme = "System Default"
 	}
+	if opts.Backend == "" {
+		opts.Backend = DefaultBackend()
+	}
 	if opts.AppChecker == nil {
 		opts.AppChecker = utils.DefaultAppChecker{}
 	}
@@ -166,7 +178,7 @@ func Run(opts Options) error {
 		return fmt.Errorf("failed to create state dir: %w", err)
 	}
 
-	log.Infof("Building theme: %s %s (%s)", opts.Kind, opts.Value, opts.Mode)
+	log.Infof("Building theme: %s %s (%s, %s backend)", opts.Kind, opts.Value, opts.Mode, opts.Backend)
 
 	changed, buildErr := buildOnce(&opts)
 	if buildErr != nil {
@@ -207,7 +219,50 @@ func buildOnce(opts *Options) (bool, error) {
 
 	oldColors, _ := os.ReadFile(opts.ColorsOutput())
 
-	var primaryDark, primaryLight, surface string
+	var primaryDark, primaryLight string
+	switch opts.Backend {
+	case BackendMatugen:
+		primaryDark, primaryLight, err = runMatugenBackend(opts, cfgFile.Name())
+	default:
+		primaryDark, primaryLight, err = runNative(opts, cfgFile.Name())
+	}
+	if err != nil {
+		return false, err
+	}
+
+	newColors, _ := os.ReadFile(opts.ColorsOutput())
+	if bytes.Equal(oldColors, newColors) && len(oldColors) > 0 {
+		return false, nil
+	}
+
+	if opts.ColorsOnly {
+		return true, nil
+	}
+
+	if isDMSGTKActive(opts.ConfigDir) {
+		switch opts.Mode {
+		case ColorModeLight:
+			syncAccentColor(primaryLight)
+		default:
+			syncAccentColor(primaryDark)
+		}
+		refreshGTK(opts.Mode)
+		refreshGTK4()
+	}
+
+	if !opts.ShouldSkipTemplate("qt6ct") && appExists(opts.AppChecker, []string{"qt6ct"}, nil) {
+		refreshQt6ct()
+	}
+
+	signalTerminals(opts)
+
+	return true, nil
+}
+
+// runMatugenBackend generates the palette with the matugen binary, using a
+// dry run to seed dank16 before the real run renders the templates.
+func runMatugenBackend(opts *Options, cfgPath string) (primaryDark, primaryLight string, err error) {
+	var surface string
 	var dank16JSON string
 	var importArgs []string
 
@@ -218,7 +273,7 @@ func buildOnce(opts *Options) (bool, error) {
 		surface = extractNestedColor(opts.StockColors, "surface", "dark")
 
 		if primaryDark == "" {
-			return false, fmt.Errorf("failed to extract primary dark from stock colors")
+			return "", "", fmt.Errorf("failed to extract primary dark from stock colors")
 		}
 		if primaryLight == "" {
 			primaryLight = primaryDark
@@ -229,18 +284,18 @@ func buildOnce(opts *Options) (bool, error) {
 		importArgs = []string{"--import-json-string", importData}
 
 		log.Info("Running matugen color hex with stock color overrides")
-		args := []string{"color", "hex", primaryDark, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name()}
+		args := []string{"color", "hex", primaryDark, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgPath}
 		args = appendContrastArg(args, opts.Contrast)
 		args = append(args, importArgs...)
 		if err := runMatugen(args); err != nil {
-			return false, err
+			return "", "", err
 		}
 	} else {
 		log.Infof("Using dynamic theme from %s: %s", opts.Kind, opts.Value)
 
 		matJSON, err := runMatugenDryRun(opts)
 		if err != nil {
-			return false, fmt.Errorf("matugen dry-run failed: %w", err)
+			return "", "", fmt.Errorf("matugen dry-run failed: %w", err)
 		}
 
 		primaryDark = extractMatugenColor(matJSON, "primary", "dark")
@@ -248,7 +303,7 @@ func buildOnce(opts *Options) (bool, error) {
 		surface = extractMatugenColor(matJSON, "surface", "dark")
 
 		if primaryDark == "" {
-			return false, fmt.Errorf("failed to extract primary color")
+			return "", "", fmt.Errorf("failed to extract primary color")
 		}
 		if primaryLight == "" {
 			primaryLight = primaryDark
@@ -266,41 +321,15 @@ func buildOnce(opts *Options) (bool, error) {
 		default:
 			args = []string{opts.Kind, opts.Value}
 		}
-		args = append(args, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name())
+		args = append(args, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgPath)
 		args = appendContrastArg(args, opts.Contrast)
 		args = append(args, importArgs...)
 		if err := runMatugen(args); err != nil {
-			return false, err
-		}
-	}
-
-	newColors, _ := os.ReadFile(opts.ColorsOutput())
-	if bytes.Equal(oldColors, newColors) && len(oldColors) > 0 {
-		return false, nil
-	}
-
-	if opts.ColorsOnly {
-		return true, nil
-	}
-
-	if isDMSGTKActive(opts.ConfigDir) {
-		switch opts.Mode {
-		case ColorModeLight:
-			syncAccentColor(primaryLight)
-		default:
-			syncAccentColor(primaryDark)
+			return "", "", err
 		}
-		refreshGTK(opts.Mode)
-		refreshGTK4()
 	}
 
-	if !opts.ShouldSkipTemplate("qt6ct") && appExists(opts.AppChecker, []string{"qt6ct"}, nil) {
-		refreshQt6ct()
-	}
-
-	signalTerminals(opts)
-
-	return true, nil
+	return primaryDark, primaryLight, nil
 }
 
 func appendContrastArg(args []string, contrast float64) []string {
@@ -352,7 +381,7 @@ output_path = '%s'
 	}
 
 	homeDir, _ := os.UserHomeDir()
-	for _, tmpl := range templateRegistry {
+	for _, tmpl := range Registry(opts.ConfigDir) {
 		if opts.ShouldSkipTemplate(tmpl.ID) {
 			continue
 		}
@@ -378,6 +407,8 @@ output_path = '%s'
 			if utils.EmacsConfigDir() != "" {
 				appendConfig(opts, cfgFile, tmpl.Commands, tmpl.Flatpaks, tmpl.ConfigFile)
 			}
+		case TemplateKindManifest:
+			appendManifestConfig(opts, cfgFile, tmpDir, tmpl)
 		default:
 			appendConfig(opts, cfgFile, tmpl.Commands, tmpl.Flatpaks, tmpl.ConfigFile)
 		}
@@ -930,17 +961,24 @@ func syncAccentColor(primaryHex string) {
 type TemplateCheck struct {
 	ID       string `json:"id"`
 	Detected bool   `json:"detected"`
+	Source   string `json:"source,omitempty"`
 }
 
-func CheckTemplates(checker utils.AppChecker) []TemplateCheck {
+// CheckTemplates reports which templates would run. configDir locates user
+// and plugin manifests and defaults to the XDG config directory.
+func CheckTemplates(checker utils.AppChecker, configDir string) []TemplateCheck {
 	if checker == nil {
 		checker = utils.DefaultAppChecker{}
 	}
+	if configDir == "" {
+		configDir = utils.XDGConfigHome()
+	}
 
 	homeDir, _ := os.UserHomeDir()
-	checks := make([]TemplateCheck, 0, len(templateRegistry))
+	registry := Registry(configDir)
+	checks := make([]TemplateCheck, 0, len(registry))
 
-	for _, tmpl := range templateRegistry {
+	for _, tmpl := range registry {
 		detected := false
 
 		switch {
@@ -954,7 +992,7 @@ func CheckTemplates(checker utils.AppChecker) []TemplateCheck {
 			detected = appExists(checker, tmpl.Commands, tmpl.Flatpaks)
 		}
 
-		checks = append(checks, TemplateCheck{ID: tmpl.ID, Detected: detected})
+		checks = append(checks, TemplateCheck{ID: tmpl.ID, Detected: detected, Source: tmpl.Manifest})
 	}
 
 	return checks
@@ -441,3 +441,26 @@ func TestBuildMergedConfigColorsOnly(t *testing.T) {
 	assert.NotContains(t, content, "[templates.gtk]")
 	assert.False(t, strings.Contains(content, "output_path = 'CONFIG_DIR/"), "colors-only config should not emit app template outputs")
 }
+
+func TestParseBackendDefault(t *testing.T) {
+	binDir := t.TempDir()
+	t.Setenv("PATH", binDir)
+
+	backend, err := ParseBackend("")
+	assert.NoError(t, err)
+	assert.Equal(t, BackendNative, backend)
+
+	if err := os.WriteFile(filepath.Join(binDir, "matugen"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("failed to create fake matugen: %v", err)
+	}
+	backend, err = ParseBackend("")
+	assert.NoError(t, err)
+	assert.Equal(t, BackendMatugen, backend)
+
+	backend, err = ParseBackend("Native")
+	assert.NoError(t, err)
+	assert.Equal(t, BackendNative, backend)
+
+	_, err = ParseBackend("pywal")
+	assert.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package matugen
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/material"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// Backend selects how the palette is generated and templates rendered.
+type Backend string
+
+const (
+	// BackendNative generates schemes and renders templates in-process.
+	BackendNative Backend = "native"
+	// BackendMatugen shells out to the matugen binary.
+	BackendMatugen Backend = "matugen"
+)
+
+// DefaultBackend keeps the matugen binary when it is installed so existing
+// setups render exactly as before; the native backend covers systems
+// without it.
+func DefaultBackend() Backend {
+	if utils.CommandExists("matugen") {
+		return BackendMatugen
+	}
+	return BackendNative
+}
+
+func ParseBackend(s string) (Backend, error) {
+	switch Backend(strings.ToLower(strings.TrimSpace(s))) {
+	case "":
+		return DefaultBackend(), nil
+	case BackendNative:
+		return BackendNative, nil
+	case BackendMatugen:
+		return BackendMatugen, nil
+	}
+	return "", fmt.Errorf("unknown backend %q (native, matugen)", s)
+}
+
+// sourceColor resolves the seed color for a hex value or a wallpaper.
+func sourceColor(opts *Options) (material.ARGB, error) {
+	switch opts.Kind {
+	case "hex":
+		return material.ParseHex(opts.Value)
+	case "image":
+		f, err := os.Open(opts.Value)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open image: %w", err)
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode image %s: %w", opts.Value, err)
+		}
+		return material.SourceColorFromImage(img), nil
+	}
+	return 0, fmt.Errorf("native backend does not support source kind %q", opts.Kind)
+}
+
+// stockColors is the --stock-colors JSON: {"primary": {"dark": {"color": "#.."}}}.
+type stockColors map[string]map[string]struct {
+	Color string `json:"color"`
+}
+
+// buildNativeContext generates the dark and light schemes and assembles
+// the render context templates see. Stock colors, when given, replace the
+// generated ones role by role.
+func buildNativeContext(opts *Options) (renderContext, error) {
+	variant, err := material.ParseVariant(opts.MatugenType)
+	if err != nil {
+		return nil, err
+	}
+
+	var stock stockColors
+	var source material.ARGB
+	if opts.StockColors != "" {
+		if err := json.Unmarshal([]byte(opts.StockColors), &stock); err != nil {
+			return nil, fmt.Errorf("invalid stock colors: %w", err)
+		}
+		source, err = material.ParseHex(stock["primary"]["dark"].Color)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract primary dark from stock colors")
+		}
+	} else if source, err = sourceColor(opts); err != nil {
+		return nil, err
+	}
+
+	schemes := map[string]*material.Scheme{
+		"dark":  material.NewScheme(source, variant, true, opts.Contrast),
+		"light": material.NewScheme(source, variant, false, opts.Contrast),
+	}
+	defaultMode := "dark"
+	if opts.Mode == ColorModeLight {
+		defaultMode = "light"
+	}
+	modes := map[string]string{"dark": "dark", "light": "light", "default": defaultMode}
+
+	colors := make(map[string]any)
+	for _, name := range material.ColorNames() {
+		byMode := make(map[string]any, len(modes))
+		for mode, schemeMode := range modes {
+			c, _ := schemes[schemeMode].Color(name)
+			byMode[mode] = colorFromARGB(c)
+		}
+		colors[name] = byMode
+	}
+	for name, byMode := range stock {
+		entry, ok := colors[name].(map[string]any)
+		if !ok {
+			entry = make(map[string]any)
+			colors[name] = entry
+		}
+		for mode, v := range byMode {
+			if c, ok := colorFromHex(v.Color); ok {
+				entry[mode] = c
+			}
+		}
+		if _, ok := byMode["default"]; !ok {
+			if _, ok := byMode[defaultMode]; ok {
+				entry["default"] = entry[defaultMode]
+			}
+		}
+	}
+
+	hexOf := func(name, mode string) string {
+		byMode, _ := colors[name].(map[string]any)
+		c, _ := byMode[mode].(templateColor)
+		s, _ := c.format("hex")
+		return s
+	}
+	dank16JSON := generateDank16Variants(hexOf("primary", "dark"), hexOf("primary", "light"), hexOf("surface", "dark"), opts.Mode)
+	var dank16Raw map[string]map[string]struct {
+		Hex string `json:"hex"`
+	}
+	if err := json.Unmarshal([]byte(dank16JSON), &dank16Raw); err != nil {
+		return nil, fmt.Errorf("failed to build dank16 palette: %w", err)
+	}
+	dank16 := make(map[string]any, len(dank16Raw))
+	for name, byMode := range dank16Raw {
+		entry := make(map[string]any, len(byMode))
+		for mode, v := range byMode {
+			if c, ok := colorFromHex(v.Hex); ok {
+				entry[mode] = c
+			}
+		}
+		dank16[name] = entry
+	}
+
+	imagePath := ""
+	if opts.Kind == "image" {
+		imagePath = opts.Value
+	}
+	return renderContext{
+		"colors":       colors,
+		"dank16":       dank16,
+		"image":        imagePath,
+		"mode":         string(opts.Mode),
+		"is_dark_mode": opts.Mode != ColorModeLight,
+	}, nil
+}
+
+// runNative renders every template in the merged config without the
+// matugen binary. A failing template is logged and skipped so one broken
+// user template can't block the rest of the theme, like matugen's
+// --continue-on-error.
+func runNative(opts *Options, cfgPath string) (primaryDark, primaryLight string, err error) {
+	ctx, err := buildNativeContext(opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read config: %w", err)
+	}
+	templates, err := parseTemplateConfigs(string(data))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	log.Infof("Rendering %d templates natively (%s)", len(templates), opts.MatugenType)
+	baseDir := filepath.Dir(cfgPath)
+	for _, t := range templates {
+		if err := renderTemplateFile(t, ctx, baseDir); err != nil {
+			log.Warnf("Template %s: %v", t.Name, err)
+		}
+	}
+
+	dark, _ := ctx.lookup("colors.primary.dark.hex")
+	light, _ := ctx.lookup("colors.primary.light.hex")
+	return fmt.Sprint(dark), fmt.Sprint(light), nil
+}
+
+func renderTemplateFile(t templateConfig, ctx renderContext, baseDir string) error {
+	if t.InputPath == "" || t.OutputPath == "" {
+		return fmt.Errorf("input_path and output_path are required")
+	}
+	if t.PreHook != "" {
+		runHook(t.Name, t.PreHook)
+	}
+
+	src, err := os.ReadFile(expandTemplatePath(t.InputPath, baseDir))
+	if err != nil {
+		return err
+	}
+	out, err := renderTemplate(string(src), ctx)
+	if err != nil {
+		return err
+	}
+
+	outPath := expandTemplatePath(t.OutputPath, baseDir)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outPath, []byte(out), 0o644); err != nil {
+		return err
+	}
+
+	if t.PostHook != "" {
+		runHook(t.Name, t.PostHook)
+	}
+	return nil
+}
+
+func runHook(name, hook string) {
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Warnf("Template %s hook failed: %v", name, err)
+	}
+}
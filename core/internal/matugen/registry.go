@@ -0,0 +1,211 @@
+package matugen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+// TemplateManifest describes a template contributed by a user or plugin,
+// read from a JSON file in one of the manifest directories:
+//
+//	{
+//	  "id": "fuzzel",
+//	  "commands": ["fuzzel"],
+//	  "input": "fuzzel.ini",
+//	  "output": "CONFIG_DIR/fuzzel/colors.ini",
+//	  "postHook": "pkill -USR1 fuzzel"
+//	}
+//
+// Relative input paths are resolved against the manifest's directory.
+// Paths may start with SHELL_DIR/, CONFIG_DIR/, DATA_DIR/, CACHE_DIR/ or ~/.
+// Without commands or flatpaks the template always runs.
+type TemplateManifest struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+	Flatpaks []string `json:"flatpaks,omitempty"`
+	Input    string   `json:"input"`
+	Output   string   `json:"output"`
+	PostHook string   `json:"postHook,omitempty"`
+	// Terminal templates follow --terminals-always-dark like the built-in
+	// terminal templates.
+	Terminal bool `json:"terminal,omitempty"`
+}
+
+var templateIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func (m *TemplateManifest) validate() error {
+	switch {
+	case !templateIDPattern.MatchString(m.ID):
+		return fmt.Errorf("invalid id %q: use lowercase letters, digits, - and _", m.ID)
+	case m.Input == "":
+		return fmt.Errorf("input is required")
+	case m.Output == "":
+		return fmt.Errorf("output is required")
+	}
+	return nil
+}
+
+// manifestDirs lists where manifests are looked up, in priority order:
+// the user's own directory, then installed plugins.
+func manifestDirs(configDir string) []string {
+	dirs := []string{filepath.Join(configDir, "matugen", "dms", "templates")}
+	for _, pluginsDir := range []string{
+		filepath.Join(configDir, "DankMaterialShell", "plugins"),
+		"/etc/xdg/quickshell/dms-plugins",
+	} {
+		matches, _ := filepath.Glob(filepath.Join(pluginsDir, "*", "matugen"))
+		sort.Strings(matches)
+		dirs = append(dirs, matches...)
+	}
+	return dirs
+}
+
+// LoadTemplateManifests reads the template manifests for configDir. Invalid
+// manifests and IDs that are already taken are logged and skipped.
+func LoadTemplateManifests(configDir string) []TemplateDef {
+	taken := make(map[string]bool, len(templateRegistry))
+	for _, tmpl := range templateRegistry {
+		taken[tmpl.ID] = true
+	}
+
+	var defs []TemplateDef
+	for _, dir := range manifestDirs(configDir) {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		sort.Strings(files)
+		for _, path := range files {
+			def, err := loadTemplateManifest(path)
+			if err != nil {
+				log.Warnf("Skipping template manifest %s: %v", path, err)
+				continue
+			}
+			if taken[def.ID] {
+				log.Warnf("Skipping template manifest %s: id %q is already registered", path, def.ID)
+				continue
+			}
+			taken[def.ID] = true
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+func loadTemplateManifest(path string) (TemplateDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TemplateDef{}, err
+	}
+	var m TemplateManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return TemplateDef{}, err
+	}
+	if err := m.validate(); err != nil {
+		return TemplateDef{}, err
+	}
+
+	def := TemplateDef{
+		ID:       m.ID,
+		Kind:     TemplateKindManifest,
+		Input:    m.Input,
+		Output:   m.Output,
+		PostHook: m.PostHook,
+		Terminal: m.Terminal,
+		Manifest: path,
+	}
+	// An empty list means "always run", the same as leaving it out.
+	if len(m.Commands) > 0 {
+		def.Commands = m.Commands
+	}
+	if len(m.Flatpaks) > 0 {
+		def.Flatpaks = m.Flatpaks
+	}
+	return def, nil
+}
+
+// Registry returns the built-in templates followed by user and plugin
+// templates.
+func Registry(configDir string) []TemplateDef {
+	return append(append([]TemplateDef(nil), templateRegistry...), LoadTemplateManifests(configDir)...)
+}
+
+func resolveManifestPath(path, manifestDir, shellDir string) string {
+	prefixes := []struct{ prefix, dir string }{
+		{"SHELL_DIR/", shellDir},
+		{"CONFIG_DIR/", utils.XDGConfigHome()},
+		{"DATA_DIR/", utils.XDGDataHome()},
+		{"CACHE_DIR/", utils.XDGCacheHome()},
+	}
+	for _, p := range prefixes {
+		if rest, ok := strings.CutPrefix(path, p.prefix); ok {
+			return filepath.Join(p.dir, rest)
+		}
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(manifestDir, path)
+	}
+	return path
+}
+
+func appendManifestConfig(opts *Options, cfgFile *os.File, tmpDir string, tmpl TemplateDef) {
+	if !appExists(opts.AppChecker, tmpl.Commands, tmpl.Flatpaks) {
+		return
+	}
+
+	manifestDir := filepath.Dir(tmpl.Manifest)
+	input := resolveManifestPath(tmpl.Input, manifestDir, opts.ShellDir)
+	data, err := os.ReadFile(input)
+	if err != nil {
+		log.Warnf("Template %s: %v", tmpl.ID, err)
+		return
+	}
+	if tmpl.Terminal && opts.TerminalsAlwaysDark {
+		input = filepath.Join(tmpDir, tmpl.ID+"-"+filepath.Base(input))
+		modified := strings.ReplaceAll(string(data), ".default.", ".dark.")
+		if err := os.WriteFile(input, []byte(modified), 0o644); err != nil {
+			log.Warnf("Template %s: %v", tmpl.ID, err)
+			return
+		}
+	}
+
+	fmt.Fprintf(cfgFile, "[templates.dms-%s]\ninput_path = %s\noutput_path = %s\n",
+		tmpl.ID, tomlString(input), tomlString(resolveManifestPath(tmpl.Output, manifestDir, opts.ShellDir)))
+	if tmpl.PostHook != "" {
+		fmt.Fprintf(cfgFile, "post_hook = %s\n", tomlString(tmpl.PostHook))
+	}
+	cfgFile.WriteString("\n")
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			b.WriteString(`\u` + fmt.Sprintf("%04x", r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
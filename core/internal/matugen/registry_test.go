@@ -0,0 +1,131 @@
+package matugen
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAppChecker struct{ commands []string }
+
+func (s stubAppChecker) CommandExists(cmd string) bool { return slices.Contains(s.commands, cmd) }
+func (s stubAppChecker) AnyCommandExists(cmds ...string) bool {
+	return slices.ContainsFunc(cmds, s.CommandExists)
+}
+func (s stubAppChecker) FlatpakExists(string) bool       { return false }
+func (s stubAppChecker) AnyFlatpakExists(...string) bool { return false }
+
+func writeManifest(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadTemplateManifests(t *testing.T) {
+	configDir := t.TempDir()
+	userDir := filepath.Join(configDir, "matugen", "dms", "templates")
+	pluginDir := filepath.Join(configDir, "DankMaterialShell", "plugins", "fuzzelTheme", "matugen")
+
+	fuzzel := writeManifest(t, userDir, "fuzzel.json", `{"id": "fuzzel", "commands": ["fuzzel"], "flatpaks": [], "input": "fuzzel.ini", "output": "CONFIG_DIR/fuzzel/colors.ini"}`)
+	writeManifest(t, userDir, "kitty.json", `{"id": "kitty", "input": "a", "output": "b"}`)
+	writeManifest(t, userDir, "broken.json", `{"id": "broken"`)
+	writeManifest(t, userDir, "noout.json", `{"id": "noout", "input": "a"}`)
+	writeManifest(t, userDir, "badid.json", `{"id": "Bad ID", "input": "a", "output": "b"}`)
+	writeManifest(t, pluginDir, "fuzzel.json", `{"id": "fuzzel", "input": "other", "output": "other"}`)
+	mako := writeManifest(t, pluginDir, "mako.json", `{"id": "mako", "input": "mako", "output": "~/.config/mako/colors", "postHook": "makoctl reload"}`)
+
+	defs := LoadTemplateManifests(configDir)
+	require.Len(t, defs, 2)
+
+	assert.Equal(t, "fuzzel", defs[0].ID)
+	assert.Equal(t, TemplateKindManifest, defs[0].Kind)
+	assert.Equal(t, fuzzel, defs[0].Manifest)
+	assert.Equal(t, []string{"fuzzel"}, defs[0].Commands)
+	assert.Nil(t, defs[0].Flatpaks)
+
+	assert.Equal(t, "mako", defs[1].ID)
+	assert.Equal(t, mako, defs[1].Manifest)
+	assert.Equal(t, "makoctl reload", defs[1].PostHook)
+
+	registry := Registry(configDir)
+	assert.Len(t, registry, len(templateRegistry)+2)
+}
+
+func TestBuildMergedConfigManifests(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config")
+	userDir := filepath.Join(configDir, "matugen", "dms", "templates")
+
+	writeManifest(t, userDir, "fuzzel.json", `{"id": "fuzzel", "commands": ["fuzzel"], "input": "fuzzel.ini", "output": "/out/fuzzel.ini", "postHook": "pkill -USR1 \"fuzzel\""}`)
+	writeManifest(t, userDir, "fuzzel.ini", "bg={{colors.surface.default.hex}}\n")
+	writeManifest(t, userDir, "mako.json", `{"id": "mako", "input": "mako", "output": "/out/mako"}`)
+	writeManifest(t, userDir, "mako", "")
+	writeManifest(t, userDir, "foo.json", `{"id": "foo", "commands": ["foo"], "input": "foo.conf", "output": "/out/foo"}`)
+	writeManifest(t, userDir, "term.json", `{"id": "term", "input": "term.conf", "output": "/out/term", "terminal": true}`)
+	writeManifest(t, userDir, "term.conf", "fg={{colors.on_surface.default.hex}}\n")
+
+	build := func(opts *Options) string {
+		cfgFile, err := os.CreateTemp(tempDir, "merged-*.toml")
+		require.NoError(t, err)
+		defer cfgFile.Close()
+		require.NoError(t, buildMergedConfig(opts, cfgFile, tempDir))
+		data, err := os.ReadFile(cfgFile.Name())
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	opts := &Options{
+		ShellDir:            filepath.Join(tempDir, "shell"),
+		ConfigDir:           configDir,
+		StateDir:            filepath.Join(tempDir, "state"),
+		SkipTemplates:       "gtk,vscode,mako",
+		TerminalsAlwaysDark: true,
+		AppChecker:          stubAppChecker{commands: []string{"fuzzel"}},
+	}
+	content := build(opts)
+
+	assert.Contains(t, content, "[templates.dms-fuzzel]\ninput_path = \""+filepath.Join(userDir, "fuzzel.ini")+"\"\noutput_path = \"/out/fuzzel.ini\"\npost_hook = \"pkill -USR1 \\\"fuzzel\\\"\"\n")
+	assert.NotContains(t, content, "dms-mako", "skipped template")
+	assert.NotContains(t, content, "dms-foo", "app not installed")
+
+	darkTerm := filepath.Join(tempDir, "term-term.conf")
+	assert.Contains(t, content, "[templates.dms-term]\ninput_path = \""+darkTerm+"\"")
+	data, err := os.ReadFile(darkTerm)
+	require.NoError(t, err)
+	assert.Equal(t, "fg={{colors.on_surface.dark.hex}}\n", string(data))
+
+	templates, err := parseTemplateConfigs(content)
+	require.NoError(t, err)
+	assert.True(t, slices.ContainsFunc(templates, func(tc templateConfig) bool {
+		return tc.Name == "dms-fuzzel" && tc.PostHook == `pkill -USR1 "fuzzel"`
+	}))
+}
+
+func TestCheckTemplatesManifests(t *testing.T) {
+	configDir := t.TempDir()
+	userDir := filepath.Join(configDir, "matugen", "dms", "templates")
+	fuzzel := writeManifest(t, userDir, "fuzzel.json", `{"id": "fuzzel", "commands": ["fuzzel"], "input": "a", "output": "b"}`)
+	writeManifest(t, userDir, "foo.json", `{"id": "foo", "commands": ["foo"], "input": "a", "output": "b"}`)
+
+	checks := CheckTemplates(stubAppChecker{commands: []string{"fuzzel"}}, configDir)
+	byID := make(map[string]TemplateCheck, len(checks))
+	for _, c := range checks {
+		byID[c.ID] = c
+	}
+
+	assert.Equal(t, TemplateCheck{ID: "fuzzel", Detected: true, Source: fuzzel}, byID["fuzzel"])
+	assert.False(t, byID["foo"].Detected)
+	assert.Empty(t, byID["kitty"].Source)
+	assert.True(t, strings.HasSuffix(byID["foo"].Source, "foo.json"))
+}
+
+func TestTOMLString(t *testing.T) {
+	assert.Equal(t, `"a \"b\" \\ c\n"`, tomlString("a \"b\" \\ c\n"))
+}
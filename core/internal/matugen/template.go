@@ -0,0 +1,505 @@
+package matugen
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/material"
+)
+
+// templateConfig is one [templates.<name>] entry of a matugen config.
+type templateConfig struct {
+	Name       string
+	InputPath  string
+	OutputPath string
+	PreHook    string
+	PostHook   string
+}
+
+// parseTemplateConfigs reads the [templates.*] tables from the merged
+// matugen config. It understands the subset of TOML the shipped and
+// plugin configs use: table headers, comments and key = 'string' pairs.
+// Other keys and tables are ignored.
+func parseTemplateConfigs(data string) ([]templateConfig, error) {
+	var templates []templateConfig
+	index := make(map[string]int)
+	current := -1
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			header := strings.TrimSpace(strings.Trim(line, "[]"))
+			name, ok := strings.CutPrefix(header, "templates.")
+			if !ok {
+				current = -1
+				continue
+			}
+			name = strings.Trim(name, `"'`)
+			if i, exists := index[name]; exists {
+				current = i
+				continue
+			}
+			index[name] = len(templates)
+			current = len(templates)
+			templates = append(templates, templateConfig{Name: name})
+			continue
+		}
+
+		if current < 0 {
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+		if !strings.HasPrefix(raw, "'") && !strings.HasPrefix(raw, `"`) {
+			continue
+		}
+		value, err := parseTOMLString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		t := &templates[current]
+		switch key {
+		case "input_path":
+			t.InputPath = value
+		case "output_path":
+			t.OutputPath = value
+		case "pre_hook":
+			t.PreHook = value
+		case "post_hook":
+			t.PostHook = value
+		}
+	}
+	return templates, scanner.Err()
+}
+
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseTOMLString(raw string) (string, error) {
+	if strings.HasPrefix(raw, "'") {
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return raw[1 : end+1], nil
+	}
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return strconv.Unquote(raw[:i+1])
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// templateColor is a color in the render context. Expressions select a
+// format from it, e.g. {{colors.primary.dark.hex_stripped}}.
+type templateColor struct {
+	R, G, B uint8
+	A       float64
+}
+
+func colorFromARGB(c material.ARGB) templateColor {
+	return templateColor{R: c.Red(), G: c.Green(), B: c.Blue(), A: 1}
+}
+
+func colorFromHex(hex string) (templateColor, bool) {
+	c, err := material.ParseHex(hex)
+	if err != nil {
+		return templateColor{}, false
+	}
+	return colorFromARGB(c), true
+}
+
+func (c templateColor) hsl() (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxV, minV := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (maxV + minV) / 2
+	if maxV == minV {
+		return 0, 0, l * 100
+	}
+	d := maxV - minV
+	if l > 0.5 {
+		s = d / (2 - maxV - minV)
+	} else {
+		s = d / (maxV + minV)
+	}
+	switch maxV {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s * 100, l * 100
+}
+
+func fromHSL(h, s, l, a float64) templateColor {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	s = math.Max(0, math.Min(100, s)) / 100
+	l = math.Max(0, math.Min(100, l)) / 100
+	if s == 0 {
+		v := uint8(math.Round(l * 255))
+		return templateColor{v, v, v, a}
+	}
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	hue := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 0.5:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return templateColor{hue(h + 1.0/3), hue(h), hue(h - 1.0/3), a}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func (c templateColor) format(name string) (string, bool) {
+	h, s, l := c.hsl()
+	switch name {
+	case "hex":
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), true
+	case "hex_stripped":
+		return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B), true
+	case "rgb":
+		return fmt.Sprintf("rgb(%d, %d, %d)", c.R, c.G, c.B), true
+	case "rgba":
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.R, c.G, c.B, formatFloat(c.A)), true
+	case "hsl":
+		return fmt.Sprintf("hsl(%s, %s%%, %s%%)", formatFloat(h), formatFloat(s), formatFloat(l)), true
+	case "hsla":
+		return fmt.Sprintf("hsla(%s, %s%%, %s%%, %s)", formatFloat(h), formatFloat(s), formatFloat(l), formatFloat(c.A)), true
+	case "red":
+		return strconv.Itoa(int(c.R)), true
+	case "green":
+		return strconv.Itoa(int(c.G)), true
+	case "blue":
+		return strconv.Itoa(int(c.B)), true
+	case "alpha":
+		return formatFloat(c.A), true
+	case "hue":
+		return formatFloat(h), true
+	case "saturation":
+		return formatFloat(s), true
+	case "lightness":
+		return formatFloat(l), true
+	}
+	return "", false
+}
+
+// renderContext is the data templates are rendered against. Nested maps
+// hold templateColor leaves or plain values.
+type renderContext map[string]any
+
+func (ctx renderContext) lookup(path string) (any, error) {
+	parts := strings.Split(path, ".")
+	var cur any = map[string]any(ctx)
+	for i, part := range parts {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return nil, fmt.Errorf("unknown variable %q", strings.Join(parts[:i+1], "."))
+			}
+			cur = next
+		case templateColor:
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("unknown variable %q", path)
+			}
+			s, ok := v.format(part)
+			if !ok {
+				return nil, fmt.Errorf("unknown color format %q in %q", part, path)
+			}
+			return s, nil
+		default:
+			return nil, fmt.Errorf("unknown variable %q", path)
+		}
+	}
+	return cur, nil
+}
+
+// eval resolves an expression such as "colors.primary.dark.hex" or
+// "colors.surface.default.rgba | set_alpha: 0.8". Filters apply to the
+// color before the trailing format is taken.
+func (ctx renderContext) eval(expr string) (any, error) {
+	segments := strings.Split(expr, "|")
+	path := strings.TrimSpace(segments[0])
+	filters := segments[1:]
+	if len(filters) == 0 {
+		return ctx.lookup(path)
+	}
+
+	colorPath, formatName := path, ""
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		colorPath, formatName = path[:i], path[i+1:]
+	}
+	value, err := ctx.lookup(colorPath)
+	if err != nil {
+		return nil, err
+	}
+	c, isColor := value.(templateColor)
+	if !isColor {
+		value, err = ctx.lookup(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range filters {
+		name, arg, _ := strings.Cut(strings.TrimSpace(f), ":")
+		name, arg = strings.TrimSpace(name), strings.Trim(strings.TrimSpace(arg), `"'`)
+		if isColor {
+			if c, err = applyColorFilter(c, name, arg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		s := fmt.Sprint(value)
+		switch name {
+		case "to_upper":
+			value = strings.ToUpper(s)
+		case "to_lower":
+			value = strings.ToLower(s)
+		default:
+			return nil, fmt.Errorf("unknown filter %q", name)
+		}
+	}
+	if !isColor {
+		return value, nil
+	}
+	s, ok := c.format(formatName)
+	if !ok {
+		return nil, fmt.Errorf("unknown color format %q in %q", formatName, path)
+	}
+	return s, nil
+}
+
+func applyColorFilter(c templateColor, name, arg string) (templateColor, error) {
+	amount := func() (float64, error) {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, fmt.Errorf("filter %s: invalid amount %q", name, arg)
+		}
+		return v, nil
+	}
+	h, s, l := c.hsl()
+	switch name {
+	case "set_alpha":
+		v, err := amount()
+		c.A = math.Max(0, math.Min(1, v))
+		return c, err
+	case "set_lightness":
+		v, err := amount()
+		return fromHSL(h, s, l+v, c.A), err
+	case "set_saturation":
+		v, err := amount()
+		return fromHSL(h, s+v, l, c.A), err
+	case "set_hue":
+		v, err := amount()
+		return fromHSL(h+v, s, l, c.A), err
+	case "grayscale":
+		return fromHSL(h, 0, l, c.A), nil
+	case "invert":
+		return templateColor{255 - c.R, 255 - c.G, 255 - c.B, c.A}, nil
+	case "to_upper", "to_lower":
+		return c, nil
+	}
+	return c, fmt.Errorf("unknown filter %q", name)
+}
+
+// renderTemplate expands {{ expr }} and <* if {{ expr }} *> ... <* else *>
+// ... <* endif *> blocks, the part of matugen's template language the
+// bundled and user templates rely on.
+func renderTemplate(src string, ctx renderContext) (string, error) {
+	var out strings.Builder
+	rest, err := renderBlock(src, ctx, &out, true, 0)
+	if err != nil {
+		return "", err
+	}
+	if rest != "" {
+		return "", fmt.Errorf("unexpected %q", firstLine(rest))
+	}
+	return out.String(), nil
+}
+
+// renderBlock renders until an unmatched else/endif tag or the end of
+// input and returns the unconsumed remainder starting at that tag.
+func renderBlock(src string, ctx renderContext, out *strings.Builder, emit bool, depth int) (string, error) {
+	for {
+		exprAt := strings.Index(src, "{{")
+		tagAt := strings.Index(src, "<*")
+		if exprAt < 0 && tagAt < 0 {
+			if emit {
+				out.WriteString(src)
+			}
+			return "", nil
+		}
+
+		if tagAt < 0 || (exprAt >= 0 && exprAt < tagAt) {
+			end := strings.Index(src[exprAt:], "}}")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated {{ in %q", firstLine(src[exprAt:]))
+			}
+			if emit {
+				out.WriteString(src[:exprAt])
+				value, err := ctx.eval(strings.TrimSpace(src[exprAt+2 : exprAt+end]))
+				if err != nil {
+					return "", err
+				}
+				fmt.Fprint(out, value)
+			}
+			src = src[exprAt+end+2:]
+			continue
+		}
+
+		end := strings.Index(src[tagAt:], "*>")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated <* in %q", firstLine(src[tagAt:]))
+		}
+		if emit {
+			out.WriteString(src[:tagAt])
+		}
+		tag := strings.TrimSpace(src[tagAt+2 : tagAt+end])
+		after := src[tagAt+end+2:]
+
+		switch {
+		case tag == "else" || tag == "endif":
+			if depth == 0 {
+				return "", fmt.Errorf("unexpected <* %s *>", tag)
+			}
+			return src[tagAt:], nil
+		case strings.HasPrefix(tag, "if "):
+			cond := false
+			if emit {
+				var err error
+				if cond, err = ctx.condition(strings.TrimSpace(tag[3:])); err != nil {
+					return "", err
+				}
+			}
+			rest, err := renderBlock(after, ctx, out, emit && cond, depth+1)
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(rest, "<*") && tagName(rest) == "else" {
+				rest, err = renderBlock(afterTag(rest), ctx, out, emit && !cond, depth+1)
+				if err != nil {
+					return "", err
+				}
+			}
+			if tagName(rest) != "endif" {
+				return "", fmt.Errorf("missing <* endif *>")
+			}
+			src = afterTag(rest)
+		default:
+			return "", fmt.Errorf("unsupported template tag <* %s *>", tag)
+		}
+	}
+}
+
+func tagName(s string) string {
+	end := strings.Index(s, "*>")
+	if !strings.HasPrefix(s, "<*") || end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(s[2:end])
+}
+
+func afterTag(s string) string {
+	return s[strings.Index(s, "*>")+2:]
+}
+
+func (ctx renderContext) condition(expr string) (bool, error) {
+	negate := false
+	if rest, ok := strings.CutPrefix(expr, "not "); ok {
+		negate, expr = true, strings.TrimSpace(rest)
+	}
+	expr = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}"))
+	value, err := ctx.eval(expr)
+	if err != nil {
+		return false, err
+	}
+	var truthy bool
+	switch v := value.(type) {
+	case bool:
+		truthy = v
+	case string:
+		truthy = v != "" && v != "false"
+	default:
+		truthy = v != nil
+	}
+	return truthy != negate, nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	if len(line) > 60 {
+		line = line[:60] + "…"
+	}
+	return line
+}
+
+// expandTemplatePath resolves ~ and makes relative paths relative to the
+// config directory, as matugen does.
+func expandTemplatePath(path, baseDir string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok || path == "~" {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(path) && baseDir != "" {
+		return filepath.Join(baseDir, path)
+	}
+	return path
+}
@@ -0,0 +1,129 @@
+package matugen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplateConfigs(t *testing.T) {
+	templates, err := parseTemplateConfigs(`[config]
+version_check = false
+
+[templates.dank]
+input_path = '/shell/dank.json' # trailing comment
+output_path = "/state/dms-colors.json"
+
+[templates.dmspywalfox]
+input_path = '/shell/pywalfox-colors.json'
+output_path = '~/.cache/wal/dank-pywalfox.json'
+post_hook = 'sh -c "command -v pywalfox && pywalfox update"'
+`)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, templateConfig{Name: "dank", InputPath: "/shell/dank.json", OutputPath: "/state/dms-colors.json"}, templates[0])
+	assert.Equal(t, `sh -c "command -v pywalfox && pywalfox update"`, templates[1].PostHook)
+
+	_, err = parseTemplateConfigs("[templates.x]\ninput_path = 'unterminated\n")
+	assert.Error(t, err)
+}
+
+func testContext() renderContext {
+	primary, _ := colorFromHex("#d0bcff")
+	return renderContext{
+		"colors": map[string]any{
+			"primary": map[string]any{"dark": primary, "default": primary},
+		},
+		"image":        "/wall.png",
+		"is_dark_mode": true,
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	ctx := testContext()
+
+	out, err := renderTemplate("a={{colors.primary.dark.hex}} b={{ colors.primary.default.hex_stripped }} r={{colors.primary.dark.red}} i={{image}}", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a=#d0bcff b=d0bcff r=208 i=/wall.png", out)
+
+	out, err = renderTemplate("{{colors.primary.dark.rgba | set_alpha: 0.5}} {{colors.primary.dark.rgb}}", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "rgba(208, 188, 255, 0.5) rgb(208, 188, 255)", out)
+
+	out, err = renderTemplate("blend=<* if {{ is_dark_mode }} *>black<* else *>white<* endif *>;", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "blend=black;", out)
+
+	ctx["is_dark_mode"] = false
+	out, err = renderTemplate("<* if {{ is_dark_mode }} *>x<* if {{ image }} *>y<* endif *><* else *>z<* endif *>", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "z", out)
+
+	for _, bad := range []string{
+		"{{colors.secondary.dark.hex}}",
+		"{{colors.primary.dark.cmyk}}",
+		"{{colors.primary.dark.hex",
+		"<* if {{ is_dark_mode }} *>x",
+		"<* endif *>",
+		"<* for c in colors *><* endfor *>",
+	} {
+		_, err := renderTemplate(bad, ctx)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRunNative(t *testing.T) {
+	tempDir := t.TempDir()
+	shellDir := filepath.Join(tempDir, "shell")
+	templatesDir := filepath.Join(shellDir, "matugen", "templates")
+	require.NoError(t, os.MkdirAll(templatesDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "dank.json"),
+		[]byte(`{"primary": "{{colors.primary.dark.hex}}", "light": "{{colors.primary.light.hex}}", "default": "{{colors.primary.default.hex}}", "c4": "{{dank16.color4.dark.hex}}"}`), 0o644))
+
+	opts := &Options{
+		StateDir:    filepath.Join(tempDir, "state"),
+		ShellDir:    shellDir,
+		ConfigDir:   filepath.Join(tempDir, "config"),
+		Kind:        "hex",
+		Value:       "#0000ff",
+		Mode:        ColorModeLight,
+		MatugenType: "scheme-tonal-spot",
+		ColorsOnly:  true,
+	}
+	cfgPath := filepath.Join(tempDir, "config.toml")
+	cfgFile, err := os.Create(cfgPath)
+	require.NoError(t, err)
+	require.NoError(t, buildMergedConfig(opts, cfgFile, tempDir))
+	cfgFile.Close()
+
+	dark, light, err := runNative(opts, cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#bec2ff", dark)
+	assert.Equal(t, "#555992", light)
+
+	data, err := os.ReadFile(opts.ColorsOutput())
+	require.NoError(t, err)
+	var out map[string]string
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "#bec2ff", out["primary"])
+	assert.Equal(t, "#555992", out["default"])
+	assert.Regexp(t, `^#[0-9a-f]{6}$`, out["c4"])
+
+	opts.StockColors = `{"primary": {"dark": {"color": "#ff0000"}, "light": {"color": "#aa0000"}}}`
+	dark, light, err = runNative(opts, cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#ff0000", dark)
+	assert.Equal(t, "#aa0000", light)
+	data, _ = os.ReadFile(opts.ColorsOutput())
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "#aa0000", out["default"])
+
+	opts.StockColors = ""
+	opts.Kind = "video"
+	_, _, err = runNative(opts, cfgPath)
+	assert.Error(t, err)
+}
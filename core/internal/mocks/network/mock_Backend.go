@@ -282,6 +282,52 @@ func (_c *MockBackend_ConnectVPN_Call) RunAndReturn(run func(string, bool) error
 	return _c
 }
 
+// ConnectWWAN provides a mock function with given fields: uuid
+func (_m *MockBackend) ConnectWWAN(uuid string) error {
+	ret := _m.Called(uuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConnectWWAN")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(uuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_ConnectWWAN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConnectWWAN'
+type MockBackend_ConnectWWAN_Call struct {
+	*mock.Call
+}
+
+// ConnectWWAN is a helper method to define mock.On call
+//   - uuid string
+func (_e *MockBackend_Expecter) ConnectWWAN(uuid interface{}) *MockBackend_ConnectWWAN_Call {
+	return &MockBackend_ConnectWWAN_Call{Call: _e.mock.On("ConnectWWAN", uuid)}
+}
+
+func (_c *MockBackend_ConnectWWAN_Call) Run(run func(uuid string)) *MockBackend_ConnectWWAN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_ConnectWWAN_Call) Return(_a0 error) *MockBackend_ConnectWWAN_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_ConnectWWAN_Call) RunAndReturn(run func(string) error) *MockBackend_ConnectWWAN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ConnectWiFi provides a mock function with given fields: req
 func (_m *MockBackend) ConnectWiFi(req network.ConnectionRequest) error {
 	ret := _m.Called(req)
@@ -328,6 +374,64 @@ func (_c *MockBackend_ConnectWiFi_Call) RunAndReturn(run func(network.Connection
 	return _c
 }
 
+// CreateWireGuard provides a mock function with given fields: cfg
+func (_m *MockBackend) CreateWireGuard(cfg network.WireGuardConfig) (*network.WireGuardConfig, error) {
+	ret := _m.Called(cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWireGuard")
+	}
+
+	var r0 *network.WireGuardConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(network.WireGuardConfig) (*network.WireGuardConfig, error)); ok {
+		return rf(cfg)
+	}
+	if rf, ok := ret.Get(0).(func(network.WireGuardConfig) *network.WireGuardConfig); ok {
+		r0 = rf(cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.WireGuardConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(network.WireGuardConfig) error); ok {
+		r1 = rf(cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_CreateWireGuard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWireGuard'
+type MockBackend_CreateWireGuard_Call struct {
+	*mock.Call
+}
+
+// CreateWireGuard is a helper method to define mock.On call
+//   - cfg network.WireGuardConfig
+func (_e *MockBackend_Expecter) CreateWireGuard(cfg interface{}) *MockBackend_CreateWireGuard_Call {
+	return &MockBackend_CreateWireGuard_Call{Call: _e.mock.On("CreateWireGuard", cfg)}
+}
+
+func (_c *MockBackend_CreateWireGuard_Call) Run(run func(cfg network.WireGuardConfig)) *MockBackend_CreateWireGuard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(network.WireGuardConfig))
+	})
+	return _c
+}
+
+func (_c *MockBackend_CreateWireGuard_Call) Return(_a0 *network.WireGuardConfig, _a1 error) *MockBackend_CreateWireGuard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_CreateWireGuard_Call) RunAndReturn(run func(network.WireGuardConfig) (*network.WireGuardConfig, error)) *MockBackend_CreateWireGuard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteVPN provides a mock function with given fields: uuidOrName
 func (_m *MockBackend) DeleteVPN(uuidOrName string) error {
 	ret := _m.Called(uuidOrName)
@@ -374,6 +478,52 @@ func (_c *MockBackend_DeleteVPN_Call) RunAndReturn(run func(string) error) *Mock
 	return _c
 }
 
+// DeleteWWANProfile provides a mock function with given fields: uuid
+func (_m *MockBackend) DeleteWWANProfile(uuid string) error {
+	ret := _m.Called(uuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWWANProfile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(uuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_DeleteWWANProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWWANProfile'
+type MockBackend_DeleteWWANProfile_Call struct {
+	*mock.Call
+}
+
+// DeleteWWANProfile is a helper method to define mock.On call
+//   - uuid string
+func (_e *MockBackend_Expecter) DeleteWWANProfile(uuid interface{}) *MockBackend_DeleteWWANProfile_Call {
+	return &MockBackend_DeleteWWANProfile_Call{Call: _e.mock.On("DeleteWWANProfile", uuid)}
+}
+
+func (_c *MockBackend_DeleteWWANProfile_Call) Run(run func(uuid string)) *MockBackend_DeleteWWANProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_DeleteWWANProfile_Call) Return(_a0 error) *MockBackend_DeleteWWANProfile_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_DeleteWWANProfile_Call) RunAndReturn(run func(string) error) *MockBackend_DeleteWWANProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DisconnectAllVPN provides a mock function with no fields
 func (_m *MockBackend) DisconnectAllVPN() error {
 	ret := _m.Called()
@@ -556,6 +706,52 @@ func (_c *MockBackend_DisconnectVPN_Call) RunAndReturn(run func(string) error) *
 	return _c
 }
 
+// DisconnectWWAN provides a mock function with given fields: uuid
+func (_m *MockBackend) DisconnectWWAN(uuid string) error {
+	ret := _m.Called(uuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisconnectWWAN")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(uuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_DisconnectWWAN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisconnectWWAN'
+type MockBackend_DisconnectWWAN_Call struct {
+	*mock.Call
+}
+
+// DisconnectWWAN is a helper method to define mock.On call
+//   - uuid string
+func (_e *MockBackend_Expecter) DisconnectWWAN(uuid interface{}) *MockBackend_DisconnectWWAN_Call {
+	return &MockBackend_DisconnectWWAN_Call{Call: _e.mock.On("DisconnectWWAN", uuid)}
+}
+
+func (_c *MockBackend_DisconnectWWAN_Call) Run(run func(uuid string)) *MockBackend_DisconnectWWAN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_DisconnectWWAN_Call) Return(_a0 error) *MockBackend_DisconnectWWAN_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_DisconnectWWAN_Call) RunAndReturn(run func(string) error) *MockBackend_DisconnectWWAN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DisconnectWiFi provides a mock function with no fields
 func (_m *MockBackend) DisconnectWiFi() error {
 	ret := _m.Called()
@@ -647,6 +843,64 @@ func (_c *MockBackend_DisconnectWiFiDevice_Call) RunAndReturn(run func(string) e
 	return _c
 }
 
+// ExportWireGuard provides a mock function with given fields: uuidOrName
+func (_m *MockBackend) ExportWireGuard(uuidOrName string) (*network.WireGuardConfig, error) {
+	ret := _m.Called(uuidOrName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportWireGuard")
+	}
+
+	var r0 *network.WireGuardConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.WireGuardConfig, error)); ok {
+		return rf(uuidOrName)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.WireGuardConfig); ok {
+		r0 = rf(uuidOrName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.WireGuardConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuidOrName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_ExportWireGuard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportWireGuard'
+type MockBackend_ExportWireGuard_Call struct {
+	*mock.Call
+}
+
+// ExportWireGuard is a helper method to define mock.On call
+//   - uuidOrName string
+func (_e *MockBackend_Expecter) ExportWireGuard(uuidOrName interface{}) *MockBackend_ExportWireGuard_Call {
+	return &MockBackend_ExportWireGuard_Call{Call: _e.mock.On("ExportWireGuard", uuidOrName)}
+}
+
+func (_c *MockBackend_ExportWireGuard_Call) Run(run func(uuidOrName string)) *MockBackend_ExportWireGuard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_ExportWireGuard_Call) Return(_a0 *network.WireGuardConfig, _a1 error) *MockBackend_ExportWireGuard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_ExportWireGuard_Call) RunAndReturn(run func(string) (*network.WireGuardConfig, error)) *MockBackend_ExportWireGuard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ForgetWiFiNetwork provides a mock function with given fields: ssid
 func (_m *MockBackend) ForgetWiFiNetwork(ssid string) error {
 	ret := _m.Called(ssid)
@@ -693,6 +947,64 @@ func (_c *MockBackend_ForgetWiFiNetwork_Call) RunAndReturn(run func(string) erro
 	return _c
 }
 
+// GetConnectionSettings provides a mock function with given fields: uuid
+func (_m *MockBackend) GetConnectionSettings(uuid string) (*network.ConnectionConfig, error) {
+	ret := _m.Called(uuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConnectionSettings")
+	}
+
+	var r0 *network.ConnectionConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.ConnectionConfig, error)); ok {
+		return rf(uuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.ConnectionConfig); ok {
+		r0 = rf(uuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.ConnectionConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetConnectionSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetConnectionSettings'
+type MockBackend_GetConnectionSettings_Call struct {
+	*mock.Call
+}
+
+// GetConnectionSettings is a helper method to define mock.On call
+//   - uuid string
+func (_e *MockBackend_Expecter) GetConnectionSettings(uuid interface{}) *MockBackend_GetConnectionSettings_Call {
+	return &MockBackend_GetConnectionSettings_Call{Call: _e.mock.On("GetConnectionSettings", uuid)}
+}
+
+func (_c *MockBackend_GetConnectionSettings_Call) Run(run func(uuid string)) *MockBackend_GetConnectionSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetConnectionSettings_Call) Return(_a0 *network.ConnectionConfig, _a1 error) *MockBackend_GetConnectionSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetConnectionSettings_Call) RunAndReturn(run func(string) (*network.ConnectionConfig, error)) *MockBackend_GetConnectionSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetCurrentState provides a mock function with no fields
 func (_m *MockBackend) GetCurrentState() (*network.BackendState, error) {
 	ret := _m.Called()
@@ -797,6 +1109,110 @@ func (_c *MockBackend_GetEthernetDevices_Call) RunAndReturn(run func() []network
 	return _c
 }
 
+// GetHotspotConfig provides a mock function with no fields
+func (_m *MockBackend) GetHotspotConfig() (*network.HotspotConfig, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHotspotConfig")
+	}
+
+	var r0 *network.HotspotConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*network.HotspotConfig, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *network.HotspotConfig); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.HotspotConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetHotspotConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetHotspotConfig'
+type MockBackend_GetHotspotConfig_Call struct {
+	*mock.Call
+}
+
+// GetHotspotConfig is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) GetHotspotConfig() *MockBackend_GetHotspotConfig_Call {
+	return &MockBackend_GetHotspotConfig_Call{Call: _e.mock.On("GetHotspotConfig")}
+}
+
+func (_c *MockBackend_GetHotspotConfig_Call) Run(run func()) *MockBackend_GetHotspotConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetHotspotConfig_Call) Return(_a0 *network.HotspotConfig, _a1 error) *MockBackend_GetHotspotConfig_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetHotspotConfig_Call) RunAndReturn(run func() (*network.HotspotConfig, error)) *MockBackend_GetHotspotConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInterfaceConnections provides a mock function with no fields
+func (_m *MockBackend) GetInterfaceConnections() map[string]network.InterfaceConnection {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInterfaceConnections")
+	}
+
+	var r0 map[string]network.InterfaceConnection
+	if rf, ok := ret.Get(0).(func() map[string]network.InterfaceConnection); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]network.InterfaceConnection)
+		}
+	}
+
+	return r0
+}
+
+// MockBackend_GetInterfaceConnections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInterfaceConnections'
+type MockBackend_GetInterfaceConnections_Call struct {
+	*mock.Call
+}
+
+// GetInterfaceConnections is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) GetInterfaceConnections() *MockBackend_GetInterfaceConnections_Call {
+	return &MockBackend_GetInterfaceConnections_Call{Call: _e.mock.On("GetInterfaceConnections")}
+}
+
+func (_c *MockBackend_GetInterfaceConnections_Call) Run(run func()) *MockBackend_GetInterfaceConnections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetInterfaceConnections_Call) Return(_a0 map[string]network.InterfaceConnection) *MockBackend_GetInterfaceConnections_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_GetInterfaceConnections_Call) RunAndReturn(run func() map[string]network.InterfaceConnection) *MockBackend_GetInterfaceConnections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPromptBroker provides a mock function with no fields
 func (_m *MockBackend) GetPromptBroker() network.PromptBroker {
 	ret := _m.Called()
@@ -874,30 +1290,87 @@ func (_m *MockBackend) GetVPNConfig(uuidOrName string) (*network.VPNConfig, erro
 	return r0, r1
 }
 
-// MockBackend_GetVPNConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVPNConfig'
-type MockBackend_GetVPNConfig_Call struct {
+// MockBackend_GetVPNConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVPNConfig'
+type MockBackend_GetVPNConfig_Call struct {
+	*mock.Call
+}
+
+// GetVPNConfig is a helper method to define mock.On call
+//   - uuidOrName string
+func (_e *MockBackend_Expecter) GetVPNConfig(uuidOrName interface{}) *MockBackend_GetVPNConfig_Call {
+	return &MockBackend_GetVPNConfig_Call{Call: _e.mock.On("GetVPNConfig", uuidOrName)}
+}
+
+func (_c *MockBackend_GetVPNConfig_Call) Run(run func(uuidOrName string)) *MockBackend_GetVPNConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetVPNConfig_Call) Return(_a0 *network.VPNConfig, _a1 error) *MockBackend_GetVPNConfig_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetVPNConfig_Call) RunAndReturn(run func(string) (*network.VPNConfig, error)) *MockBackend_GetVPNConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWWANModems provides a mock function with no fields
+func (_m *MockBackend) GetWWANModems() ([]network.WWANModem, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWWANModems")
+	}
+
+	var r0 []network.WWANModem
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]network.WWANModem, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []network.WWANModem); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]network.WWANModem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetWWANModems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWWANModems'
+type MockBackend_GetWWANModems_Call struct {
 	*mock.Call
 }
 
-// GetVPNConfig is a helper method to define mock.On call
-//   - uuidOrName string
-func (_e *MockBackend_Expecter) GetVPNConfig(uuidOrName interface{}) *MockBackend_GetVPNConfig_Call {
-	return &MockBackend_GetVPNConfig_Call{Call: _e.mock.On("GetVPNConfig", uuidOrName)}
+// GetWWANModems is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) GetWWANModems() *MockBackend_GetWWANModems_Call {
+	return &MockBackend_GetWWANModems_Call{Call: _e.mock.On("GetWWANModems")}
 }
 
-func (_c *MockBackend_GetVPNConfig_Call) Run(run func(uuidOrName string)) *MockBackend_GetVPNConfig_Call {
+func (_c *MockBackend_GetWWANModems_Call) Run(run func()) *MockBackend_GetWWANModems_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run()
 	})
 	return _c
 }
 
-func (_c *MockBackend_GetVPNConfig_Call) Return(_a0 *network.VPNConfig, _a1 error) *MockBackend_GetVPNConfig_Call {
+func (_c *MockBackend_GetWWANModems_Call) Return(_a0 []network.WWANModem, _a1 error) *MockBackend_GetWWANModems_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockBackend_GetVPNConfig_Call) RunAndReturn(run func(string) (*network.VPNConfig, error)) *MockBackend_GetVPNConfig_Call {
+func (_c *MockBackend_GetWWANModems_Call) RunAndReturn(run func() ([]network.WWANModem, error)) *MockBackend_GetWWANModems_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1508,6 +1981,121 @@ func (_c *MockBackend_ListVPNProfiles_Call) RunAndReturn(run func() ([]network.V
 	return _c
 }
 
+// ListWWANProfiles provides a mock function with no fields
+func (_m *MockBackend) ListWWANProfiles() ([]network.WWANProfile, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWWANProfiles")
+	}
+
+	var r0 []network.WWANProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]network.WWANProfile, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []network.WWANProfile); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]network.WWANProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_ListWWANProfiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWWANProfiles'
+type MockBackend_ListWWANProfiles_Call struct {
+	*mock.Call
+}
+
+// ListWWANProfiles is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) ListWWANProfiles() *MockBackend_ListWWANProfiles_Call {
+	return &MockBackend_ListWWANProfiles_Call{Call: _e.mock.On("ListWWANProfiles")}
+}
+
+func (_c *MockBackend_ListWWANProfiles_Call) Run(run func()) *MockBackend_ListWWANProfiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_ListWWANProfiles_Call) Return(_a0 []network.WWANProfile, _a1 error) *MockBackend_ListWWANProfiles_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_ListWWANProfiles_Call) RunAndReturn(run func() ([]network.WWANProfile, error)) *MockBackend_ListWWANProfiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveWWANProfile provides a mock function with given fields: profile
+func (_m *MockBackend) SaveWWANProfile(profile network.WWANProfile) (*network.WWANProfile, error) {
+	ret := _m.Called(profile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWWANProfile")
+	}
+
+	var r0 *network.WWANProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(network.WWANProfile) (*network.WWANProfile, error)); ok {
+		return rf(profile)
+	}
+	if rf, ok := ret.Get(0).(func(network.WWANProfile) *network.WWANProfile); ok {
+		r0 = rf(profile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.WWANProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(network.WWANProfile) error); ok {
+		r1 = rf(profile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_SaveWWANProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveWWANProfile'
+type MockBackend_SaveWWANProfile_Call struct {
+	*mock.Call
+}
+
+// SaveWWANProfile is a helper method to define mock.On call
+//   - profile network.WWANProfile
+func (_e *MockBackend_Expecter) SaveWWANProfile(profile interface{}) *MockBackend_SaveWWANProfile_Call {
+	return &MockBackend_SaveWWANProfile_Call{Call: _e.mock.On("SaveWWANProfile", profile)}
+}
+
+func (_c *MockBackend_SaveWWANProfile_Call) Run(run func(profile network.WWANProfile)) *MockBackend_SaveWWANProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(network.WWANProfile))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SaveWWANProfile_Call) Return(_a0 *network.WWANProfile, _a1 error) *MockBackend_SaveWWANProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_SaveWWANProfile_Call) RunAndReturn(run func(network.WWANProfile) (*network.WWANProfile, error)) *MockBackend_SaveWWANProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ScanWiFi provides a mock function with no fields
 func (_m *MockBackend) ScanWiFi() error {
 	ret := _m.Called()
@@ -1694,6 +2282,52 @@ func (_c *MockBackend_SetVPNCredentials_Call) RunAndReturn(run func(string, stri
 	return _c
 }
 
+// SetWWANEnabled provides a mock function with given fields: enabled
+func (_m *MockBackend) SetWWANEnabled(enabled bool) error {
+	ret := _m.Called(enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWWANEnabled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(bool) error); ok {
+		r0 = rf(enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetWWANEnabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWWANEnabled'
+type MockBackend_SetWWANEnabled_Call struct {
+	*mock.Call
+}
+
+// SetWWANEnabled is a helper method to define mock.On call
+//   - enabled bool
+func (_e *MockBackend_Expecter) SetWWANEnabled(enabled interface{}) *MockBackend_SetWWANEnabled_Call {
+	return &MockBackend_SetWWANEnabled_Call{Call: _e.mock.On("SetWWANEnabled", enabled)}
+}
+
+func (_c *MockBackend_SetWWANEnabled_Call) Run(run func(enabled bool)) *MockBackend_SetWWANEnabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(bool))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetWWANEnabled_Call) Return(_a0 error) *MockBackend_SetWWANEnabled_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetWWANEnabled_Call) RunAndReturn(run func(bool) error) *MockBackend_SetWWANEnabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetWiFiAutoconnect provides a mock function with given fields: ssid, autoconnect
 func (_m *MockBackend) SetWiFiAutoconnect(ssid string, autoconnect bool) error {
 	ret := _m.Called(ssid, autoconnect)
@@ -1787,6 +2421,64 @@ func (_c *MockBackend_SetWiFiEnabled_Call) RunAndReturn(run func(bool) error) *M
 	return _c
 }
 
+// StartHotspot provides a mock function with given fields: cfg
+func (_m *MockBackend) StartHotspot(cfg network.HotspotConfig) (*network.HotspotConfig, error) {
+	ret := _m.Called(cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartHotspot")
+	}
+
+	var r0 *network.HotspotConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(network.HotspotConfig) (*network.HotspotConfig, error)); ok {
+		return rf(cfg)
+	}
+	if rf, ok := ret.Get(0).(func(network.HotspotConfig) *network.HotspotConfig); ok {
+		r0 = rf(cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.HotspotConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(network.HotspotConfig) error); ok {
+		r1 = rf(cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_StartHotspot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartHotspot'
+type MockBackend_StartHotspot_Call struct {
+	*mock.Call
+}
+
+// StartHotspot is a helper method to define mock.On call
+//   - cfg network.HotspotConfig
+func (_e *MockBackend_Expecter) StartHotspot(cfg interface{}) *MockBackend_StartHotspot_Call {
+	return &MockBackend_StartHotspot_Call{Call: _e.mock.On("StartHotspot", cfg)}
+}
+
+func (_c *MockBackend_StartHotspot_Call) Run(run func(cfg network.HotspotConfig)) *MockBackend_StartHotspot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(network.HotspotConfig))
+	})
+	return _c
+}
+
+func (_c *MockBackend_StartHotspot_Call) Return(_a0 *network.HotspotConfig, _a1 error) *MockBackend_StartHotspot_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_StartHotspot_Call) RunAndReturn(run func(network.HotspotConfig) (*network.HotspotConfig, error)) *MockBackend_StartHotspot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StartMonitoring provides a mock function with given fields: onStateChange
 func (_m *MockBackend) StartMonitoring(onStateChange func()) error {
 	ret := _m.Called(onStateChange)
@@ -1833,6 +2525,51 @@ func (_c *MockBackend_StartMonitoring_Call) RunAndReturn(run func(func()) error)
 	return _c
 }
 
+// StopHotspot provides a mock function with no fields
+func (_m *MockBackend) StopHotspot() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopHotspot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_StopHotspot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StopHotspot'
+type MockBackend_StopHotspot_Call struct {
+	*mock.Call
+}
+
+// StopHotspot is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) StopHotspot() *MockBackend_StopHotspot_Call {
+	return &MockBackend_StopHotspot_Call{Call: _e.mock.On("StopHotspot")}
+}
+
+func (_c *MockBackend_StopHotspot_Call) Run(run func()) *MockBackend_StopHotspot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_StopHotspot_Call) Return(_a0 error) *MockBackend_StopHotspot_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_StopHotspot_Call) RunAndReturn(run func() error) *MockBackend_StopHotspot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StopMonitoring provides a mock function with no fields
 func (_m *MockBackend) StopMonitoring() {
 	_m.Called()
@@ -1913,6 +2650,102 @@ func (_c *MockBackend_SubmitCredentials_Call) RunAndReturn(run func(string, map[
 	return _c
 }
 
+// UnlockWWAN provides a mock function with given fields: modem, pin, puk
+func (_m *MockBackend) UnlockWWAN(modem string, pin string, puk string) error {
+	ret := _m.Called(modem, pin, puk)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockWWAN")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(modem, pin, puk)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_UnlockWWAN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockWWAN'
+type MockBackend_UnlockWWAN_Call struct {
+	*mock.Call
+}
+
+// UnlockWWAN is a helper method to define mock.On call
+//   - modem string
+//   - pin string
+//   - puk string
+func (_e *MockBackend_Expecter) UnlockWWAN(modem interface{}, pin interface{}, puk interface{}) *MockBackend_UnlockWWAN_Call {
+	return &MockBackend_UnlockWWAN_Call{Call: _e.mock.On("UnlockWWAN", modem, pin, puk)}
+}
+
+func (_c *MockBackend_UnlockWWAN_Call) Run(run func(modem string, pin string, puk string)) *MockBackend_UnlockWWAN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_UnlockWWAN_Call) Return(_a0 error) *MockBackend_UnlockWWAN_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_UnlockWWAN_Call) RunAndReturn(run func(string, string, string) error) *MockBackend_UnlockWWAN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateConnectionSettings provides a mock function with given fields: uuid, cfg, reactivate
+func (_m *MockBackend) UpdateConnectionSettings(uuid string, cfg network.ConnectionConfig, reactivate bool) error {
+	ret := _m.Called(uuid, cfg, reactivate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateConnectionSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, network.ConnectionConfig, bool) error); ok {
+		r0 = rf(uuid, cfg, reactivate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_UpdateConnectionSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateConnectionSettings'
+type MockBackend_UpdateConnectionSettings_Call struct {
+	*mock.Call
+}
+
+// UpdateConnectionSettings is a helper method to define mock.On call
+//   - uuid string
+//   - cfg network.ConnectionConfig
+//   - reactivate bool
+func (_e *MockBackend_Expecter) UpdateConnectionSettings(uuid interface{}, cfg interface{}, reactivate interface{}) *MockBackend_UpdateConnectionSettings_Call {
+	return &MockBackend_UpdateConnectionSettings_Call{Call: _e.mock.On("UpdateConnectionSettings", uuid, cfg, reactivate)}
+}
+
+func (_c *MockBackend_UpdateConnectionSettings_Call) Run(run func(uuid string, cfg network.ConnectionConfig, reactivate bool)) *MockBackend_UpdateConnectionSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(network.ConnectionConfig), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockBackend_UpdateConnectionSettings_Call) Return(_a0 error) *MockBackend_UpdateConnectionSettings_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_UpdateConnectionSettings_Call) RunAndReturn(run func(string, network.ConnectionConfig, bool) error) *MockBackend_UpdateConnectionSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateVPNConfig provides a mock function with given fields: uuid, updates
 func (_m *MockBackend) UpdateVPNConfig(uuid string, updates map[string]interface{}) error {
 	ret := _m.Called(uuid, updates)
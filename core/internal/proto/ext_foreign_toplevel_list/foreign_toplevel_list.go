@@ -0,0 +1,409 @@
+// Generated by go-wayland-scanner
+// https://github.com/yaslama/go-wayland/cmd/go-wayland-scanner
+// XML file : internal/proto/xml/ext-foreign-toplevel-list-v1.xml
+//
+// ext_foreign_toplevel_list_v1 Protocol Copyright:
+//
+// Copyright © 2018 Ilia Bozhinov
+// Copyright © 2020 Isaac Freund
+// Copyright © 2022 wb9688
+// Copyright © 2023 i509VCB
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice (including the next
+// paragraph) shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.  IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+package ext_foreign_toplevel_list
+
+import "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+
+// ExtForeignToplevelListV1InterfaceName is the name of the interface as it appears in the [client.Registry].
+// It can be used to match the [client.RegistryGlobalEvent.Interface] in the
+// [Registry.SetGlobalHandler] and can be used in [Registry.Bind] if this applies.
+const ExtForeignToplevelListV1InterfaceName = "ext_foreign_toplevel_list_v1"
+
+// ExtForeignToplevelListV1 : list toplevels
+//
+// A toplevel is defined as a surface with a role similar to xdg_toplevel.
+// XWayland surfaces may be treated like toplevels in this protocol.
+//
+// After a client binds the ext_foreign_toplevel_list_v1, each mapped
+// toplevel window will be sent using the ext_foreign_toplevel_list_v1.toplevel
+// event.
+//
+// Clients which only care about the current state can perform a roundtrip after
+// binding this global.
+//
+// For each instance of ext_foreign_toplevel_list_v1, the compositor must
+// create a new ext_foreign_toplevel_handle_v1 object for each mapped toplevel.
+//
+// If a compositor implementation sends the ext_foreign_toplevel_list_v1.finished
+// event after the global is bound, the compositor must not send any
+// ext_foreign_toplevel_list_v1.toplevel events.
+type ExtForeignToplevelListV1 struct {
+	client.BaseProxy
+	toplevelHandler ExtForeignToplevelListV1ToplevelHandlerFunc
+	finishedHandler ExtForeignToplevelListV1FinishedHandlerFunc
+}
+
+// NewExtForeignToplevelListV1 : list toplevels
+//
+// A toplevel is defined as a surface with a role similar to xdg_toplevel.
+// XWayland surfaces may be treated like toplevels in this protocol.
+//
+// After a client binds the ext_foreign_toplevel_list_v1, each mapped
+// toplevel window will be sent using the ext_foreign_toplevel_list_v1.toplevel
+// event.
+//
+// Clients which only care about the current state can perform a roundtrip after
+// binding this global.
+//
+// For each instance of ext_foreign_toplevel_list_v1, the compositor must
+// create a new ext_foreign_toplevel_handle_v1 object for each mapped toplevel.
+//
+// If a compositor implementation sends the ext_foreign_toplevel_list_v1.finished
+// event after the global is bound, the compositor must not send any
+// ext_foreign_toplevel_list_v1.toplevel events.
+func NewExtForeignToplevelListV1(ctx *client.Context) *ExtForeignToplevelListV1 {
+	extForeignToplevelListV1 := &ExtForeignToplevelListV1{}
+	ctx.Register(extForeignToplevelListV1)
+	return extForeignToplevelListV1
+}
+
+// Stop : stop sending events
+//
+// This request indicates that the client no longer wishes to receive
+// events for new toplevels.
+//
+// The Wayland protocol is asynchronous, meaning the compositor may send
+// further toplevel events until the stop request is processed.
+// The client should wait for a ext_foreign_toplevel_list_v1.finished
+// event before destroying this object.
+func (i *ExtForeignToplevelListV1) Stop() error {
+	const opcode = 0
+	const _reqBufLen = 8
+	var _reqBuf [_reqBufLen]byte
+	l := 0
+	client.PutUint32(_reqBuf[l:4], i.ID())
+	l += 4
+	client.PutUint32(_reqBuf[l:l+4], uint32(_reqBufLen<<16|opcode&0x0000ffff))
+	l += 4
+	err := i.Context().WriteMsg(_reqBuf[:], nil)
+	return err
+}
+
+// Destroy : destroy the ext_foreign_toplevel_list_v1 object
+//
+// This request should be called either when the client will no longer
+// use the ext_foreign_toplevel_list_v1 or after the finished event
+// has been received to allow destruction of the object.
+//
+// If a client wishes to destroy this object it should send a
+// ext_foreign_toplevel_list_v1.stop request and wait for a ext_foreign_toplevel_list_v1.finished
+// event, then destroy the handles and then this object.
+func (i *ExtForeignToplevelListV1) Destroy() error {
+	defer i.MarkZombie()
+	const opcode = 1
+	const _reqBufLen = 8
+	var _reqBuf [_reqBufLen]byte
+	l := 0
+	client.PutUint32(_reqBuf[l:4], i.ID())
+	l += 4
+	client.PutUint32(_reqBuf[l:l+4], uint32(_reqBufLen<<16|opcode&0x0000ffff))
+	l += 4
+	err := i.Context().WriteMsg(_reqBuf[:], nil)
+	return err
+}
+
+// ExtForeignToplevelListV1ToplevelEvent : a toplevel has been created
+//
+// This event is emitted whenever a new toplevel window is created. It is
+// emitted for all toplevels, regardless of the app that has created them.
+//
+// All initial properties of the toplevel (identifier, title, app_id) will be sent
+// immediately after this event using the corresponding events for
+// ext_foreign_toplevel_handle_v1. The compositor will use the
+// ext_foreign_toplevel_handle_v1.done event to indicate when all data has
+// been sent.
+type ExtForeignToplevelListV1ToplevelEvent struct {
+	Toplevel *ExtForeignToplevelHandleV1
+}
+type ExtForeignToplevelListV1ToplevelHandlerFunc func(ExtForeignToplevelListV1ToplevelEvent)
+
+// SetToplevelHandler : sets handler for ExtForeignToplevelListV1ToplevelEvent
+func (i *ExtForeignToplevelListV1) SetToplevelHandler(f ExtForeignToplevelListV1ToplevelHandlerFunc) {
+	i.toplevelHandler = f
+}
+
+// ExtForeignToplevelListV1FinishedEvent : the compositor has finished with the toplevel manager
+//
+// This event indicates that the compositor is done sending events
+// to this object. The client should destroy the object.
+// See ext_foreign_toplevel_list_v1.destroy for more information.
+//
+// The compositor must not send any more toplevel events after this event.
+type ExtForeignToplevelListV1FinishedEvent struct{}
+type ExtForeignToplevelListV1FinishedHandlerFunc func(ExtForeignToplevelListV1FinishedEvent)
+
+// SetFinishedHandler : sets handler for ExtForeignToplevelListV1FinishedEvent
+func (i *ExtForeignToplevelListV1) SetFinishedHandler(f ExtForeignToplevelListV1FinishedHandlerFunc) {
+	i.finishedHandler = f
+}
+
+func (i *ExtForeignToplevelListV1) Dispatch(opcode uint32, fd int, data []byte) {
+	switch opcode {
+	case 0:
+		// toplevel event: server creates a new object (new_id). Register it
+		// even without a handler so its events have a proxy to land on.
+		var e ExtForeignToplevelListV1ToplevelEvent
+		l := 0
+		newID := client.Uint32(data[l : l+4])
+		l += 4
+
+		ctx := i.Context()
+		toplevel := &ExtForeignToplevelHandleV1{}
+		toplevel.SetContext(ctx)
+		toplevel.SetID(newID)
+		ctx.RegisterWithID(toplevel, newID)
+		e.Toplevel = toplevel
+
+		if i.toplevelHandler == nil {
+			return
+		}
+		i.toplevelHandler(e)
+	case 1:
+		if i.finishedHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelListV1FinishedEvent
+
+		i.finishedHandler(e)
+	}
+}
+
+// ExtForeignToplevelHandleV1InterfaceName is the name of the interface as it appears in the [client.Registry].
+// It can be used to match the [client.RegistryGlobalEvent.Interface] in the
+// [Registry.SetGlobalHandler] and can be used in [Registry.Bind] if this applies.
+const ExtForeignToplevelHandleV1InterfaceName = "ext_foreign_toplevel_handle_v1"
+
+// ExtForeignToplevelHandleV1 : a mapped toplevel
+//
+// A ext_foreign_toplevel_handle_v1 object represents a mapped toplevel
+// window. A single app may have multiple mapped toplevels.
+type ExtForeignToplevelHandleV1 struct {
+	client.BaseProxy
+	closedHandler     ExtForeignToplevelHandleV1ClosedHandlerFunc
+	doneHandler       ExtForeignToplevelHandleV1DoneHandlerFunc
+	titleHandler      ExtForeignToplevelHandleV1TitleHandlerFunc
+	appIdHandler      ExtForeignToplevelHandleV1AppIdHandlerFunc
+	identifierHandler ExtForeignToplevelHandleV1IdentifierHandlerFunc
+}
+
+// NewExtForeignToplevelHandleV1 : a mapped toplevel
+//
+// A ext_foreign_toplevel_handle_v1 object represents a mapped toplevel
+// window. A single app may have multiple mapped toplevels.
+func NewExtForeignToplevelHandleV1(ctx *client.Context) *ExtForeignToplevelHandleV1 {
+	extForeignToplevelHandleV1 := &ExtForeignToplevelHandleV1{}
+	ctx.Register(extForeignToplevelHandleV1)
+	return extForeignToplevelHandleV1
+}
+
+// Destroy : destroy the ext_foreign_toplevel_handle_v1 object
+//
+// This request should be used when the client will no longer use the handle
+// or after the closed event has been received to allow destruction of the
+// object.
+//
+// When a handle is destroyed, a new handle may not be created by the server
+// until the toplevel is unmapped and then remapped. Destroying a toplevel handle
+// is not recommended unless the client is cleaning up child objects
+// before destroying the ext_foreign_toplevel_list_v1 object, the toplevel
+// was closed or the toplevel handle will not be used in the future.
+//
+// Other protocols which extend the ext_foreign_toplevel_handle_v1
+// interface should require destructors for extension interfaces be
+// called before allowing the toplevel handle to be destroyed.
+func (i *ExtForeignToplevelHandleV1) Destroy() error {
+	defer i.MarkZombie()
+	const opcode = 0
+	const _reqBufLen = 8
+	var _reqBuf [_reqBufLen]byte
+	l := 0
+	client.PutUint32(_reqBuf[l:4], i.ID())
+	l += 4
+	client.PutUint32(_reqBuf[l:l+4], uint32(_reqBufLen<<16|opcode&0x0000ffff))
+	l += 4
+	err := i.Context().WriteMsg(_reqBuf[:], nil)
+	return err
+}
+
+// ExtForeignToplevelHandleV1ClosedEvent : the toplevel has been closed
+//
+// The server will emit no further events on the ext_foreign_toplevel_handle_v1
+// after this event. Any requests received aside from the destroy request must
+// be ignored. Upon receiving this event, the client should destroy the handle.
+//
+// Other protocols which extend the ext_foreign_toplevel_handle_v1
+// interface must also ignore requests other than destructors.
+type ExtForeignToplevelHandleV1ClosedEvent struct{}
+type ExtForeignToplevelHandleV1ClosedHandlerFunc func(ExtForeignToplevelHandleV1ClosedEvent)
+
+// SetClosedHandler : sets handler for ExtForeignToplevelHandleV1ClosedEvent
+func (i *ExtForeignToplevelHandleV1) SetClosedHandler(f ExtForeignToplevelHandleV1ClosedHandlerFunc) {
+	i.closedHandler = f
+}
+
+// ExtForeignToplevelHandleV1DoneEvent : all information about the toplevel has been sent
+//
+// This event is sent after all changes in the toplevel state have
+// been sent.
+//
+// This allows changes to the ext_foreign_toplevel_handle_v1 properties
+// to be atomically applied. Other protocols which extend the
+// ext_foreign_toplevel_handle_v1 interface may use this event to also
+// atomically apply any pending state.
+//
+// This event must not be sent after the ext_foreign_toplevel_handle_v1.closed
+// event.
+type ExtForeignToplevelHandleV1DoneEvent struct{}
+type ExtForeignToplevelHandleV1DoneHandlerFunc func(ExtForeignToplevelHandleV1DoneEvent)
+
+// SetDoneHandler : sets handler for ExtForeignToplevelHandleV1DoneEvent
+func (i *ExtForeignToplevelHandleV1) SetDoneHandler(f ExtForeignToplevelHandleV1DoneHandlerFunc) {
+	i.doneHandler = f
+}
+
+// ExtForeignToplevelHandleV1TitleEvent : title change
+//
+// The title of the toplevel has changed.
+//
+// The configured state must not be applied immediately. See
+// ext_foreign_toplevel_handle_v1.done for details.
+type ExtForeignToplevelHandleV1TitleEvent struct {
+	Title string
+}
+type ExtForeignToplevelHandleV1TitleHandlerFunc func(ExtForeignToplevelHandleV1TitleEvent)
+
+// SetTitleHandler : sets handler for ExtForeignToplevelHandleV1TitleEvent
+func (i *ExtForeignToplevelHandleV1) SetTitleHandler(f ExtForeignToplevelHandleV1TitleHandlerFunc) {
+	i.titleHandler = f
+}
+
+// ExtForeignToplevelHandleV1AppIdEvent : app_id change
+//
+// The app id of the toplevel has changed.
+//
+// The configured state must not be applied immediately. See
+// ext_foreign_toplevel_handle_v1.done for details.
+type ExtForeignToplevelHandleV1AppIdEvent struct {
+	AppId string
+}
+type ExtForeignToplevelHandleV1AppIdHandlerFunc func(ExtForeignToplevelHandleV1AppIdEvent)
+
+// SetAppIdHandler : sets handler for ExtForeignToplevelHandleV1AppIdEvent
+func (i *ExtForeignToplevelHandleV1) SetAppIdHandler(f ExtForeignToplevelHandleV1AppIdHandlerFunc) {
+	i.appIdHandler = f
+}
+
+// ExtForeignToplevelHandleV1IdentifierEvent : a stable identifier for a toplevel
+//
+// This identifier is used to check if two or more toplevel handles belong
+// to the same toplevel.
+//
+// The identifier is useful for command line tools or privileged clients
+// which may need to reference an exact toplevel across processes or
+// instances of the ext_foreign_toplevel_list_v1 global.
+//
+// The compositor must only send this event when the handle is created.
+//
+// The identifier must be unique per toplevel and it's handles. Two different
+// toplevels must not have the same identifier. The identifier is only valid
+// as long as the toplevel is mapped. If the toplevel is unmapped the identifier
+// must not be reused. An identifier must not be reused by the compositor to
+// ensure there are no races when sharing identifiers between processes.
+//
+// An identifier is a string that contains up to 32 printable ASCII bytes.
+// An identifier must not be an empty string. It is recommended that a
+// compositor includes an opaque generation value in identifiers. How the
+// generation value is used when generating the identifier is implementation
+// dependent.
+type ExtForeignToplevelHandleV1IdentifierEvent struct {
+	Identifier string
+}
+type ExtForeignToplevelHandleV1IdentifierHandlerFunc func(ExtForeignToplevelHandleV1IdentifierEvent)
+
+// SetIdentifierHandler : sets handler for ExtForeignToplevelHandleV1IdentifierEvent
+func (i *ExtForeignToplevelHandleV1) SetIdentifierHandler(f ExtForeignToplevelHandleV1IdentifierHandlerFunc) {
+	i.identifierHandler = f
+}
+
+func (i *ExtForeignToplevelHandleV1) Dispatch(opcode uint32, fd int, data []byte) {
+	switch opcode {
+	case 0:
+		if i.closedHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelHandleV1ClosedEvent
+
+		i.closedHandler(e)
+	case 1:
+		if i.doneHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelHandleV1DoneEvent
+
+		i.doneHandler(e)
+	case 2:
+		if i.titleHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelHandleV1TitleEvent
+		l := 0
+		titleLen := client.PaddedLen(int(client.Uint32(data[l : l+4])))
+		l += 4
+		e.Title = client.String(data[l : l+titleLen])
+		l += titleLen
+
+		i.titleHandler(e)
+	case 3:
+		if i.appIdHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelHandleV1AppIdEvent
+		l := 0
+		appIdLen := client.PaddedLen(int(client.Uint32(data[l : l+4])))
+		l += 4
+		e.AppId = client.String(data[l : l+appIdLen])
+		l += appIdLen
+
+		i.appIdHandler(e)
+	case 4:
+		if i.identifierHandler == nil {
+			return
+		}
+		var e ExtForeignToplevelHandleV1IdentifierEvent
+		l := 0
+		identifierLen := client.PaddedLen(int(client.Uint32(data[l : l+4])))
+		l += 4
+		e.Identifier = client.String(data[l : l+identifierLen])
+		l += identifierLen
+
+		i.identifierHandler(e)
+	}
+}
@@ -0,0 +1,378 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
+	xdraw "golang.org/x/image/draw"
+)
+
+type AnnotateAction int
+
+const (
+	AnnotateCancel AnnotateAction = iota
+	AnnotateDone                  // Save and copy as configured
+	AnnotateSave                  // Save to file only
+	AnnotateCopy                  // Copy to clipboard only
+)
+
+const (
+	annotateMinWidth = 1
+	annotateMaxWidth = 64
+	annotateHUDPad   = 10
+)
+
+// Annotator is a full-screen layer surface for marking up a captured image
+// before it is saved or copied.
+type Annotator struct {
+	*overlayClient
+
+	buf    *ShmBuffer
+	format uint32
+	base   *image.RGBA
+	flat   *image.RGBA // base with every committed annotation applied
+	doc    annotationDoc
+
+	surface    overlaySurface
+	logicalW   int
+	logicalH   int
+	scale      int
+	slots      renderSlots
+	slotsReady bool
+	dirty      bool
+
+	// backdrop is the surface-sized background with the scaled image,
+	// canvas is the backdrop plus live preview and HUD.
+	backdrop   *image.RGBA
+	canvas     *image.RGBA
+	viewRect   image.Rectangle
+	viewScale  float64
+	viewOrigin fpoint
+
+	tool     AnnotateTool
+	colorIdx int
+	width    float64
+	pending  *annotation
+	typing   bool
+	pointerX float64
+	pointerY float64
+	mods     uint32
+	locked   uint32
+	group    uint32
+
+	running bool
+	action  AnnotateAction
+}
+
+// Annotate opens the editor over buf, which holds pixels in the given wl_shm
+// format. Unless the user cancels, the annotations are drawn back into buf.
+func Annotate(buf *ShmBuffer, format uint32) (AnnotateAction, error) {
+	return NewAnnotator(buf, format).Run()
+}
+
+func NewAnnotator(buf *ShmBuffer, format uint32) *Annotator {
+	base := BufferToImageWithFormat(buf, format)
+	a := &Annotator{
+		overlayClient: newOverlayClient(),
+		buf:           buf,
+		format:        format,
+		base:          base,
+		flat:          base,
+		scale:         1,
+	}
+	a.setupPointer = a.setupPointerHandlers
+	a.setupKeyboard = a.setupKeyboardHandlers
+	return a
+}
+
+func (a *Annotator) Run() (AnnotateAction, error) {
+	if err := a.connect(); err != nil {
+		return AnnotateCancel, fmt.Errorf("wayland connect: %w", err)
+	}
+	defer a.cleanup()
+
+	if err := a.setupRegistry(); err != nil {
+		return AnnotateCancel, fmt.Errorf("registry setup: %w", err)
+	}
+
+	if err := a.roundtrip(); err != nil {
+		return AnnotateCancel, fmt.Errorf("roundtrip after registry: %w", err)
+	}
+
+	if err := a.checkGlobals(); err != nil {
+		return AnnotateCancel, err
+	}
+
+	// Second roundtrip delivers output names and scales.
+	if err := a.roundtrip(); err != nil {
+		return AnnotateCancel, fmt.Errorf("roundtrip after protocol check: %w", err)
+	}
+
+	output := a.focusedOutput()
+	if output != nil && output.scale > 0 {
+		a.scale = int(output.scale)
+	}
+	a.width = float64(4 * a.scale)
+
+	if err := a.createSurface(output); err != nil {
+		return AnnotateCancel, fmt.Errorf("create surface: %w", err)
+	}
+
+	_ = a.createCursor()
+
+	a.running = true
+	for a.running {
+		if err := a.ctx.Dispatch(); err != nil {
+			return AnnotateCancel, fmt.Errorf("dispatch: %w", err)
+		}
+	}
+
+	if a.action != AnnotateCancel {
+		a.commitPending()
+		copyImageToBuffer(a.buf, a.doc.render(a.base), a.format)
+	}
+	return a.action, nil
+}
+
+func (a *Annotator) focusedOutput() *WaylandOutput {
+	a.outputsMu.Lock()
+	defer a.outputsMu.Unlock()
+
+	if mon := GetFocusedMonitor(); mon != "" {
+		for _, o := range a.outputs {
+			if o.name == mon {
+				return o
+			}
+		}
+	}
+	for _, o := range a.outputs {
+		return o
+	}
+	return nil
+}
+
+func (a *Annotator) createSurface(output *WaylandOutput) error {
+	surf, err := a.createOverlaySurface(output, "dms-annotate")
+	a.surface = surf
+	if err != nil {
+		return err
+	}
+
+	surf.layerSurf.SetConfigureHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ConfigureEvent) {
+		if err := surf.layerSurf.AckConfigure(e.Serial); err != nil {
+			log.Error("ack configure failed", "err", err)
+			return
+		}
+		if int(e.Width) != a.logicalW || int(e.Height) != a.logicalH || !a.slotsReady {
+			a.logicalW = int(e.Width)
+			a.logicalH = int(e.Height)
+			a.initRenderBuffers()
+			a.layout()
+		}
+		a.ensureShortcutsInhibitor(surf.wlSurface)
+		a.redraw()
+	})
+
+	surf.layerSurf.SetClosedHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ClosedEvent) {
+		a.action = AnnotateCancel
+		a.running = false
+	})
+
+	return surf.wlSurface.Commit()
+}
+
+func (a *Annotator) initRenderBuffers() {
+	a.slots.destroy()
+	a.slotsReady = false
+
+	w, h := a.logicalW*a.scale, a.logicalH*a.scale
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	a.slotsReady = a.createRenderSlots(&a.slots, w, h, w*4, uint32(FormatARGB8888), func() {
+		if a.dirty {
+			a.redraw()
+		}
+	})
+	if !a.slotsReady {
+		return
+	}
+	a.canvas = image.NewRGBA(image.Rect(0, 0, w, h))
+	a.backdrop = image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+// layout fits the image above the HUD without upscaling it.
+func (a *Annotator) layout() {
+	if a.backdrop == nil {
+		return
+	}
+	bw, bh := a.backdrop.Rect.Dx(), a.backdrop.Rect.Dy()
+	margin := 24 * a.scale
+	_, textH := labelSize("", a.scale)
+	hudH := textH + 2*annotateHUDPad*a.scale + margin
+
+	availW := float64(bw - 2*margin)
+	availH := float64(bh - margin - hudH)
+	imgW, imgH := float64(a.base.Rect.Dx()), float64(a.base.Rect.Dy())
+
+	a.viewScale = min(1, availW/imgW, availH/imgH)
+	if a.viewScale <= 0 {
+		a.viewScale = 1
+	}
+	vw, vh := int(imgW*a.viewScale), int(imgH*a.viewScale)
+	x := (bw - vw) / 2
+	y := margin + (bh-margin-hudH-vh)/2
+	a.viewRect = image.Rect(x, y, x+vw, y+vh)
+	a.viewOrigin = fpoint{float64(x), float64(y)}
+
+	a.rebuildBackdrop()
+}
+
+// rebuildBackdrop rescales the flattened image. It runs when the committed
+// annotations change, not on every pointer motion.
+func (a *Annotator) rebuildBackdrop() {
+	if a.backdrop == nil {
+		return
+	}
+	style := LoadOverlayStyle()
+	bg := color.RGBA{style.BackgroundR / 2, style.BackgroundG / 2, style.BackgroundB / 2, 255}
+	xdraw.Draw(a.backdrop, a.backdrop.Rect, image.NewUniform(bg), image.Point{}, xdraw.Src)
+	xdraw.ApproxBiLinear.Scale(a.backdrop, a.viewRect, a.flat, a.flat.Rect, xdraw.Src, nil)
+}
+
+func (a *Annotator) docChanged() {
+	a.flat = a.doc.render(a.base)
+	a.rebuildBackdrop()
+	a.redraw()
+}
+
+func (a *Annotator) redraw() {
+	if !a.slotsReady || a.canvas == nil {
+		return
+	}
+
+	slot := a.slots.acquire()
+	if slot == nil {
+		a.dirty = true
+		return
+	}
+	a.dirty = false
+
+	copy(a.canvas.Pix, a.backdrop.Pix)
+	if a.pending != nil {
+		preview := a.pending.scaled(a.viewScale, a.viewOrigin)
+		view := a.canvas.SubImage(a.viewRect).(*image.RGBA)
+		preview.draw(view)
+		if a.typing {
+			a.drawCaret(preview)
+		}
+	}
+	a.drawHUD()
+
+	copyImageToBuffer(slot.shm, a.canvas, uint32(FormatARGB8888))
+	a.surface.present(slot, a.logicalW, a.logicalH, int32(a.scale))
+}
+
+func (a *Annotator) drawCaret(preview annotation) {
+	scale := textScale(preview.width)
+	w, h := labelSize(preview.text, scale)
+	p := preview.points[0]
+	caret := image.Rect(int(p.X)+w+scale, int(p.Y), int(p.X)+w+2*scale, int(p.Y)+h)
+	xdraw.Draw(a.canvas, caret, image.NewUniform(preview.color), image.Point{}, xdraw.Src)
+}
+
+func (a *Annotator) drawHUD() {
+	style := LoadOverlayStyle()
+	text := color.RGBA{style.TextR, style.TextG, style.TextB, 255}
+	accent := color.RGBA{style.AccentR, style.AccentG, style.AccentB, 255}
+	s := a.scale
+	pad := annotateHUDPad * s
+	gap := 14 * s
+
+	type hudItem struct {
+		key, desc string
+		tool      AnnotateTool
+		isTool    bool
+	}
+	items := []hudItem{
+		{"A", "arrow", ToolArrow, true},
+		{"R", "rect", ToolRect, true},
+		{"F", "draw", ToolFreehand, true},
+		{"T", "text", ToolText, true},
+		{"B", "blur", ToolBlur, true},
+		{"X", "pixelate", ToolPixelate, true},
+		{"N", "number", ToolCallout, true},
+		{"C", "color", 0, false},
+		{"-/+", fmt.Sprintf("size %d", int(a.width)), 0, false},
+		{"^Z/^Y", "undo/redo", 0, false},
+		{"Enter", "done", 0, false},
+		{"^S", "save", 0, false},
+		{"^C", "copy", 0, false},
+		{"Esc", "cancel", 0, false},
+	}
+
+	widths := make([]int, len(items))
+	total := 0
+	for i, item := range items {
+		w, _ := labelSize(item.key+" "+item.desc, s)
+		widths[i] = w
+		total += w + gap
+	}
+	_, textH := labelSize("", s)
+	swatch := textH
+	total += swatch - gap
+
+	bw, bh := a.canvas.Rect.Dx(), a.canvas.Rect.Dy()
+	hud := image.Rect(0, 0, total+2*pad, textH+2*pad)
+	hud = hud.Add(image.Pt((bw-hud.Dx())/2, bh-hud.Dy()-12*s))
+	blendRect(a.canvas, hud, color.RGBA{style.BackgroundR, style.BackgroundG, style.BackgroundB, style.BackgroundA})
+
+	x, y := hud.Min.X+pad, hud.Min.Y+pad
+	for i, item := range items {
+		if item.isTool && item.tool == a.tool {
+			hl := image.Rect(x-pad/2, y-pad/2, x+widths[i]+pad/2, y+textH+pad/2)
+			blendRect(a.canvas, hl, color.RGBA{accent.R, accent.G, accent.B, 70})
+		}
+		drawLabel(a.canvas, x, y, item.key, s, accent)
+		kw, _ := labelSize(item.key+" ", s)
+		drawLabel(a.canvas, x+kw, y, item.desc, s, text)
+		x += widths[i] + gap
+
+		if item.key == "C" {
+			sw := image.Rect(x-gap/2, y, x-gap/2+swatch, y+swatch)
+			xdraw.Draw(a.canvas, sw, image.NewUniform(a.color()), image.Point{}, xdraw.Src)
+			x += swatch
+		}
+	}
+}
+
+func blendRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Rect)
+	alpha := int(c.A)
+	inv := 255 - alpha
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			off := img.PixOffset(x, y)
+			p := img.Pix[off : off+4 : off+4]
+			p[0] = uint8((int(p[0])*inv + int(c.R)*alpha) / 255)
+			p[1] = uint8((int(p[1])*inv + int(c.G)*alpha) / 255)
+			p[2] = uint8((int(p[2])*inv + int(c.B)*alpha) / 255)
+			p[3] = 255
+		}
+	}
+}
+
+func (a *Annotator) color() color.RGBA {
+	return annotatePalette[a.colorIdx%len(annotatePalette)]
+}
+
+func (a *Annotator) cleanup() {
+	a.destroyCursor()
+	a.slots.destroy()
+	a.surface.destroy()
+	a.close()
+}
@@ -0,0 +1,391 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+type AnnotateTool int
+
+const (
+	ToolArrow AnnotateTool = iota
+	ToolRect
+	ToolFreehand
+	ToolText
+	ToolBlur
+	ToolPixelate
+	ToolCallout
+)
+
+func (t AnnotateTool) String() string {
+	switch t {
+	case ToolArrow:
+		return "arrow"
+	case ToolRect:
+		return "rect"
+	case ToolFreehand:
+		return "draw"
+	case ToolText:
+		return "text"
+	case ToolBlur:
+		return "blur"
+	case ToolPixelate:
+		return "pixelate"
+	case ToolCallout:
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+// annotatePalette is cycled with C in the editor.
+var annotatePalette = []color.RGBA{
+	{230, 57, 70, 255},
+	{255, 190, 11, 255},
+	{46, 196, 102, 255},
+	{58, 134, 255, 255},
+	{255, 255, 255, 255},
+	{20, 20, 20, 255},
+}
+
+type fpoint struct{ X, Y float64 }
+
+// annotation is a single editor operation in image coordinates. Two-point
+// tools use the first and last point, text and callouts only the first.
+type annotation struct {
+	tool   AnnotateTool
+	color  color.RGBA
+	width  float64
+	points []fpoint
+	text   string
+	number int
+}
+
+func (a annotation) bounds() (x1, y1, x2, y2 float64) {
+	p, q := a.points[0], a.points[len(a.points)-1]
+	return min(p.X, q.X), min(p.Y, q.Y), max(p.X, q.X), max(p.Y, q.Y)
+}
+
+// scaled maps the annotation into view coordinates for live preview.
+func (a annotation) scaled(s float64, off fpoint) annotation {
+	out := a
+	out.width = a.width * s
+	out.points = make([]fpoint, len(a.points))
+	for i, p := range a.points {
+		out.points[i] = fpoint{p.X*s + off.X, p.Y*s + off.Y}
+	}
+	return out
+}
+
+func (a annotation) draw(img *image.RGBA) {
+	if len(a.points) == 0 {
+		return
+	}
+	first, last := a.points[0], a.points[len(a.points)-1]
+
+	switch a.tool {
+	case ToolArrow:
+		drawArrow(img, first, last, a.width, a.color)
+	case ToolRect:
+		x1, y1, x2, y2 := a.bounds()
+		strokeLine(img, fpoint{x1, y1}, fpoint{x2, y1}, a.width, a.color)
+		strokeLine(img, fpoint{x2, y1}, fpoint{x2, y2}, a.width, a.color)
+		strokeLine(img, fpoint{x2, y2}, fpoint{x1, y2}, a.width, a.color)
+		strokeLine(img, fpoint{x1, y2}, fpoint{x1, y1}, a.width, a.color)
+	case ToolFreehand:
+		if len(a.points) == 1 {
+			fillCircle(img, first, a.width/2, a.color)
+		}
+		for i := 1; i < len(a.points); i++ {
+			strokeLine(img, a.points[i-1], a.points[i], a.width, a.color)
+		}
+	case ToolText:
+		drawLabel(img, int(first.X), int(first.Y), a.text, textScale(a.width), a.color)
+	case ToolBlur:
+		blurRect(img, a.rect(), redactStrength(a.width))
+	case ToolPixelate:
+		pixelateRect(img, a.rect(), redactStrength(a.width))
+	case ToolCallout:
+		drawCallout(img, first, a.width, a.number, a.color)
+	}
+}
+
+func (a annotation) rect() image.Rectangle {
+	x1, y1, x2, y2 := a.bounds()
+	return image.Rect(int(math.Floor(x1)), int(math.Floor(y1)), int(math.Ceil(x2)), int(math.Ceil(y2)))
+}
+
+func textScale(width float64) int {
+	return max(1, int(math.Round(width/2)))
+}
+
+// redactStrength is the block size or blur radius for a stroke width. It
+// never drops low enough to leave text legible.
+func redactStrength(width float64) int {
+	return max(8, int(width*3))
+}
+
+// annotationDoc holds committed annotations with linear undo/redo.
+type annotationDoc struct {
+	items  []annotation
+	undone []annotation
+}
+
+func (d *annotationDoc) add(a annotation) {
+	d.items = append(d.items, a)
+	d.undone = nil
+}
+
+func (d *annotationDoc) undo() bool {
+	if len(d.items) == 0 {
+		return false
+	}
+	last := d.items[len(d.items)-1]
+	d.items = d.items[:len(d.items)-1]
+	d.undone = append(d.undone, last)
+	return true
+}
+
+func (d *annotationDoc) redo() bool {
+	if len(d.undone) == 0 {
+		return false
+	}
+	last := d.undone[len(d.undone)-1]
+	d.undone = d.undone[:len(d.undone)-1]
+	d.items = append(d.items, last)
+	return true
+}
+
+func (d *annotationDoc) nextNumber() int {
+	n := 1
+	for _, a := range d.items {
+		if a.tool == ToolCallout {
+			n = a.number + 1
+		}
+	}
+	return n
+}
+
+// render returns base with every annotation applied in order, so later
+// redactions also cover earlier markup.
+func (d *annotationDoc) render(base *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(base.Rect)
+	copy(out.Pix, base.Pix)
+	for _, a := range d.items {
+		a.draw(out)
+	}
+	return out
+}
+
+func fillCircle(img *image.RGBA, c fpoint, r float64, col color.RGBA) {
+	if r < 0.5 {
+		r = 0.5
+	}
+	b := img.Rect.Intersect(image.Rect(int(c.X-r)-1, int(c.Y-r)-1, int(c.X+r)+2, int(c.Y+r)+2))
+	r2 := r * r
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		dy := float64(y) + 0.5 - c.Y
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dx := float64(x) + 0.5 - c.X
+			if dx*dx+dy*dy <= r2 {
+				img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+// strokeLine stamps round brushes along the segment, which also gives
+// freehand strokes round joins for free.
+func strokeLine(img *image.RGBA, a, b fpoint, width float64, col color.RGBA) {
+	r := width / 2
+	dist := math.Hypot(b.X-a.X, b.Y-a.Y)
+	step := max(0.5, r/2)
+	n := int(dist/step) + 1
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		fillCircle(img, fpoint{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}, r, col)
+	}
+}
+
+func fillTriangle(img *image.RGBA, p0, p1, p2 fpoint, col color.RGBA) {
+	minX := int(math.Floor(min(p0.X, p1.X, p2.X)))
+	minY := int(math.Floor(min(p0.Y, p1.Y, p2.Y)))
+	maxX := int(math.Ceil(max(p0.X, p1.X, p2.X)))
+	maxY := int(math.Ceil(max(p0.Y, p1.Y, p2.Y)))
+	b := img.Rect.Intersect(image.Rect(minX, minY, maxX+1, maxY+1))
+
+	edge := func(a, b fpoint, x, y float64) float64 {
+		return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		py := float64(y) + 0.5
+		for x := b.Min.X; x < b.Max.X; x++ {
+			px := float64(x) + 0.5
+			e0, e1, e2 := edge(p0, p1, px, py), edge(p1, p2, px, py), edge(p2, p0, px, py)
+			if (e0 >= 0 && e1 >= 0 && e2 >= 0) || (e0 <= 0 && e1 <= 0 && e2 <= 0) {
+				img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+func drawArrow(img *image.RGBA, from, to fpoint, width float64, col color.RGBA) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length < 1 {
+		fillCircle(img, to, width/2, col)
+		return
+	}
+	ux, uy := dx/length, dy/length
+
+	head := min(max(width*4, 12), length)
+	base := fpoint{to.X - ux*head, to.Y - uy*head}
+	half := head * 0.55
+	left := fpoint{base.X - uy*half, base.Y + ux*half}
+	right := fpoint{base.X + uy*half, base.Y - ux*half}
+
+	// Stop the shaft inside the head so the round cap doesn't poke out.
+	strokeLine(img, from, fpoint{to.X - ux*head*0.8, to.Y - uy*head*0.8}, width, col)
+	fillTriangle(img, to, left, right, col)
+}
+
+func drawCallout(img *image.RGBA, c fpoint, width float64, number int, col color.RGBA) {
+	r := 10 + width*3
+	fillCircle(img, c, r+max(1, width/3), color.RGBA{255, 255, 255, 255})
+	fillCircle(img, c, r, col)
+
+	label := strconv.Itoa(number)
+	scale := max(1, int(r/9))
+	w, h := labelSize(label, scale)
+	drawLabel(img, int(c.X)-w/2, int(c.Y)-h/2, label, scale, contrastColor(col))
+}
+
+func contrastColor(c color.RGBA) color.RGBA {
+	if 299*int(c.R)+587*int(c.G)+114*int(c.B) > 150000 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
+
+// labelSize is the pixel size of text drawn with drawLabel.
+func labelSize(text string, scale int) (int, int) {
+	face := basicfont.Face7x13
+	return font.MeasureString(face, text).Ceil() * scale, face.Height * scale
+}
+
+// drawLabel renders text with its top-left corner at (x, y), upscaling the
+// fixed 7x13 font by an integer factor so it stays crisp.
+func drawLabel(img *image.RGBA, x, y int, text string, scale int, col color.RGBA) {
+	if text == "" {
+		return
+	}
+	face := basicfont.Face7x13
+	w, h := labelSize(text, 1)
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	d := font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	d.DrawString(text)
+
+	for my := 0; my < h; my++ {
+		for mx := 0; mx < w; mx++ {
+			if mask.Pix[my*mask.Stride+mx] < 128 {
+				continue
+			}
+			px, py := x+mx*scale, y+my*scale
+			for sy := 0; sy < scale; sy++ {
+				for sx := 0; sx < scale; sx++ {
+					if image.Pt(px+sx, py+sy).In(img.Rect) {
+						img.SetRGBA(px+sx, py+sy, col)
+					}
+				}
+			}
+		}
+	}
+}
+
+// pixelateRect replaces each block with its average colour.
+func pixelateRect(img *image.RGBA, r image.Rectangle, block int) {
+	r = r.Intersect(img.Rect)
+	for by := r.Min.Y; by < r.Max.Y; by += block {
+		for bx := r.Min.X; bx < r.Max.X; bx += block {
+			cell := image.Rect(bx, by, bx+block, by+block).Intersect(r)
+			var sr, sg, sb, n int
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					c := img.RGBAAt(x, y)
+					sr, sg, sb = sr+int(c.R), sg+int(c.G), sb+int(c.B)
+					n++
+				}
+			}
+			avg := color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), 255}
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					img.SetRGBA(x, y, avg)
+				}
+			}
+		}
+	}
+}
+
+// blurRect applies three box blur passes, close to a gaussian, sampling
+// only from inside the rectangle so nothing outside bleeds in.
+func blurRect(img *image.RGBA, r image.Rectangle, radius int) {
+	r = r.Intersect(img.Rect)
+	if r.Empty() {
+		return
+	}
+	w, h := r.Dx(), r.Dy()
+	buf := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.RGBAAt(r.Min.X+x, r.Min.Y+y)
+			buf[y*w+x] = [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+		}
+	}
+
+	tmp := make([][3]float64, len(buf))
+	for range 3 {
+		boxBlur(buf, tmp, w, h, radius, 1, w)
+		boxBlur(tmp, buf, h, w, radius, w, 1)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := buf[y*w+x]
+			img.SetRGBA(r.Min.X+x, r.Min.Y+y, color.RGBA{uint8(v[0]), uint8(v[1]), uint8(v[2]), 255})
+		}
+	}
+}
+
+// boxBlur runs a sliding-window mean along lines of length n. step is the
+// distance between neighbours in a line, lineStep between lines.
+func boxBlur(src, dst [][3]float64, n, lines, radius, step, lineStep int) {
+	for l := 0; l < lines; l++ {
+		base := l * lineStep
+		at := func(i int) [3]float64 { return src[base+clamp(i, 0, n-1)*step] }
+
+		var sum [3]float64
+		for i := -radius; i <= radius; i++ {
+			v := at(i)
+			sum[0], sum[1], sum[2] = sum[0]+v[0], sum[1]+v[1], sum[2]+v[2]
+		}
+		size := float64(2*radius + 1)
+		for i := 0; i < n; i++ {
+			dst[base+i*step] = [3]float64{sum[0] / size, sum[1] / size, sum[2] / size}
+			in, out := at(i+radius+1), at(i-radius)
+			sum[0] += in[0] - out[0]
+			sum[1] += in[1] - out[1]
+			sum[2] += in[2] - out[2]
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package screenshot
+
+import (
+	"math"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+const (
+	modShift = 1 << 0
+	modCtrl  = 1 << 2
+)
+
+// usKeymap maps evdev keycodes to characters on a US layout, unshifted and
+// shifted. Text entry uses it only when the compositor's keymap couldn't be
+// loaded.
+var usKeymap = map[uint32][2]rune{
+	2: {'1', '!'}, 3: {'2', '@'}, 4: {'3', '#'}, 5: {'4', '$'}, 6: {'5', '%'},
+	7: {'6', '^'}, 8: {'7', '&'}, 9: {'8', '*'}, 10: {'9', '('}, 11: {'0', ')'},
+	12: {'-', '_'}, 13: {'=', '+'},
+	16: {'q', 'Q'}, 17: {'w', 'W'}, 18: {'e', 'E'}, 19: {'r', 'R'}, 20: {'t', 'T'},
+	21: {'y', 'Y'}, 22: {'u', 'U'}, 23: {'i', 'I'}, 24: {'o', 'O'}, 25: {'p', 'P'},
+	26: {'[', '{'}, 27: {']', '}'},
+	30: {'a', 'A'}, 31: {'s', 'S'}, 32: {'d', 'D'}, 33: {'f', 'F'}, 34: {'g', 'G'},
+	35: {'h', 'H'}, 36: {'j', 'J'}, 37: {'k', 'K'}, 38: {'l', 'L'},
+	39: {';', ':'}, 40: {'\'', '"'}, 41: {'`', '~'}, 43: {'\\', '|'},
+	44: {'z', 'Z'}, 45: {'x', 'X'}, 46: {'c', 'C'}, 47: {'v', 'V'}, 48: {'b', 'B'},
+	49: {'n', 'N'}, 50: {'m', 'M'}, 51: {',', '<'}, 52: {'.', '>'}, 53: {'/', '?'},
+	57: {' ', ' '},
+}
+
+var annotateToolKeys = map[uint32]AnnotateTool{
+	30: ToolArrow,    // A
+	19: ToolRect,     // R
+	33: ToolFreehand, // F
+	20: ToolText,     // T
+	48: ToolBlur,     // B
+	45: ToolPixelate, // X
+	49: ToolCallout,  // N
+}
+
+// imagePoint converts the pointer position into image coordinates, clamped
+// to the image so redactions can't be dragged off the edge.
+func (a *Annotator) imagePoint() (fpoint, bool) {
+	bx, by := a.pointerX*float64(a.scale), a.pointerY*float64(a.scale)
+	x := (bx - a.viewOrigin.X) / a.viewScale
+	y := (by - a.viewOrigin.Y) / a.viewScale
+	w, h := float64(a.base.Rect.Dx()), float64(a.base.Rect.Dy())
+	inside := x >= 0 && y >= 0 && x < w && y < h
+	return fpoint{math.Max(0, math.Min(x, w)), math.Max(0, math.Min(y, h))}, inside
+}
+
+func (a *Annotator) setupPointerHandlers() {
+	a.pointer.SetEnterHandler(func(e client.PointerEnterEvent) {
+		if a.cursorSurface != nil {
+			_ = a.pointer.SetCursor(e.Serial, a.cursorSurface, 12, 12)
+		}
+		a.pointerX = e.SurfaceX
+		a.pointerY = e.SurfaceY
+	})
+
+	a.pointer.SetMotionHandler(func(e client.PointerMotionEvent) {
+		a.pointerX = e.SurfaceX
+		a.pointerY = e.SurfaceY
+
+		if a.pending == nil || a.typing {
+			return
+		}
+		p, _ := a.imagePoint()
+		if a.pending.tool == ToolFreehand {
+			a.pending.points = append(a.pending.points, p)
+		} else {
+			a.pending.points[1] = p
+		}
+		a.redraw()
+	})
+
+	a.pointer.SetButtonHandler(func(e client.PointerButtonEvent) {
+		switch e.Button {
+		case 0x110: // BTN_LEFT
+			if e.State == 1 {
+				a.pointerPressed()
+			} else {
+				a.pointerReleased()
+			}
+		case 0x111: // BTN_RIGHT
+			if e.State == 1 && a.pending != nil {
+				a.pending = nil
+				a.typing = false
+				a.redraw()
+			}
+		}
+	})
+
+	a.pointer.SetAxisHandler(func(e client.PointerAxisEvent) {
+		if e.Axis != uint32(client.PointerAxisVerticalScroll) || a.pending != nil {
+			return
+		}
+		a.adjustWidth(e.Value < 0)
+	})
+}
+
+func (a *Annotator) pointerPressed() {
+	if a.typing {
+		a.commitPending()
+	}
+
+	p, inside := a.imagePoint()
+	if !inside {
+		return
+	}
+
+	shape := annotation{tool: a.tool, color: a.color(), width: a.width, points: []fpoint{p}}
+	switch a.tool {
+	case ToolCallout:
+		shape.number = a.doc.nextNumber()
+		a.doc.add(shape)
+		a.docChanged()
+		return
+	case ToolText:
+		a.typing = true
+	case ToolFreehand:
+	default:
+		shape.points = append(shape.points, p)
+	}
+	a.pending = &shape
+	a.redraw()
+}
+
+func (a *Annotator) pointerReleased() {
+	if a.pending == nil || a.typing {
+		return
+	}
+	shape := *a.pending
+	a.pending = nil
+
+	if shape.tool != ToolFreehand {
+		x1, y1, x2, y2 := shape.bounds()
+		if x2-x1 < 2 && y2-y1 < 2 {
+			a.redraw()
+			return
+		}
+	}
+	a.doc.add(shape)
+	a.docChanged()
+}
+
+// commitPending finishes text entry, dropping it if nothing was typed.
+func (a *Annotator) commitPending() {
+	pending := a.pending
+	a.pending = nil
+	a.typing = false
+	if pending == nil || (pending.tool == ToolText && pending.text == "") {
+		return
+	}
+	a.doc.add(*pending)
+	a.docChanged()
+}
+
+func (a *Annotator) adjustWidth(grow bool) {
+	step := math.Max(1, math.Round(a.width/4))
+	if grow {
+		a.width = math.Min(a.width+step, annotateMaxWidth)
+	} else {
+		a.width = math.Max(a.width-step, annotateMinWidth)
+	}
+	a.redraw()
+}
+
+func (a *Annotator) finish(action AnnotateAction) {
+	a.action = action
+	a.running = false
+}
+
+func (a *Annotator) setupKeyboardHandlers() {
+	a.keyboard.SetModifiersHandler(func(e client.KeyboardModifiersEvent) {
+		a.mods = e.ModsDepressed | e.ModsLatched
+		a.locked = e.ModsLocked
+		a.group = e.Group
+	})
+
+	a.keyboard.SetKeyHandler(func(e client.KeyboardKeyEvent) {
+		if e.State != 1 {
+			return
+		}
+		if a.typing && a.handleTextKey(e.Key) {
+			return
+		}
+		if a.mods&modCtrl != 0 {
+			a.handleCtrlKey(e.Key)
+			return
+		}
+
+		switch e.Key {
+		case 1: // Esc
+			a.finish(AnnotateCancel)
+		case 28, 96: // Enter, KP Enter
+			a.finish(AnnotateDone)
+		case 46: // C
+			a.colorIdx = (a.colorIdx + 1) % len(annotatePalette)
+			a.redraw()
+		case 12, 74: // -, KP -
+			a.adjustWidth(false)
+		case 13, 78: // =, KP +
+			a.adjustWidth(true)
+		default:
+			if tool, ok := annotateToolKeys[e.Key]; ok {
+				a.tool = tool
+				a.redraw()
+			}
+		}
+	})
+}
+
+// handleTextKey edits the pending text label and reports whether the key
+// was consumed. Ctrl shortcuts end text entry and fall through.
+func (a *Annotator) handleTextKey(key uint32) bool {
+	if a.mods&modCtrl != 0 {
+		a.commitPending()
+		return false
+	}
+
+	switch key {
+	case 1: // Esc drops the label being typed
+		a.pending = nil
+		a.typing = false
+	case 28, 96:
+		a.commitPending()
+		return true
+	case 14: // Backspace
+		if text := []rune(a.pending.text); len(text) > 0 {
+			a.pending.text = string(text[:len(text)-1])
+		}
+	default:
+		if ch, ok := a.textChar(key); ok {
+			a.pending.text += string(ch)
+		}
+	}
+	a.redraw()
+	return true
+}
+
+// textChar resolves key through the compositor's keymap, falling back to
+// the US layout when none was received.
+func (a *Annotator) textChar(key uint32) (rune, bool) {
+	if a.keymap != nil {
+		return a.keymap.char(key, a.group, a.mods, a.locked)
+	}
+	chars, ok := usKeymap[key]
+	if !ok {
+		return 0, false
+	}
+	if a.mods&modShift != 0 {
+		return chars[1], true
+	}
+	return chars[0], true
+}
+
+func (a *Annotator) handleCtrlKey(key uint32) {
+	switch key {
+	case 44: // Z
+		if a.mods&modShift != 0 {
+			a.redo()
+		} else {
+			a.undo()
+		}
+	case 21: // Y
+		a.redo()
+	case 31: // S
+		a.finish(AnnotateSave)
+	case 46: // C
+		a.finish(AnnotateCopy)
+	}
+}
+
+func (a *Annotator) undo() {
+	if a.pending != nil {
+		a.pending = nil
+		a.typing = false
+		a.redraw()
+		return
+	}
+	if a.doc.undo() {
+		a.docChanged()
+	}
+}
+
+func (a *Annotator) redo() {
+	if a.doc.redo() {
+		a.docChanged()
+	}
+}
@@ -0,0 +1,129 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var red = color.RGBA{255, 0, 0, 255}
+
+func twoPoint(tool AnnotateTool, x1, y1, x2, y2 float64) annotation {
+	return annotation{tool: tool, color: red, width: 4, points: []fpoint{{x1, y1}, {x2, y2}}}
+}
+
+func TestAnnotationDocUndoRedo(t *testing.T) {
+	var d annotationDoc
+	assert.False(t, d.undo())
+	assert.False(t, d.redo())
+
+	d.add(twoPoint(ToolRect, 0, 0, 10, 10))
+	d.add(twoPoint(ToolArrow, 0, 0, 10, 10))
+	require.True(t, d.undo())
+	assert.Len(t, d.items, 1)
+	require.True(t, d.redo())
+	assert.Equal(t, ToolArrow, d.items[1].tool)
+
+	// A new edit after undo discards the redo stack.
+	require.True(t, d.undo())
+	d.add(twoPoint(ToolBlur, 0, 0, 10, 10))
+	assert.False(t, d.redo())
+	assert.Equal(t, ToolBlur, d.items[1].tool)
+}
+
+func TestAnnotationDocCalloutNumbers(t *testing.T) {
+	var d annotationDoc
+	assert.Equal(t, 1, d.nextNumber())
+
+	d.add(annotation{tool: ToolCallout, number: 1, points: []fpoint{{5, 5}}})
+	d.add(annotation{tool: ToolCallout, number: 2, points: []fpoint{{9, 9}}})
+	assert.Equal(t, 3, d.nextNumber())
+
+	d.undo()
+	assert.Equal(t, 2, d.nextNumber())
+}
+
+func TestAnnotationDocRenderLeavesBase(t *testing.T) {
+	base := solidFrame(40, 40, black)
+	var d annotationDoc
+	d.add(twoPoint(ToolRect, 5, 5, 30, 30))
+
+	out := d.render(base)
+	assert.Equal(t, red, out.RGBAAt(5, 17))
+	assert.Equal(t, black, out.RGBAAt(17, 17), "rectangle must not be filled")
+	assert.Equal(t, black, base.RGBAAt(5, 17))
+}
+
+func TestArrowReachesTip(t *testing.T) {
+	img := solidFrame(100, 40, black)
+	drawArrow(img, fpoint{10, 20}, fpoint{90, 20}, 4, red)
+
+	assert.Equal(t, red, img.RGBAAt(10, 20))
+	assert.Equal(t, red, img.RGBAAt(88, 20))
+	assert.Equal(t, red, img.RGBAAt(76, 25), "head is wider than the shaft")
+	assert.Equal(t, black, img.RGBAAt(30, 26))
+}
+
+func TestPixelateRect(t *testing.T) {
+	img := solidFrame(32, 32, black)
+	fillRect(img, image.Rect(0, 0, 4, 8), white)
+
+	pixelateRect(img, image.Rect(0, 0, 8, 8), 8)
+
+	got := img.RGBAAt(7, 7)
+	assert.Equal(t, uint8(127), got.R)
+	assert.Equal(t, got, img.RGBAAt(0, 0))
+	assert.Equal(t, black, img.RGBAAt(8, 8), "outside the rect is untouched")
+}
+
+func TestBlurRectStaysInside(t *testing.T) {
+	img := solidFrame(32, 32, black)
+	img.SetRGBA(10, 10, white)
+	fillRect(img, image.Rect(20, 0, 32, 32), white)
+
+	blurRect(img, image.Rect(0, 0, 20, 20), 8)
+
+	assert.Less(t, img.RGBAAt(10, 10).R, uint8(16), "single bright pixel is smeared out")
+	assert.Equal(t, uint8(0), img.RGBAAt(19, 5).R, "nothing bleeds in from outside")
+	assert.Equal(t, white, img.RGBAAt(20, 5))
+}
+
+func TestDrawLabel(t *testing.T) {
+	img := solidFrame(60, 30, black)
+	drawLabel(img, 2, 2, "Hi", 2, red)
+
+	w, h := labelSize("Hi", 2)
+	assert.Equal(t, 28, w)
+	assert.Equal(t, 26, h)
+
+	painted := 0
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 60; x++ {
+			if img.RGBAAt(x, y) == red {
+				painted++
+				assert.True(t, image.Pt(x, y).In(image.Rect(2, 2, 2+w, 2+h)))
+			}
+		}
+	}
+	assert.Positive(t, painted)
+}
+
+func TestCopyImageToBufferRoundTrip(t *testing.T) {
+	buf, err := CreateShmBuffer(4, 2, 16)
+	require.NoError(t, err)
+	defer buf.Close()
+
+	img := solidFrame(4, 2, black)
+	img.SetRGBA(1, 1, color.RGBA{10, 20, 30, 255})
+
+	for _, format := range []uint32{uint32(FormatARGB8888), uint32(FormatXBGR8888)} {
+		copyImageToBuffer(buf, img, format)
+		assert.Equal(t, img.Pix, BufferToImageWithFormat(buf, format).Pix)
+	}
+
+	copyImageToBuffer(buf, img, uint32(FormatARGB8888))
+	assert.Equal(t, []byte{30, 20, 10, 255}, buf.Data()[16+4:16+8])
+}
@@ -0,0 +1,290 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+// Shot describes one delivered screenshot.
+type Shot struct {
+	// ID is the history entry, when the shot was saved and recorded.
+	ID        string    `json:"id,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Format    string    `json:"format"`
+	Size      int       `json:"size"`
+	Region    Region    `json:"region"`
+	Clipboard bool      `json:"clipboard"`
+	Time      time.Time `json:"time"`
+}
+
+// Take runs the whole pipeline: the delay, Repeat captures Interval apart,
+// annotation, encoding and delivery. A region is selected once and reused
+// for the rest of a burst; only the last shot of a burst goes to the
+// clipboard and notification. Cancelling a selection returns the shots so
+// far without an error.
+func Take(ctx context.Context, config Config) ([]Shot, error) {
+	if config.Delay > 0 {
+		wait := sleepContext
+		if config.Countdown {
+			wait = Countdown
+		}
+		if err := wait(ctx, config.Delay); err != nil {
+			return nil, err
+		}
+	}
+
+	repeat := max(config.Repeat, 1)
+	shots := make([]Shot, 0, repeat)
+	for i := range repeat {
+		if i > 0 {
+			if err := sleepContext(ctx, config.Interval); err != nil {
+				return shots, err
+			}
+		}
+
+		cfg := config
+		if i > 0 && cfg.Mode == ModeRegion {
+			cfg.Mode = ModeLastRegion
+		}
+		if i < repeat-1 {
+			cfg.Clipboard = false
+			cfg.Notify = false
+		}
+
+		shot, err := takeOne(cfg, i+1)
+		if err != nil {
+			return shots, err
+		}
+		if shot == nil {
+			break
+		}
+		shots = append(shots, *shot)
+	}
+	return shots, nil
+}
+
+func takeOne(config Config, index int) (*Shot, error) {
+	result, err := New(config).Run()
+	if err != nil || result == nil {
+		return nil, err
+	}
+	defer result.Buffer.Close()
+
+	if result.YInverted {
+		result.Buffer.FlipVertical()
+	}
+
+	if config.Annotate {
+		action, err := Annotate(result.Buffer, result.Format)
+		if err != nil {
+			return nil, fmt.Errorf("annotate: %w", err)
+		}
+		switch action {
+		case AnnotateCancel:
+			return nil, nil
+		case AnnotateSave:
+			config.Clipboard = false
+			config.SaveFile = true
+		case AnnotateCopy:
+			config.Clipboard = true
+			config.SaveFile = false
+		}
+	}
+
+	shot := &Shot{
+		Width:  result.Buffer.Width,
+		Height: result.Buffer.Height,
+		Format: config.Format.String(),
+		Region: result.Region,
+		Time:   time.Now(),
+	}
+	outputName := result.Region.Output
+	if outputName == "" {
+		outputName = config.OutputName
+	}
+
+	opts := EncodeOptions{
+		Format:   config.Format,
+		Quality:  config.Quality,
+		Lossless: config.Lossless,
+	}
+	if config.Metadata {
+		opts.Metadata = &Metadata{
+			Time:   shot.Time,
+			Output: outputName,
+			Mode:   config.Mode,
+			Region: result.Region,
+		}
+	}
+
+	img := BufferToImageWithFormat(result.Buffer, result.Format)
+	data, err := EncodeWithinSize(img, opts, config.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	shot.Size = len(data)
+
+	if config.Stdout {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return nil, fmt.Errorf("write to stdout: %w", err)
+		}
+		return shot, nil
+	}
+
+	if config.SaveFile {
+		path, err := shotPath(config, FilenameInfo{
+			Time:   shot.Time,
+			Output: outputName,
+			Mode:   config.Mode,
+			Width:  shot.Width,
+			Height: shot.Height,
+			Index:  index,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, fmt.Errorf("write file: %w", err)
+		}
+		shot.Path = path
+
+		if config.History {
+			if entry, err := AddHistory(*shot, config.Mode, outputName, img); err != nil {
+				log.Warnf("screenshot history: %v", err)
+			} else {
+				shot.ID = entry.ID
+			}
+		}
+	}
+
+	if config.Clipboard {
+		if err := copyToClipboard(img, data, config.Format); err != nil {
+			return nil, fmt.Errorf("copy to clipboard: %w", err)
+		}
+		shot.Clipboard = true
+	}
+
+	if config.Notify {
+		thumbData, thumbW, thumbH := rgbThumbnail(result.Buffer, 256, result.Format)
+		SendNotification(NotifyResult{
+			FilePath:  shot.Path,
+			Clipboard: shot.Clipboard,
+			ImageData: thumbData,
+			Width:     thumbW,
+			Height:    thumbH,
+		})
+	}
+	return shot, nil
+}
+
+// shotPath expands the filename and creates its directory. An explicit
+// filename is used as given and overwrites, apart from a burst index
+// suffix after the first shot. A name from the template never overwrites;
+// a numeric suffix is added instead, which also keeps bursts within the
+// same second apart.
+func shotPath(config Config, info FilenameInfo) (string, error) {
+	outputDir := config.OutputDir
+	if outputDir == "" {
+		outputDir = GetOutputDir()
+	}
+
+	filename := config.Filename
+	explicit := filename != ""
+	if !explicit {
+		template := config.Template
+		if template == "" {
+			template = FilenameTemplate()
+		}
+		filename = ExpandFilename(template, config.Format, info)
+	}
+
+	path := filepath.Join(outputDir, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if explicit {
+		if info.Index > 1 {
+			return base + "-" + strconv.Itoa(info.Index) + ext, nil
+		}
+		return path, nil
+	}
+
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path, nil
+		}
+		path = base + "-" + strconv.Itoa(n) + ext
+	}
+}
+
+// copyToClipboard offers the encoded bytes when clients are likely to
+// understand the format and falls back to PNG otherwise.
+func copyToClipboard(img *image.RGBA, data []byte, format Format) error {
+	switch format {
+	case FormatPNG, FormatJPEG, FormatWebP, FormatAVIF:
+		return clipboard.Copy(data, format.MimeType())
+	}
+
+	var png bytes.Buffer
+	if err := EncodePNG(&png, img); err != nil {
+		return err
+	}
+	return clipboard.Copy(png.Bytes(), "image/png")
+}
+
+// rgbThumbnail downsamples buf to packed RGB for notification icons.
+func rgbThumbnail(buf *ShmBuffer, maxSize int, pixelFormat uint32) ([]byte, int, int) {
+	srcW, srcH := buf.Width, buf.Height
+	scale := 1.0
+	if srcW > maxSize || srcH > maxSize {
+		if srcW > srcH {
+			scale = float64(maxSize) / float64(srcW)
+		} else {
+			scale = float64(maxSize) / float64(srcH)
+		}
+	}
+
+	dstW := max(int(float64(srcW)*scale), 1)
+	dstH := max(int(float64(srcH)*scale), 1)
+
+	data := buf.Data()
+	rgb := make([]byte, dstW*dstH*3)
+	swapRB := pixelFormat != uint32(FormatABGR8888) && pixelFormat != uint32(FormatXBGR8888)
+
+	for y := 0; y < dstH; y++ {
+		srcY := min(int(float64(y)/scale), srcH-1)
+		for x := 0; x < dstW; x++ {
+			srcX := min(int(float64(x)/scale), srcW-1)
+			si := srcY*buf.Stride + srcX*4
+			di := (y*dstW + x) * 3
+			if si+3 >= len(data) {
+				continue
+			}
+			if swapRB {
+				rgb[di+0] = data[si+2]
+				rgb[di+1] = data[si+1]
+				rgb[di+2] = data[si+0]
+			} else {
+				rgb[di+0] = data[si+0]
+				rgb[di+1] = data[si+1]
+				rgb[di+2] = data[si+2]
+			}
+		}
+	}
+	return rgb, dstW, dstH
+}
@@ -0,0 +1,78 @@
+package screenshot
+
+import (
+	"context"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShotPathNeverOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.OutputDir = dir
+	cfg.Template = "%F/shot_{n}"
+	info := FilenameInfo{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), Index: 2}
+
+	path, err := shotPath(cfg, info)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2024-01-02", "shot_2.png"), path)
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	path, err = shotPath(cfg, info)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2024-01-02", "shot_2-2.png"), path)
+}
+
+func TestShotPathExplicitFilenameOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.OutputDir = dir
+	cfg.Filename = "shot.png"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shot.png"), nil, 0o644))
+
+	path, err := shotPath(cfg, FilenameInfo{Time: time.Now(), Index: 1})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "shot.png"), path)
+
+	path, err = shotPath(cfg, FilenameInfo{Time: time.Now(), Index: 3})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "shot-3.png"), path)
+}
+
+func TestTakeCancelledDuringDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := DefaultConfig()
+	cfg.Delay = time.Hour
+	cfg.Countdown = false
+	shots, err := Take(ctx, cfg)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, shots)
+}
+
+func TestRenderCountdown(t *testing.T) {
+	img := renderCountdown(3, 2, DefaultOverlayStyle)
+	size := countdownSize * 2
+	require.Equal(t, size, img.Bounds().Dx())
+
+	assert.Zero(t, img.RGBAAt(0, 0).A, "corners stay transparent")
+	assert.Equal(t, uint8(255), img.RGBAAt(size/2, 2).A, "ring is opaque")
+	assert.Equal(t, DefaultOverlayStyle.BackgroundA, img.RGBAAt(size/2, size/6).A)
+
+	text := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if img.RGBAAt(x, y) == (color.RGBA{DefaultOverlayStyle.TextR, DefaultOverlayStyle.TextG, DefaultOverlayStyle.TextB, 255}) {
+				text++
+			}
+		}
+	}
+	assert.Positive(t, text, "digit is drawn")
+}
@@ -0,0 +1,352 @@
+package screenshot
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
+	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+// countdownSize is the badge's logical width and height.
+const countdownSize = 96
+
+// Countdown waits d while showing the remaining seconds in a badge on the
+// focused output. The badge takes no keyboard or pointer input, so open
+// menus and hover states survive until the capture. Without layer-shell it
+// degrades to a plain wait.
+func Countdown(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	c := &countdown{outputs: make(map[uint32]*WaylandOutput), scale: 1}
+	deadline := time.Now().Add(d)
+	if err := c.show(); err != nil {
+		log.Warnf("countdown overlay unavailable: %v", err)
+		c.cleanup()
+		return sleepContext(ctx, time.Until(deadline))
+	}
+	defer c.cleanup()
+
+	return c.run(ctx, deadline)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type countdown struct {
+	display  *client.Display
+	registry *client.Registry
+	ctx      *client.Context
+
+	compositor *client.Compositor
+	shm        *client.Shm
+	layerShell *wlr_layer_shell.ZwlrLayerShellV1
+	outputs    map[uint32]*WaylandOutput
+
+	wlSurface  *client.Surface
+	layerSurf  *wlr_layer_shell.ZwlrLayerSurfaceV1
+	buf        *ShmBuffer
+	pool       *client.ShmPool
+	wlBuf      *client.Buffer
+	scale      int
+	configured bool
+	busy       bool
+	shown      int
+}
+
+func (c *countdown) show() error {
+	display, err := client.Connect("")
+	if err != nil {
+		return fmt.Errorf("wayland connect: %w", err)
+	}
+	c.display = display
+	c.ctx = display.Context()
+
+	registry, err := display.GetRegistry()
+	if err != nil {
+		return err
+	}
+	c.registry = registry
+	registry.SetGlobalHandler(c.handleGlobal)
+
+	// The second roundtrip delivers output names and scales.
+	for range 2 {
+		if err := wlhelpers.Roundtrip(c.display, c.ctx); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case c.layerShell == nil:
+		return fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
+	case c.compositor == nil || c.shm == nil:
+		return fmt.Errorf("compositor or wl_shm not available")
+	}
+
+	output := c.focusedOutput()
+	if output != nil && output.scale > 0 {
+		c.scale = int(output.scale)
+	}
+	return c.createSurface(output)
+}
+
+func (c *countdown) handleGlobal(e client.RegistryGlobalEvent) {
+	switch e.Interface {
+	case client.CompositorInterfaceName:
+		comp := client.NewCompositor(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, comp); err == nil {
+			c.compositor = comp
+		}
+
+	case client.ShmInterfaceName:
+		shm := client.NewShm(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, shm); err == nil {
+			c.shm = shm
+		}
+
+	case client.OutputInterfaceName:
+		output := client.NewOutput(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, min(e.Version, 4), output); err != nil {
+			return
+		}
+		wo := &WaylandOutput{wlOutput: output, globalName: e.Name, scale: 1, fractionalScale: 1.0}
+		c.outputs[e.Name] = wo
+		output.SetScaleHandler(func(e client.OutputScaleEvent) {
+			wo.scale = e.Factor
+		})
+		output.SetNameHandler(func(e client.OutputNameEvent) {
+			wo.name = e.Name
+		})
+
+	case wlr_layer_shell.ZwlrLayerShellV1InterfaceName:
+		ls := wlr_layer_shell.NewZwlrLayerShellV1(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, min(e.Version, 4), ls); err == nil {
+			c.layerShell = ls
+		}
+	}
+}
+
+func (c *countdown) focusedOutput() *WaylandOutput {
+	if mon := GetFocusedMonitor(); mon != "" {
+		for _, o := range c.outputs {
+			if o.name == mon {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+func (c *countdown) createSurface(output *WaylandOutput) error {
+	surface, err := c.compositor.CreateSurface()
+	if err != nil {
+		return fmt.Errorf("create surface: %w", err)
+	}
+	c.wlSurface = surface
+
+	// An empty input region lets clicks and hover fall through.
+	region, err := c.compositor.CreateRegion()
+	if err != nil {
+		return fmt.Errorf("create region: %w", err)
+	}
+	_ = surface.SetInputRegion(region)
+	_ = region.Destroy()
+
+	var wlOutput *client.Output
+	if output != nil {
+		wlOutput = output.wlOutput
+	}
+	layerSurf, err := c.layerShell.GetLayerSurface(
+		surface,
+		wlOutput,
+		uint32(wlr_layer_shell.ZwlrLayerShellV1LayerOverlay),
+		"dms-countdown",
+	)
+	if err != nil {
+		return fmt.Errorf("get layer surface: %w", err)
+	}
+	c.layerSurf = layerSurf
+
+	if err := layerSurf.SetSize(countdownSize, countdownSize); err != nil {
+		return fmt.Errorf("set size: %w", err)
+	}
+	if err := layerSurf.SetExclusiveZone(-1); err != nil {
+		return fmt.Errorf("set exclusive zone: %w", err)
+	}
+
+	layerSurf.SetConfigureHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ConfigureEvent) {
+		if err := layerSurf.AckConfigure(e.Serial); err != nil {
+			log.Error("ack configure failed", "err", err)
+			return
+		}
+		c.configured = true
+	})
+	layerSurf.SetClosedHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ClosedEvent) {
+		c.configured = false
+	})
+
+	size := countdownSize * c.scale
+	buf, err := CreateShmBuffer(size, size, size*4)
+	if err != nil {
+		return err
+	}
+	c.buf = buf
+
+	pool, err := c.shm.CreatePool(buf.Fd(), int32(buf.Size()))
+	if err != nil {
+		return fmt.Errorf("create pool: %w", err)
+	}
+	c.pool = pool
+
+	wlBuf, err := pool.CreateBuffer(0, int32(size), int32(size), int32(size*4), uint32(FormatARGB8888))
+	if err != nil {
+		return fmt.Errorf("create buffer: %w", err)
+	}
+	c.wlBuf = wlBuf
+	wlBuf.SetReleaseHandler(func(e client.BufferReleaseEvent) {
+		c.busy = false
+	})
+
+	if err := surface.SetBufferScale(int32(c.scale)); err != nil {
+		return fmt.Errorf("set buffer scale: %w", err)
+	}
+	return surface.Commit()
+}
+
+func (c *countdown) run(ctx context.Context, deadline time.Time) error {
+	defer c.ctx.SetReadDeadline(time.Time{})
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return c.hide()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if secs := int(math.Ceil(remaining.Seconds())); secs != c.shown && c.configured && !c.busy {
+			c.draw(secs)
+		}
+
+		if err := c.ctx.SetReadDeadline(time.Now().Add(min(remaining, 100*time.Millisecond))); err != nil {
+			return err
+		}
+		if err := c.ctx.Dispatch(); err != nil && !isTimeout(err) {
+			return fmt.Errorf("dispatch: %w", err)
+		}
+	}
+}
+
+func (c *countdown) draw(secs int) {
+	copyBadgeToBuffer(c.buf, renderCountdown(secs, c.scale, LoadOverlayStyle()))
+
+	size := int32(countdownSize * c.scale)
+	_ = c.wlSurface.Attach(c.wlBuf, 0, 0)
+	_ = c.wlSurface.DamageBuffer(0, 0, size, size)
+	_ = c.wlSurface.Commit()
+	c.busy = true
+	c.shown = secs
+}
+
+// hide unmaps the badge and waits for the compositor to repaint without
+// it, so the capture that follows can't contain it.
+func (c *countdown) hide() error {
+	if c.layerSurf != nil {
+		c.layerSurf.Destroy()
+		c.layerSurf = nil
+	}
+	if c.wlSurface != nil {
+		c.wlSurface.Destroy()
+		c.wlSurface = nil
+	}
+	if err := c.ctx.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+	if err := wlhelpers.Roundtrip(c.display, c.ctx); err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return nil
+}
+
+func renderCountdown(secs, scale int, style OverlayStyle) *image.RGBA {
+	size := countdownSize * scale
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	center := fpoint{float64(size) / 2, float64(size) / 2}
+	radius := float64(size)/2 - 1
+	accent := color.RGBA{style.AccentR, style.AccentG, style.AccentB, 255}
+	fillCircle(img, center, radius, accent)
+	fillCircle(img, center, radius-float64(3*scale), premultiply(color.RGBA{
+		style.BackgroundR, style.BackgroundG, style.BackgroundB, style.BackgroundA,
+	}))
+
+	text := strconv.Itoa(secs)
+	textScale := 4 * scale
+	w, h := labelSize(text, textScale)
+	drawLabel(img, (size-w)/2, (size-h)/2, text, textScale, color.RGBA{style.TextR, style.TextG, style.TextB, 255})
+	return img
+}
+
+func premultiply(c color.RGBA) color.RGBA {
+	a := uint16(c.A)
+	return color.RGBA{uint8(uint16(c.R) * a / 255), uint8(uint16(c.G) * a / 255), uint8(uint16(c.B) * a / 255), c.A}
+}
+
+// copyBadgeToBuffer copies img into an ARGB8888 buffer keeping alpha,
+// unlike copyImageToBuffer which produces opaque screenshots.
+func copyBadgeToBuffer(buf *ShmBuffer, img *image.RGBA) {
+	data := buf.Data()
+	for y := 0; y < buf.Height; y++ {
+		for x := 0; x < buf.Width; x++ {
+			si := y*img.Stride + x*4
+			di := y*buf.Stride + x*4
+			data[di+0] = img.Pix[si+2]
+			data[di+1] = img.Pix[si+1]
+			data[di+2] = img.Pix[si+0]
+			data[di+3] = img.Pix[si+3]
+		}
+	}
+}
+
+func (c *countdown) cleanup() {
+	if c.wlBuf != nil {
+		c.wlBuf.Destroy()
+	}
+	if c.pool != nil {
+		c.pool.Destroy()
+	}
+	if c.buf != nil {
+		c.buf.Close()
+	}
+	if c.layerSurf != nil {
+		c.layerSurf.Destroy()
+	}
+	if c.wlSurface != nil {
+		c.wlSurface.Destroy()
+	}
+	if c.display != nil {
+		c.ctx.Close()
+	}
+}
@@ -2,16 +2,21 @@ package screenshot
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	xdraw "golang.org/x/image/draw"
 )
 
 func BufferToImage(buf *ShmBuffer) *image.RGBA {
@@ -20,6 +25,12 @@ func BufferToImage(buf *ShmBuffer) *image.RGBA {
 
 func BufferToImageWithFormat(buf *ShmBuffer, format uint32) *image.RGBA {
 	img := image.NewRGBA(image.Rect(0, 0, buf.Width, buf.Height))
+	copyBufferToImage(img, buf, format)
+	return img
+}
+
+// copyBufferToImage converts buf into img, which must have the same size.
+func copyBufferToImage(img *image.RGBA, buf *ShmBuffer, format uint32) {
 	data := buf.Data()
 
 	var swapRB bool
@@ -51,7 +62,35 @@ func BufferToImageWithFormat(buf *ShmBuffer, format uint32) *image.RGBA {
 			img.Pix[di+3] = 255
 		}
 	}
-	return img
+}
+
+// copyImageToBuffer is the inverse of copyBufferToImage.
+func copyImageToBuffer(buf *ShmBuffer, img *image.RGBA, format uint32) {
+	data := buf.Data()
+
+	swapRB := format != uint32(FormatABGR8888) && format != uint32(FormatXBGR8888)
+
+	for y := 0; y < buf.Height; y++ {
+		srcOff := y * img.Stride
+		dstOff := y * buf.Stride
+		for x := 0; x < buf.Width; x++ {
+			si := srcOff + x*4
+			di := dstOff + x*4
+			if di+3 >= len(data) || si+3 >= len(img.Pix) {
+				continue
+			}
+			if swapRB {
+				data[di+0] = img.Pix[si+2]
+				data[di+1] = img.Pix[si+1]
+				data[di+2] = img.Pix[si+0]
+			} else {
+				data[di+0] = img.Pix[si+0]
+				data[di+1] = img.Pix[si+1]
+				data[di+2] = img.Pix[si+2]
+			}
+			data[di+3] = 255
+		}
+	}
 }
 
 func EncodePNG(w io.Writer, img image.Image) error {
@@ -86,16 +125,250 @@ func EncodePPM(w io.Writer, img *image.RGBA) error {
 	return bw.Flush()
 }
 
-func GenerateFilename(format Format) string {
-	t := time.Now()
-	ext := "png"
+// decodePPM reads a binary (P6) PPM with 8-bit samples, as EncodePPM writes.
+func decodePPM(data []byte) (*image.RGBA, error) {
+	var magic string
+	var w, h, maxVal int
+	r := bytes.NewReader(data)
+	if _, err := fmt.Fscan(r, &magic, &w, &h, &maxVal); err != nil || magic != "P6" {
+		return nil, fmt.Errorf("not a ppm image")
+	}
+	if maxVal != 255 {
+		return nil, fmt.Errorf("unsupported ppm max value %d", maxVal)
+	}
+	if w <= 0 || h <= 0 || w*h > 1<<28 {
+		return nil, fmt.Errorf("invalid ppm size %dx%d", w, h)
+	}
+	if _, err := r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("truncated ppm image")
+	}
+
+	pix := data[len(data)-r.Len():]
+	if len(pix) < w*h*3 {
+		return nil, fmt.Errorf("truncated ppm image")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < w*h; i++ {
+		copy(img.Pix[i*4:i*4+3], pix[i*3:i*3+3])
+		img.Pix[i*4+3] = 255
+	}
+	return img, nil
+}
+
+// DecodeBytes decodes an image saved in format. JPEG XL and AVIF need
+// djxl or avifdec.
+func DecodeBytes(data []byte, format Format) (image.Image, error) {
 	switch format {
+	case FormatPPM:
+		return decodePPM(data)
+	case FormatQOI:
+		return decodeQOI(data)
+	case FormatJXL, FormatAVIF:
+		return decodeExternal(data, format)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+type EncodeOptions struct {
+	Format   Format
+	Quality  int
+	Lossless bool
+	// Metadata is embedded in PNG, JPEG and WebP output. Nil strips it.
+	Metadata *Metadata
+
+	// compact trades encoding speed for size; set while fitting a budget.
+	compact bool
+}
+
+// EncodeBytes encodes img with opts.
+func EncodeBytes(img *image.RGBA, opts EncodeOptions) ([]byte, error) {
+	var out bytes.Buffer
+	var err error
+
+	switch opts.Format {
 	case FormatJPEG:
-		ext = "jpg"
+		err = EncodeJPEG(&out, img, opts.Quality)
+		if err == nil && opts.Metadata != nil {
+			return embedJPEGComment(out.Bytes(), opts.Metadata), nil
+		}
 	case FormatPPM:
-		ext = "ppm"
+		err = EncodePPM(&out, img)
+	case FormatQOI:
+		err = EncodeQOI(&out, img)
+	case FormatWebP:
+		return encodeWebP(img, webpFallback(opts))
+	case FormatAVIF, FormatJXL:
+		return encodeExternal(img, opts)
+	default:
+		level := png.BestSpeed
+		if opts.compact {
+			level = png.BestCompression
+		}
+		err = (&png.Encoder{CompressionLevel: level}).Encode(&out, img)
+		if err == nil && opts.Metadata != nil {
+			return embedPNGText(out.Bytes(), opts.Metadata), nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// webpFallback switches lossy WebP to the built-in lossless encoder when
+// neither cwebp nor ffmpeg is installed, so the format always works.
+func webpFallback(opts EncodeOptions) EncodeOptions {
+	if opts.Format != FormatWebP || opts.Lossless {
+		return opts
+	}
+	if _, err := findExternalEncoder(FormatWebP); err != nil {
+		log.Debugf("%v, writing lossless webp", err)
+		opts.Lossless = true
+	}
+	return opts
+}
+
+func encodeWebP(img *image.RGBA, opts EncodeOptions) ([]byte, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() > vp8lMaxSize || bounds.Dy() > vp8lMaxSize {
+		return nil, fmt.Errorf("webp is limited to %dx%d pixels", vp8lMaxSize, vp8lMaxSize)
+	}
+
+	var bitstream []byte
+	if opts.Lossless {
+		bitstream = vp8lChunk(img)
+	} else {
+		data, err := encodeExternal(img, opts)
+		if err != nil {
+			return nil, err
+		}
+		if bitstream, err = webpBitstream(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var xmp []byte
+	if opts.Metadata != nil {
+		xmp = opts.Metadata.xmp()
+	}
+	return wrapWebP(bitstream, xmp, bounds.Dx(), bounds.Dy()), nil
+}
+
+// minFitQuality is the lowest quality tried before shrinking the image
+// instead; below it text stops being readable.
+const (
+	minFitQuality = 30
+	maxFitScales  = 6
+)
+
+// EncodeWithinSize encodes img in at most maxSize bytes. Lossy formats
+// search for the highest quality that fits; when even the lowest doesn't,
+// or the format is lossless, the image is scaled down until it does.
+func EncodeWithinSize(img *image.RGBA, opts EncodeOptions, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return EncodeBytes(img, opts)
+	}
+	opts = webpFallback(opts)
+	opts.compact = true
+
+	for range maxFitScales {
+		data, err := encodeBestQuality(img, opts, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) <= maxSize {
+			return data, nil
+		}
+
+		factor := min(0.9, 0.95*math.Sqrt(float64(maxSize)/float64(len(data))))
+		w := int(float64(img.Bounds().Dx()) * factor)
+		h := int(float64(img.Bounds().Dy()) * factor)
+		if w < 16 || h < 16 {
+			break
+		}
+		img = scaleImage(img, w, h)
+	}
+	return nil, fmt.Errorf("cannot fit the image in %s", FormatSize(maxSize))
+}
+
+// encodeBestQuality binary searches the quality for lossy formats and
+// returns the smallest attempt when nothing fits.
+func encodeBestQuality(img *image.RGBA, opts EncodeOptions, maxSize int64) ([]byte, error) {
+	data, err := EncodeBytes(img, opts)
+	if err != nil || int64(len(data)) <= maxSize || !opts.Format.Lossy() || opts.Lossless {
+		return data, err
+	}
+
+	smallest := data
+	lo, hi := minFitQuality, opts.Quality-1
+	var best []byte
+	for lo <= hi {
+		opts.Quality = (lo + hi) / 2
+		attempt, err := EncodeBytes(img, opts)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(attempt)) <= maxSize {
+			best = attempt
+			lo = opts.Quality + 1
+			continue
+		}
+		if len(attempt) < len(smallest) {
+			smallest = attempt
+		}
+		hi = opts.Quality - 1
+	}
+	if best != nil {
+		return best, nil
+	}
+	return smallest, nil
+}
+
+func scaleImage(img *image.RGBA, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// ParseSize parses byte sizes such as "800K", "8M" or "1.5MiB". Units are
+// binary, matching how upload limits are usually enforced.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+
+	num := strings.TrimRight(s, "KMGIB")
+	mult := int64(1)
+	switch strings.TrimSuffix(strings.TrimSuffix(s[len(num):], "B"), "I") {
+	case "":
+	case "K":
+		mult = 1 << 10
+	case "M":
+		mult = 1 << 20
+	case "G":
+		mult = 1 << 30
+	default:
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(v * float64(mult)), nil
+}
+
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
 	}
-	return fmt.Sprintf("screenshot_%s.%s", t.Format("2006-01-02_15-04-05"), ext)
 }
 
 func GetOutputDir() string {
@@ -122,19 +395,9 @@ func WriteToFile(buf *ShmBuffer, path string, format Format, quality int) error
 }
 
 func WriteToFileWithFormat(buf *ShmBuffer, path string, format Format, quality int, pixelFormat uint32) error {
-	f, err := os.Create(path)
+	data, err := EncodeBytes(BufferToImageWithFormat(buf, pixelFormat), EncodeOptions{Format: format, Quality: quality})
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	img := BufferToImageWithFormat(buf, pixelFormat)
-	switch format {
-	case FormatJPEG:
-		return EncodeJPEG(f, img, quality)
-	case FormatPPM:
-		return EncodePPM(f, img)
-	default:
-		return EncodePNG(f, img)
-	}
+	return os.WriteFile(path, data, 0o644)
 }
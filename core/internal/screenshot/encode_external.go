@@ -0,0 +1,135 @@
+package screenshot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// externalEncoder runs a locally installed encoder on a PNG. Lossy WebP,
+// AVIF and JPEG XL have no pure Go encoders worth shipping.
+type externalEncoder struct {
+	bin  string
+	args func(in, out string, quality int, lossless bool) []string
+}
+
+var externalEncoders = map[Format][]externalEncoder{
+	FormatWebP: {
+		{"cwebp", func(in, out string, q int, _ bool) []string {
+			return []string{"-quiet", "-metadata", "none", "-q", strconv.Itoa(q), in, "-o", out}
+		}},
+		{"ffmpeg", func(in, out string, q int, _ bool) []string {
+			return []string{"-hide_banner", "-loglevel", "error", "-y", "-i", in, "-c:v", "libwebp", "-quality", strconv.Itoa(q), "-f", "webp", out}
+		}},
+	},
+	FormatAVIF: {
+		{"avifenc", func(in, out string, q int, lossless bool) []string {
+			if lossless {
+				return []string{"--lossless", in, out}
+			}
+			return []string{"-q", strconv.Itoa(q), in, out}
+		}},
+	},
+	FormatJXL: {
+		{"cjxl", func(in, out string, q int, lossless bool) []string {
+			if lossless {
+				q = 100
+			}
+			return []string{in, out, "-q", strconv.Itoa(q)}
+		}},
+	},
+}
+
+func findExternalEncoder(format Format) (externalEncoder, error) {
+	candidates := externalEncoders[format]
+	names := make([]string, 0, len(candidates))
+	for _, enc := range candidates {
+		if _, err := exec.LookPath(enc.bin); err == nil {
+			return enc, nil
+		}
+		names = append(names, enc.bin)
+	}
+	return externalEncoder{}, fmt.Errorf("%s output requires %s", format, strings.Join(names, " or "))
+}
+
+func encodeExternal(img *image.RGBA, opts EncodeOptions) ([]byte, error) {
+	enc, err := findExternalEncoder(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "dms-encode-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.png")
+	out := filepath.Join(dir, "out."+opts.Format.Extension())
+
+	f, err := os.Create(in)
+	if err != nil {
+		return nil, err
+	}
+	err = EncodePNG(f, img)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(enc.bin, enc.args(in, out, opts.Quality, opts.Lossless)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", enc.bin, err, strings.TrimSpace(stderr.String()))
+	}
+	return os.ReadFile(out)
+}
+
+// externalDecoders convert to PNG, for formats the image package can't read.
+var externalDecoders = map[Format]string{
+	FormatJXL:  "djxl",
+	FormatAVIF: "avifdec",
+}
+
+func decodeExternal(data []byte, format Format) (image.Image, error) {
+	bin, ok := externalDecoders[format]
+	if !ok {
+		return nil, fmt.Errorf("no decoder for %s", format)
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return nil, fmt.Errorf("reading %s requires %s", format, bin)
+	}
+
+	dir, err := os.MkdirTemp("", "dms-decode-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in."+format.Extension())
+	out := filepath.Join(dir, "out.png")
+	if err := os.WriteFile(in, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(bin, in, out)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", bin, err, strings.TrimSpace(stderr.String()))
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
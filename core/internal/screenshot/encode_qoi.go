@@ -0,0 +1,160 @@
+package screenshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	qoiOpIndex = 0x00
+	qoiOpDiff  = 0x40
+	qoiOpLuma  = 0x80
+	qoiOpRun   = 0xc0
+	qoiOpRGB   = 0xfe
+	qoiOpRGBA  = 0xff
+)
+
+// EncodeQOI writes img as a Quite OK Image. Screenshots are opaque, so
+// the encoder only ever emits RGB chunks and declares 3 channels.
+func EncodeQOI(w io.Writer, img *image.RGBA) error {
+	bw := bufio.NewWriter(w)
+	bounds := img.Bounds()
+
+	header := make([]byte, 14)
+	copy(header, "qoif")
+	binary.BigEndian.PutUint32(header[4:], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(header[8:], uint32(bounds.Dy()))
+	header[12] = 3 // channels
+	header[13] = 0 // sRGB with linear alpha
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	var index [64][4]byte
+	prev := [4]byte{0, 0, 0, 255}
+	run := 0
+
+	flushRun := func() {
+		if run > 0 {
+			bw.WriteByte(qoiOpRun | byte(run-1))
+			run = 0
+		}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := img.Pix[(y-bounds.Min.Y)*img.Stride:]
+		for x := 0; x < bounds.Dx(); x++ {
+			px := [4]byte{row[x*4], row[x*4+1], row[x*4+2], 255}
+
+			if px == prev {
+				run++
+				if run == 62 {
+					flushRun()
+				}
+				continue
+			}
+			flushRun()
+
+			hash := (int(px[0])*3 + int(px[1])*5 + int(px[2])*7 + int(px[3])*11) % 64
+			if index[hash] == px {
+				bw.WriteByte(qoiOpIndex | byte(hash))
+				prev = px
+				continue
+			}
+			index[hash] = px
+
+			dr := int8(px[0] - prev[0])
+			dg := int8(px[1] - prev[1])
+			db := int8(px[2] - prev[2])
+			drg := dr - dg
+			dbg := db - dg
+
+			switch {
+			case dr >= -2 && dr <= 1 && dg >= -2 && dg <= 1 && db >= -2 && db <= 1:
+				bw.WriteByte(qoiOpDiff | byte(dr+2)<<4 | byte(dg+2)<<2 | byte(db+2))
+			case dg >= -32 && dg <= 31 && drg >= -8 && drg <= 7 && dbg >= -8 && dbg <= 7:
+				bw.WriteByte(qoiOpLuma | byte(dg+32))
+				bw.WriteByte(byte(drg+8)<<4 | byte(dbg+8))
+			default:
+				bw.Write([]byte{qoiOpRGB, px[0], px[1], px[2]})
+			}
+			prev = px
+		}
+	}
+	flushRun()
+
+	if _, err := bw.Write([]byte{0, 0, 0, 0, 0, 0, 0, 1}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// decodeQOI reads a Quite OK Image with 3 or 4 channels.
+func decodeQOI(data []byte) (*image.RGBA, error) {
+	if len(data) < 14+8 || !bytes.HasPrefix(data, []byte("qoif")) {
+		return nil, fmt.Errorf("not a qoi image")
+	}
+	w := int(binary.BigEndian.Uint32(data[4:]))
+	h := int(binary.BigEndian.Uint32(data[8:]))
+	if w <= 0 || h <= 0 || w*h > 1<<28 {
+		return nil, fmt.Errorf("invalid qoi size %dx%d", w, h)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var index [64][4]byte
+	px := [4]byte{0, 0, 0, 255}
+	pos := 14
+	end := len(data) - 8
+	run := 0
+
+	for off := 0; off < len(img.Pix); off += 4 {
+		switch {
+		case run > 0:
+			run--
+		case pos >= end:
+			return nil, fmt.Errorf("truncated qoi image")
+		default:
+			b := data[pos]
+			pos++
+			switch {
+			case b == qoiOpRGB:
+				if pos+3 > end {
+					return nil, fmt.Errorf("truncated qoi image")
+				}
+				copy(px[:3], data[pos:pos+3])
+				pos += 3
+			case b == qoiOpRGBA:
+				if pos+4 > end {
+					return nil, fmt.Errorf("truncated qoi image")
+				}
+				copy(px[:], data[pos:pos+4])
+				pos += 4
+			case b&0xc0 == qoiOpIndex:
+				px = index[b]
+			case b&0xc0 == qoiOpDiff:
+				px[0] += (b>>4)&3 - 2
+				px[1] += (b>>2)&3 - 2
+				px[2] += b&3 - 2
+			case b&0xc0 == qoiOpLuma:
+				if pos >= end {
+					return nil, fmt.Errorf("truncated qoi image")
+				}
+				dg := b&0x3f - 32
+				b2 := data[pos]
+				pos++
+				px[0] += dg + b2>>4 - 8
+				px[1] += dg
+				px[2] += dg + b2&0x0f - 8
+			default:
+				run = int(b & 0x3f)
+			}
+			index[(int(px[0])*3+int(px[1])*5+int(px[2])*7+int(px[3])*11)%64] = px
+		}
+		copy(img.Pix[off:off+4], px[:])
+	}
+	return img, nil
+}
@@ -0,0 +1,228 @@
+package screenshot
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+// testScreen looks enough like a screenshot to exercise every encoder
+// path: flat areas, repeated rows, gradients and some noise.
+func testScreen(w, h int) *image.RGBA {
+	img := solidFrame(w, h, white)
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			switch {
+			case y < h/4:
+				img.Pix[i], img.Pix[i+1], img.Pix[i+2] = 30, 30, 46
+			case x < w/3:
+				img.Pix[i], img.Pix[i+1], img.Pix[i+2] = byte(x*255/w), byte(y*255/h), 128
+			case x > 2*w/3:
+				img.Pix[i], img.Pix[i+1], img.Pix[i+2] = byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256))
+			}
+		}
+	}
+	fillRect(img, image.Rect(w/3+4, h/2, w/3+20, h/2+6), black)
+	return img
+}
+
+func assertSamePixels(t *testing.T, want *image.RGBA, got image.Image) {
+	t.Helper()
+	require.Equal(t, want.Bounds(), got.Bounds())
+	for y := 0; y < want.Bounds().Dy(); y++ {
+		for x := 0; x < want.Bounds().Dx(); x++ {
+			r, g, b, _ := got.At(x, y).RGBA()
+			c := want.RGBAAt(x, y)
+			if byte(r>>8) != c.R || byte(g>>8) != c.G || byte(b>>8) != c.B {
+				t.Fatalf("pixel %d,%d: got %d,%d,%d want %v", x, y, r>>8, g>>8, b>>8, c)
+			}
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("JPG")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, f)
+	assert.Equal(t, "jpg", f.Extension())
+	assert.Equal(t, "image/jpeg", f.MimeType())
+
+	f, err = ParseFormat("webp")
+	require.NoError(t, err)
+	assert.True(t, f.Lossy())
+
+	_, err = ParseFormat("bmp")
+	assert.Error(t, err)
+}
+
+func TestEncodeWebPLossless(t *testing.T) {
+	for _, size := range []image.Point{{1, 1}, {3, 2}, {97, 61}} {
+		img := testScreen(size.X, size.Y)
+		data, err := EncodeBytes(img, EncodeOptions{Format: FormatWebP, Lossless: true})
+		require.NoError(t, err)
+
+		decoded, err := webp.Decode(bytes.NewReader(data))
+		require.NoError(t, err, "size %v", size)
+		assertSamePixels(t, img, decoded)
+	}
+}
+
+func TestEncodeWebPWithoutEncoder(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	img := testScreen(40, 30)
+	data, err := EncodeBytes(img, EncodeOptions{Format: FormatWebP, Quality: 80})
+	require.NoError(t, err)
+
+	decoded, err := webp.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assertSamePixels(t, img, decoded)
+}
+
+func TestEncodeWebPMetadata(t *testing.T) {
+	img := testScreen(16, 16)
+	md := &Metadata{Time: time.Unix(0, 0).UTC(), Output: "DP-1", Mode: ModeOutput}
+	data, err := EncodeBytes(img, EncodeOptions{Format: FormatWebP, Lossless: true, Metadata: md})
+	require.NoError(t, err)
+
+	assert.Equal(t, "VP8X", string(data[12:16]))
+	assert.Contains(t, string(data), "XMP ")
+	assert.Contains(t, string(data), "output DP-1")
+
+	decoded, err := webp.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assertSamePixels(t, img, decoded)
+}
+
+func TestEncodeQOI(t *testing.T) {
+	img := testScreen(80, 50)
+	var buf bytes.Buffer
+	require.NoError(t, EncodeQOI(&buf, img))
+
+	decoded, err := DecodeBytes(buf.Bytes(), FormatQOI)
+	require.NoError(t, err)
+	assertSamePixels(t, img, decoded)
+	assert.Less(t, buf.Len(), len(img.Pix))
+
+	_, err = DecodeBytes(buf.Bytes()[:buf.Len()/2], FormatQOI)
+	assert.Error(t, err)
+}
+
+func TestDecodePPM(t *testing.T) {
+	img := testScreen(40, 30)
+	var buf bytes.Buffer
+	require.NoError(t, EncodePPM(&buf, img))
+
+	decoded, err := DecodeBytes(buf.Bytes(), FormatPPM)
+	require.NoError(t, err)
+	assertSamePixels(t, img, decoded)
+
+	_, err = DecodeBytes(buf.Bytes()[:buf.Len()-1], FormatPPM)
+	assert.Error(t, err)
+}
+
+func TestEncodeWithinSize(t *testing.T) {
+	img := testScreen(400, 300)
+	opts := EncodeOptions{Format: FormatJPEG, Quality: 95}
+
+	full, err := EncodeBytes(img, opts)
+	require.NoError(t, err)
+
+	budget := int64(len(full)) / 2
+	data, err := EncodeWithinSize(img, opts, budget)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, int64(len(data)), budget)
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+
+	// PNG can only shrink by scaling.
+	data, err = EncodeWithinSize(img, EncodeOptions{Format: FormatPNG}, 20<<10)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 20<<10)
+	decoded, err = png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Less(t, decoded.Bounds().Dx(), 400)
+
+	_, err = EncodeWithinSize(img, opts, 100)
+	assert.Error(t, err)
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{
+		"":       0,
+		"512":    512,
+		"800K":   800 << 10,
+		"8m":     8 << 20,
+		"1.5MiB": 3 << 19,
+		"2GB":    2 << 30,
+	} {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"K", "8X", "-1M"} {
+		_, err := ParseSize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestEmbedPNGText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodePNG(&buf, testScreen(8, 8)))
+
+	md := &Metadata{
+		Time:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Output: "eDP-1",
+		Mode:   ModeRegion,
+		Region: Region{X: 10, Y: 20, Width: 8, Height: 8},
+	}
+	data := embedPNGText(buf.Bytes(), md)
+
+	_, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err, "chunks must keep the file valid")
+	assert.Contains(t, string(data), "tEXtOutput\x00eDP-1")
+	assert.Contains(t, string(data), "tEXtRegion\x008x8+10+20")
+	assert.NotContains(t, buf.String(), "tEXt")
+}
+
+func TestEmbedJPEGComment(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, testScreen(8, 8), 90))
+
+	md := &Metadata{Time: time.Unix(0, 0), Mode: ModeFullScreen}
+	data := embedJPEGComment(buf.Bytes(), md)
+
+	_, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xd8, 0xff, 0xfe}, data[:4])
+	assert.Contains(t, string(data), "Capture Mode: full")
+}
+
+func TestExpandFilename(t *testing.T) {
+	info := FilenameInfo{
+		Time:   time.Date(2024, 3, 9, 7, 5, 2, 0, time.Local),
+		Output: "HDMI-A/1",
+		Mode:   ModeOutput,
+		Width:  1920,
+		Height: 1080,
+	}
+
+	assert.Equal(t, "screenshot_2024-03-09_07-05-02.png",
+		ExpandFilename(DefaultFilenameTemplate, FormatPNG, info))
+	assert.Equal(t, "2024-03-09/HDMI-A-1_output_1920x1080.jpg",
+		ExpandFilename("%F/{output}_{mode}_{w}x{h}", FormatJPEG, info))
+	assert.Equal(t, "shot.webp", ExpandFilename("shot.webp", FormatWebP, info))
+	assert.Equal(t, "100%_%q.qoi", ExpandFilename("100%%_%q", FormatQOI, info))
+}
@@ -0,0 +1,529 @@
+package screenshot
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+)
+
+// Native lossless WebP (VP8L). It uses the subtract-green transform and
+// LZ77 with the "pixel above" and "pixel to the left" short distance codes,
+// which is where flat UI screenshots get almost all of their savings.
+
+const (
+	vp8lMaxSize       = 1 << 14
+	vp8lLengthCodes   = 24
+	vp8lDistanceCodes = 40
+	vp8lMinMatch      = 3
+	vp8lMaxMatch      = 4096
+	vp8lMaxDistance   = 1<<20 - 120
+	vp8lMaxCodeLength = 15
+	vp8lHashBits      = 16
+	vp8lChainDepth    = 8
+)
+
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type vp8lWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *vp8lWriter) write(v uint32, bits uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += bits
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *vp8lWriter) flush() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
+
+// vp8lToken is a literal pixel when length is 0, otherwise a backward
+// reference using a VP8L distance code.
+type vp8lToken struct {
+	argb   uint32
+	length int
+	dist   int
+}
+
+// EncodeWebPLossless writes img as a lossless WebP.
+func EncodeWebPLossless(w io.Writer, img *image.RGBA) error {
+	if img.Bounds().Dx() > vp8lMaxSize || img.Bounds().Dy() > vp8lMaxSize {
+		return fmt.Errorf("webp is limited to %dx%d pixels", vp8lMaxSize, vp8lMaxSize)
+	}
+	_, err := w.Write(wrapWebP(vp8lChunk(img), nil, 0, 0))
+	return err
+}
+
+func vp8lChunk(img *image.RGBA) []byte {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+
+	pixels := make([]uint32, 0, width*height)
+	for y := range height {
+		row := img.Pix[y*img.Stride:]
+		for x := range width {
+			r, g, b := row[x*4], row[x*4+1], row[x*4+2]
+			// Subtract-green transform.
+			pixels = append(pixels, 0xff000000|uint32(r-g)<<16|uint32(g)<<8|uint32(b-g))
+		}
+	}
+	tokens := vp8lBackwardRefs(pixels, width)
+
+	var (
+		green = make([]int, 256+vp8lLengthCodes)
+		red   = make([]int, 256)
+		blue  = make([]int, 256)
+		alpha = make([]int, 256)
+		dist  = make([]int, vp8lDistanceCodes)
+	)
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[t.argb>>8&0xff]++
+			red[t.argb>>16&0xff]++
+			blue[t.argb&0xff]++
+			alpha[t.argb>>24]++
+			continue
+		}
+		lp, _, _ := vp8lPrefix(t.length)
+		dp, _, _ := vp8lPrefix(t.dist)
+		green[256+lp]++
+		dist[dp]++
+	}
+
+	bw := &vp8lWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(0, 1) // alpha_is_used
+	bw.write(0, 3) // version
+
+	bw.write(1, 1) // transform present
+	bw.write(2, 2) // subtract green
+	bw.write(0, 1) // no more transforms
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // no meta prefix codes
+
+	codes := make([]vp8lCode, 5)
+	for i, freq := range [][]int{green, red, blue, alpha, dist} {
+		codes[i] = writePrefixCode(bw, freq)
+	}
+	greenCode, redCode, blueCode, alphaCode, distCode := codes[0], codes[1], codes[2], codes[3], codes[4]
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			greenCode.put(bw, int(t.argb>>8&0xff))
+			redCode.put(bw, int(t.argb>>16&0xff))
+			blueCode.put(bw, int(t.argb&0xff))
+			alphaCode.put(bw, int(t.argb>>24))
+			continue
+		}
+		lp, lbits, lextra := vp8lPrefix(t.length)
+		greenCode.put(bw, 256+lp)
+		bw.write(lextra, lbits)
+		dp, dbits, dextra := vp8lPrefix(t.dist)
+		distCode.put(bw, dp)
+		bw.write(dextra, dbits)
+	}
+
+	return bw.flush()
+}
+
+// vp8lBackwardRefs greedily finds matches through a hash chain, always
+// also trying the previous pixel and the pixel above since both have
+// dedicated one-symbol distance codes.
+func vp8lBackwardRefs(pixels []uint32, width int) []vp8lToken {
+	n := len(pixels)
+	head := make([]int32, 1<<vp8lHashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	chain := make([]int32, n)
+
+	hashAt := func(i int) uint32 {
+		return (pixels[i]*0x1e35a7bd ^ pixels[i+1]*0x9e3779b1) >> (32 - vp8lHashBits)
+	}
+	insert := func(i int) {
+		if i+1 >= n {
+			return
+		}
+		h := hashAt(i)
+		chain[i] = head[h]
+		head[h] = int32(i)
+	}
+	matchLen := func(i, cand int) int {
+		limit := min(vp8lMaxMatch, n-i)
+		l := 0
+		for l < limit && pixels[cand+l] == pixels[i+l] {
+			l++
+		}
+		return l
+	}
+
+	tokens := make([]vp8lToken, 0, n/4)
+	for i := 0; i < n; {
+		bestLen, bestDist := 0, 0
+		try := func(cand int) {
+			d := i - cand
+			if cand < 0 || d <= 0 || d > vp8lMaxDistance {
+				return
+			}
+			if l := matchLen(i, cand); l > bestLen {
+				bestLen, bestDist = l, d
+			}
+		}
+
+		try(i - 1)
+		try(i - width)
+		if i+1 < n && bestLen < vp8lMaxMatch {
+			cand := head[hashAt(i)]
+			for depth := 0; cand >= 0 && depth < vp8lChainDepth; depth++ {
+				try(int(cand))
+				cand = chain[cand]
+			}
+		}
+
+		if bestLen < vp8lMinMatch {
+			tokens = append(tokens, vp8lToken{argb: pixels[i]})
+			insert(i)
+			i++
+			continue
+		}
+
+		tokens = append(tokens, vp8lToken{length: bestLen, dist: vp8lDistanceCode(bestDist, width)})
+		for j := i; j < i+bestLen; j++ {
+			insert(j)
+		}
+		i += bestLen
+	}
+	return tokens
+}
+
+// vp8lDistanceCode maps a linear distance to its code. Codes 1 and 2 are
+// the pixel above and the pixel to the left; everything else is offset
+// past the 120 two-dimensional neighbourhood codes.
+func vp8lDistanceCode(dist, width int) int {
+	switch dist {
+	case width:
+		return 1
+	case 1:
+		return 2
+	}
+	return dist + 120
+}
+
+// vp8lPrefix splits a length or distance code into its prefix symbol and
+// extra bits.
+func vp8lPrefix(v int) (prefix int, bits uint, extra uint32) {
+	if v <= 4 {
+		return v - 1, 0, 0
+	}
+	d := v - 1
+	high := 0
+	for d>>(high+1) != 0 {
+		high++
+	}
+	second := (d >> (high - 1)) & 1
+	bits = uint(high - 1)
+	return 2*high + second, bits, uint32(d & (1<<bits - 1))
+}
+
+type vp8lCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c vp8lCode) put(w *vp8lWriter, sym int) {
+	w.write(c.codes[sym], uint(c.lengths[sym]))
+}
+
+// writePrefixCode picks the simple form for one or two small symbols and a
+// normal, length-limited Huffman code otherwise.
+func writePrefixCode(w *vp8lWriter, freq []int) vp8lCode {
+	var used []int
+	for sym, f := range freq {
+		if f > 0 {
+			used = append(used, sym)
+		}
+	}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		lengths := make([]uint8, len(freq))
+		w.write(1, 1) // simple code
+		switch len(used) {
+		case 0:
+			w.write(0, 1)
+			w.write(0, 1)
+			w.write(0, 1)
+		case 1:
+			// A lone symbol takes no bits at all.
+			w.write(0, 1)
+			writeSimpleSymbol(w, used[0])
+		default:
+			w.write(1, 1)
+			writeSimpleSymbol(w, used[0])
+			w.write(uint32(used[1]), 8)
+			lengths[used[0]], lengths[used[1]] = 1, 1
+		}
+		return vp8lCode{lengths: lengths, codes: canonicalCodes(lengths)}
+	}
+
+	if len(used) == 1 {
+		// Only a length code is in use; give it a partner so the code is a
+		// proper one-bit tree.
+		freq = append([]int(nil), freq...)
+		freq[0] = 1
+	}
+
+	lengths := huffmanLengths(freq, vp8lMaxCodeLength)
+	w.write(0, 1) // normal code
+
+	type clToken struct {
+		sym   int
+		extra uint32
+	}
+	var tokens []clToken
+	clFreq := make([]int, 19)
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		switch {
+		case l == 0 && run >= 11:
+			run = min(run, 138)
+			tokens = append(tokens, clToken{18, uint32(run - 11)})
+		case l == 0 && run >= 3:
+			run = min(run, 10)
+			tokens = append(tokens, clToken{17, uint32(run - 3)})
+		default:
+			run = 1
+			tokens = append(tokens, clToken{int(l), 0})
+		}
+		clFreq[tokens[len(tokens)-1].sym]++
+		i += run
+	}
+
+	clUsed := 0
+	for _, f := range clFreq {
+		if f > 0 {
+			clUsed++
+		}
+	}
+	if clUsed < 2 {
+		for sym := range clFreq {
+			if clFreq[sym] == 0 {
+				clFreq[sym] = 1
+				break
+			}
+		}
+	}
+	clLengths := huffmanLengths(clFreq, 7)
+	clCodes := canonicalCodes(clLengths)
+
+	count := 4
+	for i, sym := range vp8lCodeLengthOrder {
+		if clLengths[sym] != 0 {
+			count = max(count, i+1)
+		}
+	}
+	w.write(uint32(count-4), 4)
+	for _, sym := range vp8lCodeLengthOrder[:count] {
+		w.write(uint32(clLengths[sym]), 3)
+	}
+	w.write(0, 1) // code lengths cover the whole alphabet
+
+	for _, t := range tokens {
+		w.write(clCodes[t.sym], uint(clLengths[t.sym]))
+		switch t.sym {
+		case 17:
+			w.write(t.extra, 3)
+		case 18:
+			w.write(t.extra, 7)
+		}
+	}
+
+	return vp8lCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+func writeSimpleSymbol(w *vp8lWriter, sym int) {
+	if sym < 2 {
+		w.write(0, 1)
+		w.write(uint32(sym), 1)
+		return
+	}
+	w.write(1, 1)
+	w.write(uint32(sym), 8)
+}
+
+// huffmanLengths builds code lengths no longer than maxLen, flattening the
+// frequencies until the tree fits.
+func huffmanLengths(freq []int, maxLen int) []uint8 {
+	freq = append([]int(nil), freq...)
+	for {
+		lengths, depth := huffmanTree(freq)
+		if depth <= maxLen {
+			return lengths
+		}
+		for i, f := range freq {
+			if f > 0 {
+				freq[i] = (f + 1) / 2
+			}
+		}
+	}
+}
+
+func huffmanTree(freq []int) ([]uint8, int) {
+	type node struct {
+		weight      int
+		sym         int
+		left, right int
+	}
+	nodes := make([]node, 0, 2*len(freq))
+	var queue []int
+	for sym, f := range freq {
+		if f > 0 {
+			nodes = append(nodes, node{weight: f, sym: sym, left: -1, right: -1})
+			queue = append(queue, len(nodes)-1)
+		}
+	}
+
+	lengths := make([]uint8, len(freq))
+	if len(queue) == 1 {
+		lengths[nodes[0].sym] = 1
+		return lengths, 1
+	}
+
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(a, b int) bool {
+			return nodes[queue[a]].weight < nodes[queue[b]].weight
+		})
+		a, b := queue[0], queue[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, sym: -1, left: a, right: b})
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	depth := 0
+	var walk func(i, d int)
+	walk = func(i, d int) {
+		if nodes[i].sym >= 0 {
+			lengths[nodes[i].sym] = uint8(d)
+			depth = max(depth, d)
+			return
+		}
+		walk(nodes[i].left, d+1)
+		walk(nodes[i].right, d+1)
+	}
+	if len(queue) == 1 {
+		walk(queue[0], 0)
+	}
+	return lengths, depth
+}
+
+// canonicalCodes assigns canonical Huffman codes, bit-reversed because
+// VP8L reads codes MSB first out of an LSB-first stream.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [vp8lMaxCodeLength + 1]int
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+
+	var next [vp8lMaxCodeLength + 2]uint32
+	code := uint32(0)
+	for l := 1; l <= vp8lMaxCodeLength; l++ {
+		code = (code + uint32(count[l-1])) << 1
+		next[l] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for sym, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		var rev uint32
+		for range l {
+			rev = rev<<1 | c&1
+			c >>= 1
+		}
+		codes[sym] = rev
+	}
+	return codes
+}
+
+// wrapWebP puts a VP8L or VP8 bitstream into a RIFF container. With xmp
+// set it uses the extended layout so the metadata chunk is allowed.
+func wrapWebP(bitstream []byte, xmp []byte, width, height int) []byte {
+	chunk := func(fourcc string, data []byte) []byte {
+		out := make([]byte, 8, 8+len(data)+1)
+		copy(out, fourcc)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(data)))
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	fourcc := "VP8L"
+	if len(bitstream) > 0 && bitstream[0] != 0x2f {
+		fourcc = "VP8 "
+	}
+
+	var body []byte
+	if len(xmp) > 0 {
+		vp8x := make([]byte, 10)
+		vp8x[0] = 0x04 // XMP present
+		putUint24(vp8x[4:], uint32(width-1))
+		putUint24(vp8x[7:], uint32(height-1))
+		body = append(body, chunk("VP8X", vp8x)...)
+		body = append(body, chunk(fourcc, bitstream)...)
+		body = append(body, chunk("XMP ", xmp)...)
+	} else {
+		body = chunk(fourcc, bitstream)
+	}
+
+	out := make([]byte, 12, 12+len(body))
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(4+len(body)))
+	copy(out[8:], "WEBP")
+	return append(out, body...)
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// webpBitstream extracts the VP8 or VP8L payload from a simple-format
+// WebP file, as produced by external encoders.
+func webpBitstream(data []byte) ([]byte, error) {
+	if len(data) < 20 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("not a WebP file")
+	}
+	for off := 12; off+8 <= len(data); {
+		fourcc := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4:]))
+		if off+8+size > len(data) {
+			break
+		}
+		if fourcc == "VP8 " || fourcc == "VP8L" {
+			return data[off+8 : off+8+size], nil
+		}
+		off += 8 + size + size%2
+	}
+	return nil, fmt.Errorf("no image data in WebP file")
+}
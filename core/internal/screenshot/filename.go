@@ -0,0 +1,108 @@
+package screenshot
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultFilenameTemplate = "screenshot_%Y-%m-%d_%H-%M-%S"
+
+type FilenameInfo struct {
+	Time   time.Time
+	Output string
+	Mode   Mode
+	Width  int
+	Height int
+	// Index is the 1-based position in a burst.
+	Index int
+}
+
+// FilenameTemplate returns the template from DMS_SCREENSHOT_TEMPLATE, or
+// the default.
+func FilenameTemplate() string {
+	if tmpl := os.Getenv("DMS_SCREENSHOT_TEMPLATE"); tmpl != "" {
+		return tmpl
+	}
+	return DefaultFilenameTemplate
+}
+
+// ExpandFilename fills in strftime conversions and the {output}, {mode},
+// {w}, {h} and {n} placeholders, then appends the format's extension. The
+// template may contain directories.
+func ExpandFilename(template string, format Format, info FilenameInfo) string {
+	replacer := strings.NewReplacer(
+		"{output}", sanitizeFilenamePart(info.Output),
+		"{mode}", info.Mode.String(),
+		"{w}", strconv.Itoa(info.Width),
+		"{h}", strconv.Itoa(info.Height),
+		"{n}", strconv.Itoa(max(info.Index, 1)),
+	)
+	name := strftime(replacer.Replace(template), info.Time)
+
+	ext := "." + format.Extension()
+	if !strings.HasSuffix(strings.ToLower(name), ext) {
+		name += ext
+	}
+	return name
+}
+
+func sanitizeFilenamePart(s string) string {
+	return strings.NewReplacer("/", "-", "\x00", "").Replace(s)
+}
+
+func strftime(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			b.WriteString(t.Format("2006"))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'I':
+			b.WriteString(t.Format("03"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'j':
+			b.WriteString(strconv.Itoa(t.YearDay()))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'F':
+			b.WriteString(t.Format("2006-01-02"))
+		case 'T':
+			b.WriteString(t.Format("15:04:05"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
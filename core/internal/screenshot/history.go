@@ -0,0 +1,285 @@
+package screenshot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+const historyThumbSize = 256
+
+// HistoryEntry is a saved screenshot. Entries only point at files; the
+// screenshot itself stays wherever it was saved.
+type HistoryEntry struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Time      time.Time `json:"time"`
+	Mode      string    `json:"mode"`
+	Output    string    `json:"output,omitempty"`
+	Region    Region    `json:"region"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Format    string    `json:"format"`
+	Size      int       `json:"size"`
+}
+
+// HistoryPolicy bounds the history. Zero values mean no limit. With
+// DeleteFiles, pruning also removes the screenshots, not just the entries.
+type HistoryPolicy struct {
+	MaxEntries  int  `json:"maxEntries"`
+	MaxAgeDays  int  `json:"maxAgeDays"`
+	DeleteFiles bool `json:"deleteFiles"`
+}
+
+var DefaultHistoryPolicy = HistoryPolicy{MaxEntries: 200}
+
+// History is the on-disk index, newest entry first.
+type History struct {
+	Policy  HistoryPolicy  `json:"policy"`
+	Entries []HistoryEntry `json:"entries"`
+}
+
+func historyDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = path.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheDir, "dms")
+}
+
+func getHistoryFilePath() string {
+	return filepath.Join(historyDir(), "screenshot-history.json")
+}
+
+func getThumbnailDir() string {
+	return filepath.Join(historyDir(), "screenshot-thumbnails")
+}
+
+// updateHistory runs fn on the loaded history under an exclusive lock and
+// saves the result. The CLI and the server both write the file.
+func updateHistory(fn func(h *History) error) error {
+	return utils.UpdateJSONState(getHistoryFilePath(), History{Policy: DefaultHistoryPolicy}, fn)
+}
+
+func LoadHistory() (*History, error) {
+	return utils.LoadJSONState(getHistoryFilePath(), History{Policy: DefaultHistoryPolicy})
+}
+
+// ListHistory returns up to limit entries, newest first, skipping ones
+// whose file has been deleted or moved. A limit of 0 returns everything.
+func ListHistory(limit int) ([]HistoryEntry, error) {
+	h, err := LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]HistoryEntry, 0, len(h.Entries))
+	for _, e := range h.Entries {
+		if _, err := os.Stat(e.Path); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func GetHistoryEntry(id string) (*HistoryEntry, error) {
+	h, err := LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range h.Entries {
+		if e.ID == id {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("screenshot %q not found in history", id)
+}
+
+// AddHistory records a saved shot with a thumbnail and applies the
+// pruning policy.
+func AddHistory(shot Shot, mode Mode, output string, img *image.RGBA) (*HistoryEntry, error) {
+	entry := HistoryEntry{
+		ID:     strconv.FormatInt(shot.Time.UnixNano(), 36),
+		Path:   shot.Path,
+		Time:   shot.Time,
+		Mode:   mode.String(),
+		Output: output,
+		Region: shot.Region,
+		Width:  shot.Width,
+		Height: shot.Height,
+		Format: shot.Format,
+		Size:   shot.Size,
+	}
+
+	if img != nil {
+		thumb, err := writeThumbnail(entry.ID, img)
+		if err != nil {
+			return nil, fmt.Errorf("thumbnail: %w", err)
+		}
+		entry.Thumbnail = thumb
+	}
+
+	err := updateHistory(func(h *History) error {
+		h.Entries = append([]HistoryEntry{entry}, h.Entries...)
+		pruneHistory(h, time.Now())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func writeThumbnail(id string, img *image.RGBA) (string, error) {
+	if err := os.MkdirAll(getThumbnailDir(), 0o755); err != nil {
+		return "", err
+	}
+
+	b := img.Bounds()
+	scale := min(1, float64(historyThumbSize)/float64(max(b.Dx(), b.Dy())))
+	thumb := img
+	if scale < 1 {
+		thumb = scaleImage(img, max(int(float64(b.Dx())*scale), 1), max(int(float64(b.Dy())*scale), 1))
+	}
+
+	path := filepath.Join(getThumbnailDir(), id+".png")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := EncodePNG(f, thumb); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// DeleteHistory removes an entry and its thumbnail, and the screenshot
+// itself when deleteFile is set.
+func DeleteHistory(id string, deleteFile bool) error {
+	return updateHistory(func(h *History) error {
+		for i, e := range h.Entries {
+			if e.ID != id {
+				continue
+			}
+			removeEntryFiles(e, deleteFile)
+			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+			return nil
+		}
+		return fmt.Errorf("screenshot %q not found in history", id)
+	})
+}
+
+// PruneHistory applies the stored policy and drops entries whose file is
+// gone. It returns how many entries were removed.
+func PruneHistory() (int, error) {
+	removed := 0
+	err := updateHistory(func(h *History) error {
+		removed = pruneHistory(h, time.Now())
+		return nil
+	})
+	return removed, err
+}
+
+// SetHistoryPolicy stores policy and prunes with it right away.
+func SetHistoryPolicy(policy HistoryPolicy) (int, error) {
+	removed := 0
+	err := updateHistory(func(h *History) error {
+		h.Policy = policy
+		removed = pruneHistory(h, time.Now())
+		return nil
+	})
+	return removed, err
+}
+
+func pruneHistory(h *History, now time.Time) int {
+	policy := h.Policy
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+	kept := h.Entries[:0]
+	removed := 0
+	for _, e := range h.Entries {
+		_, statErr := os.Stat(e.Path)
+		switch {
+		case statErr != nil:
+			removeEntryFiles(e, false)
+		case maxAge > 0 && now.Sub(e.Time) > maxAge,
+			policy.MaxEntries > 0 && len(kept) >= policy.MaxEntries:
+			removeEntryFiles(e, policy.DeleteFiles)
+		default:
+			kept = append(kept, e)
+			continue
+		}
+		removed++
+	}
+	h.Entries = kept
+	return removed
+}
+
+func removeEntryFiles(e HistoryEntry, deleteFile bool) {
+	if e.Thumbnail != "" {
+		os.Remove(e.Thumbnail)
+	}
+	if deleteFile {
+		os.Remove(e.Path)
+	}
+}
+
+// CopyHistory puts a saved screenshot back on the clipboard.
+func CopyHistory(id string) error {
+	entry, err := GetHistoryEntry(id)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return err
+	}
+
+	format, err := ParseFormat(entry.Format)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatPNG, FormatJPEG, FormatWebP, FormatAVIF:
+		return clipboard.Copy(data, format.MimeType())
+	}
+
+	// Clipboard consumers don't take QOI, PPM or JPEG XL; offer PNG as
+	// copyToClipboard does for fresh shots.
+	img, err := DecodeBytes(data, format)
+	if err != nil {
+		return err
+	}
+	var png bytes.Buffer
+	if err := EncodePNG(&png, img); err != nil {
+		return err
+	}
+	return clipboard.Copy(png.Bytes(), "image/png")
+}
+
+// OpenHistory opens a saved screenshot in the default image viewer.
+func OpenHistory(id string) error {
+	entry, err := GetHistoryEntry(id)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return err
+	}
+	openFile(entry.Path)
+	return nil
+}
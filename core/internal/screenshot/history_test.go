@@ -0,0 +1,112 @@
+package screenshot
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addTestShot(t *testing.T, dir string, name string, at time.Time) *HistoryEntry {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("png"), 0o644))
+
+	shot := Shot{Path: path, Width: 400, Height: 300, Format: "png", Size: 3, Time: at}
+	entry, err := AddHistory(shot, ModeRegion, "DP-1", testScreen(400, 300))
+	require.NoError(t, err)
+	return entry
+}
+
+func TestHistory(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	now := time.Now()
+
+	old := addTestShot(t, dir, "old.png", now.Add(-10*24*time.Hour))
+	gone := addTestShot(t, dir, "gone.png", now.Add(-time.Hour))
+	recent := addTestShot(t, dir, "recent.png", now)
+
+	require.FileExists(t, recent.Thumbnail)
+	thumb, err := os.Open(recent.Thumbnail)
+	require.NoError(t, err)
+	cfg, err := png.DecodeConfig(thumb)
+	thumb.Close()
+	require.NoError(t, err)
+	assert.Equal(t, historyThumbSize, cfg.Width)
+	assert.Equal(t, 192, cfg.Height)
+
+	entries, err := ListHistory(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, recent.ID, entries[0].ID, "newest first")
+	assert.Equal(t, "region", entries[0].Mode)
+	assert.Equal(t, "DP-1", entries[0].Output)
+
+	require.NoError(t, os.Remove(gone.Path))
+	entries, err = ListHistory(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "missing files are hidden")
+
+	removed, err := SetHistoryPolicy(HistoryPolicy{MaxAgeDays: 7, DeleteFiles: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.NoFileExists(t, old.Path)
+	assert.NoFileExists(t, old.Thumbnail)
+	assert.NoFileExists(t, gone.Thumbnail)
+
+	h, err := LoadHistory()
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 1)
+	assert.Equal(t, 7, h.Policy.MaxAgeDays)
+
+	require.NoError(t, DeleteHistory(recent.ID, false))
+	assert.FileExists(t, recent.Path, "the screenshot is kept unless asked")
+	assert.NoFileExists(t, recent.Thumbnail)
+	assert.Error(t, DeleteHistory(recent.ID, false))
+}
+
+func TestHistoryMaxEntries(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	_, err := SetHistoryPolicy(HistoryPolicy{MaxEntries: 2})
+	require.NoError(t, err)
+
+	start := time.Now()
+	first := addTestShot(t, dir, "1.png", start)
+	addTestShot(t, dir, "2.png", start.Add(time.Second))
+	addTestShot(t, dir, "3.png", start.Add(2*time.Second))
+
+	entries, err := ListHistory(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.FileExists(t, first.Path, "count pruning keeps files by default")
+
+	_, err = GetHistoryEntry(first.ID)
+	assert.Error(t, err)
+
+	entries, err = ListHistory(1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestHistoryCorruptFileKept(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	require.NoError(t, os.MkdirAll(historyDir(), 0o755))
+	require.NoError(t, os.WriteFile(getHistoryFilePath(), []byte(`{"entries": [`), 0o644))
+
+	_, err := ListHistory(0)
+	assert.Error(t, err)
+
+	addTestShot(t, t.TempDir(), "new.png", time.Now())
+	assert.FileExists(t, getHistoryFilePath()+".corrupt")
+
+	entries, err := ListHistory(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,254 @@
+package screenshot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/sys/unix"
+)
+
+// xkbKeymap is the character table of a compiled xkb_v1 keymap as sent in
+// wl_keyboard.keymap. It only resolves what text entry needs: the symbol at
+// each shift level of each group. Key types, actions and compose are not
+// modelled.
+type xkbKeymap struct {
+	// keys maps evdev keycodes to groups of shift levels; 0 marks a level
+	// without a printable character.
+	keys map[uint32][][]rune
+}
+
+const (
+	xkbKeycodeOffset = 8      // xkb keycodes are evdev keycodes + 8
+	modLock          = 1 << 1 // Caps Lock
+	modLevel3        = 1 << 7 // Mod5, where xkeyboard-config puts AltGr
+)
+
+var (
+	xkbSectionRe = regexp.MustCompile(`xkb_(keycodes|symbols)\b[^{]*\{`)
+	xkbKeycodeRe = regexp.MustCompile(`<([^>]+)>\s*=\s*(\d+)\s*;`)
+	xkbAliasRe   = regexp.MustCompile(`alias\s*<([^>]+)>\s*=\s*<([^>]+)>\s*;`)
+	xkbKeyRe     = regexp.MustCompile(`key\s*<([^>]+)>\s*\{([^}]*)\}`)
+	xkbGroupRe   = regexp.MustCompile(`(?:symbols\[\s*[Gg]roup(\d+)\s*\]\s*=\s*)?\[([^\]]*)\]`)
+)
+
+// loadXKBKeymap reads the keymap the compositor shared through fd and
+// closes it.
+func loadXKBKeymap(format uint32, fd int, size uint32) (*xkbKeymap, error) {
+	defer unix.Close(fd)
+
+	if format != 1 {
+		return nil, fmt.Errorf("unsupported keymap format %d", format)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("empty keymap")
+	}
+
+	data, err := unix.Mmap(fd, 0, int(size), unix.PROT_READ, unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("mmap keymap: %w", err)
+	}
+	text := strings.TrimRight(string(data), "\x00")
+	_ = unix.Munmap(data)
+
+	return parseXKBKeymap(text)
+}
+
+func parseXKBKeymap(text string) (*xkbKeymap, error) {
+	keycodes := xkbSection(text, "keycodes")
+	symbols := xkbSection(text, "symbols")
+	if keycodes == "" || symbols == "" {
+		return nil, fmt.Errorf("keymap has no keycodes or symbols section")
+	}
+
+	codes := make(map[string]uint32)
+	for _, m := range xkbKeycodeRe.FindAllStringSubmatch(keycodes, -1) {
+		code, err := strconv.ParseUint(m[2], 10, 32)
+		if err != nil || code < xkbKeycodeOffset {
+			continue
+		}
+		codes[m[1]] = uint32(code) - xkbKeycodeOffset
+	}
+	for _, m := range xkbAliasRe.FindAllStringSubmatch(keycodes, -1) {
+		if code, ok := codes[m[2]]; ok {
+			codes[m[1]] = code
+		}
+	}
+
+	km := &xkbKeymap{keys: make(map[uint32][][]rune)}
+	for _, m := range xkbKeyRe.FindAllStringSubmatch(symbols, -1) {
+		code, ok := codes[m[1]]
+		if !ok {
+			continue
+		}
+
+		var groups [][]rune
+		for i, g := range xkbGroupRe.FindAllStringSubmatch(m[2], -1) {
+			idx := i
+			if g[1] != "" {
+				n, err := strconv.Atoi(g[1])
+				if err != nil || n < 1 {
+					continue
+				}
+				idx = n - 1
+			}
+			for len(groups) <= idx {
+				groups = append(groups, nil)
+			}
+			groups[idx] = parseXKBLevels(g[2])
+		}
+		if len(groups) > 0 {
+			km.keys[code] = groups
+		}
+	}
+
+	if len(km.keys) == 0 {
+		return nil, fmt.Errorf("keymap has no key symbols")
+	}
+	return km, nil
+}
+
+// xkbSection returns the body of the named top-level section.
+func xkbSection(text, name string) string {
+	for _, loc := range xkbSectionRe.FindAllStringSubmatchIndex(text, -1) {
+		if text[loc[2]:loc[3]] != name {
+			continue
+		}
+		depth := 1
+		for i := loc[1]; i < len(text); i++ {
+			switch text[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					return text[loc[1]:i]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func parseXKBLevels(list string) []rune {
+	var levels []rune
+	for _, name := range strings.Split(list, ",") {
+		levels = append(levels, keysymRune(strings.TrimSpace(name)))
+	}
+	return levels
+}
+
+// char resolves key to the character it types in group with the given
+// wl_keyboard modifier masks.
+func (k *xkbKeymap) char(key, group, mods, locked uint32) (rune, bool) {
+	groups := k.keys[key]
+	if len(groups) == 0 {
+		return 0, false
+	}
+	levels := groups[int(group)%len(groups)]
+	if len(levels) == 0 {
+		levels = groups[0]
+	}
+
+	level := 0
+	if mods&modShift != 0 {
+		level = 1
+	}
+	if mods&modLevel3 != 0 && len(levels) > 2 {
+		level += 2
+	}
+	if level >= len(levels) {
+		level = 0
+	}
+
+	ch := levels[level]
+	if ch == 0 {
+		return 0, false
+	}
+	// Caps Lock inverts the case of letters, as the ALPHABETIC key type
+	// does.
+	if (mods|locked)&modLock != 0 && unicode.IsLetter(ch) {
+		if unicode.IsUpper(ch) {
+			ch = unicode.ToLower(ch)
+		} else {
+			ch = unicode.ToUpper(ch)
+		}
+	}
+	return ch, true
+}
+
+// latin1Keysyms names the keysyms for U+00A0..U+00FF in order; their
+// keysym values equal their code points.
+var latin1Keysyms = strings.Fields(`
+	nobreakspace exclamdown cent sterling currency yen brokenbar section
+	diaeresis copyright ordfeminine guillemetleft notsign hyphen registered macron
+	degree plusminus twosuperior threesuperior acute mu paragraph periodcentered
+	cedilla onesuperior ordmasculine guillemetright onequarter onehalf threequarters questiondown
+	Agrave Aacute Acircumflex Atilde Adiaeresis Aring AE Ccedilla
+	Egrave Eacute Ecircumflex Ediaeresis Igrave Iacute Icircumflex Idiaeresis
+	ETH Ntilde Ograve Oacute Ocircumflex Otilde Odiaeresis multiply
+	Oslash Ugrave Uacute Ucircumflex Udiaeresis Yacute THORN ssharp
+	agrave aacute acircumflex atilde adiaeresis aring ae ccedilla
+	egrave eacute ecircumflex ediaeresis igrave iacute icircumflex idiaeresis
+	eth ntilde ograve oacute ocircumflex otilde odiaeresis division
+	oslash ugrave uacute ucircumflex udiaeresis yacute thorn ydiaeresis`)
+
+var namedKeysyms = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#',
+	"dollar": '$', "percent": '%', "ampersand": '&', "apostrophe": '\'',
+	"parenleft": '(', "parenright": ')', "asterisk": '*', "plus": '+',
+	"comma": ',', "minus": '-', "period": '.', "slash": '/',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=',
+	"greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "asciicircum": '^', "underscore": '_',
+	"grave": '`', "braceleft": '{', "bar": '|', "braceright": '}',
+	"asciitilde": '~', "EuroSign": '€',
+	// Legacy spellings xkbcommon still emits.
+	"guillemotleft": '«', "guillemotright": '»', "masculine": 'º',
+	"Eth": 'Ð', "Thorn": 'Þ', "Ooblique": 'Ø',
+}
+
+func init() {
+	for i, name := range latin1Keysyms {
+		if _, ok := namedKeysyms[name]; !ok {
+			namedKeysyms[name] = rune(0xa0 + i)
+		}
+	}
+}
+
+// keysymRune maps a keysym name to the character it types, or 0 for
+// keysyms without one (modifiers, dead keys, NoSymbol). Beyond ASCII and
+// Latin-1 only the Unicode forms (U20AC, 0x10020ac) are understood.
+func keysymRune(name string) rune {
+	if r := []rune(name); len(r) == 1 {
+		if unicode.IsPrint(r[0]) {
+			return r[0]
+		}
+		return 0
+	}
+	if r, ok := namedKeysyms[name]; ok {
+		return r
+	}
+
+	var code uint64
+	var err error
+	switch {
+	case len(name) > 1 && name[0] == 'U':
+		code, err = strconv.ParseUint(name[1:], 16, 32)
+	case strings.HasPrefix(name, "0x"):
+		code, err = strconv.ParseUint(name[2:], 16, 32)
+		if err == nil && code >= 0x1000100 {
+			code -= 0x1000000
+		} else if err == nil && code > 0xff {
+			return 0
+		}
+	default:
+		return 0
+	}
+	if err != nil || !unicode.IsPrint(rune(code)) {
+		return 0
+	}
+	return rune(code)
+}
@@ -0,0 +1,134 @@
+package screenshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeymap = `xkb_keymap {
+xkb_keycodes "(unnamed)" {
+	minimum = 8;
+	maximum = 255;
+	<ESC>                = 9;
+	<AE01>               = 10;
+	<AD01>               = 24;
+	<AD06>               = 29;
+	<AC10>               = 47;
+	<AB01>               = 52;
+	<LatZ>               = 52;
+	alias <AZ01>         = <LatZ>;
+	indicator 1 = "Caps Lock";
+};
+
+xkb_types "complete" {
+	type "ALPHABETIC" {
+		modifiers= Shift+Lock;
+		map[Shift]= Level2;
+	};
+};
+
+xkb_compatibility "complete" {
+	interpret Shift_L+AnyOf(all) {
+		action= SetMods(modifiers=Shift);
+	};
+};
+
+xkb_symbols "pc+de+us:2" {
+	name[Group1]="German";
+	key <ESC>                {	[          Escape ] };
+	key <AE01>               {	[               1,          exclam,     onesuperior,      exclamdown ] };
+	key <AD01>               {
+		type= "ALPHABETIC",
+		symbols[Group1]= [               q,               Q,              at,     Greek_OMEGA ],
+		symbols[Group2]= [               q,               Q ]
+	};
+	key <AD06>               {
+		symbols[Group1]= [               z,               Z ],
+		symbols[Group2]= [               y,               Y ]
+	};
+	key <AC10>               {	[      odiaeresis,      Odiaeresis,      dead_acute ] };
+	key <AZ01>               {	[           U20AC,      0x10003a9 ] };
+};
+};
+`
+
+func TestParseXKBKeymap(t *testing.T) {
+	km, err := parseXKBKeymap(testKeymap)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		key    uint32
+		group  uint32
+		mods   uint32
+		locked uint32
+		want   rune
+		ok     bool
+	}{
+		{"german z on the us y key", 21, 0, 0, 0, 'z', true},
+		{"second group", 21, 1, 0, 0, 'y', true},
+		{"shifted second group", 21, 1, modShift, 0, 'Y', true},
+		{"group wraps", 21, 2, 0, 0, 'z', true},
+		{"latin-1 name", 39, 0, 0, 0, 'ö', true},
+		{"shifted latin-1 name", 39, 0, modShift, 0, 'Ö', true},
+		{"dead key types nothing", 39, 0, modLevel3, 0, 0, false},
+		{"altgr level 3", 16, 0, modLevel3, 0, '@', true},
+		{"altgr shift unknown keysym", 16, 0, modLevel3 | modShift, 0, 0, false},
+		{"altgr falls back on two-level group", 16, 1, modLevel3, 0, 'q', true},
+		{"caps lock", 16, 0, 0, modLock, 'Q', true},
+		{"caps lock with shift", 16, 0, modShift, modLock, 'q', true},
+		{"caps lock leaves digits", 2, 0, 0, modLock, '1', true},
+		{"shifted digit", 2, 0, modShift, 0, '!', true},
+		{"unicode keysym via alias", 44, 0, 0, 0, '€', true},
+		{"unicode hex keysym", 44, 0, modShift, 0, 'Ω', true},
+		{"escape types nothing", 1, 0, 0, 0, 0, false},
+		{"unmapped key", 100, 0, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := km.char(tt.key, tt.group, tt.mods, tt.locked)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseXKBKeymapInvalid(t *testing.T) {
+	_, err := parseXKBKeymap("xkb_keymap { xkb_keycodes { <AE01> = 10; }; };")
+	assert.Error(t, err)
+
+	_, err = parseXKBKeymap("")
+	assert.Error(t, err)
+}
+
+func TestKeysymRune(t *testing.T) {
+	tests := map[string]rune{
+		"a":              'a',
+		"slash":          '/',
+		"space":          ' ',
+		"ssharp":         'ß',
+		"guillemotleft":  '«',
+		"guillemetleft":  '«',
+		"EuroSign":       '€',
+		"U00E9":          'é',
+		"0x10020ac":      '€',
+		"0xe9":           'é',
+		"0xff08":         0,
+		"Shift_L":        0,
+		"dead_grave":     0,
+		"NoSymbol":       0,
+		"Ucircumflex":    'Û',
+		"Udiaeresis":     'Ü',
+		"ydiaeresis":     'ÿ',
+		"nobreakspace":   ' ',
+		"Greek_OMEGA":    0,
+		"UNKNOWNKEYSYM1": 0,
+	}
+
+	for name, want := range tests {
+		assert.Equal(t, want, keysymRune(name), name)
+	}
+}
@@ -0,0 +1,113 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"time"
+)
+
+const metadataSoftware = "DankMaterialShell"
+
+// Metadata describes a capture. Embedding is opt-in; by default the
+// encoders write nothing but pixels.
+type Metadata struct {
+	Time   time.Time
+	Output string
+	Mode   Mode
+	Region Region
+}
+
+func (m *Metadata) fields() [][2]string {
+	fields := [][2]string{
+		{"Software", metadataSoftware},
+		{"Creation Time", m.Time.Format(time.RFC1123Z)},
+		{"Capture Mode", m.Mode.String()},
+	}
+	if m.Output != "" {
+		fields = append(fields, [2]string{"Output", m.Output})
+	}
+	if !m.Region.IsEmpty() {
+		fields = append(fields, [2]string{"Region", m.regionString()})
+	}
+	return fields
+}
+
+func (m *Metadata) regionString() string {
+	r := m.Region
+	return fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+}
+
+// embedPNGText adds a tEXt chunk per field right after IHDR.
+func embedPNGText(data []byte, m *Metadata) []byte {
+	const ihdrEnd = 8 + 8 + 13 + 4
+	if len(data) < ihdrEnd {
+		return data
+	}
+
+	var chunks bytes.Buffer
+	for _, f := range m.fields() {
+		body := append([]byte(f[0]+"\x00"), f[1]...)
+		var hdr [8]byte
+		binary.BigEndian.PutUint32(hdr[:4], uint32(len(body)))
+		copy(hdr[4:], "tEXt")
+		chunks.Write(hdr[:])
+		chunks.Write(body)
+
+		crc := crc32.NewIEEE()
+		crc.Write(hdr[4:])
+		crc.Write(body)
+		binary.Write(&chunks, binary.BigEndian, crc.Sum32())
+	}
+
+	out := make([]byte, 0, len(data)+chunks.Len())
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunks.Bytes()...)
+	return append(out, data[ihdrEnd:]...)
+}
+
+// embedJPEGComment adds a COM segment right after SOI.
+func embedJPEGComment(data []byte, m *Metadata) []byte {
+	if len(data) < 2 {
+		return data
+	}
+
+	var text bytes.Buffer
+	for i, f := range m.fields() {
+		if i > 0 {
+			text.WriteByte('\n')
+		}
+		fmt.Fprintf(&text, "%s: %s", f[0], f[1])
+	}
+
+	seg := []byte{0xff, 0xfe, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(text.Len()+2))
+	seg = append(seg, text.Bytes()...)
+
+	out := make([]byte, 0, len(data)+len(seg))
+	out = append(out, data[:2]...)
+	out = append(out, seg...)
+	return append(out, data[2:]...)
+}
+
+func (m *Metadata) xmp() []byte {
+	desc := m.Mode.String()
+	if m.Output != "" {
+		desc += " " + m.Output
+	}
+	if !m.Region.IsEmpty() {
+		desc += " " + m.regionString()
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">`)
+	b.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">`)
+	b.WriteString(`<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	fmt.Fprintf(&b, `<xmp:CreatorTool>%s</xmp:CreatorTool>`, metadataSoftware)
+	fmt.Fprintf(&b, `<xmp:CreateDate>%s</xmp:CreateDate>`, m.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, `<dc:description><rdf:Alt><rdf:li xml:lang="x-default">%s</rdf:li></rdf:Alt></dc:description>`, html.EscapeString(desc))
+	b.WriteString(`</rdf:Description></rdf:RDF></x:xmpmeta>`)
+	return b.Bytes()
+}
@@ -0,0 +1,545 @@
+package screenshot
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/keyboard_shortcuts_inhibit"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wp_viewporter"
+	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+// overlayClient is the Wayland plumbing shared by the full-screen overlays
+// (region selector, annotation editor): globals, input devices, the
+// crosshair cursor and triple-buffered layer surfaces.
+type overlayClient struct {
+	display  *client.Display
+	registry *client.Registry
+	ctx      *client.Context
+
+	compositor *client.Compositor
+	shm        *client.Shm
+	seat       *client.Seat
+	pointer    *client.Pointer
+	keyboard   *client.Keyboard
+	keymap     *xkbKeymap
+	layerShell *wlr_layer_shell.ZwlrLayerShellV1
+	viewporter *wp_viewporter.WpViewporter
+
+	shortcutsInhibitMgr *keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitManagerV1
+	shortcutsInhibitor  *keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitorV1
+
+	outputs   map[uint32]*WaylandOutput
+	outputsMu sync.Mutex
+
+	cursorSurface *client.Surface
+	cursorBuffer  *ShmBuffer
+	cursorWlBuf   *client.Buffer
+	cursorPool    *client.ShmPool
+
+	// bindExtra binds globals beyond the shared set and reports whether it
+	// took the global.
+	bindExtra func(e client.RegistryGlobalEvent) bool
+	// setupPointer and setupKeyboard install the owner's handlers once the
+	// seat offers the device.
+	setupPointer  func()
+	setupKeyboard func()
+}
+
+func newOverlayClient() *overlayClient {
+	return &overlayClient{outputs: make(map[uint32]*WaylandOutput)}
+}
+
+func (c *overlayClient) connect() error {
+	display, err := client.Connect("")
+	if err != nil {
+		return err
+	}
+	c.display = display
+	c.ctx = display.Context()
+	return nil
+}
+
+func (c *overlayClient) roundtrip() error {
+	return wlhelpers.Roundtrip(c.display, c.ctx)
+}
+
+func (c *overlayClient) setupRegistry() error {
+	registry, err := c.display.GetRegistry()
+	if err != nil {
+		return err
+	}
+	c.registry = registry
+
+	registry.SetGlobalHandler(func(e client.RegistryGlobalEvent) {
+		c.handleGlobal(e)
+	})
+
+	registry.SetGlobalRemoveHandler(func(e client.RegistryGlobalRemoveEvent) {
+		c.outputsMu.Lock()
+		delete(c.outputs, e.Name)
+		c.outputsMu.Unlock()
+	})
+
+	return nil
+}
+
+// checkGlobals reports the first missing global every overlay needs.
+func (c *overlayClient) checkGlobals() error {
+	switch {
+	case c.layerShell == nil:
+		return fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
+	case c.seat == nil:
+		return fmt.Errorf("no seat available")
+	case c.compositor == nil:
+		return fmt.Errorf("compositor not available")
+	case c.shm == nil:
+		return fmt.Errorf("wl_shm not available")
+	}
+	return nil
+}
+
+func (c *overlayClient) handleGlobal(e client.RegistryGlobalEvent) {
+	if c.bindExtra != nil && c.bindExtra(e) {
+		return
+	}
+
+	switch e.Interface {
+	case client.CompositorInterfaceName:
+		comp := client.NewCompositor(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, comp); err == nil {
+			c.compositor = comp
+		}
+
+	case client.ShmInterfaceName:
+		shm := client.NewShm(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, shm); err == nil {
+			c.shm = shm
+		}
+
+	case client.SeatInterfaceName:
+		seat := client.NewSeat(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, seat); err == nil {
+			c.seat = seat
+			c.setupInput()
+		}
+
+	case client.OutputInterfaceName:
+		output := client.NewOutput(c.ctx)
+		version := min(e.Version, 4)
+		if err := c.registry.Bind(e.Name, e.Interface, version, output); err == nil {
+			c.outputsMu.Lock()
+			c.outputs[e.Name] = &WaylandOutput{
+				wlOutput:        output,
+				globalName:      e.Name,
+				scale:           1,
+				fractionalScale: 1.0,
+			}
+			c.outputsMu.Unlock()
+			c.setupOutputHandlers(e.Name, output)
+		}
+
+	case wlr_layer_shell.ZwlrLayerShellV1InterfaceName:
+		ls := wlr_layer_shell.NewZwlrLayerShellV1(c.ctx)
+		version := min(e.Version, 4)
+		if err := c.registry.Bind(e.Name, e.Interface, version, ls); err == nil {
+			c.layerShell = ls
+		}
+
+	case wp_viewporter.WpViewporterInterfaceName:
+		vp := wp_viewporter.NewWpViewporter(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, vp); err == nil {
+			c.viewporter = vp
+		}
+
+	case keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitManagerV1InterfaceName:
+		mgr := keyboard_shortcuts_inhibit.NewZwpKeyboardShortcutsInhibitManagerV1(c.ctx)
+		if err := c.registry.Bind(e.Name, e.Interface, e.Version, mgr); err == nil {
+			c.shortcutsInhibitMgr = mgr
+		}
+	}
+}
+
+func (c *overlayClient) setupOutputHandlers(name uint32, output *client.Output) {
+	output.SetGeometryHandler(func(e client.OutputGeometryEvent) {
+		c.outputsMu.Lock()
+		if o, ok := c.outputs[name]; ok {
+			o.x = e.X
+			o.y = e.Y
+			o.transform = int32(e.Transform)
+		}
+		c.outputsMu.Unlock()
+	})
+
+	output.SetModeHandler(func(e client.OutputModeEvent) {
+		if e.Flags&uint32(client.OutputModeCurrent) == 0 {
+			return
+		}
+		c.outputsMu.Lock()
+		if o, ok := c.outputs[name]; ok {
+			o.width = e.Width
+			o.height = e.Height
+		}
+		c.outputsMu.Unlock()
+	})
+
+	output.SetScaleHandler(func(e client.OutputScaleEvent) {
+		c.outputsMu.Lock()
+		if o, ok := c.outputs[name]; ok {
+			o.scale = e.Factor
+			o.fractionalScale = float64(e.Factor)
+		}
+		c.outputsMu.Unlock()
+	})
+
+	output.SetNameHandler(func(e client.OutputNameEvent) {
+		c.outputsMu.Lock()
+		if o, ok := c.outputs[name]; ok {
+			o.name = e.Name
+		}
+		c.outputsMu.Unlock()
+	})
+}
+
+func (c *overlayClient) outputList() []*WaylandOutput {
+	c.outputsMu.Lock()
+	defer c.outputsMu.Unlock()
+
+	outputs := make([]*WaylandOutput, 0, len(c.outputs))
+	for _, o := range c.outputs {
+		outputs = append(outputs, o)
+	}
+	return outputs
+}
+
+func (c *overlayClient) setupInput() {
+	if c.seat == nil {
+		return
+	}
+
+	c.seat.SetCapabilitiesHandler(func(e client.SeatCapabilitiesEvent) {
+		if e.Capabilities&uint32(client.SeatCapabilityPointer) != 0 && c.pointer == nil {
+			if pointer, err := c.seat.GetPointer(); err == nil {
+				c.pointer = pointer
+				if c.setupPointer != nil {
+					c.setupPointer()
+				}
+			}
+		}
+		if e.Capabilities&uint32(client.SeatCapabilityKeyboard) != 0 && c.keyboard == nil {
+			if keyboard, err := c.seat.GetKeyboard(); err == nil {
+				c.keyboard = keyboard
+				keyboard.SetKeymapHandler(c.handleKeymap)
+				if c.setupKeyboard != nil {
+					c.setupKeyboard()
+				}
+			}
+		}
+	})
+}
+
+func (c *overlayClient) handleKeymap(e client.KeyboardKeymapEvent) {
+	keymap, err := loadXKBKeymap(e.Format, e.Fd, e.Size)
+	if err != nil {
+		log.Warn("keyboard keymap unavailable, falling back to US layout", "err", err)
+		return
+	}
+	c.keymap = keymap
+}
+
+func (c *overlayClient) createCursor() error {
+	const size = 24
+
+	surface, err := c.compositor.CreateSurface()
+	if err != nil {
+		return fmt.Errorf("create cursor surface: %w", err)
+	}
+	c.cursorSurface = surface
+
+	buf, err := CreateShmBuffer(size, size, size*4)
+	if err != nil {
+		return fmt.Errorf("create cursor buffer: %w", err)
+	}
+	c.cursorBuffer = buf
+
+	drawCrosshair(buf.Data(), size)
+
+	pool, err := c.shm.CreatePool(buf.Fd(), int32(buf.Size()))
+	if err != nil {
+		return fmt.Errorf("create cursor pool: %w", err)
+	}
+	c.cursorPool = pool
+
+	wlBuf, err := pool.CreateBuffer(0, size, size, size*4, uint32(FormatARGB8888))
+	if err != nil {
+		return fmt.Errorf("create cursor wl_buffer: %w", err)
+	}
+	c.cursorWlBuf = wlBuf
+
+	if err := surface.Attach(wlBuf, 0, 0); err != nil {
+		return fmt.Errorf("attach cursor: %w", err)
+	}
+	if err := surface.Damage(0, 0, size, size); err != nil {
+		return fmt.Errorf("damage cursor: %w", err)
+	}
+	if err := surface.Commit(); err != nil {
+		return fmt.Errorf("commit cursor: %w", err)
+	}
+
+	return nil
+}
+
+// drawCrosshair fills a square ARGB8888 cursor image with a white crosshair.
+func drawCrosshair(data []byte, size int) {
+	hotspot := size / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			off := (y*size + x) * 4
+			// Vertical line
+			if x >= hotspot-1 && x <= hotspot && y >= 2 && y < size-2 {
+				data[off+0] = 255 // B
+				data[off+1] = 255 // G
+				data[off+2] = 255 // R
+				data[off+3] = 255 // A
+				continue
+			}
+			// Horizontal line
+			if y >= hotspot-1 && y <= hotspot && x >= 2 && x < size-2 {
+				data[off+0] = 255
+				data[off+1] = 255
+				data[off+2] = 255
+				data[off+3] = 255
+				continue
+			}
+			// Transparent
+			data[off+0] = 0
+			data[off+1] = 0
+			data[off+2] = 0
+			data[off+3] = 0
+		}
+	}
+}
+
+// overlaySurface is a full-screen overlay layer surface with exclusive
+// keyboard focus. The caller installs the configure and closed handlers and
+// commits.
+type overlaySurface struct {
+	wlSurface *client.Surface
+	layerSurf *wlr_layer_shell.ZwlrLayerSurfaceV1
+	viewport  *wp_viewporter.WpViewport
+}
+
+func (c *overlayClient) createOverlaySurface(output *WaylandOutput, namespace string) (overlaySurface, error) {
+	var s overlaySurface
+
+	surface, err := c.compositor.CreateSurface()
+	if err != nil {
+		return s, fmt.Errorf("create surface: %w", err)
+	}
+	s.wlSurface = surface
+
+	var wlOutput *client.Output
+	if output != nil {
+		wlOutput = output.wlOutput
+	}
+	layerSurf, err := c.layerShell.GetLayerSurface(
+		surface,
+		wlOutput,
+		uint32(wlr_layer_shell.ZwlrLayerShellV1LayerOverlay),
+		namespace,
+	)
+	if err != nil {
+		return s, fmt.Errorf("get layer surface: %w", err)
+	}
+	s.layerSurf = layerSurf
+
+	if c.viewporter != nil {
+		if vp, err := c.viewporter.GetViewport(surface); err == nil {
+			s.viewport = vp
+		}
+	}
+
+	if err := layerSurf.SetAnchor(
+		uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorTop) |
+			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorBottom) |
+			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorLeft) |
+			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorRight),
+	); err != nil {
+		return s, fmt.Errorf("set anchor: %w", err)
+	}
+	if err := layerSurf.SetExclusiveZone(-1); err != nil {
+		return s, fmt.Errorf("set exclusive zone: %w", err)
+	}
+	if err := layerSurf.SetKeyboardInteractivity(uint32(wlr_layer_shell.ZwlrLayerSurfaceV1KeyboardInteractivityExclusive)); err != nil {
+		return s, fmt.Errorf("set keyboard interactivity: %w", err)
+	}
+
+	return s, nil
+}
+
+// present attaches slot and scales it onto the logical surface size, through
+// the viewport when there is one.
+func (s *overlaySurface) present(slot *RenderSlot, logicalW, logicalH int, bufferScale int32) {
+	if s.viewport != nil {
+		_ = s.wlSurface.SetBufferScale(1)
+		_ = s.viewport.SetSource(0, 0, float64(slot.shm.Width), float64(slot.shm.Height))
+		_ = s.viewport.SetDestination(int32(logicalW), int32(logicalH))
+	} else {
+		_ = s.wlSurface.SetBufferScale(max(bufferScale, 1))
+	}
+
+	_ = s.wlSurface.Attach(slot.wlBuf, 0, 0)
+	_ = s.wlSurface.Damage(0, 0, int32(logicalW), int32(logicalH))
+	_ = s.wlSurface.Commit()
+
+	// Busy until the compositor releases it
+	slot.busy = true
+}
+
+func (s *overlaySurface) destroy() {
+	if s.viewport != nil {
+		s.viewport.Destroy()
+	}
+	if s.layerSurf != nil {
+		s.layerSurf.Destroy()
+	}
+	if s.wlSurface != nil {
+		s.wlSurface.Destroy()
+	}
+}
+
+func (c *overlayClient) ensureShortcutsInhibitor(surface *client.Surface) {
+	if c.shortcutsInhibitMgr == nil || c.seat == nil || c.shortcutsInhibitor != nil {
+		return
+	}
+	if inhibitor, err := c.shortcutsInhibitMgr.InhibitShortcuts(surface, c.seat); err == nil {
+		c.shortcutsInhibitor = inhibitor
+	}
+}
+
+type RenderSlot struct {
+	shm   *ShmBuffer
+	pool  *client.ShmPool
+	wlBuf *client.Buffer
+	busy  bool
+}
+
+// renderSlots triple-buffers an overlay surface.
+type renderSlots [3]*RenderSlot
+
+// createRenderSlots allocates every slot, calling onRelease after the
+// compositor hands one back. It reports false, leaving the slots empty, if
+// any allocation fails.
+func (c *overlayClient) createRenderSlots(slots *renderSlots, w, h, stride int, format uint32, onRelease func()) bool {
+	slots.destroy()
+
+	for i := range slots {
+		slot := &RenderSlot{}
+
+		buf, err := CreateShmBuffer(w, h, stride)
+		if err != nil {
+			log.Error("create render slot buffer failed", "err", err)
+			slots.destroy()
+			return false
+		}
+		slot.shm = buf
+
+		pool, err := c.shm.CreatePool(buf.Fd(), int32(buf.Size()))
+		if err != nil {
+			log.Error("create render slot pool failed", "err", err)
+			buf.Close()
+			slots.destroy()
+			return false
+		}
+		slot.pool = pool
+
+		wlBuf, err := pool.CreateBuffer(0, int32(w), int32(h), int32(stride), format)
+		if err != nil {
+			log.Error("create render slot wl_buffer failed", "err", err)
+			pool.Destroy()
+			buf.Close()
+			slots.destroy()
+			return false
+		}
+		slot.wlBuf = wlBuf
+
+		slotRef := slot
+		wlBuf.SetReleaseHandler(func(e client.BufferReleaseEvent) {
+			slotRef.busy = false
+			if onRelease != nil {
+				onRelease()
+			}
+		})
+
+		slots[i] = slot
+	}
+	return true
+}
+
+func (slots *renderSlots) acquire() *RenderSlot {
+	for _, slot := range slots {
+		if slot != nil && !slot.busy {
+			return slot
+		}
+	}
+	return nil
+}
+
+func (slots *renderSlots) destroy() {
+	for i, slot := range slots {
+		if slot == nil {
+			continue
+		}
+		if slot.wlBuf != nil {
+			slot.wlBuf.Destroy()
+		}
+		if slot.pool != nil {
+			slot.pool.Destroy()
+		}
+		if slot.shm != nil {
+			slot.shm.Close()
+		}
+		slots[i] = nil
+	}
+}
+
+func (c *overlayClient) destroyCursor() {
+	if c.cursorWlBuf != nil {
+		c.cursorWlBuf.Destroy()
+	}
+	if c.cursorPool != nil {
+		c.cursorPool.Destroy()
+	}
+	if c.cursorSurface != nil {
+		c.cursorSurface.Destroy()
+	}
+	if c.cursorBuffer != nil {
+		c.cursorBuffer.Close()
+	}
+}
+
+// close releases the shared globals and input devices and disconnects. The
+// owner destroys its surfaces first.
+func (c *overlayClient) close() {
+	if c.shortcutsInhibitor != nil {
+		_ = c.shortcutsInhibitor.Destroy()
+	}
+	if c.shortcutsInhibitMgr != nil {
+		_ = c.shortcutsInhibitMgr.Destroy()
+	}
+	if c.viewporter != nil {
+		c.viewporter.Destroy()
+	}
+	if c.pointer != nil {
+		c.pointer.Release()
+	}
+	if c.keyboard != nil {
+		c.keyboard.Release()
+	}
+	if c.display != nil {
+		c.ctx.Close()
+	}
+}
@@ -0,0 +1,731 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+type RecordFormat int
+
+const (
+	RecordAuto RecordFormat = iota
+	RecordGIF
+	RecordAPNG
+	RecordMP4
+	RecordWebM
+	RecordMKV
+)
+
+func ParseRecordFormat(s string) (RecordFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return RecordAuto, nil
+	case "gif":
+		return RecordGIF, nil
+	case "apng", "png":
+		return RecordAPNG, nil
+	case "mp4":
+		return RecordMP4, nil
+	case "webm":
+		return RecordWebM, nil
+	case "mkv":
+		return RecordMKV, nil
+	}
+	return RecordAuto, fmt.Errorf("unknown recording format %q (gif, apng, mp4, webm, mkv)", s)
+}
+
+func (f RecordFormat) String() string {
+	switch f {
+	case RecordGIF:
+		return "gif"
+	case RecordAPNG:
+		return "apng"
+	case RecordMP4:
+		return "mp4"
+	case RecordWebM:
+		return "webm"
+	case RecordMKV:
+		return "mkv"
+	default:
+		return "auto"
+	}
+}
+
+func (f RecordFormat) Extension() string {
+	if f == RecordAPNG {
+		return "png"
+	}
+	return f.String()
+}
+
+// Native formats are encoded in-process; the rest need ffmpeg.
+func (f RecordFormat) Native() bool {
+	return f == RecordGIF || f == RecordAPNG
+}
+
+// ResolveRecordFormat picks a video format when ffmpeg is installed and
+// falls back to GIF otherwise.
+func ResolveRecordFormat(f RecordFormat) RecordFormat {
+	if f != RecordAuto {
+		return f
+	}
+	if ffmpegAvailable() {
+		return RecordMP4
+	}
+	return RecordGIF
+}
+
+type RecordConfig struct {
+	Mode        Mode
+	OutputName  string
+	Cursor      CursorMode
+	Reset       bool
+	Format      RecordFormat
+	FPS         int
+	Path        string
+	MaxDuration time.Duration
+}
+
+func DefaultRecordConfig() RecordConfig {
+	return RecordConfig{
+		Mode:   ModeRegion,
+		Cursor: CursorOn,
+		Format: RecordAuto,
+	}
+}
+
+// defaultFPS keeps animated images small; video encoders can afford more.
+func defaultFPS(f RecordFormat) int {
+	if f.Native() {
+		return 15
+	}
+	return 30
+}
+
+// Recording describes a recording that has started.
+type Recording struct {
+	Path      string
+	Format    RecordFormat
+	Region    Region
+	StartedAt time.Time
+}
+
+type RecordResult struct {
+	Path     string
+	Format   RecordFormat
+	Region   Region
+	Frames   int
+	Duration time.Duration
+}
+
+func GetRecordingDir() string {
+	if dir := os.Getenv("DMS_RECORDING_DIR"); dir != "" {
+		return dir
+	}
+
+	if xdgVideos := utils.XDGVideosDir(); xdgVideos != "" {
+		recordingDir := filepath.Join(xdgVideos, "Screencasts")
+		if err := os.MkdirAll(recordingDir, 0o755); err == nil {
+			return recordingDir
+		}
+		return xdgVideos
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return "."
+}
+
+func GenerateRecordingFilename(format RecordFormat) string {
+	return fmt.Sprintf("recording_%s.%s", time.Now().Format("2006-01-02_15-04-05"), format.Extension())
+}
+
+// frameSource is what a recording captures on every frame. Sources on an
+// untransformed output stream straight from wlr-screencopy and get damage
+// from the compositor; the rest reuse the screenshot paths and diff frames.
+type frameSource struct {
+	output  *WaylandOutput
+	region  Region
+	local   image.Rectangle
+	whole   bool
+	capture func() (*CaptureResult, error)
+}
+
+type Recorder struct {
+	config RecordConfig
+	sc     *Screenshoter
+}
+
+func NewRecorder(config RecordConfig) *Recorder {
+	return &Recorder{config: config}
+}
+
+// Run selects what to record and captures frames until ctx is cancelled or
+// MaxDuration passes. started is called once the first frame is on disk.
+// A cancelled region selection returns a nil result and no error.
+func (r *Recorder) Run(ctx context.Context, started func(Recording)) (*RecordResult, error) {
+	format := ResolveRecordFormat(r.config.Format)
+	if !format.Native() && !ffmpegAvailable() {
+		return nil, fmt.Errorf("recording to %s requires ffmpeg", format)
+	}
+
+	fps := r.config.FPS
+	if fps <= 0 {
+		fps = defaultFPS(format)
+	}
+
+	r.sc = New(Config{
+		Mode:       r.config.Mode,
+		OutputName: r.config.OutputName,
+		Cursor:     r.config.Cursor,
+		Reset:      r.config.Reset,
+	})
+	sc := r.sc
+
+	if err := sc.connect(); err != nil {
+		return nil, fmt.Errorf("wayland connect: %w", err)
+	}
+	defer sc.cleanup()
+
+	if err := sc.setupRegistry(); err != nil {
+		return nil, fmt.Errorf("registry setup: %w", err)
+	}
+	if err := sc.roundtrip(); err != nil {
+		return nil, fmt.Errorf("roundtrip: %w", err)
+	}
+	if sc.screencopy == nil {
+		return nil, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+	}
+	if err := sc.roundtrip(); err != nil {
+		return nil, fmt.Errorf("roundtrip: %w", err)
+	}
+
+	src, err := r.selectSource()
+	if err != nil || src == nil {
+		return nil, err
+	}
+
+	path := r.config.Path
+	if path == "" {
+		path = filepath.Join(GetRecordingDir(), GenerateRecordingFilename(format))
+	}
+
+	if r.config.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.MaxDuration)
+		defer cancel()
+	}
+
+	rec := &recording{
+		src:      src,
+		sc:       sc,
+		format:   format,
+		path:     path,
+		fps:      fps,
+		interval: time.Second / time.Duration(fps),
+		started:  started,
+	}
+	return rec.run(ctx)
+}
+
+func (r *Recorder) selectSource() (*frameSource, error) {
+	sc := r.sc
+
+	switch r.config.Mode {
+	case ModeOutput:
+		output := sc.findOutputByName(r.config.OutputName)
+		if output == nil {
+			return nil, fmt.Errorf("output %q not found", r.config.OutputName)
+		}
+		return sc.outputSource(output), nil
+
+	case ModeFullScreen:
+		output := sc.findFocusedOutput()
+		if output == nil {
+			return nil, fmt.Errorf("no output available")
+		}
+		return sc.outputSource(output), nil
+
+	case ModeWindow:
+		return sc.windowSource()
+
+	case ModeLastRegion:
+		region := GetLastRegion()
+		if output := sc.findOutputForRegion(region); !region.IsEmpty() && output != nil {
+			return sc.regionSource(output, region), nil
+		}
+		return r.selectRegion()
+
+	case ModeRegion:
+		return r.selectRegion()
+
+	default:
+		return nil, fmt.Errorf("recording all outputs at once is not supported")
+	}
+}
+
+func (r *Recorder) selectRegion() (*frameSource, error) {
+	if r.config.Reset {
+		if err := SaveLastRegion(Region{}); err != nil {
+			log.Debug("failed to reset last region", "err", err)
+		}
+	}
+
+	result, cancelled, err := NewRegionSelector(r.sc).Run()
+	if err != nil {
+		return nil, fmt.Errorf("region selection: %w", err)
+	}
+	if cancelled || result == nil {
+		return nil, nil
+	}
+	result.Buffer.Close()
+
+	if err := SaveLastRegion(result.Region); err != nil {
+		log.Debug("failed to save last region", "err", err)
+	}
+
+	output := r.sc.findOutputForRegion(result.Region)
+	if output == nil {
+		return nil, fmt.Errorf("could not find output for region")
+	}
+	return r.sc.regionSource(output, result.Region), nil
+}
+
+func (s *Screenshoter) outputSource(output *WaylandOutput) *frameSource {
+	region := Region{X: output.x, Y: output.y, Width: output.width, Height: output.height, Output: output.name}
+	if output.transform != TransformNormal {
+		return &frameSource{output: output, region: region, capture: func() (*CaptureResult, error) {
+			return s.captureWholeOutput(output)
+		}}
+	}
+	return &frameSource{output: output, region: region, whole: true}
+}
+
+func (s *Screenshoter) regionSource(output *WaylandOutput, region Region) *frameSource {
+	if output.transform != TransformNormal {
+		return &frameSource{output: output, region: region, capture: func() (*CaptureResult, error) {
+			return s.captureRegionOnTransformedOutput(output, region)
+		}}
+	}
+	x, y, w, h := s.regionOnOutput(output, region)
+	return &frameSource{output: output, region: region, local: image.Rect(int(x), int(y), int(x+w), int(y+h))}
+}
+
+func (s *Screenshoter) windowSource() (*frameSource, error) {
+	geom, err := GetActiveWindow()
+	if err != nil {
+		return nil, err
+	}
+
+	region := Region{X: geom.X, Y: geom.Y, Width: geom.Width, Height: geom.Height}
+
+	var output *WaylandOutput
+	if geom.Output != "" {
+		output = s.findOutputByName(geom.Output)
+	}
+	if output == nil {
+		output = s.findOutputForRegion(region)
+	}
+	if output == nil {
+		return nil, fmt.Errorf("could not find output for window")
+	}
+
+	switch DetectCompositor() {
+	case CompositorHyprland:
+		return &frameSource{output: output, region: region, capture: func() (*CaptureResult, error) {
+			return s.captureAndCrop(output, region)
+		}}, nil
+	case CompositorDWL:
+		return &frameSource{output: output, region: region, capture: func() (*CaptureResult, error) {
+			return s.captureDWLWindow(output, region, geom)
+		}}, nil
+	default:
+		return s.regionSource(output, region), nil
+	}
+}
+
+type recording struct {
+	src      *frameSource
+	sc       *Screenshoter
+	format   RecordFormat
+	path     string
+	fps      int
+	interval time.Duration
+	started  func(Recording)
+
+	sink   frameSink
+	frame  *image.RGBA
+	prev   *image.RGBA
+	start  time.Time
+	frames int
+
+	stream *streamCapture
+}
+
+var errFrameSizeChanged = errors.New("capture size changed during recording")
+
+func (rec *recording) run(ctx context.Context) (*RecordResult, error) {
+	if rec.src.capture == nil {
+		rec.stream = &streamCapture{sc: rec.sc, src: rec.src}
+		defer rec.stream.release()
+	}
+
+	var runErr error
+	next := time.Now()
+	for {
+		if err := rec.captureFrame(ctx); err != nil {
+			if ctx.Err() == nil {
+				runErr = err
+			}
+			break
+		}
+
+		next = next.Add(rec.interval)
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		} else {
+			next = time.Now()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if rec.sink == nil {
+		if runErr == nil {
+			runErr = fmt.Errorf("recording stopped before the first frame")
+		}
+		return nil, runErr
+	}
+
+	duration := time.Since(rec.start)
+	if err := rec.sink.Close(duration); err != nil && runErr == nil {
+		runErr = err
+	}
+
+	result := &RecordResult{
+		Path:     rec.path,
+		Format:   rec.format,
+		Region:   rec.src.region,
+		Frames:   rec.frames,
+		Duration: duration,
+	}
+	if runErr != nil && !errors.Is(runErr, errFrameSizeChanged) {
+		return result, runErr
+	}
+	if runErr != nil {
+		log.Warnf("Recording stopped: %v", runErr)
+	}
+	return result, nil
+}
+
+func (rec *recording) captureFrame(ctx context.Context) error {
+	var dirty image.Rectangle
+
+	if rec.stream != nil {
+		buf, damage, yInverted, err := rec.stream.next(ctx)
+		if err != nil {
+			return err
+		}
+		if err := rec.ensureFrame(buf.Width, buf.Height); err != nil {
+			return err
+		}
+		if yInverted {
+			buf.FlipVertical()
+			damage = image.Rect(damage.Min.X, buf.Height-damage.Max.Y, damage.Max.X, buf.Height-damage.Min.Y)
+		}
+		copyBufferToImage(rec.frame, buf, uint32(rec.stream.format))
+		dirty = damage.Intersect(rec.frame.Rect)
+	} else {
+		res, err := rec.src.capture()
+		if err != nil {
+			return err
+		}
+		if res.YInverted {
+			res.Buffer.FlipVertical()
+		}
+		err = rec.ensureFrame(res.Buffer.Width, res.Buffer.Height)
+		if err == nil {
+			rec.prev, rec.frame = rec.frame, rec.prev
+			if rec.frame == nil {
+				rec.frame = image.NewRGBA(rec.prev.Rect)
+			}
+			copyBufferToImage(rec.frame, res.Buffer, res.Format)
+			dirty = diffRect(rec.prev, rec.frame)
+		}
+		res.Buffer.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if rec.sink == nil {
+		return rec.begin()
+	}
+	if dirty.Empty() {
+		return nil
+	}
+
+	rec.frames++
+	return rec.sink.WriteFrame(rec.frame, dirty, time.Since(rec.start))
+}
+
+func (rec *recording) ensureFrame(w, h int) error {
+	switch {
+	case rec.frame == nil && rec.prev == nil:
+		rec.frame = image.NewRGBA(image.Rect(0, 0, w, h))
+		return nil
+	case rec.frame != nil && rec.frame.Rect.Dx() == w && rec.frame.Rect.Dy() == h:
+		return nil
+	case rec.frame == nil && rec.prev.Rect.Dx() == w && rec.prev.Rect.Dy() == h:
+		return nil
+	}
+	return errFrameSizeChanged
+}
+
+func (rec *recording) begin() error {
+	if err := os.MkdirAll(filepath.Dir(rec.path), 0o755); err != nil {
+		return err
+	}
+
+	bounds := rec.frame.Rect
+	sink, err := newFrameSink(rec.format, rec.path, bounds.Dx(), bounds.Dy(), rec.fps)
+	if err != nil {
+		return err
+	}
+	rec.sink = sink
+	rec.start = time.Now()
+
+	rec.frames++
+	if err := sink.WriteFrame(rec.frame, bounds, 0); err != nil {
+		return err
+	}
+
+	if rec.started != nil {
+		rec.started(Recording{
+			Path:      rec.path,
+			Format:    rec.format,
+			Region:    rec.src.region,
+			StartedAt: rec.start,
+		})
+	}
+	return nil
+}
+
+// diffRect returns the bounding box of pixels that differ between a and b.
+func diffRect(a, b *image.RGBA) image.Rectangle {
+	if a == nil {
+		return b.Rect
+	}
+
+	bounds := b.Rect
+	rowLen := bounds.Dx() * 4
+	rowEqual := func(y int) bool {
+		off := y * b.Stride
+		return bytes.Equal(a.Pix[off:off+rowLen], b.Pix[off:off+rowLen])
+	}
+
+	minY, maxY := bounds.Min.Y, bounds.Max.Y
+	for minY < maxY && rowEqual(minY-bounds.Min.Y) {
+		minY++
+	}
+	if minY == maxY {
+		return image.Rectangle{}
+	}
+	for maxY > minY && rowEqual(maxY-1-bounds.Min.Y) {
+		maxY--
+	}
+
+	minX, maxX := bounds.Max.X, bounds.Min.X
+	for y := minY - bounds.Min.Y; y < maxY-bounds.Min.Y; y++ {
+		off := y * b.Stride
+		for x := 0; x < bounds.Dx(); x++ {
+			i := off + x*4
+			if !bytes.Equal(a.Pix[i:i+4], b.Pix[i:i+4]) {
+				minX = min(minX, bounds.Min.X+x)
+				break
+			}
+		}
+		for x := bounds.Dx() - 1; x >= 0; x-- {
+			i := off + x*4
+			if !bytes.Equal(a.Pix[i:i+4], b.Pix[i:i+4]) {
+				maxX = max(maxX, bounds.Min.X+x+1)
+				break
+			}
+		}
+	}
+
+	return image.Rect(minX, minY, maxX, maxY)
+}
+
+// streamCapture copies frames from wlr-screencopy into one reused buffer.
+// After the first frame it uses copy_with_damage, which only completes once
+// the screen changes, so a static screen costs nothing to record.
+type streamCapture struct {
+	sc     *Screenshoter
+	src    *frameSource
+	buf    *ShmBuffer
+	pool   *client.ShmPool
+	wlBuf  *client.Buffer
+	format PixelFormat
+	primed bool
+}
+
+func (c *streamCapture) release() {
+	if c.wlBuf != nil {
+		c.wlBuf.Destroy()
+		c.wlBuf = nil
+	}
+	if c.pool != nil {
+		c.pool.Destroy()
+		c.pool = nil
+	}
+	if c.buf != nil {
+		c.buf.Close()
+		c.buf = nil
+	}
+}
+
+func (c *streamCapture) ensureBuffer(e wlr_screencopy.ZwlrScreencopyFrameV1BufferEvent) error {
+	format := PixelFormat(e.Format)
+	if c.buf != nil && c.buf.Width == int(e.Width) && c.buf.Height == int(e.Height) &&
+		c.buf.Stride == int(e.Stride) && c.format == format {
+		return nil
+	}
+	c.release()
+
+	if format.BytesPerPixel() != 4 {
+		return fmt.Errorf("unsupported pixel format %d for recording", e.Format)
+	}
+
+	buf, err := CreateShmBuffer(int(e.Width), int(e.Height), int(e.Stride))
+	if err != nil {
+		return err
+	}
+	buf.Format = format
+
+	pool, err := c.sc.shm.CreatePool(buf.Fd(), int32(buf.Size()))
+	if err != nil {
+		buf.Close()
+		return err
+	}
+	wlBuf, err := pool.CreateBuffer(0, int32(buf.Width), int32(buf.Height), int32(buf.Stride), uint32(format))
+	if err != nil {
+		pool.Destroy()
+		buf.Close()
+		return err
+	}
+
+	c.buf, c.pool, c.wlBuf, c.format = buf, pool, wlBuf, format
+	c.primed = false
+	return nil
+}
+
+func (c *streamCapture) next(ctx context.Context) (*ShmBuffer, image.Rectangle, bool, error) {
+	cursor := int32(c.sc.config.Cursor)
+	output := c.src.output
+
+	var frame *wlr_screencopy.ZwlrScreencopyFrameV1
+	var err error
+	if c.src.whole {
+		frame, err = c.sc.screencopy.CaptureOutput(cursor, output.wlOutput)
+	} else {
+		l := c.src.local
+		frame, err = c.sc.screencopy.CaptureOutputRegion(cursor, output.wlOutput, int32(l.Min.X), int32(l.Min.Y), int32(l.Dx()), int32(l.Dy()))
+	}
+	if err != nil {
+		return nil, image.Rectangle{}, false, fmt.Errorf("capture: %w", err)
+	}
+	defer frame.Destroy()
+
+	var shmEvent *wlr_screencopy.ZwlrScreencopyFrameV1BufferEvent
+	var damage image.Rectangle
+	var yInverted, ready, failed, withDamage bool
+	var setupErr error
+
+	frame.SetBufferHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1BufferEvent) {
+		shmEvent = &e
+	})
+	frame.SetFlagsHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1FlagsEvent) {
+		yInverted = (e.Flags & 1) != 0
+	})
+	frame.SetDamageHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1DamageEvent) {
+		damage = damage.Union(image.Rect(int(e.X), int(e.Y), int(e.X+e.Width), int(e.Y+e.Height)))
+	})
+	frame.SetBufferDoneHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1BufferDoneEvent) {
+		if shmEvent == nil {
+			setupErr = fmt.Errorf("compositor offered no shm buffer")
+			return
+		}
+		if setupErr = c.ensureBuffer(*shmEvent); setupErr != nil {
+			return
+		}
+		withDamage = c.primed && c.sc.screencopyVersion >= 2
+		if withDamage {
+			setupErr = frame.CopyWithDamage(c.wlBuf)
+		} else {
+			setupErr = frame.Copy(c.wlBuf)
+		}
+	})
+	frame.SetReadyHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1ReadyEvent) {
+		ready = true
+	})
+	frame.SetFailedHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1FailedEvent) {
+		failed = true
+	})
+
+	// copy_with_damage blocks until something changes, so poll with a
+	// deadline to notice a stop request on a static screen.
+	defer c.sc.ctx.SetReadDeadline(time.Time{})
+	for !ready && !failed && setupErr == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, image.Rectangle{}, false, err
+		}
+		if err := c.sc.ctx.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			return nil, image.Rectangle{}, false, err
+		}
+		if err := c.sc.ctx.Dispatch(); err != nil {
+			if isTimeout(err) {
+				continue
+			}
+			return nil, image.Rectangle{}, false, fmt.Errorf("dispatch: %w", err)
+		}
+	}
+
+	switch {
+	case setupErr != nil:
+		return nil, image.Rectangle{}, false, setupErr
+	case failed:
+		return nil, image.Rectangle{}, false, fmt.Errorf("frame capture failed")
+	}
+
+	if !withDamage || damage.Empty() {
+		damage = image.Rect(0, 0, c.buf.Width, c.buf.Height)
+	}
+	c.primed = true
+	return c.buf, damage, yInverted, nil
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
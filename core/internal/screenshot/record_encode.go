@@ -0,0 +1,509 @@
+package screenshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frameSink receives recorded frames. img is only valid during the call and
+// dirty is the area that changed since the previous frame; at is the time
+// the frame was captured, relative to the start of the recording.
+type frameSink interface {
+	WriteFrame(img *image.RGBA, dirty image.Rectangle, at time.Duration) error
+	Close(end time.Duration) error
+}
+
+func newFrameSink(format RecordFormat, path string, width, height, fps int) (frameSink, error) {
+	switch format {
+	case RecordGIF:
+		return newGIFSink(path, width, height)
+	case RecordAPNG:
+		return newAPNGSink(path)
+	default:
+		return newFFmpegSink(format, path, width, height, fps)
+	}
+}
+
+func ffmpegAvailable() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// GIF frames use a fixed 6x8x5 color cube so quantizing is a lookup rather
+// than a palette search, which keeps up with full-resolution frames.
+const (
+	gifLevelsR = 6
+	gifLevelsG = 8
+	gifLevelsB = 5
+)
+
+func gifPalette() []byte {
+	pal := make([]byte, 256*3)
+	for r := range gifLevelsR {
+		for g := range gifLevelsG {
+			for b := range gifLevelsB {
+				i := (r*gifLevelsG*gifLevelsB + g*gifLevelsB + b) * 3
+				pal[i] = uint8(r * 255 / (gifLevelsR - 1))
+				pal[i+1] = uint8(g * 255 / (gifLevelsG - 1))
+				pal[i+2] = uint8(b * 255 / (gifLevelsB - 1))
+			}
+		}
+	}
+	return pal
+}
+
+func gifIndex(r, g, b uint8) byte {
+	ri := (int(r)*(gifLevelsR-1) + 127) / 255
+	gi := (int(g)*(gifLevelsG-1) + 127) / 255
+	bi := (int(b)*(gifLevelsB-1) + 127) / 255
+	return byte(ri*gifLevelsG*gifLevelsB + gi*gifLevelsB + bi)
+}
+
+type gifFrame struct {
+	rect image.Rectangle
+	pix  []byte
+	at   time.Duration
+}
+
+// gifSink streams frames to disk. A frame's delay is only known once the
+// next one arrives, so one quantized frame is held back.
+type gifSink struct {
+	f       *os.File
+	w       *bufio.Writer
+	pending *gifFrame
+	shownCs int64
+	frames  int
+}
+
+func newGIFSink(path string, width, height int) (*gifSink, error) {
+	if width > 0xffff || height > 0xffff {
+		return nil, fmt.Errorf("frame too large for GIF (%dx%d)", width, height)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+
+	header := []byte("GIF89a")
+	header = binary.LittleEndian.AppendUint16(header, uint16(width))
+	header = binary.LittleEndian.AppendUint16(header, uint16(height))
+	// Global color table of 256 entries, 8 bits per channel.
+	header = append(header, 0xf7, 0, 0)
+	header = append(header, gifPalette()...)
+	// Loop forever.
+	header = append(header, 0x21, 0xff, 0x0b)
+	header = append(header, "NETSCAPE2.0"...)
+	header = append(header, 0x03, 0x01, 0x00, 0x00, 0x00)
+
+	if _, err := w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gifSink{f: f, w: w}, nil
+}
+
+func (s *gifSink) WriteFrame(img *image.RGBA, dirty image.Rectangle, at time.Duration) error {
+	if err := s.flush(at); err != nil {
+		return err
+	}
+
+	pix := make([]byte, 0, dirty.Dx()*dirty.Dy())
+	for y := dirty.Min.Y; y < dirty.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(dirty.Min.X, y):img.PixOffset(dirty.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			pix = append(pix, gifIndex(row[i], row[i+1], row[i+2]))
+		}
+	}
+	s.pending = &gifFrame{rect: dirty, pix: pix, at: at}
+	return nil
+}
+
+func (s *gifSink) flush(until time.Duration) error {
+	p := s.pending
+	if p == nil {
+		return nil
+	}
+	s.pending = nil
+
+	// Delays are in centiseconds; tracking the total keeps rounding from
+	// drifting over a long recording. Most viewers bump delays under 2cs.
+	delay := max(until.Milliseconds()/10-s.shownCs, 2)
+	delay = min(delay, 0xffff)
+	s.shownCs += delay
+
+	var hdr []byte
+	hdr = append(hdr, 0x21, 0xf9, 0x04, 0x04)
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(delay))
+	hdr = append(hdr, 0x00, 0x00)
+	hdr = append(hdr, 0x2c)
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(p.rect.Min.X))
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(p.rect.Min.Y))
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(p.rect.Dx()))
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(p.rect.Dy()))
+	hdr = append(hdr, 0x00, 0x08)
+	if _, err := s.w.Write(hdr); err != nil {
+		return err
+	}
+
+	bw := &gifBlockWriter{w: s.w}
+	lw := lzw.NewWriter(bw, lzw.LSB, 8)
+	if _, err := lw.Write(p.pix); err != nil {
+		return err
+	}
+	if err := lw.Close(); err != nil {
+		return err
+	}
+	if err := bw.close(); err != nil {
+		return err
+	}
+
+	s.frames++
+	return nil
+}
+
+func (s *gifSink) Close(end time.Duration) error {
+	defer s.f.Close()
+
+	if err := s.flush(end); err != nil {
+		return err
+	}
+	if err := s.w.WriteByte(0x3b); err != nil {
+		return err
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.f.Close()
+}
+
+// gifBlockWriter splits image data into the length-prefixed sub-blocks GIF
+// requires.
+type gifBlockWriter struct {
+	w   io.Writer
+	buf [256]byte
+	n   int
+}
+
+func (b *gifBlockWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		c := copy(b.buf[1+b.n:], p)
+		b.n += c
+		p = p[c:]
+		written += c
+		if b.n == 255 {
+			if err := b.emit(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (b *gifBlockWriter) emit() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.buf[0] = byte(b.n)
+	_, err := b.w.Write(b.buf[:1+b.n])
+	b.n = 0
+	return err
+}
+
+func (b *gifBlockWriter) close() error {
+	if err := b.emit(); err != nil {
+		return err
+	}
+	_, err := b.w.Write([]byte{0})
+	return err
+}
+
+type apngFrame struct {
+	rect image.Rectangle
+	data []byte
+	at   time.Duration
+}
+
+// apngSink writes an animated PNG. The frame count in acTL is patched in on
+// close, so frames can be streamed to disk as they arrive.
+type apngSink struct {
+	f         *os.File
+	actlAt    int64
+	offset    int64
+	seq       uint32
+	frames    uint32
+	shownMs   int64
+	pending   *apngFrame
+	wroteHead bool
+}
+
+func newAPNGSink(path string) (*apngSink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &apngSink{f: f}, nil
+}
+
+func (s *apngSink) WriteFrame(img *image.RGBA, dirty image.Rectangle, at time.Duration) error {
+	if err := s.flush(at); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := enc.Encode(&buf, img.SubImage(dirty)); err != nil {
+		return err
+	}
+
+	var ihdr, idat []byte
+	if err := walkPNGChunks(buf.Bytes(), func(typ string, data []byte) {
+		switch typ {
+		case "IHDR":
+			ihdr = data
+		case "IDAT":
+			idat = append(idat, data...)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if !s.wroteHead {
+		if err := s.writeHead(ihdr); err != nil {
+			return err
+		}
+	}
+
+	s.pending = &apngFrame{rect: dirty, data: idat, at: at}
+	return nil
+}
+
+func (s *apngSink) writeHead(ihdr []byte) error {
+	if _, err := s.f.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return err
+	}
+	s.offset = 8
+	if err := s.writeChunk("IHDR", ihdr); err != nil {
+		return err
+	}
+	s.actlAt = s.offset
+	if err := s.writeChunk("acTL", make([]byte, 8)); err != nil {
+		return err
+	}
+	s.wroteHead = true
+	return nil
+}
+
+func (s *apngSink) flush(until time.Duration) error {
+	p := s.pending
+	if p == nil {
+		return nil
+	}
+	s.pending = nil
+
+	delay := max(until.Milliseconds()-s.shownMs, 1)
+	delay = min(delay, 0xffff)
+	s.shownMs += delay
+
+	fctl := binary.BigEndian.AppendUint32(nil, s.seq)
+	fctl = binary.BigEndian.AppendUint32(fctl, uint32(p.rect.Dx()))
+	fctl = binary.BigEndian.AppendUint32(fctl, uint32(p.rect.Dy()))
+	fctl = binary.BigEndian.AppendUint32(fctl, uint32(p.rect.Min.X))
+	fctl = binary.BigEndian.AppendUint32(fctl, uint32(p.rect.Min.Y))
+	fctl = binary.BigEndian.AppendUint16(fctl, uint16(delay))
+	fctl = binary.BigEndian.AppendUint16(fctl, 1000)
+	// APNG_DISPOSE_OP_NONE, APNG_BLEND_OP_SOURCE
+	fctl = append(fctl, 0, 0)
+	s.seq++
+	if err := s.writeChunk("fcTL", fctl); err != nil {
+		return err
+	}
+
+	// The first frame doubles as the default image for plain PNG viewers.
+	if s.frames == 0 {
+		if err := s.writeChunk("IDAT", p.data); err != nil {
+			return err
+		}
+	} else {
+		fdat := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(p.data)), s.seq)
+		fdat = append(fdat, p.data...)
+		s.seq++
+		if err := s.writeChunk("fdAT", fdat); err != nil {
+			return err
+		}
+	}
+
+	s.frames++
+	return nil
+}
+
+func (s *apngSink) writeChunk(typ string, data []byte) error {
+	chunk := make([]byte, 0, 12+len(data))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	n, err := s.f.Write(chunk)
+	s.offset += int64(n)
+	return err
+}
+
+func (s *apngSink) Close(end time.Duration) error {
+	defer s.f.Close()
+
+	if err := s.flush(end); err != nil {
+		return err
+	}
+	if !s.wroteHead {
+		return fmt.Errorf("no frames recorded")
+	}
+	if err := s.writeChunk("IEND", nil); err != nil {
+		return err
+	}
+
+	actl := []byte("acTL")
+	actl = binary.BigEndian.AppendUint32(actl, s.frames)
+	actl = binary.BigEndian.AppendUint32(actl, 0)
+	actl = binary.BigEndian.AppendUint32(actl, crc32.ChecksumIEEE(actl))
+	if _, err := s.f.WriteAt(actl, s.actlAt+4); err != nil {
+		return err
+	}
+	return s.f.Close()
+}
+
+func walkPNGChunks(data []byte, fn func(typ string, data []byte)) error {
+	if len(data) < 8 {
+		return fmt.Errorf("short png")
+	}
+	data = data[8:]
+	for len(data) >= 12 {
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+n {
+			return fmt.Errorf("truncated png chunk")
+		}
+		fn(string(data[4:8]), data[8:8+n])
+		data = data[12+n:]
+	}
+	return nil
+}
+
+// ffmpegSink pipes raw RGBA frames to ffmpeg at a constant frame rate,
+// repeating the last frame to fill time where nothing changed.
+type ffmpegSink struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	fps     int
+	written int64
+	last    []byte
+}
+
+func ffmpegArgs(format RecordFormat, path string, width, height, fps int) []string {
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "rawvideo",
+		"-pixel_format", "rgba",
+		"-video_size", fmt.Sprintf("%dx%d", width, height),
+		"-framerate", strconv.Itoa(fps),
+		"-i", "pipe:0",
+		// yuv420p needs even dimensions.
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-pix_fmt", "yuv420p",
+	}
+	switch format {
+	case RecordWebM:
+		args = append(args, "-c:v", "libvpx-vp9", "-b:v", "0", "-crf", "32", "-deadline", "realtime", "-cpu-used", "8")
+	case RecordMP4:
+		args = append(args, "-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-movflags", "+faststart")
+	default:
+		args = append(args, "-c:v", "libx264", "-preset", "veryfast", "-crf", "23")
+	}
+	return append(args, path)
+}
+
+func newFFmpegSink(format RecordFormat, path string, width, height, fps int) (*ffmpegSink, error) {
+	if !ffmpegAvailable() {
+		return nil, fmt.Errorf("recording to %s requires ffmpeg", format)
+	}
+
+	s := &ffmpegSink{fps: fps}
+	s.cmd = exec.Command("ffmpeg", ffmpegArgs(format, path, width, height, fps)...)
+	s.cmd.Stderr = &s.stderr
+
+	stdin, err := s.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	s.stdin = stdin
+
+	if err := s.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+	return s, nil
+}
+
+func (s *ffmpegSink) slot(at time.Duration) int64 {
+	return int64(at.Seconds()*float64(s.fps) + 0.5)
+}
+
+func (s *ffmpegSink) fill(until int64) error {
+	for ; s.last != nil && s.written < until; s.written++ {
+		if _, err := s.stdin.Write(s.last); err != nil {
+			return fmt.Errorf("write frame to ffmpeg: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *ffmpegSink) WriteFrame(img *image.RGBA, dirty image.Rectangle, at time.Duration) error {
+	n := s.slot(at)
+	if err := s.fill(n); err != nil {
+		return err
+	}
+
+	if s.written <= n {
+		if _, err := s.stdin.Write(img.Pix); err != nil {
+			return fmt.Errorf("write frame to ffmpeg: %w", err)
+		}
+		s.written++
+	}
+
+	if s.last == nil {
+		s.last = make([]byte, len(img.Pix))
+	}
+	copy(s.last, img.Pix)
+	return nil
+}
+
+func (s *ffmpegSink) Close(end time.Duration) error {
+	fillErr := s.fill(s.slot(end))
+	s.stdin.Close()
+	if err := s.cmd.Wait(); err != nil {
+		return s.wrap(err)
+	}
+	return fillErr
+}
+
+// wrap prefers ffmpeg's own message. Only call it after Wait, once stderr
+// is no longer being written.
+func (s *ffmpegSink) wrap(err error) error {
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		return fmt.Errorf("ffmpeg: %s", msg)
+	}
+	return fmt.Errorf("ffmpeg: %w", err)
+}
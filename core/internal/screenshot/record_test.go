@@ -0,0 +1,144 @@
+package screenshot
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solidFrame(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, 255
+	}
+	return img
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+// writeFrames records a black frame, then a white square appearing, then
+// nothing changing for the rest of the second.
+func writeFrames(t *testing.T, sink frameSink) {
+	t.Helper()
+
+	img := solidFrame(32, 24, black)
+	require.NoError(t, sink.WriteFrame(img, img.Rect, 0))
+
+	square := image.Rect(8, 4, 16, 12)
+	fillRect(img, square, white)
+	require.NoError(t, sink.WriteFrame(img, square, 250*time.Millisecond))
+
+	require.NoError(t, sink.Close(time.Second))
+}
+
+func TestGIFSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.gif")
+	sink, err := newGIFSink(path, 32, 24)
+	require.NoError(t, err)
+	writeFrames(t, sink)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	anim, err := gif.DecodeAll(f)
+	require.NoError(t, err)
+	require.Len(t, anim.Image, 2)
+	assert.Equal(t, []int{25, 75}, anim.Delay)
+	assert.Equal(t, 0, anim.LoopCount)
+	assert.Equal(t, image.Rect(8, 4, 16, 12), anim.Image[1].Bounds())
+
+	r, g, b, _ := anim.Image[1].At(10, 6).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+}
+
+func TestAPNGSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.png")
+	sink, err := newAPNGSink(path)
+	require.NoError(t, err)
+	writeFrames(t, sink)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var numFrames uint32
+	var seqs []uint32
+	var delays []uint16
+	var sizes []image.Rectangle
+	require.NoError(t, walkPNGChunks(data, func(typ string, d []byte) {
+		switch typ {
+		case "acTL":
+			numFrames = binary.BigEndian.Uint32(d)
+		case "fcTL":
+			seqs = append(seqs, binary.BigEndian.Uint32(d))
+			w, h := binary.BigEndian.Uint32(d[4:]), binary.BigEndian.Uint32(d[8:])
+			x, y := binary.BigEndian.Uint32(d[12:]), binary.BigEndian.Uint32(d[16:])
+			sizes = append(sizes, image.Rect(int(x), int(y), int(x+w), int(y+h)))
+			delays = append(delays, binary.BigEndian.Uint16(d[20:]))
+		case "fdAT":
+			seqs = append(seqs, binary.BigEndian.Uint32(d))
+		}
+	}))
+
+	assert.Equal(t, uint32(2), numFrames)
+	assert.Equal(t, []uint32{0, 1, 2}, seqs)
+	assert.Equal(t, []uint16{250, 750}, delays)
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 32, 24), image.Rect(8, 4, 16, 12)}, sizes)
+
+	// Plain PNG decoders see the first frame, and CRCs must be intact.
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 32, 24), img.Bounds())
+}
+
+func TestDiffRect(t *testing.T) {
+	a := solidFrame(16, 16, black)
+	b := solidFrame(16, 16, black)
+	assert.True(t, diffRect(a, b).Empty())
+
+	b.SetRGBA(3, 5, white)
+	b.SetRGBA(9, 2, white)
+	assert.Equal(t, image.Rect(3, 2, 10, 6), diffRect(a, b))
+
+	assert.Equal(t, b.Rect, diffRect(nil, b))
+}
+
+func TestFFmpegArgsPadToEvenSize(t *testing.T) {
+	args := ffmpegArgs(RecordWebM, "/tmp/out.webm", 101, 57, 30)
+	assert.Contains(t, args, "101x57")
+	assert.Contains(t, args, "libvpx-vp9")
+	assert.Equal(t, "/tmp/out.webm", args[len(args)-1])
+}
+
+func TestParseRecordFormat(t *testing.T) {
+	f, err := ParseRecordFormat("APNG")
+	require.NoError(t, err)
+	assert.Equal(t, RecordAPNG, f)
+	assert.Equal(t, "png", f.Extension())
+
+	_, err = ParseRecordFormat("avi")
+	assert.Error(t, err)
+}
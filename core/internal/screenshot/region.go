@@ -2,14 +2,11 @@ package screenshot
 
 import (
 	"fmt"
-	"sync"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
-	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/keyboard_shortcuts_inhibit"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
-	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wp_viewporter"
-	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/imagecopy"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
@@ -24,18 +21,9 @@ type SelectionState struct {
 	currentY float64
 }
 
-type RenderSlot struct {
-	shm   *ShmBuffer
-	pool  *client.ShmPool
-	wlBuf *client.Buffer
-	busy  bool
-}
-
 type OutputSurface struct {
+	overlaySurface
 	output            *WaylandOutput
-	wlSurface         *client.Surface
-	layerSurf         *wlr_layer_shell.ZwlrLayerSurfaceV1
-	viewport          *wp_viewporter.WpViewport
 	screenBuf         *ShmBuffer
 	screenBufNoCursor *ShmBuffer
 	screenFormat      uint32
@@ -45,7 +33,7 @@ type OutputSurface struct {
 	yInverted         bool
 
 	// Triple-buffered render slots
-	slots      [3]*RenderSlot
+	slots      renderSlots
 	slotsReady bool
 }
 
@@ -57,37 +45,17 @@ type PreCapture struct {
 }
 
 type RegionSelector struct {
+	*overlayClient
 	screenshoter *Screenshoter
 
-	display  *client.Display
-	registry *client.Registry
-	ctx      *client.Context
-
-	compositor *client.Compositor
-	shm        *client.Shm
-	seat       *client.Seat
-	pointer    *client.Pointer
-	keyboard   *client.Keyboard
-	layerShell *wlr_layer_shell.ZwlrLayerShellV1
 	screencopy *wlr_screencopy.ZwlrScreencopyManagerV1
-	viewporter *wp_viewporter.WpViewporter
+	imageCopy  *imagecopy.Capturer
 
-	shortcutsInhibitMgr *keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitManagerV1
-	shortcutsInhibitor  *keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitorV1
-
-	outputs    map[uint32]*WaylandOutput
-	outputsMu  sync.Mutex
 	preCapture map[*WaylandOutput]*PreCapture
 
 	surfaces      []*OutputSurface
 	activeSurface *OutputSurface
 
-	// Cursor surface for crosshair
-	cursorSurface *client.Surface
-	cursorBuffer  *ShmBuffer
-	cursorWlBuf   *client.Buffer
-	cursorPool    *client.ShmPool
-
 	selection          SelectionState
 	pointerX           float64
 	pointerY           float64
@@ -104,12 +72,16 @@ type RegionSelector struct {
 }
 
 func NewRegionSelector(s *Screenshoter) *RegionSelector {
-	return &RegionSelector{
+	r := &RegionSelector{
+		overlayClient:      newOverlayClient(),
 		screenshoter:       s,
-		outputs:            make(map[uint32]*WaylandOutput),
 		preCapture:         make(map[*WaylandOutput]*PreCapture),
 		showCapturedCursor: s.config.Cursor == CursorOn,
 	}
+	r.bindExtra = r.bindCaptureGlobal
+	r.setupPointer = r.setupPointerHandlers
+	r.setupKeyboard = r.setupKeyboardHandlers
+	return r
 }
 
 func (r *RegionSelector) Run() (*CaptureResult, bool, error) {
@@ -132,18 +104,13 @@ func (r *RegionSelector) Run() (*CaptureResult, bool, error) {
 		return nil, false, fmt.Errorf("roundtrip after registry: %w", err)
 	}
 
-	switch {
-	case r.screencopy == nil:
-		return nil, false, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
-	case r.layerShell == nil:
-		return nil, false, fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
-	case r.seat == nil:
-		return nil, false, fmt.Errorf("no seat available")
-	case r.compositor == nil:
-		return nil, false, fmt.Errorf("compositor not available")
-	case r.shm == nil:
-		return nil, false, fmt.Errorf("wl_shm not available")
-	case len(r.outputs) == 0:
+	if r.screencopy == nil && !r.imageCopy.Available() {
+		return nil, false, fmt.Errorf("compositor supports neither ext-image-copy-capture-v1 nor wlr-screencopy-unstable-v1")
+	}
+	if err := r.checkGlobals(); err != nil {
+		return nil, false, err
+	}
+	if len(r.outputs) == 0 {
 		return nil, false, fmt.Errorf("no outputs available")
 	}
 
@@ -192,160 +159,32 @@ func (r *RegionSelector) Run() (*CaptureResult, bool, error) {
 }
 
 func (r *RegionSelector) connect() error {
-	display, err := client.Connect("")
-	if err != nil {
+	if err := r.overlayClient.connect(); err != nil {
 		return err
 	}
-	r.display = display
-	r.ctx = display.Context()
+	r.imageCopy = imagecopy.New(r.ctx)
 	return nil
 }
 
-func (r *RegionSelector) roundtrip() error {
-	return wlhelpers.Roundtrip(r.display, r.ctx)
-}
-
-func (r *RegionSelector) setupRegistry() error {
-	registry, err := r.display.GetRegistry()
-	if err != nil {
-		return err
+// bindCaptureGlobal binds the screen capture protocols.
+func (r *RegionSelector) bindCaptureGlobal(e client.RegistryGlobalEvent) bool {
+	if r.imageCopy.HandleGlobal(r.registry, e) {
+		return true
 	}
-	r.registry = registry
-
-	registry.SetGlobalHandler(func(e client.RegistryGlobalEvent) {
-		r.handleGlobal(e)
-	})
-
-	registry.SetGlobalRemoveHandler(func(e client.RegistryGlobalRemoveEvent) {
-		r.outputsMu.Lock()
-		delete(r.outputs, e.Name)
-		r.outputsMu.Unlock()
-	})
-
-	return nil
-}
 
-func (r *RegionSelector) handleGlobal(e client.RegistryGlobalEvent) {
-	switch e.Interface {
-	case client.CompositorInterfaceName:
-		comp := client.NewCompositor(r.ctx)
-		if err := r.registry.Bind(e.Name, e.Interface, e.Version, comp); err == nil {
-			r.compositor = comp
-		}
-
-	case client.ShmInterfaceName:
-		shm := client.NewShm(r.ctx)
-		if err := r.registry.Bind(e.Name, e.Interface, e.Version, shm); err == nil {
-			r.shm = shm
-		}
-
-	case client.SeatInterfaceName:
-		seat := client.NewSeat(r.ctx)
-		if err := r.registry.Bind(e.Name, e.Interface, e.Version, seat); err == nil {
-			r.seat = seat
-			r.setupInput()
-		}
-
-	case client.OutputInterfaceName:
-		output := client.NewOutput(r.ctx)
-		version := e.Version
-		if version > 4 {
-			version = 4
-		}
-		if err := r.registry.Bind(e.Name, e.Interface, version, output); err == nil {
-			r.outputsMu.Lock()
-			r.outputs[e.Name] = &WaylandOutput{
-				wlOutput:        output,
-				globalName:      e.Name,
-				scale:           1,
-				fractionalScale: 1.0,
-			}
-			r.outputsMu.Unlock()
-			r.setupOutputHandlers(e.Name, output)
-		}
-
-	case wlr_layer_shell.ZwlrLayerShellV1InterfaceName:
-		ls := wlr_layer_shell.NewZwlrLayerShellV1(r.ctx)
-		version := e.Version
-		if version > 4 {
-			version = 4
-		}
-		if err := r.registry.Bind(e.Name, e.Interface, version, ls); err == nil {
-			r.layerShell = ls
-		}
-
-	case wlr_screencopy.ZwlrScreencopyManagerV1InterfaceName:
-		sc := wlr_screencopy.NewZwlrScreencopyManagerV1(r.ctx)
-		version := e.Version
-		if version > 3 {
-			version = 3
-		}
-		if err := r.registry.Bind(e.Name, e.Interface, version, sc); err == nil {
-			r.screencopy = sc
-		}
-
-	case wp_viewporter.WpViewporterInterfaceName:
-		vp := wp_viewporter.NewWpViewporter(r.ctx)
-		if err := r.registry.Bind(e.Name, e.Interface, e.Version, vp); err == nil {
-			r.viewporter = vp
-		}
-
-	case keyboard_shortcuts_inhibit.ZwpKeyboardShortcutsInhibitManagerV1InterfaceName:
-		mgr := keyboard_shortcuts_inhibit.NewZwpKeyboardShortcutsInhibitManagerV1(r.ctx)
-		if err := r.registry.Bind(e.Name, e.Interface, e.Version, mgr); err == nil {
-			r.shortcutsInhibitMgr = mgr
-		}
+	if e.Interface != wlr_screencopy.ZwlrScreencopyManagerV1InterfaceName {
+		return false
 	}
-}
-
-func (r *RegionSelector) setupOutputHandlers(name uint32, output *client.Output) {
-	output.SetGeometryHandler(func(e client.OutputGeometryEvent) {
-		r.outputsMu.Lock()
-		if o, ok := r.outputs[name]; ok {
-			o.x = e.X
-			o.y = e.Y
-			o.transform = int32(e.Transform)
-		}
-		r.outputsMu.Unlock()
-	})
-
-	output.SetModeHandler(func(e client.OutputModeEvent) {
-		if e.Flags&uint32(client.OutputModeCurrent) == 0 {
-			return
-		}
-		r.outputsMu.Lock()
-		if o, ok := r.outputs[name]; ok {
-			o.width = e.Width
-			o.height = e.Height
-		}
-		r.outputsMu.Unlock()
-	})
-
-	output.SetScaleHandler(func(e client.OutputScaleEvent) {
-		r.outputsMu.Lock()
-		if o, ok := r.outputs[name]; ok {
-			o.scale = e.Factor
-			o.fractionalScale = float64(e.Factor)
-		}
-		r.outputsMu.Unlock()
-	})
-
-	output.SetNameHandler(func(e client.OutputNameEvent) {
-		r.outputsMu.Lock()
-		if o, ok := r.outputs[name]; ok {
-			o.name = e.Name
-		}
-		r.outputsMu.Unlock()
-	})
+	sc := wlr_screencopy.NewZwlrScreencopyManagerV1(r.ctx)
+	version := min(e.Version, 3)
+	if err := r.registry.Bind(e.Name, e.Interface, version, sc); err == nil {
+		r.screencopy = sc
+	}
+	return true
 }
 
 func (r *RegionSelector) preCaptureAllOutputs() error {
-	r.outputsMu.Lock()
-	outputs := make([]*WaylandOutput, 0, len(r.outputs))
-	for _, o := range r.outputs {
-		outputs = append(outputs, o)
-	}
-	r.outputsMu.Unlock()
+	outputs := r.outputList()
 
 	pending := len(outputs) * 2
 	done := make(chan struct{}, pending)
@@ -372,6 +211,24 @@ func (r *RegionSelector) preCaptureAllOutputs() error {
 }
 
 func (r *RegionSelector) preCaptureOutput(output *WaylandOutput, pc *PreCapture, withCursor bool, onReady func()) {
+	if r.imageCopy.Available() {
+		r.imageCopy.CaptureOutputAsync(r.shm, output.wlOutput, withCursor, func(buf *ShmBuffer, err error) {
+			if err != nil {
+				log.Error("image copy capture failed", "err", err)
+				onReady()
+				return
+			}
+			pc.format = uint32(buf.Format)
+			if withCursor {
+				pc.screenBuf = buf
+			} else {
+				pc.screenBufNoCursor = buf
+			}
+			onReady()
+		})
+		return
+	}
+
 	cursor := int32(0)
 	if withCursor {
 		cursor = 1
@@ -482,14 +339,7 @@ func (r *RegionSelector) preCaptureOutput(output *WaylandOutput, pc *PreCapture,
 }
 
 func (r *RegionSelector) createSurfaces() error {
-	r.outputsMu.Lock()
-	outputs := make([]*WaylandOutput, 0, len(r.outputs))
-	for _, o := range r.outputs {
-		outputs = append(outputs, o)
-	}
-	r.outputsMu.Unlock()
-
-	for _, output := range outputs {
+	for _, output := range r.outputList() {
 		os, err := r.createOutputSurface(output)
 		if err != nil {
 			return fmt.Errorf("output %s: %w", output.name, err)
@@ -500,122 +350,20 @@ func (r *RegionSelector) createSurfaces() error {
 	return nil
 }
 
-func (r *RegionSelector) createCursor() error {
-	const size = 24
-	const hotspot = size / 2
-
-	surface, err := r.compositor.CreateSurface()
-	if err != nil {
-		return fmt.Errorf("create cursor surface: %w", err)
-	}
-	r.cursorSurface = surface
-
-	buf, err := CreateShmBuffer(size, size, size*4)
-	if err != nil {
-		return fmt.Errorf("create cursor buffer: %w", err)
-	}
-	r.cursorBuffer = buf
-
-	// Draw crosshair
-	data := buf.Data()
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
-			off := (y*size + x) * 4
-			// Vertical line
-			if x >= hotspot-1 && x <= hotspot && y >= 2 && y < size-2 {
-				data[off+0] = 255 // B
-				data[off+1] = 255 // G
-				data[off+2] = 255 // R
-				data[off+3] = 255 // A
-				continue
-			}
-			// Horizontal line
-			if y >= hotspot-1 && y <= hotspot && x >= 2 && x < size-2 {
-				data[off+0] = 255
-				data[off+1] = 255
-				data[off+2] = 255
-				data[off+3] = 255
-				continue
-			}
-			// Transparent
-			data[off+0] = 0
-			data[off+1] = 0
-			data[off+2] = 0
-			data[off+3] = 0
-		}
-	}
-
-	pool, err := r.shm.CreatePool(buf.Fd(), int32(buf.Size()))
-	if err != nil {
-		return fmt.Errorf("create cursor pool: %w", err)
-	}
-	r.cursorPool = pool
-
-	wlBuf, err := pool.CreateBuffer(0, size, size, size*4, uint32(FormatARGB8888))
-	if err != nil {
-		return fmt.Errorf("create cursor wl_buffer: %w", err)
-	}
-	r.cursorWlBuf = wlBuf
-
-	if err := surface.Attach(wlBuf, 0, 0); err != nil {
-		return fmt.Errorf("attach cursor: %w", err)
-	}
-	if err := surface.Damage(0, 0, size, size); err != nil {
-		return fmt.Errorf("damage cursor: %w", err)
-	}
-	if err := surface.Commit(); err != nil {
-		return fmt.Errorf("commit cursor: %w", err)
-	}
-
-	return nil
-}
-
 func (r *RegionSelector) createOutputSurface(output *WaylandOutput) (*OutputSurface, error) {
-	surface, err := r.compositor.CreateSurface()
+	surf, err := r.createOverlaySurface(output, "dms-screenshot")
 	if err != nil {
-		return nil, fmt.Errorf("create surface: %w", err)
-	}
-
-	layerSurf, err := r.layerShell.GetLayerSurface(
-		surface,
-		output.wlOutput,
-		uint32(wlr_layer_shell.ZwlrLayerShellV1LayerOverlay),
-		"dms-screenshot",
-	)
-	if err != nil {
-		return nil, fmt.Errorf("get layer surface: %w", err)
+		surf.destroy()
+		return nil, err
 	}
 
 	os := &OutputSurface{
-		output:    output,
-		wlSurface: surface,
-		layerSurf: layerSurf,
-	}
-
-	if r.viewporter != nil {
-		vp, err := r.viewporter.GetViewport(surface)
-		if err == nil {
-			os.viewport = vp
-		}
-	}
-
-	if err := layerSurf.SetAnchor(
-		uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorTop) |
-			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorBottom) |
-			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorLeft) |
-			uint32(wlr_layer_shell.ZwlrLayerSurfaceV1AnchorRight),
-	); err != nil {
-		return nil, fmt.Errorf("set anchor: %w", err)
-	}
-	if err := layerSurf.SetExclusiveZone(-1); err != nil {
-		return nil, fmt.Errorf("set exclusive zone: %w", err)
-	}
-	if err := layerSurf.SetKeyboardInteractivity(uint32(wlr_layer_shell.ZwlrLayerSurfaceV1KeyboardInteractivityExclusive)); err != nil {
-		return nil, fmt.Errorf("set keyboard interactivity: %w", err)
+		overlaySurface: surf,
+		output:         output,
 	}
 
-	layerSurf.SetConfigureHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ConfigureEvent) {
-		if err := layerSurf.AckConfigure(e.Serial); err != nil {
+	os.layerSurf.SetConfigureHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ConfigureEvent) {
+		if err := os.layerSurf.AckConfigure(e.Serial); err != nil {
 			log.Error("ack configure failed", "err", err)
 			return
 		}
@@ -623,31 +371,22 @@ func (r *RegionSelector) createOutputSurface(output *WaylandOutput) (*OutputSurf
 		os.logicalH = int(e.Height)
 		os.configured = true
 		r.captureForSurface(os)
-		r.ensureShortcutsInhibitor(os)
+		r.ensureShortcutsInhibitor(os.wlSurface)
 	})
 
-	layerSurf.SetClosedHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ClosedEvent) {
+	os.layerSurf.SetClosedHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ClosedEvent) {
 		r.running = false
 		r.cancelled = true
 	})
 
-	if err := surface.Commit(); err != nil {
+	if err := os.wlSurface.Commit(); err != nil {
+		os.destroy()
 		return nil, fmt.Errorf("surface commit: %w", err)
 	}
 
 	return os, nil
 }
 
-func (r *RegionSelector) ensureShortcutsInhibitor(os *OutputSurface) {
-	if r.shortcutsInhibitMgr == nil || r.seat == nil || r.shortcutsInhibitor != nil {
-		return
-	}
-	inhibitor, err := r.shortcutsInhibitMgr.InhibitShortcuts(os.wlSurface, r.seat)
-	if err == nil {
-		r.shortcutsInhibitor = inhibitor
-	}
-}
-
 func (r *RegionSelector) captureForSurface(os *OutputSurface) {
 	pc := r.preCapture[os.output]
 	if pc == nil {
@@ -672,51 +411,7 @@ func (r *RegionSelector) initRenderBuffer(os *OutputSurface) {
 	if os.screenBuf == nil {
 		return
 	}
-
-	for i := 0; i < 3; i++ {
-		slot := &RenderSlot{}
-
-		buf, err := CreateShmBuffer(os.screenBuf.Width, os.screenBuf.Height, os.screenBuf.Stride)
-		if err != nil {
-			log.Error("create render slot buffer failed", "err", err)
-			return
-		}
-		slot.shm = buf
-
-		pool, err := r.shm.CreatePool(buf.Fd(), int32(buf.Size()))
-		if err != nil {
-			log.Error("create render slot pool failed", "err", err)
-			buf.Close()
-			return
-		}
-		slot.pool = pool
-
-		wlBuf, err := pool.CreateBuffer(0, int32(buf.Width), int32(buf.Height), int32(buf.Stride), os.screenFormat)
-		if err != nil {
-			log.Error("create render slot wl_buffer failed", "err", err)
-			pool.Destroy()
-			buf.Close()
-			return
-		}
-		slot.wlBuf = wlBuf
-
-		slotRef := slot
-		wlBuf.SetReleaseHandler(func(e client.BufferReleaseEvent) {
-			slotRef.busy = false
-		})
-
-		os.slots[i] = slot
-	}
-	os.slotsReady = true
-}
-
-func (os *OutputSurface) acquireFreeSlot() *RenderSlot {
-	for _, slot := range os.slots {
-		if slot != nil && !slot.busy {
-			return slot
-		}
-	}
-	return nil
+	os.slotsReady = r.createRenderSlots(&os.slots, os.screenBuf.Width, os.screenBuf.Height, os.screenBuf.Stride, os.screenFormat, nil)
 }
 
 func (r *RegionSelector) applyPreSelection(os *OutputSurface) {
@@ -759,7 +454,7 @@ func (r *RegionSelector) redrawSurface(os *OutputSurface) {
 		return
 	}
 
-	slot := os.acquireFreeSlot()
+	slot := os.slots.acquire()
 	if slot == nil {
 		return
 	}
@@ -769,64 +464,15 @@ func (r *RegionSelector) redrawSurface(os *OutputSurface) {
 	// Draw overlay (dimming + selection) into this slot
 	r.drawOverlay(os, slot.shm)
 
-	if os.viewport != nil {
-		_ = os.wlSurface.SetBufferScale(1)
-		_ = os.viewport.SetSource(0, 0, float64(slot.shm.Width), float64(slot.shm.Height))
-		_ = os.viewport.SetDestination(int32(os.logicalW), int32(os.logicalH))
-	} else {
-		bufferScale := os.output.scale
-		if bufferScale <= 0 {
-			bufferScale = 1
-		}
-		_ = os.wlSurface.SetBufferScale(bufferScale)
-	}
-
-	_ = os.wlSurface.Attach(slot.wlBuf, 0, 0)
-	_ = os.wlSurface.Damage(0, 0, int32(os.logicalW), int32(os.logicalH))
-	_ = os.wlSurface.Commit()
-
-	// Mark this slot as busy until compositor releases it
-	slot.busy = true
+	os.present(slot, os.logicalW, os.logicalH, os.output.scale)
 }
 
 func (r *RegionSelector) cleanup() {
-	if r.cursorWlBuf != nil {
-		r.cursorWlBuf.Destroy()
-	}
-	if r.cursorPool != nil {
-		r.cursorPool.Destroy()
-	}
-	if r.cursorSurface != nil {
-		r.cursorSurface.Destroy()
-	}
-	if r.cursorBuffer != nil {
-		r.cursorBuffer.Close()
-	}
+	r.destroyCursor()
 
 	for _, os := range r.surfaces {
-		for _, slot := range os.slots {
-			if slot == nil {
-				continue
-			}
-			if slot.wlBuf != nil {
-				slot.wlBuf.Destroy()
-			}
-			if slot.pool != nil {
-				slot.pool.Destroy()
-			}
-			if slot.shm != nil {
-				slot.shm.Close()
-			}
-		}
-		if os.viewport != nil {
-			os.viewport.Destroy()
-		}
-		if os.layerSurf != nil {
-			os.layerSurf.Destroy()
-		}
-		if os.wlSurface != nil {
-			os.wlSurface.Destroy()
-		}
+		os.slots.destroy()
+		os.destroy()
 		if os.screenBuf != nil {
 			os.screenBuf.Close()
 		}
@@ -835,25 +481,11 @@ func (r *RegionSelector) cleanup() {
 		}
 	}
 
-	if r.shortcutsInhibitor != nil {
-		_ = r.shortcutsInhibitor.Destroy()
-	}
-	if r.shortcutsInhibitMgr != nil {
-		_ = r.shortcutsInhibitMgr.Destroy()
-	}
-	if r.viewporter != nil {
-		r.viewporter.Destroy()
-	}
 	if r.screencopy != nil {
 		r.screencopy.Destroy()
 	}
-	if r.pointer != nil {
-		r.pointer.Release()
-	}
-	if r.keyboard != nil {
-		r.keyboard.Release()
-	}
-	if r.display != nil {
-		r.ctx.Close()
+	if r.imageCopy != nil {
+		r.imageCopy.Destroy()
 	}
+	r.close()
 }
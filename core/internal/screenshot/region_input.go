@@ -4,27 +4,6 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
-func (r *RegionSelector) setupInput() {
-	if r.seat == nil {
-		return
-	}
-
-	r.seat.SetCapabilitiesHandler(func(e client.SeatCapabilitiesEvent) {
-		if e.Capabilities&uint32(client.SeatCapabilityPointer) != 0 && r.pointer == nil {
-			if pointer, err := r.seat.GetPointer(); err == nil {
-				r.pointer = pointer
-				r.setupPointerHandlers()
-			}
-		}
-		if e.Capabilities&uint32(client.SeatCapabilityKeyboard) != 0 && r.keyboard == nil {
-			if keyboard, err := r.seat.GetKeyboard(); err == nil {
-				r.keyboard = keyboard
-				r.setupKeyboardHandlers()
-			}
-		}
-	})
-}
-
 func (r *RegionSelector) setupPointerHandlers() {
 	r.pointer.SetEnterHandler(func(e client.PointerEnterEvent) {
 		if r.cursorSurface != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
 	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/imagecopy"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
@@ -37,9 +38,11 @@ type Screenshoter struct {
 	registry *client.Registry
 	ctx      *client.Context
 
-	compositor *client.Compositor
-	shm        *client.Shm
-	screencopy *wlr_screencopy.ZwlrScreencopyManagerV1
+	compositor        *client.Compositor
+	shm               *client.Shm
+	screencopy        *wlr_screencopy.ZwlrScreencopyManagerV1
+	screencopyVersion uint32
+	imageCopy         *imagecopy.Capturer
 
 	outputs   map[uint32]*WaylandOutput
 	outputsMu sync.Mutex
@@ -66,8 +69,8 @@ func (s *Screenshoter) Run() (*CaptureResult, error) {
 		return nil, fmt.Errorf("roundtrip: %w", err)
 	}
 
-	if s.screencopy == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+	if s.screencopy == nil && !s.imageCopy.Available() {
+		return nil, fmt.Errorf("compositor supports neither ext-image-copy-capture-v1 nor wlr-screencopy-unstable-v1")
 	}
 
 	if err := s.roundtrip(); err != nil {
@@ -130,6 +133,13 @@ func (s *Screenshoter) captureRegion() (*CaptureResult, error) {
 }
 
 func (s *Screenshoter) captureWindow() (*CaptureResult, error) {
+	if s.imageCopy.CanCaptureToplevels() {
+		if toplevel := s.imageCopy.ActiveToplevel(); toplevel != nil {
+			return s.captureToplevel(toplevel)
+		}
+		log.Debug("active toplevel unknown, falling back to compositor IPC")
+	}
+
 	geom, err := GetActiveWindow()
 	if err != nil {
 		return nil, err
@@ -163,6 +173,21 @@ func (s *Screenshoter) captureWindow() (*CaptureResult, error) {
 	}
 }
 
+// captureToplevel copies just the window's own surfaces, so overlapping
+// windows and popups don't end up in the shot.
+func (s *Screenshoter) captureToplevel(toplevel *imagecopy.Toplevel) (*CaptureResult, error) {
+	buf, err := s.imageCopy.CaptureToplevel(s.shm, toplevel, s.config.Cursor == CursorOn)
+	if err != nil {
+		return nil, fmt.Errorf("capture window: %w", err)
+	}
+
+	return &CaptureResult{
+		Buffer: buf,
+		Region: Region{Width: int32(buf.Width), Height: int32(buf.Height)},
+		Format: uint32(buf.Format),
+	}, nil
+}
+
 func (s *Screenshoter) captureDWLWindow(output *WaylandOutput, region Region, geom *WindowGeometry) (*CaptureResult, error) {
 	result, err := s.captureWholeOutput(output)
 	if err != nil {
@@ -485,6 +510,10 @@ func (s *Screenshoter) blitBufferScaled(dst, src *ShmBuffer, dstX, dstY, dstW, d
 }
 
 func (s *Screenshoter) captureWholeOutput(output *WaylandOutput) (*CaptureResult, error) {
+	if s.imageCopy.Available() {
+		return s.captureWholeOutputExt(output)
+	}
+
 	cursor := int32(s.config.Cursor)
 
 	frame, err := s.screencopy.CaptureOutput(cursor, output.wlOutput)
@@ -530,6 +559,27 @@ func (s *Screenshoter) captureWholeOutput(output *WaylandOutput) (*CaptureResult
 	return result, nil
 }
 
+// captureWholeOutputExt is captureWholeOutput over ext-image-copy-capture,
+// which hands back frames already flipped and rotated upright.
+func (s *Screenshoter) captureWholeOutputExt(output *WaylandOutput) (*CaptureResult, error) {
+	buf, err := s.imageCopy.CaptureOutput(s.shm, output.wlOutput, s.config.Cursor == CursorOn)
+	if err != nil {
+		return nil, fmt.Errorf("capture output: %w", err)
+	}
+
+	return &CaptureResult{
+		Buffer: buf,
+		Region: Region{
+			X:      output.x,
+			Y:      output.y,
+			Width:  int32(buf.Width),
+			Height: int32(buf.Height),
+			Output: output.name,
+		},
+		Format: uint32(buf.Format),
+	}, nil
+}
+
 func (s *Screenshoter) captureAndCrop(output *WaylandOutput, region Region) (*CaptureResult, error) {
 	result, err := s.captureWholeOutput(output)
 	if err != nil {
@@ -608,10 +658,25 @@ func (s *Screenshoter) captureAndCrop(output *WaylandOutput, region Region) (*Ca
 }
 
 func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Region) (*CaptureResult, error) {
-	if output.transform != TransformNormal {
+	// ext-image-copy-capture has no region requests, so crop a full frame.
+	if output.transform != TransformNormal || s.imageCopy.Available() {
 		return s.captureRegionOnTransformedOutput(output, region)
 	}
 
+	localX, localY, w, h := s.regionOnOutput(output, region)
+	cursor := int32(s.config.Cursor)
+
+	frame, err := s.screencopy.CaptureOutputRegion(cursor, output.wlOutput, localX, localY, w, h)
+	if err != nil {
+		return nil, fmt.Errorf("capture region: %w", err)
+	}
+
+	return s.processFrame(frame, region)
+}
+
+// regionOnOutput converts a logical region to the buffer-local box that
+// wlr-screencopy expects for an untransformed output.
+func (s *Screenshoter) regionOnOutput(output *WaylandOutput, region Region) (localX, localY, w, h int32) {
 	scale := output.fractionalScale
 	if scale <= 0 && DetectCompositor() == CompositorHyprland {
 		scale = GetHyprlandMonitorScale(output.name)
@@ -623,10 +688,10 @@ func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Regio
 		scale = 1.0
 	}
 
-	localX := int32(float64(region.X-output.x) * scale)
-	localY := int32(float64(region.Y-output.y) * scale)
-	w := int32(float64(region.Width) * scale)
-	h := int32(float64(region.Height) * scale)
+	localX = int32(float64(region.X-output.x) * scale)
+	localY = int32(float64(region.Y-output.y) * scale)
+	w = int32(float64(region.Width) * scale)
+	h = int32(float64(region.Height) * scale)
 
 	if DetectCompositor() == CompositorDWL {
 		scaledOutW := int32(float64(output.width) * scale)
@@ -653,14 +718,7 @@ func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Regio
 		}
 	}
 
-	cursor := int32(s.config.Cursor)
-
-	frame, err := s.screencopy.CaptureOutputRegion(cursor, output.wlOutput, localX, localY, w, h)
-	if err != nil {
-		return nil, fmt.Errorf("capture region: %w", err)
-	}
-
-	return s.processFrame(frame, region)
+	return localX, localY, w, h
 }
 
 func (s *Screenshoter) captureRegionOnTransformedOutput(output *WaylandOutput, region Region) (*CaptureResult, error) {
@@ -909,6 +967,7 @@ func (s *Screenshoter) connect() error {
 	}
 	s.display = display
 	s.ctx = display.Context()
+	s.imageCopy = imagecopy.New(s.ctx)
 	return nil
 }
 
@@ -937,6 +996,10 @@ func (s *Screenshoter) setupRegistry() error {
 }
 
 func (s *Screenshoter) handleGlobal(e client.RegistryGlobalEvent) {
+	if s.imageCopy.HandleGlobal(s.registry, e) {
+		return
+	}
+
 	switch e.Interface {
 	case client.CompositorInterfaceName:
 		comp := client.NewCompositor(s.ctx)
@@ -976,6 +1039,7 @@ func (s *Screenshoter) handleGlobal(e client.RegistryGlobalEvent) {
 		}
 		if err := s.registry.Bind(e.Name, e.Interface, version, sc); err == nil {
 			s.screencopy = sc
+			s.screencopyVersion = version
 		}
 	}
 }
@@ -1023,6 +1087,9 @@ func (s *Screenshoter) cleanup() {
 	if s.screencopy != nil {
 		s.screencopy.Destroy()
 	}
+	if s.imageCopy != nil {
+		s.imageCopy.Destroy()
+	}
 	if s.display != nil {
 		s.ctx.Close()
 	}
@@ -1,5 +1,11 @@
 package screenshot
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Mode int
 
 const (
@@ -11,14 +17,119 @@ const (
 	ModeLastRegion
 )
 
+func (m Mode) String() string {
+	switch m {
+	case ModeWindow:
+		return "window"
+	case ModeFullScreen:
+		return "full"
+	case ModeAllScreens:
+		return "all"
+	case ModeOutput:
+		return "output"
+	case ModeLastRegion:
+		return "last"
+	default:
+		return "region"
+	}
+}
+
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "region":
+		return ModeRegion, nil
+	case "window":
+		return ModeWindow, nil
+	case "full":
+		return ModeFullScreen, nil
+	case "all":
+		return ModeAllScreens, nil
+	case "output":
+		return ModeOutput, nil
+	case "last":
+		return ModeLastRegion, nil
+	}
+	return ModeRegion, fmt.Errorf("unknown screenshot mode %q (region, window, full, all, output, last)", s)
+}
+
 type Format int
 
 const (
 	FormatPNG Format = iota
 	FormatJPEG
 	FormatPPM
+	FormatWebP
+	FormatQOI
+	FormatAVIF
+	FormatJXL
 )
 
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "png":
+		return FormatPNG, nil
+	case "jpg", "jpeg":
+		return FormatJPEG, nil
+	case "ppm":
+		return FormatPPM, nil
+	case "webp":
+		return FormatWebP, nil
+	case "qoi":
+		return FormatQOI, nil
+	case "avif":
+		return FormatAVIF, nil
+	case "jxl":
+		return FormatJXL, nil
+	}
+	return FormatPNG, fmt.Errorf("unknown image format %q (png, jpg, ppm, webp, qoi, avif, jxl)", s)
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatJPEG:
+		return "jpeg"
+	case FormatPPM:
+		return "ppm"
+	case FormatWebP:
+		return "webp"
+	case FormatQOI:
+		return "qoi"
+	case FormatAVIF:
+		return "avif"
+	case FormatJXL:
+		return "jxl"
+	default:
+		return "png"
+	}
+}
+
+func (f Format) Extension() string {
+	if f == FormatJPEG {
+		return "jpg"
+	}
+	return f.String()
+}
+
+func (f Format) MimeType() string {
+	switch f {
+	case FormatPPM:
+		return "image/x-portable-pixmap"
+	default:
+		return "image/" + f.String()
+	}
+}
+
+// Lossy reports whether quality applies to the format when lossless
+// encoding isn't requested.
+func (f Format) Lossy() bool {
+	switch f {
+	case FormatJPEG, FormatWebP, FormatAVIF, FormatJXL:
+		return true
+	default:
+		return false
+	}
+}
+
 type CursorMode int
 
 const (
@@ -56,12 +167,25 @@ type Config struct {
 	Reset      bool
 	Format     Format
 	Quality    int
+	Lossless   bool
+	MaxSize    int64
+	Metadata   bool
 	OutputDir  string
 	Filename   string
+	Template   string
 	Clipboard  bool
 	SaveFile   bool
 	Notify     bool
 	Stdout     bool
+	Annotate   bool
+	History    bool
+
+	// Delay waits before the first capture, with an on-screen countdown
+	// when Countdown is set. Repeat captures are taken Interval apart.
+	Delay     time.Duration
+	Countdown bool
+	Repeat    int
+	Interval  time.Duration
 }
 
 func DefaultConfig() Config {
@@ -77,5 +201,9 @@ func DefaultConfig() Config {
 		Clipboard: true,
 		SaveFile:  true,
 		Notify:    true,
+		History:   true,
+		Countdown: true,
+		Repeat:    1,
+		Interval:  time.Second,
 	}
 }
@@ -0,0 +1,267 @@
+package secretservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName         = "org.freedesktop.secrets"
+	servicePath     = "/org/freedesktop/secrets"
+	defaultAlias    = "/org/freedesktop/secrets/aliases/default"
+	serviceIface    = "org.freedesktop.Secret.Service"
+	collectionIface = "org.freedesktop.Secret.Collection"
+	itemIface       = "org.freedesktop.Secret.Item"
+	promptIface     = "org.freedesktop.Secret.Prompt"
+
+	promptTimeout = 120 * time.Second
+)
+
+// Session is a plain (unencrypted transport) session with the Secret
+// Service. Secrets only travel over the user's session bus.
+type Session struct {
+	conn        *dbus.Conn
+	svc         dbus.BusObject
+	sessionPath dbus.ObjectPath
+}
+
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+func Open() (*Session, error) {
+	c, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	svc := c.Object(busName, dbus.ObjectPath(servicePath))
+
+	var sessionPath dbus.ObjectPath
+	call := svc.Call(serviceIface+".OpenSession", 0, "plain", dbus.MakeVariant(""))
+	if call.Err != nil {
+		c.Close()
+		return nil, call.Err
+	}
+	if err := call.Store(new(dbus.Variant), &sessionPath); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &Session{
+		conn:        c,
+		svc:         svc,
+		sessionPath: sessionPath,
+	}, nil
+}
+
+func (s *Session) Close() {
+	s.conn.Close()
+}
+
+// Unlock unlocks items or collections, showing the keyring's unlock prompt
+// if needed.
+func (s *Session) Unlock(objects []dbus.ObjectPath) error {
+	var prompt dbus.ObjectPath
+	var unlocked []dbus.ObjectPath
+	call := s.svc.Call(serviceIface+".Unlock", 0, objects)
+	if call.Err != nil {
+		return call.Err
+	}
+	if err := call.Store(&unlocked, &prompt); err != nil {
+		return err
+	}
+	return s.runPrompt(prompt)
+}
+
+// runPrompt shows prompt and waits for it to complete. A "/" prompt means
+// none was needed. Dismissing the prompt is not an error here; the caller's
+// next operation on the locked object fails instead.
+func (s *Session) runPrompt(prompt dbus.ObjectPath) error {
+	_, err := s.runPromptResult(prompt)
+	return err
+}
+
+func (s *Session) runPromptResult(prompt dbus.ObjectPath) (dbus.Variant, error) {
+	if prompt == "/" || prompt == "" {
+		return dbus.Variant{}, nil
+	}
+
+	if err := s.conn.AddMatchSignal(
+		dbus.WithMatchInterface(promptIface),
+		dbus.WithMatchObjectPath(prompt),
+	); err != nil {
+		return dbus.Variant{}, err
+	}
+	defer s.conn.RemoveMatchSignal(
+		dbus.WithMatchInterface(promptIface),
+		dbus.WithMatchObjectPath(prompt),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), promptTimeout)
+	defer cancel()
+
+	ch := make(chan *dbus.Signal, 10)
+	s.conn.Signal(ch)
+
+	var result dbus.Variant
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer s.conn.RemoveSignal(ch)
+		for {
+			select {
+			case v := <-ch:
+				if v.Path != prompt || v.Name != promptIface+".Completed" {
+					continue
+				}
+				if len(v.Body) < 2 {
+					log.Debugf("[SecretService] Prompt Completed signal has %d body element(s), expected >= 2", len(v.Body))
+				} else {
+					if dismissed, ok := v.Body[0].(bool); ok && dismissed {
+						log.Debugf("[SecretService] Prompt dismissed by user")
+					}
+					result, _ = v.Body[1].(dbus.Variant)
+				}
+				cancel()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	promptObj := s.conn.Object(busName, prompt)
+	if err := promptObj.Call(promptIface+".Prompt", 0, "").Store(); err != nil {
+		cancel()
+		<-done
+		return dbus.Variant{}, err
+	}
+
+	<-ctx.Done()
+	<-done
+	if ctx.Err() == context.DeadlineExceeded {
+		promptObj.Call(promptIface+".Dismiss", 0)
+		return dbus.Variant{}, fmt.Errorf("timed out waiting for secret service prompt")
+	}
+	return result, nil
+}
+
+// Search returns the items matching attrs, unlocking locked matches when
+// there are no unlocked ones.
+func (s *Session) Search(attrs map[string]string) ([]dbus.ObjectPath, error) {
+	var unlocked []dbus.ObjectPath
+	var locked []dbus.ObjectPath
+	call := s.svc.Call(serviceIface+".SearchItems", 0, attrs)
+	if call.Err != nil {
+		return nil, call.Err
+	}
+	if err := call.Store(&unlocked, &locked); err != nil {
+		return nil, err
+	}
+
+	if len(unlocked) == 0 && len(locked) > 0 {
+		log.Debugf("[SecretService] Attempting to unlock %d locked item(s)", len(locked))
+		if err := s.Unlock(locked); err != nil {
+			return nil, err
+		}
+		unlocked = locked
+	}
+	return unlocked, nil
+}
+
+func (s *Session) GetSecret(item dbus.ObjectPath) ([]byte, error) {
+	var sec secret
+	call := s.conn.Object(busName, item).Call(itemIface+".GetSecret", 0, s.sessionPath)
+	if call.Err != nil {
+		return nil, call.Err
+	}
+	if err := call.Store(&sec); err != nil {
+		return nil, err
+	}
+	return sec.Value, nil
+}
+
+// Lookup returns the value of the first item matching attrs, or nil when
+// there is none.
+func (s *Session) Lookup(attrs map[string]string) ([]byte, error) {
+	items, err := s.Search(attrs)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return s.GetSecret(items[0])
+}
+
+// Store saves value in the default collection, replacing any item with the
+// same attributes.
+func (s *Session) Store(label string, attrs map[string]string, value []byte) (dbus.ObjectPath, error) {
+	collection := dbus.ObjectPath(defaultAlias)
+	if err := s.Unlock([]dbus.ObjectPath{collection}); err != nil {
+		return "", err
+	}
+
+	props := map[string]dbus.Variant{
+		itemIface + ".Label":      dbus.MakeVariant(label),
+		itemIface + ".Attributes": dbus.MakeVariant(attrs),
+	}
+	sec := secret{
+		Session:     s.sessionPath,
+		Parameters:  []byte{},
+		Value:       value,
+		ContentType: "application/octet-stream",
+	}
+
+	var item, prompt dbus.ObjectPath
+	call := s.conn.Object(busName, collection).Call(collectionIface+".CreateItem", 0, props, sec, true)
+	if call.Err != nil {
+		return "", call.Err
+	}
+	if err := call.Store(&item, &prompt); err != nil {
+		return "", err
+	}
+	if item != "/" && item != "" {
+		return item, nil
+	}
+
+	result, err := s.runPromptResult(prompt)
+	if err != nil {
+		return "", err
+	}
+	created, ok := result.Value().(dbus.ObjectPath)
+	if !ok || created == "/" || created == "" {
+		return "", fmt.Errorf("secret service did not create item")
+	}
+	return created, nil
+}
+
+// Delete removes every item matching attrs.
+func (s *Session) Delete(attrs map[string]string) error {
+	items, err := s.Search(attrs)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		call := s.conn.Object(busName, item).Call(itemIface+".Delete", 0)
+		if call.Err != nil {
+			return call.Err
+		}
+		if err := call.Store(&prompt); err != nil {
+			return err
+		}
+		if err := s.runPrompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package audio
+
+import "time"
+
+const (
+	// protocolVersion is the native protocol version we speak. Servers
+	// negotiate down to the lower of the two, pipewire-pulse reports 35.
+	protocolVersion     = 32
+	protocolVersionMask = 0x0000FFFF
+
+	cookieLength     = 256
+	descriptorLength = 20
+	controlChannel   = 0xFFFFFFFF
+	maxPacketLength  = 16 * 1024 * 1024
+	invalidIndex     = 0xFFFFFFFF
+
+	requestTimeout = 5 * time.Second
+)
+
+// reconnectDelay is how long to wait between attempts after the server
+// goes away.
+var reconnectDelay = 2 * time.Second
+
+// Native protocol commands, from pulsecore/native-common.h.
+const (
+	commandError                   = 0
+	commandReply                   = 2
+	commandAuth                    = 8
+	commandSetClientName           = 9
+	commandGetServerInfo           = 20
+	commandGetSinkInfoList         = 22
+	commandGetSourceInfoList       = 24
+	commandGetSinkInputInfoList    = 30
+	commandGetSourceOutputInfoList = 32
+	commandSubscribe               = 35
+	commandSetSinkVolume           = 36
+	commandSetSinkInputVolume      = 37
+	commandSetSourceVolume         = 38
+	commandSetSinkMute             = 39
+	commandSetSourceMute           = 40
+	commandSetDefaultSink          = 44
+	commandSetDefaultSource        = 45
+	commandSubscribeEvent          = 66
+	commandMoveSinkInput           = 67
+	commandMoveSourceOutput        = 68
+	commandSetSinkInputMute        = 69
+	commandSetSourceOutputVolume   = 98
+	commandSetSourceOutputMute     = 99
+)
+
+// Tagstruct value tags.
+const (
+	tagString       = 't'
+	tagStringNull   = 'N'
+	tagU32          = 'L'
+	tagU8           = 'B'
+	tagSampleSpec   = 'a'
+	tagArbitrary    = 'x'
+	tagBooleanTrue  = '1'
+	tagBooleanFalse = '0'
+	tagUsec         = 'U'
+	tagChannelMap   = 'm'
+	tagCVolume      = 'v'
+	tagPropList     = 'P'
+	tagVolume       = 'V'
+	tagFormatInfo   = 'f'
+)
+
+const (
+	subscriptionMaskSink         = 0x0001
+	subscriptionMaskSource       = 0x0002
+	subscriptionMaskSinkInput    = 0x0004
+	subscriptionMaskSourceOutput = 0x0008
+	subscriptionMaskServer       = 0x0080
+	subscriptionMaskCard         = 0x0200
+
+	subscriptionMask = subscriptionMaskSink | subscriptionMaskSource |
+		subscriptionMaskSinkInput | subscriptionMaskSourceOutput |
+		subscriptionMaskServer | subscriptionMaskCard
+)
+
+const (
+	volumeNorm = 0x10000
+	// MaxVolume matches the 150% ceiling pavucontrol and the shell allow.
+	MaxVolume = 1.5
+)
+
+const (
+	TypeSink         = "sink"
+	TypeSource       = "source"
+	TypeSinkInput    = "sinkInput"
+	TypeSourceOutput = "sourceOutput"
+)
+
+var deviceStates = map[uint32]string{
+	0: "running",
+	1: "idle",
+	2: "suspended",
+}
+
+var portAvailability = map[uint32]string{
+	0: "unknown",
+	1: "no",
+	2: "yes",
+}
+
+// errorNames covers the pa_error_code_t values a server is likely to send
+// back for the commands we issue.
+var errorNames = map[uint32]string{
+	1:  "access denied",
+	2:  "unknown command",
+	3:  "invalid argument",
+	4:  "entity exists",
+	5:  "no such entity",
+	7:  "protocol error",
+	8:  "timeout",
+	9:  "no authentication key",
+	10: "internal error",
+	15: "bad state",
+	17: "incompatible protocol version",
+	19: "not supported",
+	23: "not implemented",
+}
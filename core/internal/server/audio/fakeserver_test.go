@@ -0,0 +1,447 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+)
+
+// fakeServer is a pulse native protocol stand-in serving a fixed graph of
+// devices and streams over net.Pipe connections.
+type fakeServer struct {
+	mu            sync.Mutex
+	version       uint32
+	authError     uint32
+	defaultSink   string
+	defaultSource string
+	sinks         []*fakeDevice
+	sources       []*fakeDevice
+	sinkInputs    []*fakeStream
+	sourceOutputs []*fakeStream
+	commands      []uint32
+	conns         []net.Conn
+	dials         int
+}
+
+type fakeDevice struct {
+	index   uint32
+	name    string
+	desc    string
+	volumes []uint32
+	mute    bool
+	monitor string
+	ports   []Port
+	port    string
+}
+
+type fakeStream struct {
+	index    uint32
+	name     string
+	app      string
+	device   uint32
+	volumes  []uint32
+	mute     bool
+	writable bool
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{
+		version:       35,
+		defaultSink:   "alsa_output.pci-0000_00_1f.3.analog-stereo",
+		defaultSource: "alsa_input.pci-0000_00_1f.3.analog-stereo",
+		sinks: []*fakeDevice{
+			{
+				index:   47,
+				name:    "alsa_output.pci-0000_00_1f.3.analog-stereo",
+				desc:    "Built-in Audio Analog Stereo",
+				volumes: []uint32{volumeNorm / 2, volumeNorm / 4},
+				ports: []Port{
+					{Name: "analog-output-speaker", Description: "Speakers", Priority: 10000, Availability: "unknown"},
+					{Name: "analog-output-headphones", Description: "Headphones", Priority: 9900, Availability: "no"},
+				},
+				port: "analog-output-speaker",
+			},
+			{
+				index:   52,
+				name:    "bluez_output.AC_80_0A_2F_91_1D.1",
+				desc:    "WH-1000XM4",
+				volumes: []uint32{volumeNorm, volumeNorm},
+			},
+		},
+		sources: []*fakeDevice{
+			{
+				index:   48,
+				name:    "alsa_output.pci-0000_00_1f.3.analog-stereo.monitor",
+				desc:    "Monitor of Built-in Audio Analog Stereo",
+				volumes: []uint32{volumeNorm, volumeNorm},
+				monitor: "alsa_output.pci-0000_00_1f.3.analog-stereo",
+			},
+			{
+				index:   49,
+				name:    "alsa_input.pci-0000_00_1f.3.analog-stereo",
+				desc:    "Built-in Audio Analog Stereo",
+				volumes: []uint32{volumeNorm * 3 / 4, volumeNorm * 3 / 4},
+				mute:    true,
+			},
+		},
+		sinkInputs: []*fakeStream{
+			{index: 112, name: "Playback", app: "Firefox", device: 47, volumes: []uint32{volumeNorm, volumeNorm}, writable: true},
+			{index: 118, name: "event", app: "libcanberra", device: 52, volumes: []uint32{volumeNorm}, writable: false},
+		},
+		sourceOutputs: []*fakeStream{
+			{index: 130, name: "WebRTC", app: "Chromium", device: 49, volumes: []uint32{volumeNorm}, writable: true},
+		},
+	}
+}
+
+func (s *fakeServer) dial() (net.Conn, error) {
+	client, server := net.Pipe()
+
+	s.mu.Lock()
+	s.conns = append(s.conns, server)
+	s.dials++
+	s.mu.Unlock()
+
+	go s.serve(server)
+	return client, nil
+}
+
+// drop closes every connection, like a server restart.
+func (s *fakeServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeServer) received(command uint32) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, c := range s.commands {
+		if c == command {
+			n++
+		}
+	}
+	return n
+}
+
+// emit sends a change event to every client, as the server does after any
+// modification.
+func (s *fakeServer) emit() {
+	s.mu.Lock()
+	conns := append([]net.Conn{}, s.conns...)
+	s.mu.Unlock()
+
+	w := &tagWriter{}
+	w.putU32(commandSubscribeEvent)
+	w.putU32(invalidIndex)
+	w.putU32(0x10)
+	w.putU32(0)
+	for _, c := range conns {
+		writeFrame(c, w.bytes())
+	}
+}
+
+func writeFrame(c net.Conn, payload []byte) error {
+	header := make([]byte, descriptorLength)
+	binary.BigEndian.PutUint32(header[0:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:], controlChannel)
+	_, err := c.Write(append(header, payload...))
+	return err
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+
+	version := uint32(protocolVersion)
+	header := make([]byte, descriptorLength)
+	for {
+		if _, err := io.ReadFull(c, header); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(c, payload); err != nil {
+			return
+		}
+
+		r := newTagReader(payload)
+		command := r.u32()
+		tag := r.u32()
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		reply, errCode := s.handle(command, r, &version)
+		s.mu.Unlock()
+
+		w := &tagWriter{}
+		if errCode != 0 {
+			w.putU32(commandError)
+			w.putU32(tag)
+			w.putU32(errCode)
+		} else {
+			w.putU32(commandReply)
+			w.putU32(tag)
+			w.buf = append(w.buf, reply...)
+		}
+		if err := writeFrame(c, w.bytes()); err != nil {
+			return
+		}
+
+		if errCode == 0 && isMutation(command) {
+			go s.emit()
+		}
+	}
+}
+
+func isMutation(command uint32) bool {
+	switch command {
+	case commandSetSinkVolume, commandSetSourceVolume, commandSetSinkMute, commandSetSourceMute,
+		commandSetSinkInputVolume, commandSetSinkInputMute, commandSetSourceOutputVolume,
+		commandSetSourceOutputMute, commandSetDefaultSink, commandSetDefaultSource,
+		commandMoveSinkInput, commandMoveSourceOutput:
+		return true
+	}
+	return false
+}
+
+const errNoEntity = 5
+
+func (s *fakeServer) handle(command uint32, r *tagReader, version *uint32) ([]byte, uint32) {
+	w := &tagWriter{}
+
+	switch command {
+	case commandAuth:
+		if s.authError != 0 {
+			return nil, s.authError
+		}
+		*version = min(r.u32()&protocolVersionMask, s.version)
+		w.putU32(s.version | 0x80000000)
+	case commandSetClientName:
+		r.propList()
+		w.putU32(7)
+	case commandSubscribe:
+	case commandGetServerInfo:
+		w.putString("PulseAudio (on PipeWire 1.4.2)")
+		w.putString("15.0.0")
+		w.putString("user")
+		w.putString("host")
+		w.putSampleSpec(sampleSpec{Format: 3, Channels: 2, Rate: 48000})
+		w.putString(s.defaultSink)
+		w.putString(s.defaultSource)
+		w.putU32(0xdeadbeef)
+		w.putChannelMap([]uint8{1, 2})
+	case commandGetSinkInfoList:
+		for _, d := range s.sinks {
+			s.writeDevice(w, d, *version, false)
+		}
+	case commandGetSourceInfoList:
+		for _, d := range s.sources {
+			s.writeDevice(w, d, *version, true)
+		}
+	case commandGetSinkInputInfoList:
+		for _, st := range s.sinkInputs {
+			writeSinkInput(w, st)
+		}
+	case commandGetSourceOutputInfoList:
+		for _, st := range s.sourceOutputs {
+			writeSourceOutput(w, st)
+		}
+	case commandSetSinkVolume, commandSetSourceVolume:
+		index, name, volumes := r.u32(), r.string(), r.cvolume()
+		d := s.findDevice(command == commandSetSourceVolume, index, name)
+		if d == nil {
+			return nil, errNoEntity
+		}
+		d.volumes = volumes
+	case commandSetSinkMute, commandSetSourceMute:
+		index, name, mute := r.u32(), r.string(), r.bool()
+		d := s.findDevice(command == commandSetSourceMute, index, name)
+		if d == nil {
+			return nil, errNoEntity
+		}
+		d.mute = mute
+	case commandSetSinkInputVolume, commandSetSourceOutputVolume:
+		index, volumes := r.u32(), r.cvolume()
+		st := s.findStream(command == commandSetSourceOutputVolume, index)
+		if st == nil {
+			return nil, errNoEntity
+		}
+		st.volumes = volumes
+	case commandSetSinkInputMute, commandSetSourceOutputMute:
+		index, mute := r.u32(), r.bool()
+		st := s.findStream(command == commandSetSourceOutputMute, index)
+		if st == nil {
+			return nil, errNoEntity
+		}
+		st.mute = mute
+	case commandSetDefaultSink, commandSetDefaultSource:
+		name := r.string()
+		if s.findDevice(command == commandSetDefaultSource, invalidIndex, name) == nil {
+			return nil, errNoEntity
+		}
+		if command == commandSetDefaultSink {
+			s.defaultSink = name
+		} else {
+			s.defaultSource = name
+		}
+	case commandMoveSinkInput, commandMoveSourceOutput:
+		isSource := command == commandMoveSourceOutput
+		index, deviceIndex, deviceName := r.u32(), r.u32(), r.string()
+		st := s.findStream(isSource, index)
+		d := s.findDevice(isSource, deviceIndex, deviceName)
+		if st == nil || d == nil {
+			return nil, errNoEntity
+		}
+		st.device = d.index
+	default:
+		return nil, 2
+	}
+
+	if r.err != nil {
+		return nil, 7
+	}
+	return w.bytes(), 0
+}
+
+func (s *fakeServer) findDevice(isSource bool, index uint32, name string) *fakeDevice {
+	devices := s.sinks
+	if isSource {
+		devices = s.sources
+	}
+	for _, d := range devices {
+		if (index != invalidIndex && d.index == index) || (name != "" && d.name == name) {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *fakeServer) findStream(isSource bool, index uint32) *fakeStream {
+	streams := s.sinkInputs
+	if isSource {
+		streams = s.sourceOutputs
+	}
+	for _, st := range streams {
+		if st.index == index {
+			return st
+		}
+	}
+	return nil
+}
+
+func (s *fakeServer) writeDevice(w *tagWriter, d *fakeDevice, version uint32, isSource bool) {
+	w.putU32(d.index)
+	w.putString(d.name)
+	w.putString(d.desc)
+	w.putSampleSpec(sampleSpec{Format: 3, Channels: uint8(len(d.volumes)), Rate: 48000})
+	w.putChannelMap(make([]uint8, len(d.volumes)))
+	w.putU32(12)
+	w.putCVolume(d.volumes)
+	w.putBool(d.mute)
+	if d.monitor != "" {
+		w.putU32(47)
+	} else {
+		w.putU32(invalidIndex)
+	}
+	w.putString(d.monitor)
+	w.putUsec(0)
+	w.putString("PipeWire")
+	w.putU32(0)
+
+	w.putPropList(map[string]string{
+		"device.description": d.desc,
+		"device.icon_name":   "audio-card-analog-pci",
+		"device.form_factor": "internal",
+	})
+	w.putUsec(0)
+
+	w.putVolume(volumeNorm)
+	w.putU32(1)
+	w.putU32(65537)
+	w.putU32(3)
+
+	w.putU32(uint32(len(d.ports)))
+	for _, p := range d.ports {
+		w.putString(p.Name)
+		w.putString(p.Description)
+		w.putU32(p.Priority)
+		availability := uint32(0)
+		for k, v := range portAvailability {
+			if v == p.Availability {
+				availability = k
+			}
+		}
+		w.putU32(availability)
+		if version >= 34 {
+			w.putString("")
+			w.putU32(0)
+		}
+	}
+	w.putString(d.port)
+
+	w.putU8(1)
+	w.putFormatInfo(1, map[string]string{"format.rate": "48000"})
+}
+
+func writeSinkInput(w *tagWriter, st *fakeStream) {
+	w.putU32(st.index)
+	w.putString(st.name)
+	w.putU32(invalidIndex)
+	w.putU32(80)
+	w.putU32(st.device)
+	w.putSampleSpec(sampleSpec{Format: 5, Channels: uint8(len(st.volumes)), Rate: 44100})
+	w.putChannelMap(make([]uint8, len(st.volumes)))
+	w.putCVolume(st.volumes)
+	w.putUsec(0)
+	w.putUsec(0)
+	w.putString("")
+	w.putString("PipeWire")
+	w.putBool(st.mute)
+	w.putPropList(map[string]string{
+		"application.name":           st.app,
+		"application.process.binary": "firefox",
+		"application.icon_name":      "firefox",
+	})
+	w.putBool(false)
+	w.putBool(st.writable)
+	w.putBool(st.writable)
+	w.putFormatInfo(1, nil)
+}
+
+func writeSourceOutput(w *tagWriter, st *fakeStream) {
+	w.putU32(st.index)
+	w.putString(st.name)
+	w.putU32(invalidIndex)
+	w.putU32(81)
+	w.putU32(st.device)
+	w.putSampleSpec(sampleSpec{Format: 5, Channels: uint8(len(st.volumes)), Rate: 48000})
+	w.putChannelMap(make([]uint8, len(st.volumes)))
+	w.putUsec(0)
+	w.putUsec(0)
+	w.putString("")
+	w.putString("PipeWire")
+	w.putPropList(map[string]string{"application.name": st.app})
+	w.putBool(true)
+	w.putCVolume(st.volumes)
+	w.putBool(st.mute)
+	w.putBool(st.writable)
+	w.putBool(st.writable)
+	w.putFormatInfo(1, nil)
+}
+
+func newTestManager(t *testing.T, server *fakeServer) *Manager {
+	t.Helper()
+
+	m, err := newManager(server.dial, make([]byte, cookieLength))
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	t.Cleanup(m.Close)
+	return m
+}
@@ -0,0 +1,156 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	switch req.Method {
+	case "audio.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "audio.setVolume":
+		handleSetVolume(conn, req, manager)
+	case "audio.setMute":
+		handleSetMute(conn, req, manager)
+	case "audio.setDefaultSink":
+		handleSetDefault(conn, req, manager.SetDefaultSink)
+	case "audio.setDefaultSource":
+		handleSetDefault(conn, req, manager.SetDefaultSource)
+	case "audio.moveStream":
+		handleMoveStream(conn, req, manager)
+	case "audio.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+// targetFromParams reads type (default sink), name for devices and index
+// for streams.
+func targetFromParams(p map[string]any) (Target, error) {
+	t := Target{
+		Type: params.StringOpt(p, "type", TypeSink),
+		Name: params.StringOpt(p, "name", ""),
+	}
+
+	switch t.Type {
+	case TypeSink, TypeSource:
+	case TypeSinkInput, TypeSourceOutput:
+		index, err := params.Int(p, "index")
+		if err != nil {
+			return t, err
+		}
+		t.Index = uint32(index)
+	default:
+		return t, fmt.Errorf("invalid type: %s", t.Type)
+	}
+	return t, nil
+}
+
+func handleSetVolume(conn net.Conn, req models.Request, manager *Manager) {
+	target, err := targetFromParams(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if delta, ok := models.Get[float64](req, "delta"); ok {
+		err = manager.AdjustVolume(target, delta)
+	} else {
+		var volume float64
+		if volume, err = params.Float(req.Params, "volume"); err == nil {
+			err = manager.SetVolume(target, volume)
+		}
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetMute(conn net.Conn, req models.Request, manager *Manager) {
+	target, err := targetFromParams(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	mute, ok := models.Get[bool](req, "mute")
+	if ok {
+		err = manager.SetMute(target, mute)
+	} else {
+		mute, err = manager.ToggleMute(target)
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Value: fmt.Sprint(mute)})
+}
+
+func handleSetDefault(conn net.Conn, req models.Request, set func(string) error) {
+	name, err := params.StringNonEmpty(req.Params, "name")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := set(name); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleMoveStream(conn net.Conn, req models.Request, manager *Manager) {
+	index, err := params.Int(req.Params, "index")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	device, err := params.StringNonEmpty(req.Params, "device")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	streamType := params.StringOpt(req.Params, "type", TypeSinkInput)
+	if err := manager.MoveStream(streamType, uint32(index), device); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
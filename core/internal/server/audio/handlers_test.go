@@ -0,0 +1,115 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNetConn struct {
+	net.Conn
+	readBuf  *bytes.Buffer
+	writeBuf *bytes.Buffer
+}
+
+func newMockNetConn() *mockNetConn {
+	return &mockNetConn{
+		readBuf:  &bytes.Buffer{},
+		writeBuf: &bytes.Buffer{},
+	}
+}
+
+func (m *mockNetConn) Read(b []byte) (n int, err error) {
+	return m.readBuf.Read(b)
+}
+
+func (m *mockNetConn) Write(b []byte) (n int, err error) {
+	return m.writeBuf.Write(b)
+}
+
+func TestHandleGetState(t *testing.T) {
+	m := newTestManager(t, newFakeServer())
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{ID: 1, Method: "audio.getState"}, m)
+
+	var resp models.Response[State]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	assert.Equal(t, builtinSink, resp.Result.DefaultSink)
+	assert.Len(t, resp.Result.Sinks, 2)
+	assert.Len(t, resp.Result.SinkInputs, 2)
+}
+
+func TestHandleSetVolume(t *testing.T) {
+	m := newTestManager(t, newFakeServer())
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{
+		ID:     2,
+		Method: "audio.setVolume",
+		Params: map[string]any{"delta": 0.1},
+	}, m)
+
+	var resp models.Response[models.SuccessResult]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, 0.6, m.GetState().Sinks[0].Volume)
+
+	conn = newMockNetConn()
+	HandleRequest(conn, models.Request{
+		ID:     3,
+		Method: "audio.setVolume",
+		Params: map[string]any{"type": "sinkInput", "volume": 0.5},
+	}, m)
+	resp = models.Response[models.SuccessResult]{}
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Contains(t, resp.Error, "'index'")
+}
+
+func TestHandleSetMuteToggles(t *testing.T) {
+	m := newTestManager(t, newFakeServer())
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{
+		ID:     4,
+		Method: "audio.setMute",
+		Params: map[string]any{"type": "sink"},
+	}, m)
+
+	var resp models.Response[models.SuccessResult]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	assert.Equal(t, "true", resp.Result.Value)
+	assert.True(t, m.GetState().Sinks[0].Mute)
+}
+
+func TestHandleMoveStream(t *testing.T) {
+	m := newTestManager(t, newFakeServer())
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{
+		ID:     5,
+		Method: "audio.moveStream",
+		Params: map[string]any{"index": float64(112), "device": headphones},
+	}, m)
+
+	var resp models.Response[models.SuccessResult]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, headphones, m.GetState().SinkInputs[0].DeviceName)
+}
+
+func TestHandleUnknownMethod(t *testing.T) {
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{ID: 6, Method: "audio.equalize"}, &Manager{})
+
+	var resp models.Response[any]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Contains(t, resp.Error, "unknown method")
+}
@@ -0,0 +1,248 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+type serverInfo struct {
+	name          string
+	version       string
+	defaultSink   string
+	defaultSource string
+}
+
+func parseServerInfo(r *tagReader) (serverInfo, error) {
+	info := serverInfo{
+		name:    r.string(),
+		version: r.string(),
+	}
+	r.string() // user name
+	r.string() // host name
+	r.sampleSpec()
+	info.defaultSink = r.string()
+	info.defaultSource = r.string()
+	return info, r.err
+}
+
+// parseDevices decodes a sink or source info list. The two records only
+// differ in the monitor field's meaning and when format info was added.
+func parseDevices(r *tagReader, version uint32, isSource bool) ([]Device, error) {
+	devices := []Device{}
+	for !r.eof() && r.err == nil {
+		var dev Device
+		dev.Index = r.u32()
+		dev.Name = r.string()
+		dev.Description = r.string()
+		r.sampleSpec()
+		r.channelMap()
+		r.u32() // owner module
+		dev.volumes = r.cvolume()
+		dev.Mute = r.bool()
+		r.u32() // monitor source / monitor of sink index
+		monitor := r.string()
+		r.usec()   // latency
+		r.string() // driver
+		r.u32()    // flags
+
+		if isSource {
+			dev.MonitorOf = monitor
+		}
+
+		if version >= 13 {
+			props := r.propList()
+			dev.IconName = props["device.icon_name"]
+			dev.FormFactor = props["device.form_factor"]
+			r.usec() // configured latency
+		}
+		if version >= 15 {
+			r.volume() // base volume
+			dev.State = deviceStates[r.u32()]
+			r.u32() // volume steps
+			dev.Card = indexOrNone(r.u32())
+		}
+		if version >= 16 {
+			dev.Ports = parsePorts(r, version)
+			dev.ActivePort = r.string()
+		}
+		if (!isSource && version >= 21) || (isSource && version >= 22) {
+			formats := r.u8()
+			for range formats {
+				r.formatInfo()
+			}
+		}
+
+		if r.err != nil {
+			break
+		}
+		dev.Volume, dev.ChannelVolumes = volumeFractions(dev.volumes)
+		devices = append(devices, dev)
+	}
+	return devices, r.err
+}
+
+func parsePorts(r *tagReader, version uint32) []Port {
+	n := r.u32()
+	if r.err != nil || n > 1024 {
+		if r.err == nil {
+			r.err = fmt.Errorf("implausible port count %d", n)
+		}
+		return nil
+	}
+
+	ports := make([]Port, 0, n)
+	for range n {
+		port := Port{
+			Name:         r.string(),
+			Description:  r.string(),
+			Priority:     r.u32(),
+			Availability: "unknown",
+		}
+		if version >= 24 {
+			port.Availability = portAvailability[r.u32()]
+		}
+		if version >= 34 {
+			r.string() // availability group
+			r.u32()    // port type
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func parseSinkInputs(r *tagReader, version uint32) ([]Stream, error) {
+	streams := []Stream{}
+	for !r.eof() && r.err == nil {
+		var s Stream
+		s.Index = r.u32()
+		s.Name = r.string()
+		r.u32() // owner module
+		s.Client = indexOrNone(r.u32())
+		s.Device = r.u32()
+		r.sampleSpec()
+		r.channelMap()
+		s.volumes = r.cvolume()
+		r.usec()   // buffer latency
+		r.usec()   // sink latency
+		r.string() // resample method
+		r.string() // driver
+		s.HasVolume = true
+		s.VolumeWritable = true
+
+		if version >= 11 {
+			s.Mute = r.bool()
+		}
+		if version >= 13 {
+			applyStreamProps(&s, r.propList())
+		}
+		if version >= 19 {
+			s.Corked = r.bool()
+		}
+		if version >= 20 {
+			s.HasVolume = r.bool()
+			s.VolumeWritable = r.bool()
+		}
+		if version >= 21 {
+			r.formatInfo()
+		}
+
+		if r.err != nil {
+			break
+		}
+		s.Volume, s.ChannelVolumes = volumeFractions(s.volumes)
+		streams = append(streams, s)
+	}
+	return streams, r.err
+}
+
+func parseSourceOutputs(r *tagReader, version uint32) ([]Stream, error) {
+	streams := []Stream{}
+	for !r.eof() && r.err == nil {
+		var s Stream
+		s.Index = r.u32()
+		s.Name = r.string()
+		r.u32() // owner module
+		s.Client = indexOrNone(r.u32())
+		s.Device = r.u32()
+		r.sampleSpec()
+		r.channelMap()
+		r.usec()   // buffer latency
+		r.usec()   // source latency
+		r.string() // resample method
+		r.string() // driver
+
+		if version >= 13 {
+			applyStreamProps(&s, r.propList())
+		}
+		if version >= 19 {
+			s.Corked = r.bool()
+		}
+		if version >= 22 {
+			s.volumes = r.cvolume()
+			s.Mute = r.bool()
+			s.HasVolume = r.bool()
+			s.VolumeWritable = r.bool()
+			r.formatInfo()
+		}
+
+		if r.err != nil {
+			break
+		}
+		s.Volume, s.ChannelVolumes = volumeFractions(s.volumes)
+		streams = append(streams, s)
+	}
+	return streams, r.err
+}
+
+func applyStreamProps(s *Stream, props map[string]string) {
+	s.Application = props["application.name"]
+	s.Binary = props["application.process.binary"]
+	s.IconName = props["application.icon_name"]
+	if s.Name == "" {
+		s.Name = props["media.name"]
+	}
+}
+
+// indexOrNone maps PA_INVALID_INDEX to -1 so it survives JSON.
+func indexOrNone(idx uint32) int64 {
+	if idx == invalidIndex {
+		return -1
+	}
+	return int64(idx)
+}
+
+func volumeFractions(volumes []uint32) (float64, []float64) {
+	channels := make([]float64, len(volumes))
+	var peak float64
+	for i, v := range volumes {
+		channels[i] = volumeToFraction(v)
+		peak = max(peak, channels[i])
+	}
+	return peak, channels
+}
+
+func volumeToFraction(v uint32) float64 {
+	return math.Round(float64(v)/volumeNorm*10000) / 10000
+}
+
+// scaleVolumes sets the loudest channel to target and scales the others by
+// the same factor, keeping the balance like pa_cvolume_scale does.
+func scaleVolumes(volumes []uint32, target float64) []uint32 {
+	target = math.Max(0, math.Min(target, MaxVolume))
+	want := uint32(math.Round(target * volumeNorm))
+
+	var peak uint32
+	for _, v := range volumes {
+		peak = max(peak, v)
+	}
+
+	scaled := make([]uint32, len(volumes))
+	for i, v := range volumes {
+		if peak == 0 {
+			scaled[i] = want
+			continue
+		}
+		scaled[i] = uint32(math.Round(float64(v) * float64(want) / float64(peak)))
+	}
+	return scaled
+}
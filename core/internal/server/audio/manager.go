@@ -0,0 +1,555 @@
+package audio
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+// eventSettleDelay batches the bursts of subscription events a single
+// change produces (a new stream also changes its sink, and so on).
+const eventSettleDelay = 30 * time.Millisecond
+
+// Target identifies what a volume or mute request applies to: a sink or
+// source by name (the default one when empty), or a stream by index.
+type Target struct {
+	Type  string
+	Name  string
+	Index uint32
+}
+
+func NewManager() (*Manager, error) {
+	path, err := socketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	dial := func() (net.Conn, error) {
+		return net.Dial("unix", path)
+	}
+	return newManager(dial, readCookie())
+}
+
+func newManager(dial func() (net.Conn, error), cookie []byte) (*Manager, error) {
+	m := &Manager{
+		dial:     dial,
+		cookie:   cookie,
+		state:    emptyState(),
+		stopChan: make(chan struct{}),
+	}
+
+	pulse, err := m.connect()
+	if err != nil {
+		return nil, fmt.Errorf("pulse server not available: %w", err)
+	}
+	m.pulse = pulse
+
+	if err := m.refresh(); err != nil {
+		pulse.Close()
+		return nil, fmt.Errorf("failed to query pulse server: %w", err)
+	}
+
+	m.wg.Add(1)
+	go m.run(pulse)
+
+	return m, nil
+}
+
+func emptyState() *State {
+	return &State{
+		Sinks:         []Device{},
+		Sources:       []Device{},
+		SinkInputs:    []Stream{},
+		SourceOutputs: []Stream{},
+	}
+}
+
+func (m *Manager) connect() (*pulseConn, error) {
+	nc, err := m.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	pulse := newPulseConn(nc)
+	if err := pulse.handshake(m.cookie); err != nil {
+		pulse.Close()
+		return nil, err
+	}
+	return pulse, nil
+}
+
+func (m *Manager) conn() (*pulseConn, error) {
+	m.connMutex.RLock()
+	defer m.connMutex.RUnlock()
+
+	if m.pulse == nil {
+		return nil, fmt.Errorf("pulse server not connected")
+	}
+	return m.pulse, nil
+}
+
+// run refreshes the state on server events and reconnects when the server
+// restarts, which is routine for pipewire-pulse.
+func (m *Manager) run(pulse *pulseConn) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-pulse.events:
+			if !m.sleep(eventSettleDelay) {
+				return
+			}
+			select {
+			case <-pulse.events:
+			default:
+			}
+			if err := m.refresh(); err != nil {
+				log.Debugf("[Audio] refresh failed: %v", err)
+			}
+		case <-pulse.done:
+			select {
+			case <-m.stopChan:
+				return
+			default:
+			}
+			log.Warnf("[Audio] lost connection to pulse server: %v", pulse.err)
+			m.setUnavailable()
+
+			if pulse = m.reconnect(); pulse == nil {
+				return
+			}
+		}
+	}
+}
+
+func (m *Manager) reconnect() *pulseConn {
+	for {
+		if !m.sleep(reconnectDelay) {
+			return nil
+		}
+
+		pulse, err := m.connect()
+		if err != nil {
+			log.Debugf("[Audio] reconnect failed: %v", err)
+			continue
+		}
+
+		m.connMutex.Lock()
+		m.pulse = pulse
+		m.connMutex.Unlock()
+
+		if err := m.refresh(); err != nil {
+			log.Debugf("[Audio] refresh after reconnect failed: %v", err)
+			pulse.Close()
+			continue
+		}
+
+		log.Info("[Audio] reconnected to pulse server")
+		return pulse
+	}
+}
+
+func (m *Manager) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-m.stopChan:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (m *Manager) setUnavailable() {
+	m.connMutex.Lock()
+	m.pulse = nil
+	m.connMutex.Unlock()
+
+	m.stateMutex.Lock()
+	m.state = emptyState()
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) refresh() error {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
+	pulse, err := m.conn()
+	if err != nil {
+		return err
+	}
+
+	reply, err := pulse.request(commandGetServerInfo, nil)
+	if err != nil {
+		return fmt.Errorf("get server info: %w", err)
+	}
+	info, err := parseServerInfo(reply)
+	if err != nil {
+		return fmt.Errorf("parse server info: %w", err)
+	}
+
+	if reply, err = pulse.request(commandGetSinkInfoList, nil); err != nil {
+		return fmt.Errorf("list sinks: %w", err)
+	}
+	sinks, err := parseDevices(reply, pulse.version, false)
+	if err != nil {
+		return fmt.Errorf("parse sinks: %w", err)
+	}
+
+	if reply, err = pulse.request(commandGetSourceInfoList, nil); err != nil {
+		return fmt.Errorf("list sources: %w", err)
+	}
+	sources, err := parseDevices(reply, pulse.version, true)
+	if err != nil {
+		return fmt.Errorf("parse sources: %w", err)
+	}
+
+	if reply, err = pulse.request(commandGetSinkInputInfoList, nil); err != nil {
+		return fmt.Errorf("list sink inputs: %w", err)
+	}
+	sinkInputs, err := parseSinkInputs(reply, pulse.version)
+	if err != nil {
+		return fmt.Errorf("parse sink inputs: %w", err)
+	}
+
+	if reply, err = pulse.request(commandGetSourceOutputInfoList, nil); err != nil {
+		return fmt.Errorf("list source outputs: %w", err)
+	}
+	sourceOutputs, err := parseSourceOutputs(reply, pulse.version)
+	if err != nil {
+		return fmt.Errorf("parse source outputs: %w", err)
+	}
+
+	sinkNames := markDefault(sinks, info.defaultSink)
+	sourceNames := markDefault(sources, info.defaultSource)
+	for i := range sinkInputs {
+		sinkInputs[i].DeviceName = sinkNames[sinkInputs[i].Device]
+	}
+	for i := range sourceOutputs {
+		sourceOutputs[i].DeviceName = sourceNames[sourceOutputs[i].Device]
+	}
+
+	newState := &State{
+		Available:     true,
+		ServerName:    info.name,
+		ServerVersion: info.version,
+		DefaultSink:   info.defaultSink,
+		DefaultSource: info.defaultSource,
+		Sinks:         sinks,
+		Sources:       sources,
+		SinkInputs:    sinkInputs,
+		SourceOutputs: sourceOutputs,
+	}
+
+	m.stateMutex.Lock()
+	changed := !reflect.DeepEqual(m.state, newState)
+	m.state = newState
+	m.stateMutex.Unlock()
+
+	if changed {
+		m.notifySubscribers()
+	}
+	return nil
+}
+
+func markDefault(devices []Device, defaultName string) map[uint32]string {
+	names := make(map[uint32]string, len(devices))
+	for i := range devices {
+		devices[i].IsDefault = devices[i].Name == defaultName
+		names[devices[i].Index] = devices[i].Name
+	}
+	return names
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	return State{
+		Available:     m.state.Available,
+		ServerName:    m.state.ServerName,
+		ServerVersion: m.state.ServerVersion,
+		DefaultSink:   m.state.DefaultSink,
+		DefaultSource: m.state.DefaultSource,
+		Sinks:         cloneDevices(m.state.Sinks),
+		Sources:       cloneDevices(m.state.Sources),
+		SinkInputs:    cloneStreams(m.state.SinkInputs),
+		SourceOutputs: cloneStreams(m.state.SourceOutputs),
+	}
+}
+
+func cloneDevices(devices []Device) []Device {
+	out := make([]Device, len(devices))
+	for i, d := range devices {
+		d.ChannelVolumes = append([]float64{}, d.ChannelVolumes...)
+		d.Ports = append([]Port{}, d.Ports...)
+		d.volumes = append([]uint32{}, d.volumes...)
+		out[i] = d
+	}
+	return out
+}
+
+func cloneStreams(streams []Stream) []Stream {
+	out := make([]Stream, len(streams))
+	for i, s := range streams {
+		s.ChannelVolumes = append([]float64{}, s.ChannelVolumes...)
+		s.volumes = append([]uint32{}, s.volumes...)
+		out[i] = s
+	}
+	return out
+}
+
+type resolvedTarget struct {
+	index    uint32
+	volumes  []uint32
+	mute     bool
+	writable bool
+}
+
+func (m *Manager) resolve(t Target) (resolvedTarget, error) {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	if !m.state.Available {
+		return resolvedTarget{}, fmt.Errorf("pulse server not connected")
+	}
+
+	switch t.Type {
+	case TypeSink, TypeSource:
+		devices, name := m.state.Sinks, t.Name
+		if t.Type == TypeSource {
+			devices = m.state.Sources
+		}
+		if name == "" {
+			name = m.state.DefaultSink
+			if t.Type == TypeSource {
+				name = m.state.DefaultSource
+			}
+		}
+		for _, d := range devices {
+			if d.Name == name {
+				return resolvedTarget{index: d.Index, volumes: d.volumes, mute: d.Mute, writable: true}, nil
+			}
+		}
+		return resolvedTarget{}, fmt.Errorf("%s not found: %s", t.Type, name)
+
+	case TypeSinkInput, TypeSourceOutput:
+		streams := m.state.SinkInputs
+		if t.Type == TypeSourceOutput {
+			streams = m.state.SourceOutputs
+		}
+		for _, s := range streams {
+			if s.Index == t.Index {
+				return resolvedTarget{
+					index:    s.Index,
+					volumes:  s.volumes,
+					mute:     s.Mute,
+					writable: s.HasVolume && s.VolumeWritable,
+				}, nil
+			}
+		}
+		return resolvedTarget{}, fmt.Errorf("%s not found: %d", t.Type, t.Index)
+	}
+
+	return resolvedTarget{}, fmt.Errorf("invalid type: %s", t.Type)
+}
+
+// call sends a command and refreshes right away, so a getState issued after
+// the reply already reflects the change.
+func (m *Manager) call(command uint32, build func(*tagWriter)) error {
+	pulse, err := m.conn()
+	if err != nil {
+		return err
+	}
+
+	if _, err := pulse.request(command, build); err != nil {
+		return err
+	}
+
+	if err := m.refresh(); err != nil {
+		log.Debugf("[Audio] refresh failed: %v", err)
+	}
+	return nil
+}
+
+// SetVolume sets the loudest channel of the target to volume (1.0 = 100%)
+// and keeps the channel balance.
+func (m *Manager) SetVolume(t Target, volume float64) error {
+	target, err := m.resolve(t)
+	if err != nil {
+		return err
+	}
+	if !target.writable || len(target.volumes) == 0 {
+		return fmt.Errorf("%s volume is not writable", t.Type)
+	}
+
+	volumes := scaleVolumes(target.volumes, volume)
+	switch t.Type {
+	case TypeSink, TypeSource:
+		command := uint32(commandSetSinkVolume)
+		if t.Type == TypeSource {
+			command = commandSetSourceVolume
+		}
+		return m.call(command, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putString("")
+			w.putCVolume(volumes)
+		})
+	case TypeSinkInput:
+		return m.call(commandSetSinkInputVolume, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putCVolume(volumes)
+		})
+	default:
+		return m.call(commandSetSourceOutputVolume, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putCVolume(volumes)
+		})
+	}
+}
+
+// AdjustVolume changes the volume by delta, clamped to 0..MaxVolume.
+func (m *Manager) AdjustVolume(t Target, delta float64) error {
+	target, err := m.resolve(t)
+	if err != nil {
+		return err
+	}
+	current, _ := volumeFractions(target.volumes)
+	return m.SetVolume(t, current+delta)
+}
+
+func (m *Manager) SetMute(t Target, mute bool) error {
+	target, err := m.resolve(t)
+	if err != nil {
+		return err
+	}
+
+	switch t.Type {
+	case TypeSink, TypeSource:
+		command := uint32(commandSetSinkMute)
+		if t.Type == TypeSource {
+			command = commandSetSourceMute
+		}
+		return m.call(command, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putString("")
+			w.putBool(mute)
+		})
+	case TypeSinkInput:
+		return m.call(commandSetSinkInputMute, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putBool(mute)
+		})
+	default:
+		return m.call(commandSetSourceOutputMute, func(w *tagWriter) {
+			w.putU32(target.index)
+			w.putBool(mute)
+		})
+	}
+}
+
+// ToggleMute flips the mute state and returns the new value.
+func (m *Manager) ToggleMute(t Target) (bool, error) {
+	target, err := m.resolve(t)
+	if err != nil {
+		return false, err
+	}
+	return !target.mute, m.SetMute(t, !target.mute)
+}
+
+func (m *Manager) SetDefaultSink(name string) error {
+	if _, err := m.resolve(Target{Type: TypeSink, Name: name}); err != nil {
+		return err
+	}
+	return m.call(commandSetDefaultSink, func(w *tagWriter) {
+		w.putString(name)
+	})
+}
+
+func (m *Manager) SetDefaultSource(name string) error {
+	if _, err := m.resolve(Target{Type: TypeSource, Name: name}); err != nil {
+		return err
+	}
+	return m.call(commandSetDefaultSource, func(w *tagWriter) {
+		w.putString(name)
+	})
+}
+
+// MoveStream moves a sink input to another sink, or a source output to
+// another source.
+func (m *Manager) MoveStream(streamType string, index uint32, device string) error {
+	deviceType := TypeSink
+	command := uint32(commandMoveSinkInput)
+	switch streamType {
+	case TypeSinkInput:
+	case TypeSourceOutput:
+		deviceType = TypeSource
+		command = commandMoveSourceOutput
+	default:
+		return fmt.Errorf("invalid stream type: %s", streamType)
+	}
+
+	if _, err := m.resolve(Target{Type: streamType, Index: index}); err != nil {
+		return err
+	}
+	dest, err := m.resolve(Target{Type: deviceType, Name: device})
+	if err != nil {
+		return err
+	}
+
+	return m.call(command, func(w *tagWriter) {
+		w.putU32(index)
+		w.putU32(dest.index)
+		w.putString("")
+	})
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if val, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(val)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	state := m.GetState()
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+		}
+		return true
+	})
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+
+	m.connMutex.Lock()
+	if m.pulse != nil {
+		m.pulse.Close()
+		m.pulse = nil
+	}
+	m.connMutex.Unlock()
+
+	m.wg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
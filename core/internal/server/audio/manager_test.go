@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	builtinSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	headphones  = "bluez_output.AC_80_0A_2F_91_1D.1"
+	builtinMic  = "alsa_input.pci-0000_00_1f.3.analog-stereo"
+)
+
+func waitForState(t *testing.T, ch chan State, match func(State) bool) State {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case state := <-ch:
+			if match(state) {
+				return state
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for state")
+			return State{}
+		}
+	}
+}
+
+func TestNewManager_InitialState(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	state := m.GetState()
+	assert.True(t, state.Available)
+	assert.Equal(t, "PulseAudio (on PipeWire 1.4.2)", state.ServerName)
+	assert.Equal(t, builtinSink, state.DefaultSink)
+	assert.Equal(t, builtinMic, state.DefaultSource)
+
+	require.Len(t, state.Sinks, 2)
+	sink := state.Sinks[0]
+	assert.Equal(t, uint32(47), sink.Index)
+	assert.Equal(t, "Built-in Audio Analog Stereo", sink.Description)
+	assert.Equal(t, 0.5, sink.Volume)
+	assert.Equal(t, []float64{0.5, 0.25}, sink.ChannelVolumes)
+	assert.True(t, sink.IsDefault)
+	assert.Equal(t, "idle", sink.State)
+	assert.Equal(t, int64(3), sink.Card)
+	assert.Equal(t, "audio-card-analog-pci", sink.IconName)
+	assert.Equal(t, "analog-output-speaker", sink.ActivePort)
+	require.Len(t, sink.Ports, 2)
+	assert.Equal(t, "no", sink.Ports[1].Availability)
+	assert.False(t, state.Sinks[1].IsDefault)
+
+	require.Len(t, state.Sources, 2)
+	assert.Equal(t, builtinSink, state.Sources[0].MonitorOf)
+	assert.True(t, state.Sources[1].IsDefault)
+	assert.True(t, state.Sources[1].Mute)
+
+	require.Len(t, state.SinkInputs, 2)
+	assert.Equal(t, "Firefox", state.SinkInputs[0].Application)
+	assert.Equal(t, builtinSink, state.SinkInputs[0].DeviceName)
+	assert.Equal(t, int64(80), state.SinkInputs[0].Client)
+	assert.False(t, state.SinkInputs[1].VolumeWritable)
+
+	require.Len(t, state.SourceOutputs, 1)
+	assert.Equal(t, "Chromium", state.SourceOutputs[0].Application)
+	assert.Equal(t, builtinMic, state.SourceOutputs[0].DeviceName)
+	assert.Equal(t, 1.0, state.SourceOutputs[0].Volume)
+
+	assert.Equal(t, 1, server.received(commandSubscribe))
+}
+
+func TestNewManager_AuthRejected(t *testing.T) {
+	server := newFakeServer()
+	server.authError = 1
+
+	m, err := newManager(server.dial, make([]byte, cookieLength))
+	assert.Nil(t, m)
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestNewManager_DialFails(t *testing.T) {
+	dial := func() (net.Conn, error) { return nil, assert.AnError }
+
+	m, err := newManager(dial, nil)
+	assert.Nil(t, m)
+	assert.ErrorContains(t, err, "pulse server not available")
+}
+
+func TestManager_SetVolumeKeepsBalance(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	require.NoError(t, m.SetVolume(Target{Type: TypeSink}, 0.8))
+
+	sink := m.GetState().Sinks[0]
+	assert.Equal(t, 0.8, sink.Volume)
+	assert.Equal(t, []float64{0.8, 0.4}, sink.ChannelVolumes)
+
+	require.NoError(t, m.AdjustVolume(Target{Type: TypeSink, Name: headphones}, 1))
+	assert.Equal(t, MaxVolume, m.GetState().Sinks[1].Volume)
+
+	require.NoError(t, m.AdjustVolume(Target{Type: TypeSink}, -5))
+	assert.Equal(t, 0.0, m.GetState().Sinks[0].Volume)
+}
+
+func TestManager_StreamVolume(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	require.NoError(t, m.SetVolume(Target{Type: TypeSinkInput, Index: 112}, 0.3))
+	assert.Equal(t, 0.3, m.GetState().SinkInputs[0].Volume)
+
+	require.NoError(t, m.SetVolume(Target{Type: TypeSourceOutput, Index: 130}, 0.6))
+	assert.Equal(t, 0.6, m.GetState().SourceOutputs[0].Volume)
+
+	assert.ErrorContains(t, m.SetVolume(Target{Type: TypeSinkInput, Index: 118}, 0.3), "not writable")
+	assert.ErrorContains(t, m.SetVolume(Target{Type: TypeSinkInput, Index: 999}, 0.3), "not found")
+	assert.ErrorContains(t, m.SetVolume(Target{Type: "card"}, 0.3), "invalid type")
+}
+
+func TestManager_Mute(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	muted, err := m.ToggleMute(Target{Type: TypeSource})
+	require.NoError(t, err)
+	assert.False(t, muted)
+	assert.False(t, m.GetState().Sources[1].Mute)
+
+	require.NoError(t, m.SetMute(Target{Type: TypeSinkInput, Index: 112}, true))
+	assert.True(t, m.GetState().SinkInputs[0].Mute)
+}
+
+func TestManager_DefaultsAndMove(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	require.NoError(t, m.SetDefaultSink(headphones))
+	state := m.GetState()
+	assert.Equal(t, headphones, state.DefaultSink)
+	assert.False(t, state.Sinks[0].IsDefault)
+	assert.True(t, state.Sinks[1].IsDefault)
+
+	assert.ErrorContains(t, m.SetDefaultSource("nope"), "source not found")
+
+	require.NoError(t, m.MoveStream(TypeSinkInput, 112, headphones))
+	state = m.GetState()
+	assert.Equal(t, uint32(52), state.SinkInputs[0].Device)
+	assert.Equal(t, headphones, state.SinkInputs[0].DeviceName)
+
+	assert.ErrorContains(t, m.MoveStream(TypeSink, 112, headphones), "invalid stream type")
+	assert.ErrorContains(t, m.MoveStream(TypeSourceOutput, 130, builtinSink), "source not found")
+}
+
+func TestManager_ExternalChangeNotifies(t *testing.T) {
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	server.mu.Lock()
+	server.sinks[0].mute = true
+	server.mu.Unlock()
+	server.emit()
+
+	state := waitForState(t, ch, func(s State) bool { return s.Sinks[0].Mute })
+	assert.True(t, state.Available)
+}
+
+func TestManager_Reconnects(t *testing.T) {
+	old := reconnectDelay
+	reconnectDelay = 10 * time.Millisecond
+	t.Cleanup(func() { reconnectDelay = old })
+
+	server := newFakeServer()
+	m := newTestManager(t, server)
+
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	server.drop()
+	waitForState(t, ch, func(s State) bool { return !s.Available })
+
+	state := waitForState(t, ch, func(s State) bool { return s.Available })
+	assert.Len(t, state.Sinks, 2)
+
+	server.mu.Lock()
+	assert.Equal(t, 2, server.dials)
+	server.mu.Unlock()
+}
@@ -0,0 +1,296 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var errConnClosed = errors.New("pulse connection closed")
+
+type pulseError struct {
+	code uint32
+}
+
+func (e *pulseError) Error() string {
+	if name, ok := errorNames[e.code]; ok {
+		return name
+	}
+	return fmt.Sprintf("pulse error %d", e.code)
+}
+
+type pulseReply struct {
+	body *tagReader
+	err  error
+}
+
+// pulseConn is a minimal native protocol client: control packets only, no
+// streams or shared memory. Replies are matched to requests by tag and
+// subscription events are coalesced onto the events channel.
+type pulseConn struct {
+	conn    net.Conn
+	version uint32
+
+	writeMutex sync.Mutex
+
+	pendingMutex sync.Mutex
+	pending      map[uint32]chan pulseReply
+	nextTag      uint32
+
+	events chan struct{}
+	done   chan struct{}
+	err    error
+}
+
+func newPulseConn(conn net.Conn) *pulseConn {
+	c := &pulseConn{
+		conn:    conn,
+		pending: make(map[uint32]chan pulseReply),
+		events:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// handshake authenticates, names the client and enables change events.
+func (c *pulseConn) handshake(cookie []byte) error {
+	reply, err := c.request(commandAuth, func(w *tagWriter) {
+		w.putU32(protocolVersion)
+		w.putArbitrary(cookie)
+	})
+	if err != nil {
+		return fmt.Errorf("auth failed: %w", err)
+	}
+
+	serverVersion := reply.u32() & protocolVersionMask
+	if reply.err != nil {
+		return fmt.Errorf("auth failed: %w", reply.err)
+	}
+	if serverVersion < 13 {
+		return fmt.Errorf("server protocol version %d is too old", serverVersion)
+	}
+	c.version = min(serverVersion, protocolVersion)
+
+	if _, err := c.request(commandSetClientName, func(w *tagWriter) {
+		w.putPropList(clientProperties())
+	}); err != nil {
+		return fmt.Errorf("set client name failed: %w", err)
+	}
+
+	if _, err := c.request(commandSubscribe, func(w *tagWriter) {
+		w.putU32(subscriptionMask)
+	}); err != nil {
+		return fmt.Errorf("subscribe failed: %w", err)
+	}
+	return nil
+}
+
+func clientProperties() map[string]string {
+	props := map[string]string{
+		"application.name":           "DankMaterialShell",
+		"application.id":             "com.danklinux.dms",
+		"application.process.id":     fmt.Sprint(os.Getpid()),
+		"application.process.binary": "dms",
+	}
+	if host, err := os.Hostname(); err == nil {
+		props["application.process.host"] = host
+	}
+	return props
+}
+
+func (c *pulseConn) request(command uint32, build func(*tagWriter)) (*tagReader, error) {
+	c.pendingMutex.Lock()
+	tag := c.nextTag
+	c.nextTag++
+	ch := make(chan pulseReply, 1)
+	c.pending[tag] = ch
+	c.pendingMutex.Unlock()
+
+	w := &tagWriter{}
+	w.putU32(command)
+	w.putU32(tag)
+	if build != nil {
+		build(w)
+	}
+
+	if err := c.writePacket(w.bytes(), command == commandAuth); err != nil {
+		c.dropPending(tag)
+		return nil, err
+	}
+
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-ch:
+		return reply.body, reply.err
+	case <-c.done:
+		c.dropPending(tag)
+		return nil, c.err
+	case <-timer.C:
+		c.dropPending(tag)
+		return nil, fmt.Errorf("request %d timed out", command)
+	}
+}
+
+func (c *pulseConn) dropPending(tag uint32) {
+	c.pendingMutex.Lock()
+	delete(c.pending, tag)
+	c.pendingMutex.Unlock()
+}
+
+// writePacket frames payload for the control channel. The auth packet also
+// carries our credentials so a PulseAudio server accepts same-user
+// connections even without a readable cookie.
+func (c *pulseConn) writePacket(payload []byte, withCreds bool) error {
+	packet := make([]byte, descriptorLength, descriptorLength+len(payload))
+	binary.BigEndian.PutUint32(packet[0:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(packet[4:], controlChannel)
+	packet = append(packet, payload...)
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if uc, ok := c.conn.(*net.UnixConn); ok && withCreds {
+		creds := unix.UnixCredentials(&unix.Ucred{
+			Pid: int32(os.Getpid()),
+			Uid: uint32(os.Getuid()),
+			Gid: uint32(os.Getgid()),
+		})
+		_, _, err := uc.WriteMsgUnix(packet, creds, nil)
+		return err
+	}
+
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *pulseConn) readLoop() {
+	header := make([]byte, descriptorLength)
+	var err error
+
+	for {
+		if _, err = io.ReadFull(c.conn, header); err != nil {
+			break
+		}
+
+		length := binary.BigEndian.Uint32(header[0:])
+		channel := binary.BigEndian.Uint32(header[4:])
+		if length > maxPacketLength {
+			err = fmt.Errorf("packet too large: %d bytes", length)
+			break
+		}
+
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(c.conn, payload); err != nil {
+			break
+		}
+		if channel != controlChannel {
+			continue
+		}
+
+		c.dispatch(newTagReader(payload))
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		err = errConnClosed
+	}
+	c.err = err
+	close(c.done)
+}
+
+func (c *pulseConn) dispatch(r *tagReader) {
+	command := r.u32()
+	tag := r.u32()
+	if r.err != nil {
+		return
+	}
+
+	switch command {
+	case commandReply, commandError:
+		c.pendingMutex.Lock()
+		ch, ok := c.pending[tag]
+		delete(c.pending, tag)
+		c.pendingMutex.Unlock()
+		if !ok {
+			return
+		}
+
+		if command == commandError {
+			code := r.u32()
+			ch <- pulseReply{err: &pulseError{code: code}}
+			return
+		}
+		ch <- pulseReply{body: r}
+
+	case commandSubscribeEvent:
+		select {
+		case c.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *pulseConn) Close() error {
+	return c.conn.Close()
+}
+
+// socketPath resolves the server socket the same way libpulse does for
+// local connections: PULSE_SERVER first, then the per-user runtime dir.
+func socketPath() (string, error) {
+	if server := os.Getenv("PULSE_SERVER"); server != "" {
+		for _, candidate := range strings.Fields(server) {
+			switch {
+			case strings.HasPrefix(candidate, "unix:"):
+				return strings.TrimPrefix(candidate, "unix:"), nil
+			case strings.HasPrefix(candidate, "/"):
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("PULSE_SERVER has no local socket: %s", server)
+	}
+
+	if runtimeDir := os.Getenv("PULSE_RUNTIME_PATH"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "native"), nil
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return filepath.Join(runtimeDir, "pulse", "native"), nil
+}
+
+// readCookie returns the auth cookie, or zeros when none exists. A zero
+// cookie still works with pipewire-pulse and with PulseAudio when the
+// credentials check passes.
+func readCookie() []byte {
+	var candidates []string
+	if path := os.Getenv("PULSE_COOKIE"); path != "" {
+		candidates = append(candidates, path)
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(configDir, "pulse", "cookie"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".pulse-cookie"))
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if err == nil && len(data) >= cookieLength {
+			return data[:cookieLength]
+		}
+	}
+	return make([]byte, cookieLength)
+}
@@ -0,0 +1,328 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var errShortTagstruct = errors.New("truncated tagstruct")
+
+type sampleSpec struct {
+	Format   uint8
+	Channels uint8
+	Rate     uint32
+}
+
+// tagWriter builds a pulse tagstruct: every value is prefixed with a one
+// byte type tag and multi-byte integers are big endian.
+type tagWriter struct {
+	buf []byte
+}
+
+func (w *tagWriter) bytes() []byte {
+	return w.buf
+}
+
+func (w *tagWriter) putU32(v uint32) {
+	w.buf = append(w.buf, tagU32)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+func (w *tagWriter) putU8(v uint8) {
+	w.buf = append(w.buf, tagU8, v)
+}
+
+func (w *tagWriter) putUsec(v uint64) {
+	w.buf = append(w.buf, tagUsec)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, v)
+}
+
+// putString writes s, or the null string when s is empty. The server treats
+// both as "not set" for every command we send.
+func (w *tagWriter) putString(s string) {
+	if s == "" {
+		w.buf = append(w.buf, tagStringNull)
+		return
+	}
+	w.buf = append(w.buf, tagString)
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+}
+
+func (w *tagWriter) putBool(v bool) {
+	if v {
+		w.buf = append(w.buf, tagBooleanTrue)
+	} else {
+		w.buf = append(w.buf, tagBooleanFalse)
+	}
+}
+
+func (w *tagWriter) putArbitrary(data []byte) {
+	w.buf = append(w.buf, tagArbitrary)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(data)))
+	w.buf = append(w.buf, data...)
+}
+
+func (w *tagWriter) putSampleSpec(ss sampleSpec) {
+	w.buf = append(w.buf, tagSampleSpec, ss.Format, ss.Channels)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, ss.Rate)
+}
+
+func (w *tagWriter) putChannelMap(positions []uint8) {
+	w.buf = append(w.buf, tagChannelMap, uint8(len(positions)))
+	w.buf = append(w.buf, positions...)
+}
+
+func (w *tagWriter) putCVolume(volumes []uint32) {
+	w.buf = append(w.buf, tagCVolume, uint8(len(volumes)))
+	for _, v := range volumes {
+		w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+	}
+}
+
+func (w *tagWriter) putVolume(v uint32) {
+	w.buf = append(w.buf, tagVolume)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+// putPropList writes string properties; values carry the trailing NUL the
+// C library expects for string entries.
+func (w *tagWriter) putPropList(props map[string]string) {
+	w.buf = append(w.buf, tagPropList)
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value := append([]byte(props[k]), 0)
+		w.putString(k)
+		w.putU32(uint32(len(value)))
+		w.putArbitrary(value)
+	}
+	w.buf = append(w.buf, tagStringNull)
+}
+
+func (w *tagWriter) putFormatInfo(encoding uint8, props map[string]string) {
+	w.buf = append(w.buf, tagFormatInfo)
+	w.putU8(encoding)
+	w.putPropList(props)
+}
+
+// tagReader decodes a tagstruct. The first error sticks and all further
+// reads return zero values, so parsers can read a whole record and check
+// err once at the end.
+type tagReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func newTagReader(data []byte) *tagReader {
+	return &tagReader{data: data}
+}
+
+func (r *tagReader) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *tagReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data)-r.pos < n {
+		r.err = errShortTagstruct
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *tagReader) expect(tag byte) bool {
+	b := r.take(1)
+	if b == nil {
+		return false
+	}
+	if b[0] != tag {
+		r.err = fmt.Errorf("unexpected tag %q, want %q at offset %d", b[0], tag, r.pos-1)
+		return false
+	}
+	return true
+}
+
+func (r *tagReader) u32() uint32 {
+	if !r.expect(tagU32) {
+		return 0
+	}
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *tagReader) u8() uint8 {
+	if !r.expect(tagU8) {
+		return 0
+	}
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *tagReader) usec() uint64 {
+	if !r.expect(tagUsec) {
+		return 0
+	}
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *tagReader) volume() uint32 {
+	if !r.expect(tagVolume) {
+		return 0
+	}
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// string returns "" for the null string.
+func (r *tagReader) string() string {
+	b := r.take(1)
+	if b == nil {
+		return ""
+	}
+
+	switch b[0] {
+	case tagStringNull:
+		return ""
+	case tagString:
+	default:
+		r.err = fmt.Errorf("unexpected tag %q, want string at offset %d", b[0], r.pos-1)
+		return ""
+	}
+
+	for i := r.pos; i < len(r.data); i++ {
+		if r.data[i] == 0 {
+			s := string(r.data[r.pos:i])
+			r.pos = i + 1
+			return s
+		}
+	}
+	r.err = errShortTagstruct
+	return ""
+}
+
+func (r *tagReader) bool() bool {
+	b := r.take(1)
+	if b == nil {
+		return false
+	}
+
+	switch b[0] {
+	case tagBooleanTrue:
+		return true
+	case tagBooleanFalse:
+		return false
+	}
+	r.err = fmt.Errorf("unexpected tag %q, want boolean at offset %d", b[0], r.pos-1)
+	return false
+}
+
+func (r *tagReader) arbitrary() []byte {
+	if !r.expect(tagArbitrary) {
+		return nil
+	}
+	b := r.take(4)
+	if b == nil {
+		return nil
+	}
+	return r.take(int(binary.BigEndian.Uint32(b)))
+}
+
+func (r *tagReader) sampleSpec() sampleSpec {
+	if !r.expect(tagSampleSpec) {
+		return sampleSpec{}
+	}
+	b := r.take(6)
+	if b == nil {
+		return sampleSpec{}
+	}
+	return sampleSpec{Format: b[0], Channels: b[1], Rate: binary.BigEndian.Uint32(b[2:])}
+}
+
+func (r *tagReader) channelMap() []uint8 {
+	if !r.expect(tagChannelMap) {
+		return nil
+	}
+	n := r.take(1)
+	if n == nil {
+		return nil
+	}
+	return append([]uint8{}, r.take(int(n[0]))...)
+}
+
+func (r *tagReader) cvolume() []uint32 {
+	if !r.expect(tagCVolume) {
+		return nil
+	}
+	n := r.take(1)
+	if n == nil {
+		return nil
+	}
+
+	volumes := make([]uint32, int(n[0]))
+	for i := range volumes {
+		b := r.take(4)
+		if b == nil {
+			return nil
+		}
+		volumes[i] = binary.BigEndian.Uint32(b)
+	}
+	return volumes
+}
+
+// propList returns the string-valued properties; binary values are dropped.
+func (r *tagReader) propList() map[string]string {
+	if !r.expect(tagPropList) {
+		return nil
+	}
+
+	props := make(map[string]string)
+	for r.err == nil {
+		key := r.string()
+		if key == "" {
+			break
+		}
+		length := r.u32()
+		value := r.arbitrary()
+		if r.err != nil {
+			break
+		}
+		if int(length) != len(value) {
+			r.err = fmt.Errorf("proplist entry %s: length mismatch", key)
+			break
+		}
+		if n := len(value); n > 0 && value[n-1] == 0 {
+			props[key] = string(value[:n-1])
+		}
+	}
+	return props
+}
+
+func (r *tagReader) formatInfo() {
+	if !r.expect(tagFormatInfo) {
+		return
+	}
+	r.u8()
+	r.propList()
+}
@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagstruct_RoundTrip(t *testing.T) {
+	w := &tagWriter{}
+	w.putU32(42)
+	w.putString("alsa_output.usb")
+	w.putString("")
+	w.putBool(true)
+	w.putBool(false)
+	w.putU8(3)
+	w.putUsec(1500)
+	w.putVolume(volumeNorm)
+	w.putSampleSpec(sampleSpec{Format: 3, Channels: 2, Rate: 48000})
+	w.putChannelMap([]uint8{1, 2})
+	w.putCVolume([]uint32{volumeNorm, volumeNorm / 2})
+	w.putArbitrary([]byte{1, 2, 3})
+	w.putPropList(map[string]string{"application.name": "mpv", "media.role": "video"})
+
+	r := newTagReader(w.bytes())
+	assert.Equal(t, uint32(42), r.u32())
+	assert.Equal(t, "alsa_output.usb", r.string())
+	assert.Equal(t, "", r.string())
+	assert.True(t, r.bool())
+	assert.False(t, r.bool())
+	assert.Equal(t, uint8(3), r.u8())
+	assert.Equal(t, uint64(1500), r.usec())
+	assert.Equal(t, uint32(volumeNorm), r.volume())
+	assert.Equal(t, sampleSpec{Format: 3, Channels: 2, Rate: 48000}, r.sampleSpec())
+	assert.Equal(t, []uint8{1, 2}, r.channelMap())
+	assert.Equal(t, []uint32{volumeNorm, volumeNorm / 2}, r.cvolume())
+	assert.Equal(t, []byte{1, 2, 3}, r.arbitrary())
+	assert.Equal(t, map[string]string{"application.name": "mpv", "media.role": "video"}, r.propList())
+
+	require.NoError(t, r.err)
+	assert.True(t, r.eof())
+}
+
+func TestTagReader_Errors(t *testing.T) {
+	r := newTagReader([]byte{tagU32, 0, 0})
+	assert.Equal(t, uint32(0), r.u32())
+	assert.ErrorIs(t, r.err, errShortTagstruct)
+
+	// The first error sticks.
+	assert.Equal(t, "", r.string())
+	assert.ErrorIs(t, r.err, errShortTagstruct)
+
+	r = newTagReader([]byte{tagString, 'a', 'b'})
+	assert.Equal(t, "", r.string())
+	assert.ErrorIs(t, r.err, errShortTagstruct)
+
+	r = newTagReader([]byte{tagBooleanTrue})
+	r.u32()
+	assert.ErrorContains(t, r.err, "unexpected tag")
+}
+
+func TestVolumeScaling(t *testing.T) {
+	peak, channels := volumeFractions([]uint32{volumeNorm / 2, volumeNorm / 4})
+	assert.Equal(t, 0.5, peak)
+	assert.Equal(t, []float64{0.5, 0.25}, channels)
+
+	assert.Equal(t, []uint32{volumeNorm, volumeNorm / 2}, scaleVolumes([]uint32{volumeNorm / 2, volumeNorm / 4}, 1))
+	assert.Equal(t, []uint32{volumeNorm / 2, volumeNorm / 2}, scaleVolumes([]uint32{0, 0}, 0.5))
+	assert.Equal(t, []uint32{volumeNorm * 3 / 2}, scaleVolumes([]uint32{volumeNorm}, 4))
+	assert.Equal(t, []uint32{0}, scaleVolumes([]uint32{volumeNorm}, -1))
+}
+
+func TestIndexOrNone(t *testing.T) {
+	assert.Equal(t, int64(-1), indexOrNone(invalidIndex))
+	assert.Equal(t, int64(3), indexOrNone(3))
+}
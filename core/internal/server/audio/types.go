@@ -0,0 +1,85 @@
+package audio
+
+import (
+	"net"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Port struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Priority     uint32 `json:"priority"`
+	Availability string `json:"availability"`
+}
+
+// Device is a sink or source. Volume is the loudest channel as a fraction
+// of 100%, which is what sliders show and what setVolume scales.
+type Device struct {
+	Index          uint32    `json:"index"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	IconName       string    `json:"iconName"`
+	FormFactor     string    `json:"formFactor"`
+	Volume         float64   `json:"volume"`
+	ChannelVolumes []float64 `json:"channelVolumes"`
+	Mute           bool      `json:"mute"`
+	State          string    `json:"state"`
+	Card           int64     `json:"card"`
+	Ports          []Port    `json:"ports"`
+	ActivePort     string    `json:"activePort"`
+	MonitorOf      string    `json:"monitorOf,omitempty"`
+	IsDefault      bool      `json:"isDefault"`
+
+	volumes []uint32
+}
+
+// Stream is a per-application sink input (playback) or source output
+// (recording). Device is the index of the sink or source it is attached to.
+type Stream struct {
+	Index          uint32    `json:"index"`
+	Name           string    `json:"name"`
+	Application    string    `json:"application"`
+	Binary         string    `json:"binary"`
+	IconName       string    `json:"iconName"`
+	Client         int64     `json:"client"`
+	Device         uint32    `json:"device"`
+	DeviceName     string    `json:"deviceName"`
+	Volume         float64   `json:"volume"`
+	ChannelVolumes []float64 `json:"channelVolumes"`
+	Mute           bool      `json:"mute"`
+	Corked         bool      `json:"corked"`
+	HasVolume      bool      `json:"hasVolume"`
+	VolumeWritable bool      `json:"volumeWritable"`
+
+	volumes []uint32
+}
+
+type State struct {
+	Available     bool     `json:"available"`
+	ServerName    string   `json:"serverName"`
+	ServerVersion string   `json:"serverVersion"`
+	DefaultSink   string   `json:"defaultSink"`
+	DefaultSource string   `json:"defaultSource"`
+	Sinks         []Device `json:"sinks"`
+	Sources       []Device `json:"sources"`
+	SinkInputs    []Stream `json:"sinkInputs"`
+	SourceOutputs []Stream `json:"sourceOutputs"`
+}
+
+type Manager struct {
+	dial   func() (net.Conn, error)
+	cookie []byte
+
+	connMutex sync.RWMutex
+	pulse     *pulseConn
+
+	refreshMutex sync.Mutex
+	stateMutex   sync.RWMutex
+	state        *State
+
+	subscribers syncmap.Map[string, chan State]
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
@@ -0,0 +1,217 @@
+package brightness
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	sensorProxyDest      = "net.hadess.SensorProxy"
+	sensorProxyPath      = "/net/hadess/SensorProxy"
+	sensorProxyInterface = "net.hadess.SensorProxy"
+	dbusPropsInterface   = "org.freedesktop.DBus.Properties"
+)
+
+type SensorConn interface {
+	Object(dest string, path dbus.ObjectPath) dbus.BusObject
+	Signal(ch chan<- *dbus.Signal)
+	RemoveSignal(ch chan<- *dbus.Signal)
+	AddMatchSignal(options ...dbus.MatchOption) error
+	RemoveMatchSignal(options ...dbus.MatchOption) error
+	Close() error
+}
+
+// LightSensor reads the ambient light level from iio-sensor-proxy. The
+// proxy only polls the sensor while some client holds a claim, and drops
+// the claim when that client's bus connection goes away.
+type LightSensor struct {
+	conn SensorConn
+	obj  dbus.BusObject
+
+	mu       sync.Mutex
+	claimed  bool
+	signals  chan *dbus.Signal
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+
+	onLevel     func(level float64, unit string)
+	onAvailable func(available bool)
+	unit        string
+}
+
+func NewLightSensor() (*LightSensor, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect to system bus: %w", err)
+	}
+	return NewLightSensorWithConn(conn), nil
+}
+
+func NewLightSensorWithConn(conn SensorConn) *LightSensor {
+	return &LightSensor{
+		conn: conn,
+		obj:  conn.Object(sensorProxyDest, sensorProxyPath),
+		unit: "lux",
+	}
+}
+
+func (s *LightSensor) Available() bool {
+	v, err := s.obj.GetProperty(sensorProxyInterface + ".HasAmbientLight")
+	if err != nil {
+		return false
+	}
+	available, _ := v.Value().(bool)
+	return available
+}
+
+func (s *LightSensor) level() (float64, string, error) {
+	v, err := s.obj.GetProperty(sensorProxyInterface + ".LightLevel")
+	if err != nil {
+		return 0, "", err
+	}
+	level, ok := v.Value().(float64)
+	if !ok {
+		return 0, "", fmt.Errorf("unexpected LightLevel type %T", v.Value())
+	}
+
+	unit := "lux"
+	if u, err := s.obj.GetProperty(sensorProxyInterface + ".LightLevelUnit"); err == nil {
+		if str, ok := u.Value().(string); ok && str != "" {
+			unit = str
+		}
+	}
+	return level, unit, nil
+}
+
+// Claim starts light readings. onLevel is called with the current level
+// right away and on every change; onAvailable when the sensor goes away or
+// comes back.
+func (s *LightSensor) Claim(onLevel func(level float64, unit string), onAvailable func(available bool)) error {
+	s.mu.Lock()
+
+	if s.claimed {
+		s.mu.Unlock()
+		return nil
+	}
+
+	if err := s.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(sensorProxyPath),
+		dbus.WithMatchInterface(dbusPropsInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+	); err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to watch sensor proxy: %w", err)
+	}
+
+	if call := s.obj.Call(sensorProxyInterface+".ClaimLight", 0); call.Err != nil {
+		s.removeMatch()
+		s.mu.Unlock()
+		return fmt.Errorf("failed to claim light sensor: %w", call.Err)
+	}
+
+	level, unit, err := s.level()
+	if err == nil {
+		s.unit = unit
+	}
+
+	s.onLevel = onLevel
+	s.onAvailable = onAvailable
+	s.signals = make(chan *dbus.Signal, 32)
+	s.stopChan = make(chan struct{})
+	s.conn.Signal(s.signals)
+	s.claimed = true
+
+	s.wg.Add(1)
+	go s.signalPump(s.signals, s.stopChan)
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Debugf("ALS: initial light level unavailable: %v", err)
+		return nil
+	}
+	onLevel(level, unit)
+	return nil
+}
+
+func (s *LightSensor) Release() {
+	s.mu.Lock()
+	if !s.claimed {
+		s.mu.Unlock()
+		return
+	}
+	s.claimed = false
+	close(s.stopChan)
+	s.conn.RemoveSignal(s.signals)
+	s.removeMatch()
+
+	if call := s.obj.Call(sensorProxyInterface+".ReleaseLight", 0); call.Err != nil {
+		log.Debugf("ALS: failed to release light sensor: %v", call.Err)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *LightSensor) Close() {
+	s.Release()
+	s.conn.Close()
+}
+
+func (s *LightSensor) removeMatch() {
+	_ = s.conn.RemoveMatchSignal(
+		dbus.WithMatchObjectPath(sensorProxyPath),
+		dbus.WithMatchInterface(dbusPropsInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+	)
+}
+
+func (s *LightSensor) signalPump(signals chan *dbus.Signal, stop chan struct{}) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			s.handleSignal(sig)
+		}
+	}
+}
+
+func (s *LightSensor) handleSignal(sig *dbus.Signal) {
+	if sig == nil || sig.Path != sensorProxyPath || sig.Name != dbusPropsInterface+".PropertiesChanged" {
+		return
+	}
+	if len(sig.Body) < 2 {
+		return
+	}
+	if iface, _ := sig.Body[0].(string); iface != sensorProxyInterface {
+		return
+	}
+	changed, ok := sig.Body[1].(map[string]dbus.Variant)
+	if !ok {
+		return
+	}
+
+	if v, ok := changed["LightLevelUnit"]; ok {
+		if unit, ok := v.Value().(string); ok && unit != "" {
+			s.unit = unit
+		}
+	}
+	if v, ok := changed["HasAmbientLight"]; ok {
+		if available, ok := v.Value().(bool); ok && s.onAvailable != nil {
+			s.onAvailable(available)
+		}
+	}
+	if v, ok := changed["LightLevel"]; ok {
+		if level, ok := v.Value().(float64); ok && s.onLevel != nil {
+			s.onLevel(level, s.unit)
+		}
+	}
+}
@@ -0,0 +1,580 @@
+package brightness
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+type CurvePoint struct {
+	Lux     float64 `json:"lux"`
+	Percent int     `json:"percent"`
+}
+
+// AutoConfig is persisted to auto-brightness.json. Devices lists the
+// devices auto-brightness drives; when empty every backlight is used.
+// Curves overrides Curve per device ID.
+type AutoConfig struct {
+	Enabled    bool                    `json:"enabled"`
+	Devices    []string                `json:"devices"`
+	Curve      []CurvePoint            `json:"curve"`
+	Curves     map[string][]CurvePoint `json:"curves"`
+	Smoothing  float64                 `json:"smoothing"`
+	Hysteresis int                     `json:"hysteresis"`
+}
+
+type AutoState struct {
+	Available bool     `json:"available"`
+	Enabled   bool     `json:"enabled"`
+	Lux       float64  `json:"lux"`
+	Unit      string   `json:"unit"`
+	Paused    []string `json:"paused"`
+}
+
+func DefaultAutoConfig() AutoConfig {
+	return AutoConfig{
+		Devices: []string{},
+		Curve: []CurvePoint{
+			{Lux: 0, Percent: 5},
+			{Lux: 10, Percent: 20},
+			{Lux: 100, Percent: 40},
+			{Lux: 1000, Percent: 75},
+			{Lux: 10000, Percent: 100},
+		},
+		Curves:     map[string][]CurvePoint{},
+		Smoothing:  0.6,
+		Hysteresis: 5,
+	}
+}
+
+func getAutoConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "auto-brightness.json"), nil
+}
+
+func loadAutoConfig(path string) AutoConfig {
+	cfg := DefaultAutoConfig()
+	if path == "" {
+		return cfg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Warnf("Invalid auto-brightness config %s: %v", path, err)
+		return DefaultAutoConfig()
+	}
+	if cfg.Devices == nil {
+		cfg.Devices = []string{}
+	}
+	if cfg.Curves == nil {
+		cfg.Curves = map[string][]CurvePoint{}
+	}
+	if err := validateCurve(cfg.Curve); err != nil {
+		cfg.Curve = DefaultAutoConfig().Curve
+	}
+	return cfg
+}
+
+func saveAutoConfig(path string, cfg AutoConfig) error {
+	if path == "" {
+		return fmt.Errorf("no config directory")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func validateCurve(points []CurvePoint) error {
+	if len(points) == 0 {
+		return fmt.Errorf("curve needs at least one point")
+	}
+	for _, p := range points {
+		if p.Lux < 0 || math.IsNaN(p.Lux) || math.IsInf(p.Lux, 0) {
+			return fmt.Errorf("invalid lux value: %v", p.Lux)
+		}
+		if p.Percent < 0 || p.Percent > 100 {
+			return fmt.Errorf("percent out of range: %d", p.Percent)
+		}
+	}
+	return nil
+}
+
+// curvePercent interpolates the curve in log(1+lux) space, which is closer
+// to how brightness is perceived than linear lux. Points must be sorted.
+func curvePercent(points []CurvePoint, lux float64) int {
+	if len(points) == 0 {
+		return 0
+	}
+	if lux <= points[0].Lux {
+		return points[0].Percent
+	}
+	last := points[len(points)-1]
+	if lux >= last.Lux {
+		return last.Percent
+	}
+
+	x := math.Log1p(lux)
+	for i := 1; i < len(points); i++ {
+		hi := points[i]
+		if lux > hi.Lux {
+			continue
+		}
+		lo := points[i-1]
+		x0, x1 := math.Log1p(lo.Lux), math.Log1p(hi.Lux)
+		if x1 == x0 {
+			return hi.Percent
+		}
+		t := (x - x0) / (x1 - x0)
+		return int(math.Round(float64(lo.Percent) + t*float64(hi.Percent-lo.Percent)))
+	}
+	return last.Percent
+}
+
+func sortCurve(points []CurvePoint) []CurvePoint {
+	sorted := slices.Clone(points)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Lux < sorted[j].Lux })
+	return sorted
+}
+
+// autoController maps ambient light readings to brightness. A manual change
+// to a driven device pauses that device until it is resumed or auto mode is
+// toggled.
+type autoController struct {
+	configPath string
+	newSensor  func() (*LightSensor, error)
+	devices    func() []Device
+	apply      func(deviceID string, percent int) error
+	notify     func()
+
+	// sensorMutex serializes claim/release and is always taken before mu.
+	sensorMutex sync.Mutex
+	sensor      *LightSensor
+
+	mu        sync.Mutex
+	config    AutoConfig
+	available bool
+	lux       float64
+	hasLux    bool
+	unit      string
+	applied   map[string]int
+	paused    map[string]bool
+}
+
+func newAutoController(configPath string, newSensor func() (*LightSensor, error), devices func() []Device, apply func(string, int) error, notify func()) *autoController {
+	return &autoController{
+		configPath: configPath,
+		newSensor:  newSensor,
+		devices:    devices,
+		apply:      apply,
+		notify:     notify,
+		config:     loadAutoConfig(configPath),
+		unit:       "lux",
+		applied:    make(map[string]int),
+		paused:     make(map[string]bool),
+	}
+}
+
+func (a *autoController) start() {
+	a.mu.Lock()
+	enabled := a.config.Enabled
+	a.mu.Unlock()
+
+	if !enabled {
+		return
+	}
+	if err := a.claim(); err != nil {
+		log.Infof("Auto-brightness unavailable: %v", err)
+	}
+}
+
+func (a *autoController) claim() error {
+	a.sensorMutex.Lock()
+	defer a.sensorMutex.Unlock()
+
+	if a.sensor == nil {
+		sensor, err := a.newSensor()
+		if err != nil {
+			return err
+		}
+		a.sensor = sensor
+	}
+
+	available := a.sensor.Available()
+	a.setAvailable(available)
+	if !available {
+		return fmt.Errorf("no ambient light sensor")
+	}
+
+	return a.sensor.Claim(a.handleLevel, a.setAvailable)
+}
+
+func (a *autoController) release() {
+	a.sensorMutex.Lock()
+	defer a.sensorMutex.Unlock()
+
+	if a.sensor != nil {
+		a.sensor.Release()
+	}
+}
+
+func (a *autoController) close() {
+	a.sensorMutex.Lock()
+	defer a.sensorMutex.Unlock()
+
+	if a.sensor != nil {
+		a.sensor.Close()
+		a.sensor = nil
+	}
+}
+
+func (a *autoController) setAvailable(available bool) {
+	a.mu.Lock()
+	changed := a.available != available
+	a.available = available
+	if !available {
+		a.hasLux = false
+	}
+	a.mu.Unlock()
+
+	if changed {
+		a.notify()
+	}
+}
+
+func (a *autoController) handleLevel(level float64, unit string) {
+	a.mu.Lock()
+	if a.hasLux {
+		a.lux = a.config.Smoothing*a.lux + (1-a.config.Smoothing)*level
+	} else {
+		a.lux = level
+		a.hasLux = true
+	}
+	a.unit = unit
+	a.mu.Unlock()
+
+	a.evaluate(false)
+}
+
+func (a *autoController) targets(devices []Device) []string {
+	var ids []string
+	for _, dev := range devices {
+		if len(a.config.Devices) == 0 {
+			if dev.Class == ClassBacklight {
+				ids = append(ids, dev.ID)
+			}
+			continue
+		}
+		if slices.Contains(a.config.Devices, dev.ID) {
+			ids = append(ids, dev.ID)
+		}
+	}
+	return ids
+}
+
+// evaluate applies the curve to every driven device that is not paused.
+// Changes smaller than the hysteresis are skipped unless force is set.
+func (a *autoController) evaluate(force bool) {
+	devices := a.devices()
+
+	type change struct {
+		id      string
+		percent int
+	}
+	var changes []change
+
+	a.mu.Lock()
+	if !a.config.Enabled || !a.hasLux {
+		a.mu.Unlock()
+		return
+	}
+	for _, id := range a.targets(devices) {
+		if a.paused[id] {
+			continue
+		}
+		curve := a.config.Curve
+		if c, ok := a.config.Curves[id]; ok {
+			curve = c
+		}
+		target := curvePercent(curve, a.lux)
+
+		last, ok := a.applied[id]
+		if ok && !force {
+			diff := target - last
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff < a.config.Hysteresis {
+				continue
+			}
+		}
+		a.applied[id] = target
+		changes = append(changes, change{id: id, percent: target})
+	}
+	a.mu.Unlock()
+
+	for _, c := range changes {
+		log.Debugf("Auto-brightness: %s to %d%%", c.id, c.percent)
+		if err := a.apply(c.id, c.percent); err != nil {
+			log.Debugf("Auto-brightness: failed to set %s: %v", c.id, err)
+		}
+	}
+}
+
+// noteManual pauses auto-brightness for a device the user just changed.
+func (a *autoController) noteManual(deviceID string) {
+	devices := a.devices()
+
+	a.mu.Lock()
+	if !a.config.Enabled || a.paused[deviceID] || !slices.Contains(a.targets(devices), deviceID) {
+		a.mu.Unlock()
+		return
+	}
+	a.paused[deviceID] = true
+	a.mu.Unlock()
+
+	log.Debugf("Auto-brightness: paused for %s after manual change", deviceID)
+	a.notify()
+}
+
+// noteExternal pauses auto-brightness when a device reports a level that
+// moved away from the last one applied by more than the hysteresis, as
+// happens with brightness keys handled by the kernel or tools like
+// brightnessctl.
+func (a *autoController) noteExternal(deviceID string, percent int) {
+	a.mu.Lock()
+	last, ok := a.applied[deviceID]
+	hysteresis := a.config.Hysteresis
+	a.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	diff := percent - last
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > hysteresis {
+		a.noteManual(deviceID)
+	}
+}
+
+func (a *autoController) Config() AutoConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.config
+	cfg.Devices = slices.Clone(a.config.Devices)
+	cfg.Curve = slices.Clone(a.config.Curve)
+	cfg.Curves = make(map[string][]CurvePoint, len(a.config.Curves))
+	for id, curve := range a.config.Curves {
+		cfg.Curves[id] = slices.Clone(curve)
+	}
+	return cfg
+}
+
+func (a *autoController) State() AutoState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	paused := make([]string, 0, len(a.paused))
+	for id := range a.paused {
+		paused = append(paused, id)
+	}
+	sort.Strings(paused)
+
+	return AutoState{
+		Available: a.available,
+		Enabled:   a.config.Enabled,
+		Lux:       a.lux,
+		Unit:      a.unit,
+		Paused:    paused,
+	}
+}
+
+func (a *autoController) update(fn func(cfg *AutoConfig) error) error {
+	a.mu.Lock()
+	cfg := a.config
+	if err := fn(&cfg); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	if err := saveAutoConfig(a.configPath, cfg); err != nil {
+		a.mu.Unlock()
+		return fmt.Errorf("failed to save auto-brightness config: %w", err)
+	}
+	a.config = cfg
+	a.mu.Unlock()
+
+	a.notify()
+	return nil
+}
+
+func (a *autoController) SetEnabled(enabled bool) error {
+	a.mu.Lock()
+	clear(a.paused)
+	clear(a.applied)
+	a.hasLux = false
+	a.mu.Unlock()
+
+	// Claim before saving so a missing sensor doesn't leave auto-brightness
+	// enabled on disk.
+	if enabled {
+		if err := a.claim(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.update(func(cfg *AutoConfig) error {
+		cfg.Enabled = enabled
+		return nil
+	}); err != nil {
+		if enabled {
+			a.release()
+		}
+		return err
+	}
+
+	if !enabled {
+		a.release()
+		return nil
+	}
+	a.evaluate(true)
+	return nil
+}
+
+// SetCurve replaces the default curve, or the curve for deviceID when set.
+// An empty curve for a device drops its override.
+func (a *autoController) SetCurve(deviceID string, points []CurvePoint) error {
+	if deviceID == "" || len(points) > 0 {
+		if err := validateCurve(points); err != nil {
+			return err
+		}
+	}
+
+	if err := a.update(func(cfg *AutoConfig) error {
+		curves := make(map[string][]CurvePoint, len(cfg.Curves))
+		for id, curve := range cfg.Curves {
+			curves[id] = curve
+		}
+		cfg.Curves = curves
+
+		switch {
+		case deviceID == "":
+			cfg.Curve = sortCurve(points)
+		case len(points) == 0:
+			delete(cfg.Curves, deviceID)
+		default:
+			cfg.Curves[deviceID] = sortCurve(points)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	a.evaluate(true)
+	return nil
+}
+
+func (a *autoController) SetOptions(smoothing *float64, hysteresis *int, devices []string) error {
+	if smoothing != nil && (*smoothing < 0 || *smoothing >= 1) {
+		return fmt.Errorf("smoothing out of range: %v", *smoothing)
+	}
+	if hysteresis != nil && (*hysteresis < 0 || *hysteresis > 100) {
+		return fmt.Errorf("hysteresis out of range: %d", *hysteresis)
+	}
+
+	if err := a.update(func(cfg *AutoConfig) error {
+		if smoothing != nil {
+			cfg.Smoothing = *smoothing
+		}
+		if hysteresis != nil {
+			cfg.Hysteresis = *hysteresis
+		}
+		if devices != nil {
+			cfg.Devices = slices.Clone(devices)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	a.evaluate(true)
+	return nil
+}
+
+// Resume clears a manual override for deviceID, or for every device when
+// deviceID is empty, and reapplies the curve right away.
+func (a *autoController) Resume(deviceID string) {
+	a.mu.Lock()
+	if deviceID == "" {
+		clear(a.paused)
+		clear(a.applied)
+	} else {
+		delete(a.paused, deviceID)
+		delete(a.applied, deviceID)
+	}
+	a.mu.Unlock()
+
+	a.notify()
+	a.evaluate(false)
+}
+
+var errAutoUnavailable = fmt.Errorf("auto-brightness not initialized")
+
+func (m *Manager) AutoConfig() (AutoConfig, error) {
+	if m.auto == nil {
+		return AutoConfig{}, errAutoUnavailable
+	}
+	return m.auto.Config(), nil
+}
+
+func (m *Manager) SetAutoEnabled(enabled bool) error {
+	if m.auto == nil {
+		return errAutoUnavailable
+	}
+	return m.auto.SetEnabled(enabled)
+}
+
+func (m *Manager) SetAutoCurve(deviceID string, points []CurvePoint) error {
+	if m.auto == nil {
+		return errAutoUnavailable
+	}
+	return m.auto.SetCurve(deviceID, points)
+}
+
+func (m *Manager) SetAutoOptions(smoothing *float64, hysteresis *int, devices []string) error {
+	if m.auto == nil {
+		return errAutoUnavailable
+	}
+	return m.auto.SetOptions(smoothing, hysteresis, devices)
+}
+
+func (m *Manager) ResumeAuto(deviceID string) error {
+	if m.auto == nil {
+		return errAutoUnavailable
+	}
+	m.auto.Resume(deviceID)
+	return nil
+}
@@ -0,0 +1,280 @@
+package brightness
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSensorConn struct {
+	mu      sync.Mutex
+	obj     dbus.BusObject
+	signals chan<- *dbus.Signal
+	closed  bool
+}
+
+func (c *fakeSensorConn) Object(dest string, path dbus.ObjectPath) dbus.BusObject {
+	return c.obj
+}
+
+func (c *fakeSensorConn) Signal(ch chan<- *dbus.Signal) {
+	c.mu.Lock()
+	c.signals = ch
+	c.mu.Unlock()
+}
+
+func (c *fakeSensorConn) RemoveSignal(ch chan<- *dbus.Signal) {
+	c.mu.Lock()
+	c.signals = nil
+	c.mu.Unlock()
+}
+
+func (c *fakeSensorConn) AddMatchSignal(options ...dbus.MatchOption) error    { return nil }
+func (c *fakeSensorConn) RemoveMatchSignal(options ...dbus.MatchOption) error { return nil }
+
+func (c *fakeSensorConn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeSensorConn) emit(changed map[string]dbus.Variant) {
+	c.mu.Lock()
+	ch := c.signals
+	c.mu.Unlock()
+
+	ch <- &dbus.Signal{
+		Path: sensorProxyPath,
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{sensorProxyInterface, changed, []string{}},
+	}
+}
+
+func newFakeSensor(t *testing.T, lux float64) *fakeSensorConn {
+	obj := mockdbus.NewMockBusObject(t)
+	obj.EXPECT().GetProperty(sensorProxyInterface+".HasAmbientLight").Return(dbus.MakeVariant(true), nil).Maybe()
+	obj.EXPECT().GetProperty(sensorProxyInterface+".LightLevel").Return(dbus.MakeVariant(lux), nil).Maybe()
+	obj.EXPECT().GetProperty(sensorProxyInterface+".LightLevelUnit").Return(dbus.MakeVariant("lux"), nil).Maybe()
+	obj.EXPECT().Call(sensorProxyInterface+".ClaimLight", dbus.Flags(0)).Return(&dbus.Call{}).Maybe()
+	obj.EXPECT().Call(sensorProxyInterface+".ReleaseLight", dbus.Flags(0)).Return(&dbus.Call{}).Maybe()
+	return &fakeSensorConn{obj: obj}
+}
+
+type appliedLog struct {
+	mu      sync.Mutex
+	percent map[string]int
+	calls   int
+}
+
+func (l *appliedLog) apply(id string, percent int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.percent[id] = percent
+	l.calls++
+	return nil
+}
+
+func (l *appliedLog) get(id string) (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.percent[id], l.calls
+}
+
+func newTestAuto(t *testing.T, conn *fakeSensorConn) (*autoController, *appliedLog) {
+	t.Helper()
+
+	devices := []Device{
+		{Class: ClassBacklight, ID: "backlight:intel_backlight"},
+		{Class: ClassLED, ID: "leds:kbd_backlight"},
+		{Class: ClassDDC, ID: "ddc:i2c-5"},
+	}
+	applied := &appliedLog{percent: make(map[string]int)}
+
+	a := newAutoController(
+		filepath.Join(t.TempDir(), "auto-brightness.json"),
+		func() (*LightSensor, error) { return NewLightSensorWithConn(conn), nil },
+		func() []Device { return devices },
+		applied.apply,
+		func() {},
+	)
+	t.Cleanup(a.close)
+	return a, applied
+}
+
+func TestCurvePercent(t *testing.T) {
+	curve := DefaultAutoConfig().Curve
+
+	assert.Equal(t, 5, curvePercent(curve, 0))
+	assert.Equal(t, 20, curvePercent(curve, 10))
+	assert.Equal(t, 40, curvePercent(curve, 100))
+	assert.Equal(t, 100, curvePercent(curve, 50000))
+
+	// Interpolation happens in log space, so 31 lux sits about halfway
+	// between the 10 and 100 lux points.
+	assert.InDelta(t, 30, curvePercent(curve, 31), 1)
+
+	assert.Equal(t, 60, curvePercent([]CurvePoint{{Lux: 50, Percent: 60}}, 5))
+	assert.Equal(t, 0, curvePercent(nil, 5))
+}
+
+func TestValidateCurve(t *testing.T) {
+	assert.Error(t, validateCurve(nil))
+	assert.ErrorContains(t, validateCurve([]CurvePoint{{Lux: -1, Percent: 5}}), "invalid lux")
+	assert.ErrorContains(t, validateCurve([]CurvePoint{{Lux: 1, Percent: 101}}), "out of range")
+	assert.NoError(t, validateCurve([]CurvePoint{{Lux: 0, Percent: 0}}))
+}
+
+func TestAutoConfig_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auto-brightness.json")
+
+	cfg := loadAutoConfig(path)
+	assert.Equal(t, DefaultAutoConfig(), cfg)
+
+	cfg.Enabled = true
+	cfg.Curves["backlight:intel_backlight"] = []CurvePoint{{Lux: 0, Percent: 10}}
+	require.NoError(t, saveAutoConfig(path, cfg))
+	assert.Equal(t, cfg, loadAutoConfig(path))
+
+	assert.Error(t, saveAutoConfig("", cfg))
+}
+
+func TestAutoController_AppliesCurveToBacklights(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, applied := newTestAuto(t, conn)
+
+	require.NoError(t, a.SetEnabled(true))
+
+	percent, calls := applied.get("backlight:intel_backlight")
+	assert.Equal(t, 40, percent)
+	assert.Equal(t, 1, calls, "only backlights are driven by default")
+
+	state := a.State()
+	assert.True(t, state.Available)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, 100.0, state.Lux)
+	assert.Equal(t, "lux", state.Unit)
+}
+
+func TestAutoController_SmoothingAndHysteresis(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, applied := newTestAuto(t, conn)
+	require.NoError(t, a.SetEnabled(true))
+
+	// 0.6*100 + 0.4*110 = 104 lux maps to the same percent band.
+	a.handleLevel(110, "lux")
+	_, calls := applied.get("backlight:intel_backlight")
+	assert.Equal(t, 1, calls)
+	assert.InDelta(t, 104, a.State().Lux, 0.001)
+
+	smoothing := 0.0
+	require.NoError(t, a.SetOptions(&smoothing, nil, nil))
+	conn.emit(map[string]dbus.Variant{"LightLevel": dbus.MakeVariant(1000.0)})
+
+	require.Eventually(t, func() bool {
+		percent, _ := applied.get("backlight:intel_backlight")
+		return percent == 75
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestAutoController_ManualOverridePauses(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, applied := newTestAuto(t, conn)
+	require.NoError(t, a.SetEnabled(true))
+
+	a.noteManual("backlight:intel_backlight")
+	a.noteManual("leds:kbd_backlight")
+	assert.Equal(t, []string{"backlight:intel_backlight"}, a.State().Paused)
+
+	a.handleLevel(10000, "lux")
+	a.handleLevel(10000, "lux")
+	percent, _ := applied.get("backlight:intel_backlight")
+	assert.Equal(t, 40, percent)
+
+	a.Resume("")
+	assert.Empty(t, a.State().Paused)
+	percent, _ = applied.get("backlight:intel_backlight")
+	assert.Greater(t, percent, 40)
+}
+
+func TestAutoController_ExternalChangePauses(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, _ := newTestAuto(t, conn)
+
+	a.noteExternal("backlight:intel_backlight", 90)
+	assert.Empty(t, a.State().Paused, "nothing applied yet")
+
+	require.NoError(t, a.SetEnabled(true))
+
+	a.noteExternal("backlight:intel_backlight", 43)
+	assert.Empty(t, a.State().Paused, "within hysteresis")
+
+	a.noteExternal("backlight:intel_backlight", 70)
+	assert.Equal(t, []string{"backlight:intel_backlight"}, a.State().Paused)
+}
+
+func TestAutoController_DeviceCurveAndSelection(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, applied := newTestAuto(t, conn)
+
+	hysteresis := 0
+	require.NoError(t, a.SetOptions(nil, &hysteresis, []string{"ddc:i2c-5"}))
+	require.NoError(t, a.SetCurve("ddc:i2c-5", []CurvePoint{{Lux: 1000, Percent: 90}, {Lux: 0, Percent: 30}}))
+	require.NoError(t, a.SetEnabled(true))
+
+	percent, calls := applied.get("ddc:i2c-5")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, curvePercent([]CurvePoint{{Lux: 0, Percent: 30}, {Lux: 1000, Percent: 90}}, 100), percent)
+
+	cfg := a.Config()
+	assert.Equal(t, 0.0, cfg.Curves["ddc:i2c-5"][0].Lux, "curve points are stored sorted")
+
+	require.NoError(t, a.SetCurve("ddc:i2c-5", nil))
+	assert.NotContains(t, a.Config().Curves, "ddc:i2c-5")
+	assert.Error(t, a.SetCurve("", nil))
+}
+
+func TestAutoController_SensorUnplugged(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, _ := newTestAuto(t, conn)
+	require.NoError(t, a.SetEnabled(true))
+
+	conn.emit(map[string]dbus.Variant{"HasAmbientLight": dbus.MakeVariant(false)})
+	require.Eventually(t, func() bool { return !a.State().Available }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.SetEnabled(false))
+	assert.False(t, a.State().Enabled)
+}
+
+func TestManager_ManualSetPausesAuto(t *testing.T) {
+	conn := newFakeSensor(t, 100)
+	a, _ := newTestAuto(t, conn)
+	require.NoError(t, a.SetEnabled(true))
+
+	m := &Manager{stopChan: make(chan struct{}), auto: a}
+	err := m.SetBrightnessWithExponent("backlight:intel_backlight", 50, false, 1.2)
+	assert.ErrorContains(t, err, "device not found")
+
+	state := m.GetState()
+	require.NotNil(t, state.Auto)
+	assert.Equal(t, []string{"backlight:intel_backlight"}, state.Auto.Paused)
+}
+
+func TestAutoController_EnableWithoutSensorNotPersisted(t *testing.T) {
+	obj := mockdbus.NewMockBusObject(t)
+	obj.EXPECT().GetProperty(sensorProxyInterface+".HasAmbientLight").Return(dbus.MakeVariant(false), nil).Maybe()
+	a, applied := newTestAuto(t, &fakeSensorConn{obj: obj})
+
+	assert.ErrorContains(t, a.SetEnabled(true), "no ambient light sensor")
+	assert.False(t, a.State().Enabled)
+	assert.False(t, loadAutoConfig(a.configPath).Enabled)
+
+	_, calls := applied.get("backlight:intel_backlight")
+	assert.Equal(t, 0, calls)
+}
@@ -21,6 +21,16 @@ func HandleRequest(conn net.Conn, req models.Request, m *Manager) {
 		handleDecrement(conn, req, m)
 	case "brightness.rescan":
 		handleRescan(conn, req, m)
+	case "brightness.auto.getConfig":
+		handleAutoGetConfig(conn, req, m)
+	case "brightness.auto.setEnabled":
+		handleAutoSetEnabled(conn, req, m)
+	case "brightness.auto.setCurve":
+		handleAutoSetCurve(conn, req, m)
+	case "brightness.auto.setConfig":
+		handleAutoSetConfig(conn, req, m)
+	case "brightness.auto.resume":
+		handleAutoResume(conn, req, m)
 	case "brightness.subscribe":
 		handleSubscribe(conn, req, m)
 	default:
@@ -99,6 +109,115 @@ func handleRescan(conn net.Conn, req models.Request, m *Manager) {
 	models.Respond(conn, req.ID, m.GetState())
 }
 
+func handleAutoGetConfig(conn net.Conn, req models.Request, m *Manager) {
+	cfg, err := m.AutoConfig()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, cfg)
+}
+
+func handleAutoSetEnabled(conn net.Conn, req models.Request, m *Manager) {
+	enabled, err := params.Bool(req.Params, "enabled")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := m.SetAutoEnabled(enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, m.GetState())
+}
+
+func handleAutoSetCurve(conn net.Conn, req models.Request, m *Manager) {
+	raw, ok := params.Any(req.Params, "points")
+	if !ok {
+		models.RespondError(conn, req.ID, "missing 'points' parameter")
+		return
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		models.RespondError(conn, req.ID, "invalid 'points' parameter format")
+		return
+	}
+
+	var points []CurvePoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid curve points: %v", err))
+		return
+	}
+
+	device := params.StringOpt(req.Params, "device", "")
+	if err := m.SetAutoCurve(device, points); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	cfg, _ := m.AutoConfig()
+	models.Respond(conn, req.ID, cfg)
+}
+
+func handleAutoSetConfig(conn net.Conn, req models.Request, m *Manager) {
+	var smoothing *float64
+	if _, ok := params.Any(req.Params, "smoothing"); ok {
+		v, err := params.Float(req.Params, "smoothing")
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		smoothing = &v
+	}
+
+	var hysteresis *int
+	if _, ok := params.Any(req.Params, "hysteresis"); ok {
+		v, err := params.Int(req.Params, "hysteresis")
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		hysteresis = &v
+	}
+
+	var devices []string
+	if raw, ok := params.Any(req.Params, "devices"); ok {
+		list, ok := raw.([]any)
+		if !ok {
+			models.RespondError(conn, req.ID, "invalid 'devices' parameter format")
+			return
+		}
+		devices = make([]string, 0, len(list))
+		for _, item := range list {
+			id, ok := item.(string)
+			if !ok {
+				models.RespondError(conn, req.ID, "invalid 'devices' parameter format")
+				return
+			}
+			devices = append(devices, id)
+		}
+	}
+
+	if err := m.SetAutoOptions(smoothing, hysteresis, devices); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	cfg, _ := m.AutoConfig()
+	models.Respond(conn, req.ID, cfg)
+}
+
+func handleAutoResume(conn net.Conn, req models.Request, m *Manager) {
+	device := params.StringOpt(req.Params, "device", "")
+	if err := m.ResumeAuto(device); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, m.GetState())
+}
+
 func handleSubscribe(conn net.Conn, req models.Request, m *Manager) {
 	clientID := fmt.Sprintf("brightness-%d", req.ID)
 
@@ -19,9 +19,16 @@ func NewManagerWithOptions(exponential bool) (*Manager, error) {
 		exponential: exponential,
 	}
 
+	configPath, err := getAutoConfigPath()
+	if err != nil {
+		log.Debugf("Auto-brightness config unavailable: %v", err)
+	}
+	m.auto = newAutoController(configPath, NewLightSensor, m.devices, m.setAutoBrightness, m.NotifySubscribers)
+
 	go m.initLogind()
 	go m.initSysfs()
 	go m.initDDC()
+	go m.auto.start()
 
 	return m, nil
 }
@@ -67,6 +74,7 @@ func (m *Manager) initSysfs() {
 	m.sysfsReady = true
 	m.updateState()
 	m.initUdev()
+	m.auto.evaluate(false)
 }
 
 func (m *Manager) initUdev() {
@@ -85,6 +93,7 @@ func (m *Manager) initDDC() {
 	log.Info("DDC backend initialized")
 
 	m.updateState()
+	m.auto.evaluate(false)
 }
 
 func (m *Manager) Rescan() {
@@ -196,6 +205,23 @@ func (m *Manager) SetBrightnessWithMode(deviceID string, percent int, exponentia
 }
 
 func (m *Manager) SetBrightnessWithExponent(deviceID string, percent int, exponential bool, exponent float64) error {
+	if m.auto != nil {
+		m.auto.noteManual(deviceID)
+	}
+	return m.setBrightness(deviceID, percent, exponential, exponent)
+}
+
+func (m *Manager) setAutoBrightness(deviceID string, percent int) error {
+	return m.setBrightness(deviceID, percent, m.exponential, 1.2)
+}
+
+func (m *Manager) devices() []Device {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state.Devices
+}
+
+func (m *Manager) setBrightness(deviceID string, percent int, exponential bool, exponent float64) error {
 	if percent < 0 {
 		return fmt.Errorf("percent out of range: %d", percent)
 	}
@@ -26,7 +26,8 @@ type Device struct {
 }
 
 type State struct {
-	Devices []Device `json:"devices"`
+	Devices []Device   `json:"devices"`
+	Auto    *AutoState `json:"auto,omitempty"`
 }
 
 type DeviceUpdate struct {
@@ -45,6 +46,8 @@ type Manager struct {
 
 	exponential bool
 
+	auto *autoController
+
 	stateMutex sync.RWMutex
 	state      State
 
@@ -137,9 +140,7 @@ func (m *Manager) UnsubscribeUpdates(id string) {
 }
 
 func (m *Manager) NotifySubscribers() {
-	m.stateMutex.RLock()
-	state := m.state
-	m.stateMutex.RUnlock()
+	state := m.GetState()
 
 	m.subscribers.Range(func(key string, ch chan State) bool {
 		select {
@@ -152,8 +153,14 @@ func (m *Manager) NotifySubscribers() {
 
 func (m *Manager) GetState() State {
 	m.stateMutex.RLock()
-	defer m.stateMutex.RUnlock()
-	return m.state
+	state := m.state
+	m.stateMutex.RUnlock()
+
+	if m.auto != nil {
+		auto := m.auto.State()
+		state.Auto = &auto
+	}
+	return state
 }
 
 func (m *Manager) Close() {
@@ -181,4 +188,8 @@ func (m *Manager) Close() {
 	if m.ddcBackend != nil {
 		m.ddcBackend.Close()
 	}
+
+	if m.auto != nil {
+		m.auto.close()
+	}
 }
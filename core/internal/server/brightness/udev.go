@@ -233,5 +233,8 @@ func (m *Manager) handleUdevBrightnessChange(deviceID string, rawBrightness int)
 	}
 
 	log.Debugf("Udev brightness change: %s -> %d (%d%%)", deviceID, rawBrightness, percent)
+	if m.auto != nil {
+		m.auto.noteExternal(deviceID, m.sysfsBackend.ValueToPercent(rawBrightness, dev, m.exponential))
+	}
 	m.broadcastDeviceUpdate(deviceID)
 }
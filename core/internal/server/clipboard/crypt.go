@@ -0,0 +1,741 @@
+package clipboard
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/argon2"
+
+	clipboardstore "github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/secretservice"
+)
+
+type EncryptionMode string
+
+const (
+	EncryptionNone       EncryptionMode = "none"
+	EncryptionKeyring    EncryptionMode = "keyring"
+	EncryptionPassphrase EncryptionMode = "passphrase"
+)
+
+// Sealed records start with this byte. Plain records start with the high
+// byte of a big-endian entry ID, which is always zero in practice.
+const sealedMagic byte = 0xE1
+
+var (
+	metaBucket    = []byte("clipboard_meta")
+	cipherInfoKey = []byte("cipher")
+	checkValue    = []byte("dms-clipboard")
+	hashLabel     = []byte("dms-clipboard-hash")
+)
+
+var ErrLocked = errors.New("clipboard history is locked")
+
+type argonParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+var defaultArgonParams = argonParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// cipherInfo is stored in the database and describes how to get the key.
+// Check is a known value sealed with the key, so a wrong passphrase is
+// reported instead of every entry failing to decode.
+type cipherInfo struct {
+	Mode  EncryptionMode `json:"mode"`
+	KeyID string         `json:"keyId"`
+	Salt  []byte         `json:"salt,omitempty"`
+	Argon *argonParams   `json:"argon,omitempty"`
+	Check []byte         `json:"check"`
+}
+
+type EncryptionStatus struct {
+	Mode   EncryptionMode `json:"mode"`
+	Locked bool           `json:"locked"`
+}
+
+// keyStore holds raw keys for keyring mode.
+type keyStore interface {
+	Load(keyID string) ([]byte, error)
+	Store(keyID string, key []byte) error
+	Delete(keyID string) error
+}
+
+type secretServiceKeys struct{}
+
+func keyAttrs(keyID string) map[string]string {
+	return map[string]string{
+		"application": "dms",
+		"dms-item":    "clipboard-key",
+		"key-id":      keyID,
+	}
+}
+
+func (secretServiceKeys) Load(keyID string) ([]byte, error) {
+	sess, err := secretservice.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open secret service: %w", err)
+	}
+	defer sess.Close()
+
+	key, err := sess.Lookup(keyAttrs(keyID))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("clipboard key %s not found in keyring", keyID)
+	}
+	return key, nil
+}
+
+func (secretServiceKeys) Store(keyID string, key []byte) error {
+	sess, err := secretservice.Open()
+	if err != nil {
+		return fmt.Errorf("open secret service: %w", err)
+	}
+	defer sess.Close()
+
+	_, err = sess.Store("DankMaterialShell clipboard history key", keyAttrs(keyID), key)
+	return err
+}
+
+func (secretServiceKeys) Delete(keyID string) error {
+	sess, err := secretservice.Open()
+	if err != nil {
+		return fmt.Errorf("open secret service: %w", err)
+	}
+	defer sess.Close()
+
+	return sess.Delete(keyAttrs(keyID))
+}
+
+type entryCipher struct {
+	info    cipherInfo
+	aead    cipher.AEAD
+	hashKey []byte
+}
+
+func newEntryCipher(key []byte, info cipherInfo) (*entryCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(hashLabel)
+
+	return &entryCipher{info: info, aead: aead, hashKey: mac.Sum(nil)}, nil
+}
+
+func (c *entryCipher) seal(plain []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plain)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return c.aead.Seal(nonce, nonce, plain, nil)
+}
+
+func (c *entryCipher) open(sealed []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, fmt.Errorf("sealed value too short")
+	}
+	return c.aead.Open(nil, sealed[:n], sealed[n:], nil)
+}
+
+// hash replaces the plain FNV hash for sealed entries, so the stored
+// dedup hash can't be used to confirm guesses of short secrets.
+func (c *entryCipher) hash(data []byte) uint64 {
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write(data)
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+func deriveKey(passphrase string, salt []byte, p argonParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, 32)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// createCipher makes a fresh key for mode. In keyring mode the key is
+// saved to the keyring before returning.
+func createCipher(mode EncryptionMode, passphrase string, keys keyStore) (*entryCipher, error) {
+	info := cipherInfo{
+		Mode:  mode,
+		KeyID: hex.EncodeToString(randomBytes(8)),
+	}
+
+	var key []byte
+	switch mode {
+	case EncryptionKeyring:
+		key = randomBytes(32)
+		if err := keys.Store(info.KeyID, key); err != nil {
+			return nil, fmt.Errorf("save key to keyring: %w", err)
+		}
+	case EncryptionPassphrase:
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase required")
+		}
+		params := defaultArgonParams
+		info.Salt = randomBytes(16)
+		info.Argon = &params
+		key = deriveKey(passphrase, info.Salt, params)
+	default:
+		return nil, fmt.Errorf("unknown encryption mode %q", mode)
+	}
+
+	c, err := newEntryCipher(key, info)
+	if err != nil {
+		return nil, err
+	}
+	c.info.Check = c.seal(checkValue)
+	return c, nil
+}
+
+func unlockCipher(info cipherInfo, passphrase string, keys keyStore) (*entryCipher, error) {
+	var key []byte
+	switch info.Mode {
+	case EncryptionKeyring:
+		k, err := keys.Load(info.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		key = k
+	case EncryptionPassphrase:
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase required")
+		}
+		if info.Argon == nil {
+			return nil, fmt.Errorf("missing key derivation parameters")
+		}
+		key = deriveKey(passphrase, info.Salt, *info.Argon)
+	default:
+		return nil, fmt.Errorf("unknown encryption mode %q", info.Mode)
+	}
+
+	c, err := newEntryCipher(key, info)
+	if err != nil {
+		return nil, err
+	}
+	check, err := c.open(info.Check)
+	if err != nil || !bytes.Equal(check, checkValue) {
+		if info.Mode == EncryptionPassphrase {
+			return nil, fmt.Errorf("wrong passphrase")
+		}
+		return nil, fmt.Errorf("keyring key does not match clipboard history")
+	}
+	return c, nil
+}
+
+func loadCipherInfo(tx *bolt.Tx) (*cipherInfo, error) {
+	b := tx.Bucket(metaBucket)
+	if b == nil {
+		return nil, nil
+	}
+	v := b.Get(cipherInfoKey)
+	if v == nil {
+		return nil, nil
+	}
+	var info cipherInfo
+	if err := json.Unmarshal(v, &info); err != nil {
+		return nil, fmt.Errorf("corrupt encryption metadata: %w", err)
+	}
+	return &info, nil
+}
+
+func putCipherInfo(tx *bolt.Tx, info *cipherInfo) error {
+	b, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return b.Delete(cipherInfoKey)
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return b.Put(cipherInfoKey, data)
+}
+
+func isSealed(v []byte) bool {
+	return len(v) > 0 && v[0] == sealedMagic
+}
+
+// recordPinned reports whether the record v is pinned. Sealed records keep
+// the flag in their clear trailer, so this works while history is locked.
+func recordPinned(v []byte) (bool, error) {
+	if !isSealed(v) {
+		entry, err := decodeEntryMeta(v)
+		return entry.Pinned, err
+	}
+	if len(v) < 1+4+4+9 {
+		return false, fmt.Errorf("sealed entry too short")
+	}
+	return v[len(v)-1] == 1, nil
+}
+
+// sealEntry stores the metadata and payload as separate ciphertexts so the
+// history list can be read without decrypting every image. The hash and
+// pinned flag stay in the clear at the end, where extractHash and the
+// pin bookkeeping expect them.
+func sealEntry(c *entryCipher, e Entry) ([]byte, error) {
+	meta := e
+	meta.Data = nil
+	metaPlain, err := encodeEntry(meta)
+	if err != nil {
+		return nil, err
+	}
+	sealedMeta := c.seal(metaPlain)
+	sealedData := c.seal(e.Data)
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte(sealedMagic)
+	binary.Write(buf, binary.BigEndian, uint32(len(sealedMeta)))
+	buf.Write(sealedMeta)
+	binary.Write(buf, binary.BigEndian, uint32(len(sealedData)))
+	buf.Write(sealedData)
+	binary.Write(buf, binary.BigEndian, e.Hash)
+	if e.Pinned {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes(), nil
+}
+
+func openEntry(c *entryCipher, v []byte, withData bool) (Entry, error) {
+	if len(v) < 1+4+4+9 {
+		return Entry{}, fmt.Errorf("sealed entry too short")
+	}
+	body := v[1 : len(v)-9]
+
+	next := func() ([]byte, error) {
+		if len(body) < 4 {
+			return nil, fmt.Errorf("sealed entry truncated")
+		}
+		n := binary.BigEndian.Uint32(body)
+		if uint64(len(body)-4) < uint64(n) {
+			return nil, fmt.Errorf("sealed entry truncated")
+		}
+		part := body[4 : 4+n]
+		body = body[4+n:]
+		return part, nil
+	}
+
+	sealedMeta, err := next()
+	if err != nil {
+		return Entry{}, err
+	}
+	sealedData, err := next()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	metaPlain, err := c.open(sealedMeta)
+	if err != nil {
+		return Entry{}, fmt.Errorf("decrypt entry: %w", err)
+	}
+	e, err := decodeEntryFields(metaPlain, false)
+	if err != nil {
+		return Entry{}, err
+	}
+	e.Hash = extractHash(v)
+	e.Pinned = v[len(v)-1] == 1
+
+	if withData {
+		data, err := c.open(sealedData)
+		if err != nil {
+			return Entry{}, fmt.Errorf("decrypt entry: %w", err)
+		}
+		e.Data = data
+	}
+	return e, nil
+}
+
+func encodeEntryWith(c *entryCipher, e Entry) ([]byte, error) {
+	if c == nil {
+		return encodeEntry(e)
+	}
+	return sealEntry(c, e)
+}
+
+func decodeEntryWith(c *entryCipher, v []byte, withData bool) (Entry, error) {
+	if !isSealed(v) {
+		return decodeEntryFields(v, withData)
+	}
+	if c == nil {
+		return Entry{}, ErrLocked
+	}
+	return openEntry(c, v, withData)
+}
+
+func (m *Manager) getCipher() (*entryCipher, bool) {
+	m.cipherMutex.RLock()
+	defer m.cipherMutex.RUnlock()
+	return m.cipher, m.cipherInfo != nil && m.cipher == nil
+}
+
+func (m *Manager) setCipher(c *entryCipher) {
+	m.cipherMutex.Lock()
+	m.cipher = c
+	if c != nil {
+		info := c.info
+		m.cipherInfo = &info
+	} else {
+		m.cipherInfo = nil
+	}
+	m.cipherMutex.Unlock()
+}
+
+func (m *Manager) encodeEntry(e Entry) ([]byte, error) {
+	c, locked := m.getCipher()
+	if locked {
+		return nil, ErrLocked
+	}
+	return encodeEntryWith(c, e)
+}
+
+func (m *Manager) decodeEntry(v []byte) (Entry, error) {
+	c, _ := m.getCipher()
+	return decodeEntryWith(c, v, true)
+}
+
+func (m *Manager) decodeEntryMeta(v []byte) (Entry, error) {
+	c, _ := m.getCipher()
+	return decodeEntryWith(c, v, false)
+}
+
+func (m *Manager) entryHash(data []byte) uint64 {
+	if c, _ := m.getCipher(); c != nil {
+		return c.hash(data)
+	}
+	return computeHash(data)
+}
+
+func (m *Manager) EncryptionStatus() EncryptionStatus {
+	m.cipherMutex.RLock()
+	defer m.cipherMutex.RUnlock()
+
+	if m.cipherInfo == nil {
+		return EncryptionStatus{Mode: EncryptionNone}
+	}
+	return EncryptionStatus{Mode: m.cipherInfo.Mode, Locked: m.cipher == nil}
+}
+
+func (m *Manager) getKeys() keyStore {
+	if m.keys == nil {
+		return secretServiceKeys{}
+	}
+	return m.keys
+}
+
+// initEncryption reads the encryption metadata at startup. History stays
+// locked until the key is available; keyring keys are fetched in the
+// background since the keyring may show an unlock prompt.
+func (m *Manager) initEncryption() error {
+	var info *cipherInfo
+	if err := m.db.View(func(tx *bolt.Tx) error {
+		var err error
+		info, err = loadCipherInfo(tx)
+		return err
+	}); err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+
+	m.cipherMutex.Lock()
+	m.cipherInfo = info
+	m.cipherMutex.Unlock()
+
+	if info.Mode == EncryptionKeyring {
+		go func() {
+			if err := m.Unlock(""); err != nil {
+				log.Warnf("Clipboard history stays locked: %v", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// StoreOffline adds an entry to the history database while the server is
+// not running, for `dms clipboard import`, `cliphist-migrate` and
+// `watch --store`. The settings, filter rules and MIME lists are read from
+// the same config file as the server's. Encrypted history is sealed with
+// the server's key. A passphrase, or a keyring that can't hand out the key,
+// means the entry is refused with ErrLocked rather than written in the
+// clear.
+func StoreOffline(data []byte, mimeType string, offered []string) error {
+	dbPath, err := clipboardstore.GetDBPath()
+	if err != nil {
+		return fmt.Errorf("get db path: %w", err)
+	}
+	return storeOffline(dbPath, secretServiceKeys{}, LoadConfig(), data, mimeType, offered)
+}
+
+func storeOffline(dbPath string, keys keyStore, cfg Config, data []byte, mimeType string, offered []string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if int64(len(data)) > cfg.MaxEntrySize {
+		return fmt.Errorf("data too large: %d > %d", len(data), cfg.MaxEntrySize)
+	}
+
+	m := &Manager{config: cfg, keys: keys}
+
+	decision := m.getFilter().evaluate(data, mimeType, offered)
+	if decision.drop {
+		log.Debugf("Clipboard entry not stored: %s", decision.reason)
+		return nil
+	}
+
+	db, err := openDB(dbPath)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+	m.db = db
+
+	var info *cipherInfo
+	if err := db.View(func(tx *bolt.Tx) error {
+		info, err = loadCipherInfo(tx)
+		return err
+	}); err != nil {
+		return err
+	}
+	if info != nil {
+		if info.Mode != EncryptionKeyring {
+			return ErrLocked
+		}
+		c, err := unlockCipher(*info, "", keys)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrLocked, err)
+		}
+		m.setCipher(c)
+	}
+
+	entry := m.newEntry(data, mimeType)
+	if decision.expiresIn > 0 {
+		expiresAt := entry.Timestamp.Add(decision.expiresIn)
+		entry.ExpiresAt = &expiresAt
+	}
+	return m.storeEntry(entry)
+}
+
+// Unlock makes sealed history readable. Keyring mode ignores passphrase
+// and retries the keyring.
+func (m *Manager) Unlock(passphrase string) error {
+	m.rekeyMutex.Lock()
+	defer m.rekeyMutex.Unlock()
+
+	m.cipherMutex.RLock()
+	info := m.cipherInfo
+	locked := info != nil && m.cipher == nil
+	m.cipherMutex.RUnlock()
+
+	if !locked {
+		return nil
+	}
+
+	c, err := unlockCipher(*info, passphrase, m.getKeys())
+	if err != nil {
+		return err
+	}
+	m.setCipher(c)
+
+	if err := m.sealPlainEntries(); err != nil {
+		log.Errorf("Failed to encrypt clipboard entries: %v", err)
+	}
+
+	m.updateState()
+	m.notifySubscribers()
+	return nil
+}
+
+// sealPlainEntries encrypts entries written in the clear, e.g. by
+// `dms clipboard import` while the server wasn't running.
+func (m *Manager) sealPlainEntries() error {
+	c, _ := m.getCipher()
+	if c == nil || m.db == nil {
+		return nil
+	}
+
+	var sealed int
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("clipboard"))
+		var err error
+		sealed, err = reencodeBucket(b, nil, c, true)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if sealed == 0 {
+		return nil
+	}
+
+	log.Infof("Encrypted %d clipboard entries", sealed)
+	return m.compactDB()
+}
+
+// reencodeBucket rewrites every entry from the from cipher to the to cipher.
+// With plainOnly set, already sealed entries are left alone.
+func reencodeBucket(b *bolt.Bucket, from, to *entryCipher, plainOnly bool) (int, error) {
+	type update struct {
+		key   []byte
+		value []byte
+	}
+	var updates []update
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if plainOnly && isSealed(v) {
+			continue
+		}
+		entry, err := decodeEntryWith(from, v, true)
+		if err != nil {
+			return 0, fmt.Errorf("entry %d: %w", binary.BigEndian.Uint64(k), err)
+		}
+
+		switch to {
+		case nil:
+			entry.Hash = computeHash(entry.Data)
+		default:
+			entry.Hash = to.hash(entry.Data)
+		}
+
+		encoded, err := encodeEntryWith(to, entry)
+		if err != nil {
+			return 0, err
+		}
+		updates = append(updates, update{append([]byte(nil), k...), encoded})
+	}
+
+	for _, u := range updates {
+		if err := b.Put(u.key, u.value); err != nil {
+			return 0, err
+		}
+	}
+	return len(updates), nil
+}
+
+// Rekey re-encrypts the whole history with a new key, switching to mode.
+// EncryptionNone decrypts it. The previous keyring key is removed once the
+// new one is in place.
+func (m *Manager) Rekey(mode EncryptionMode, passphrase string) error {
+	if m.db == nil {
+		return fmt.Errorf("database not available")
+	}
+
+	switch mode {
+	case "", EncryptionNone:
+		mode = EncryptionNone
+	case EncryptionKeyring, EncryptionPassphrase:
+	default:
+		return fmt.Errorf("unknown encryption mode %q", mode)
+	}
+
+	m.rekeyMutex.Lock()
+	defer m.rekeyMutex.Unlock()
+
+	m.cipherMutex.RLock()
+	oldCipher := m.cipher
+	oldInfo := m.cipherInfo
+	m.cipherMutex.RUnlock()
+
+	if oldInfo != nil && oldCipher == nil {
+		return ErrLocked
+	}
+	if mode == EncryptionNone && oldInfo == nil {
+		return nil
+	}
+
+	keys := m.getKeys()
+
+	var newCipher *entryCipher
+	if mode != EncryptionNone {
+		c, err := createCipher(mode, passphrase, keys)
+		if err != nil {
+			return err
+		}
+		newCipher = c
+	}
+
+	var count int
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		count, err = reencodeBucket(tx.Bucket([]byte("clipboard")), oldCipher, newCipher, false)
+		if err != nil {
+			return err
+		}
+		if err := reencodeOCR(tx, oldCipher, newCipher); err != nil {
+			return err
+		}
+
+		var info *cipherInfo
+		if newCipher != nil {
+			info = &newCipher.info
+		}
+		if err := putCipherInfo(tx, info); err != nil {
+			return err
+		}
+
+		// Switch inside the transaction so no writer can slip in an entry
+		// encoded with the old key between commit and switch.
+		m.setCipher(newCipher)
+		return nil
+	})
+	if err != nil {
+		m.setCipher(oldCipher)
+		if newCipher != nil && mode == EncryptionKeyring {
+			if derr := keys.Delete(newCipher.info.KeyID); derr != nil {
+				log.Warnf("Failed to remove unused clipboard key from keyring: %v", derr)
+			}
+		}
+		return fmt.Errorf("rekey: %w", err)
+	}
+
+	if oldInfo != nil && oldInfo.Mode == EncryptionKeyring {
+		if err := keys.Delete(oldInfo.KeyID); err != nil {
+			log.Warnf("Failed to remove old clipboard key from keyring: %v", err)
+		}
+	}
+
+	log.Infof("Re-encoded %d clipboard entries (encryption: %s)", count, mode)
+
+	// Old pages still hold the previous encoding until the file is rewritten.
+	if err := m.compactDB(); err != nil {
+		log.Errorf("Failed to compact database: %v", err)
+	}
+
+	m.updateState()
+	m.notifySubscribers()
+	return nil
+}
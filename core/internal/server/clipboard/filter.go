@@ -0,0 +1,230 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type RuleAction string
+
+const (
+	RuleActionDrop   RuleAction = "drop"
+	RuleActionExpire RuleAction = "expire"
+)
+
+// FilterRule matches text entries by regex, or by one of the built-in
+// presets when Preset is set. Expiring entries are stored but removed from
+// history ExpireSeconds after the copy.
+type FilterRule struct {
+	Name          string     `json:"name"`
+	Pattern       string     `json:"pattern,omitempty"`
+	Preset        string     `json:"preset,omitempty"`
+	Action        RuleAction `json:"action"`
+	ExpireSeconds int        `json:"expireSeconds,omitempty"`
+	Disabled      bool       `json:"disabled,omitempty"`
+}
+
+var rulePresets = map[string]string{
+	"private-key": `-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----`,
+	"api-token": `\b(?:sk-[A-Za-z0-9_-]{20,}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|` +
+		`glpat-[A-Za-z0-9_-]{20,}|xox[abpors]-[A-Za-z0-9-]{10,}|AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_-]{35})`,
+	"credit-card": `\b(?:\d[ -]?){12,18}\d\b`,
+}
+
+type compiledRule struct {
+	FilterRule
+	re *regexp.Regexp
+}
+
+type contentFilter struct {
+	rules []compiledRule
+	allow []string
+	deny  []string
+}
+
+type filterDecision struct {
+	drop      bool
+	expiresIn time.Duration
+	reason    string
+}
+
+// compileFilter builds the filter for cfg. Invalid rules are skipped and
+// reported in the returned error; the filter is usable either way.
+func compileFilter(cfg Config) (*contentFilter, error) {
+	f := &contentFilter{
+		allow: cfg.MimeAllow,
+		deny:  cfg.MimeDeny,
+	}
+
+	var errs []error
+	for _, pattern := range append(append([]string{}, cfg.MimeAllow...), cfg.MimeDeny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid mime pattern %q: %w", pattern, err))
+		}
+	}
+
+	for i, rule := range cfg.Rules {
+		if rule.Disabled {
+			continue
+		}
+
+		label := rule.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i)
+		}
+
+		switch rule.Action {
+		case RuleActionDrop:
+		case RuleActionExpire:
+			if rule.ExpireSeconds <= 0 {
+				errs = append(errs, fmt.Errorf("rule %s: expireSeconds must be positive", label))
+				continue
+			}
+		default:
+			errs = append(errs, fmt.Errorf("rule %s: unknown action %q", label, rule.Action))
+			continue
+		}
+
+		pattern := rule.Pattern
+		if rule.Preset != "" {
+			preset, ok := rulePresets[rule.Preset]
+			if !ok {
+				errs = append(errs, fmt.Errorf("rule %s: unknown preset %q", label, rule.Preset))
+				continue
+			}
+			pattern = preset
+		}
+		if pattern == "" {
+			errs = append(errs, fmt.Errorf("rule %s: missing pattern", label))
+			continue
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", label, err))
+			continue
+		}
+		f.rules = append(f.rules, compiledRule{FilterRule: rule, re: re})
+	}
+
+	return f, errors.Join(errs...)
+}
+
+func matchMime(patterns []string, mime string) bool {
+	mime = strings.ToLower(mime)
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = strings.TrimSpace(mime[:i])
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), mime); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextMimeType(mime string) bool {
+	switch mime {
+	case "UTF8_STRING", "STRING", "TEXT":
+		return true
+	}
+	return strings.HasPrefix(mime, "text/")
+}
+
+// evaluate decides what to do with data copied as mimeType. offered is the
+// full set of types the source advertised; a deny match on any of them drops
+// the entry, so sources that tag secrets with a private type are caught even
+// when we read text/plain.
+func (f *contentFilter) evaluate(data []byte, mimeType string, offered []string) filterDecision {
+	if f == nil {
+		return filterDecision{}
+	}
+
+	for _, mime := range append([]string{mimeType}, offered...) {
+		if matchMime(f.deny, mime) {
+			return filterDecision{drop: true, reason: "mime type " + mime + " denied"}
+		}
+	}
+	if len(f.allow) > 0 && !matchMime(f.allow, mimeType) {
+		return filterDecision{drop: true, reason: "mime type " + mimeType + " not allowed"}
+	}
+
+	if len(f.rules) == 0 || !isTextMimeType(mimeType) || !utf8.Valid(data) {
+		return filterDecision{}
+	}
+
+	var decision filterDecision
+	for _, rule := range f.rules {
+		if !rule.matches(data) {
+			continue
+		}
+		if rule.Action == RuleActionDrop {
+			return filterDecision{drop: true, reason: "rule " + rule.Name}
+		}
+		expiresIn := time.Duration(rule.ExpireSeconds) * time.Second
+		if decision.expiresIn == 0 || expiresIn < decision.expiresIn {
+			decision = filterDecision{expiresIn: expiresIn, reason: "rule " + rule.Name}
+		}
+	}
+	return decision
+}
+
+func (r compiledRule) matches(data []byte) bool {
+	if r.Preset != "credit-card" {
+		return r.re.Match(data)
+	}
+	for _, m := range r.re.FindAll(data, -1) {
+		if luhnValid(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnValid checks the card number checksum, ignoring separators, to keep
+// the credit-card preset from matching arbitrary digit runs.
+func luhnValid(number []byte) bool {
+	var sum, digits int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+func (m *Manager) getFilter() *contentFilter {
+	m.configMutex.RLock()
+	f := m.filter
+	cfg := m.config
+	m.configMutex.RUnlock()
+
+	if f != nil {
+		return f
+	}
+
+	f, _ = compileFilter(cfg)
+	m.configMutex.Lock()
+	if m.filter == nil {
+		m.filter = f
+	}
+	m.configMutex.Unlock()
+	return f
+}
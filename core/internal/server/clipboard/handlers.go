@@ -47,6 +47,16 @@ func HandleRequest(conn net.Conn, req models.Request, m *Manager) {
 		handleGetPinnedCount(conn, req, m)
 	case "clipboard.copyFile":
 		handleCopyFile(conn, req, m)
+	case "clipboard.getEncryption":
+		handleGetEncryption(conn, req, m)
+	case "clipboard.unlock":
+		handleUnlock(conn, req, m)
+	case "clipboard.rekey":
+		handleRekey(conn, req, m)
+	case "clipboard.sync.status":
+		handleSyncStatus(conn, req, m)
+	case "clipboard.sync.now":
+		handleSyncNow(conn, req, m)
 	default:
 		models.RespondError(conn, req.ID, "unknown method: "+req.Method)
 	}
@@ -243,6 +253,41 @@ func handleSetConfig(conn net.Conn, req models.Request, m *Manager) {
 	if v, ok := models.Get[float64](req, "maxPinned"); ok {
 		cfg.MaxPinned = int(v)
 	}
+	if v, ok := models.Get[any](req, "rules"); ok {
+		if err := decodeParam(v, &cfg.Rules); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'rules' parameter: %v", err))
+			return
+		}
+	}
+	if v, ok := models.Get[any](req, "mimeAllow"); ok {
+		if err := decodeParam(v, &cfg.MimeAllow); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'mimeAllow' parameter: %v", err))
+			return
+		}
+	}
+	if v, ok := models.Get[any](req, "mimeDeny"); ok {
+		if err := decodeParam(v, &cfg.MimeDeny); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'mimeDeny' parameter: %v", err))
+			return
+		}
+	}
+
+	if v, ok := models.Get[any](req, "ocr"); ok {
+		var ocrCfg OCRConfig
+		if err := decodeParam(v, &ocrCfg); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'ocr' parameter: %v", err))
+			return
+		}
+		cfg.OCR = &ocrCfg
+	}
+	if v, ok := models.Get[any](req, "sync"); ok {
+		var syncCfg SyncConfig
+		if err := decodeParam(v, &syncCfg); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'sync' parameter: %v", err))
+			return
+		}
+		cfg.Sync = &syncCfg
+	}
 
 	if err := m.SetConfig(cfg); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
@@ -252,6 +297,14 @@ func handleSetConfig(conn net.Conn, req models.Request, m *Manager) {
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "config updated"})
 }
 
+func decodeParam(v any, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
 func handleStore(conn net.Conn, req models.Request, m *Manager) {
 	data, err := params.String(req.Params, "data")
 	if err != nil {
@@ -323,3 +376,47 @@ func handleCopyFile(conn net.Conn, req models.Request, m *Manager) {
 
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "copied"})
 }
+
+func handleGetEncryption(conn net.Conn, req models.Request, m *Manager) {
+	models.Respond(conn, req.ID, m.EncryptionStatus())
+}
+
+func handleUnlock(conn net.Conn, req models.Request, m *Manager) {
+	passphrase := params.StringOpt(req.Params, "passphrase", "")
+
+	if err := m.Unlock(passphrase); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "history unlocked"})
+}
+
+func handleRekey(conn net.Conn, req models.Request, m *Manager) {
+	mode, err := params.String(req.Params, "mode")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	passphrase := params.StringOpt(req.Params, "passphrase", "")
+
+	if err := m.Rekey(EncryptionMode(mode), passphrase); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "history re-encrypted", Value: mode})
+}
+
+func handleSyncStatus(conn net.Conn, req models.Request, m *Manager) {
+	models.Respond(conn, req.ID, m.SyncStatus())
+}
+
+func handleSyncNow(conn net.Conn, req models.Request, m *Manager) {
+	status, err := m.SyncNow()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, status)
+}
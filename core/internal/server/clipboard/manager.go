@@ -3,6 +3,7 @@ package clipboard
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/gif"
@@ -12,6 +13,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"strings"
 	"syscall"
@@ -39,6 +41,10 @@ var sensitiveMimeTypes = []string{
 	"x-kde-passwordManagerHint",
 }
 
+// expiryBucket maps entry IDs to the unix time they expire at, for entries
+// stored by an expire rule.
+var expiryBucket = []byte("clipboard_expiry")
+
 func NewManager(wlCtx wlcontext.WaylandContext, config Config) (*Manager, error) {
 	display := wlCtx.Display()
 	dbPath, err := clipboardstore.GetDBPath()
@@ -59,6 +65,7 @@ func NewManager(wlCtx wlcontext.WaylandContext, config Config) (*Manager, error)
 		offerMimeTypes: make(map[any][]string),
 		offerRegistry:  make(map[uint32]any),
 		dbPath:         dbPath,
+		ocrTrigger:     make(chan struct{}, 1),
 	}
 
 	if !config.Disabled {
@@ -82,6 +89,16 @@ func NewManager(wlCtx wlcontext.WaylandContext, config Config) (*Manager, error)
 		log.Errorf("Failed to migrate hashes: %v", err)
 	}
 
+	if err := m.initEncryption(); err != nil {
+		log.Errorf("Failed to read clipboard encryption settings: %v", err)
+	}
+
+	if config.Sync != nil {
+		if err := m.restartSync(*config.Sync); err != nil {
+			log.Errorf("Failed to start clipboard sync: %v", err)
+		}
+	}
+
 	if !config.Disabled {
 		if config.ClearAtStartup {
 			if err := m.clearHistoryInternal(); err != nil {
@@ -99,6 +116,9 @@ func NewManager(wlCtx wlcontext.WaylandContext, config Config) (*Manager, error)
 	m.alive = true
 	m.updateState()
 
+	go m.ocrLoop()
+	m.pokeOCR()
+
 	if !config.Disabled && m.dataControlMgr != nil && m.seat != nil {
 		m.setupDataDeviceSync()
 	}
@@ -115,7 +135,10 @@ func openDB(path string) (*bolt.DB, error) {
 	}
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte("clipboard"))
+		if _, err := tx.CreateBucketIfNotExists([]byte("clipboard")); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(expiryBucket)
 		return err
 	})
 	if err != nil {
@@ -273,7 +296,7 @@ func (m *Manager) setupDataDeviceSync() {
 		}
 		w.Close()
 
-		go m.readAndStore(r, preferredMime)
+		go m.readAndStore(r, preferredMime, mimes)
 	})
 
 	if err := dataMgr.GetDataDeviceWithProxy(dataDevice, m.seat); err != nil {
@@ -318,7 +341,7 @@ func (m *Manager) releaseCurrentSource() {
 	source.Destroy()
 }
 
-func (m *Manager) readAndStore(r *os.File, mimeType string) {
+func (m *Manager) readAndStore(r *os.File, mimeType string, offered []string) {
 	defer r.Close()
 
 	cfg := m.getConfig()
@@ -344,14 +367,20 @@ func (m *Manager) readAndStore(r *os.File, mimeType string) {
 	}
 
 	if !cfg.Disabled && m.db != nil {
-		m.storeClipboardEntry(data, mimeType)
+		m.storeClipboardEntry(data, mimeType, offered)
 	}
 
 	m.updateState()
 	m.notifySubscribers()
 }
 
-func (m *Manager) storeClipboardEntry(data []byte, mimeType string) {
+func (m *Manager) storeClipboardEntry(data []byte, mimeType string, offered []string) {
+	decision := m.getFilter().evaluate(data, mimeType, offered)
+	if decision.drop {
+		log.Debugf("Clipboard entry not stored: %s", decision.reason)
+		return
+	}
+
 	if mimeType == "text/uri-list" {
 		if imgData, imgMime, ok := m.tryReadImageFromURI(data); ok {
 			data = imgData
@@ -359,6 +388,29 @@ func (m *Manager) storeClipboardEntry(data []byte, mimeType string) {
 		}
 	}
 
+	entry := m.newEntry(data, mimeType)
+
+	if decision.expiresIn > 0 {
+		expiresAt := entry.Timestamp.Add(decision.expiresIn)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	if err := m.storeEntry(entry); err != nil {
+		if errors.Is(err, ErrLocked) {
+			log.Debugf("Clipboard entry not stored: %v", err)
+			return
+		}
+		log.Errorf("Failed to store clipboard entry: %v", err)
+		return
+	}
+
+	if entry.ExpiresAt != nil {
+		log.Debugf("Clipboard entry expires in %s: %s", decision.expiresIn, decision.reason)
+		m.scheduleExpiry(decision.expiresIn)
+	}
+}
+
+func (m *Manager) newEntry(data []byte, mimeType string) Entry {
 	entry := Entry{
 		Data:      data,
 		MimeType:  mimeType,
@@ -375,10 +427,42 @@ func (m *Manager) storeClipboardEntry(data []byte, mimeType string) {
 	default:
 		entry.Preview = m.textPreview(data)
 	}
+	return entry
+}
 
-	if err := m.storeEntry(entry); err != nil {
-		log.Errorf("Failed to store clipboard entry: %v", err)
+// scheduleExpiry refreshes history once an expiring entry is due;
+// GetHistory drops expired entries from the database.
+func (m *Manager) scheduleExpiry(d time.Duration) {
+	time.AfterFunc(d, func() {
+		select {
+		case <-m.stopChan:
+			return
+		default:
+		}
+		m.updateState()
+		m.notifySubscribers()
+	})
+}
+
+func entryExpiry(tx *bolt.Tx, key []byte) *time.Time {
+	eb := tx.Bucket(expiryBucket)
+	if eb == nil {
+		return nil
 	}
+	v := eb.Get(key)
+	if len(v) != 8 {
+		return nil
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(v)), 0)
+	return &expiresAt
+}
+
+func deleteExpiryInTx(tx *bolt.Tx, key []byte) error {
+	eb := tx.Bucket(expiryBucket)
+	if eb == nil {
+		return nil
+	}
+	return eb.Delete(key)
 }
 
 func (m *Manager) storeEntry(entry Entry) error {
@@ -386,11 +470,10 @@ func (m *Manager) storeEntry(entry Entry) error {
 		return fmt.Errorf("database not available")
 	}
 
-	entry.Hash = computeHash(entry.Data)
-
 	return m.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("clipboard"))
 
+		entry.Hash = m.entryHash(entry.Data)
 		if err := m.deduplicateInTx(b, entry.Hash); err != nil {
 			return err
 		}
@@ -402,7 +485,7 @@ func (m *Manager) storeEntry(entry Entry) error {
 
 		entry.ID = id
 
-		encoded, err := encodeEntry(entry)
+		encoded, err := m.encodeEntry(entry)
 		if err != nil {
 			return err
 		}
@@ -411,6 +494,22 @@ func (m *Manager) storeEntry(entry Entry) error {
 			return err
 		}
 
+		if s := m.getSync(); s != nil {
+			if err := putSyncHash(tx, itob(id), s.hash(entry.Data)); err != nil {
+				return err
+			}
+		}
+
+		if entry.ExpiresAt != nil {
+			eb, err := tx.CreateBucketIfNotExists(expiryBucket)
+			if err != nil {
+				return err
+			}
+			if err := eb.Put(itob(id), itob(uint64(entry.ExpiresAt.Unix()))); err != nil {
+				return err
+			}
+		}
+
 		return m.trimLengthInTx(b)
 	})
 }
@@ -421,13 +520,15 @@ func (m *Manager) deduplicateInTx(b *bolt.Bucket, hash uint64) error {
 		if extractHash(v) != hash {
 			continue
 		}
-		entry, err := decodeEntryMeta(v)
-		if err == nil && entry.Pinned {
+		if pinned, _ := recordPinned(v); pinned {
 			continue
 		}
 		if err := b.Delete(k); err != nil {
 			return err
 		}
+		if err := deleteExpiryInTx(b.Tx(), k); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -439,8 +540,7 @@ func (m *Manager) trimLengthInTx(b *bolt.Bucket) error {
 	c := b.Cursor()
 	var count int
 	for k, v := c.Last(); k != nil; k, v = c.Prev() {
-		entry, err := decodeEntryMeta(v)
-		if err == nil && entry.Pinned {
+		if pinned, _ := recordPinned(v); pinned {
 			continue
 		}
 		if count < m.config.MaxHistory {
@@ -450,6 +550,9 @@ func (m *Manager) trimLengthInTx(b *bolt.Bucket) error {
 		if err := b.Delete(k); err != nil {
 			return err
 		}
+		if err := deleteExpiryInTx(b.Tx(), k); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -715,10 +818,14 @@ func (m *Manager) updateState() {
 		current = &c
 	}
 
+	encryption := m.EncryptionStatus()
+
 	newState := &State{
-		Enabled: m.alive,
-		History: history,
-		Current: current,
+		Enabled:    m.alive,
+		History:    history,
+		Current:    current,
+		Encryption: encryption.Mode,
+		Locked:     encryption.Locked,
 	}
 
 	m.stateMutex.Lock()
@@ -761,6 +868,9 @@ func stateEqual(a, b *State) bool {
 	if a.Enabled != b.Enabled {
 		return false
 	}
+	if a.Encryption != b.Encryption || a.Locked != b.Locked {
+		return false
+	}
 	if len(a.History) != len(b.History) {
 		return false
 	}
@@ -780,13 +890,14 @@ func (m *Manager) GetHistory() []Entry {
 
 	var history []Entry
 	var stale []uint64
+	now := time.Now()
 
 	if err := m.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("clipboard"))
 		c := b.Cursor()
 
 		for k, v := c.Last(); k != nil; k, v = c.Prev() {
-			entry, err := decodeEntryMeta(v)
+			entry, err := m.decodeEntryMeta(v)
 			if err != nil {
 				continue
 			}
@@ -794,6 +905,13 @@ func (m *Manager) GetHistory() []Entry {
 				stale = append(stale, entry.ID)
 				continue
 			}
+			if !entry.Pinned {
+				entry.ExpiresAt = entryExpiry(tx, k)
+				if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+					stale = append(stale, entry.ID)
+					continue
+				}
+			}
 			history = append(history, entry)
 		}
 		return nil
@@ -819,6 +937,9 @@ func (m *Manager) deleteStaleEntries(ids []uint64) {
 			if err := b.Delete(itob(id)); err != nil {
 				log.Errorf("Failed to delete stale entry %d: %v", id, err)
 			}
+			if err := deleteExpiryInTx(tx, itob(id)); err != nil {
+				log.Errorf("Failed to delete expiry for entry %d: %v", id, err)
+			}
 		}
 		return nil
 	}); err != nil {
@@ -842,11 +963,16 @@ func (m *Manager) GetEntry(id uint64) (*Entry, error) {
 		}
 
 		var err error
-		entry, err = decodeEntry(v)
+		entry, err = m.decodeEntry(v)
 		if err != nil {
 			return err
 		}
 		found = true
+
+		if !entry.Pinned {
+			entry.ExpiresAt = entryExpiry(tx, itob(id))
+		}
+		entry.OCRText = m.entryOCRText(tx, itob(id))
 		return nil
 	})
 
@@ -867,7 +993,15 @@ func (m *Manager) DeleteEntry(id uint64) error {
 
 	err := m.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("clipboard"))
-		return b.Delete(itob(id))
+		if v := b.Get(itob(id)); v != nil {
+			m.markSyncInTx(tx, itob(id), v, func(mark *syncMark) {
+				mark.DeletedAt = time.Now().Unix()
+			})
+		}
+		if err := b.Delete(itob(id)); err != nil {
+			return err
+		}
+		return deleteExpiryInTx(tx, itob(id))
 	})
 
 	if err == nil {
@@ -931,11 +1065,10 @@ func (m *Manager) storeEntryWithoutDedup(entry Entry) error {
 		return fmt.Errorf("database not available")
 	}
 
-	entry.Hash = computeHash(entry.Data)
-
 	return m.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte("clipboard"))
 
+		entry.Hash = m.entryHash(entry.Data)
 		id, err := b.NextSequence()
 		if err != nil {
 			return err
@@ -943,7 +1076,7 @@ func (m *Manager) storeEntryWithoutDedup(entry Entry) error {
 
 		entry.ID = id
 
-		encoded, err := encodeEntry(entry)
+		encoded, err := m.encodeEntry(entry)
 		if err != nil {
 			return err
 		}
@@ -971,8 +1104,12 @@ func (m *Manager) ClearHistory() {
 		var toDelete [][]byte
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			entry, err := decodeEntryMeta(v)
-			if err != nil || !entry.Pinned {
+			pinned, err := recordPinned(v)
+			if err != nil {
+				log.Warnf("Keeping unreadable clipboard entry %d: %v", binary.BigEndian.Uint64(k), err)
+				continue
+			}
+			if !pinned {
 				toDelete = append(toDelete, k)
 			}
 		}
@@ -981,6 +1118,12 @@ func (m *Manager) ClearHistory() {
 			if err := b.Delete(k); err != nil {
 				return err
 			}
+			if err := deleteExpiryInTx(tx, k); err != nil {
+				return err
+			}
+			if err := deleteOCRInTx(tx, k); err != nil {
+				return err
+			}
 		}
 		return nil
 	}); err != nil {
@@ -994,8 +1137,7 @@ func (m *Manager) ClearHistory() {
 		if b != nil {
 			c := b.Cursor()
 			for k, v := c.First(); k != nil; k, v = c.Next() {
-				entry, _ := decodeEntryMeta(v)
-				if entry.Pinned {
+				if pinned, _ := recordPinned(v); pinned {
 					pinnedCount++
 				}
 			}
@@ -1182,6 +1324,10 @@ func (m *Manager) Close() {
 	m.subscribers = make(map[string]chan State)
 	m.subMutex.Unlock()
 
+	if err := m.restartSync(SyncConfig{}); err != nil {
+		log.Errorf("Failed to stop clipboard sync: %v", err)
+	}
+
 	m.releaseCurrentSource()
 
 	if m.currentOffer != nil {
@@ -1213,7 +1359,16 @@ func (m *Manager) clearHistoryInternal() error {
 		if err := tx.DeleteBucket([]byte("clipboard")); err != nil {
 			return err
 		}
-		_, err := tx.CreateBucket([]byte("clipboard"))
+		if _, err := tx.CreateBucket([]byte("clipboard")); err != nil {
+			return err
+		}
+		if err := tx.DeleteBucket(expiryBucket); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		if err := tx.DeleteBucket(ocrBucket); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err := tx.CreateBucket(expiryBucket)
 		return err
 	})
 }
@@ -1230,7 +1385,7 @@ func (m *Manager) clearOldEntries(days int) error {
 		var toDelete [][]byte
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			entry, err := decodeEntryMeta(v)
+			entry, err := m.decodeEntryMeta(v)
 			if err != nil {
 				continue
 			}
@@ -1264,7 +1419,7 @@ func (m *Manager) migrateHashes() error {
 		}
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			if extractHash(v) == 0 {
+			if !isSealed(v) && extractHash(v) == 0 {
 				needsMigration = true
 				return nil
 			}
@@ -1293,6 +1448,9 @@ func (m *Manager) migrateHashes() error {
 
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if isSealed(v) {
+				continue
+			}
 			entry, err := decodeEntry(v)
 			if err != nil {
 				continue
@@ -1348,7 +1506,7 @@ func (m *Manager) Search(params SearchParams) SearchResult {
 
 		c := b.Cursor()
 		for k, v := c.Last(); k != nil; k, v = c.Prev() {
-			entry, err := decodeEntryMeta(v)
+			entry, err := m.decodeEntryMeta(v)
 			if err != nil {
 				continue
 			}
@@ -1370,7 +1528,9 @@ func (m *Manager) Search(params SearchParams) SearchResult {
 			}
 
 			if query != "" && !strings.Contains(strings.ToLower(entry.Preview), query) {
-				continue
+				if !entry.IsImage || !strings.Contains(strings.ToLower(m.entryOCRText(tx, k)), query) {
+					continue
+				}
 			}
 
 			all = append(all, entry)
@@ -1403,10 +1563,28 @@ func (m *Manager) GetConfig() Config {
 }
 
 func (m *Manager) SetConfig(cfg Config) error {
+	filter, err := compileFilter(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Sync != nil && cfg.Sync.Enabled {
+		if _, err := loadPSK(*cfg.Sync); err != nil {
+			return err
+		}
+	}
+
 	m.configMutex.Lock()
+	oldCfg := m.config
 	m.config = cfg
+	m.filter = filter
 	m.configMutex.Unlock()
 
+	if !reflect.DeepEqual(oldCfg.Sync, cfg.Sync) {
+		if err := m.restartSync(syncConfigOf(cfg)); err != nil {
+			log.Errorf("Failed to restart clipboard sync: %v", err)
+		}
+	}
+
 	m.updateState()
 	m.notifySubscribers()
 
@@ -1463,9 +1641,15 @@ func (m *Manager) watchConfig() {
 }
 
 func (m *Manager) applyConfigChange(newCfg Config) {
+	filter, err := compileFilter(newCfg)
+	if err != nil {
+		log.Warnf("Invalid clipboard filter rules: %v", err)
+	}
+
 	m.configMutex.Lock()
 	oldCfg := m.config
 	m.config = newCfg
+	m.filter = filter
 	m.configMutex.Unlock()
 
 	switch {
@@ -1475,6 +1659,12 @@ func (m *Manager) applyConfigChange(newCfg Config) {
 		log.Info("Clipboard tracking enabled")
 	}
 
+	if !reflect.DeepEqual(oldCfg.Sync, newCfg.Sync) {
+		if err := m.restartSync(syncConfigOf(newCfg)); err != nil {
+			log.Errorf("Failed to restart clipboard sync: %v", err)
+		}
+	}
+
 	m.updateState()
 	m.notifySubscribers()
 }
@@ -1502,27 +1692,26 @@ func (m *Manager) StoreData(data []byte, mimeType string) error {
 		return nil
 	}
 
-	entry := Entry{
-		Data:      data,
-		MimeType:  mimeType,
-		Size:      len(data),
-		Timestamp: time.Now(),
-		IsImage:   m.isImageMimeType(mimeType),
+	decision := m.getFilter().evaluate(data, mimeType, nil)
+	if decision.drop {
+		return fmt.Errorf("rejected by clipboard filter: %s", decision.reason)
 	}
 
-	switch {
-	case entry.IsImage:
-		entry.Preview = m.imagePreview(data, mimeType)
-	case mimeType == "text/uri-list":
-		entry.Preview, entry.IsImage = m.uriListPreview(data)
-	default:
-		entry.Preview = m.textPreview(data)
+	entry := m.newEntry(data, mimeType)
+
+	if decision.expiresIn > 0 {
+		expiresAt := entry.Timestamp.Add(decision.expiresIn)
+		entry.ExpiresAt = &expiresAt
 	}
 
 	if err := m.storeEntry(entry); err != nil {
 		return err
 	}
 
+	if entry.ExpiresAt != nil {
+		m.scheduleExpiry(decision.expiresIn)
+	}
+
 	m.updateState()
 	m.notifySubscribers()
 
@@ -1547,11 +1736,10 @@ func (m *Manager) PinEntry(id uint64) error {
 		}
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			entry, err := decodeEntryMeta(v)
-			if err != nil || !entry.Pinned {
+			if pinned, err := recordPinned(v); err != nil || !pinned {
 				continue
 			}
-			if entry.Hash == entryToPin.Hash {
+			if extractHash(v) == entryToPin.Hash {
 				hashExists = true
 				return nil
 			}
@@ -1574,8 +1762,7 @@ func (m *Manager) PinEntry(id uint64) error {
 		}
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			entry, err := decodeEntryMeta(v)
-			if err == nil && entry.Pinned {
+			if pinned, _ := recordPinned(v); pinned {
 				pinnedCount++
 			}
 		}
@@ -1595,18 +1782,24 @@ func (m *Manager) PinEntry(id uint64) error {
 			return fmt.Errorf("entry not found")
 		}
 
-		entry, err := decodeEntry(v)
+		entry, err := m.decodeEntry(v)
 		if err != nil {
 			return err
 		}
 
 		entry.Pinned = true
-		encoded, err := encodeEntry(entry)
+		encoded, err := m.encodeEntry(entry)
 		if err != nil {
 			return err
 		}
 
-		return b.Put(itob(id), encoded)
+		if err := b.Put(itob(id), encoded); err != nil {
+			return err
+		}
+		m.markSyncInTx(tx, itob(id), encoded, func(mark *syncMark) {
+			mark.Pinned, mark.PinAt = true, time.Now().Unix()
+		})
+		return deleteExpiryInTx(tx, itob(id))
 	})
 
 	if err == nil {
@@ -1629,18 +1822,24 @@ func (m *Manager) UnpinEntry(id uint64) error {
 			return fmt.Errorf("entry not found")
 		}
 
-		entry, err := decodeEntry(v)
+		entry, err := m.decodeEntry(v)
 		if err != nil {
 			return err
 		}
 
 		entry.Pinned = false
-		encoded, err := encodeEntry(entry)
+		encoded, err := m.encodeEntry(entry)
 		if err != nil {
 			return err
 		}
 
-		return b.Put(itob(id), encoded)
+		if err := b.Put(itob(id), encoded); err != nil {
+			return err
+		}
+		m.markSyncInTx(tx, itob(id), encoded, func(mark *syncMark) {
+			mark.Pinned, mark.PinAt = false, time.Now().Unix()
+		})
+		return nil
 	})
 
 	if err == nil {
@@ -1665,7 +1864,7 @@ func (m *Manager) GetPinnedEntries() []Entry {
 
 		c := b.Cursor()
 		for k, v := c.Last(); k != nil; k, v = c.Prev() {
-			entry, err := decodeEntryMeta(v)
+			entry, err := m.decodeEntryMeta(v)
 			if err != nil {
 				continue
 			}
@@ -1695,8 +1894,7 @@ func (m *Manager) GetPinnedCount() int {
 
 		c := b.Cursor()
 		for k, v := c.First(); k != nil; k, v = c.Next() {
-			entry, err := decodeEntryMeta(v)
-			if err == nil && entry.Pinned {
+			if pinned, _ := recordPinned(v); pinned {
 				count++
 			}
 		}
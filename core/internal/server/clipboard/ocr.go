@@ -0,0 +1,295 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+const (
+	ocrTimeout    = 30 * time.Second
+	maxOCRTextLen = 16 * 1024
+)
+
+// ocrBucket maps entry IDs to text extracted from image entries. An empty
+// value means the image was processed and holds no text.
+var ocrBucket = []byte("clipboard_ocr")
+
+type OCRConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Languages string `json:"languages,omitempty"`
+}
+
+// ocrFunc extracts text from an image.
+type ocrFunc func(ctx context.Context, img []byte, languages string) (string, error)
+
+func tesseractAvailable() bool {
+	_, err := exec.LookPath("tesseract")
+	return err == nil
+}
+
+func runTesseract(ctx context.Context, img []byte, languages string) (string, error) {
+	args := []string{"stdin", "stdout"}
+	if languages != "" {
+		args = append(args, "-l", languages)
+	}
+
+	cmd := exec.CommandContext(ctx, "tesseract", args...)
+	cmd.Stdin = bytes.NewReader(img)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract: %s", msg)
+		}
+		return "", fmt.Errorf("tesseract: %w", err)
+	}
+	return string(out), nil
+}
+
+// ocrInput converts images tesseract may not have been built to read into
+// PNG.
+func ocrInput(data []byte, mimeType string) ([]byte, error) {
+	if mimeType == "image/png" {
+		return data, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func normalizeOCRText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxOCRTextLen {
+		text = strings.ToValidUTF8(text[:maxOCRTextLen], "")
+	}
+	return text
+}
+
+// OCR text is as sensitive as the image it came from, so it is sealed
+// whenever history is encrypted.
+func encodeOCRText(c *entryCipher, text string) []byte {
+	if c == nil {
+		return []byte(text)
+	}
+	return append([]byte{sealedMagic}, c.seal([]byte(text))...)
+}
+
+func decodeOCRText(c *entryCipher, v []byte) (string, error) {
+	if !isSealed(v) {
+		return string(v), nil
+	}
+	if c == nil {
+		return "", ErrLocked
+	}
+	plain, err := c.open(v[1:])
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func reencodeOCR(tx *bolt.Tx, from, to *entryCipher) error {
+	b := tx.Bucket(ocrBucket)
+	if b == nil {
+		return nil
+	}
+
+	type update struct {
+		key   []byte
+		value []byte
+	}
+	var updates []update
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		text, err := decodeOCRText(from, v)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, update{append([]byte(nil), k...), encodeOCRText(to, text)})
+	}
+	for _, u := range updates {
+		if err := b.Put(u.key, u.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteOCRInTx(tx *bolt.Tx, key []byte) error {
+	b := tx.Bucket(ocrBucket)
+	if b == nil {
+		return nil
+	}
+	return b.Delete(key)
+}
+
+func (m *Manager) entryOCRText(tx *bolt.Tx, key []byte) string {
+	b := tx.Bucket(ocrBucket)
+	if b == nil {
+		return ""
+	}
+	v := b.Get(key)
+	if v == nil {
+		return ""
+	}
+	c, _ := m.getCipher()
+	text, err := decodeOCRText(c, v)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+func (m *Manager) pokeOCR() {
+	if m.ocrTrigger == nil {
+		return
+	}
+	select {
+	case m.ocrTrigger <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) ocrLoop() {
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.ocrTrigger:
+			m.indexImages()
+		}
+	}
+}
+
+// indexImages runs OCR on image entries that haven't been processed and
+// drops text for entries that are gone.
+func (m *Manager) indexImages() int {
+	cfg := m.getConfig()
+	if cfg.OCR == nil || !cfg.OCR.Enabled || m.db == nil {
+		return 0
+	}
+	if _, locked := m.getCipher(); locked {
+		return 0
+	}
+
+	run := m.ocr
+	if run == nil {
+		if !tesseractAvailable() {
+			m.ocrWarnOnce.Do(func() {
+				log.Warn("Clipboard OCR enabled but tesseract was not found in PATH")
+			})
+			return 0
+		}
+		run = runTesseract
+	}
+
+	var pending []uint64
+	if err := m.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("clipboard"))
+		ob, err := tx.CreateBucketIfNotExists(ocrBucket)
+		if err != nil {
+			return err
+		}
+
+		var orphaned [][]byte
+		oc := ob.Cursor()
+		for k, _ := oc.First(); k != nil; k, _ = oc.Next() {
+			if b.Get(k) == nil {
+				orphaned = append(orphaned, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range orphaned {
+			if err := ob.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if ob.Get(k) != nil {
+				continue
+			}
+			entry, err := m.decodeEntryMeta(v)
+			if err != nil || !m.isImageMimeType(entry.MimeType) {
+				continue
+			}
+			pending = append(pending, entry.ID)
+		}
+		return nil
+	}); err != nil {
+		log.Errorf("Clipboard OCR: failed to scan history: %v", err)
+		return 0
+	}
+
+	var indexed int
+	for _, id := range pending {
+		select {
+		case <-m.stopChan:
+			return indexed
+		default:
+		}
+
+		entry, err := m.GetEntry(id)
+		if err != nil {
+			continue
+		}
+
+		// Failures are stored as empty text too, so a broken image or a
+		// missing language pack doesn't rerun tesseract on every change.
+		text, err := m.extractText(run, entry, cfg.OCR.Languages)
+		if err != nil {
+			log.Debugf("Clipboard OCR: entry %d: %v", id, err)
+		}
+
+		if err := m.db.Update(func(tx *bolt.Tx) error {
+			if tx.Bucket([]byte("clipboard")).Get(itob(id)) == nil {
+				return nil
+			}
+			c, _ := m.getCipher()
+			return tx.Bucket(ocrBucket).Put(itob(id), encodeOCRText(c, text))
+		}); err != nil {
+			log.Errorf("Clipboard OCR: failed to store text for entry %d: %v", id, err)
+			continue
+		}
+		indexed++
+	}
+
+	if indexed > 0 {
+		log.Debugf("Clipboard OCR: indexed %d image entries", indexed)
+	}
+	return indexed
+}
+
+func (m *Manager) extractText(run ocrFunc, entry *Entry, languages string) (string, error) {
+	input, err := ocrInput(entry.Data, entry.MimeType)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ocrTimeout)
+	defer cancel()
+
+	out, err := run(ctx, input, languages)
+	if err != nil {
+		return "", err
+	}
+	return normalizeOCRText(out), nil
+}
@@ -0,0 +1,113 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func fakeOCR(text string) (ocrFunc, *atomic.Int32) {
+	var calls atomic.Int32
+	return func(ctx context.Context, img []byte, languages string) (string, error) {
+		calls.Add(1)
+		return text, nil
+	}, &calls
+}
+
+func newOCRTestManager(t *testing.T, ocr ocrFunc) *Manager {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.OCR = &OCRConfig{Enabled: true}
+	return newTestDBManager(t, cfg, withKeys(newFakeKeys()), withOCR(ocr))
+}
+
+func storeImage(t *testing.T, m *Manager, shade uint8) uint64 {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	img.SetGray(0, 0, color.Gray{Y: shade})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	require.NoError(t, m.storeEntry(Entry{
+		Data:      buf.Bytes(),
+		MimeType:  "image/png",
+		Preview:   "[[ image 4x4 png ]]",
+		Size:      buf.Len(),
+		Timestamp: time.Now(),
+		IsImage:   true,
+	}))
+
+	history := m.GetHistory()
+	require.NotEmpty(t, history)
+	return history[0].ID
+}
+
+func TestOCR_IndexedImagesAreSearchable(t *testing.T) {
+	ocr, calls := fakeOCR("  Invoice\n  #4471\n\n")
+	m := newOCRTestManager(t, ocr)
+	storeText(t, m, "unrelated")
+	id := storeImage(t, m, 1)
+
+	assert.Equal(t, 1, m.indexImages())
+	assert.Zero(t, m.indexImages(), "processed images are not rerun")
+	assert.Equal(t, int32(1), calls.Load())
+
+	res := m.Search(SearchParams{Query: "invoice #4471"})
+	require.Len(t, res.Entries, 1)
+	assert.Equal(t, id, res.Entries[0].ID)
+
+	entry, err := m.GetEntry(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Invoice #4471", entry.OCRText)
+}
+
+func TestOCR_DisabledDoesNothing(t *testing.T) {
+	ocr, calls := fakeOCR("text")
+	m := newOCRTestManager(t, ocr)
+	m.config.OCR.Enabled = false
+	storeImage(t, m, 1)
+
+	assert.Zero(t, m.indexImages())
+	assert.Zero(t, calls.Load())
+}
+
+func TestOCR_TextIsSealedAndPruned(t *testing.T) {
+	ocr, _ := fakeOCR("hunter2")
+	m := newOCRTestManager(t, ocr)
+	id := storeImage(t, m, 1)
+	m.indexImages()
+
+	require.NoError(t, m.Rekey(EncryptionKeyring, ""))
+
+	var raw []byte
+	require.NoError(t, m.db.View(func(tx *bolt.Tx) error {
+		raw = append([]byte(nil), tx.Bucket(ocrBucket).Get(itob(id))...)
+		return nil
+	}))
+	assert.True(t, isSealed(raw))
+	assert.NotContains(t, string(raw), "hunter2")
+	assert.Len(t, m.Search(SearchParams{Query: "hunter2"}).Entries, 1)
+
+	require.NoError(t, m.DeleteEntry(id))
+	m.indexImages()
+	require.NoError(t, m.db.View(func(tx *bolt.Tx) error {
+		assert.Zero(t, tx.Bucket(ocrBucket).Stats().KeyN)
+		return nil
+	}))
+}
+
+func TestNormalizeOCRText(t *testing.T) {
+	assert.Equal(t, "a b c", normalizeOCRText(" a\n\tb  \n c \n"))
+	assert.Len(t, normalizeOCRText(string(bytes.Repeat([]byte("x "), maxOCRTextLen))), maxOCRTextLen)
+}
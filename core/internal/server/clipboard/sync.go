@@ -0,0 +1,887 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+const (
+	defaultSyncInterval = 60
+	defaultSyncRecent   = 20
+	syncSessionTimeout  = 2 * time.Minute
+	syncDebounce        = 2 * time.Second
+	tombstoneTTL        = 30 * 24 * time.Hour
+	minPSKLength        = 16
+)
+
+// syncBucket maps sync hashes to pin and deletion times, so peers can tell
+// which side changed an entry last. syncHashBucket caches the sync hash of
+// each entry by ID, so sealed payloads aren't decrypted on every sync.
+var (
+	syncBucket     = []byte("clipboard_sync")
+	syncHashBucket = []byte("clipboard_sync_hash")
+	syncKeyIDKey   = []byte("syncKeyID")
+)
+
+// SyncConfig configures history sync with other dms instances. Addresses
+// are host:port for TCP, or unix:/path (or just /path) for a Unix socket,
+// e.g. one forwarded over ssh.
+type SyncConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Listen   string   `json:"listen,omitempty"`
+	Peers    []string `json:"peers,omitempty"`
+	PSK      string   `json:"psk,omitempty"`
+	PSKFile  string   `json:"pskFile,omitempty"`
+	Interval int      `json:"interval,omitempty"`
+	Recent   int      `json:"recent,omitempty"`
+}
+
+type PeerStatus struct {
+	Address   string     `json:"address"`
+	LastSync  *time.Time `json:"lastSync,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	Received  int        `json:"received"`
+	Sent      int        `json:"sent"`
+}
+
+type SyncStatus struct {
+	Enabled bool         `json:"enabled"`
+	Listen  string       `json:"listen,omitempty"`
+	Peers   []PeerStatus `json:"peers"`
+}
+
+type syncItem struct {
+	Hash      uint64 `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+	Size      int    `json:"size,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	PinAt     int64  `json:"pinAt,omitempty"`
+	DeletedAt int64  `json:"deletedAt,omitempty"`
+}
+
+type syncEntry struct {
+	Hash      uint64 `json:"hash"`
+	Data      []byte `json:"data"`
+	MimeType  string `json:"mimeType"`
+	Timestamp int64  `json:"timestamp"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	PinAt     int64  `json:"pinAt,omitempty"`
+}
+
+type syncMessage struct {
+	Type   string     `json:"type"`
+	Items  []syncItem `json:"items,omitempty"`
+	Hashes []uint64   `json:"hashes,omitempty"`
+	Entry  *syncEntry `json:"entry,omitempty"`
+}
+
+type syncMark struct {
+	Pinned    bool
+	PinAt     int64
+	DeletedAt int64
+}
+
+func getSyncMark(tx *bolt.Tx, hash uint64) syncMark {
+	b := tx.Bucket(syncBucket)
+	if b == nil {
+		return syncMark{}
+	}
+	v := b.Get(itob(hash))
+	if len(v) != 17 {
+		return syncMark{}
+	}
+	return syncMark{
+		Pinned:    v[0] == 1,
+		PinAt:     int64(binary.BigEndian.Uint64(v[1:9])),
+		DeletedAt: int64(binary.BigEndian.Uint64(v[9:17])),
+	}
+}
+
+func putSyncMark(tx *bolt.Tx, hash uint64, mark syncMark) error {
+	b, err := tx.CreateBucketIfNotExists(syncBucket)
+	if err != nil {
+		return err
+	}
+	v := make([]byte, 17)
+	if mark.Pinned {
+		v[0] = 1
+	}
+	binary.BigEndian.PutUint64(v[1:9], uint64(mark.PinAt))
+	binary.BigEndian.PutUint64(v[9:17], uint64(mark.DeletedAt))
+	return b.Put(itob(hash), v)
+}
+
+// putSyncHash caches the sync hash of the entry stored under key.
+func putSyncHash(tx *bolt.Tx, key []byte, hash uint64) error {
+	b, err := tx.CreateBucketIfNotExists(syncHashBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, itob(hash))
+}
+
+// syncHashInTx returns the sync hash of the entry stored under key as v.
+// Only entries stored before sync was enabled have to be decoded, once.
+func (m *Manager) syncHashInTx(tx *bolt.Tx, s *syncService, key, v []byte) (uint64, error) {
+	if b := tx.Bucket(syncHashBucket); b != nil {
+		if h := b.Get(key); len(h) == 8 {
+			return binary.BigEndian.Uint64(h), nil
+		}
+	}
+	entry, err := m.decodeEntry(v)
+	if err != nil {
+		return 0, err
+	}
+	hash := s.hash(entry.Data)
+	return hash, putSyncHash(tx, key, hash)
+}
+
+func (m *Manager) getSync() *syncService {
+	m.syncMutex.Lock()
+	defer m.syncMutex.Unlock()
+	return m.sync
+}
+
+// markSyncInTx records a local pin change or deletion of the entry stored
+// under key as v, for peers to pick up.
+func (m *Manager) markSyncInTx(tx *bolt.Tx, key, v []byte, update func(*syncMark)) {
+	s := m.getSync()
+	if s == nil {
+		return
+	}
+	hash, err := m.syncHashInTx(tx, s, key, v)
+	if err != nil {
+		return
+	}
+	mark := getSyncMark(tx, hash)
+	update(&mark)
+	if err := putSyncMark(tx, hash, mark); err != nil {
+		log.Warnf("Failed to record sync state: %v", err)
+	}
+}
+
+type localItem struct {
+	syncItem
+	id       uint64
+	expiring bool
+}
+
+// syncSnapshot lists local entries by sync hash, plus tombstones for
+// deleted ones.
+func (m *Manager) syncSnapshot(s *syncService) (map[uint64]*localItem, error) {
+	items := make(map[uint64]*localItem)
+	cutoff := time.Now().Add(-tombstoneTTL).Unix()
+
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("clipboard"))
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			entry, err := m.decodeEntryMeta(v)
+			if err != nil {
+				continue
+			}
+			hash, err := m.syncHashInTx(tx, s, k, v)
+			if err != nil {
+				continue
+			}
+			mark := getSyncMark(tx, hash)
+			items[hash] = &localItem{
+				syncItem: syncItem{
+					Hash:      hash,
+					Timestamp: entry.Timestamp.Unix(),
+					Size:      entry.Size,
+					Pinned:    entry.Pinned,
+					PinAt:     mark.PinAt,
+				},
+				id:       entry.ID,
+				expiring: entryExpiry(tx, k) != nil,
+			}
+		}
+
+		if err := pruneSyncHashes(tx, b); err != nil {
+			return err
+		}
+
+		sb := tx.Bucket(syncBucket)
+		if sb == nil {
+			return nil
+		}
+		var expired [][]byte
+		sc := sb.Cursor()
+		for k, _ := sc.First(); k != nil; k, _ = sc.Next() {
+			hash := binary.BigEndian.Uint64(k)
+			mark := getSyncMark(tx, hash)
+			if mark.DeletedAt == 0 {
+				continue
+			}
+			if mark.DeletedAt < cutoff {
+				expired = append(expired, append([]byte(nil), k...))
+				continue
+			}
+			if item, ok := items[hash]; ok {
+				item.DeletedAt = mark.DeletedAt
+				continue
+			}
+			items[hash] = &localItem{syncItem: syncItem{Hash: hash, DeletedAt: mark.DeletedAt}}
+		}
+		for _, k := range expired {
+			if err := sb.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// pruneSyncHashes drops cached hashes of entries that no longer exist.
+func pruneSyncHashes(tx *bolt.Tx, entries *bolt.Bucket) error {
+	hb := tx.Bucket(syncHashBucket)
+	if hb == nil {
+		return nil
+	}
+	var stale [][]byte
+	c := hb.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if entries.Get(k) == nil {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+	}
+	for _, k := range stale {
+		if err := hb.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifest picks what this side offers: every pinned entry, the most
+// recent unpinned ones, and tombstones. Entries from expire rules stay
+// local.
+func manifest(local map[uint64]*localItem, recent int, maxSize int64) []syncItem {
+	var pinned, unpinned, tombstones []syncItem
+	for _, item := range local {
+		switch {
+		case item.id == 0:
+			tombstones = append(tombstones, item.syncItem)
+		case item.expiring || int64(item.Size) > maxSize:
+		case item.Pinned:
+			pinned = append(pinned, item.syncItem)
+		default:
+			unpinned = append(unpinned, item.syncItem)
+		}
+	}
+
+	slices.SortFunc(unpinned, func(a, b syncItem) int { return int(b.Timestamp - a.Timestamp) })
+	if len(unpinned) > recent {
+		unpinned = unpinned[:recent]
+	}
+
+	// A live entry newer than a tombstone for the same content wins.
+	out := append(pinned, unpinned...)
+	for i := range out {
+		out[i].DeletedAt = 0
+	}
+	return append(out, tombstones...)
+}
+
+type syncPlan struct {
+	want    []uint64
+	deletes []syncItem
+	pins    []syncItem
+}
+
+func planSync(local map[uint64]*localItem, remote []syncItem, maxSize int64) syncPlan {
+	var plan syncPlan
+	for _, r := range remote {
+		l, have := local[r.Hash]
+
+		if r.DeletedAt > 0 {
+			switch {
+			case have && l.id != 0 && l.Timestamp <= r.DeletedAt:
+				plan.deletes = append(plan.deletes, r)
+			case !have || l.DeletedAt < r.DeletedAt:
+				plan.deletes = append(plan.deletes, r)
+			}
+			continue
+		}
+
+		if have && l.id != 0 {
+			if r.PinAt > l.PinAt && r.Pinned != l.Pinned {
+				plan.pins = append(plan.pins, r)
+			}
+			continue
+		}
+		if have && l.DeletedAt >= r.Timestamp {
+			continue
+		}
+		if int64(r.Size) > maxSize {
+			continue
+		}
+		plan.want = append(plan.want, r.Hash)
+	}
+	return plan
+}
+
+func (m *Manager) applyRemoteDeletes(local map[uint64]*localItem, deletes []syncItem) (int, error) {
+	var removed int
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("clipboard"))
+		for _, r := range deletes {
+			if l, ok := local[r.Hash]; ok && l.id != 0 && l.Timestamp <= r.DeletedAt {
+				if err := b.Delete(itob(l.id)); err != nil {
+					return err
+				}
+				if err := deleteExpiryInTx(tx, itob(l.id)); err != nil {
+					return err
+				}
+				removed++
+			}
+			mark := getSyncMark(tx, r.Hash)
+			mark.DeletedAt = max(mark.DeletedAt, r.DeletedAt)
+			if err := putSyncMark(tx, r.Hash, mark); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (m *Manager) applyRemotePins(local map[uint64]*localItem, pins []syncItem) (int, error) {
+	maxPinned := m.getConfig().MaxPinned
+	var changed int
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("clipboard"))
+
+		pinnedCount := 0
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if pinned, _ := recordPinned(v); pinned {
+				pinnedCount++
+			}
+		}
+
+		for _, r := range pins {
+			l := local[r.Hash]
+			if r.Pinned && pinnedCount >= maxPinned {
+				log.Debugf("Clipboard sync: not pinning entry %d, maximum pinned entries reached", l.id)
+				continue
+			}
+
+			v := b.Get(itob(l.id))
+			if v == nil {
+				continue
+			}
+			entry, err := m.decodeEntry(v)
+			if err != nil {
+				continue
+			}
+			entry.Pinned = r.Pinned
+			encoded, err := m.encodeEntry(entry)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(itob(l.id), encoded); err != nil {
+				return err
+			}
+			if r.Pinned {
+				pinnedCount++
+				if err := deleteExpiryInTx(tx, itob(l.id)); err != nil {
+					return err
+				}
+			} else {
+				pinnedCount--
+			}
+
+			mark := getSyncMark(tx, r.Hash)
+			mark.Pinned, mark.PinAt = r.Pinned, r.PinAt
+			if err := putSyncMark(tx, r.Hash, mark); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// storeSyncedEntry stores an entry received from a peer with its original
+// timestamp. Local filter rules still apply.
+func (m *Manager) storeSyncedEntry(s *syncService, se *syncEntry) error {
+	cfg := m.getConfig()
+	if int64(len(se.Data)) > cfg.MaxEntrySize {
+		return fmt.Errorf("entry too large")
+	}
+	if s.hash(se.Data) != se.Hash {
+		return fmt.Errorf("entry hash mismatch")
+	}
+
+	decision := m.getFilter().evaluate(se.Data, se.MimeType, nil)
+	if decision.drop {
+		log.Debugf("Clipboard sync: entry not stored: %s", decision.reason)
+		return nil
+	}
+
+	entry := m.newEntry(se.Data, se.MimeType)
+	entry.Timestamp = time.Unix(se.Timestamp, 0)
+	entry.Pinned = se.Pinned
+
+	if decision.expiresIn > 0 && !entry.Pinned {
+		expiresAt := time.Now().Add(decision.expiresIn)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	if err := m.storeEntry(entry); err != nil {
+		return err
+	}
+	if entry.ExpiresAt != nil {
+		m.scheduleExpiry(decision.expiresIn)
+	}
+
+	if se.PinAt == 0 {
+		return nil
+	}
+	return m.db.Update(func(tx *bolt.Tx) error {
+		mark := getSyncMark(tx, se.Hash)
+		mark.Pinned, mark.PinAt = se.Pinned, se.PinAt
+		return putSyncMark(tx, se.Hash, mark)
+	})
+}
+
+type sessionResult struct {
+	sent     int
+	received int
+}
+
+// runSyncSession exchanges manifests with the peer on c, then the entries
+// each side is missing. Both sides run the same steps.
+func (m *Manager) runSyncSession(s *syncService, c *syncConn) (sessionResult, error) {
+	var res sessionResult
+
+	local, err := m.syncSnapshot(s)
+	if err != nil {
+		return res, err
+	}
+	maxSize := m.getConfig().MaxEntrySize
+
+	in, err := c.exchange(syncMessage{Type: "manifest", Items: manifest(local, s.cfg.Recent, maxSize)})
+	if err != nil {
+		return res, err
+	}
+	if in.Type == "busy" {
+		return res, errSyncBusy
+	}
+	if in.Type != "manifest" {
+		return res, fmt.Errorf("unexpected %q message", in.Type)
+	}
+
+	plan := planSync(local, in.Items, maxSize)
+
+	in, err = c.exchange(syncMessage{Type: "want", Hashes: plan.want})
+	if err != nil {
+		return res, err
+	}
+	if in.Type != "want" {
+		return res, fmt.Errorf("unexpected %q message", in.Type)
+	}
+	requested := in.Hashes
+
+	errc := make(chan error, 1)
+	go func() {
+		for _, hash := range requested {
+			item, ok := local[hash]
+			if !ok || item.id == 0 {
+				continue
+			}
+			entry, err := m.GetEntry(item.id)
+			if err != nil {
+				continue
+			}
+			msg := syncMessage{Type: "entry", Entry: &syncEntry{
+				Hash:      hash,
+				Data:      entry.Data,
+				MimeType:  entry.MimeType,
+				Timestamp: entry.Timestamp.Unix(),
+				Pinned:    entry.Pinned,
+				PinAt:     item.PinAt,
+			}}
+			if err := c.writeMsg(msg); err != nil {
+				errc <- err
+				return
+			}
+			res.sent++
+		}
+		errc <- c.writeMsg(syncMessage{Type: "end"})
+	}()
+
+	var readErr error
+	for {
+		msg, err := c.readMsg()
+		if err != nil {
+			readErr = err
+			c.conn.Close()
+			break
+		}
+		if msg.Type == "end" {
+			break
+		}
+		if msg.Type != "entry" || msg.Entry == nil || !slices.Contains(plan.want, msg.Entry.Hash) {
+			readErr = fmt.Errorf("unexpected %q message", msg.Type)
+			c.conn.Close()
+			break
+		}
+		if err := m.storeSyncedEntry(s, msg.Entry); err != nil {
+			log.Debugf("Clipboard sync: skipped entry: %v", err)
+			continue
+		}
+		res.received++
+	}
+	if err := <-errc; err != nil {
+		return res, err
+	}
+	if readErr != nil {
+		return res, readErr
+	}
+
+	removed, err := m.applyRemoteDeletes(local, plan.deletes)
+	if err != nil {
+		return res, err
+	}
+	pinned, err := m.applyRemotePins(local, plan.pins)
+	if err != nil {
+		return res, err
+	}
+
+	if res.received > 0 || removed > 0 || pinned > 0 {
+		m.updateState()
+		m.notifySubscribers()
+	}
+	return res, nil
+}
+
+type syncService struct {
+	m   *Manager
+	cfg SyncConfig
+	psk []byte
+
+	listener net.Listener
+	sessions sync.Mutex
+	trigger  chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+
+	statusMu sync.Mutex
+	peers    map[string]*PeerStatus
+}
+
+func loadPSK(cfg SyncConfig) ([]byte, error) {
+	psk := cfg.PSK
+	if cfg.PSKFile != "" {
+		data, err := os.ReadFile(cfg.PSKFile)
+		if err != nil {
+			return nil, fmt.Errorf("read psk file: %w", err)
+		}
+		psk = strings.TrimSpace(string(data))
+	}
+	if len(psk) < minPSKLength {
+		return nil, fmt.Errorf("sync psk must be at least %d characters", minPSKLength)
+	}
+	return []byte(psk), nil
+}
+
+func newSyncService(m *Manager, cfg SyncConfig) (*syncService, error) {
+	psk, err := loadPSK(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSyncInterval
+	}
+	if cfg.Recent <= 0 {
+		cfg.Recent = defaultSyncRecent
+	}
+
+	s := &syncService{
+		m:       m,
+		cfg:     cfg,
+		psk:     psk,
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		peers:   make(map[string]*PeerStatus),
+	}
+	for _, addr := range cfg.Peers {
+		s.peers[addr] = &PeerStatus{Address: addr}
+	}
+	if err := s.adoptKey(); err != nil {
+		return nil, fmt.Errorf("reset sync state: %w", err)
+	}
+
+	if cfg.Listen != "" {
+		network, address := parseSyncAddr(cfg.Listen)
+		if network == "unix" {
+			os.Remove(address)
+		}
+		l, err := net.Listen(network, address)
+		if err != nil {
+			return nil, fmt.Errorf("listen on %s: %w", cfg.Listen, err)
+		}
+		if network == "unix" {
+			os.Chmod(address, 0o600)
+		}
+		s.listener = l
+
+		s.wg.Add(1)
+		go s.acceptLoop()
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+	return s, nil
+}
+
+// hash is the content hash peers agree on. It is keyed with the PSK so
+// the marks and tombstones kept in the database can't be used to test
+// whether some text was ever copied.
+func (s *syncService) hash(data []byte) uint64 {
+	return binary.BigEndian.Uint64(syncMAC(s.psk, "entry-hash", data))
+}
+
+// adoptKey drops sync marks and cached hashes derived from another PSK;
+// peers using this one can't match them.
+func (s *syncService) adoptKey() error {
+	id := syncMAC(s.psk, "key-id")[:8]
+	return s.m.db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(meta.Get(syncKeyIDKey), id) {
+			return nil
+		}
+		for _, name := range [][]byte{syncBucket, syncHashBucket} {
+			if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+		}
+		return meta.Put(syncKeyIDKey, id)
+	})
+}
+
+func (s *syncService) maxFrame() int {
+	return int(s.m.getConfig().MaxEntrySize)*2 + 1<<20
+}
+
+func (s *syncService) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warnf("Clipboard sync: accept failed: %v", err)
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if _, err := s.serve(conn, false); err != nil {
+				log.Debugf("Clipboard sync: session from %s failed: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (s *syncService) serve(conn net.Conn, dialer bool) (sessionResult, error) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(syncSessionTimeout))
+
+	c, err := syncHandshake(conn, s.psk, dialer, s.maxFrame())
+	if err != nil {
+		return sessionResult{}, err
+	}
+
+	if dialer {
+		s.sessions.Lock()
+	} else if !s.sessions.TryLock() {
+		// Never make a peer wait behind our own outbound session: when two
+		// instances dial each other at once, each would hold its lock while
+		// waiting for the other's inbound side. The peer retries instead.
+		if _, err := c.exchange(syncMessage{Type: "busy"}); err != nil {
+			return sessionResult{}, err
+		}
+		return sessionResult{}, errSyncBusy
+	}
+	defer s.sessions.Unlock()
+	return s.m.runSyncSession(s, c)
+}
+
+func (s *syncService) syncPeer(addr string) (sessionResult, error) {
+	network, address := parseSyncAddr(addr)
+	conn, err := net.DialTimeout(network, address, 10*time.Second)
+	if err != nil {
+		return sessionResult{}, err
+	}
+	return s.serve(conn, true)
+}
+
+func (s *syncService) syncAll() {
+	var busy bool
+	for _, addr := range s.cfg.Peers {
+		res, err := s.syncPeer(addr)
+		if errors.Is(err, errSyncBusy) {
+			log.Debugf("Clipboard sync with %s deferred: %v", addr, err)
+			busy = true
+			continue
+		}
+
+		s.statusMu.Lock()
+		status := s.peers[addr]
+		switch {
+		case err != nil:
+			status.LastError = err.Error()
+			log.Debugf("Clipboard sync with %s failed: %v", addr, err)
+		default:
+			now := time.Now()
+			status.LastSync = &now
+			status.LastError = ""
+			status.Sent += res.sent
+			status.Received += res.received
+		}
+		s.statusMu.Unlock()
+	}
+
+	// Jitter the retry so two peers that collided don't collide again.
+	if busy {
+		time.AfterFunc(rand.N(syncDebounce), s.poke)
+	}
+}
+
+func (s *syncService) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	var debounce <-chan time.Time
+	s.syncAll()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.syncAll()
+		case <-s.trigger:
+			debounce = time.After(syncDebounce)
+		case <-debounce:
+			debounce = nil
+			s.syncAll()
+		}
+	}
+}
+
+func (s *syncService) poke() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *syncService) status() SyncStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	st := SyncStatus{Enabled: true, Listen: s.cfg.Listen, Peers: []PeerStatus{}}
+	for _, addr := range s.cfg.Peers {
+		st.Peers = append(st.Peers, *s.peers[addr])
+	}
+	return st
+}
+
+func (s *syncService) close() {
+	close(s.stop)
+	if s.listener != nil {
+		s.listener.Close()
+		if network, address := parseSyncAddr(s.cfg.Listen); network == "unix" {
+			os.Remove(address)
+		}
+	}
+	s.wg.Wait()
+}
+
+func syncConfigOf(cfg Config) SyncConfig {
+	if cfg.Sync == nil {
+		return SyncConfig{}
+	}
+	return *cfg.Sync
+}
+
+// restartSync starts, stops or reconfigures sync to match cfg.
+func (m *Manager) restartSync(cfg SyncConfig) error {
+	m.syncMutex.Lock()
+	old := m.sync
+	m.sync = nil
+	m.syncMutex.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+	if !cfg.Enabled || m.db == nil {
+		return nil
+	}
+
+	s, err := newSyncService(m, cfg)
+	if err != nil {
+		return err
+	}
+
+	m.syncMutex.Lock()
+	m.sync = s
+	m.syncMutex.Unlock()
+	return nil
+}
+
+func (m *Manager) pokeSync() {
+	s := m.getSync()
+	if s != nil {
+		s.poke()
+	}
+}
+
+func (m *Manager) SyncStatus() SyncStatus {
+	s := m.getSync()
+	if s == nil {
+		return SyncStatus{Peers: []PeerStatus{}}
+	}
+	return s.status()
+}
+
+// SyncNow syncs with every configured peer right away.
+func (m *Manager) SyncNow() (SyncStatus, error) {
+	s := m.getSync()
+	if s == nil {
+		return SyncStatus{}, fmt.Errorf("clipboard sync is not enabled")
+	}
+	s.syncAll()
+	return s.status(), nil
+}
@@ -0,0 +1,191 @@
+package clipboard
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+const (
+	syncMagic     = "DMSCS1"
+	syncNonceSize = 32
+)
+
+var (
+	errSyncAuth = errors.New("peer failed authentication (pre-shared key mismatch?)")
+	errSyncBusy = errors.New("peer is busy syncing")
+)
+
+// syncConn is an encrypted, authenticated stream between two peers that
+// share a key. Both sides contribute a random nonce to the handshake, prove
+// knowledge of the key over both nonces, and derive one AES-GCM key per
+// direction. Frame nonces are sequence numbers, so replayed or reordered
+// frames fail to open.
+type syncConn struct {
+	conn     net.Conn
+	send     cipher.AEAD
+	recv     cipher.AEAD
+	sendSeq  uint64
+	recvSeq  uint64
+	maxFrame int
+}
+
+func parseSyncAddr(addr string) (network, address string) {
+	switch {
+	case strings.HasPrefix(addr, "unix:"):
+		return "unix", strings.TrimPrefix(addr, "unix:")
+	case strings.HasPrefix(addr, "/"):
+		return "unix", addr
+	case strings.HasPrefix(addr, "tcp:"):
+		return "tcp", strings.TrimPrefix(addr, "tcp:")
+	}
+	return "tcp", addr
+}
+
+func syncMAC(psk []byte, label string, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, psk)
+	mac.Write([]byte(label))
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func syncHandshake(conn net.Conn, psk []byte, dialer bool, maxFrame int) (*syncConn, error) {
+	own := make([]byte, syncNonceSize)
+	if _, err := rand.Read(own); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write(append([]byte(syncMagic), own...)); err != nil {
+		return nil, fmt.Errorf("send hello: %w", err)
+	}
+
+	hello := make([]byte, len(syncMagic)+syncNonceSize)
+	if _, err := io.ReadFull(conn, hello); err != nil {
+		return nil, fmt.Errorf("read hello: %w", err)
+	}
+	if string(hello[:len(syncMagic)]) != syncMagic {
+		return nil, fmt.Errorf("peer is not a dms clipboard sync endpoint")
+	}
+	peer := hello[len(syncMagic):]
+
+	dialerNonce, listenerNonce := own, peer
+	ownRole, peerRole := "proof-dialer", "proof-listener"
+	if !dialer {
+		dialerNonce, listenerNonce = peer, own
+		ownRole, peerRole = peerRole, ownRole
+	}
+
+	if _, err := conn.Write(syncMAC(psk, ownRole, dialerNonce, listenerNonce)); err != nil {
+		return nil, fmt.Errorf("send proof: %w", err)
+	}
+	proof := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, proof); err != nil {
+		return nil, fmt.Errorf("read proof: %w", err)
+	}
+	if !hmac.Equal(proof, syncMAC(psk, peerRole, dialerNonce, listenerNonce)) {
+		return nil, errSyncAuth
+	}
+
+	d2l, err := newGCM(syncMAC(psk, "key-d2l", dialerNonce, listenerNonce))
+	if err != nil {
+		return nil, err
+	}
+	l2d, err := newGCM(syncMAC(psk, "key-l2d", dialerNonce, listenerNonce))
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &syncConn{conn: conn, send: d2l, recv: l2d, maxFrame: maxFrame}
+	if !dialer {
+		sc.send, sc.recv = l2d, d2l
+	}
+	return sc, nil
+}
+
+func seqNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+func (c *syncConn) writeMsg(msg syncMessage) error {
+	plain, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	sealed := c.send.Seal(nil, seqNonce(c.send, c.sendSeq), plain, nil)
+	c.sendSeq++
+
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(sealed)))
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err = c.conn.Write(sealed)
+	return err
+}
+
+func (c *syncConn) readMsg() (syncMessage, error) {
+	var msg syncMessage
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return msg, err
+	}
+	n := binary.BigEndian.Uint32(header)
+	if int64(n) > int64(c.maxFrame) {
+		return msg, fmt.Errorf("frame too large (%d bytes)", n)
+	}
+
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, sealed); err != nil {
+		return msg, err
+	}
+
+	plain, err := c.recv.Open(nil, seqNonce(c.recv, c.recvSeq), sealed, nil)
+	if err != nil {
+		return msg, fmt.Errorf("corrupt frame: %w", err)
+	}
+	c.recvSeq++
+
+	if err := json.Unmarshal(plain, &msg); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// exchange sends out while reading the peer's message, so two peers sending
+// large frames at the same time can't block each other.
+func (c *syncConn) exchange(out syncMessage) (syncMessage, error) {
+	errc := make(chan error, 1)
+	go func() { errc <- c.writeMsg(out) }()
+
+	in, readErr := c.readMsg()
+	if readErr != nil {
+		c.conn.Close()
+	}
+	if err := <-errc; err != nil {
+		return in, err
+	}
+	return in, readErr
+}
@@ -0,0 +1,283 @@
+package clipboard
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+const testPSK = "correct-horse-battery-staple"
+
+type syncPair struct {
+	a, b *Manager
+	addr string
+}
+
+// newSyncPair starts a listening manager a and a dialing manager b, whose
+// history is encrypted so sync hashes can't come from the stored hash.
+func newSyncPair(t *testing.T) *syncPair {
+	t.Helper()
+	dir := t.TempDir()
+
+	a := newTestDBManager(t, DefaultConfig(), withDBPath(filepath.Join(dir, "a.db")), withKeys(newFakeKeys()))
+	b := newTestDBManager(t, DefaultConfig(), withDBPath(filepath.Join(dir, "b.db")), withKeys(newFakeKeys()))
+	require.NoError(t, b.Rekey(EncryptionKeyring, ""))
+
+	addr := "unix:" + filepath.Join(dir, "a.sock")
+	require.NoError(t, a.restartSync(SyncConfig{Enabled: true, Listen: addr, PSK: testPSK}))
+	require.NoError(t, b.restartSync(SyncConfig{Enabled: true, PSK: testPSK}))
+	t.Cleanup(func() {
+		a.restartSync(SyncConfig{})
+		b.restartSync(SyncConfig{})
+	})
+
+	return &syncPair{a: a, b: b, addr: addr}
+}
+
+func (p *syncPair) sync(t *testing.T) sessionResult {
+	t.Helper()
+	res, err := p.b.sync.syncPeer(p.addr)
+	require.NoError(t, err)
+	return res
+}
+
+func previews(m *Manager) []string {
+	var out []string
+	for _, e := range m.GetHistory() {
+		label := e.Preview
+		if e.Pinned {
+			label += "*"
+		}
+		out = append(out, label)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func findEntry(t *testing.T, m *Manager, preview string) Entry {
+	t.Helper()
+	for _, e := range m.GetHistory() {
+		if e.Preview == preview {
+			return e
+		}
+	}
+	t.Fatalf("entry %q not found", preview)
+	return Entry{}
+}
+
+func TestSync_ExchangesEntriesBothWays(t *testing.T) {
+	p := newSyncPair(t)
+
+	storeText(t, p.a, "alpha")
+	storeText(t, p.a, "pinned")
+	require.NoError(t, p.a.PinEntry(findEntry(t, p.a, "pinned").ID))
+	storeText(t, p.b, "beta")
+
+	res := p.sync(t)
+	assert.Equal(t, 1, res.sent)
+	assert.Equal(t, 2, res.received)
+
+	want := []string{"alpha", "beta", "pinned*"}
+	assert.Equal(t, want, previews(p.a))
+	assert.Equal(t, want, previews(p.b))
+
+	entry, err := p.b.GetEntry(findEntry(t, p.b, "alpha").ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", string(entry.Data))
+
+	res = p.sync(t)
+	assert.Zero(t, res.sent)
+	assert.Zero(t, res.received)
+}
+
+func TestSync_PropagatesDeletes(t *testing.T) {
+	p := newSyncPair(t)
+
+	storeText(t, p.a, "keep")
+	storeText(t, p.a, "drop me")
+	p.sync(t)
+	require.Len(t, p.b.GetHistory(), 2)
+
+	require.NoError(t, p.b.DeleteEntry(findEntry(t, p.b, "drop me").ID))
+	p.sync(t)
+	assert.Equal(t, []string{"keep"}, previews(p.a))
+
+	// The tombstone keeps the entry from coming back.
+	p.sync(t)
+	assert.Equal(t, []string{"keep"}, previews(p.b))
+}
+
+func TestSync_LatestPinChangeWins(t *testing.T) {
+	p := newSyncPair(t)
+
+	storeText(t, p.a, "note")
+	require.NoError(t, p.a.PinEntry(findEntry(t, p.a, "note").ID))
+	p.sync(t)
+	require.Equal(t, []string{"note*"}, previews(p.b))
+
+	// Backdate a's pin so b's unpin is clearly newer.
+	require.NoError(t, p.a.db.Update(func(tx *bolt.Tx) error {
+		return putSyncMark(tx, p.a.sync.hash([]byte("note")), syncMark{Pinned: true, PinAt: time.Now().Add(-time.Minute).Unix()})
+	}))
+
+	require.NoError(t, p.b.UnpinEntry(findEntry(t, p.b, "note").ID))
+	p.sync(t)
+	assert.Equal(t, []string{"note"}, previews(p.a))
+}
+
+func TestSync_HonorsMaxEntrySize(t *testing.T) {
+	p := newSyncPair(t)
+	p.b.config.MaxEntrySize = 16
+
+	storeText(t, p.a, strings.Repeat("x", 64))
+	storeText(t, p.a, "small")
+
+	res := p.sync(t)
+	assert.Equal(t, 1, res.received)
+	assert.Equal(t, []string{"small"}, previews(p.b))
+}
+
+func TestSync_RejectsWrongKey(t *testing.T) {
+	p := newSyncPair(t)
+	storeText(t, p.a, "secret")
+
+	intruder := newTestDBManager(t, DefaultConfig(), withKeys(newFakeKeys()))
+	require.NoError(t, intruder.restartSync(SyncConfig{Enabled: true, PSK: "not-the-right-key-at-all"}))
+	t.Cleanup(func() { intruder.restartSync(SyncConfig{}) })
+
+	_, err := intruder.sync.syncPeer(p.addr)
+	assert.ErrorIs(t, err, errSyncAuth)
+	assert.Empty(t, intruder.GetHistory())
+
+	assert.ErrorContains(t, intruder.restartSync(SyncConfig{Enabled: true, PSK: "short"}), "at least")
+}
+
+func TestSync_MarksAreKeyed(t *testing.T) {
+	p := newSyncPair(t)
+
+	storeText(t, p.a, "hunter2")
+	p.sync(t)
+	require.NoError(t, p.b.DeleteEntry(findEntry(t, p.b, "hunter2").ID))
+	p.sync(t)
+	require.Empty(t, p.a.GetHistory())
+
+	for _, m := range []*Manager{p.a, p.b} {
+		require.NoError(t, m.db.View(func(tx *bolt.Tx) error {
+			sb := tx.Bucket(syncBucket)
+			require.NotNil(t, sb)
+			assert.Nil(t, sb.Get(itob(computeHash([]byte("hunter2")))), "unkeyed hash must not be stored")
+			assert.NotZero(t, getSyncMark(tx, m.sync.hash([]byte("hunter2"))).DeletedAt)
+			return nil
+		}))
+	}
+
+	// Another PSK can't match the old tombstones, so they are dropped.
+	require.NoError(t, p.a.restartSync(SyncConfig{Enabled: true, PSK: "a-completely-different-key"}))
+	require.NoError(t, p.a.db.View(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(syncBucket))
+		return nil
+	}))
+}
+
+func TestSync_CachesHashes(t *testing.T) {
+	p := newSyncPair(t)
+
+	storeText(t, p.b, "cached")
+	id := findEntry(t, p.b, "cached").ID
+
+	var cached []byte
+	require.NoError(t, p.b.db.View(func(tx *bolt.Tx) error {
+		cached = append(cached, tx.Bucket(syncHashBucket).Get(itob(id))...)
+		return nil
+	}))
+	assert.Equal(t, itob(p.b.sync.hash([]byte("cached"))), cached)
+
+	require.NoError(t, p.b.DeleteEntry(id))
+	_, err := p.b.syncSnapshot(p.b.sync)
+	require.NoError(t, err)
+	require.NoError(t, p.b.db.View(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(syncHashBucket).Get(itob(id)))
+		return nil
+	}))
+}
+
+// TestSync_MutualPeersDontDeadlock has both sides dial each other at once.
+// Inbound sessions must not wait behind the local outbound one, or each
+// side holds its lock while waiting for the other until the timeout.
+func TestSync_MutualPeersDontDeadlock(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestDBManager(t, DefaultConfig(), withDBPath(filepath.Join(dir, "a.db")), withKeys(newFakeKeys()))
+	b := newTestDBManager(t, DefaultConfig(), withDBPath(filepath.Join(dir, "b.db")), withKeys(newFakeKeys()))
+
+	aAddr := "unix:" + filepath.Join(dir, "a.sock")
+	bAddr := "unix:" + filepath.Join(dir, "b.sock")
+	require.NoError(t, a.restartSync(SyncConfig{Enabled: true, Listen: aAddr, PSK: testPSK}))
+	require.NoError(t, b.restartSync(SyncConfig{Enabled: true, Listen: bAddr, PSK: testPSK}))
+	t.Cleanup(func() {
+		a.restartSync(SyncConfig{})
+		b.restartSync(SyncConfig{})
+	})
+
+	storeText(t, a, "from a")
+	storeText(t, b, "from b")
+
+	errs := make(chan error, 2)
+	wait := func() error {
+		t.Helper()
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("sync session blocked")
+			return nil
+		}
+	}
+
+	// b is mid-session with someone else: a is turned away, not queued.
+	b.sync.sessions.Lock()
+	unlock := sync.OnceFunc(b.sync.sessions.Unlock)
+	t.Cleanup(unlock)
+	go func() { _, err := a.sync.syncPeer(bAddr); errs <- err }()
+	err := wait()
+	unlock()
+	require.ErrorIs(t, err, errSyncBusy)
+
+	go func() { _, err := a.sync.syncPeer(bAddr); errs <- err }()
+	go func() { _, err := b.sync.syncPeer(aAddr); errs <- err }()
+	for range 2 {
+		if err := wait(); err != nil {
+			require.ErrorIs(t, err, errSyncBusy)
+		}
+	}
+
+	_, err = a.sync.syncPeer(bAddr)
+	require.NoError(t, err)
+	want := []string{"from a", "from b"}
+	assert.Equal(t, want, previews(a))
+	assert.Equal(t, want, previews(b))
+}
+
+func TestParseSyncAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		network string
+		address string
+	}{
+		{"192.168.1.5:47310", "tcp", "192.168.1.5:47310"},
+		{"tcp:[::1]:47310", "tcp", "[::1]:47310"},
+		{"unix:/run/user/1000/dms-clip.sock", "unix", "/run/user/1000/dms-clip.sock"},
+		{"/tmp/dms.sock", "unix", "/tmp/dms.sock"},
+	}
+	for _, tt := range tests {
+		network, address := parseSyncAddr(tt.addr)
+		assert.Equal(t, tt.network, network, tt.addr)
+		assert.Equal(t, tt.address, address, tt.addr)
+	}
+}
@@ -15,12 +15,17 @@ import (
 )
 
 type Config struct {
-	MaxHistory     int   `json:"maxHistory"`
-	MaxEntrySize   int64 `json:"maxEntrySize"`
-	AutoClearDays  int   `json:"autoClearDays"`
-	ClearAtStartup bool  `json:"clearAtStartup"`
-	Disabled       bool  `json:"disabled"`
-	MaxPinned      int   `json:"maxPinned"`
+	MaxHistory     int          `json:"maxHistory"`
+	MaxEntrySize   int64        `json:"maxEntrySize"`
+	AutoClearDays  int          `json:"autoClearDays"`
+	ClearAtStartup bool         `json:"clearAtStartup"`
+	Disabled       bool         `json:"disabled"`
+	MaxPinned      int          `json:"maxPinned"`
+	Rules          []FilterRule `json:"rules,omitempty"`
+	MimeAllow      []string     `json:"mimeAllow,omitempty"`
+	MimeDeny       []string     `json:"mimeDeny,omitempty"`
+	Sync           *SyncConfig  `json:"sync,omitempty"`
+	OCR            *OCRConfig   `json:"ocr,omitempty"`
 }
 
 func DefaultConfig() Config {
@@ -95,27 +100,32 @@ type SearchResult struct {
 }
 
 type Entry struct {
-	ID        uint64    `json:"id"`
-	Data      []byte    `json:"data,omitempty"`
-	MimeType  string    `json:"mimeType"`
-	Preview   string    `json:"preview"`
-	Size      int       `json:"size"`
-	Timestamp time.Time `json:"timestamp"`
-	IsImage   bool      `json:"isImage"`
-	Hash      uint64    `json:"hash,omitempty"`
-	Pinned    bool      `json:"pinned"`
+	ID        uint64     `json:"id"`
+	Data      []byte     `json:"data,omitempty"`
+	MimeType  string     `json:"mimeType"`
+	Preview   string     `json:"preview"`
+	Size      int        `json:"size"`
+	Timestamp time.Time  `json:"timestamp"`
+	IsImage   bool       `json:"isImage"`
+	Hash      uint64     `json:"hash,omitempty"`
+	Pinned    bool       `json:"pinned"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	OCRText   string     `json:"ocrText,omitempty"`
 }
 
 type State struct {
-	Enabled bool    `json:"enabled"`
-	History []Entry `json:"history"`
-	Current *Entry  `json:"current,omitempty"`
+	Enabled    bool           `json:"enabled"`
+	History    []Entry        `json:"history"`
+	Current    *Entry         `json:"current,omitempty"`
+	Encryption EncryptionMode `json:"encryption,omitempty"`
+	Locked     bool           `json:"locked,omitempty"`
 }
 
 type Manager struct {
 	config      Config
 	configMutex sync.RWMutex
 	configPath  string
+	filter      *contentFilter
 
 	display wlclient.WaylandDisplay
 	wlCtx   wlcontext.WaylandContext
@@ -150,6 +160,19 @@ type Manager struct {
 	db     *bolt.DB
 	dbPath string
 
+	cipher      *entryCipher
+	cipherInfo  *cipherInfo
+	cipherMutex sync.RWMutex
+	rekeyMutex  sync.Mutex
+	keys        keyStore
+
+	sync      *syncService
+	syncMutex sync.Mutex
+
+	ocr         ocrFunc
+	ocrTrigger  chan struct{}
+	ocrWarnOnce sync.Once
+
 	state      *State
 	stateMutex sync.RWMutex
 
@@ -193,4 +216,6 @@ func (m *Manager) notifySubscribers() {
 	case m.dirty <- struct{}{}:
 	default:
 	}
+	m.pokeSync()
+	m.pokeOCR()
 }
@@ -15,6 +15,12 @@ type MatugenQueueResult struct {
 }
 
 func handleMatugenQueue(conn net.Conn, req models.Request) {
+	backend, err := matugen.ParseBackend(models.GetOr(req, "backend", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
 	opts := matugen.Options{
 		StateDir:            models.GetOr(req, "stateDir", ""),
 		ShellDir:            models.GetOr(req, "shellDir", ""),
@@ -24,6 +30,7 @@ func handleMatugenQueue(conn net.Conn, req models.Request) {
 		Mode:                matugen.ColorMode(models.GetOr(req, "mode", "")),
 		IconTheme:           models.GetOr(req, "iconTheme", ""),
 		MatugenType:         models.GetOr(req, "matugenType", ""),
+		Backend:             backend,
 		RunUserTemplates:    models.GetOr(req, "runUserTemplates", true),
 		StockColors:         models.GetOr(req, "stockColors", ""),
 		SyncModeWithPortal:  models.GetOr(req, "syncModeWithPortal", false),
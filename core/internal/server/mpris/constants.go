@@ -0,0 +1,21 @@
+package mpris
+
+const (
+	busNamePrefix      = "org.mpris.MediaPlayer2."
+	busNameNamespace   = "org.mpris.MediaPlayer2"
+	objectPath         = "/org/mpris/MediaPlayer2"
+	rootInterface      = "org.mpris.MediaPlayer2"
+	playerInterface    = "org.mpris.MediaPlayer2.Player"
+	dbusPropsInterface = "org.freedesktop.DBus.Properties"
+	dbusDest           = "org.freedesktop.DBus"
+	dbusPath           = "/org/freedesktop/DBus"
+	dbusInterface      = "org.freedesktop.DBus"
+
+	StatusPlaying = "Playing"
+	StatusPaused  = "Paused"
+	StatusStopped = "Stopped"
+
+	LoopNone     = "None"
+	LoopTrack    = "Track"
+	LoopPlaylist = "Playlist"
+)
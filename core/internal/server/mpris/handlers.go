@@ -0,0 +1,165 @@
+package mpris
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	switch req.Method {
+	case "mpris.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "mpris.playPause":
+		handleControl(conn, req, manager.PlayPause)
+	case "mpris.play":
+		handleControl(conn, req, manager.Play)
+	case "mpris.pause":
+		handleControl(conn, req, manager.Pause)
+	case "mpris.stop":
+		handleControl(conn, req, manager.Stop)
+	case "mpris.next":
+		handleControl(conn, req, manager.Next)
+	case "mpris.previous":
+		handleControl(conn, req, manager.Previous)
+	case "mpris.seek":
+		handleSeek(conn, req, manager)
+	case "mpris.setPosition":
+		handleSetPosition(conn, req, manager)
+	case "mpris.setVolume":
+		handleSetVolume(conn, req, manager)
+	case "mpris.setShuffle":
+		handleSetShuffle(conn, req, manager)
+	case "mpris.setLoopStatus":
+		handleSetLoopStatus(conn, req, manager)
+	case "mpris.setActive":
+		handleSetActive(conn, req, manager)
+	case "mpris.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleControl(conn net.Conn, req models.Request, action func(string) error) {
+	player := params.StringOpt(req.Params, "player", "")
+	if err := action(player); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSeek(conn net.Conn, req models.Request, manager *Manager) {
+	offset, err := params.Float(req.Params, "offset")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	player := params.StringOpt(req.Params, "player", "")
+	if err := manager.Seek(player, int64(offset)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetPosition(conn net.Conn, req models.Request, manager *Manager) {
+	position, err := params.Float(req.Params, "position")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	player := params.StringOpt(req.Params, "player", "")
+	if err := manager.SetPosition(player, int64(position)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetVolume(conn net.Conn, req models.Request, manager *Manager) {
+	volume, err := params.Float(req.Params, "volume")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	player := params.StringOpt(req.Params, "player", "")
+	if err := manager.SetVolume(player, volume); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetShuffle(conn net.Conn, req models.Request, manager *Manager) {
+	shuffle, err := params.Bool(req.Params, "shuffle")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	player := params.StringOpt(req.Params, "player", "")
+	if err := manager.SetShuffle(player, shuffle); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetLoopStatus(conn net.Conn, req models.Request, manager *Manager) {
+	status, err := params.String(req.Params, "loopStatus")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	player := params.StringOpt(req.Params, "player", "")
+	if err := manager.SetLoopStatus(player, status); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true})
+}
+
+func handleSetActive(conn net.Conn, req models.Request, manager *Manager) {
+	player, err := params.StringNonEmpty(req.Params, "player")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetActive(player); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package mpris
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNetConn struct {
+	net.Conn
+	readBuf  *bytes.Buffer
+	writeBuf *bytes.Buffer
+}
+
+func newMockNetConn() *mockNetConn {
+	return &mockNetConn{
+		readBuf:  &bytes.Buffer{},
+		writeBuf: &bytes.Buffer{},
+	}
+}
+
+func (m *mockNetConn) Read(b []byte) (n int, err error) {
+	return m.readBuf.Read(b)
+}
+
+func (m *mockNetConn) Write(b []byte) (n int, err error) {
+	return m.writeBuf.Write(b)
+}
+
+func TestHandleGetState(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	m := newTestManager(t, conn, newFakeClock())
+
+	nc := newMockNetConn()
+	HandleRequest(nc, models.Request{ID: 3, Method: "mpris.getState"}, m)
+
+	var resp models.Response[State]
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	assert.Equal(t, 3, resp.ID)
+	require.NotNil(t, resp.Result)
+	assert.Equal(t, spotify, resp.Result.Active)
+	require.Len(t, resp.Result.Players, 1)
+	assert.Equal(t, "Spotify track", resp.Result.Players[0].Metadata.Title)
+}
+
+func TestHandleControl(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	sp := newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	m := newTestManager(t, conn, newFakeClock())
+
+	sp.EXPECT().Call(playerInterface+".Previous", dbus.Flags(0)).Return(&dbus.Call{}).Once()
+
+	nc := newMockNetConn()
+	HandleRequest(nc, models.Request{ID: 1, Method: "mpris.previous"}, m)
+
+	var resp models.Response[models.SuccessResult]
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	assert.True(t, resp.Result.Success)
+}
+
+func TestHandleSeek(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	sp := newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	m := newTestManager(t, conn, newFakeClock())
+
+	sp.EXPECT().Call(playerInterface+".Seek", dbus.Flags(0), int64(10_000_000)).Return(&dbus.Call{}).Once()
+
+	nc := newMockNetConn()
+	HandleRequest(nc, models.Request{
+		ID:     2,
+		Method: "mpris.seek",
+		Params: map[string]any{"player": spotify, "offset": float64(10_000_000)},
+	}, m)
+
+	var resp models.Response[models.SuccessResult]
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+
+	nc = newMockNetConn()
+	HandleRequest(nc, models.Request{ID: 3, Method: "mpris.seek"}, m)
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	assert.NotEmpty(t, resp.Error)
+}
+
+func TestHandleSetActive(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 0)
+	m := newTestManager(t, conn, newFakeClock())
+
+	nc := newMockNetConn()
+	HandleRequest(nc, models.Request{
+		ID:     4,
+		Method: "mpris.setActive",
+		Params: map[string]any{"player": firefox},
+	}, m)
+
+	var resp models.Response[State]
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	assert.Equal(t, firefox, resp.Result.Active)
+}
+
+func TestHandleUnknownMethod(t *testing.T) {
+	nc := newMockNetConn()
+	HandleRequest(nc, models.Request{ID: 5, Method: "mpris.shuffleEverything"}, &Manager{})
+
+	var resp models.Response[any]
+	require.NoError(t, json.NewDecoder(nc.writeBuf).Decode(&resp))
+	assert.Contains(t, resp.Error, "unknown method")
+}
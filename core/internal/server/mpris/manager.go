@@ -0,0 +1,423 @@
+package mpris
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+func NewManager() (*Manager, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	m, err := NewManagerWithConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func NewManagerWithConn(conn DBusConn) (*Manager, error) {
+	return newManager(conn, time.Now)
+}
+
+func newManager(conn DBusConn, now func() time.Time) (*Manager, error) {
+	m := &Manager{
+		conn:     conn,
+		players:  make(map[string]*player),
+		owners:   make(map[string]map[string]struct{}),
+		dirty:    make(chan struct{}, 1),
+		signals:  make(chan *dbus.Signal, 256),
+		stopChan: make(chan struct{}),
+		now:      now,
+	}
+
+	if err := m.startSignalPump(); err != nil {
+		return nil, err
+	}
+
+	if err := m.discover(); err != nil {
+		m.stopSignalPump()
+		return nil, err
+	}
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	return m, nil
+}
+
+func (m *Manager) busObject() dbus.BusObject {
+	return m.conn.Object(dbusDest, dbus.ObjectPath(dbusPath))
+}
+
+func (m *Manager) discover() error {
+	var names []string
+	if err := m.busObject().Call(dbusInterface+".ListNames", 0).Store(&names); err != nil {
+		return fmt.Errorf("failed to list bus names: %w", err)
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, busNamePrefix) {
+			continue
+		}
+
+		var owner string
+		if err := m.busObject().Call(dbusInterface+".GetNameOwner", 0, name).Store(&owner); err != nil {
+			log.Debugf("[MPRIS] failed to resolve owner of %s: %v", name, err)
+			continue
+		}
+		m.addPlayer(name, owner)
+	}
+
+	return nil
+}
+
+func (m *Manager) addPlayer(name, owner string) {
+	obj := m.conn.Object(name, dbus.ObjectPath(objectPath))
+
+	p := &player{
+		info:  Player{Name: name, Rate: 1, Metadata: Metadata{Artists: []string{}, AlbumArtists: []string{}}},
+		owner: owner,
+	}
+
+	var rootProps map[string]dbus.Variant
+	if err := obj.Call(dbusPropsInterface+".GetAll", 0, rootInterface).Store(&rootProps); err == nil {
+		applyRootProps(&p.info, rootProps)
+	}
+
+	var playerProps map[string]dbus.Variant
+	if err := obj.Call(dbusPropsInterface+".GetAll", 0, playerInterface).Store(&playerProps); err != nil {
+		log.Debugf("[MPRIS] ignoring %s: %v", name, err)
+		return
+	}
+	applyPlayerProps(&p.info, playerProps)
+
+	now := m.now()
+	p.position = toInt64(playerProps["Position"])
+	p.positionAt = now
+	if p.info.PlaybackStatus == StatusPlaying {
+		p.lastPlaying = now
+	}
+
+	m.mu.Lock()
+	if old, ok := m.players[name]; ok {
+		m.removeOwnerLocked(old.owner, name)
+	}
+	m.players[name] = p
+	if m.owners[owner] == nil {
+		m.owners[owner] = make(map[string]struct{})
+	}
+	m.owners[owner][name] = struct{}{}
+	m.selectActiveLocked()
+	m.mu.Unlock()
+
+	log.Debugf("[MPRIS] player added: %s (%s)", name, p.info.Identity)
+	m.notifySubscribers()
+}
+
+func (m *Manager) removeOwnerLocked(owner, name string) {
+	delete(m.owners[owner], name)
+	if len(m.owners[owner]) == 0 {
+		delete(m.owners, owner)
+	}
+}
+
+func (m *Manager) removePlayer(name string) {
+	m.mu.Lock()
+	p, ok := m.players[name]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.players, name)
+	m.removeOwnerLocked(p.owner, name)
+	if m.active == name {
+		m.active = ""
+		m.pinned = false
+	}
+	m.selectActiveLocked()
+	m.mu.Unlock()
+
+	log.Debugf("[MPRIS] player removed: %s", name)
+	m.notifySubscribers()
+}
+
+func (m *Manager) refreshPosition(name string) {
+	obj := m.conn.Object(name, dbus.ObjectPath(objectPath))
+
+	var pos dbus.Variant
+	if err := obj.Call(dbusPropsInterface+".Get", 0, playerInterface, "Position").Store(&pos); err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	if p, ok := m.players[name]; ok {
+		p.position = toInt64(pos)
+		p.positionAt = m.now()
+	}
+	m.mu.Unlock()
+}
+
+// selectActiveLocked picks the player the shell should control. A pinned
+// choice from setActive wins; otherwise the most recently started playing
+// player, then the most recently played one, then a stable fallback.
+func (m *Manager) selectActiveLocked() {
+	if m.pinned {
+		if _, ok := m.players[m.active]; ok {
+			return
+		}
+		m.pinned = false
+	}
+
+	var best string
+	var bestPlayer *player
+	for name, p := range m.players {
+		if bestPlayer == nil || preferPlayer(name, p, best, bestPlayer) {
+			best, bestPlayer = name, p
+		}
+	}
+
+	// Nothing has played yet: keep the current choice rather than jumping
+	// between idle players as they appear.
+	if bestPlayer != nil && bestPlayer.lastPlaying.IsZero() {
+		if _, ok := m.players[m.active]; ok {
+			return
+		}
+	}
+	m.active = best
+}
+
+func preferPlayer(name string, p *player, otherName string, other *player) bool {
+	playing := p.info.PlaybackStatus == StatusPlaying
+	otherPlaying := other.info.PlaybackStatus == StatusPlaying
+	if playing != otherPlaying {
+		return playing
+	}
+	if !p.lastPlaying.Equal(other.lastPlaying) {
+		return p.lastPlaying.After(other.lastPlaying)
+	}
+	return name < otherName
+}
+
+func (p *player) currentPosition(now time.Time) int64 {
+	pos := p.position
+	if p.info.PlaybackStatus == StatusPlaying {
+		rate := p.info.Rate
+		if rate <= 0 {
+			rate = 1
+		}
+		pos += int64(float64(now.Sub(p.positionAt).Microseconds()) * rate)
+	}
+	if length := p.info.Metadata.Length; length > 0 && pos > length {
+		pos = length
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos
+}
+
+func (m *Manager) GetState() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	players := make([]Player, 0, len(m.players))
+	for _, p := range m.players {
+		info := p.info
+		info.Position = p.currentPosition(now)
+		info.PositionTime = now.UnixMilli()
+		info.Metadata.Artists = append([]string{}, p.info.Metadata.Artists...)
+		info.Metadata.AlbumArtists = append([]string{}, p.info.Metadata.AlbumArtists...)
+		players = append(players, info)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+
+	return State{Active: m.active, Players: players}
+}
+
+func (m *Manager) resolvePlayer(name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if name == "" {
+		name = m.active
+	}
+	if name == "" {
+		return "", fmt.Errorf("no active player")
+	}
+	if _, ok := m.players[name]; !ok {
+		return "", fmt.Errorf("player not found: %s", name)
+	}
+	return name, nil
+}
+
+func (m *Manager) callPlayer(name, method string, args ...any) error {
+	target, err := m.resolvePlayer(name)
+	if err != nil {
+		return err
+	}
+
+	obj := m.conn.Object(target, dbus.ObjectPath(objectPath))
+	if call := obj.Call(playerInterface+"."+method, 0, args...); call.Err != nil {
+		return fmt.Errorf("%s failed: %w", method, call.Err)
+	}
+	return nil
+}
+
+func (m *Manager) setPlayerProperty(name, property string, value any) error {
+	target, err := m.resolvePlayer(name)
+	if err != nil {
+		return err
+	}
+
+	obj := m.conn.Object(target, dbus.ObjectPath(objectPath))
+	if call := obj.Call(dbusPropsInterface+".Set", 0, playerInterface, property, dbus.MakeVariant(value)); call.Err != nil {
+		return fmt.Errorf("failed to set %s: %w", property, call.Err)
+	}
+	return nil
+}
+
+func (m *Manager) PlayPause(name string) error { return m.callPlayer(name, "PlayPause") }
+func (m *Manager) Play(name string) error      { return m.callPlayer(name, "Play") }
+func (m *Manager) Pause(name string) error     { return m.callPlayer(name, "Pause") }
+func (m *Manager) Stop(name string) error      { return m.callPlayer(name, "Stop") }
+func (m *Manager) Next(name string) error      { return m.callPlayer(name, "Next") }
+func (m *Manager) Previous(name string) error  { return m.callPlayer(name, "Previous") }
+
+// Seek moves the playback position by offset microseconds.
+func (m *Manager) Seek(name string, offset int64) error {
+	return m.callPlayer(name, "Seek", offset)
+}
+
+// SetPosition jumps to an absolute position in microseconds within the
+// current track.
+func (m *Manager) SetPosition(name string, position int64) error {
+	target, err := m.resolvePlayer(name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	trackID := m.players[target].info.Metadata.TrackID
+	m.mu.RUnlock()
+
+	if trackID == "" {
+		return fmt.Errorf("player has no track id")
+	}
+	return m.callPlayer(target, "SetPosition", dbus.ObjectPath(trackID), position)
+}
+
+func (m *Manager) SetVolume(name string, volume float64) error {
+	return m.setPlayerProperty(name, "Volume", max(volume, 0))
+}
+
+func (m *Manager) SetShuffle(name string, shuffle bool) error {
+	return m.setPlayerProperty(name, "Shuffle", shuffle)
+}
+
+func (m *Manager) SetLoopStatus(name, status string) error {
+	switch status {
+	case LoopNone, LoopTrack, LoopPlaylist:
+	default:
+		return fmt.Errorf("invalid loop status: %s", status)
+	}
+	return m.setPlayerProperty(name, "LoopStatus", status)
+}
+
+// SetActive pins the given player as active until another player starts
+// playing.
+func (m *Manager) SetActive(name string) error {
+	m.mu.Lock()
+	if _, ok := m.players[name]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("player not found: %s", name)
+	}
+	m.active = name
+	m.pinned = true
+	m.mu.Unlock()
+
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if val, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(val)
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+	const minGap = 100 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.GetState()
+			m.subscribers.Range(func(key string, ch chan State) bool {
+				select {
+				case ch <- currentState:
+				default:
+				}
+				return true
+			})
+			pending = false
+		}
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.notifierWg.Wait()
+
+	m.stopSignalPump()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+
+	if m.conn != nil {
+		m.conn.Close()
+	}
+}
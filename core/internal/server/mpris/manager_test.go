@@ -0,0 +1,342 @@
+package mpris
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	mu      sync.Mutex
+	objects map[string]dbus.BusObject
+	matches int
+	closed  bool
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{objects: make(map[string]dbus.BusObject)}
+}
+
+func (c *fakeConn) Object(dest string, path dbus.ObjectPath) dbus.BusObject {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.objects[dest]
+}
+
+func (c *fakeConn) Signal(ch chan<- *dbus.Signal)       {}
+func (c *fakeConn) RemoveSignal(ch chan<- *dbus.Signal) {}
+
+func (c *fakeConn) AddMatchSignal(options ...dbus.MatchOption) error {
+	c.mu.Lock()
+	c.matches++
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) RemoveMatchSignal(options ...dbus.MatchOption) error {
+	c.mu.Lock()
+	c.matches--
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+const (
+	spotify = busNamePrefix + "spotify"
+	firefox = busNamePrefix + "firefox.instance_1_42"
+)
+
+func trackMetadata(trackID, title string, length int64) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(trackID)),
+		"xesam:title":   dbus.MakeVariant(title),
+		"xesam:artist":  dbus.MakeVariant([]string{"Boards of Canada"}),
+		"xesam:album":   dbus.MakeVariant("Geogaddi"),
+		"mpris:artUrl":  dbus.MakeVariant("https://i.scdn.co/image/ab67616d"),
+		"mpris:length":  dbus.MakeVariant(uint64(length)),
+	}
+}
+
+func newFakePlayer(t *testing.T, conn *fakeConn, name, identity, status string, position int64) *mockdbus.MockBusObject {
+	obj := mockdbus.NewMockBusObject(t)
+	obj.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), rootInterface).
+		Return(&dbus.Call{Body: []any{map[string]dbus.Variant{
+			"Identity":     dbus.MakeVariant(identity),
+			"DesktopEntry": dbus.MakeVariant(identity),
+			"CanRaise":     dbus.MakeVariant(true),
+		}}}).Maybe()
+	obj.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), playerInterface).
+		Return(&dbus.Call{Body: []any{map[string]dbus.Variant{
+			"PlaybackStatus": dbus.MakeVariant(status),
+			"LoopStatus":     dbus.MakeVariant(LoopNone),
+			"Shuffle":        dbus.MakeVariant(false),
+			"Volume":         dbus.MakeVariant(0.8),
+			"Rate":           dbus.MakeVariant(1.0),
+			"Position":       dbus.MakeVariant(position),
+			"Metadata":       dbus.MakeVariant(trackMetadata("/org/mpris/MediaPlayer2/Track/1", identity+" track", 300_000_000)),
+			"CanControl":     dbus.MakeVariant(true),
+			"CanPlay":        dbus.MakeVariant(true),
+			"CanPause":       dbus.MakeVariant(true),
+			"CanGoNext":      dbus.MakeVariant(true),
+			"CanGoPrevious":  dbus.MakeVariant(true),
+			"CanSeek":        dbus.MakeVariant(true),
+		}}}).Maybe()
+
+	conn.mu.Lock()
+	conn.objects[name] = obj
+	conn.mu.Unlock()
+	return obj
+}
+
+func newFakeBus(t *testing.T, conn *fakeConn, owners map[string]string) {
+	names := []string{dbusDest, ":1.1", "org.freedesktop.Notifications"}
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bus := mockdbus.NewMockBusObject(t)
+	bus.EXPECT().Call(dbusInterface+".ListNames", dbus.Flags(0)).
+		Return(&dbus.Call{Body: []any{names}}).Maybe()
+	for name, owner := range owners {
+		bus.EXPECT().Call(dbusInterface+".GetNameOwner", dbus.Flags(0), name).
+			Return(&dbus.Call{Body: []any{owner}}).Maybe()
+	}
+	conn.objects[dbusDest] = bus
+}
+
+func newTestManager(t *testing.T, conn *fakeConn, clock *fakeClock) *Manager {
+	m, err := newManager(conn, clock.Now)
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestNewManagerWithConn(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPaused, 5_000_000)
+	newFakePlayer(t, conn, firefox, "Firefox", StatusPlaying, 1_000_000)
+
+	clock := newFakeClock()
+	m, err := newManager(conn, clock.Now)
+	require.NoError(t, err)
+
+	state := m.GetState()
+	assert.Equal(t, firefox, state.Active)
+	require.Len(t, state.Players, 2)
+	assert.Equal(t, firefox, state.Players[0].Name)
+	assert.Equal(t, spotify, state.Players[1].Name)
+
+	sp := state.Players[1]
+	assert.Equal(t, "Spotify", sp.Identity)
+	assert.Equal(t, StatusPaused, sp.PlaybackStatus)
+	assert.Equal(t, "Spotify track", sp.Metadata.Title)
+	assert.Equal(t, []string{"Boards of Canada"}, sp.Metadata.Artists)
+	assert.Equal(t, "/org/mpris/MediaPlayer2/Track/1", sp.Metadata.TrackID)
+	assert.Equal(t, int64(300_000_000), sp.Metadata.Length)
+	assert.Equal(t, int64(5_000_000), sp.Position)
+	assert.True(t, sp.CanSeek)
+
+	assert.Equal(t, 3, conn.matches)
+	m.Close()
+	assert.Equal(t, 0, conn.matches)
+	assert.True(t, conn.closed)
+}
+
+func TestNewManagerWithConn_ListNamesFails(t *testing.T) {
+	conn := newFakeConn()
+	bus := mockdbus.NewMockBusObject(t)
+	bus.EXPECT().Call(dbusInterface+".ListNames", dbus.Flags(0)).
+		Return(&dbus.Call{Err: assert.AnError})
+	conn.objects[dbusDest] = bus
+
+	m, err := NewManagerWithConn(conn)
+	assert.Nil(t, m)
+	assert.ErrorContains(t, err, "failed to list bus names")
+	assert.Equal(t, 0, conn.matches)
+}
+
+func TestManager_PositionInterpolation(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 10_000_000)
+
+	clock := newFakeClock()
+	m := newTestManager(t, conn, clock)
+
+	clock.Advance(2500 * time.Millisecond)
+	state := m.GetState()
+	require.Len(t, state.Players, 1)
+	assert.Equal(t, int64(12_500_000), state.Players[0].Position)
+	assert.Equal(t, clock.Now().UnixMilli(), state.Players[0].PositionTime)
+
+	// Never report past the end of the track.
+	clock.Advance(time.Hour)
+	assert.Equal(t, int64(300_000_000), m.GetState().Players[0].Position)
+}
+
+func TestManager_ActiveSelection(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPaused, 0)
+	newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 0)
+
+	clock := newFakeClock()
+	m := newTestManager(t, conn, clock)
+
+	// Nothing playing: stable fallback by name.
+	assert.Equal(t, firefox, m.GetState().Active)
+
+	tests := []struct {
+		name     string
+		statuses map[string]string
+		played   map[string]time.Duration
+		expected string
+	}{
+		{"playing wins", map[string]string{spotify: StatusPlaying}, map[string]time.Duration{spotify: 1}, spotify},
+		{"most recently played", nil, map[string]time.Duration{spotify: 1, firefox: 2}, firefox},
+		{"playing beats recent", map[string]string{spotify: StatusPlaying}, map[string]time.Duration{spotify: 1, firefox: 2}, spotify},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.mu.Lock()
+			base := clock.Now()
+			for name, p := range m.players {
+				p.info.PlaybackStatus = StatusPaused
+				if status, ok := tt.statuses[name]; ok {
+					p.info.PlaybackStatus = status
+				}
+				p.lastPlaying = time.Time{}
+				if d, ok := tt.played[name]; ok {
+					p.lastPlaying = base.Add(d * time.Second)
+				}
+			}
+			m.selectActiveLocked()
+			active := m.active
+			m.mu.Unlock()
+
+			assert.Equal(t, tt.expected, active)
+		})
+	}
+}
+
+func TestManager_SetActivePins(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 0)
+
+	m := newTestManager(t, conn, newFakeClock())
+	assert.Equal(t, spotify, m.GetState().Active)
+
+	require.NoError(t, m.SetActive(firefox))
+	assert.Equal(t, firefox, m.GetState().Active)
+
+	m.mu.Lock()
+	m.selectActiveLocked()
+	m.mu.Unlock()
+	assert.Equal(t, firefox, m.GetState().Active)
+
+	assert.ErrorContains(t, m.SetActive(busNamePrefix+"vlc"), "player not found")
+}
+
+func TestManager_Controls(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	sp := newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	ff := newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 0)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	sp.EXPECT().Call(playerInterface+".PlayPause", dbus.Flags(0)).Return(&dbus.Call{}).Once()
+	require.NoError(t, m.PlayPause(""))
+
+	ff.EXPECT().Call(playerInterface+".Next", dbus.Flags(0)).Return(&dbus.Call{}).Once()
+	require.NoError(t, m.Next(firefox))
+
+	sp.EXPECT().Call(playerInterface+".Seek", dbus.Flags(0), int64(-5_000_000)).Return(&dbus.Call{}).Once()
+	require.NoError(t, m.Seek("", -5_000_000))
+
+	sp.EXPECT().
+		Call(playerInterface+".SetPosition", dbus.Flags(0), dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1"), int64(42_000_000)).
+		Return(&dbus.Call{}).Once()
+	require.NoError(t, m.SetPosition("", 42_000_000))
+
+	sp.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), playerInterface, "Volume", dbus.MakeVariant(0.0)).
+		Return(&dbus.Call{}).Once()
+	require.NoError(t, m.SetVolume("", -1))
+
+	sp.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), playerInterface, "LoopStatus", dbus.MakeVariant(LoopPlaylist)).
+		Return(&dbus.Call{}).Once()
+	require.NoError(t, m.SetLoopStatus("", LoopPlaylist))
+	assert.ErrorContains(t, m.SetLoopStatus("", "Forever"), "invalid loop status")
+
+	ff.EXPECT().Call(playerInterface+".Pause", dbus.Flags(0)).Return(&dbus.Call{Err: assert.AnError}).Once()
+	assert.ErrorContains(t, m.Pause(firefox), "Pause failed")
+
+	assert.ErrorContains(t, m.Play(busNamePrefix+"vlc"), "player not found")
+}
+
+func TestManager_NoActivePlayer(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, nil)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	state := m.GetState()
+	assert.Empty(t, state.Active)
+	assert.NotNil(t, state.Players)
+	assert.ErrorContains(t, m.PlayPause(""), "no active player")
+}
+
+func TestStringList(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, stringList(dbus.MakeVariant([]string{"a", "b"})))
+	assert.Equal(t, []string{"solo"}, stringList(dbus.MakeVariant("solo")))
+	assert.Equal(t, []string{}, stringList(dbus.MakeVariant("")))
+	assert.Equal(t, []string{}, stringList(dbus.Variant{}))
+}
+
+func TestToInt64(t *testing.T) {
+	assert.Equal(t, int64(7), toInt64(dbus.MakeVariant(int64(7))))
+	assert.Equal(t, int64(7), toInt64(dbus.MakeVariant(uint64(7))))
+	assert.Equal(t, int64(7), toInt64(dbus.MakeVariant(int32(7))))
+	assert.Equal(t, int64(7), toInt64(dbus.MakeVariant(7.9)))
+	assert.Equal(t, int64(0), toInt64(dbus.MakeVariant("7")))
+}
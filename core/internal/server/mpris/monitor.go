@@ -0,0 +1,202 @@
+package mpris
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func (m *Manager) signalMatches() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(objectPath)),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		},
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(objectPath)),
+			dbus.WithMatchInterface(playerInterface),
+			dbus.WithMatchMember("Seeked"),
+		},
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(dbusPath)),
+			dbus.WithMatchInterface(dbusInterface),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg0Namespace(busNameNamespace),
+		},
+	}
+}
+
+func (m *Manager) startSignalPump() error {
+	m.conn.Signal(m.signals)
+
+	matches := m.signalMatches()
+	for i, match := range matches {
+		if err := m.conn.AddMatchSignal(match...); err != nil {
+			for _, added := range matches[:i] {
+				m.conn.RemoveMatchSignal(added...)
+			}
+			m.conn.RemoveSignal(m.signals)
+			return err
+		}
+	}
+
+	m.sigWG.Add(1)
+	go func() {
+		defer m.sigWG.Done()
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case sig, ok := <-m.signals:
+				if !ok {
+					return
+				}
+				if sig == nil {
+					continue
+				}
+				m.handleDBusSignal(sig)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) stopSignalPump() {
+	if m.conn == nil {
+		return
+	}
+	for _, match := range m.signalMatches() {
+		m.conn.RemoveMatchSignal(match...)
+	}
+	m.conn.RemoveSignal(m.signals)
+	close(m.signals)
+
+	m.sigWG.Wait()
+}
+
+func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
+	switch sig.Name {
+	case dbusInterface + ".NameOwnerChanged":
+		if len(sig.Body) != 3 {
+			return
+		}
+		name, _ := sig.Body[0].(string)
+		oldOwner, _ := sig.Body[1].(string)
+		newOwner, _ := sig.Body[2].(string)
+		if !strings.HasPrefix(name, busNamePrefix) {
+			return
+		}
+		if oldOwner != "" {
+			m.removePlayer(name)
+		}
+		if newOwner != "" {
+			m.addPlayer(name, newOwner)
+		}
+
+	case dbusPropsInterface + ".PropertiesChanged":
+		m.handlePropertiesChanged(sig)
+
+	case playerInterface + ".Seeked":
+		if len(sig.Body) == 0 {
+			return
+		}
+		m.mu.Lock()
+		names := m.ownedNamesLocked(sig.Sender)
+		for _, name := range names {
+			p := m.players[name]
+			p.position = toInt64(dbus.MakeVariant(sig.Body[0]))
+			p.positionAt = m.now()
+		}
+		m.mu.Unlock()
+		if len(names) > 0 {
+			m.notifySubscribers()
+		}
+	}
+}
+
+func (m *Manager) handlePropertiesChanged(sig *dbus.Signal) {
+	if len(sig.Body) < 2 {
+		return
+	}
+
+	iface, _ := sig.Body[0].(string)
+	changes, ok := sig.Body[1].(map[string]dbus.Variant)
+	if !ok {
+		return
+	}
+
+	if iface != rootInterface && iface != playerInterface {
+		return
+	}
+
+	// Signals only carry the unique sender, so every name the connection
+	// owns gets the change; they all front the same object.
+	m.mu.Lock()
+	names := m.ownedNamesLocked(sig.Sender)
+	var refresh []string
+	for _, name := range names {
+		if m.applyChangesLocked(name, iface, changes) {
+			refresh = append(refresh, name)
+		}
+	}
+	if iface == playerInterface {
+		m.selectActiveLocked()
+	}
+	m.mu.Unlock()
+
+	if len(names) == 0 {
+		return
+	}
+	for _, name := range refresh {
+		m.refreshPosition(name)
+	}
+	m.notifySubscribers()
+}
+
+// applyChangesLocked applies a PropertiesChanged payload to one player and
+// reports whether its position has to be fetched again.
+func (m *Manager) applyChangesLocked(name, iface string, changes map[string]dbus.Variant) bool {
+	p := m.players[name]
+
+	if iface == rootInterface {
+		applyRootProps(&p.info, changes)
+		return false
+	}
+
+	// Bring the extrapolated position forward before the status or rate
+	// changes so pausing does not lose the time played since the last
+	// report.
+	now := m.now()
+	p.position = p.currentPosition(now)
+	p.positionAt = now
+
+	oldTrack := p.info.Metadata.TrackID
+	statusChanged := applyPlayerProps(&p.info, changes)
+	_, hasMetadata := changes["Metadata"]
+	needsPosition := statusChanged || (hasMetadata && p.info.Metadata.TrackID != oldTrack)
+
+	if pos, ok := changes["Position"]; ok {
+		p.position = toInt64(pos)
+		needsPosition = false
+	}
+
+	if statusChanged && p.info.PlaybackStatus == StatusPlaying {
+		p.lastPlaying = now
+		if m.active != name {
+			m.pinned = false
+		}
+	}
+	return needsPosition
+}
+
+// ownedNamesLocked returns the player names owned by a unique bus name.
+func (m *Manager) ownedNamesLocked(owner string) []string {
+	names := make([]string, 0, len(m.owners[owner]))
+	for name := range m.owners[owner] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
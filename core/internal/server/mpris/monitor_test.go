@@ -0,0 +1,214 @@
+package mpris
+
+import (
+	"testing"
+	"time"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func propsChanged(sender, iface string, changes map[string]dbus.Variant) *dbus.Signal {
+	return &dbus.Signal{
+		Sender: sender,
+		Path:   dbus.ObjectPath(objectPath),
+		Name:   dbusPropsInterface + ".PropertiesChanged",
+		Body:   []any{iface, changes, []string{}},
+	}
+}
+
+func expectPosition(obj *mockdbus.MockBusObject, position int64) {
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Get", dbus.Flags(0), playerInterface, "Position").
+		Return(&dbus.Call{Body: []any{dbus.MakeVariant(position)}}).Once()
+}
+
+func TestHandleDBusSignal_PlaybackStatusSwitchesActive(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+	ff := newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 2_000_000)
+
+	clock := newFakeClock()
+	m := newTestManager(t, conn, clock)
+	assert.Equal(t, spotify, m.GetState().Active)
+
+	clock.Advance(time.Second)
+	expectPosition(ff, 2_000_000)
+	m.handleDBusSignal(propsChanged(":1.30", playerInterface, map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant(StatusPlaying),
+	}))
+
+	state := m.GetState()
+	assert.Equal(t, firefox, state.Active)
+	assert.Equal(t, StatusPlaying, state.Players[0].PlaybackStatus)
+	assert.Equal(t, int64(2_000_000), state.Players[0].Position)
+}
+
+func TestHandleDBusSignal_PauseKeepsPlayedTime(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	sp := newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 0)
+
+	clock := newFakeClock()
+	m := newTestManager(t, conn, clock)
+
+	clock.Advance(3 * time.Second)
+	sp.EXPECT().
+		Call(dbusPropsInterface+".Get", dbus.Flags(0), playerInterface, "Position").
+		Return(&dbus.Call{Err: assert.AnError}).Once()
+	m.handleDBusSignal(propsChanged(":1.20", playerInterface, map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant(StatusPaused),
+	}))
+
+	clock.Advance(10 * time.Second)
+	state := m.GetState()
+	assert.Equal(t, StatusPaused, state.Players[0].PlaybackStatus)
+	assert.Equal(t, int64(3_000_000), state.Players[0].Position)
+	assert.Equal(t, spotify, state.Active)
+}
+
+func TestHandleDBusSignal_TrackChange(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	sp := newFakePlayer(t, conn, spotify, "Spotify", StatusPlaying, 120_000_000)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	expectPosition(sp, 0)
+	m.handleDBusSignal(propsChanged(":1.20", playerInterface, map[string]dbus.Variant{
+		"Metadata": dbus.MakeVariant(trackMetadata("/org/mpris/MediaPlayer2/Track/2", "Dawn Chorus", 240_000_000)),
+	}))
+
+	state := m.GetState()
+	assert.Equal(t, "Dawn Chorus", state.Players[0].Metadata.Title)
+	assert.Equal(t, int64(0), state.Players[0].Position)
+
+	// Same track re-announced: no position round trip.
+	m.handleDBusSignal(propsChanged(":1.20", playerInterface, map[string]dbus.Variant{
+		"Metadata": dbus.MakeVariant(trackMetadata("/org/mpris/MediaPlayer2/Track/2", "Dawn Chorus", 240_000_000)),
+		"Volume":   dbus.MakeVariant(0.5),
+	}))
+	assert.Equal(t, 0.5, m.GetState().Players[0].Volume)
+}
+
+func TestHandleDBusSignal_Seeked(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPaused, 0)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	m.handleDBusSignal(&dbus.Signal{
+		Sender: ":1.20",
+		Name:   playerInterface + ".Seeked",
+		Body:   []any{int64(90_000_000)},
+	})
+	assert.Equal(t, int64(90_000_000), m.GetState().Players[0].Position)
+
+	// Unknown senders are ignored.
+	m.handleDBusSignal(&dbus.Signal{
+		Sender: ":1.99",
+		Name:   playerInterface + ".Seeked",
+		Body:   []any{int64(1)},
+	})
+	assert.Equal(t, int64(90_000_000), m.GetState().Players[0].Position)
+}
+
+func TestHandleDBusSignal_NameOwnerChanged(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPaused, 0)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	newFakePlayer(t, conn, firefox, "Firefox", StatusPlaying, 0)
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusInterface + ".NameOwnerChanged",
+		Body: []any{firefox, "", ":1.30"},
+	})
+
+	state := m.GetState()
+	require.Len(t, state.Players, 2)
+	assert.Equal(t, firefox, state.Active)
+
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusInterface + ".NameOwnerChanged",
+		Body: []any{firefox, ":1.30", ""},
+	})
+
+	state = m.GetState()
+	require.Len(t, state.Players, 1)
+	assert.Equal(t, spotify, state.Active)
+
+	m.mu.RLock()
+	_, stale := m.owners[":1.30"]
+	m.mu.RUnlock()
+	assert.False(t, stale)
+
+	// Non-MPRIS names never reach the player table.
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusInterface + ".NameOwnerChanged",
+		Body: []any{"org.freedesktop.Notifications", "", ":1.40"},
+	})
+	assert.Len(t, m.GetState().Players, 1)
+}
+
+func TestHandleDBusSignal_SharedOwner(t *testing.T) {
+	const (
+		vlc         = busNamePrefix + "vlc"
+		vlcInstance = busNamePrefix + "vlc.instance4242"
+	)
+
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{vlc: ":1.50", vlcInstance: ":1.50"})
+	newFakePlayer(t, conn, vlc, "VLC", StatusPaused, 0)
+	newFakePlayer(t, conn, vlcInstance, "VLC", StatusPaused, 0)
+
+	m := newTestManager(t, conn, newFakeClock())
+
+	m.handleDBusSignal(propsChanged(":1.50", playerInterface, map[string]dbus.Variant{
+		"Volume": dbus.MakeVariant(0.25),
+	}))
+	state := m.GetState()
+	require.Len(t, state.Players, 2)
+	for _, p := range state.Players {
+		assert.Equal(t, 0.25, p.Volume, p.Name)
+	}
+
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusInterface + ".NameOwnerChanged",
+		Body: []any{vlcInstance, ":1.50", ""},
+	})
+	m.handleDBusSignal(&dbus.Signal{
+		Sender: ":1.50",
+		Name:   playerInterface + ".Seeked",
+		Body:   []any{int64(7_000_000)},
+	})
+
+	state = m.GetState()
+	require.Len(t, state.Players, 1)
+	assert.Equal(t, vlc, state.Players[0].Name)
+	assert.Equal(t, int64(7_000_000), state.Players[0].Position)
+}
+
+func TestHandleDBusSignal_PlayingUnpins(t *testing.T) {
+	conn := newFakeConn()
+	newFakeBus(t, conn, map[string]string{spotify: ":1.20", firefox: ":1.30"})
+	newFakePlayer(t, conn, spotify, "Spotify", StatusPaused, 0)
+	ff := newFakePlayer(t, conn, firefox, "Firefox", StatusPaused, 0)
+
+	clock := newFakeClock()
+	m := newTestManager(t, conn, clock)
+	require.NoError(t, m.SetActive(spotify))
+
+	clock.Advance(time.Second)
+	expectPosition(ff, 0)
+	m.handleDBusSignal(propsChanged(":1.30", playerInterface, map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant(StatusPlaying),
+	}))
+
+	assert.Equal(t, firefox, m.GetState().Active)
+}
@@ -0,0 +1,122 @@
+package mpris
+
+import (
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/dbusutil"
+	"github.com/godbus/dbus/v5"
+)
+
+func applyRootProps(p *Player, props map[string]dbus.Variant) {
+	if v, ok := dbusutil.Get[string](props, "Identity"); ok {
+		p.Identity = v
+	}
+	if v, ok := dbusutil.Get[string](props, "DesktopEntry"); ok {
+		p.DesktopEntry = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanRaise"); ok {
+		p.CanRaise = v
+	}
+}
+
+// applyPlayerProps merges changed Player properties into p and reports
+// whether the playback status changed, since that requires re-reading the
+// position which players do not announce through PropertiesChanged.
+func applyPlayerProps(p *Player, props map[string]dbus.Variant) (statusChanged bool) {
+	if v, ok := dbusutil.Get[string](props, "PlaybackStatus"); ok {
+		statusChanged = v != p.PlaybackStatus
+		p.PlaybackStatus = v
+	}
+	if v, ok := dbusutil.Get[string](props, "LoopStatus"); ok {
+		p.LoopStatus = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "Shuffle"); ok {
+		p.Shuffle = v
+	}
+	if v, ok := dbusutil.Get[float64](props, "Volume"); ok {
+		p.Volume = v
+	}
+	if v, ok := dbusutil.Get[float64](props, "Rate"); ok {
+		p.Rate = v
+	}
+	if v, ok := dbusutil.Get[map[string]dbus.Variant](props, "Metadata"); ok {
+		p.Metadata = parseMetadata(v)
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanControl"); ok {
+		p.CanControl = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanPlay"); ok {
+		p.CanPlay = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanPause"); ok {
+		p.CanPause = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanGoNext"); ok {
+		p.CanGoNext = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanGoPrevious"); ok {
+		p.CanGoPrevious = v
+	}
+	if v, ok := dbusutil.Get[bool](props, "CanSeek"); ok {
+		p.CanSeek = v
+	}
+	return statusChanged
+}
+
+func parseMetadata(md map[string]dbus.Variant) Metadata {
+	meta := Metadata{
+		Title:        dbusutil.GetOr(md, "xesam:title", ""),
+		Album:        dbusutil.GetOr(md, "xesam:album", ""),
+		ArtURL:       dbusutil.GetOr(md, "mpris:artUrl", ""),
+		URL:          dbusutil.GetOr(md, "xesam:url", ""),
+		Artists:      stringList(md["xesam:artist"]),
+		AlbumArtists: stringList(md["xesam:albumArtist"]),
+		Length:       toInt64(md["mpris:length"]),
+	}
+
+	switch v := dbusutil.Normalize(md["mpris:trackid"]).(type) {
+	case string:
+		meta.TrackID = v
+	}
+
+	return meta
+}
+
+// stringList accepts both the spec'd "as" and the plain "s" some players
+// send for artist fields.
+func stringList(v dbus.Variant) []string {
+	switch val := v.Value().(type) {
+	case []string:
+		return val
+	case string:
+		if val == "" {
+			return []string{}
+		}
+		return []string{val}
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return []string{}
+}
+
+// toInt64 normalizes the integer types players use for mpris:length and
+// Position; the spec says int64 but uint64, int32 and double all occur.
+func toInt64(v dbus.Variant) int64 {
+	switch val := v.Value().(type) {
+	case int64:
+		return val
+	case uint64:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case uint32:
+		return int64(val)
+	case float64:
+		return int64(val)
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package mpris
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+	"github.com/godbus/dbus/v5"
+)
+
+type DBusConn interface {
+	Object(dest string, path dbus.ObjectPath) dbus.BusObject
+	Signal(ch chan<- *dbus.Signal)
+	RemoveSignal(ch chan<- *dbus.Signal)
+	AddMatchSignal(options ...dbus.MatchOption) error
+	RemoveMatchSignal(options ...dbus.MatchOption) error
+	Close() error
+}
+
+type Metadata struct {
+	TrackID      string   `json:"trackId"`
+	Title        string   `json:"title"`
+	Artists      []string `json:"artists"`
+	Album        string   `json:"album"`
+	AlbumArtists []string `json:"albumArtists"`
+	ArtURL       string   `json:"artUrl"`
+	URL          string   `json:"url"`
+	Length       int64    `json:"length"`
+}
+
+type Player struct {
+	Name           string   `json:"name"`
+	Identity       string   `json:"identity"`
+	DesktopEntry   string   `json:"desktopEntry"`
+	PlaybackStatus string   `json:"playbackStatus"`
+	LoopStatus     string   `json:"loopStatus"`
+	Shuffle        bool     `json:"shuffle"`
+	Volume         float64  `json:"volume"`
+	Rate           float64  `json:"rate"`
+	Position       int64    `json:"position"`
+	PositionTime   int64    `json:"positionTime"`
+	Metadata       Metadata `json:"metadata"`
+	CanControl     bool     `json:"canControl"`
+	CanPlay        bool     `json:"canPlay"`
+	CanPause       bool     `json:"canPause"`
+	CanGoNext      bool     `json:"canGoNext"`
+	CanGoPrevious  bool     `json:"canGoPrevious"`
+	CanSeek        bool     `json:"canSeek"`
+	CanRaise       bool     `json:"canRaise"`
+}
+
+type State struct {
+	Active  string   `json:"active"`
+	Players []Player `json:"players"`
+}
+
+// player is the internal bookkeeping for one MPRIS name. Position is the
+// last value reported by the player at positionAt; the exported position is
+// extrapolated from it while playing.
+type player struct {
+	info        Player
+	owner       string
+	position    int64
+	positionAt  time.Time
+	lastPlaying time.Time
+}
+
+type Manager struct {
+	conn DBusConn
+
+	mu      sync.RWMutex
+	players map[string]*player
+	owners  map[string]map[string]struct{}
+	active  string
+	pinned  bool
+
+	subscribers syncmap.Map[string, chan State]
+	dirty       chan struct{}
+	notifierWg  sync.WaitGroup
+
+	signals  chan *dbus.Signal
+	sigWG    sync.WaitGroup
+	stopChan chan struct{}
+
+	now func() time.Time
+}
@@ -223,6 +223,14 @@ func (a *SecretAgent) GetSecrets(
 						}
 					}
 				}
+			case "gsm":
+				if gsmSettings, ok := conn["gsm"]; ok {
+					if flagsVariant, ok := gsmSettings["password-flags"]; ok {
+						if pwdFlags, ok := flagsVariant.Value().(uint32); ok {
+							passwordFlags = pwdFlags
+						}
+					}
+				}
 			}
 
 			if passwordFlags == 0xFFFF {
@@ -239,6 +247,8 @@ func (a *SecretAgent) GetSecrets(
 					fields = []string{"psk"}
 				case "802-1x":
 					fields = infer8021xFields(conn)
+				case "gsm":
+					fields = []string{"password"}
 				default:
 					log.Warnf("[SecretAgent] Agent-owned secrets for unhandled setting %s (flags=%d)", settingName, passwordFlags)
 					return nil, dbus.NewError("org.freedesktop.NetworkManager.SecretAgent.Error.NoSecrets", nil)
@@ -648,7 +658,7 @@ func fieldsNeeded(setting string, hints []string, conn map[string]nmVariantMap)
 			return hints
 		}
 		return infer8021xFields(conn)
-	case "vpn", "wireguard":
+	case "vpn", "wireguard", "gsm":
 		return hints
 	default:
 		return []string{}
@@ -712,6 +722,8 @@ func buildFieldsInfo(setting string, fields []string, vpnService string) []Field
 			}
 		case "vpn":
 			info.Label, info.IsSecret = vpnFieldMeta(f, vpnService)
+		case "gsm":
+			info.Label, info.IsSecret = gsmFieldMeta(f)
 		default:
 			info.Label = f
 			info.IsSecret = true
@@ -721,6 +733,21 @@ func buildFieldsInfo(setting string, fields []string, vpnService string) []Field
 	return result
 }
 
+func gsmFieldMeta(field string) (string, bool) {
+	switch field {
+	case "pin":
+		return "SIM PIN", true
+	case "puk":
+		return "SIM PUK", true
+	case "username":
+		return "Username", false
+	case "password":
+		return "Password", true
+	default:
+		return field, true
+	}
+}
+
 func inferVPNFields(conn map[string]nmVariantMap, vpnService string) []string {
 	fields := []string{"password"}
 
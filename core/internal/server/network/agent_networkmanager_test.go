@@ -219,6 +219,16 @@ func TestVpnFieldMeta_StandardFields(t *testing.T) {
 	}
 }
 
+func TestFieldsNeeded_GSM(t *testing.T) {
+	assert.Equal(t, []string{"pin"}, fieldsNeeded("gsm", []string{"pin"}, nil))
+	assert.Empty(t, fieldsNeeded("gsm", nil, nil))
+
+	assert.Equal(t, []FieldInfo{
+		{Name: "pin", Label: "SIM PIN", IsSecret: true},
+		{Name: "password", Label: "Password", IsSecret: true},
+	}, buildFieldsInfo("gsm", []string{"pin", "password"}, ""))
+}
+
 func TestInferVPNFields_GPSaml(t *testing.T) {
 	tests := []struct {
 		name        string
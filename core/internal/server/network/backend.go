@@ -19,6 +19,10 @@ type Backend interface {
 	ForgetWiFiNetwork(ssid string) error
 	SetWiFiAutoconnect(ssid string, autoconnect bool) error
 
+	StartHotspot(cfg HotspotConfig) (*HotspotConfig, error)
+	StopHotspot() error
+	GetHotspotConfig() (*HotspotConfig, error)
+
 	GetEthernetDevices() []EthernetDevice
 	GetWiredConnections() ([]WiredConnection, error)
 	GetWiredNetworkDetails(uuid string) (*WiredNetworkInfoResponse, error)
@@ -39,8 +43,23 @@ type Backend interface {
 	UpdateVPNConfig(uuid string, updates map[string]any) error
 	SetVPNCredentials(uuid string, username string, password string, save bool) error
 	DeleteVPN(uuidOrName string) error
+	CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error)
+	ExportWireGuard(uuidOrName string) (*WireGuardConfig, error)
+
+	GetWWANModems() ([]WWANModem, error)
+	SetWWANEnabled(enabled bool) error
+	UnlockWWAN(modem, pin, puk string) error
+	ListWWANProfiles() ([]WWANProfile, error)
+	SaveWWANProfile(profile WWANProfile) (*WWANProfile, error)
+	DeleteWWANProfile(uuid string) error
+	ConnectWWAN(uuid string) error
+	DisconnectWWAN(uuid string) error
+
+	GetConnectionSettings(uuid string) (*ConnectionConfig, error)
+	UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error
 
 	GetCurrentState() (*BackendState, error)
+	GetInterfaceConnections() map[string]InterfaceConnection
 
 	StartMonitoring(onStateChange func()) error
 	StopMonitoring()
@@ -71,6 +90,8 @@ type BackendState struct {
 	WiredConnections       []WiredConnection
 	VPNProfiles            []VPNProfile
 	VPNActive              []VPNActive
+	WWANEnabled            bool
+	WWANModems             []WWANModem
 	IsConnecting           bool
 	ConnectingSSID         string
 	ConnectingDevice       string
@@ -115,6 +115,35 @@ func (b *HybridIwdNetworkdBackend) GetWiFiQRCodeContent(ssid string) (string, er
 	return b.wifi.GetWiFiQRCodeContent(ssid)
 }
 
+func (b *HybridIwdNetworkdBackend) StartHotspot(cfg HotspotConfig) (*HotspotConfig, error) {
+	return b.wifi.StartHotspot(cfg)
+}
+
+func (b *HybridIwdNetworkdBackend) StopHotspot() error {
+	if err := b.wifi.StopHotspot(); err != nil {
+		return err
+	}
+
+	ws, err := b.wifi.GetCurrentState()
+	if err == nil && ws.WiFiDevice != "" {
+		b.l3.EnsureDhcpUp(ws.WiFiDevice) //nolint:errcheck
+	}
+
+	return nil
+}
+
+func (b *HybridIwdNetworkdBackend) GetHotspotConfig() (*HotspotConfig, error) {
+	return b.wifi.GetHotspotConfig()
+}
+
+func (b *HybridIwdNetworkdBackend) GetConnectionSettings(uuid string) (*ConnectionConfig, error) {
+	return b.l3.GetConnectionSettings(uuid)
+}
+
+func (b *HybridIwdNetworkdBackend) UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error {
+	return b.l3.UpdateConnectionSettings(uuid, cfg, reactivate)
+}
+
 func (b *HybridIwdNetworkdBackend) ConnectWiFi(req ConnectionRequest) error {
 	if err := b.wifi.ConnectWiFi(req); err != nil {
 		return err
@@ -208,6 +237,46 @@ func (b *HybridIwdNetworkdBackend) DeleteVPN(uuidOrName string) error {
 	return fmt.Errorf("VPN not supported in hybrid mode")
 }
 
+func (b *HybridIwdNetworkdBackend) CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) ExportWireGuard(uuidOrName string) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) GetWWANModems() ([]WWANModem, error) {
+	return []WWANModem{}, nil
+}
+
+func (b *HybridIwdNetworkdBackend) SetWWANEnabled(enabled bool) error {
+	return fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) UnlockWWAN(modem, pin, puk string) error {
+	return fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) ListWWANProfiles() ([]WWANProfile, error) {
+	return []WWANProfile{}, nil
+}
+
+func (b *HybridIwdNetworkdBackend) SaveWWANProfile(profile WWANProfile) (*WWANProfile, error) {
+	return nil, fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) DeleteWWANProfile(uuid string) error {
+	return fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) ConnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
+func (b *HybridIwdNetworkdBackend) DisconnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported in hybrid mode")
+}
+
 func (b *HybridIwdNetworkdBackend) GetPromptBroker() PromptBroker {
 	return b.wifi.GetPromptBroker()
 }
@@ -240,6 +309,10 @@ func (b *HybridIwdNetworkdBackend) GetWiFiDevices() []WiFiDevice {
 	return b.wifi.GetWiFiDevices()
 }
 
+func (b *HybridIwdNetworkdBackend) GetInterfaceConnections() map[string]InterfaceConnection {
+	return b.wifi.GetInterfaceConnections()
+}
+
 func (b *HybridIwdNetworkdBackend) SetVPNCredentials(uuid, username, password string, save bool) error {
 	return fmt.Errorf("VPN not supported in hybrid mode")
 }
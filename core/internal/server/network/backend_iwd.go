@@ -53,6 +53,9 @@ type IWDBackend struct {
 	attemptMutex  sync.RWMutex
 	recentScans   map[string]time.Time
 	recentScansMu sync.Mutex
+
+	hotspot   *HotspotConfig
+	hotspotMu sync.Mutex
 }
 
 func NewIWDBackend() (*IWDBackend, error) {
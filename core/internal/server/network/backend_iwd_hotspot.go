@@ -0,0 +1,157 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	iwdAccessPointInterface = "net.connman.iwd.AccessPoint"
+	iwdAPProfileDir         = "/var/lib/iwd/ap"
+)
+
+// iwd has no band setting, only a channel in the AP profile.
+var iwdBandChannels = map[string]int{
+	HotspotBand2GHz: 6,
+	HotspotBand5GHz: 36,
+}
+
+func (b *IWDBackend) StartHotspot(cfg HotspotConfig) (*HotspotConfig, error) {
+	cfg, err := prepareHotspotConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if b.devicePath == "" {
+		return nil, fmt.Errorf("no WiFi device available")
+	}
+
+	b.stateMutex.RLock()
+	device := b.state.WiFiDevice
+	b.stateMutex.RUnlock()
+	if cfg.Device != "" && cfg.Device != device {
+		return nil, fmt.Errorf("WiFi device not found: %s", cfg.Device)
+	}
+	cfg.Device = device
+
+	// Start() always picks the channel itself, so a band needs a profile.
+	if cfg.Band != HotspotBandAuto {
+		if err := writeIWDAPProfile(iwdAPProfileDir, cfg); err != nil {
+			return nil, fmt.Errorf("band selection requires an iwd AP profile: %w", err)
+		}
+	}
+
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	call := obj.Call(dbusPropertiesInterface+".Set", 0, iwdDeviceInterface, "Mode", dbus.MakeVariant("ap"))
+	if call.Err != nil {
+		return nil, fmt.Errorf("failed to switch %s to access point mode: %w", device, call.Err)
+	}
+
+	if cfg.Band != HotspotBandAuto {
+		call = obj.Call(iwdAccessPointInterface+".StartProfile", 0, cfg.SSID)
+	} else {
+		call = obj.Call(iwdAccessPointInterface+".Start", 0, cfg.SSID, cfg.Password)
+	}
+	if call.Err != nil {
+		if err := b.restoreStationMode(); err != nil {
+			log.Warnf("[StartHotspot] %v", err)
+		}
+		return nil, fmt.Errorf("failed to start hotspot: %w", call.Err)
+	}
+
+	saved := cfg
+	b.hotspotMu.Lock()
+	b.hotspot = &saved
+	b.hotspotMu.Unlock()
+	log.Infof("[StartHotspot] Hotspot %q started on %s", cfg.SSID, device)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	cfg.Active = true
+	return &cfg, nil
+}
+
+func (b *IWDBackend) StopHotspot() error {
+	if b.devicePath == "" {
+		return fmt.Errorf("no WiFi device available")
+	}
+
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	if call := obj.Call(iwdAccessPointInterface+".Stop", 0); call.Err != nil {
+		return fmt.Errorf("failed to stop hotspot: %w", call.Err)
+	}
+	if err := b.restoreStationMode(); err != nil {
+		return err
+	}
+	log.Infof("[StopHotspot] Hotspot stopped")
+
+	b.updateState()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *IWDBackend) GetHotspotConfig() (*HotspotConfig, error) {
+	cfg := HotspotConfig{SSID: hotspotDefaultSSID}
+	if saved := b.hotspotConfig(); saved != nil {
+		cfg = *saved
+	}
+	if b.devicePath == "" {
+		return &cfg, nil
+	}
+
+	// The AccessPoint interface only exists while the device is in ap mode.
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	if v, err := obj.GetProperty(iwdAccessPointInterface + ".Started"); err == nil {
+		cfg.Active, _ = v.Value().(bool)
+	}
+	if cfg.Active {
+		if v, err := obj.GetProperty(iwdAccessPointInterface + ".Name"); err == nil {
+			if name, ok := v.Value().(string); ok && name != cfg.SSID {
+				cfg = HotspotConfig{SSID: name, Active: true}
+			}
+		}
+	}
+
+	return &cfg, nil
+}
+
+func (b *IWDBackend) hotspotConfig() *HotspotConfig {
+	b.hotspotMu.Lock()
+	defer b.hotspotMu.Unlock()
+	if b.hotspot == nil {
+		return nil
+	}
+	cfg := *b.hotspot
+	return &cfg
+}
+
+func (b *IWDBackend) restoreStationMode() error {
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	call := obj.Call(dbusPropertiesInterface+".Set", 0, iwdDeviceInterface, "Mode", dbus.MakeVariant("station"))
+	if call.Err != nil {
+		return fmt.Errorf("failed to switch back to station mode: %w", call.Err)
+	}
+	return nil
+}
+
+func writeIWDAPProfile(dir string, cfg HotspotConfig) error {
+	if strings.ContainsAny(cfg.SSID, "/\x00") || strings.HasPrefix(cfg.SSID, ".") {
+		return fmt.Errorf("SSID %q cannot be used as a profile name", cfg.SSID)
+	}
+
+	content := fmt.Sprintf("[Security]\nPassphrase=%s\n\n[General]\nChannel=%d\n", cfg.Password, iwdBandChannels[cfg.Band])
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, cfg.SSID+".ap"), []byte(content), 0o600)
+}
@@ -81,6 +81,54 @@ func (b *IWDBackend) SetVPNCredentials(uuid, username, password string, save boo
 	return fmt.Errorf("VPN not supported by iwd backend")
 }
 
+func (b *IWDBackend) CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported by iwd backend")
+}
+
+func (b *IWDBackend) ExportWireGuard(uuidOrName string) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported by iwd backend")
+}
+
+func (b *IWDBackend) GetWWANModems() ([]WWANModem, error) {
+	return nil, fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetWWANEnabled(enabled bool) error {
+	return fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) UnlockWWAN(modem, pin, puk string) error {
+	return fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) ListWWANProfiles() ([]WWANProfile, error) {
+	return nil, fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) SaveWWANProfile(profile WWANProfile) (*WWANProfile, error) {
+	return nil, fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) DeleteWWANProfile(uuid string) error {
+	return fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) ConnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) DisconnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported by iwd backend")
+}
+
+func (b *IWDBackend) GetConnectionSettings(uuid string) (*ConnectionConfig, error) {
+	return nil, fmt.Errorf("connection settings not supported by iwd backend")
+}
+
+func (b *IWDBackend) UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error {
+	return fmt.Errorf("connection settings not supported by iwd backend")
+}
+
 func (b *IWDBackend) ScanWiFiDevice(device string) error {
 	return b.ScanWiFi()
 }
@@ -95,6 +143,17 @@ func (b *IWDBackend) GetWiFiDevices() []WiFiDevice {
 	return b.getWiFiDevicesLocked()
 }
 
+func (b *IWDBackend) GetInterfaceConnections() map[string]InterfaceConnection {
+	b.stateMutex.RLock()
+	defer b.stateMutex.RUnlock()
+
+	links := make(map[string]InterfaceConnection)
+	if b.state.WiFiConnected && b.state.WiFiDevice != "" {
+		links[b.state.WiFiDevice] = InterfaceConnection{Name: b.state.WiFiSSID, Type: "802-11-wireless"}
+	}
+	return links
+}
+
 func (b *IWDBackend) getWiFiDevicesLocked() []WiFiDevice {
 	if b.state.WiFiDevice == "" {
 		return nil
@@ -117,6 +176,10 @@ func (b *IWDBackend) getWiFiDevicesLocked() []WiFiDevice {
 }
 
 func (b *IWDBackend) GetWiFiQRCodeContent(ssid string) (string, error) {
+	if hotspot := b.hotspotConfig(); hotspot != nil && hotspot.SSID == ssid {
+		return FormatWiFiQRString("WPA", ssid, hotspot.Password), nil
+	}
+
 	path := iwdConfigPath(ssid)
 
 	data, err := os.ReadFile(path)
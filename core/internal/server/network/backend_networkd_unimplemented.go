@@ -22,6 +22,26 @@ func (b *SystemdNetworkdBackend) GetWiFiQRCodeContent(ssid string) (string, erro
 	return "", fmt.Errorf("WiFi QR Code not supported by networkd backend")
 }
 
+func (b *SystemdNetworkdBackend) StartHotspot(cfg HotspotConfig) (*HotspotConfig, error) {
+	return nil, fmt.Errorf("WiFi hotspot not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) StopHotspot() error {
+	return fmt.Errorf("WiFi hotspot not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) GetHotspotConfig() (*HotspotConfig, error) {
+	return nil, fmt.Errorf("WiFi hotspot not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) GetConnectionSettings(uuid string) (*ConnectionConfig, error) {
+	return nil, fmt.Errorf("connection settings not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error {
+	return fmt.Errorf("connection settings not supported by networkd backend")
+}
+
 func (b *SystemdNetworkdBackend) ConnectWiFi(req ConnectionRequest) error {
 	return fmt.Errorf("WiFi connect not supported by networkd backend")
 }
@@ -82,6 +102,46 @@ func (b *SystemdNetworkdBackend) SetVPNCredentials(uuid, username, password stri
 	return fmt.Errorf("VPN not supported by networkd backend")
 }
 
+func (b *SystemdNetworkdBackend) CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) ExportWireGuard(uuidOrName string) (*WireGuardConfig, error) {
+	return nil, fmt.Errorf("VPN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) GetWWANModems() ([]WWANModem, error) {
+	return []WWANModem{}, nil
+}
+
+func (b *SystemdNetworkdBackend) SetWWANEnabled(enabled bool) error {
+	return fmt.Errorf("WWAN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) UnlockWWAN(modem, pin, puk string) error {
+	return fmt.Errorf("WWAN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) ListWWANProfiles() ([]WWANProfile, error) {
+	return []WWANProfile{}, nil
+}
+
+func (b *SystemdNetworkdBackend) SaveWWANProfile(profile WWANProfile) (*WWANProfile, error) {
+	return nil, fmt.Errorf("WWAN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) DeleteWWANProfile(uuid string) error {
+	return fmt.Errorf("WWAN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) ConnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) DisconnectWWAN(uuid string) error {
+	return fmt.Errorf("WWAN not supported by networkd backend")
+}
+
 func (b *SystemdNetworkdBackend) SetWiFiAutoconnect(ssid string, autoconnect bool) error {
 	return fmt.Errorf("WiFi autoconnect not supported by networkd backend")
 }
@@ -97,3 +157,8 @@ func (b *SystemdNetworkdBackend) DisconnectWiFiDevice(device string) error {
 func (b *SystemdNetworkdBackend) GetWiFiDevices() []WiFiDevice {
 	return nil
 }
+
+// networkd has no connection profiles, so usage is tracked per interface.
+func (b *SystemdNetworkdBackend) GetInterfaceConnections() map[string]InterfaceConnection {
+	return nil
+}
@@ -62,6 +62,8 @@ type NetworkManagerBackend struct {
 	secretAgent  *SecretAgent
 	promptBroker PromptBroker
 
+	mm *modemManager
+
 	state      *BackendState
 	stateMutex sync.RWMutex
 
@@ -243,6 +245,13 @@ func (b *NetworkManagerBackend) Initialize() error {
 		log.Warnf("Failed to get initial active VPNs: %v", err)
 	}
 
+	if mm, err := newModemManager(); err != nil {
+		log.Warnf("ModemManager unavailable, WWAN disabled: %v", err)
+	} else {
+		b.mm = mm
+	}
+	b.updateWWANState()
+
 	return nil
 }
 
@@ -253,6 +262,10 @@ func (b *NetworkManagerBackend) Close() {
 	if b.secretAgent != nil {
 		b.secretAgent.Close()
 	}
+
+	if b.mm != nil {
+		b.mm.Close()
+	}
 }
 
 func (b *NetworkManagerBackend) GetCurrentState() (*BackendState, error) {
@@ -266,6 +279,7 @@ func (b *NetworkManagerBackend) GetCurrentState() (*BackendState, error) {
 	state.EthernetDevices = append([]EthernetDevice(nil), b.state.EthernetDevices...)
 	state.VPNProfiles = append([]VPNProfile(nil), b.state.VPNProfiles...)
 	state.VPNActive = append([]VPNActive(nil), b.state.VPNActive...)
+	state.WWANModems = append([]WWANModem(nil), b.state.WWANModems...)
 
 	return &state, nil
 }
@@ -0,0 +1,214 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// NM_WIFI_DEVICE_CAP_AP
+const nmWiFiDeviceCapAP = uint32(0x80)
+
+func (b *NetworkManagerBackend) StartHotspot(cfg HotspotConfig) (*HotspotConfig, error) {
+	cfg, err := prepareHotspotConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	devInfo, err := b.getWifiDeviceForConnection(cfg.Device)
+	if err != nil {
+		return nil, err
+	}
+	if caps, err := devInfo.wireless.GetPropertyWirelessCapabilities(); err == nil && caps&nmWiFiDeviceCapAP == 0 {
+		return nil, fmt.Errorf("WiFi device %s does not support access point mode", devInfo.name)
+	}
+	cfg.Device = devInfo.name
+
+	wifiSettings := map[string]any{
+		"ssid": []byte(cfg.SSID),
+		"mode": "ap",
+	}
+	if cfg.Band != HotspotBandAuto {
+		wifiSettings["band"] = cfg.Band
+	}
+	settings := map[string]map[string]any{
+		"connection": {
+			"id":             hotspotConnectionID,
+			"type":           "802-11-wireless",
+			"autoconnect":    false,
+			"interface-name": cfg.Device,
+		},
+		"802-11-wireless": wifiSettings,
+		"802-11-wireless-security": {
+			"key-mgmt":  "wpa-psk",
+			"psk":       cfg.Password,
+			"psk-flags": uint32(0),
+			"proto":     []string{"rsn"},
+			"pairwise":  []string{"ccmp"},
+			"group":     []string{"ccmp"},
+		},
+		"ipv4": {"method": "shared"},
+		"ipv6": {"method": "ignore"},
+	}
+
+	conn, err := b.findHotspotConnection()
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil {
+		existing, err := conn.GetSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hotspot settings: %w", err)
+		}
+		if uuid, ok := existing["connection"]["uuid"].(string); ok {
+			settings["connection"]["uuid"] = uuid
+		}
+		if err := conn.Update(settings); err != nil {
+			return nil, fmt.Errorf("failed to update hotspot: %w", err)
+		}
+	} else {
+		settingsMgr := b.settings.(gonetworkmanager.Settings)
+		if conn, err = settingsMgr.AddConnection(settings); err != nil {
+			return nil, fmt.Errorf("failed to add hotspot: %w", err)
+		}
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if _, err := nm.ActivateConnection(conn, devInfo.device, nil); err != nil {
+		return nil, fmt.Errorf("failed to activate hotspot: %w", err)
+	}
+	log.Infof("[StartHotspot] Hotspot %q starting on %s", cfg.SSID, cfg.Device)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return &cfg, nil
+}
+
+func (b *NetworkManagerBackend) StopHotspot() error {
+	conn, err := b.findHotspotConnection()
+	if err != nil {
+		return err
+	}
+	if conn == nil {
+		return fmt.Errorf("no hotspot configured")
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get hotspot settings: %w", err)
+	}
+	uuid, _ := settings["connection"]["uuid"].(string)
+
+	active, err := b.findActiveConnection(uuid)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		return fmt.Errorf("hotspot is not active")
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if err := nm.DeactivateConnection(active); err != nil {
+		return fmt.Errorf("failed to stop hotspot: %w", err)
+	}
+	log.Infof("[StopHotspot] Hotspot stopped")
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) GetHotspotConfig() (*HotspotConfig, error) {
+	conn, err := b.findHotspotConnection()
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return &HotspotConfig{SSID: hotspotDefaultSSID}, nil
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotspot settings: %w", err)
+	}
+	cfg := hotspotConfigFromSettings(settings)
+
+	secrets, err := conn.GetSecrets("802-11-wireless-security")
+	if err != nil {
+		log.Debugf("[GetHotspotConfig] conn.GetSecrets failed: %v", err)
+	} else if psk, ok := secrets["802-11-wireless-security"]["psk"].(string); ok {
+		cfg.Password = psk
+	}
+
+	uuid, _ := settings["connection"]["uuid"].(string)
+	if active, err := b.findActiveConnection(uuid); err == nil && active != nil {
+		cfg.Active = true
+	}
+
+	return &cfg, nil
+}
+
+func hotspotConfigFromSettings(settings gonetworkmanager.ConnectionSettings) HotspotConfig {
+	var cfg HotspotConfig
+	if ssid, ok := settings["802-11-wireless"]["ssid"].([]byte); ok {
+		cfg.SSID = string(ssid)
+	}
+	cfg.Band, _ = settings["802-11-wireless"]["band"].(string)
+	cfg.Device, _ = settings["connection"]["interface-name"].(string)
+	return cfg
+}
+
+// findHotspotConnection returns the saved hotspot profile, or nil if none
+// has been created yet.
+func (b *NetworkManagerBackend) findHotspotConnection() (gonetworkmanager.Connection, error) {
+	s := b.settings
+	if s == nil {
+		var err error
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		id, _ := settings["connection"]["id"].(string)
+		mode, _ := settings["802-11-wireless"]["mode"].(string)
+		if id == hotspotConnectionID && mode == "ap" {
+			return conn, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (b *NetworkManagerBackend) findActiveConnection(uuid string) (gonetworkmanager.ActiveConnection, error) {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	activeConns, err := nm.GetPropertyActiveConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active connections: %w", err)
+	}
+
+	for _, activeConn := range activeConns {
+		if activeUUID, _ := activeConn.GetPropertyUUID(); activeUUID == uuid {
+			return activeConn, nil
+		}
+	}
+
+	return nil, nil
+}
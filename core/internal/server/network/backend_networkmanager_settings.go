@@ -0,0 +1,487 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// NetworkManager has no manual proxy method, only PAC. A manual proxy is
+// stored as a generated PAC script whose first line carries the original
+// values so it can be read back.
+const manualProxyMarker = "// dms-manual-proxy "
+
+func (b *NetworkManagerBackend) GetConnectionSettings(uuid string) (*ConnectionConfig, error) {
+	conn, err := b.connectionByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	return connectionConfigFromSettings(settings), nil
+}
+
+func (b *NetworkManagerBackend) UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error {
+	conn, err := b.connectionByUUID(uuid)
+	if err != nil {
+		return err
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	if err := applyConnectionConfig(settings, &cfg); err != nil {
+		return err
+	}
+
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[UpdateConnectionSettings] Updated connection %s", uuid)
+
+	if reactivate {
+		if err := b.reactivateConnection(conn, uuid); err != nil {
+			return err
+		}
+	}
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+// reactivateConnection re-activates conn on the device it's active on so
+// updated settings take effect. Inactive connections are left alone.
+func (b *NetworkManagerBackend) reactivateConnection(conn gonetworkmanager.Connection, uuid string) error {
+	active, err := b.findActiveConnection(uuid)
+	if err != nil || active == nil {
+		return err
+	}
+
+	var dev gonetworkmanager.Device
+	if devices, err := active.GetPropertyDevices(); err == nil && len(devices) > 0 {
+		dev = devices[0]
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+		return fmt.Errorf("failed to reactivate connection: %w", err)
+	}
+	return nil
+}
+
+func (b *NetworkManagerBackend) connectionByUUID(uuid string) (gonetworkmanager.Connection, error) {
+	s := b.settings
+	if s == nil {
+		var err error
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	conn, err := settingsMgr.GetConnectionByUUID(uuid)
+	if err != nil || conn == nil {
+		return nil, fmt.Errorf("connection not found: %s", uuid)
+	}
+	return conn, nil
+}
+
+func connectionConfigFromSettings(settings gonetworkmanager.ConnectionSettings) *ConnectionConfig {
+	meta := settings["connection"]
+	cfg := &ConnectionConfig{
+		Autoconnect: true,
+		Metered:     "auto",
+		IPv4:        ipSettingsFromSection(settings["ipv4"], false),
+		IPv6:        ipSettingsFromSection(settings["ipv6"], true),
+		Proxy:       proxySettingsFromSection(settings["proxy"]),
+	}
+	cfg.UUID, _ = meta["uuid"].(string)
+	cfg.Name, _ = meta["id"].(string)
+	cfg.Type, _ = meta["type"].(string)
+	if autoconnect, ok := meta["autoconnect"].(bool); ok {
+		cfg.Autoconnect = autoconnect
+	}
+	if metered, ok := meta["metered"].(int32); ok {
+		switch gonetworkmanager.NmMetered(metered) {
+		case gonetworkmanager.NmMeteredYes, gonetworkmanager.NmMeteredGuessYes:
+			cfg.Metered = "yes"
+		case gonetworkmanager.NmMeteredNo, gonetworkmanager.NmMeteredGuessNo:
+			cfg.Metered = "no"
+		}
+	}
+	if section := macSection(cfg.Type); section != "" {
+		cfg.MACAddress = "default"
+		if mac, ok := settings[section]["assigned-mac-address"].(string); ok && mac != "" {
+			cfg.MACAddress = mac
+		}
+	}
+	return cfg
+}
+
+func ipSettingsFromSection(section map[string]any, v6 bool) IPSettings {
+	ip := IPSettings{
+		Method:    "auto",
+		Addresses: []string{},
+		DNS:       []string{},
+		DNSSearch: []string{},
+		Routes:    []RouteSettings{},
+	}
+	if section == nil {
+		return ip
+	}
+
+	if method, ok := section["method"].(string); ok && method != "" {
+		ip.Method = method
+	}
+	if addresses, ok := section["address-data"].([]map[string]any); ok {
+		for _, addr := range addresses {
+			address, _ := addr["address"].(string)
+			prefix, _ := addr["prefix"].(uint32)
+			ip.Addresses = append(ip.Addresses, fmt.Sprintf("%s/%d", address, prefix))
+		}
+	}
+	ip.Gateway, _ = section["gateway"].(string)
+
+	switch dns := section["dns"].(type) {
+	case []uint32:
+		for _, v := range dns {
+			b := make([]byte, 4)
+			binary.NativeEndian.PutUint32(b, v)
+			ip.DNS = append(ip.DNS, net.IP(b).String())
+		}
+	case [][]byte:
+		for _, v := range dns {
+			ip.DNS = append(ip.DNS, net.IP(v).String())
+		}
+	}
+	if dnsData, ok := section["dns-data"].([]string); ok && len(dnsData) > 0 {
+		ip.DNS = dnsData
+	}
+	if search, ok := section["dns-search"].([]string); ok {
+		ip.DNSSearch = search
+	}
+	ip.IgnoreAutoDNS, _ = section["ignore-auto-dns"].(bool)
+
+	if routes, ok := section["route-data"].([]map[string]any); ok {
+		for _, r := range routes {
+			dest, _ := r["dest"].(string)
+			prefix, _ := r["prefix"].(uint32)
+			route := RouteSettings{Dest: fmt.Sprintf("%s/%d", dest, prefix)}
+			route.NextHop, _ = r["next-hop"].(string)
+			route.Metric, _ = r["metric"].(uint32)
+			ip.Routes = append(ip.Routes, route)
+		}
+	}
+
+	return ip
+}
+
+func proxySettingsFromSection(section map[string]any) ProxySettings {
+	proxy := ProxySettings{Method: "none"}
+	if section == nil {
+		return proxy
+	}
+
+	proxy.BrowserOnly, _ = section["browser-only"].(bool)
+	if method, _ := section["method"].(int32); method != 1 {
+		return proxy
+	}
+
+	proxy.Method = "pac"
+	proxy.PACURL, _ = section["pac-url"].(string)
+	proxy.PACScript, _ = section["pac-script"].(string)
+	if host, port, bypass, ok := parseManualProxyScript(proxy.PACScript); ok {
+		proxy = ProxySettings{Method: "manual", Host: host, Port: port, Bypass: bypass, BrowserOnly: proxy.BrowserOnly}
+	}
+	return proxy
+}
+
+func macSection(connType string) string {
+	switch connType {
+	case "802-11-wireless":
+		return "802-11-wireless"
+	case "802-3-ethernet":
+		return "802-3-ethernet"
+	}
+	return ""
+}
+
+func applyConnectionConfig(settings gonetworkmanager.ConnectionSettings, cfg *ConnectionConfig) error {
+	meta, ok := settings["connection"]
+	if !ok {
+		return fmt.Errorf("connection metadata not found")
+	}
+	connType, _ := meta["type"].(string)
+
+	if cfg.Name != "" {
+		meta["id"] = cfg.Name
+	}
+	meta["autoconnect"] = cfg.Autoconnect
+
+	switch cfg.Metered {
+	case "", "auto":
+		meta["metered"] = int32(gonetworkmanager.NmMeteredUnknown)
+	case "yes":
+		meta["metered"] = int32(gonetworkmanager.NmMeteredYes)
+	case "no":
+		meta["metered"] = int32(gonetworkmanager.NmMeteredNo)
+	default:
+		return fmt.Errorf("invalid metered value %q (auto, yes, no)", cfg.Metered)
+	}
+
+	if err := applyMACAddress(settings, connType, cfg.MACAddress); err != nil {
+		return err
+	}
+
+	for _, family := range []struct {
+		name string
+		ip   IPSettings
+		v6   bool
+	}{{"ipv4", cfg.IPv4, false}, {"ipv6", cfg.IPv6, true}} {
+		section, ok := settings[family.name]
+		if !ok {
+			section = make(map[string]any)
+			settings[family.name] = section
+		}
+		if err := applyIPSettings(section, family.ip, family.v6); err != nil {
+			return fmt.Errorf("%s: %w", family.name, err)
+		}
+	}
+
+	section, ok := settings["proxy"]
+	if !ok {
+		section = make(map[string]any)
+		settings["proxy"] = section
+	}
+	return applyProxySettings(section, cfg.Proxy)
+}
+
+func applyMACAddress(settings gonetworkmanager.ConnectionSettings, connType, mac string) error {
+	sectionName := macSection(connType)
+	if sectionName == "" {
+		if mac != "" && mac != "default" {
+			return fmt.Errorf("MAC address settings only apply to WiFi and ethernet connections")
+		}
+		return nil
+	}
+
+	section, ok := settings[sectionName]
+	if !ok {
+		section = make(map[string]any)
+		settings[sectionName] = section
+	}
+	delete(section, "cloned-mac-address")
+
+	switch mac {
+	case "", "default":
+		delete(section, "assigned-mac-address")
+	case "permanent", "preserve", "random", "stable":
+		section["assigned-mac-address"] = mac
+	default:
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			return fmt.Errorf("invalid MAC address %q", mac)
+		}
+		section["assigned-mac-address"] = strings.ToUpper(hw.String())
+	}
+	return nil
+}
+
+func applyIPSettings(section map[string]any, ip IPSettings, v6 bool) error {
+	methods := []string{"auto", "manual", "link-local", "shared", "disabled"}
+	if v6 {
+		methods = append(methods, "dhcp", "ignore")
+	}
+	switch {
+	case ip.Method == "":
+	case !slices.Contains(methods, ip.Method):
+		return fmt.Errorf("invalid method %q", ip.Method)
+	default:
+		section["method"] = ip.Method
+	}
+
+	addresses := make([]map[string]any, 0, len(ip.Addresses))
+	for _, cidr := range ip.Addresses {
+		addr, prefix, err := parseCIDRFamily(cidr, v6)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, map[string]any{"address": addr, "prefix": prefix})
+	}
+	if section["method"] == "manual" && len(addresses) == 0 {
+		return fmt.Errorf("manual method requires at least one address")
+	}
+	section["address-data"] = addresses
+	delete(section, "addresses")
+
+	if ip.Gateway == "" {
+		delete(section, "gateway")
+	} else {
+		gw := net.ParseIP(ip.Gateway)
+		if gw == nil || (gw.To4() == nil) != v6 {
+			return fmt.Errorf("invalid gateway %q", ip.Gateway)
+		}
+		section["gateway"] = gw.String()
+	}
+
+	if v6 {
+		dns := make([][]byte, 0, len(ip.DNS))
+		for _, s := range ip.DNS {
+			addr := net.ParseIP(s)
+			if addr == nil || addr.To4() != nil {
+				return fmt.Errorf("invalid DNS server %q", s)
+			}
+			dns = append(dns, addr.To16())
+		}
+		section["dns"] = dns
+	} else {
+		dns := make([]uint32, 0, len(ip.DNS))
+		for _, s := range ip.DNS {
+			addr := net.ParseIP(s).To4()
+			if addr == nil {
+				return fmt.Errorf("invalid DNS server %q", s)
+			}
+			dns = append(dns, binary.NativeEndian.Uint32(addr))
+		}
+		section["dns"] = dns
+	}
+	delete(section, "dns-data")
+
+	search := make([]string, 0, len(ip.DNSSearch))
+	for _, domain := range ip.DNSSearch {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			search = append(search, domain)
+		}
+	}
+	section["dns-search"] = search
+	section["ignore-auto-dns"] = ip.IgnoreAutoDNS
+
+	routes := make([]map[string]any, 0, len(ip.Routes))
+	for _, r := range ip.Routes {
+		dest, prefix, err := parseCIDRFamily(r.Dest, v6)
+		if err != nil {
+			return err
+		}
+		route := map[string]any{"dest": dest, "prefix": prefix}
+		if r.NextHop != "" {
+			hop := net.ParseIP(r.NextHop)
+			if hop == nil || (hop.To4() == nil) != v6 {
+				return fmt.Errorf("invalid next hop %q", r.NextHop)
+			}
+			route["next-hop"] = hop.String()
+		}
+		if r.Metric > 0 {
+			route["metric"] = r.Metric
+		}
+		routes = append(routes, route)
+	}
+	section["route-data"] = routes
+	delete(section, "routes")
+
+	return nil
+}
+
+func parseCIDRFamily(cidr string, v6 bool) (string, uint32, error) {
+	addr, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil || (addr.To4() == nil) != v6 {
+		return "", 0, fmt.Errorf("invalid address %q", cidr)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	return addr.String(), uint32(prefix), nil
+}
+
+func applyProxySettings(section map[string]any, proxy ProxySettings) error {
+	section["browser-only"] = proxy.BrowserOnly
+
+	switch proxy.Method {
+	case "", "none":
+		section["method"] = int32(0)
+		delete(section, "pac-url")
+		delete(section, "pac-script")
+	case "pac":
+		if proxy.PACURL == "" && proxy.PACScript == "" {
+			return fmt.Errorf("PAC proxy requires a URL or script")
+		}
+		section["method"] = int32(1)
+		section["pac-url"] = proxy.PACURL
+		section["pac-script"] = proxy.PACScript
+	case "manual":
+		script, err := manualProxyScript(proxy.Host, proxy.Port, proxy.Bypass)
+		if err != nil {
+			return err
+		}
+		section["method"] = int32(1)
+		section["pac-script"] = script
+		delete(section, "pac-url")
+	default:
+		return fmt.Errorf("invalid proxy method %q (none, manual, pac)", proxy.Method)
+	}
+	return nil
+}
+
+func manualProxyScript(host string, port int, bypass []string) (string, error) {
+	switch {
+	case host == "" || strings.ContainsAny(host, "\"'\\;, \n"):
+		return "", fmt.Errorf("invalid proxy host %q", host)
+	case port < 1 || port > 65535:
+		return "", fmt.Errorf("invalid proxy port %d", port)
+	}
+	for _, pattern := range bypass {
+		if pattern == "" || strings.ContainsAny(pattern, "\"'\\;, \n") {
+			return "", fmt.Errorf("invalid bypass pattern %q", pattern)
+		}
+	}
+
+	hostPort := net.JoinHostPort(host, strconv.Itoa(port))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s bypass=%s\n", manualProxyMarker, hostPort, strings.Join(bypass, ","))
+	b.WriteString("function FindProxyForURL(url, host) {\n")
+	if len(bypass) > 0 {
+		conds := make([]string, 0, len(bypass))
+		for _, pattern := range bypass {
+			conds = append(conds, fmt.Sprintf("shExpMatch(host, %q)", pattern))
+		}
+		fmt.Fprintf(&b, "\tif (%s) {\n\t\treturn \"DIRECT\";\n\t}\n", strings.Join(conds, " || "))
+	}
+	fmt.Fprintf(&b, "\treturn \"PROXY %s\";\n}\n", hostPort)
+	return b.String(), nil
+}
+
+func parseManualProxyScript(script string) (host string, port int, bypass []string, ok bool) {
+	line, _, _ := strings.Cut(script, "\n")
+	rest, found := strings.CutPrefix(line, manualProxyMarker)
+	if !found {
+		return "", 0, nil, false
+	}
+
+	hostPort, bypassList, _ := strings.Cut(rest, " bypass=")
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", 0, nil, false
+	}
+	port, err = strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, nil, false
+	}
+	if bypassList != "" {
+		bypass = strings.Split(bypassList, ",")
+	}
+	return host, port, bypass, true
+}
@@ -0,0 +1,160 @@
+package network
+
+import (
+	"testing"
+
+	mock_gonetworkmanager "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/Wifx/gonetworkmanager/v2"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func wifiSettingsFixture() gonetworkmanager.ConnectionSettings {
+	return gonetworkmanager.ConnectionSettings{
+		"connection": {
+			"id":   "Office",
+			"uuid": "1111-2222",
+			"type": "802-11-wireless",
+		},
+		"802-11-wireless": {
+			"ssid":                 []byte("Office"),
+			"assigned-mac-address": "stable",
+		},
+		"ipv4": {
+			"method":       "manual",
+			"address-data": []map[string]any{{"address": "10.0.0.5", "prefix": uint32(24)}},
+			"addresses":    [][]uint32{{84017162, 24, 16842762}},
+			"gateway":      "10.0.0.1",
+			"dns":          []uint32{16843009},
+			"dns-search":   []string{"corp.example"},
+			"route-data":   []map[string]any{{"dest": "192.168.0.0", "prefix": uint32(16), "next-hop": "10.0.0.254", "metric": uint32(50)}},
+		},
+		"ipv6": {
+			"method": "auto",
+			"dns":    [][]byte{{0x26, 0x06, 0x47, 0x00, 0x47, 0x00, 0, 0, 0, 0, 0, 0, 0, 0, 0x11, 0x11}},
+		},
+		"proxy": {
+			"method":  int32(1),
+			"pac-url": "http://wpad.corp.example/wpad.dat",
+		},
+	}
+}
+
+func TestConnectionConfigFromSettings(t *testing.T) {
+	cfg := connectionConfigFromSettings(wifiSettingsFixture())
+
+	assert.Equal(t, "Office", cfg.Name)
+	assert.Equal(t, "802-11-wireless", cfg.Type)
+	assert.True(t, cfg.Autoconnect)
+	assert.Equal(t, "auto", cfg.Metered)
+	assert.Equal(t, "stable", cfg.MACAddress)
+
+	assert.Equal(t, "manual", cfg.IPv4.Method)
+	assert.Equal(t, []string{"10.0.0.5/24"}, cfg.IPv4.Addresses)
+	assert.Equal(t, "10.0.0.1", cfg.IPv4.Gateway)
+	assert.Equal(t, []string{"1.1.1.1"}, cfg.IPv4.DNS)
+	assert.Equal(t, []string{"corp.example"}, cfg.IPv4.DNSSearch)
+	assert.Equal(t, []RouteSettings{{Dest: "192.168.0.0/16", NextHop: "10.0.0.254", Metric: 50}}, cfg.IPv4.Routes)
+
+	assert.Equal(t, "auto", cfg.IPv6.Method)
+	assert.Equal(t, []string{"2606:4700:4700::1111"}, cfg.IPv6.DNS)
+	assert.Empty(t, cfg.IPv6.Addresses)
+
+	assert.Equal(t, ProxySettings{Method: "pac", PACURL: "http://wpad.corp.example/wpad.dat"}, cfg.Proxy)
+}
+
+func TestApplyConnectionConfig_RoundTrip(t *testing.T) {
+	settings := wifiSettingsFixture()
+	cfg := connectionConfigFromSettings(settings)
+
+	cfg.Metered = "yes"
+	cfg.MACAddress = "random"
+	cfg.IPv4.Addresses = []string{"10.0.0.6/24", "10.0.1.6/24"}
+	cfg.IPv4.DNS = []string{"9.9.9.9", "1.0.0.1"}
+	cfg.IPv4.Routes = []RouteSettings{}
+	cfg.IPv6.Method = "manual"
+	cfg.IPv6.Addresses = []string{"fd00::6/64"}
+	cfg.Proxy = ProxySettings{Method: "manual", Host: "proxy.corp.example", Port: 3128, Bypass: []string{"localhost", "*.corp.example"}}
+
+	require.NoError(t, applyConnectionConfig(settings, cfg))
+
+	assert.NotContains(t, settings["ipv4"], "addresses")
+	assert.Equal(t, int32(gonetworkmanager.NmMeteredYes), settings["connection"]["metered"])
+	assert.Equal(t, int32(1), settings["proxy"]["method"])
+	assert.Contains(t, settings["proxy"]["pac-script"], `return "PROXY proxy.corp.example:3128";`)
+	assert.Contains(t, settings["proxy"]["pac-script"], `shExpMatch(host, "*.corp.example")`)
+
+	got := connectionConfigFromSettings(settings)
+	assert.Equal(t, cfg, got)
+}
+
+func TestApplyConnectionConfig_Invalid(t *testing.T) {
+	for name, mutate := range map[string]func(*ConnectionConfig){
+		"metered":        func(c *ConnectionConfig) { c.Metered = "sometimes" },
+		"ipv4 method":    func(c *ConnectionConfig) { c.IPv4.Method = "dhcp" },
+		"v6 in ipv4":     func(c *ConnectionConfig) { c.IPv4.Addresses = []string{"fd00::1/64"} },
+		"manual no addr": func(c *ConnectionConfig) { c.IPv4.Addresses = nil },
+		"dns":            func(c *ConnectionConfig) { c.IPv4.DNS = []string{"not-an-ip"} },
+		"gateway":        func(c *ConnectionConfig) { c.IPv4.Gateway = "fd00::1" },
+		"mac":            func(c *ConnectionConfig) { c.MACAddress = "zz:zz" },
+		"proxy method":   func(c *ConnectionConfig) { c.Proxy.Method = "socks" },
+		"proxy port":     func(c *ConnectionConfig) { c.Proxy = ProxySettings{Method: "manual", Host: "proxy"} },
+		"proxy pac":      func(c *ConnectionConfig) { c.Proxy = ProxySettings{Method: "pac"} },
+	} {
+		settings := wifiSettingsFixture()
+		cfg := connectionConfigFromSettings(settings)
+		mutate(cfg)
+		assert.Error(t, applyConnectionConfig(settings, cfg), name)
+	}
+}
+
+func TestApplyConnectionConfig_VPNRejectsMAC(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{
+		"connection": {"id": "Work VPN", "uuid": "vpn-1", "type": "vpn"},
+	}
+	cfg := connectionConfigFromSettings(settings)
+	assert.Empty(t, cfg.MACAddress)
+	require.NoError(t, applyConnectionConfig(settings, cfg))
+
+	cfg.MACAddress = "random"
+	assert.Error(t, applyConnectionConfig(settings, cfg))
+}
+
+func TestNetworkManagerBackend_UpdateConnectionSettings(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockSettings := mock_gonetworkmanager.NewMockSettings(t)
+	mockConn := mock_gonetworkmanager.NewMockConnection(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.settings = mockSettings
+
+	mockSettings.EXPECT().GetConnectionByUUID("1111-2222").Return(mockConn, nil)
+	mockConn.EXPECT().GetSettings().Return(wifiSettingsFixture(), nil)
+	mockConn.EXPECT().Update(mock.MatchedBy(func(s gonetworkmanager.ConnectionSettings) bool {
+		return s["ipv4"]["method"] == "auto" && s["ipv4"]["ignore-auto-dns"] == true
+	})).Return(nil)
+
+	cfg := connectionConfigFromSettings(wifiSettingsFixture())
+	cfg.IPv4.Method = "auto"
+	cfg.IPv4.IgnoreAutoDNS = true
+	require.NoError(t, backend.UpdateConnectionSettings("1111-2222", *cfg, false))
+}
+
+func TestManualProxyScript(t *testing.T) {
+	script, err := manualProxyScript("::1", 8080, nil)
+	require.NoError(t, err)
+
+	host, port, bypass, ok := parseManualProxyScript(script)
+	require.True(t, ok)
+	assert.Equal(t, "::1", host)
+	assert.Equal(t, 8080, port)
+	assert.Nil(t, bypass)
+
+	_, _, _, ok = parseManualProxyScript("function FindProxyForURL(url, host) { return \"DIRECT\"; }")
+	assert.False(t, ok)
+
+	_, err = manualProxyScript(`evil"host`, 8080, nil)
+	assert.Error(t, err)
+}
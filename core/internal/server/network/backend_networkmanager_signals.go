@@ -1,6 +1,7 @@
 package network
 
 import (
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/Wifx/gonetworkmanager/v2"
 	"github.com/godbus/dbus/v5"
 )
@@ -105,6 +106,14 @@ func (b *NetworkManagerBackend) startSignalPump() error {
 		}
 	}
 
+	if b.mm != nil {
+		for _, match := range modemManagerMatches() {
+			if err := conn.AddMatchSignal(match...); err != nil {
+				log.Warnf("Failed to watch ModemManager signals: %v", err)
+			}
+		}
+	}
+
 	b.sigWG.Add(1)
 	go func() {
 		defer b.sigWG.Done()
@@ -153,6 +162,12 @@ func (b *NetworkManagerBackend) stopSignalPump() {
 		)
 	}
 
+	if b.mm != nil {
+		for _, match := range modemManagerMatches() {
+			b.dbusConn.RemoveMatchSignal(match...)
+		}
+	}
+
 	if b.signals != nil {
 		b.dbusConn.RemoveSignal(b.signals)
 		close(b.signals)
@@ -163,6 +178,30 @@ func (b *NetworkManagerBackend) stopSignalPump() {
 	b.dbusConn.Close()
 }
 
+// ModemManager isn't always installed, so these matches are best effort.
+func modemManagerMatches() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchSender(mmBusName),
+			dbus.WithMatchPathNamespace(dbus.ObjectPath(mmObjectPath)),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		},
+		{
+			dbus.WithMatchSender(mmBusName),
+			dbus.WithMatchObjectPath(dbus.ObjectPath(mmObjectPath)),
+			dbus.WithMatchInterface(dbusObjectManager),
+			dbus.WithMatchMember("InterfacesAdded"),
+		},
+		{
+			dbus.WithMatchSender(mmBusName),
+			dbus.WithMatchObjectPath(dbus.ObjectPath(mmObjectPath)),
+			dbus.WithMatchInterface(dbusObjectManager),
+			dbus.WithMatchMember("InterfacesRemoved"),
+		},
+	}
+}
+
 func (b *NetworkManagerBackend) handleDBusSignal(sig *dbus.Signal) {
 	if sig.Name == "org.freedesktop.NetworkManager.Settings.NewConnection" ||
 		sig.Name == "org.freedesktop.NetworkManager.Settings.ConnectionRemoved" {
@@ -173,6 +212,13 @@ func (b *NetworkManagerBackend) handleDBusSignal(sig *dbus.Signal) {
 		return
 	}
 
+	if sig.Name == dbusObjectManager+".InterfacesAdded" || sig.Name == dbusObjectManager+".InterfacesRemoved" {
+		if sig.Path == dbus.ObjectPath(mmObjectPath) {
+			b.handleModemChange()
+		}
+		return
+	}
+
 	if sig.Name == "org.freedesktop.NetworkManager.DeviceAdded" {
 		if len(sig.Body) >= 1 {
 			if devicePath, ok := sig.Body[0].(dbus.ObjectPath); ok {
@@ -220,6 +266,9 @@ func (b *NetworkManagerBackend) handleDBusSignal(sig *dbus.Signal) {
 
 	case dbusNMAccessPointInterface:
 		b.handleAccessPointChange(changes)
+
+	case mmModemInterface, mm3gppInterface:
+		b.handleModemChange()
 	}
 }
 
@@ -238,6 +287,9 @@ func (b *NetworkManagerBackend) handleNetworkManagerChange(changes map[string]db
 				b.stateMutex.Unlock()
 				needsUpdate = true
 			}
+		case "WwanEnabled":
+			b.updateWWANState()
+			needsUpdate = true
 		default:
 			continue
 		}
@@ -380,6 +432,13 @@ func (b *NetworkManagerBackend) handleAccessPointChange(changes map[string]dbus.
 	}
 }
 
+func (b *NetworkManagerBackend) handleModemChange() {
+	b.updateWWANState()
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+}
+
 func (b *NetworkManagerBackend) handleDeviceAdded(devicePath dbus.ObjectPath) {
 	dev, err := gonetworkmanager.NewDevice(devicePath)
 	if err != nil {
@@ -127,6 +127,25 @@ func TestNetworkManagerBackend_HandleNetworkManagerChange_WirelessEnabled(t *tes
 	})
 }
 
+func TestNetworkManagerBackend_HandleNetworkManagerChange_WwanEnabled(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	assert.NoError(t, err)
+
+	mockNM.EXPECT().GetPropertyWwanEnabled().Return(true, nil)
+	mockNM.EXPECT().GetPropertyActiveConnections().Return([]gonetworkmanager.ActiveConnection{}, nil).Maybe()
+	mockNM.EXPECT().GetPropertyPrimaryConnection().Return(nil, nil).Maybe()
+
+	backend.handleNetworkManagerChange(map[string]dbus.Variant{
+		"WwanEnabled": dbus.MakeVariant(true),
+	})
+
+	state, err := backend.GetCurrentState()
+	assert.NoError(t, err)
+	assert.True(t, state.WWANEnabled)
+}
+
 func TestNetworkManagerBackend_HandleNetworkManagerChange_ActiveConnections(t *testing.T) {
 	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
 
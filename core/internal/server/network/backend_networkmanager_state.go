@@ -275,3 +275,51 @@ func (b *NetworkManagerBackend) getDeviceIP(dev gonetworkmanager.Device) string
 
 	return addresses[0].Address
 }
+
+func (b *NetworkManagerBackend) GetInterfaceConnections() map[string]InterfaceConnection {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+
+	links := make(map[string]InterfaceConnection)
+	activeConns, err := nm.GetPropertyActiveConnections()
+	if err != nil {
+		return links
+	}
+
+	for _, activeConn := range activeConns {
+		// Plugin VPNs report the device they tunnel over, not their own.
+		if isVPN, _ := activeConn.GetPropertyVPN(); isVPN {
+			continue
+		}
+
+		info := InterfaceConnection{}
+		info.UUID, _ = activeConn.GetPropertyUUID()
+		info.Name, _ = activeConn.GetPropertyID()
+		info.Type, _ = activeConn.GetPropertyType()
+
+		// NetworkManager guesses mobile broadband is metered when unset.
+		info.Metered = info.Type == "gsm" || info.Type == "cdma"
+		if conn, err := activeConn.GetPropertyConnection(); err == nil && conn != nil {
+			if settings, err := conn.GetSettings(); err == nil {
+				switch metered, _ := settings["connection"]["metered"].(int32); metered {
+				case 1:
+					info.Metered = true
+				case 2:
+					info.Metered = false
+				}
+			}
+		}
+
+		devices, _ := activeConn.GetPropertyDevices()
+		for _, dev := range devices {
+			iface, _ := dev.GetPropertyIpInterface()
+			if iface == "" {
+				iface, _ = dev.GetPropertyInterface()
+			}
+			if iface != "" {
+				links[iface] = info
+			}
+		}
+	}
+
+	return links
+}
@@ -164,6 +164,10 @@ func (b *NetworkManagerBackend) ListActiveVPN() ([]VPNActive, error) {
 			}
 		}
 
+		if connType == "wireguard" && vpnActive.Device != "" {
+			vpnActive.RxBytes, vpnActive.TxBytes, vpnActive.Peers = wireGuardStats(vpnActive.Device)
+		}
+
 		if connType == "vpn" {
 			conn, _ := activeConn.GetPropertyConnection()
 			if conn != nil {
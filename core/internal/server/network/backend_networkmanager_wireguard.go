@@ -0,0 +1,259 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// Matches what `nmcli connection import type wireguard` sets for tunnels
+// that carry the default route, so DNS doesn't leak outside the tunnel.
+const wireGuardFullTunnelDNSPriority = int32(50)
+
+func (b *NetworkManagerBackend) CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error) {
+	cfg, err := prepareWireGuardConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := wireGuardSettings(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := b.settings
+	if s == nil {
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	conn, err := settingsMgr.AddConnection(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add WireGuard connection: %w", err)
+	}
+
+	if created, err := conn.GetSettings(); err == nil {
+		cfg.UUID, _ = created["connection"]["uuid"].(string)
+	}
+	log.Infof("[CreateWireGuard] Added WireGuard connection %s (%s)", cfg.Name, cfg.InterfaceName)
+
+	b.ListVPNProfiles()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return &cfg, nil
+}
+
+func (b *NetworkManagerBackend) ExportWireGuard(uuidOrName string) (*WireGuardConfig, error) {
+	s := b.settings
+	if s == nil {
+		var err error
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+
+		connMeta, ok := settings["connection"]
+		if !ok {
+			continue
+		}
+
+		connType, _ := connMeta["type"].(string)
+		connID, _ := connMeta["id"].(string)
+		connUUID, _ := connMeta["uuid"].(string)
+		if connType != "wireguard" || (connUUID != uuidOrName && connID != uuidOrName) {
+			continue
+		}
+
+		secrets, err := conn.GetSecrets("wireguard")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read WireGuard keys: %w", err)
+		}
+
+		return wireGuardConfigFromSettings(settings, secrets), nil
+	}
+
+	return nil, fmt.Errorf("WireGuard connection not found: %s", uuidOrName)
+}
+
+func wireGuardSettings(cfg *WireGuardConfig) (gonetworkmanager.ConnectionSettings, error) {
+	peers := make([]map[string]any, 0, len(cfg.Peers))
+	for _, p := range cfg.Peers {
+		peer := map[string]any{
+			"public-key":  p.PublicKey,
+			"allowed-ips": append([]string{}, p.AllowedIPs...),
+		}
+		if p.Endpoint != "" {
+			peer["endpoint"] = p.Endpoint
+		}
+		if p.PresharedKey != "" {
+			peer["preshared-key"] = p.PresharedKey
+			peer["preshared-key-flags"] = uint32(0)
+		}
+		if p.PersistentKeepalive > 0 {
+			peer["persistent-keepalive"] = p.PersistentKeepalive
+		}
+		peers = append(peers, peer)
+	}
+
+	wg := map[string]any{
+		"private-key":       cfg.PrivateKey,
+		"private-key-flags": uint32(0),
+		"peers":             peers,
+	}
+	if cfg.ListenPort > 0 {
+		wg["listen-port"] = cfg.ListenPort
+	}
+	if cfg.MTU > 0 {
+		wg["mtu"] = cfg.MTU
+	}
+	if cfg.FwMark > 0 {
+		wg["fwmark"] = cfg.FwMark
+	}
+
+	settings := gonetworkmanager.ConnectionSettings{
+		"connection": {
+			"id":             cfg.Name,
+			"type":           "wireguard",
+			"interface-name": cfg.InterfaceName,
+			"autoconnect":    cfg.Autoconnect,
+		},
+		"wireguard": wg,
+		"ipv4":      {},
+		"ipv6":      {},
+	}
+
+	for _, v6 := range []bool{false, true} {
+		ip := IPSettings{Method: "disabled"}
+		if v6 {
+			ip.Method = "ignore"
+		}
+		for _, addr := range cfg.Addresses {
+			if prefix, err := netip.ParsePrefix(addr); err == nil && prefix.Addr().Is6() == v6 {
+				ip.Addresses = append(ip.Addresses, addr)
+			}
+		}
+		for _, dns := range cfg.DNS {
+			if a, err := netip.ParseAddr(dns); err == nil && a.Is6() == v6 {
+				ip.DNS = append(ip.DNS, dns)
+			}
+		}
+		if len(ip.Addresses) > 0 {
+			ip.Method = "manual"
+			ip.DNSSearch = cfg.DNSSearch
+		}
+
+		section := settings["ipv4"]
+		if v6 {
+			section = settings["ipv6"]
+		}
+		fullTunnel := len(ip.DNS) > 0 && wireGuardRoutesDefault(cfg.Peers, v6)
+		if fullTunnel {
+			ip.DNSSearch = append(append([]string{}, ip.DNSSearch...), "~")
+			section["dns-priority"] = wireGuardFullTunnelDNSPriority
+		}
+		if err := applyIPSettings(section, ip, v6); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+func wireGuardRoutesDefault(peers []WireGuardPeer, v6 bool) bool {
+	for _, p := range peers {
+		for _, allowed := range p.AllowedIPs {
+			prefix, err := netip.ParsePrefix(allowed)
+			if err == nil && prefix.Bits() == 0 && prefix.Addr().Is6() == v6 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wireGuardConfigFromSettings builds a config from connection settings and
+// the secrets returned by GetSecrets("wireguard"). Keys stored by a secret
+// agent rather than NetworkManager are left empty.
+func wireGuardConfigFromSettings(settings, secrets gonetworkmanager.ConnectionSettings) *WireGuardConfig {
+	meta := settings["connection"]
+	wg := settings["wireguard"]
+	wgSecrets := secrets["wireguard"]
+
+	cfg := &WireGuardConfig{Autoconnect: true, Addresses: []string{}, Peers: []WireGuardPeer{}}
+	cfg.UUID, _ = meta["uuid"].(string)
+	cfg.Name, _ = meta["id"].(string)
+	cfg.InterfaceName, _ = meta["interface-name"].(string)
+	if autoconnect, ok := meta["autoconnect"].(bool); ok {
+		cfg.Autoconnect = autoconnect
+	}
+	cfg.ListenPort, _ = wg["listen-port"].(uint32)
+	cfg.MTU, _ = wg["mtu"].(uint32)
+	cfg.FwMark, _ = wg["fwmark"].(uint32)
+
+	cfg.PrivateKey, _ = wgSecrets["private-key"].(string)
+	if cfg.PrivateKey != "" {
+		cfg.PublicKey, _ = wireGuardPublicKey(cfg.PrivateKey)
+	}
+
+	presharedKeys := map[string]string{}
+	secretPeers, _ := wgSecrets["peers"].([]map[string]any)
+	for _, p := range secretPeers {
+		pub, _ := p["public-key"].(string)
+		psk, _ := p["preshared-key"].(string)
+		presharedKeys[pub] = psk
+	}
+
+	peers, _ := wg["peers"].([]map[string]any)
+	for _, p := range peers {
+		peer := WireGuardPeer{AllowedIPs: []string{}}
+		peer.PublicKey, _ = p["public-key"].(string)
+		peer.Endpoint, _ = p["endpoint"].(string)
+		peer.PersistentKeepalive, _ = p["persistent-keepalive"].(uint32)
+		if allowed, ok := p["allowed-ips"].([]string); ok {
+			peer.AllowedIPs = allowed
+		}
+		peer.PresharedKey = presharedKeys[peer.PublicKey]
+		cfg.Peers = append(cfg.Peers, peer)
+	}
+
+	for _, v6 := range []bool{false, true} {
+		key := "ipv4"
+		if v6 {
+			key = "ipv6"
+		}
+		ip := ipSettingsFromSection(settings[key], v6)
+		cfg.Addresses = append(cfg.Addresses, ip.Addresses...)
+		cfg.DNS = append(cfg.DNS, ip.DNS...)
+		for _, domain := range ip.DNSSearch {
+			if domain != "~" && !slices.Contains(cfg.DNSSearch, domain) {
+				cfg.DNSSearch = append(cfg.DNSSearch, domain)
+			}
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,449 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+func (b *NetworkManagerBackend) GetWWANModems() ([]WWANModem, error) {
+	if b.mm == nil {
+		return nil, fmt.Errorf("ModemManager not available")
+	}
+
+	modems, err := b.mm.listModems()
+	if err != nil {
+		return nil, err
+	}
+
+	b.stateMutex.Lock()
+	b.state.WWANModems = modems
+	b.stateMutex.Unlock()
+
+	return modems, nil
+}
+
+func (b *NetworkManagerBackend) updateWWANState() {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if enabled, err := nm.GetPropertyWwanEnabled(); err == nil {
+		b.stateMutex.Lock()
+		b.state.WWANEnabled = enabled
+		b.stateMutex.Unlock()
+	}
+
+	if b.mm == nil {
+		return
+	}
+	if _, err := b.GetWWANModems(); err != nil {
+		log.Debugf("[WWAN] Failed to list modems: %v", err)
+	}
+}
+
+// SetWWANEnabled flips NetworkManager's mobile broadband radio switch,
+// which enables or disables every unlocked modem through ModemManager.
+func (b *NetworkManagerBackend) SetWWANEnabled(enabled bool) error {
+	if b.mm == nil {
+		return fmt.Errorf("ModemManager not available")
+	}
+
+	// gonetworkmanager has no setter for WwanEnabled.
+	obj := b.mm.conn.Object(dbusNMInterface, dbusNMPath)
+	call := obj.Call(dbusPropsInterface+".Set", 0, dbusNMInterface, "WwanEnabled", dbus.MakeVariant(enabled))
+	if call.Err != nil {
+		return fmt.Errorf("failed to set WWAN enabled: %w", call.Err)
+	}
+
+	b.stateMutex.Lock()
+	b.state.WWANEnabled = enabled
+	b.stateMutex.Unlock()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+// UnlockWWAN unlocks the SIM in a modem. Without a PIN or PUK the user is
+// prompted through the credential broker and the unlock happens in the
+// background once they answer.
+func (b *NetworkManagerBackend) UnlockWWAN(modemID, pin, puk string) error {
+	modems, err := b.GetWWANModems()
+	if err != nil {
+		return err
+	}
+
+	modem, err := findWWANModem(modems, modemID)
+	if err != nil {
+		return err
+	}
+	if modem.UnlockRequired == "" {
+		return fmt.Errorf("modem %s is not locked", modem.ID)
+	}
+	if !modem.SIMPresent {
+		return fmt.Errorf("no SIM in modem %s", modem.ID)
+	}
+
+	needsPuk := wwanLockNeedsPuk(modem.UnlockRequired)
+	if !needsPuk && !strings.HasPrefix(modem.UnlockRequired, "sim-pin") {
+		return fmt.Errorf("unlocking %s is not supported", modem.UnlockRequired)
+	}
+
+	if pin != "" || puk != "" {
+		return b.sendWWANUnlock(modem, pin, puk)
+	}
+
+	if b.promptBroker == nil {
+		return fmt.Errorf("prompt broker not initialized")
+	}
+
+	go b.promptWWANUnlock(*modem)
+	return nil
+}
+
+func wwanLockNeedsPuk(lock string) bool {
+	return strings.HasPrefix(lock, "sim-puk")
+}
+
+func (b *NetworkManagerBackend) sendWWANUnlock(modem *WWANModem, pin, puk string) error {
+	var err error
+	if wwanLockNeedsPuk(modem.UnlockRequired) {
+		if puk == "" || pin == "" {
+			return fmt.Errorf("PUK and new PIN required")
+		}
+		err = b.mm.sendPuk(modem.simPath, puk, pin)
+	} else {
+		if pin == "" {
+			return fmt.Errorf("PIN required")
+		}
+		err = b.mm.sendPin(modem.simPath, pin)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unlock SIM: %w", err)
+	}
+	log.Infof("[UnlockWWAN] Unlocked SIM in modem %s", modem.ID)
+
+	b.updateWWANState()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) promptWWANUnlock(modem WWANModem) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	name := strings.TrimSpace(modem.Manufacturer + " " + modem.Model)
+	if name == "" {
+		name = "Modem " + modem.ID
+	}
+
+	fields := []string{"pin"}
+	if wwanLockNeedsPuk(modem.UnlockRequired) {
+		fields = []string{"puk", "pin"}
+	}
+
+	token, err := b.promptBroker.Ask(ctx, PromptRequest{
+		Name:           name,
+		ConnType:       "gsm",
+		SettingName:    "gsm",
+		Fields:         fields,
+		FieldsInfo:     wwanUnlockFieldsInfo(&modem, fields),
+		Reason:         modem.UnlockRequired,
+		ConnectionId:   name,
+		ConnectionPath: modem.Path,
+	})
+	if err != nil {
+		log.Warnf("[UnlockWWAN] Failed to request SIM PIN: %v", err)
+		return
+	}
+
+	reply, err := b.promptBroker.Wait(ctx, token)
+	if err != nil {
+		log.Warnf("[UnlockWWAN] SIM PIN prompt failed: %v", err)
+		return
+	}
+	if reply.Cancel {
+		log.Infof("[UnlockWWAN] SIM unlock cancelled")
+		return
+	}
+
+	if err := b.sendWWANUnlock(&modem, reply.Secrets["pin"], reply.Secrets["puk"]); err != nil {
+		log.Warnf("[UnlockWWAN] %v", err)
+		b.stateMutex.Lock()
+		b.state.LastError = err.Error()
+		b.stateMutex.Unlock()
+		b.updateWWANState()
+		if b.onStateChange != nil {
+			b.onStateChange()
+		}
+	}
+}
+
+func wwanUnlockFieldsInfo(modem *WWANModem, fields []string) []FieldInfo {
+	infos := buildFieldsInfo("gsm", fields, "")
+	for i := range infos {
+		switch {
+		case infos[i].Name == "pin" && wwanLockNeedsPuk(modem.UnlockRequired):
+			infos[i].Label = "New SIM PIN"
+		case infos[i].Name == "pin" || infos[i].Name == "puk":
+			if n, ok := modem.UnlockRetries[modem.UnlockRequired]; ok {
+				infos[i].Label = fmt.Sprintf("%s (%d attempts left)", infos[i].Label, n)
+			}
+		}
+	}
+	return infos
+}
+
+func (b *NetworkManagerBackend) ListWWANProfiles() ([]WWANProfile, error) {
+	s := b.settings
+	if s == nil {
+		var err error
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	active := make(map[string]bool)
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if activeConns, err := nm.GetPropertyActiveConnections(); err == nil {
+		for _, activeConn := range activeConns {
+			if connType, _ := activeConn.GetPropertyType(); connType != "gsm" {
+				continue
+			}
+			if uuid, err := activeConn.GetPropertyUUID(); err == nil {
+				active[uuid] = true
+			}
+		}
+	}
+
+	profiles := []WWANProfile{}
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if connType, _ := settings["connection"]["type"].(string); connType != "gsm" {
+			continue
+		}
+
+		profile := wwanProfileFromSettings(settings)
+		profile.Active = active[profile.UUID]
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return strings.ToLower(profiles[i].Name) < strings.ToLower(profiles[j].Name)
+	})
+
+	return profiles, nil
+}
+
+// SaveWWANProfile creates a gsm connection, or updates the one with
+// profile.UUID. An empty password keeps the stored one.
+func (b *NetworkManagerBackend) SaveWWANProfile(profile WWANProfile) (*WWANProfile, error) {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return nil, fmt.Errorf("profile name is required")
+	}
+
+	if profile.UUID != "" {
+		conn, err := b.connectionByUUID(profile.UUID)
+		if err != nil {
+			return nil, err
+		}
+
+		settings, err := conn.GetSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connection settings: %w", err)
+		}
+		if connType, _ := settings["connection"]["type"].(string); connType != "gsm" {
+			return nil, fmt.Errorf("connection %s is not a mobile broadband profile", profile.UUID)
+		}
+
+		if profile.Password == "" {
+			if secrets, err := conn.GetSecrets("gsm"); err == nil {
+				profile.Password, _ = secrets["gsm"]["password"].(string)
+			}
+		}
+
+		applyWWANProfile(settings, &profile)
+		if err := conn.Update(settings); err != nil {
+			return nil, fmt.Errorf("failed to update mobile broadband profile: %w", err)
+		}
+		log.Infof("[SaveWWANProfile] Updated profile %s (%s)", profile.Name, profile.UUID)
+	} else {
+		s := b.settings
+		if s == nil {
+			var err error
+			s, err = gonetworkmanager.NewSettings()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get settings: %w", err)
+			}
+			b.settings = s
+		}
+
+		settings := gonetworkmanager.ConnectionSettings{
+			"connection": {"type": "gsm"},
+			"gsm":        {},
+			"ipv4":       {"method": "auto"},
+			"ipv6":       {"method": "auto"},
+		}
+		applyWWANProfile(settings, &profile)
+
+		settingsMgr := s.(gonetworkmanager.Settings)
+		conn, err := settingsMgr.AddConnection(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add mobile broadband profile: %w", err)
+		}
+		if created, err := conn.GetSettings(); err == nil {
+			profile.UUID, _ = created["connection"]["uuid"].(string)
+		}
+		log.Infof("[SaveWWANProfile] Added profile %s (%s)", profile.Name, profile.UUID)
+	}
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	profile.Password = ""
+	return &profile, nil
+}
+
+func (b *NetworkManagerBackend) DeleteWWANProfile(uuid string) error {
+	conn, err := b.wwanConnection(uuid)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Delete(); err != nil {
+		return fmt.Errorf("failed to delete mobile broadband profile: %w", err)
+	}
+	log.Infof("[DeleteWWANProfile] Deleted profile %s", uuid)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) ConnectWWAN(uuid string) error {
+	conn, err := b.wwanConnection(uuid)
+	if err != nil {
+		return err
+	}
+
+	// NetworkManager picks the modem when no device is given.
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if _, err := nm.ActivateConnection(conn, nil, nil); err != nil {
+		return fmt.Errorf("failed to activate mobile broadband profile: %w", err)
+	}
+	log.Infof("[ConnectWWAN] Activating profile %s", uuid)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) DisconnectWWAN(uuid string) error {
+	active, err := b.findActiveConnection(uuid)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		return fmt.Errorf("mobile broadband profile is not active: %s", uuid)
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	if err := nm.DeactivateConnection(active); err != nil {
+		return fmt.Errorf("failed to deactivate mobile broadband profile: %w", err)
+	}
+	log.Infof("[DisconnectWWAN] Deactivated profile %s", uuid)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) wwanConnection(uuid string) (gonetworkmanager.Connection, error) {
+	conn, err := b.connectionByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if connType, _ := settings["connection"]["type"].(string); connType != "gsm" {
+		return nil, fmt.Errorf("connection %s is not a mobile broadband profile", uuid)
+	}
+
+	return conn, nil
+}
+
+func applyWWANProfile(settings gonetworkmanager.ConnectionSettings, profile *WWANProfile) {
+	meta := settings["connection"]
+	meta["id"] = profile.Name
+	meta["autoconnect"] = profile.Autoconnect
+	if profile.Device != "" {
+		meta["interface-name"] = profile.Device
+	} else {
+		delete(meta, "interface-name")
+	}
+
+	gsm := settings["gsm"]
+	gsm["apn"] = profile.APN
+	gsm["home-only"] = !profile.AllowRoaming
+	if profile.Username != "" {
+		gsm["username"] = profile.Username
+	} else {
+		delete(gsm, "username")
+	}
+	if profile.Password != "" {
+		gsm["password"] = profile.Password
+		gsm["password-flags"] = uint32(0)
+	}
+}
+
+func wwanProfileFromSettings(settings gonetworkmanager.ConnectionSettings) WWANProfile {
+	meta := settings["connection"]
+	gsm := settings["gsm"]
+
+	profile := WWANProfile{Autoconnect: true, AllowRoaming: true}
+	profile.UUID, _ = meta["uuid"].(string)
+	profile.Name, _ = meta["id"].(string)
+	profile.Device, _ = meta["interface-name"].(string)
+	if autoconnect, ok := meta["autoconnect"].(bool); ok {
+		profile.Autoconnect = autoconnect
+	}
+	profile.APN, _ = gsm["apn"].(string)
+	profile.Username, _ = gsm["username"].(string)
+	if homeOnly, ok := gsm["home-only"].(bool); ok {
+		profile.AllowRoaming = !homeOnly
+	}
+
+	return profile
+}
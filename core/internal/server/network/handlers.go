@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
@@ -77,8 +79,48 @@ func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
 		handleDeleteVPN(conn, req, manager)
 	case "network.vpn.setCredentials":
 		handleSetVPNCredentials(conn, req, manager)
+	case "network.wireguard.create":
+		handleCreateWireGuard(conn, req, manager)
+	case "network.wireguard.import":
+		handleImportWireGuard(conn, req, manager)
+	case "network.wireguard.export":
+		handleExportWireGuard(conn, req, manager)
+	case "network.wireguard.generateKeypair":
+		handleGenerateWireGuardKeypair(conn, req, manager)
 	case "network.wifi.setAutoconnect":
 		handleSetWiFiAutoconnect(conn, req, manager)
+	case "network.connection.getSettings":
+		handleGetConnectionSettings(conn, req, manager)
+	case "network.connection.updateSettings":
+		handleUpdateConnectionSettings(conn, req, manager)
+	case "network.hotspot.start":
+		handleStartHotspot(conn, req, manager)
+	case "network.hotspot.stop":
+		handleStopHotspot(conn, req, manager)
+	case "network.hotspot.getConfig":
+		handleGetHotspotConfig(conn, req, manager)
+	case "network.wwan.modems":
+		handleGetWWANModems(conn, req, manager)
+	case "network.wwan.setEnabled":
+		handleSetWWANEnabled(conn, req, manager)
+	case "network.wwan.unlock":
+		handleUnlockWWAN(conn, req, manager)
+	case "network.wwan.profiles":
+		handleListWWANProfiles(conn, req, manager)
+	case "network.wwan.saveProfile":
+		handleSaveWWANProfile(conn, req, manager)
+	case "network.wwan.deleteProfile":
+		handleDeleteWWANProfile(conn, req, manager)
+	case "network.wwan.connect":
+		handleConnectWWAN(conn, req, manager)
+	case "network.wwan.disconnect":
+		handleDisconnectWWAN(conn, req, manager)
+	case "network.stats.get":
+		handleGetTrafficStats(conn, req, manager)
+	case "network.stats.setInterval":
+		handleSetTrafficInterval(conn, req, manager)
+	case "network.stats.setCap":
+		handleSetDataCap(conn, req, manager)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
@@ -513,6 +555,105 @@ func handleSetWiFiAutoconnect(conn net.Conn, req models.Request, manager *Manage
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "autoconnect updated"})
 }
 
+func handleGetConnectionSettings(conn net.Conn, req models.Request, manager *Manager) {
+	uuid, err := params.StringNonEmpty(req.Params, "uuid")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	cfg, err := manager.GetConnectionSettings(uuid)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, cfg)
+}
+
+// handleUpdateConnectionSettings overlays the given settings on the current
+// ones, so clients only need to send the fields they change.
+func handleUpdateConnectionSettings(conn net.Conn, req models.Request, manager *Manager) {
+	uuid, err := params.StringNonEmpty(req.Params, "uuid")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	updates, ok := params.AnyMap(req.Params, "settings")
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'settings' parameter")
+		return
+	}
+
+	cfg, err := manager.GetConnectionSettings(uuid)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(updates)
+	if err == nil {
+		err = json.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid settings: %v", err))
+		return
+	}
+
+	reactivate := params.BoolOpt(req.Params, "reactivate", false)
+	if err := manager.UpdateConnectionSettings(uuid, *cfg, reactivate); err != nil {
+		log.Warnf("handleUpdateConnectionSettings: failed to update %s: %v", uuid, err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to update connection: %v", err))
+		return
+	}
+
+	updated, err := manager.GetConnectionSettings(uuid)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, updated)
+}
+
+func handleStartHotspot(conn net.Conn, req models.Request, manager *Manager) {
+	cfg := HotspotConfig{
+		SSID:     params.StringOpt(req.Params, "ssid", ""),
+		Password: params.StringOpt(req.Params, "password", ""),
+		Band:     params.StringOpt(req.Params, "band", ""),
+		Device:   params.StringOpt(req.Params, "device", ""),
+	}
+
+	started, err := manager.StartHotspot(cfg)
+	if err != nil {
+		log.Warnf("handleStartHotspot: failed to start hotspot: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to start hotspot: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, started)
+}
+
+func handleStopHotspot(conn net.Conn, req models.Request, manager *Manager) {
+	if err := manager.StopHotspot(); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to stop hotspot: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "hotspot stopped"})
+}
+
+func handleGetHotspotConfig(conn net.Conn, req models.Request, manager *Manager) {
+	cfg, err := manager.GetHotspotConfig()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, cfg)
+}
+
 func handleListVPNPlugins(conn net.Conn, req models.Request, manager *Manager) {
 	plugins, err := manager.ListVPNPlugins()
 	if err != nil {
@@ -628,3 +769,289 @@ func handleSetVPNCredentials(conn net.Conn, req models.Request, manager *Manager
 
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "VPN credentials set"})
 }
+
+func handleCreateWireGuard(conn net.Conn, req models.Request, manager *Manager) {
+	raw, ok := params.AnyMap(req.Params, "config")
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'config' parameter")
+		return
+	}
+
+	var cfg WireGuardConfig
+	data, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid config: %v", err))
+		return
+	}
+
+	created, err := manager.CreateWireGuard(cfg)
+	if err != nil {
+		log.Warnf("handleCreateWireGuard: failed to create: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to create WireGuard connection: %v", err))
+		return
+	}
+
+	created.PrivateKey = ""
+	models.Respond(conn, req.ID, created)
+}
+
+func handleImportWireGuard(conn net.Conn, req models.Request, manager *Manager) {
+	filePath, _ := params.StringAlt(req.Params, "file", "path")
+	content := params.StringOpt(req.Params, "content", "")
+	if filePath == "" && content == "" {
+		models.RespondError(conn, req.ID, "missing 'file' or 'content' parameter")
+		return
+	}
+
+	name := params.StringOpt(req.Params, "name", "")
+
+	created, err := manager.ImportWireGuard(filePath, content, name)
+	if err != nil {
+		log.Warnf("handleImportWireGuard: failed to import: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to import WireGuard config: %v", err))
+		return
+	}
+
+	created.PrivateKey = ""
+	models.Respond(conn, req.ID, created)
+}
+
+// handleExportWireGuard returns the connection as wg-quick config text,
+// optionally also writing it to 'file' with owner-only permissions.
+func handleExportWireGuard(conn net.Conn, req models.Request, manager *Manager) {
+	uuidOrName, ok := params.StringAlt(req.Params, "uuid", "name", "uuidOrName")
+	if !ok {
+		models.RespondError(conn, req.ID, "missing 'uuid', 'name', or 'uuidOrName' parameter")
+		return
+	}
+
+	cfg, err := manager.ExportWireGuard(uuidOrName)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	text := formatWireGuardConf(cfg)
+	if filePath := params.StringOpt(req.Params, "file", ""); filePath != "" {
+		if err := os.WriteFile(filePath, []byte(text), 0o600); err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("failed to write config: %v", err))
+			return
+		}
+	}
+
+	models.Respond(conn, req.ID, map[string]string{"config": text})
+}
+
+func handleGenerateWireGuardKeypair(conn net.Conn, req models.Request, _ *Manager) {
+	if privateKey := params.StringOpt(req.Params, "privateKey", ""); privateKey != "" {
+		publicKey, err := wireGuardPublicKey(privateKey)
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, WireGuardKeypair{PrivateKey: privateKey, PublicKey: publicKey})
+		return
+	}
+
+	keys, err := generateWireGuardKeypair()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, keys)
+}
+
+func handleGetWWANModems(conn net.Conn, req models.Request, manager *Manager) {
+	modems, err := manager.GetWWANModems()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, modems)
+}
+
+func handleSetWWANEnabled(conn net.Conn, req models.Request, manager *Manager) {
+	enabled, err := params.Bool(req.Params, "enabled")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetWWANEnabled(enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "WWAN updated"})
+}
+
+// handleUnlockWWAN sends the given PIN, or PUK and new PIN. With neither,
+// the user is prompted through network.credentials and the response only
+// means the prompt was sent.
+func handleUnlockWWAN(conn net.Conn, req models.Request, manager *Manager) {
+	modem := params.StringOpt(req.Params, "modem", "")
+	pin := params.StringOpt(req.Params, "pin", "")
+	puk := params.StringOpt(req.Params, "puk", "")
+
+	if err := manager.UnlockWWAN(modem, pin, puk); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if pin == "" && puk == "" {
+		models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "SIM PIN requested"})
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "SIM unlocked"})
+}
+
+func handleListWWANProfiles(conn net.Conn, req models.Request, manager *Manager) {
+	profiles, err := manager.ListWWANProfiles()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, profiles)
+}
+
+// handleSaveWWANProfile creates a profile, or updates the one with the
+// given uuid. Omitted fields keep their current values.
+func handleSaveWWANProfile(conn net.Conn, req models.Request, manager *Manager) {
+	profile := WWANProfile{Autoconnect: true, AllowRoaming: true}
+
+	if uuid := params.StringOpt(req.Params, "uuid", ""); uuid != "" {
+		profiles, err := manager.ListWWANProfiles()
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		idx := slices.IndexFunc(profiles, func(p WWANProfile) bool { return p.UUID == uuid })
+		if idx < 0 {
+			models.RespondError(conn, req.ID, fmt.Sprintf("mobile broadband profile not found: %s", uuid))
+			return
+		}
+		profile = profiles[idx]
+	}
+
+	data, err := json.Marshal(req.Params)
+	if err == nil {
+		err = json.Unmarshal(data, &profile)
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid profile: %v", err))
+		return
+	}
+
+	saved, err := manager.SaveWWANProfile(profile)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, saved)
+}
+
+func handleDeleteWWANProfile(conn net.Conn, req models.Request, manager *Manager) {
+	uuid, err := params.StringNonEmpty(req.Params, "uuid")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.DeleteWWANProfile(uuid); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "profile deleted"})
+}
+
+func handleConnectWWAN(conn net.Conn, req models.Request, manager *Manager) {
+	uuid, err := params.StringNonEmpty(req.Params, "uuid")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.ConnectWWAN(uuid); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "connecting"})
+}
+
+func handleDisconnectWWAN(conn net.Conn, req models.Request, manager *Manager) {
+	uuid, err := params.StringNonEmpty(req.Params, "uuid")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.DisconnectWWAN(uuid); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "disconnected"})
+}
+
+func handleGetTrafficStats(conn net.Conn, req models.Request, manager *Manager) {
+	history := params.BoolOpt(req.Params, "history", true)
+	daily := params.BoolOpt(req.Params, "daily", false)
+
+	stats, err := manager.GetTrafficStats(history, daily)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, stats)
+}
+
+func handleSetTrafficInterval(conn net.Conn, req models.Request, manager *Manager) {
+	seconds, err := params.Float(req.Params, "interval")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetTrafficInterval(time.Duration(seconds * float64(time.Second))); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "interval updated"})
+}
+
+func handleSetDataCap(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	limit, err := params.Float(req.Params, "limit")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if limit < 0 {
+		models.RespondError(conn, req.ID, "limit must not be negative")
+		return
+	}
+
+	period := params.StringOpt(req.Params, "period", "month")
+	if err := manager.SetDataCap(id, uint64(limit), period); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "data cap updated"})
+}
@@ -0,0 +1,73 @@
+package network
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+const (
+	HotspotBandAuto = ""
+	HotspotBand2GHz = "bg"
+	HotspotBand5GHz = "a"
+
+	hotspotConnectionID = "DMS Hotspot"
+	hotspotDefaultSSID  = "DMS Hotspot"
+)
+
+// parseHotspotBand accepts NetworkManager's band names as well as the
+// friendlier 2.4/5 GHz spellings.
+func parseHotspotBand(band string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(band)) {
+	case "", "auto":
+		return HotspotBandAuto, nil
+	case "bg", "2.4", "2.4ghz", "2ghz":
+		return HotspotBand2GHz, nil
+	case "a", "5", "5ghz":
+		return HotspotBand5GHz, nil
+	}
+	return "", fmt.Errorf("invalid band %q (auto, bg, a)", band)
+}
+
+// prepareHotspotConfig validates cfg and fills in defaults. A missing
+// password is generated so the hotspot is never left open.
+func prepareHotspotConfig(cfg HotspotConfig) (HotspotConfig, error) {
+	if cfg.SSID == "" {
+		cfg.SSID = hotspotDefaultSSID
+	}
+	if len(cfg.SSID) > 32 {
+		return cfg, fmt.Errorf("SSID must be at most 32 bytes")
+	}
+
+	band, err := parseHotspotBand(cfg.Band)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Band = band
+
+	switch {
+	case cfg.Password == "":
+		if cfg.Password, err = generateHotspotPassword(); err != nil {
+			return cfg, err
+		}
+	case len(cfg.Password) < 8 || len(cfg.Password) > 63:
+		return cfg, fmt.Errorf("password must be 8 to 63 characters")
+	}
+
+	cfg.Active = false
+	return cfg, nil
+}
+
+// generateHotspotPassword avoids look-alike characters since the password
+// is often typed in by hand from another screen.
+func generateHotspotPassword() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(buf), nil
+}
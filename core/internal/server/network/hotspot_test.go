@@ -0,0 +1,117 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mock_gonetworkmanager "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/Wifx/gonetworkmanager/v2"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHotspotBand(t *testing.T) {
+	for in, want := range map[string]string{"": "", "auto": "", "bg": "bg", "2.4GHz": "bg", "a": "a", "5": "a"} {
+		got, err := parseHotspotBand(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := parseHotspotBand("6ghz")
+	assert.Error(t, err)
+}
+
+func TestPrepareHotspotConfig(t *testing.T) {
+	cfg, err := prepareHotspotConfig(HotspotConfig{Band: "5ghz", Active: true})
+	require.NoError(t, err)
+	assert.Equal(t, hotspotDefaultSSID, cfg.SSID)
+	assert.Equal(t, HotspotBand5GHz, cfg.Band)
+	assert.Len(t, cfg.Password, 12)
+	assert.False(t, cfg.Active)
+
+	_, err = prepareHotspotConfig(HotspotConfig{SSID: "demo", Password: "short"})
+	assert.Error(t, err)
+
+	_, err = prepareHotspotConfig(HotspotConfig{SSID: "this ssid is far too long to be valid"})
+	assert.Error(t, err)
+}
+
+func TestWriteIWDAPProfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ap")
+	require.NoError(t, writeIWDAPProfile(dir, HotspotConfig{SSID: "Demo AP", Password: "password123", Band: HotspotBand5GHz}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "Demo AP.ap"))
+	require.NoError(t, err)
+	assert.Equal(t, "[Security]\nPassphrase=password123\n\n[General]\nChannel=36\n", string(data))
+
+	assert.Error(t, writeIWDAPProfile(dir, HotspotConfig{SSID: "../evil", Password: "password123"}))
+}
+
+func TestIWDBackend_HotspotQRCode(t *testing.T) {
+	backend, err := NewIWDBackend()
+	require.NoError(t, err)
+	backend.hotspot = &HotspotConfig{SSID: "Demo", Password: "password123"}
+
+	content, err := backend.GetWiFiQRCodeContent("Demo")
+	require.NoError(t, err)
+	assert.Equal(t, "WIFI:T:WPA;S:Demo;P:password123;;", content)
+}
+
+func TestNetworkManagerBackend_StartHotspot(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockSettings := mock_gonetworkmanager.NewMockSettings(t)
+	mockDevice := mock_gonetworkmanager.NewMockDevice(t)
+	mockWireless := mock_gonetworkmanager.NewMockDeviceWireless(t)
+	mockConn := mock_gonetworkmanager.NewMockConnection(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.settings = mockSettings
+	backend.wifiDevices["wlan0"] = &wifiDeviceInfo{device: mockDevice, wireless: mockWireless, name: "wlan0"}
+
+	mockWireless.EXPECT().GetPropertyWirelessCapabilities().Return(nmWiFiDeviceCapAP, nil)
+	mockSettings.EXPECT().ListConnections().Return(nil, nil)
+	mockSettings.EXPECT().AddConnection(mock.MatchedBy(func(s gonetworkmanager.ConnectionSettings) bool {
+		cfg := hotspotConfigFromSettings(s)
+		return s["802-11-wireless"]["mode"] == "ap" &&
+			s["ipv4"]["method"] == "shared" &&
+			s["802-11-wireless-security"]["psk"] == "password123" &&
+			cfg == HotspotConfig{SSID: "Demo", Band: "a", Device: "wlan0"}
+	})).Return(mockConn, nil)
+	mockNM.EXPECT().ActivateConnection(mockConn, mockDevice, mock.Anything).Return(nil, nil)
+
+	cfg, err := backend.StartHotspot(HotspotConfig{SSID: "Demo", Password: "password123", Band: "5", Device: "wlan0"})
+	require.NoError(t, err)
+	assert.Equal(t, &HotspotConfig{SSID: "Demo", Password: "password123", Band: "a", Device: "wlan0"}, cfg)
+}
+
+func TestNetworkManagerBackend_StartHotspot_NoAPSupport(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockWireless := mock_gonetworkmanager.NewMockDeviceWireless(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.wifiDevices["wlan0"] = &wifiDeviceInfo{wireless: mockWireless, name: "wlan0"}
+
+	mockWireless.EXPECT().GetPropertyWirelessCapabilities().Return(0, nil)
+
+	_, err = backend.StartHotspot(HotspotConfig{Device: "wlan0"})
+	assert.ErrorContains(t, err, "does not support access point mode")
+}
+
+func TestNetworkManagerBackend_GetHotspotConfig_None(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockSettings := mock_gonetworkmanager.NewMockSettings(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.settings = mockSettings
+
+	mockSettings.EXPECT().ListConnections().Return(nil, nil)
+
+	cfg, err := backend.GetHotspotConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &HotspotConfig{SSID: hotspotDefaultSSID}, cfg)
+}
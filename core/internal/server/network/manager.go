@@ -2,6 +2,9 @@ package network
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -95,6 +98,9 @@ func NewManager() (*Manager, error) {
 		return nil, fmt.Errorf("failed to start monitoring: %w", err)
 	}
 
+	m.traffic = newTrafficSampler(trafficUsagePath(), backend.GetInterfaceConnections)
+	m.traffic.Start()
+
 	return m, nil
 }
 
@@ -124,6 +130,8 @@ func (m *Manager) syncStateFromBackend() error {
 	m.state.WiredConnections = backendState.WiredConnections
 	m.state.VPNProfiles = backendState.VPNProfiles
 	m.state.VPNActive = backendState.VPNActive
+	m.state.WWANEnabled = backendState.WWANEnabled
+	m.state.WWANModems = backendState.WWANModems
 	m.state.IsConnecting = backendState.IsConnecting
 	m.state.ConnectingSSID = backendState.ConnectingSSID
 	m.state.ConnectingDevice = backendState.ConnectingDevice
@@ -137,6 +145,9 @@ func (m *Manager) onBackendStateChange() {
 	if err := m.syncStateFromBackend(); err != nil {
 		log.Errorf("failed to sync state from backend: %v", err)
 	}
+	if m.traffic != nil {
+		m.traffic.InvalidateConnections()
+	}
 	m.notifySubscribers()
 }
 
@@ -161,6 +172,7 @@ func (m *Manager) snapshotState() NetworkState {
 	s.EthernetDevices = append([]EthernetDevice(nil), m.state.EthernetDevices...)
 	s.VPNProfiles = append([]VPNProfile(nil), m.state.VPNProfiles...)
 	s.VPNActive = append([]VPNActive(nil), m.state.VPNActive...)
+	s.WWANModems = append([]WWANModem(nil), m.state.WWANModems...)
 	return s
 }
 
@@ -192,6 +204,9 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 	if old.WiFiIP != new.WiFiIP {
 		return true
 	}
+	if wwanChangedMeaningfully(old, new) {
+		return true
+	}
 	if !signalChangeSignificant(old.WiFiSignal, new.WiFiSignal) {
 		if old.WiFiSignal != new.WiFiSignal {
 			return false
@@ -291,6 +306,32 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 	return false
 }
 
+func wwanChangedMeaningfully(old, new *NetworkState) bool {
+	if old.WWANEnabled != new.WWANEnabled {
+		return true
+	}
+	if len(old.WWANModems) != len(new.WWANModems) {
+		return true
+	}
+	for i := range old.WWANModems {
+		oldModem := &old.WWANModems[i]
+		newModem := &new.WWANModems[i]
+		if oldModem.Path != newModem.Path || oldModem.State != newModem.State {
+			return true
+		}
+		if oldModem.Generation != newModem.Generation || oldModem.Operator != newModem.Operator {
+			return true
+		}
+		if oldModem.UnlockRequired != newModem.UnlockRequired || oldModem.Roaming != newModem.Roaming {
+			return true
+		}
+		if oldModem.Signal != newModem.Signal && signalChangeSignificant(oldModem.Signal, newModem.Signal) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) GetState() NetworkState {
 	return m.snapshotState()
 }
@@ -400,6 +441,10 @@ func (m *Manager) Close() {
 	close(m.stopChan)
 	m.notifierWg.Wait()
 
+	if m.traffic != nil {
+		m.traffic.Close()
+	}
+
 	if m.backend != nil {
 		m.backend.Close()
 	}
@@ -601,6 +646,74 @@ func (m *Manager) DeleteVPN(uuidOrName string) error {
 	return m.backend.DeleteVPN(uuidOrName)
 }
 
+func (m *Manager) CreateWireGuard(cfg WireGuardConfig) (*WireGuardConfig, error) {
+	return m.backend.CreateWireGuard(cfg)
+}
+
+// ImportWireGuard creates a WireGuard connection from a wg-quick config.
+// The name defaults to the file name, which wg-quick uses as the interface.
+func (m *Manager) ImportWireGuard(filePath, content, name string) (*WireGuardConfig, error) {
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		content = string(data)
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		}
+	}
+
+	cfg, err := parseWireGuardConf(content)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PrivateKey == "" {
+		return nil, fmt.Errorf("config has no private key")
+	}
+	cfg.Name = name
+	if cfg.Name == "" {
+		cfg.Name = "wireguard"
+	}
+	return m.backend.CreateWireGuard(*cfg)
+}
+
+func (m *Manager) ExportWireGuard(uuidOrName string) (*WireGuardConfig, error) {
+	return m.backend.ExportWireGuard(uuidOrName)
+}
+
+func (m *Manager) GetWWANModems() ([]WWANModem, error) {
+	return m.backend.GetWWANModems()
+}
+
+func (m *Manager) SetWWANEnabled(enabled bool) error {
+	return m.backend.SetWWANEnabled(enabled)
+}
+
+func (m *Manager) UnlockWWAN(modem, pin, puk string) error {
+	return m.backend.UnlockWWAN(modem, pin, puk)
+}
+
+func (m *Manager) ListWWANProfiles() ([]WWANProfile, error) {
+	return m.backend.ListWWANProfiles()
+}
+
+func (m *Manager) SaveWWANProfile(profile WWANProfile) (*WWANProfile, error) {
+	return m.backend.SaveWWANProfile(profile)
+}
+
+func (m *Manager) DeleteWWANProfile(uuid string) error {
+	return m.backend.DeleteWWANProfile(uuid)
+}
+
+func (m *Manager) ConnectWWAN(uuid string) error {
+	return m.backend.ConnectWWAN(uuid)
+}
+
+func (m *Manager) DisconnectWWAN(uuid string) error {
+	return m.backend.DisconnectWWAN(uuid)
+}
+
 func (m *Manager) SetVPNCredentials(uuid, username, password string, save bool) error {
 	return m.backend.SetVPNCredentials(uuid, username, password, save)
 }
@@ -609,6 +722,26 @@ func (m *Manager) SetWiFiAutoconnect(ssid string, autoconnect bool) error {
 	return m.backend.SetWiFiAutoconnect(ssid, autoconnect)
 }
 
+func (m *Manager) GetConnectionSettings(uuid string) (*ConnectionConfig, error) {
+	return m.backend.GetConnectionSettings(uuid)
+}
+
+func (m *Manager) UpdateConnectionSettings(uuid string, cfg ConnectionConfig, reactivate bool) error {
+	return m.backend.UpdateConnectionSettings(uuid, cfg, reactivate)
+}
+
+func (m *Manager) StartHotspot(cfg HotspotConfig) (*HotspotConfig, error) {
+	return m.backend.StartHotspot(cfg)
+}
+
+func (m *Manager) StopHotspot() error {
+	return m.backend.StopHotspot()
+}
+
+func (m *Manager) GetHotspotConfig() (*HotspotConfig, error) {
+	return m.backend.GetHotspotConfig()
+}
+
 func (m *Manager) GetWiFiDevices() []WiFiDevice {
 	m.stateMutex.RLock()
 	defer m.stateMutex.RUnlock()
@@ -624,3 +757,37 @@ func (m *Manager) ScanWiFiDevice(device string) error {
 func (m *Manager) DisconnectWiFiDevice(device string) error {
 	return m.backend.DisconnectWiFiDevice(device)
 }
+
+func (m *Manager) GetTrafficStats(history, daily bool) (TrafficStats, error) {
+	if m.traffic == nil {
+		return TrafficStats{}, fmt.Errorf("traffic statistics not available")
+	}
+	return m.traffic.Stats(history, daily), nil
+}
+
+func (m *Manager) SetTrafficInterval(interval time.Duration) error {
+	if m.traffic == nil {
+		return fmt.Errorf("traffic statistics not available")
+	}
+	return m.traffic.SetInterval(interval)
+}
+
+func (m *Manager) SetDataCap(id string, limit uint64, period string) error {
+	if m.traffic == nil {
+		return fmt.Errorf("traffic statistics not available")
+	}
+	return m.traffic.SetCap(id, limit, period)
+}
+
+func (m *Manager) SubscribeTraffic(id string) chan TrafficStats {
+	if m.traffic == nil {
+		return nil
+	}
+	return m.traffic.Subscribe(id)
+}
+
+func (m *Manager) UnsubscribeTraffic(id string) {
+	if m.traffic != nil {
+		m.traffic.Unsubscribe(id)
+	}
+}
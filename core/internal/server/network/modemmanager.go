@@ -0,0 +1,216 @@
+package network
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	mmBusName           = "org.freedesktop.ModemManager1"
+	mmObjectPath        = "/org/freedesktop/ModemManager1"
+	mmModemInterface    = "org.freedesktop.ModemManager1.Modem"
+	mm3gppInterface     = "org.freedesktop.ModemManager1.Modem.Modem3gpp"
+	mmSimInterface      = "org.freedesktop.ModemManager1.Sim"
+	mmModemStateEnabled = int32(6)
+)
+
+// Indexed by MMModemState + 1, since MM_MODEM_STATE_FAILED is -1.
+var mmModemStates = []string{
+	"failed", "unknown", "initializing", "locked", "disabled", "disabling",
+	"enabling", "enabled", "searching", "registered", "disconnecting",
+	"connecting", "connected",
+}
+
+// Indexed by MMModemLock. none and unknown map to "" so UnlockRequired is
+// only set when there is something to unlock.
+var mmModemLocks = []string{
+	"", "", "sim-pin", "sim-pin2", "sim-puk", "sim-puk2", "ph-sp-pin",
+	"ph-sp-puk", "ph-net-pin", "ph-net-puk", "ph-sim-pin", "ph-corp-pin",
+	"ph-corp-puk", "ph-fsim-pin", "ph-fsim-puk", "ph-netsub-pin", "ph-netsub-puk",
+}
+
+// Indexed by bit position in MMModemAccessTechnology.
+var mmAccessTechnologies = []string{
+	"pots", "gsm", "gsm-compact", "gprs", "edge", "umts", "hsdpa", "hsupa",
+	"hspa", "hspa-plus", "1xrtt", "evdo0", "evdoa", "evdob", "lte", "5gnr",
+	"lte-cat-m", "lte-nb-iot",
+}
+
+// Indexed by MMModem3gppRegistrationState.
+var mm3gppRegistrationStates = []string{
+	"idle", "home", "searching", "denied", "unknown", "roaming", "home-sms-only",
+	"roaming-sms-only", "emergency-only", "home-csfb-not-preferred",
+	"roaming-csfb-not-preferred", "attached-rlos",
+}
+
+// modemManager talks to ModemManager directly for what NetworkManager
+// doesn't expose: signal, registration and SIM unlocking.
+type modemManager struct {
+	conn *dbus.Conn
+}
+
+func newModemManager() (*modemManager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	return &modemManager{conn: conn}, nil
+}
+
+func (m *modemManager) Close() {
+	m.conn.Close()
+}
+
+func (m *modemManager) listModems() ([]WWANModem, error) {
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	obj := m.conn.Object(mmBusName, mmObjectPath)
+	if err := obj.Call(dbusObjectManager+".GetManagedObjects", 0).Store(&objects); err != nil {
+		return nil, fmt.Errorf("failed to list modems: %w", err)
+	}
+
+	modems := []WWANModem{}
+	for objPath, ifaces := range objects {
+		if _, ok := ifaces[mmModemInterface]; ok {
+			modems = append(modems, wwanModemFromProperties(objPath, ifaces))
+		}
+	}
+	sort.Slice(modems, func(i, j int) bool { return modems[i].Path < modems[j].Path })
+	return modems, nil
+}
+
+func (m *modemManager) sendPin(simPath, pin string) error {
+	obj := m.conn.Object(mmBusName, dbus.ObjectPath(simPath))
+	if call := obj.Call(mmSimInterface+".SendPin", 0, pin); call.Err != nil {
+		return call.Err
+	}
+	return nil
+}
+
+func (m *modemManager) sendPuk(simPath, puk, newPin string) error {
+	obj := m.conn.Object(mmBusName, dbus.ObjectPath(simPath))
+	if call := obj.Call(mmSimInterface+".SendPuk", 0, puk, newPin); call.Err != nil {
+		return call.Err
+	}
+	return nil
+}
+
+func wwanModemFromProperties(objPath dbus.ObjectPath, ifaces map[string]map[string]dbus.Variant) WWANModem {
+	props := ifaces[mmModemInterface]
+	modem := WWANModem{
+		ID:                 path.Base(string(objPath)),
+		Path:               string(objPath),
+		State:              "unknown",
+		AccessTechnologies: []string{},
+	}
+
+	modem.Manufacturer, _ = props["Manufacturer"].Value().(string)
+	modem.Model, _ = props["Model"].Value().(string)
+	modem.Device, _ = props["PrimaryPort"].Value().(string)
+	modem.IMEI, _ = props["EquipmentIdentifier"].Value().(string)
+	modem.OwnNumbers, _ = props["OwnNumbers"].Value().([]string)
+
+	if state, ok := props["State"].Value().(int32); ok {
+		if i := int(state) + 1; i >= 0 && i < len(mmModemStates) {
+			modem.State = mmModemStates[i]
+		}
+		modem.Enabled = state >= mmModemStateEnabled
+	}
+
+	if quality, ok := props["SignalQuality"].Value().([]any); ok && len(quality) > 0 {
+		if percent, ok := quality[0].(uint32); ok && percent <= 100 {
+			modem.Signal = uint8(percent)
+		}
+	}
+
+	if tech, ok := props["AccessTechnologies"].Value().(uint32); ok {
+		modem.AccessTechnologies = mmAccessTechnologyNames(tech)
+		modem.Generation = wwanGeneration(modem.AccessTechnologies)
+	}
+
+	if sim, ok := props["Sim"].Value().(dbus.ObjectPath); ok && sim != "/" && sim != "" {
+		modem.SIMPresent = true
+		modem.simPath = string(sim)
+	}
+
+	if lock, ok := props["UnlockRequired"].Value().(uint32); ok && int(lock) < len(mmModemLocks) {
+		modem.UnlockRequired = mmModemLocks[lock]
+	}
+	if retries, ok := props["UnlockRetries"].Value().(map[uint32]uint32); ok && len(retries) > 0 {
+		modem.UnlockRetries = make(map[string]uint32, len(retries))
+		for lock, n := range retries {
+			if int(lock) < len(mmModemLocks) && mmModemLocks[lock] != "" {
+				modem.UnlockRetries[mmModemLocks[lock]] = n
+			}
+		}
+	}
+
+	if gpp, ok := ifaces[mm3gppInterface]; ok {
+		modem.Operator, _ = gpp["OperatorName"].Value().(string)
+		modem.OperatorCode, _ = gpp["OperatorCode"].Value().(string)
+		if imei, ok := gpp["Imei"].Value().(string); ok && imei != "" {
+			modem.IMEI = imei
+		}
+		if reg, ok := gpp["RegistrationState"].Value().(uint32); ok && int(reg) < len(mm3gppRegistrationStates) {
+			modem.Registration = mm3gppRegistrationStates[reg]
+			modem.Roaming = strings.HasPrefix(modem.Registration, "roaming")
+		}
+	}
+
+	return modem
+}
+
+func mmAccessTechnologyNames(mask uint32) []string {
+	names := []string{}
+	for bit, name := range mmAccessTechnologies {
+		if mask&(1<<bit) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// wwanGeneration summarizes access technologies as the marketing
+// generation shown in status bars.
+func wwanGeneration(techs []string) string {
+	best := ""
+	for _, tech := range techs {
+		var gen string
+		switch tech {
+		case "5gnr":
+			gen = "5G"
+		case "lte", "lte-cat-m", "lte-nb-iot":
+			gen = "4G"
+		case "umts", "hsdpa", "hsupa", "hspa", "hspa-plus", "evdo0", "evdoa", "evdob":
+			gen = "3G"
+		case "gsm", "gsm-compact", "gprs", "edge", "1xrtt":
+			gen = "2G"
+		default:
+			continue
+		}
+		if gen > best {
+			best = gen
+		}
+	}
+	return best
+}
+
+// findWWANModem matches id against the modem index, D-Bus path or IMEI.
+// An empty id picks the first modem.
+func findWWANModem(modems []WWANModem, id string) (*WWANModem, error) {
+	if len(modems) == 0 {
+		return nil, fmt.Errorf("no modem found")
+	}
+	if id == "" {
+		return &modems[0], nil
+	}
+	for i := range modems {
+		if modems[i].ID == id || modems[i].Path == id || (modems[i].IMEI != "" && modems[i].IMEI == id) {
+			return &modems[i], nil
+		}
+	}
+	return nil, fmt.Errorf("modem not found: %s", id)
+}
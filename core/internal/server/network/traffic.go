@@ -0,0 +1,602 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+const (
+	defaultTrafficInterval = time.Second
+	minTrafficInterval     = 250 * time.Millisecond
+	maxTrafficInterval     = time.Minute
+	trafficHistorySize     = 120
+	trafficSaveInterval    = time.Minute
+	trafficDaysKept        = 62
+	trafficMonthsKept      = 12
+	dataCapNearPercent     = 80
+)
+
+type interfaceCounters struct {
+	rx      uint64
+	tx      uint64
+	virtual bool
+}
+
+type interfaceTrafficState struct {
+	counters interfaceCounters
+	rxRate   uint64
+	txRate   uint64
+	history  []TrafficSample
+}
+
+// connectionUsageRecord holds daily and monthly totals, keyed by
+// "2006-01-02" and "2006-01" in local time.
+type connectionUsageRecord struct {
+	Name    string                   `json:"name"`
+	Type    string                   `json:"type,omitempty"`
+	Metered bool                     `json:"metered"`
+	Days    map[string]TrafficTotals `json:"days"`
+	Months  map[string]TrafficTotals `json:"months"`
+	Cap     *DataCap                 `json:"cap,omitempty"`
+}
+
+type trafficUsageFile struct {
+	Interval    float64                           `json:"interval,omitempty"`
+	Connections map[string]*connectionUsageRecord `json:"connections"`
+}
+
+// trafficSampler reads interface counters on a timer, keeps recent rates
+// in memory and accumulates per-connection totals on disk. Usage on an
+// interface is attributed to the connection active on it; virtual
+// interfaces without one, like bridges and veths, aren't counted.
+type trafficSampler struct {
+	mu          sync.RWMutex
+	interval    time.Duration
+	ifaces      map[string]*interfaceTrafficState
+	links       map[string]InterfaceConnection
+	usage       *trafficUsageFile
+	lastSample  time.Time
+	lastSave    time.Time
+	usageDirty  bool
+	capWarnings map[string]string
+
+	path           string
+	readCounters   func() map[string]interfaceCounters
+	getConnections func() map[string]InterfaceConnection
+	now            func() time.Time
+
+	linksStale      atomic.Bool
+	intervalChanged chan struct{}
+	subscribers     syncmap.Map[string, chan TrafficStats]
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
+}
+
+func newTrafficSampler(path string, getConnections func() map[string]InterfaceConnection) *trafficSampler {
+	s := &trafficSampler{
+		interval:        defaultTrafficInterval,
+		ifaces:          make(map[string]*interfaceTrafficState),
+		usage:           loadTrafficUsage(path),
+		capWarnings:     make(map[string]string),
+		path:            path,
+		readCounters:    readSysfsCounters,
+		getConnections:  getConnections,
+		now:             time.Now,
+		intervalChanged: make(chan struct{}, 1),
+		stopChan:        make(chan struct{}),
+	}
+
+	if d := time.Duration(s.usage.Interval * float64(time.Second)); d >= minTrafficInterval && d <= maxTrafficInterval {
+		s.interval = d
+	}
+	s.linksStale.Store(true)
+
+	return s
+}
+
+func trafficUsagePath() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "network-usage.json")
+}
+
+func (s *trafficSampler) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+func (s *trafficSampler) run() {
+	defer s.wg.Done()
+
+	s.sample()
+
+	s.mu.RLock()
+	ticker := time.NewTicker(s.interval)
+	s.mu.RUnlock()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			s.save()
+			return
+		case <-s.intervalChanged:
+			s.mu.RLock()
+			ticker.Reset(s.interval)
+			s.mu.RUnlock()
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *trafficSampler) Close() {
+	close(s.stopChan)
+	s.wg.Wait()
+
+	s.subscribers.Range(func(key string, ch chan TrafficStats) bool {
+		close(ch)
+		s.subscribers.Delete(key)
+		return true
+	})
+}
+
+// InvalidateConnections makes the next sample re-read which connection is
+// active on each interface.
+func (s *trafficSampler) InvalidateConnections() {
+	s.linksStale.Store(true)
+}
+
+func (s *trafficSampler) sample() {
+	counters := s.readCounters()
+
+	var links map[string]InterfaceConnection
+	refresh := s.linksStale.Swap(false)
+	if refresh && s.getConnections != nil {
+		links = s.getConnections()
+	}
+
+	now := s.now()
+
+	s.mu.Lock()
+	if refresh {
+		s.links = links
+	}
+
+	elapsed := now.Sub(s.lastSample).Seconds()
+	for name, c := range counters {
+		st, ok := s.ifaces[name]
+		if !ok {
+			s.ifaces[name] = &interfaceTrafficState{counters: c}
+			continue
+		}
+
+		rx := counterDelta(st.counters.rx, c.rx)
+		tx := counterDelta(st.counters.tx, c.tx)
+		st.counters = c
+		if elapsed > 0 {
+			st.rxRate = uint64(float64(rx) / elapsed)
+			st.txRate = uint64(float64(tx) / elapsed)
+		}
+
+		st.history = append(st.history, TrafficSample{Time: now.UnixMilli(), RxRate: st.rxRate, TxRate: st.txRate})
+		if len(st.history) > trafficHistorySize {
+			st.history = st.history[len(st.history)-trafficHistorySize:]
+		}
+
+		if rx > 0 || tx > 0 {
+			s.addUsageLocked(name, rx, tx, now)
+		}
+	}
+	for name := range s.ifaces {
+		if _, ok := counters[name]; !ok {
+			delete(s.ifaces, name)
+		}
+	}
+	s.lastSample = now
+
+	s.checkCapsLocked(now)
+
+	var data []byte
+	if s.usageDirty && now.Sub(s.lastSave) >= trafficSaveInterval {
+		data = s.marshalUsageLocked(now)
+	}
+
+	stats := s.statsLocked(now, false, false)
+	s.mu.Unlock()
+
+	if data != nil {
+		writeTrafficUsage(s.path, data)
+	}
+
+	s.subscribers.Range(func(key string, ch chan TrafficStats) bool {
+		select {
+		case ch <- stats:
+		default:
+		}
+		return true
+	})
+}
+
+// counterDelta treats a counter going backwards as the interface having
+// been recreated, so everything it counted is new.
+func counterDelta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// connectionIDLocked returns the usage key for an interface: the
+// connection UUID, type and name on backends without UUIDs, or the
+// interface name for physical interfaces without a connection.
+func (s *trafficSampler) connectionIDLocked(iface string) (string, InterfaceConnection) {
+	if link, ok := s.links[iface]; ok {
+		if link.UUID != "" {
+			return link.UUID, link
+		}
+		return link.Type + ":" + link.Name, link
+	}
+	if st, ok := s.ifaces[iface]; ok && !st.counters.virtual {
+		return "iface:" + iface, InterfaceConnection{Name: iface}
+	}
+	return "", InterfaceConnection{}
+}
+
+func (s *trafficSampler) addUsageLocked(iface string, rx, tx uint64, now time.Time) {
+	id, link := s.connectionIDLocked(iface)
+	if id == "" {
+		return
+	}
+
+	rec := s.usage.Connections[id]
+	if rec == nil {
+		rec = &connectionUsageRecord{
+			Days:   make(map[string]TrafficTotals),
+			Months: make(map[string]TrafficTotals),
+		}
+		s.usage.Connections[id] = rec
+	}
+	rec.Name = link.Name
+	rec.Type = link.Type
+	rec.Metered = link.Metered
+
+	day, month := now.Format(time.DateOnly), now.Format("2006-01")
+	d := rec.Days[day]
+	d.Rx += rx
+	d.Tx += tx
+	rec.Days[day] = d
+	m := rec.Months[month]
+	m.Rx += rx
+	m.Tx += tx
+	rec.Months[month] = m
+
+	s.usageDirty = true
+}
+
+func (s *trafficSampler) checkCapsLocked(now time.Time) {
+	for id, rec := range s.usage.Connections {
+		warning := dataCapWarning(rec, now)
+		if warning == s.capWarnings[id] {
+			continue
+		}
+		s.capWarnings[id] = warning
+		if warning != "" {
+			log.Warnf("Data cap %s on %s", warning, rec.Name)
+		}
+	}
+}
+
+func dataCapWarning(rec *connectionUsageRecord, now time.Time) string {
+	if rec.Cap == nil || rec.Cap.Limit == 0 {
+		return ""
+	}
+
+	used := rec.Months[now.Format("2006-01")]
+	if rec.Cap.Period == "day" {
+		used = rec.Days[now.Format(time.DateOnly)]
+	}
+
+	total := used.Rx + used.Tx
+	switch {
+	case total >= rec.Cap.Limit:
+		return "exceeded"
+	case total*100 >= rec.Cap.Limit*dataCapNearPercent:
+		return "near"
+	default:
+		return ""
+	}
+}
+
+func (s *trafficSampler) Stats(history, daily bool) TrafficStats {
+	now := s.now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.statsLocked(now, history, daily)
+}
+
+func (s *trafficSampler) statsLocked(now time.Time, history, daily bool) TrafficStats {
+	stats := TrafficStats{
+		Interval:    s.interval.Seconds(),
+		Interfaces:  []InterfaceTraffic{},
+		Connections: []ConnectionUsage{},
+	}
+
+	names := make([]string, 0, len(s.ifaces))
+	for name := range s.ifaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active := make(map[string]string)
+	for _, name := range names {
+		st := s.ifaces[name]
+		id, _ := s.connectionIDLocked(name)
+		if id != "" {
+			active[id] = name
+		}
+
+		iface := InterfaceTraffic{
+			Name:       name,
+			Virtual:    st.counters.virtual,
+			Connection: id,
+			RxBytes:    st.counters.rx,
+			TxBytes:    st.counters.tx,
+			RxRate:     st.rxRate,
+			TxRate:     st.txRate,
+		}
+		if history {
+			iface.History = slices.Clone(st.history)
+		}
+		if !st.counters.virtual {
+			stats.RxRate += st.rxRate
+			stats.TxRate += st.txRate
+		}
+		stats.Interfaces = append(stats.Interfaces, iface)
+	}
+
+	day, month := now.Format(time.DateOnly), now.Format("2006-01")
+	for id, rec := range s.usage.Connections {
+		usage := ConnectionUsage{
+			ID:         id,
+			Name:       rec.Name,
+			Type:       rec.Type,
+			Interface:  active[id],
+			Metered:    rec.Metered,
+			Today:      rec.Days[day],
+			Month:      rec.Months[month],
+			CapWarning: dataCapWarning(rec, now),
+		}
+		if rec.Cap != nil {
+			c := *rec.Cap
+			usage.Cap = &c
+		}
+		if daily {
+			usage.Daily = dailyUsage(rec.Days)
+		}
+		stats.Connections = append(stats.Connections, usage)
+	}
+	sort.Slice(stats.Connections, func(i, j int) bool {
+		a, b := stats.Connections[i], stats.Connections[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	return stats
+}
+
+func dailyUsage(days map[string]TrafficTotals) []DailyUsage {
+	out := make([]DailyUsage, 0, len(days))
+	for date, t := range days {
+		out = append(out, DailyUsage{Date: date, Rx: t.Rx, Tx: t.Tx})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out
+}
+
+func (s *trafficSampler) SetInterval(interval time.Duration) error {
+	if interval < minTrafficInterval || interval > maxTrafficInterval {
+		return fmt.Errorf("interval must be between %v and %v", minTrafficInterval, maxTrafficInterval)
+	}
+
+	s.mu.Lock()
+	s.interval = interval
+	s.usage.Interval = interval.Seconds()
+	s.usageDirty = true
+	s.mu.Unlock()
+
+	select {
+	case s.intervalChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// SetCap sets the data cap of a connection, or removes it when the limit
+// is zero. The connection must have been seen before.
+func (s *trafficSampler) SetCap(id string, limit uint64, period string) error {
+	if period == "" {
+		period = "month"
+	}
+	if period != "day" && period != "month" {
+		return fmt.Errorf("invalid period: %s", period)
+	}
+
+	s.mu.Lock()
+	rec := s.usage.Connections[id]
+	if rec == nil {
+		for iface := range s.ifaces {
+			if activeID, link := s.connectionIDLocked(iface); activeID == id {
+				rec = &connectionUsageRecord{
+					Name:    link.Name,
+					Type:    link.Type,
+					Metered: link.Metered,
+					Days:    make(map[string]TrafficTotals),
+					Months:  make(map[string]TrafficTotals),
+				}
+				s.usage.Connections[id] = rec
+				break
+			}
+		}
+	}
+	if rec == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("unknown connection: %s", id)
+	}
+
+	rec.Cap = nil
+	if limit > 0 {
+		rec.Cap = &DataCap{Limit: limit, Period: period}
+	}
+	data := s.marshalUsageLocked(s.now())
+	s.mu.Unlock()
+
+	writeTrafficUsage(s.path, data)
+	return nil
+}
+
+func (s *trafficSampler) save() {
+	s.mu.Lock()
+	if !s.usageDirty {
+		s.mu.Unlock()
+		return
+	}
+	data := s.marshalUsageLocked(s.now())
+	s.mu.Unlock()
+
+	writeTrafficUsage(s.path, data)
+}
+
+func (s *trafficSampler) marshalUsageLocked(now time.Time) []byte {
+	pruneTrafficUsage(s.usage, now)
+
+	data, err := json.MarshalIndent(s.usage, "", "  ")
+	if err != nil {
+		log.Warnf("Failed to encode network usage: %v", err)
+		return nil
+	}
+
+	s.usageDirty = false
+	s.lastSave = now
+	return data
+}
+
+// pruneTrafficUsage drops days and months past retention, then connections
+// left with neither totals nor a cap.
+func pruneTrafficUsage(usage *trafficUsageFile, now time.Time) {
+	oldestDay := now.AddDate(0, 0, -trafficDaysKept).Format(time.DateOnly)
+	oldestMonth := now.AddDate(0, -trafficMonthsKept, 0).Format("2006-01")
+
+	for id, rec := range usage.Connections {
+		for day := range rec.Days {
+			if day < oldestDay {
+				delete(rec.Days, day)
+			}
+		}
+		for month := range rec.Months {
+			if month < oldestMonth {
+				delete(rec.Months, month)
+			}
+		}
+		if len(rec.Days) == 0 && len(rec.Months) == 0 && rec.Cap == nil {
+			delete(usage.Connections, id)
+		}
+	}
+}
+
+func (s *trafficSampler) Subscribe(id string) chan TrafficStats {
+	ch := make(chan TrafficStats, 16)
+	s.subscribers.Store(id, ch)
+	return ch
+}
+
+func (s *trafficSampler) Unsubscribe(id string) {
+	if ch, ok := s.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func readSysfsCounters() map[string]interfaceCounters {
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return nil
+	}
+
+	counters := make(map[string]interfaceCounters, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "lo" {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(sysClassNet, name, "device"))
+		counters[name] = interfaceCounters{
+			rx:      readInterfaceCounter(name, "rx_bytes"),
+			tx:      readInterfaceCounter(name, "tx_bytes"),
+			virtual: err != nil,
+		}
+	}
+	return counters
+}
+
+func loadTrafficUsage(path string) *trafficUsageFile {
+	usage := &trafficUsageFile{Connections: make(map[string]*connectionUsageRecord)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Failed to read network usage %s: %v", path, err)
+		}
+		return usage
+	}
+
+	if err := json.Unmarshal(data, usage); err != nil {
+		log.Warnf("Invalid network usage file %s: %v", path, err)
+		return &trafficUsageFile{Connections: make(map[string]*connectionUsageRecord)}
+	}
+
+	if usage.Connections == nil {
+		usage.Connections = make(map[string]*connectionUsageRecord)
+	}
+	for id, rec := range usage.Connections {
+		if rec == nil {
+			delete(usage.Connections, id)
+			continue
+		}
+		if rec.Days == nil {
+			rec.Days = make(map[string]TrafficTotals)
+		}
+		if rec.Months == nil {
+			rec.Months = make(map[string]TrafficTotals)
+		}
+	}
+	return usage
+}
+
+func writeTrafficUsage(path string, data []byte) {
+	if path == "" || data == nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Warnf("Failed to save network usage: %v", err)
+		return
+	}
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		log.Warnf("Failed to save network usage: %v", err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Warnf("Failed to save network usage: %v", err)
+	}
+}
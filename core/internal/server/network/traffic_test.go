@@ -0,0 +1,211 @@
+package network
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTraffic struct {
+	now      time.Time
+	counters map[string]interfaceCounters
+	links    map[string]InterfaceConnection
+}
+
+func newTestTrafficSampler(t *testing.T, path string) (*trafficSampler, *fakeTraffic) {
+	t.Helper()
+
+	fake := &fakeTraffic{
+		now: time.Date(2026, 3, 31, 23, 59, 0, 0, time.Local),
+		counters: map[string]interfaceCounters{
+			"wlan0":   {rx: 1000, tx: 500},
+			"docker0": {rx: 100, tx: 100, virtual: true},
+		},
+		links: map[string]InterfaceConnection{
+			"wlan0": {UUID: "home-uuid", Name: "Home", Type: "802-11-wireless"},
+		},
+	}
+
+	s := newTrafficSampler(path, func() map[string]InterfaceConnection { return fake.links })
+	s.readCounters = func() map[string]interfaceCounters { return fake.counters }
+	s.now = func() time.Time { return fake.now }
+	return s, fake
+}
+
+func (f *fakeTraffic) advance(d time.Duration, rx, tx uint64) {
+	f.now = f.now.Add(d)
+	c := f.counters["wlan0"]
+	c.rx += rx
+	c.tx += tx
+	f.counters["wlan0"] = c
+}
+
+func TestTrafficSampler_RatesAndTotals(t *testing.T) {
+	s, fake := newTestTrafficSampler(t, "")
+
+	s.sample()
+	stats := s.Stats(true, false)
+	require.Len(t, stats.Interfaces, 2)
+	assert.Empty(t, stats.Connections)
+
+	fake.advance(2*time.Second, 4000, 2000)
+	s.sample()
+
+	stats = s.Stats(true, false)
+	assert.Equal(t, "docker0", stats.Interfaces[0].Name)
+	assert.True(t, stats.Interfaces[0].Virtual)
+	assert.Empty(t, stats.Interfaces[0].Connection)
+
+	wlan := stats.Interfaces[1]
+	assert.Equal(t, "home-uuid", wlan.Connection)
+	assert.Equal(t, uint64(2000), wlan.RxRate)
+	assert.Equal(t, uint64(1000), wlan.TxRate)
+	assert.Len(t, wlan.History, 1)
+	assert.Equal(t, uint64(2000), stats.RxRate)
+
+	require.Len(t, stats.Connections, 1)
+	home := stats.Connections[0]
+	assert.Equal(t, "Home", home.Name)
+	assert.Equal(t, "wlan0", home.Interface)
+	assert.Equal(t, TrafficTotals{Rx: 4000, Tx: 2000}, home.Today)
+	assert.Equal(t, TrafficTotals{Rx: 4000, Tx: 2000}, home.Month)
+	assert.Nil(t, home.Daily)
+}
+
+func TestTrafficSampler_DayAndMonthRollover(t *testing.T) {
+	s, fake := newTestTrafficSampler(t, "")
+
+	s.sample()
+	fake.advance(time.Second, 100, 0)
+	s.sample()
+	fake.advance(time.Minute, 50, 0)
+	s.sample()
+
+	stats := s.Stats(false, true)
+	require.Len(t, stats.Connections, 1)
+	home := stats.Connections[0]
+	assert.Equal(t, TrafficTotals{Rx: 50}, home.Today)
+	assert.Equal(t, TrafficTotals{Rx: 50}, home.Month)
+	assert.Equal(t, []DailyUsage{
+		{Date: "2026-03-31", Rx: 100},
+		{Date: "2026-04-01", Rx: 50},
+	}, home.Daily)
+}
+
+func TestTrafficSampler_CounterReset(t *testing.T) {
+	s, fake := newTestTrafficSampler(t, "")
+
+	s.sample()
+	fake.now = fake.now.Add(time.Second)
+	fake.counters["wlan0"] = interfaceCounters{rx: 300, tx: 0}
+	s.sample()
+
+	stats := s.Stats(false, false)
+	require.Len(t, stats.Connections, 1)
+	assert.Equal(t, TrafficTotals{Rx: 300}, stats.Connections[0].Today)
+}
+
+func TestTrafficSampler_UnattributedInterfaces(t *testing.T) {
+	s, fake := newTestTrafficSampler(t, "")
+	fake.links = nil
+
+	s.sample()
+	fake.advance(time.Second, 100, 100)
+	c := fake.counters["docker0"]
+	c.rx += 500
+	fake.counters["docker0"] = c
+	s.sample()
+
+	stats := s.Stats(false, false)
+	require.Len(t, stats.Connections, 1)
+	assert.Equal(t, "iface:wlan0", stats.Connections[0].ID)
+	assert.Equal(t, TrafficTotals{Rx: 100, Tx: 100}, stats.Connections[0].Today)
+}
+
+func TestTrafficSampler_DataCap(t *testing.T) {
+	s, fake := newTestTrafficSampler(t, "")
+	fake.links["wlan0"] = InterfaceConnection{UUID: "phone-uuid", Name: "Phone", Type: "gsm", Metered: true}
+
+	s.sample()
+	assert.Error(t, s.SetCap("unknown", 1000, ""))
+	assert.Error(t, s.SetCap("phone-uuid", 1000, "week"))
+	require.NoError(t, s.SetCap("phone-uuid", 1000, ""))
+
+	stats := s.Stats(false, false)
+	require.Len(t, stats.Connections, 1)
+	assert.True(t, stats.Connections[0].Metered)
+	assert.Equal(t, &DataCap{Limit: 1000, Period: "month"}, stats.Connections[0].Cap)
+	assert.Empty(t, stats.Connections[0].CapWarning)
+
+	fake.advance(time.Second, 500, 300)
+	s.sample()
+	assert.Equal(t, "near", s.Stats(false, false).Connections[0].CapWarning)
+
+	fake.advance(time.Second, 200, 0)
+	s.sample()
+	assert.Equal(t, "exceeded", s.Stats(false, false).Connections[0].CapWarning)
+
+	require.NoError(t, s.SetCap("phone-uuid", 0, ""))
+	stats = s.Stats(false, false)
+	assert.Nil(t, stats.Connections[0].Cap)
+	assert.Empty(t, stats.Connections[0].CapWarning)
+}
+
+func TestTrafficSampler_SetInterval(t *testing.T) {
+	s, _ := newTestTrafficSampler(t, "")
+
+	assert.Error(t, s.SetInterval(100*time.Millisecond))
+	assert.Error(t, s.SetInterval(2*time.Minute))
+	require.NoError(t, s.SetInterval(5*time.Second))
+	assert.Equal(t, 5.0, s.Stats(false, false).Interval)
+}
+
+func TestTrafficSampler_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network-usage.json")
+
+	s, fake := newTestTrafficSampler(t, path)
+	require.NoError(t, s.SetInterval(2*time.Second))
+	s.sample()
+	fake.advance(time.Second, 700, 300)
+	s.sample()
+	require.NoError(t, s.SetCap("home-uuid", 5000, "day"))
+	s.save()
+
+	loaded, _ := newTestTrafficSampler(t, path)
+	stats := loaded.Stats(false, false)
+	assert.Equal(t, 2.0, stats.Interval)
+	require.Len(t, stats.Connections, 1)
+	home := stats.Connections[0]
+	assert.Equal(t, "home-uuid", home.ID)
+	assert.Empty(t, home.Interface)
+	assert.Equal(t, TrafficTotals{Rx: 700, Tx: 300}, home.Today)
+	assert.Equal(t, &DataCap{Limit: 5000, Period: "day"}, home.Cap)
+}
+
+func TestPruneTrafficUsage(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.Local)
+	usage := &trafficUsageFile{Connections: map[string]*connectionUsageRecord{
+		"old": {
+			Days:   map[string]TrafficTotals{"2025-01-01": {Rx: 1}},
+			Months: map[string]TrafficTotals{"2025-01": {Rx: 1}},
+		},
+		"current": {
+			Days:   map[string]TrafficTotals{"2026-01-01": {Rx: 1}, "2026-06-14": {Rx: 2}},
+			Months: map[string]TrafficTotals{"2026-06": {Rx: 2}},
+		},
+		"capped": {
+			Days:   map[string]TrafficTotals{},
+			Months: map[string]TrafficTotals{},
+			Cap:    &DataCap{Limit: 1, Period: "month"},
+		},
+	}}
+
+	pruneTrafficUsage(usage, now)
+
+	assert.NotContains(t, usage.Connections, "old")
+	assert.Contains(t, usage.Connections, "capped")
+	assert.Equal(t, map[string]TrafficTotals{"2026-06-14": {Rx: 2}}, usage.Connections["current"].Days)
+}
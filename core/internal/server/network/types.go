@@ -87,6 +87,11 @@ type VPNActive struct {
 	Username   string            `json:"username,omitempty"`
 	MTU        uint32            `json:"mtu,omitempty"`
 	Data       map[string]string `json:"data,omitempty"`
+
+	// WireGuard connections only
+	RxBytes uint64               `json:"rxBytes,omitempty"`
+	TxBytes uint64               `json:"txBytes,omitempty"`
+	Peers   []WireGuardPeerStats `json:"peers,omitempty"`
 }
 
 type VPNState struct {
@@ -115,6 +120,8 @@ type NetworkState struct {
 	WiredConnections       []WiredConnection    `json:"wiredConnections"`
 	VPNProfiles            []VPNProfile         `json:"vpnProfiles"`
 	VPNActive              []VPNActive          `json:"vpnActive"`
+	WWANEnabled            bool                 `json:"wwanEnabled"`
+	WWANModems             []WWANModem          `json:"wwanModems"`
 	IsConnecting           bool                 `json:"isConnecting"`
 	ConnectingSSID         string               `json:"connectingSSID"`
 	ConnectingDevice       string               `json:"connectingDevice,omitempty"`
@@ -138,6 +145,16 @@ type ConnectionRequest struct {
 	UseSystemCACerts  *bool  `json:"useSystemCACerts,omitempty"`
 }
 
+// HotspotConfig describes an access point shared from a WiFi device. Band is
+// "bg" (2.4 GHz), "a" (5 GHz) or empty to let the backend choose.
+type HotspotConfig struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"password"`
+	Band     string `json:"band"`
+	Device   string `json:"device,omitempty"`
+	Active   bool   `json:"active"`
+}
+
 type WiredConnection struct {
 	Path     dbus.ObjectPath `json:"path"`
 	ID       string          `json:"id"`
@@ -160,6 +177,7 @@ type Manager struct {
 	notifierWg            sync.WaitGroup
 	lastNotifiedState     *NetworkState
 	credentialSubscribers syncmap.Map[string, chan CredentialPrompt]
+	traffic               *trafficSampler
 }
 
 type EventType string
@@ -258,6 +276,51 @@ type VPNConfig struct {
 	Data        map[string]string `json:"data,omitempty"`
 }
 
+// ConnectionConfig is the editable part of a saved connection profile.
+// Metered is "auto", "yes" or "no". MACAddress is "default", "permanent",
+// "preserve", "random", "stable" or a literal address, and only applies to
+// WiFi and ethernet profiles.
+type ConnectionConfig struct {
+	UUID        string        `json:"uuid"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Autoconnect bool          `json:"autoconnect"`
+	Metered     string        `json:"metered"`
+	MACAddress  string        `json:"macAddress,omitempty"`
+	IPv4        IPSettings    `json:"ipv4"`
+	IPv6        IPSettings    `json:"ipv6"`
+	Proxy       ProxySettings `json:"proxy"`
+}
+
+// IPSettings holds addresses and routes in CIDR notation.
+type IPSettings struct {
+	Method        string          `json:"method"`
+	Addresses     []string        `json:"addresses"`
+	Gateway       string          `json:"gateway,omitempty"`
+	DNS           []string        `json:"dns"`
+	DNSSearch     []string        `json:"dnsSearch"`
+	IgnoreAutoDNS bool            `json:"ignoreAutoDns"`
+	Routes        []RouteSettings `json:"routes"`
+}
+
+type RouteSettings struct {
+	Dest    string `json:"dest"`
+	NextHop string `json:"nextHop,omitempty"`
+	Metric  uint32 `json:"metric,omitempty"`
+}
+
+// ProxySettings is "none", "pac" (PACURL or PACScript) or "manual"
+// (Host, Port and Bypass).
+type ProxySettings struct {
+	Method      string   `json:"method"`
+	PACURL      string   `json:"pacUrl,omitempty"`
+	PACScript   string   `json:"pacScript,omitempty"`
+	Host        string   `json:"host,omitempty"`
+	Port        int      `json:"port,omitempty"`
+	Bypass      []string `json:"bypass,omitempty"`
+	BrowserOnly bool     `json:"browserOnly"`
+}
+
 type VPNImportResult struct {
 	Success     bool   `json:"success"`
 	UUID        string `json:"uuid,omitempty"`
@@ -265,3 +328,154 @@ type VPNImportResult struct {
 	ServiceType string `json:"serviceType,omitempty"`
 	Error       string `json:"error,omitempty"`
 }
+
+// WireGuardConfig mirrors a wg-quick .conf file. DNS entries that aren't
+// IP addresses are search domains, as in wg-quick.
+type WireGuardConfig struct {
+	UUID          string          `json:"uuid,omitempty"`
+	Name          string          `json:"name"`
+	InterfaceName string          `json:"interfaceName,omitempty"`
+	Autoconnect   bool            `json:"autoconnect"`
+	PrivateKey    string          `json:"privateKey,omitempty"`
+	PublicKey     string          `json:"publicKey,omitempty"`
+	Addresses     []string        `json:"addresses"`
+	DNS           []string        `json:"dns,omitempty"`
+	DNSSearch     []string        `json:"dnsSearch,omitempty"`
+	ListenPort    uint32          `json:"listenPort,omitempty"`
+	MTU           uint32          `json:"mtu,omitempty"`
+	FwMark        uint32          `json:"fwmark,omitempty"`
+	Peers         []WireGuardPeer `json:"peers"`
+}
+
+type WireGuardPeer struct {
+	PublicKey           string   `json:"publicKey"`
+	PresharedKey        string   `json:"presharedKey,omitempty"`
+	AllowedIPs          []string `json:"allowedIps"`
+	Endpoint            string   `json:"endpoint,omitempty"`
+	PersistentKeepalive uint32   `json:"persistentKeepalive,omitempty"`
+}
+
+type WireGuardKeypair struct {
+	PrivateKey string `json:"privateKey"`
+	PublicKey  string `json:"publicKey"`
+}
+
+// WireGuardPeerStats is live peer state. LatestHandshake is a unix
+// timestamp, zero if there hasn't been one.
+type WireGuardPeerStats struct {
+	PublicKey       string `json:"publicKey"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	LatestHandshake int64  `json:"latestHandshake"`
+	RxBytes         uint64 `json:"rxBytes"`
+	TxBytes         uint64 `json:"txBytes"`
+}
+
+// WWANModem is a ModemManager modem. Signal is a percentage, Generation
+// is "2G" to "5G" or empty when not registered.
+type WWANModem struct {
+	ID                 string            `json:"id"`
+	Path               string            `json:"path"`
+	Manufacturer       string            `json:"manufacturer"`
+	Model              string            `json:"model"`
+	Device             string            `json:"device"`
+	IMEI               string            `json:"imei,omitempty"`
+	State              string            `json:"state"`
+	Enabled            bool              `json:"enabled"`
+	Signal             uint8             `json:"signal"`
+	AccessTechnologies []string          `json:"accessTechnologies"`
+	Generation         string            `json:"generation"`
+	Operator           string            `json:"operator"`
+	OperatorCode       string            `json:"operatorCode,omitempty"`
+	Registration       string            `json:"registration,omitempty"`
+	Roaming            bool              `json:"roaming"`
+	SIMPresent         bool              `json:"simPresent"`
+	UnlockRequired     string            `json:"unlockRequired,omitempty"`
+	UnlockRetries      map[string]uint32 `json:"unlockRetries,omitempty"`
+	OwnNumbers         []string          `json:"ownNumbers,omitempty"`
+
+	simPath string
+}
+
+// WWANProfile is an APN profile stored as a NetworkManager gsm connection.
+// Password is write-only.
+type WWANProfile struct {
+	UUID         string `json:"uuid,omitempty"`
+	Name         string `json:"name"`
+	APN          string `json:"apn"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	Autoconnect  bool   `json:"autoconnect"`
+	AllowRoaming bool   `json:"allowRoaming"`
+	Device       string `json:"device,omitempty"`
+	Active       bool   `json:"active"`
+}
+
+// InterfaceConnection is the connection active on a network interface.
+// UUID is empty on backends without connection profiles.
+type InterfaceConnection struct {
+	UUID    string
+	Name    string
+	Type    string
+	Metered bool
+}
+
+// TrafficStats is the network.stats payload. Rates are bytes per second.
+// RxRate and TxRate only sum physical interfaces, so traffic through a
+// VPN isn't counted twice.
+type TrafficStats struct {
+	Interval    float64            `json:"interval"`
+	RxRate      uint64             `json:"rxRate"`
+	TxRate      uint64             `json:"txRate"`
+	Interfaces  []InterfaceTraffic `json:"interfaces"`
+	Connections []ConnectionUsage  `json:"connections"`
+}
+
+type InterfaceTraffic struct {
+	Name       string          `json:"name"`
+	Virtual    bool            `json:"virtual"`
+	Connection string          `json:"connection,omitempty"`
+	RxBytes    uint64          `json:"rxBytes"`
+	TxBytes    uint64          `json:"txBytes"`
+	RxRate     uint64          `json:"rxRate"`
+	TxRate     uint64          `json:"txRate"`
+	History    []TrafficSample `json:"history,omitempty"`
+}
+
+// TrafficSample is one rate sample. Time is in unix milliseconds.
+type TrafficSample struct {
+	Time   int64  `json:"time"`
+	RxRate uint64 `json:"rxRate"`
+	TxRate uint64 `json:"txRate"`
+}
+
+type TrafficTotals struct {
+	Rx uint64 `json:"rx"`
+	Tx uint64 `json:"tx"`
+}
+
+type DailyUsage struct {
+	Date string `json:"date"`
+	Rx   uint64 `json:"rx"`
+	Tx   uint64 `json:"tx"`
+}
+
+// ConnectionUsage is the data used on a connection. CapWarning is "near"
+// from 80% of the cap and "exceeded" past it.
+type ConnectionUsage struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type,omitempty"`
+	Interface  string        `json:"interface,omitempty"`
+	Metered    bool          `json:"metered"`
+	Today      TrafficTotals `json:"today"`
+	Month      TrafficTotals `json:"month"`
+	Daily      []DailyUsage  `json:"daily,omitempty"`
+	Cap        *DataCap      `json:"cap,omitempty"`
+	CapWarning string        `json:"capWarning,omitempty"`
+}
+
+// DataCap limits received plus sent bytes per "day" or calendar "month".
+type DataCap struct {
+	Limit  uint64 `json:"limit"`
+	Period string `json:"period"`
+}
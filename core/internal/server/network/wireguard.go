@@ -0,0 +1,363 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"golang.org/x/crypto/curve25519"
+)
+
+const sysClassNet = "/sys/class/net"
+
+var wireGuardIfaceChars = regexp.MustCompile(`[^a-zA-Z0-9_=+.-]`)
+
+func generateWireGuardKeypair() (*WireGuardKeypair, error) {
+	priv := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(priv); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	// Clamp the same way `wg genkey` does.
+	priv[0] &= 248
+	priv[31] = (priv[31] & 127) | 64
+
+	privateKey := base64.StdEncoding.EncodeToString(priv)
+	publicKey, err := wireGuardPublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &WireGuardKeypair{PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+func wireGuardPublicKey(privateKey string) (string, error) {
+	priv, err := decodeWireGuardKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	pub, err := curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid WireGuard private key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(pub), nil
+}
+
+func decodeWireGuardKey(key string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(raw) != curve25519.PointSize {
+		return nil, fmt.Errorf("invalid WireGuard key")
+	}
+	return raw, nil
+}
+
+// wireGuardInterfaceName derives a kernel interface name from a connection
+// name, the way wg-quick uses the config file name.
+func wireGuardInterfaceName(name string) string {
+	iface := wireGuardIfaceChars.ReplaceAllString(name, "")
+	if len(iface) > 15 {
+		iface = iface[:15]
+	}
+	if iface == "" {
+		return "wg0"
+	}
+	return iface
+}
+
+// parseWireGuardConf reads a wg-quick config. wg-quick's hooks and routing
+// table options have no NetworkManager equivalent and are skipped.
+func parseWireGuardConf(data string) (*WireGuardConfig, error) {
+	cfg := &WireGuardConfig{}
+	section := ""
+
+	for n, line := range strings.Split(data, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			switch section {
+			case "interface":
+			case "peer":
+				cfg.Peers = append(cfg.Peers, WireGuardPeer{})
+			default:
+				return nil, fmt.Errorf("line %d: unknown section [%s]", n+1, section)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch section {
+		case "interface":
+			err = parseWireGuardInterfaceKey(cfg, key, value)
+		case "peer":
+			err = parseWireGuardPeerKey(&cfg.Peers[len(cfg.Peers)-1], key, value)
+		default:
+			err = fmt.Errorf("%s outside of a section", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+func parseWireGuardInterfaceKey(cfg *WireGuardConfig, key, value string) error {
+	var err error
+	switch key {
+	case "privatekey":
+		cfg.PrivateKey = value
+	case "address":
+		cfg.Addresses = append(cfg.Addresses, splitWireGuardList(value)...)
+	case "dns":
+		for _, entry := range splitWireGuardList(value) {
+			if net.ParseIP(entry) != nil {
+				cfg.DNS = append(cfg.DNS, entry)
+			} else {
+				cfg.DNSSearch = append(cfg.DNSSearch, entry)
+			}
+		}
+	case "listenport":
+		cfg.ListenPort, err = parseWireGuardUint(value, 16)
+	case "mtu":
+		cfg.MTU, err = parseWireGuardUint(value, 32)
+	case "fwmark":
+		cfg.FwMark, err = parseWireGuardUint(value, 32)
+	case "table", "preup", "postup", "predown", "postdown", "saveconfig":
+		log.Warnf("[WireGuard] Ignoring unsupported wg-quick option %s", key)
+	default:
+		return fmt.Errorf("unknown interface option %s", key)
+	}
+	return err
+}
+
+func parseWireGuardPeerKey(peer *WireGuardPeer, key, value string) error {
+	var err error
+	switch key {
+	case "publickey":
+		peer.PublicKey = value
+	case "presharedkey":
+		peer.PresharedKey = value
+	case "allowedips":
+		peer.AllowedIPs = append(peer.AllowedIPs, splitWireGuardList(value)...)
+	case "endpoint":
+		peer.Endpoint = value
+	case "persistentkeepalive":
+		peer.PersistentKeepalive, err = parseWireGuardUint(value, 16)
+	default:
+		return fmt.Errorf("unknown peer option %s", key)
+	}
+	return err
+}
+
+func splitWireGuardList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func parseWireGuardUint(value string, bits int) (uint32, error) {
+	if value == "off" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(value, 0, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return uint32(n), nil
+}
+
+// prepareWireGuardConfig validates cfg and fills in defaults. A missing
+// private key is generated, which is how a profile is created from scratch.
+func prepareWireGuardConfig(cfg WireGuardConfig) (WireGuardConfig, error) {
+	if cfg.Name = strings.TrimSpace(cfg.Name); cfg.Name == "" {
+		return cfg, fmt.Errorf("name is required")
+	}
+	if cfg.InterfaceName == "" {
+		cfg.InterfaceName = wireGuardInterfaceName(cfg.Name)
+	}
+	if len(cfg.InterfaceName) > 15 || wireGuardIfaceChars.MatchString(cfg.InterfaceName) {
+		return cfg, fmt.Errorf("invalid interface name %q", cfg.InterfaceName)
+	}
+
+	if cfg.PrivateKey == "" {
+		keys, err := generateWireGuardKeypair()
+		if err != nil {
+			return cfg, err
+		}
+		cfg.PrivateKey = keys.PrivateKey
+	}
+	publicKey, err := wireGuardPublicKey(cfg.PrivateKey)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.PublicKey = publicKey
+
+	if len(cfg.Addresses) == 0 {
+		return cfg, fmt.Errorf("at least one address is required")
+	}
+	if cfg.Addresses, err = normalizeWireGuardPrefixes(cfg.Addresses); err != nil {
+		return cfg, err
+	}
+	for _, dns := range cfg.DNS {
+		if net.ParseIP(dns) == nil {
+			return cfg, fmt.Errorf("invalid DNS server %q", dns)
+		}
+	}
+
+	if len(cfg.Peers) == 0 {
+		return cfg, fmt.Errorf("at least one peer is required")
+	}
+	for i := range cfg.Peers {
+		peer := &cfg.Peers[i]
+		if _, err := decodeWireGuardKey(peer.PublicKey); err != nil {
+			return cfg, fmt.Errorf("peer %d: %w", i+1, err)
+		}
+		if peer.PresharedKey != "" {
+			if _, err := decodeWireGuardKey(peer.PresharedKey); err != nil {
+				return cfg, fmt.Errorf("peer %d: %w", i+1, err)
+			}
+		}
+		if peer.AllowedIPs, err = normalizeWireGuardPrefixes(peer.AllowedIPs); err != nil {
+			return cfg, fmt.Errorf("peer %d: %w", i+1, err)
+		}
+		if peer.Endpoint != "" {
+			_, port, err := net.SplitHostPort(peer.Endpoint)
+			if err != nil {
+				return cfg, fmt.Errorf("peer %d: invalid endpoint %q", i+1, peer.Endpoint)
+			}
+			if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+				return cfg, fmt.Errorf("peer %d: invalid endpoint port %q", i+1, port)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// normalizeWireGuardPrefixes accepts bare addresses as single-host
+// prefixes, like wg does.
+func normalizeWireGuardPrefixes(values []string) ([]string, error) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if addr, err := netip.ParseAddr(v); err == nil {
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()).String())
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", v)
+		}
+		out = append(out, prefix.String())
+	}
+	return out, nil
+}
+
+func formatWireGuardConf(cfg *WireGuardConfig) string {
+	var sb strings.Builder
+	sb.WriteString("[Interface]\n")
+	if cfg.PrivateKey != "" {
+		fmt.Fprintf(&sb, "PrivateKey = %s\n", cfg.PrivateKey)
+	}
+	if len(cfg.Addresses) > 0 {
+		fmt.Fprintf(&sb, "Address = %s\n", strings.Join(cfg.Addresses, ", "))
+	}
+	if dns := append(append([]string{}, cfg.DNS...), cfg.DNSSearch...); len(dns) > 0 {
+		fmt.Fprintf(&sb, "DNS = %s\n", strings.Join(dns, ", "))
+	}
+	if cfg.ListenPort > 0 {
+		fmt.Fprintf(&sb, "ListenPort = %d\n", cfg.ListenPort)
+	}
+	if cfg.MTU > 0 {
+		fmt.Fprintf(&sb, "MTU = %d\n", cfg.MTU)
+	}
+	if cfg.FwMark > 0 {
+		fmt.Fprintf(&sb, "FwMark = %d\n", cfg.FwMark)
+	}
+
+	for _, peer := range cfg.Peers {
+		sb.WriteString("\n[Peer]\n")
+		fmt.Fprintf(&sb, "PublicKey = %s\n", peer.PublicKey)
+		if peer.PresharedKey != "" {
+			fmt.Fprintf(&sb, "PresharedKey = %s\n", peer.PresharedKey)
+		}
+		if len(peer.AllowedIPs) > 0 {
+			fmt.Fprintf(&sb, "AllowedIPs = %s\n", strings.Join(peer.AllowedIPs, ", "))
+		}
+		if peer.Endpoint != "" {
+			fmt.Fprintf(&sb, "Endpoint = %s\n", peer.Endpoint)
+		}
+		if peer.PersistentKeepalive > 0 {
+			fmt.Fprintf(&sb, "PersistentKeepalive = %d\n", peer.PersistentKeepalive)
+		}
+	}
+
+	return sb.String()
+}
+
+// wireGuardStats reads interface byte counters from sysfs. Per-peer state
+// comes from `wg show`, which needs CAP_NET_ADMIN, so peers may be empty.
+func wireGuardStats(iface string) (rx, tx uint64, peers []WireGuardPeerStats) {
+	rx = readInterfaceCounter(iface, "rx_bytes")
+	tx = readInterfaceCounter(iface, "tx_bytes")
+
+	out, err := exec.Command("wg", "show", iface, "dump").Output()
+	if err != nil {
+		return rx, tx, nil
+	}
+	return rx, tx, parseWireGuardDump(string(out))
+}
+
+func readInterfaceCounter(iface, name string) uint64 {
+	data, err := os.ReadFile(filepath.Join(sysClassNet, iface, "statistics", name))
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return n
+}
+
+// parseWireGuardDump parses `wg show <iface> dump`. The first line is the
+// interface itself, followed by one tab-separated line per peer.
+func parseWireGuardDump(out string) []WireGuardPeerStats {
+	var peers []WireGuardPeerStats
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 8 {
+			continue
+		}
+		peer := WireGuardPeerStats{PublicKey: fields[0]}
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+		peer.LatestHandshake, _ = strconv.ParseInt(fields[4], 10, 64)
+		peer.RxBytes, _ = strconv.ParseUint(fields[5], 10, 64)
+		peer.TxBytes, _ = strconv.ParseUint(fields[6], 10, 64)
+		peers = append(peers, peer)
+	}
+	return peers
+}
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	mock_gonetworkmanager "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/Wifx/gonetworkmanager/v2"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWGPrivateKey = "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo="
+	testWGPublicKey  = "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo="
+	testWGPeerKey    = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+)
+
+const testWGConf = `[Interface]
+# laptop
+PrivateKey = dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=
+Address = 10.8.0.2/24, fd00:8::2/64
+DNS = 10.8.0.1, corp.example
+MTU = 1420
+PostUp = iptables -A FORWARD -i %i -j ACCEPT
+
+[Peer]
+PublicKey = AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=
+PresharedKey = AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=
+AllowedIPs = 0.0.0.0/0, ::/0
+Endpoint = vpn.corp.example:51820
+PersistentKeepalive = 25
+`
+
+func TestWireGuardPublicKey(t *testing.T) {
+	pub, err := wireGuardPublicKey(testWGPrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, testWGPublicKey, pub)
+
+	_, err = wireGuardPublicKey("not-a-key")
+	assert.Error(t, err)
+}
+
+func TestGenerateWireGuardKeypair(t *testing.T) {
+	keys, err := generateWireGuardKeypair()
+	require.NoError(t, err)
+
+	pub, err := wireGuardPublicKey(keys.PrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey, pub)
+
+	raw, err := decodeWireGuardKey(keys.PrivateKey)
+	require.NoError(t, err)
+	assert.Zero(t, raw[0]&7)
+	assert.Equal(t, byte(64), raw[31]&192)
+}
+
+func TestParseWireGuardConf(t *testing.T) {
+	cfg, err := parseWireGuardConf(testWGConf)
+	require.NoError(t, err)
+
+	assert.Equal(t, testWGPrivateKey, cfg.PrivateKey)
+	assert.Equal(t, []string{"10.8.0.2/24", "fd00:8::2/64"}, cfg.Addresses)
+	assert.Equal(t, []string{"10.8.0.1"}, cfg.DNS)
+	assert.Equal(t, []string{"corp.example"}, cfg.DNSSearch)
+	assert.Equal(t, uint32(1420), cfg.MTU)
+	require.Len(t, cfg.Peers, 1)
+	assert.Equal(t, WireGuardPeer{
+		PublicKey:           testWGPeerKey,
+		PresharedKey:        testWGPeerKey,
+		AllowedIPs:          []string{"0.0.0.0/0", "::/0"},
+		Endpoint:            "vpn.corp.example:51820",
+		PersistentKeepalive: 25,
+	}, cfg.Peers[0])
+
+	for _, bad := range []string{
+		"PrivateKey = x",
+		"[Interface]\nBogus = 1",
+		"[Peer]\nPersistentKeepalive = soon",
+		"[Tunnel]",
+		"[Interface]\nAddress",
+	} {
+		_, err := parseWireGuardConf(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFormatWireGuardConf_RoundTrip(t *testing.T) {
+	cfg, err := parseWireGuardConf(testWGConf)
+	require.NoError(t, err)
+
+	again, err := parseWireGuardConf(formatWireGuardConf(cfg))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, again)
+}
+
+func TestPrepareWireGuardConfig(t *testing.T) {
+	cfg, err := prepareWireGuardConfig(WireGuardConfig{
+		Name:      "Office VPN (new)",
+		Addresses: []string{"10.8.0.2"},
+		Peers:     []WireGuardPeer{{PublicKey: testWGPeerKey, AllowedIPs: []string{"10.8.0.0/24"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "OfficeVPNnew", cfg.InterfaceName)
+	assert.Equal(t, []string{"10.8.0.2/32"}, cfg.Addresses)
+	assert.NotEmpty(t, cfg.PrivateKey)
+	assert.NotEmpty(t, cfg.PublicKey)
+
+	valid := func() WireGuardConfig {
+		return WireGuardConfig{
+			Name:       "wg0",
+			PrivateKey: testWGPrivateKey,
+			Addresses:  []string{"10.8.0.2/24"},
+			Peers:      []WireGuardPeer{{PublicKey: testWGPeerKey, Endpoint: "[2001:db8::1]:51820"}},
+		}
+	}
+	_, err = prepareWireGuardConfig(valid())
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*WireGuardConfig){
+		"name":        func(c *WireGuardConfig) { c.Name = " " },
+		"iface":       func(c *WireGuardConfig) { c.InterfaceName = "much-too-long-name" },
+		"private key": func(c *WireGuardConfig) { c.PrivateKey = "short" },
+		"no address":  func(c *WireGuardConfig) { c.Addresses = nil },
+		"address":     func(c *WireGuardConfig) { c.Addresses = []string{"10.8.0.300/24"} },
+		"dns":         func(c *WireGuardConfig) { c.DNS = []string{"resolver"} },
+		"no peers":    func(c *WireGuardConfig) { c.Peers = nil },
+		"peer key":    func(c *WireGuardConfig) { c.Peers[0].PublicKey = "" },
+		"psk":         func(c *WireGuardConfig) { c.Peers[0].PresharedKey = "nope" },
+		"endpoint":    func(c *WireGuardConfig) { c.Peers[0].Endpoint = "vpn.example" },
+		"port":        func(c *WireGuardConfig) { c.Peers[0].Endpoint = "vpn.example:99999" },
+	} {
+		cfg := valid()
+		mutate(&cfg)
+		_, err := prepareWireGuardConfig(cfg)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseWireGuardDump(t *testing.T) {
+	out := testWGPrivateKey + "\t" + testWGPublicKey + "\t51820\toff\n" +
+		testWGPeerKey + "\t(none)\t203.0.113.5:51820\t0.0.0.0/0\t1760000000\t4096\t2048\t25\n" +
+		testWGPublicKey + "\t(none)\t(none)\t10.0.0.0/8\t0\t0\t0\toff\n"
+
+	assert.Equal(t, []WireGuardPeerStats{
+		{PublicKey: testWGPeerKey, Endpoint: "203.0.113.5:51820", LatestHandshake: 1760000000, RxBytes: 4096, TxBytes: 2048},
+		{PublicKey: testWGPublicKey},
+	}, parseWireGuardDump(out))
+}
+
+func TestWireGuardSettings_RoundTrip(t *testing.T) {
+	parsed, err := parseWireGuardConf(testWGConf)
+	require.NoError(t, err)
+	parsed.Name = "office"
+	cfg, err := prepareWireGuardConfig(*parsed)
+	require.NoError(t, err)
+
+	settings, err := wireGuardSettings(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "office", settings["connection"]["interface-name"])
+	assert.Equal(t, "manual", settings["ipv4"]["method"])
+	assert.Equal(t, wireGuardFullTunnelDNSPriority, settings["ipv4"]["dns-priority"])
+	assert.Equal(t, []string{"corp.example", "~"}, settings["ipv4"]["dns-search"])
+	assert.Equal(t, "manual", settings["ipv6"]["method"])
+	assert.NotContains(t, settings["ipv6"], "dns-priority")
+
+	// GetSettings omits secrets, GetSecrets returns only them.
+	secrets := gonetworkmanager.ConnectionSettings{"wireguard": {
+		"private-key": settings["wireguard"]["private-key"],
+		"peers":       []map[string]any{{"public-key": testWGPeerKey, "preshared-key": testWGPeerKey}},
+	}}
+	delete(settings["wireguard"], "private-key")
+	for _, peer := range settings["wireguard"]["peers"].([]map[string]any) {
+		delete(peer, "preshared-key")
+	}
+	settings["connection"]["uuid"] = "wg-uuid"
+
+	got := wireGuardConfigFromSettings(settings, secrets)
+	cfg.UUID = "wg-uuid"
+	assert.Equal(t, &cfg, got)
+}
+
+func TestNetworkManagerBackend_CreateWireGuard(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockSettings := mock_gonetworkmanager.NewMockSettings(t)
+	mockConn := mock_gonetworkmanager.NewMockConnection(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.settings = mockSettings
+
+	mockSettings.EXPECT().AddConnection(mock.MatchedBy(func(s gonetworkmanager.ConnectionSettings) bool {
+		return s["connection"]["type"] == "wireguard" &&
+			s["wireguard"]["private-key"] == testWGPrivateKey &&
+			s["ipv6"]["method"] == "ignore"
+	})).Return(mockConn, nil)
+	mockConn.EXPECT().GetSettings().Return(gonetworkmanager.ConnectionSettings{"connection": {"uuid": "wg-uuid"}}, nil)
+	mockSettings.EXPECT().ListConnections().Return(nil, nil)
+
+	created, err := backend.CreateWireGuard(WireGuardConfig{
+		Name:       "wg0",
+		PrivateKey: testWGPrivateKey,
+		Addresses:  []string{"10.8.0.2/24"},
+		Peers:      []WireGuardPeer{{PublicKey: testWGPeerKey, AllowedIPs: []string{"10.8.0.0/24"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "wg-uuid", created.UUID)
+	assert.Equal(t, testWGPublicKey, created.PublicKey)
+}
+
+func TestHandleGenerateWireGuardKeypair(t *testing.T) {
+	conn := newMockNetConn()
+	req := models.Request{
+		ID:     123,
+		Method: "network.wireguard.generateKeypair",
+		Params: map[string]any{"privateKey": testWGPrivateKey},
+	}
+
+	HandleRequest(conn, req, &Manager{})
+
+	var resp models.Response[WireGuardKeypair]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, testWGPublicKey, resp.Result.PublicKey)
+}
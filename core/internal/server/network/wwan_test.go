@@ -0,0 +1,188 @@
+package network
+
+import (
+	"testing"
+
+	mock_gonetworkmanager "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/Wifx/gonetworkmanager/v2"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testModemProperties() map[string]map[string]dbus.Variant {
+	return map[string]map[string]dbus.Variant{
+		mmModemInterface: {
+			"Manufacturer":        dbus.MakeVariant("Quectel"),
+			"Model":               dbus.MakeVariant("EM120R-GL"),
+			"PrimaryPort":         dbus.MakeVariant("cdc-wdm0"),
+			"EquipmentIdentifier": dbus.MakeVariant("867698040000001"),
+			"State":               dbus.MakeVariant(int32(2)),
+			"SignalQuality":       dbus.MakeVariant([]any{uint32(67), true}),
+			"AccessTechnologies":  dbus.MakeVariant(uint32(1<<14 | 1<<5)),
+			"Sim":                 dbus.MakeVariant(dbus.ObjectPath("/org/freedesktop/ModemManager1/SIM/0")),
+			"UnlockRequired":      dbus.MakeVariant(uint32(2)),
+			"UnlockRetries":       dbus.MakeVariant(map[uint32]uint32{2: 3, 4: 10}),
+			"OwnNumbers":          dbus.MakeVariant([]string{"+15550100"}),
+		},
+		mm3gppInterface: {
+			"OperatorName":      dbus.MakeVariant("Example Mobile"),
+			"OperatorCode":      dbus.MakeVariant("00101"),
+			"RegistrationState": dbus.MakeVariant(uint32(5)),
+		},
+	}
+}
+
+func TestWWANModemFromProperties(t *testing.T) {
+	modem := wwanModemFromProperties("/org/freedesktop/ModemManager1/Modem/3", testModemProperties())
+
+	assert.Equal(t, WWANModem{
+		ID:                 "3",
+		Path:               "/org/freedesktop/ModemManager1/Modem/3",
+		Manufacturer:       "Quectel",
+		Model:              "EM120R-GL",
+		Device:             "cdc-wdm0",
+		IMEI:               "867698040000001",
+		State:              "locked",
+		Signal:             67,
+		AccessTechnologies: []string{"umts", "lte"},
+		Generation:         "4G",
+		Operator:           "Example Mobile",
+		OperatorCode:       "00101",
+		Registration:       "roaming",
+		Roaming:            true,
+		SIMPresent:         true,
+		UnlockRequired:     "sim-pin",
+		UnlockRetries:      map[string]uint32{"sim-pin": 3, "sim-puk": 10},
+		OwnNumbers:         []string{"+15550100"},
+		simPath:            "/org/freedesktop/ModemManager1/SIM/0",
+	}, modem)
+}
+
+func TestWWANModemFromProperties_Minimal(t *testing.T) {
+	modem := wwanModemFromProperties("/org/freedesktop/ModemManager1/Modem/0", map[string]map[string]dbus.Variant{
+		mmModemInterface: {
+			"State":          dbus.MakeVariant(int32(11)),
+			"Sim":            dbus.MakeVariant(dbus.ObjectPath("/")),
+			"UnlockRequired": dbus.MakeVariant(uint32(1)),
+		},
+	})
+
+	assert.Equal(t, "connected", modem.State)
+	assert.True(t, modem.Enabled)
+	assert.False(t, modem.SIMPresent)
+	assert.Empty(t, modem.UnlockRequired)
+	assert.Empty(t, modem.Generation)
+	assert.Equal(t, []string{}, modem.AccessTechnologies)
+}
+
+func TestWWANGeneration(t *testing.T) {
+	assert.Equal(t, "5G", wwanGeneration(mmAccessTechnologyNames(1<<15|1<<14)))
+	assert.Equal(t, "3G", wwanGeneration([]string{"edge", "hspa-plus"}))
+	assert.Equal(t, "2G", wwanGeneration([]string{"gprs"}))
+	assert.Empty(t, wwanGeneration([]string{"pots"}))
+}
+
+func TestFindWWANModem(t *testing.T) {
+	modems := []WWANModem{
+		{ID: "0", Path: "/org/freedesktop/ModemManager1/Modem/0", IMEI: "111"},
+		{ID: "1", Path: "/org/freedesktop/ModemManager1/Modem/1", IMEI: "222"},
+	}
+
+	for _, id := range []string{"1", "/org/freedesktop/ModemManager1/Modem/1", "222"} {
+		modem, err := findWWANModem(modems, id)
+		require.NoError(t, err, id)
+		assert.Equal(t, "1", modem.ID)
+	}
+
+	modem, err := findWWANModem(modems, "")
+	require.NoError(t, err)
+	assert.Equal(t, "0", modem.ID)
+
+	_, err = findWWANModem(modems, "7")
+	assert.Error(t, err)
+	_, err = findWWANModem(nil, "")
+	assert.Error(t, err)
+}
+
+func TestWWANUnlockFieldsInfo(t *testing.T) {
+	modem := &WWANModem{UnlockRequired: "sim-puk", UnlockRetries: map[string]uint32{"sim-puk": 9}}
+	assert.Equal(t, []FieldInfo{
+		{Name: "puk", Label: "SIM PUK (9 attempts left)", IsSecret: true},
+		{Name: "pin", Label: "New SIM PIN", IsSecret: true},
+	}, wwanUnlockFieldsInfo(modem, []string{"puk", "pin"}))
+}
+
+func TestWWANProfile_RoundTrip(t *testing.T) {
+	profile := WWANProfile{
+		Name:         "Carrier",
+		APN:          "internet",
+		Username:     "user",
+		Password:     "secret",
+		Autoconnect:  false,
+		AllowRoaming: false,
+		Device:       "cdc-wdm0",
+	}
+
+	settings := gonetworkmanager.ConnectionSettings{"connection": {"type": "gsm"}, "gsm": {}}
+	applyWWANProfile(settings, &profile)
+
+	assert.Equal(t, true, settings["gsm"]["home-only"])
+	assert.Equal(t, "secret", settings["gsm"]["password"])
+	assert.Equal(t, "cdc-wdm0", settings["connection"]["interface-name"])
+
+	settings["connection"]["uuid"] = "gsm-uuid"
+	got := wwanProfileFromSettings(settings)
+	profile.UUID = "gsm-uuid"
+	profile.Password = ""
+	assert.Equal(t, profile, got)
+
+	profile.Device = ""
+	profile.Username = ""
+	applyWWANProfile(settings, &profile)
+	assert.NotContains(t, settings["connection"], "interface-name")
+	assert.NotContains(t, settings["gsm"], "username")
+}
+
+func TestNetworkManagerBackend_ConnectWWAN_RejectsOtherTypes(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+	mockSettings := mock_gonetworkmanager.NewMockSettings(t)
+	mockConn := mock_gonetworkmanager.NewMockConnection(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+	backend.settings = mockSettings
+
+	mockSettings.EXPECT().GetConnectionByUUID("wifi-uuid").Return(mockConn, nil)
+	mockConn.EXPECT().GetSettings().Return(gonetworkmanager.ConnectionSettings{
+		"connection": {"type": "802-11-wireless"},
+	}, nil)
+
+	err = backend.ConnectWWAN("wifi-uuid")
+	assert.ErrorContains(t, err, "not a mobile broadband profile")
+}
+
+func TestNetworkManagerBackend_GetWWANModems_NoModemManager(t *testing.T) {
+	mockNM := mock_gonetworkmanager.NewMockNetworkManager(t)
+
+	backend, err := NewNetworkManagerBackend(mockNM)
+	require.NoError(t, err)
+
+	_, err = backend.GetWWANModems()
+	assert.Error(t, err)
+	assert.Error(t, backend.SetWWANEnabled(true))
+}
+
+func TestStateChangedMeaningfully_WWAN(t *testing.T) {
+	old := &NetworkState{WWANModems: []WWANModem{{Path: "/m/0", State: "registered", Signal: 60}}}
+	same := &NetworkState{WWANModems: []WWANModem{{Path: "/m/0", State: "registered", Signal: 62}}}
+	assert.False(t, stateChangedMeaningfully(old, same))
+
+	weaker := &NetworkState{WWANModems: []WWANModem{{Path: "/m/0", State: "registered", Signal: 30}}}
+	assert.True(t, stateChangedMeaningfully(old, weaker))
+
+	locked := &NetworkState{WWANModems: []WWANModem{{Path: "/m/0", State: "locked", Signal: 60}}}
+	assert.True(t, stateChangedMeaningfully(old, locked))
+
+	assert.True(t, stateChangedMeaningfully(old, &NetworkState{WWANEnabled: true, WWANModems: old.WWANModems}))
+}
@@ -0,0 +1,30 @@
+package powerprofile
+
+const (
+	dbusPropsInterface = "org.freedesktop.DBus.Properties"
+
+	ProfilePowerSaver  = "power-saver"
+	ProfileBalanced    = "balanced"
+	ProfilePerformance = "performance"
+)
+
+type busService struct {
+	dest  string
+	path  string
+	iface string
+}
+
+// Since 0.20 power-profiles-daemon is exported under the UPower namespace;
+// the net.hadess name is kept as a compatibility alias for older releases.
+var busServices = []busService{
+	{
+		dest:  "org.freedesktop.UPower.PowerProfiles",
+		path:  "/org/freedesktop/UPower/PowerProfiles",
+		iface: "org.freedesktop.UPower.PowerProfiles",
+	},
+	{
+		dest:  "net.hadess.PowerProfiles",
+		path:  "/net/hadess/PowerProfiles",
+		iface: "net.hadess.PowerProfiles",
+	},
+}
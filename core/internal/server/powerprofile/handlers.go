@@ -0,0 +1,91 @@
+package powerprofile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	switch req.Method {
+	case "powerprofile.getState":
+		handleGetState(conn, req, manager)
+	case "powerprofile.set":
+		handleSet(conn, req, manager)
+	case "powerprofile.listHolds":
+		handleListHolds(conn, req, manager)
+	case "powerprofile.getRules":
+		handleGetRules(conn, req, manager)
+	case "powerprofile.setRules":
+		handleSetRules(conn, req, manager)
+	case "powerprofile.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSet(conn net.Conn, req models.Request, manager *Manager) {
+	profile, err := params.StringNonEmpty(req.Params, "profile")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetProfile(profile); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleListHolds(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetHolds())
+}
+
+func handleGetRules(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetRules())
+}
+
+func handleSetRules(conn net.Conn, req models.Request, manager *Manager) {
+	rules := manager.GetRules()
+	rules.Enabled = params.BoolOpt(req.Params, "enabled", rules.Enabled)
+	rules.OnAC = params.StringOpt(req.Params, "onAC", rules.OnAC)
+	rules.OnBattery = params.StringOpt(req.Params, "onBattery", rules.OnBattery)
+	rules.OnIdle = params.StringOpt(req.Params, "onIdle", rules.OnIdle)
+
+	if err := manager.SetRules(rules); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetRules())
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
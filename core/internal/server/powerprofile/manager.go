@@ -0,0 +1,328 @@
+package powerprofile
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/dbusutil"
+	"github.com/godbus/dbus/v5"
+)
+
+func NewManager() (*Manager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	m, err := NewManagerWithConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if path, err := getRulesPath(); err == nil {
+		m.rulesPath = path
+		m.stateMutex.Lock()
+		m.state.Rules = loadRules(path)
+		m.stateMutex.Unlock()
+	}
+	return m, nil
+}
+
+func NewManagerWithConn(conn DBusConn) (*Manager, error) {
+	m := &Manager{
+		state:    &State{Profiles: []Profile{}, Actions: []string{}, Holds: []Hold{}},
+		conn:     conn,
+		signals:  make(chan *dbus.Signal, 64),
+		stopChan: make(chan struct{}),
+	}
+
+	if err := m.initialize(); err != nil {
+		return nil, err
+	}
+
+	if err := m.startSignalPump(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Manager) initialize() error {
+	var lastErr error
+	for _, svc := range busServices {
+		obj := m.conn.Object(svc.dest, dbus.ObjectPath(svc.path))
+
+		var props map[string]dbus.Variant
+		if err := obj.Call(dbusPropsInterface+".GetAll", 0, svc.iface).Store(&props); err != nil {
+			lastErr = err
+			continue
+		}
+
+		m.service = svc
+		m.obj = obj
+
+		m.stateMutex.Lock()
+		m.state.Available = true
+		m.state.Service = svc.dest
+		applyProps(m.state, props)
+		m.stateMutex.Unlock()
+
+		log.Infof("[PowerProfiles] using %s (active: %s)", svc.dest, m.state.ActiveProfile)
+		return nil
+	}
+
+	return fmt.Errorf("power-profiles-daemon not available: %w", lastErr)
+}
+
+func (m *Manager) refresh() error {
+	var props map[string]dbus.Variant
+	if err := m.obj.Call(dbusPropsInterface+".GetAll", 0, m.service.iface).Store(&props); err != nil {
+		return err
+	}
+
+	m.stateMutex.Lock()
+	m.state.Available = true
+	applyProps(m.state, props)
+	m.stateMutex.Unlock()
+	return nil
+}
+
+func applyProps(state *State, props map[string]dbus.Variant) bool {
+	changed := false
+
+	if v, ok := dbusutil.Get[string](props, "ActiveProfile"); ok {
+		state.ActiveProfile = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[string](props, "PerformanceDegraded"); ok {
+		state.PerformanceDegraded = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[string](props, "Version"); ok {
+		state.Version = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[[]string](props, "Actions"); ok {
+		state.Actions = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[[]map[string]dbus.Variant](props, "Profiles"); ok {
+		profiles := make([]Profile, 0, len(v))
+		for _, p := range v {
+			profiles = append(profiles, Profile{
+				Profile:        dbusutil.GetOr(p, "Profile", ""),
+				Driver:         dbusutil.GetOr(p, "Driver", ""),
+				CPUDriver:      dbusutil.GetOr(p, "CpuDriver", ""),
+				PlatformDriver: dbusutil.GetOr(p, "PlatformDriver", ""),
+			})
+		}
+		state.Profiles = profiles
+		changed = true
+	}
+	if v, ok := dbusutil.Get[[]map[string]dbus.Variant](props, "ActiveProfileHolds"); ok {
+		holds := make([]Hold, 0, len(v))
+		for _, h := range v {
+			holds = append(holds, Hold{
+				Profile:       dbusutil.GetOr(h, "Profile", ""),
+				Reason:        dbusutil.GetOr(h, "Reason", ""),
+				ApplicationID: dbusutil.GetOr(h, "ApplicationId", ""),
+			})
+		}
+		state.Holds = holds
+		changed = true
+	}
+
+	return changed
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	s := *m.state
+	s.Profiles = slices.Clone(m.state.Profiles)
+	s.Actions = slices.Clone(m.state.Actions)
+	s.Holds = slices.Clone(m.state.Holds)
+	return s
+}
+
+func (m *Manager) GetHolds() []Hold {
+	return m.GetState().Holds
+}
+
+func (m *Manager) SetProfile(profile string) error {
+	state := m.GetState()
+	if !state.Available {
+		return fmt.Errorf("power-profiles-daemon not available")
+	}
+	if !slices.ContainsFunc(state.Profiles, func(p Profile) bool { return p.Profile == profile }) {
+		return fmt.Errorf("unsupported profile: %s", profile)
+	}
+
+	call := m.obj.Call(dbusPropsInterface+".Set", 0, m.service.iface, "ActiveProfile", dbus.MakeVariant(profile))
+	if call.Err != nil {
+		return fmt.Errorf("failed to set profile: %w", call.Err)
+	}
+
+	m.stateMutex.Lock()
+	m.state.ActiveProfile = profile
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+
+	return nil
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if val, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(val)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	state := m.GetState()
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+		}
+		return true
+	})
+}
+
+func (m *Manager) signalMatches() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(m.service.path)),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		},
+		{
+			dbus.WithMatchObjectPath("/org/freedesktop/DBus"),
+			dbus.WithMatchInterface("org.freedesktop.DBus"),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg(0, m.service.dest),
+		},
+	}
+}
+
+func (m *Manager) startSignalPump() error {
+	m.conn.Signal(m.signals)
+
+	matches := m.signalMatches()
+	for i, match := range matches {
+		if err := m.conn.AddMatchSignal(match...); err != nil {
+			for _, added := range matches[:i] {
+				m.conn.RemoveMatchSignal(added...)
+			}
+			m.conn.RemoveSignal(m.signals)
+			return err
+		}
+	}
+
+	m.sigWG.Add(1)
+	go func() {
+		defer m.sigWG.Done()
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case sig, ok := <-m.signals:
+				if !ok {
+					return
+				}
+				if sig == nil {
+					continue
+				}
+				m.handleDBusSignal(sig)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
+	switch sig.Name {
+	case dbusPropsInterface + ".PropertiesChanged":
+		if len(sig.Body) < 2 {
+			return
+		}
+		iface, _ := sig.Body[0].(string)
+		if iface != m.service.iface {
+			return
+		}
+		changes, ok := sig.Body[1].(map[string]dbus.Variant)
+		if !ok {
+			return
+		}
+
+		m.stateMutex.Lock()
+		changed := applyProps(m.state, changes)
+		m.stateMutex.Unlock()
+
+		if changed {
+			m.notifySubscribers()
+		}
+
+	case "org.freedesktop.DBus.NameOwnerChanged":
+		if len(sig.Body) != 3 {
+			return
+		}
+		name, _ := sig.Body[0].(string)
+		newOwner, _ := sig.Body[2].(string)
+		if name != m.service.dest {
+			return
+		}
+
+		if newOwner == "" {
+			m.stateMutex.Lock()
+			m.state.Available = false
+			m.state.Holds = []Hold{}
+			m.stateMutex.Unlock()
+			m.notifySubscribers()
+			return
+		}
+
+		if err := m.refresh(); err != nil {
+			log.Warnf("[PowerProfiles] failed to refresh after daemon restart: %v", err)
+			return
+		}
+		m.notifySubscribers()
+	}
+}
+
+func (m *Manager) Close() {
+	select {
+	case <-m.stopChan:
+		return
+	default:
+		close(m.stopChan)
+	}
+	m.wg.Wait()
+
+	if m.conn != nil {
+		for _, match := range m.signalMatches() {
+			m.conn.RemoveMatchSignal(match...)
+		}
+		m.conn.RemoveSignal(m.signals)
+		close(m.signals)
+		m.sigWG.Wait()
+	}
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+
+	if m.conn != nil {
+		m.conn.Close()
+	}
+}
@@ -0,0 +1,206 @@
+package powerprofile
+
+import (
+	"testing"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	objects map[dbus.ObjectPath]dbus.BusObject
+	matches int
+	closed  bool
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{objects: make(map[dbus.ObjectPath]dbus.BusObject)}
+}
+
+func (c *fakeConn) Object(dest string, path dbus.ObjectPath) dbus.BusObject {
+	return c.objects[path]
+}
+
+func (c *fakeConn) Signal(ch chan<- *dbus.Signal)       {}
+func (c *fakeConn) RemoveSignal(ch chan<- *dbus.Signal) {}
+
+func (c *fakeConn) AddMatchSignal(options ...dbus.MatchOption) error {
+	c.matches++
+	return nil
+}
+
+func (c *fakeConn) RemoveMatchSignal(options ...dbus.MatchOption) error {
+	c.matches--
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func daemonProps(active string) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"ActiveProfile":       dbus.MakeVariant(active),
+		"PerformanceDegraded": dbus.MakeVariant(""),
+		"Version":             dbus.MakeVariant("0.21"),
+		"Actions":             dbus.MakeVariant([]string{"amdgpu_panel_power"}),
+		"Profiles": dbus.MakeVariant([]map[string]dbus.Variant{
+			{"Profile": dbus.MakeVariant(ProfilePowerSaver), "Driver": dbus.MakeVariant("multiple"), "CpuDriver": dbus.MakeVariant("amd_pstate")},
+			{"Profile": dbus.MakeVariant(ProfileBalanced), "Driver": dbus.MakeVariant("multiple")},
+			{"Profile": dbus.MakeVariant(ProfilePerformance), "Driver": dbus.MakeVariant("multiple")},
+		}),
+		"ActiveProfileHolds": dbus.MakeVariant([]map[string]dbus.Variant{
+			{
+				"Profile":       dbus.MakeVariant(ProfilePerformance),
+				"Reason":        dbus.MakeVariant("Compiling"),
+				"ApplicationId": dbus.MakeVariant("org.gnome.Builder"),
+			},
+		}),
+	}
+}
+
+func newDaemonObject(t *testing.T, iface, active string) *mockdbus.MockBusObject {
+	obj := mockdbus.NewMockBusObject(t)
+	obj.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), iface).
+		Return(&dbus.Call{Body: []any{daemonProps(active)}}).Maybe()
+	return obj
+}
+
+func newTestManager(t *testing.T, active string) (*Manager, *mockdbus.MockBusObject) {
+	svc := busServices[0]
+	obj := newDaemonObject(t, svc.iface, active)
+	conn := newFakeConn()
+	conn.objects[dbus.ObjectPath(svc.path)] = obj
+
+	m, err := NewManagerWithConn(conn)
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+	return m, obj
+}
+
+func TestNewManagerWithConn(t *testing.T) {
+	m, _ := newTestManager(t, ProfileBalanced)
+
+	state := m.GetState()
+	assert.True(t, state.Available)
+	assert.Equal(t, "org.freedesktop.UPower.PowerProfiles", state.Service)
+	assert.Equal(t, ProfileBalanced, state.ActiveProfile)
+	assert.Equal(t, "0.21", state.Version)
+	assert.Equal(t, []string{"amdgpu_panel_power"}, state.Actions)
+	require.Len(t, state.Profiles, 3)
+	assert.Equal(t, "amd_pstate", state.Profiles[0].CPUDriver)
+	require.Len(t, state.Holds, 1)
+	assert.Equal(t, Hold{Profile: ProfilePerformance, Reason: "Compiling", ApplicationID: "org.gnome.Builder"}, state.Holds[0])
+}
+
+func TestNewManagerWithConn_LegacyFallback(t *testing.T) {
+	legacy := busServices[1]
+	conn := newFakeConn()
+
+	modern := mockdbus.NewMockBusObject(t)
+	modern.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), busServices[0].iface).
+		Return(&dbus.Call{Err: assert.AnError})
+	conn.objects[dbus.ObjectPath(busServices[0].path)] = modern
+	conn.objects[dbus.ObjectPath(legacy.path)] = newDaemonObject(t, legacy.iface, ProfilePowerSaver)
+
+	m, err := NewManagerWithConn(conn)
+	require.NoError(t, err)
+	defer m.Close()
+
+	assert.Equal(t, legacy.dest, m.GetState().Service)
+	assert.Equal(t, ProfilePowerSaver, m.GetState().ActiveProfile)
+	assert.Equal(t, 2, conn.matches)
+}
+
+func TestNewManagerWithConn_Unavailable(t *testing.T) {
+	conn := newFakeConn()
+	for _, svc := range busServices {
+		obj := mockdbus.NewMockBusObject(t)
+		obj.EXPECT().
+			Call(dbusPropsInterface+".GetAll", dbus.Flags(0), svc.iface).
+			Return(&dbus.Call{Err: assert.AnError})
+		conn.objects[dbus.ObjectPath(svc.path)] = obj
+	}
+
+	m, err := NewManagerWithConn(conn)
+	assert.Nil(t, m)
+	assert.ErrorContains(t, err, "power-profiles-daemon not available")
+}
+
+func TestManager_SetProfile(t *testing.T) {
+	t.Run("switches profile", func(t *testing.T) {
+		m, obj := newTestManager(t, ProfileBalanced)
+		obj.EXPECT().
+			Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePerformance)).
+			Return(&dbus.Call{})
+
+		require.NoError(t, m.SetProfile(ProfilePerformance))
+		assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+	})
+
+	t.Run("rejects unsupported profile", func(t *testing.T) {
+		m, _ := newTestManager(t, ProfileBalanced)
+		assert.ErrorContains(t, m.SetProfile("turbo"), "unsupported profile")
+	})
+
+	t.Run("propagates dbus error", func(t *testing.T) {
+		m, obj := newTestManager(t, ProfileBalanced)
+		obj.EXPECT().
+			Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePowerSaver)).
+			Return(&dbus.Call{Err: assert.AnError})
+
+		assert.ErrorContains(t, m.SetProfile(ProfilePowerSaver), "failed to set profile")
+		assert.Equal(t, ProfileBalanced, m.GetState().ActiveProfile)
+	})
+}
+
+func TestManager_HandleDBusSignal(t *testing.T) {
+	m, _ := newTestManager(t, ProfileBalanced)
+	ch := m.Subscribe("test")
+
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{
+			busServices[0].iface,
+			map[string]dbus.Variant{
+				"ActiveProfile":      dbus.MakeVariant(ProfilePerformance),
+				"ActiveProfileHolds": dbus.MakeVariant([]map[string]dbus.Variant{}),
+			},
+			[]string{},
+		},
+	})
+
+	state := <-ch
+	assert.Equal(t, ProfilePerformance, state.ActiveProfile)
+	assert.Empty(t, state.Holds)
+
+	m.handleDBusSignal(&dbus.Signal{
+		Name: "org.freedesktop.DBus.NameOwnerChanged",
+		Body: []any{busServices[0].dest, ":1.5", ""},
+	})
+	state = <-ch
+	assert.False(t, state.Available)
+
+	m.handleDBusSignal(&dbus.Signal{
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{"org.example.Other", map[string]dbus.Variant{}, []string{}},
+	})
+	assert.Empty(t, ch)
+}
+
+func TestManager_GetStateIsCopy(t *testing.T) {
+	m, _ := newTestManager(t, ProfileBalanced)
+
+	state := m.GetState()
+	state.Profiles[0].Profile = "mutated"
+	state.Holds[0].Reason = "mutated"
+
+	fresh := m.GetState()
+	assert.Equal(t, ProfilePowerSaver, fresh.Profiles[0].Profile)
+	assert.Equal(t, "Compiling", fresh.Holds[0].Reason)
+}
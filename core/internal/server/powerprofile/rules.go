@@ -0,0 +1,269 @@
+package powerprofile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/upower"
+)
+
+func validRuleProfile(profile string) bool {
+	switch profile {
+	case "", ProfilePowerSaver, ProfileBalanced, ProfilePerformance:
+		return true
+	}
+	return false
+}
+
+func (r Rules) Validate() error {
+	for _, p := range []string{r.OnAC, r.OnBattery, r.OnIdle} {
+		if !validRuleProfile(p) {
+			return fmt.Errorf("invalid profile: %s", p)
+		}
+	}
+	return nil
+}
+
+func getRulesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "power-profile-rules.json"), nil
+}
+
+func loadRules(path string) Rules {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		log.Warnf("[PowerProfiles] invalid rules file %s: %v", path, err)
+		return Rules{}
+	}
+	if err := rules.Validate(); err != nil {
+		log.Warnf("[PowerProfiles] invalid rules file %s: %v", path, err)
+		return Rules{}
+	}
+	return rules
+}
+
+func saveRules(path string, rules Rules) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (m *Manager) GetRules() Rules {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state.Rules
+}
+
+// SetRules replaces and saves the automation rules, then immediately
+// applies the rule matching the current power source and idle state.
+func (m *Manager) SetRules(rules Rules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	if m.rulesPath != "" {
+		if err := saveRules(m.rulesPath, rules); err != nil {
+			return fmt.Errorf("failed to save rules: %w", err)
+		}
+	}
+
+	m.stateMutex.Lock()
+	m.state.Rules = rules
+	m.stateMutex.Unlock()
+
+	m.ruleMutex.Lock()
+	if !rules.Enabled || rules.OnIdle == "" {
+		m.preIdleProfile = ""
+	}
+	m.ruleMutex.Unlock()
+
+	m.notifySubscribers()
+	m.evaluateRules()
+	return nil
+}
+
+// targetProfile picks the profile the rules call for. Idle takes precedence
+// over the power source. An empty result means no rule applies.
+func targetProfile(rules Rules, onBattery, idle bool) string {
+	if !rules.Enabled {
+		return ""
+	}
+	if idle && rules.OnIdle != "" {
+		return rules.OnIdle
+	}
+	if onBattery {
+		return rules.OnBattery
+	}
+	return rules.OnAC
+}
+
+func (m *Manager) evaluateRules() {
+	m.stateMutex.RLock()
+	rules := m.state.Rules
+	onBattery := m.state.OnBattery
+	idle := m.state.Idle
+	m.stateMutex.RUnlock()
+
+	target := targetProfile(rules, onBattery, idle)
+	if target == "" {
+		return
+	}
+	m.applyRuleProfile(target)
+}
+
+func (m *Manager) applyRuleProfile(profile string) {
+	m.stateMutex.RLock()
+	current := m.state.ActiveProfile
+	available := m.state.Available
+	m.stateMutex.RUnlock()
+
+	if !available || profile == "" || profile == current {
+		return
+	}
+
+	if err := m.SetProfile(profile); err != nil {
+		log.Warnf("[PowerProfiles] rule failed to switch to %s: %v", profile, err)
+		return
+	}
+	log.Infof("[PowerProfiles] rule switched profile %s -> %s", current, profile)
+}
+
+// SetOnBattery records a power source transition and applies the matching
+// rule. While an idle rule is in effect the power source profile is only
+// remembered, to be restored once the session becomes active.
+func (m *Manager) SetOnBattery(onBattery bool) {
+	m.stateMutex.Lock()
+	if m.state.OnBattery == onBattery {
+		m.stateMutex.Unlock()
+		return
+	}
+	m.state.OnBattery = onBattery
+	rules := m.state.Rules
+	idle := m.state.Idle
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+
+	target := targetProfile(rules, onBattery, false)
+	if idle && rules.Enabled && rules.OnIdle != "" {
+		if target != "" {
+			m.ruleMutex.Lock()
+			m.preIdleProfile = target
+			m.ruleMutex.Unlock()
+		}
+		return
+	}
+	m.applyRuleProfile(target)
+}
+
+// initPowerSource records the power source found at startup and applies
+// the rules once, even when it matches the default of being on AC.
+func (m *Manager) initPowerSource(onBattery bool) {
+	m.stateMutex.Lock()
+	changed := m.state.OnBattery != onBattery
+	m.state.OnBattery = onBattery
+	m.stateMutex.Unlock()
+
+	if changed {
+		m.notifySubscribers()
+	}
+	m.evaluateRules()
+}
+
+// SetIdle records an idle hint transition. Entering idle switches to the
+// idle profile; leaving idle restores whatever was active before.
+func (m *Manager) SetIdle(idle bool) {
+	m.stateMutex.Lock()
+	if m.state.Idle == idle {
+		m.stateMutex.Unlock()
+		return
+	}
+	m.state.Idle = idle
+	rules := m.state.Rules
+	current := m.state.ActiveProfile
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+
+	if !rules.Enabled || rules.OnIdle == "" {
+		return
+	}
+
+	m.ruleMutex.Lock()
+	if idle {
+		m.preIdleProfile = current
+		m.ruleMutex.Unlock()
+		m.applyRuleProfile(rules.OnIdle)
+		return
+	}
+	restore := m.preIdleProfile
+	m.preIdleProfile = ""
+	m.ruleMutex.Unlock()
+
+	m.applyRuleProfile(restore)
+}
+
+func (m *Manager) WatchUPower(um *upower.Manager) {
+	ch := um.Subscribe("powerprofile")
+	m.initPowerSource(um.GetState().OnBattery)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer um.Unsubscribe("powerprofile")
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case state, ok := <-ch:
+				if !ok {
+					return
+				}
+				if !state.Available {
+					continue
+				}
+				m.SetOnBattery(state.OnBattery)
+			}
+		}
+	}()
+}
+
+func (m *Manager) WatchLoginctl(lm *loginctl.Manager) {
+	ch := lm.Subscribe("powerprofile")
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer lm.Unsubscribe("powerprofile")
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case state, ok := <-ch:
+				if !ok {
+					return
+				}
+				m.SetIdle(state.IdleHint)
+			}
+		}
+	}()
+}
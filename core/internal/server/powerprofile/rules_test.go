@@ -0,0 +1,154 @@
+package powerprofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetProfile(t *testing.T) {
+	rules := Rules{Enabled: true, OnAC: ProfilePerformance, OnBattery: ProfilePowerSaver, OnIdle: ProfilePowerSaver}
+
+	tests := []struct {
+		name      string
+		rules     Rules
+		onBattery bool
+		idle      bool
+		expected  string
+	}{
+		{"disabled", Rules{OnAC: ProfilePerformance}, false, false, ""},
+		{"on ac", rules, false, false, ProfilePerformance},
+		{"on battery", rules, true, false, ProfilePowerSaver},
+		{"idle wins over ac", rules, false, true, ProfilePowerSaver},
+		{"idle without idle rule", Rules{Enabled: true, OnAC: ProfileBalanced}, false, true, ProfileBalanced},
+		{"no battery rule", Rules{Enabled: true, OnAC: ProfileBalanced}, true, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, targetProfile(tt.rules, tt.onBattery, tt.idle))
+		})
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	assert.NoError(t, Rules{OnAC: ProfilePerformance, OnIdle: ""}.Validate())
+	assert.Error(t, Rules{OnBattery: "eco"}.Validate())
+}
+
+func TestManager_PowerSourceRules(t *testing.T) {
+	m, obj := newTestManager(t, ProfileBalanced)
+	require.NoError(t, m.SetRules(Rules{Enabled: true, OnBattery: ProfilePowerSaver}))
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePowerSaver)).
+		Return(&dbus.Call{}).Once()
+
+	m.SetOnBattery(true)
+	assert.Equal(t, ProfilePowerSaver, m.GetState().ActiveProfile)
+
+	// Repeated reports of the same power source must not fight a manual change.
+	m.stateMutex.Lock()
+	m.state.ActiveProfile = ProfilePerformance
+	m.stateMutex.Unlock()
+	m.SetOnBattery(true)
+	assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+
+	// No AC rule configured: plugging in leaves the profile alone.
+	m.SetOnBattery(false)
+	assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+}
+
+func TestManager_IdleRules(t *testing.T) {
+	m, obj := newTestManager(t, ProfilePerformance)
+	require.NoError(t, m.SetRules(Rules{Enabled: true, OnIdle: ProfilePowerSaver, OnBattery: ProfileBalanced}))
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePowerSaver)).
+		Return(&dbus.Call{}).Once()
+	m.SetIdle(true)
+	assert.Equal(t, ProfilePowerSaver, m.GetState().ActiveProfile)
+	assert.True(t, m.GetState().Idle)
+
+	// Unplugging while idle only updates the profile to restore.
+	m.SetOnBattery(true)
+	assert.Equal(t, ProfilePowerSaver, m.GetState().ActiveProfile)
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfileBalanced)).
+		Return(&dbus.Call{}).Once()
+	m.SetIdle(false)
+	assert.Equal(t, ProfileBalanced, m.GetState().ActiveProfile)
+}
+
+func TestManager_IdleRestoresPreviousProfile(t *testing.T) {
+	m, obj := newTestManager(t, ProfilePerformance)
+	require.NoError(t, m.SetRules(Rules{Enabled: true, OnIdle: ProfilePowerSaver}))
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePowerSaver)).
+		Return(&dbus.Call{}).Once()
+	m.SetIdle(true)
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePerformance)).
+		Return(&dbus.Call{}).Once()
+	m.SetIdle(false)
+
+	assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+}
+
+func TestManager_RulesDisabled(t *testing.T) {
+	m, _ := newTestManager(t, ProfileBalanced)
+	require.NoError(t, m.SetRules(Rules{Enabled: false, OnBattery: ProfilePowerSaver, OnIdle: ProfilePowerSaver}))
+
+	m.SetOnBattery(true)
+	m.SetIdle(true)
+	assert.Equal(t, ProfileBalanced, m.GetState().ActiveProfile)
+}
+
+func TestManager_SetRulesAppliesImmediately(t *testing.T) {
+	m, obj := newTestManager(t, ProfileBalanced)
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePerformance)).
+		Return(&dbus.Call{}).Once()
+
+	require.NoError(t, m.SetRules(Rules{Enabled: true, OnAC: ProfilePerformance}))
+	assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+
+	assert.Error(t, m.SetRules(Rules{Enabled: true, OnAC: "turbo"}))
+	assert.Equal(t, ProfilePerformance, m.GetRules().OnAC)
+}
+
+func TestManager_InitPowerSourceAppliesACRule(t *testing.T) {
+	m, obj := newTestManager(t, ProfileBalanced)
+	m.stateMutex.Lock()
+	m.state.Rules = Rules{Enabled: true, OnAC: ProfilePerformance}
+	m.stateMutex.Unlock()
+
+	obj.EXPECT().
+		Call(dbusPropsInterface+".Set", dbus.Flags(0), busServices[0].iface, "ActiveProfile", dbus.MakeVariant(ProfilePerformance)).
+		Return(&dbus.Call{}).Once()
+
+	m.initPowerSource(false)
+	assert.Equal(t, ProfilePerformance, m.GetState().ActiveProfile)
+	assert.False(t, m.GetState().OnBattery)
+}
+
+func TestManager_RulesPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DankMaterialShell", "power-profile-rules.json")
+	m, _ := newTestManager(t, ProfileBalanced)
+	m.rulesPath = path
+
+	rules := Rules{Enabled: true, OnBattery: ProfilePowerSaver, OnIdle: ProfilePowerSaver}
+	require.NoError(t, m.SetRules(rules))
+	assert.Equal(t, rules, loadRules(path))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"enabled":true,"onAC":"turbo"}`), 0o644))
+	assert.Equal(t, Rules{}, loadRules(path))
+	assert.Equal(t, Rules{}, loadRules(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -0,0 +1,75 @@
+package powerprofile
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+	"github.com/godbus/dbus/v5"
+)
+
+type DBusConn interface {
+	Object(dest string, path dbus.ObjectPath) dbus.BusObject
+	Signal(ch chan<- *dbus.Signal)
+	RemoveSignal(ch chan<- *dbus.Signal)
+	AddMatchSignal(options ...dbus.MatchOption) error
+	RemoveMatchSignal(options ...dbus.MatchOption) error
+	Close() error
+}
+
+type Profile struct {
+	Profile        string `json:"profile"`
+	Driver         string `json:"driver"`
+	CPUDriver      string `json:"cpuDriver,omitempty"`
+	PlatformDriver string `json:"platformDriver,omitempty"`
+}
+
+type Hold struct {
+	Profile       string `json:"profile"`
+	Reason        string `json:"reason"`
+	ApplicationID string `json:"applicationId"`
+}
+
+// Rules describe automatic profile switches. An empty profile leaves the
+// current profile untouched for that transition.
+type Rules struct {
+	Enabled   bool   `json:"enabled"`
+	OnAC      string `json:"onAC"`
+	OnBattery string `json:"onBattery"`
+	OnIdle    string `json:"onIdle"`
+}
+
+type State struct {
+	Available           bool      `json:"available"`
+	Service             string    `json:"service"`
+	Version             string    `json:"version"`
+	ActiveProfile       string    `json:"activeProfile"`
+	Profiles            []Profile `json:"profiles"`
+	PerformanceDegraded string    `json:"performanceDegraded"`
+	Actions             []string  `json:"actions"`
+	Holds               []Hold    `json:"holds"`
+	Rules               Rules     `json:"rules"`
+	OnBattery           bool      `json:"onBattery"`
+	Idle                bool      `json:"idle"`
+}
+
+type Manager struct {
+	state      *State
+	stateMutex sync.RWMutex
+
+	subscribers syncmap.Map[string, chan State]
+
+	conn    DBusConn
+	service busService
+	obj     dbus.BusObject
+
+	// preIdleProfile remembers the profile active when an idle rule kicked
+	// in so it can be restored once the session is active again.
+	ruleMutex      sync.Mutex
+	preIdleProfile string
+	rulesPath      string
+
+	signals  chan *dbus.Signal
+	sigWG    sync.WaitGroup
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
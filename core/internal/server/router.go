@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/apppicker"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/audio"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/bluez"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/clipboard"
@@ -18,12 +19,17 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/mime"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/mpris"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/network"
 	serverPlugins "github.com/AvengeMedia/DankMaterialShell/core/internal/server/plugins"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/powerprofile"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenrecord"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/sysupdate"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/tailscale"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/thememode"
 	serverThemes "github.com/AvengeMedia/DankMaterialShell/core/internal/server/themes"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/upower"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wayland"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlroutput"
 )
@@ -57,6 +63,24 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "screenrecord.") {
+		if screenRecordManager == nil {
+			models.RespondError(conn, req.ID, "screen recording manager not initialized")
+			return
+		}
+		screenrecord.HandleRequest(conn, req, screenRecordManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "screenshot.") {
+		if screenshotManager == nil {
+			models.RespondError(conn, req.ID, "screenshot manager not initialized")
+			return
+		}
+		screenshot.HandleRequest(conn, req, screenshotManager)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "loginctl.") {
 		if loginctlManager == nil {
 			models.RespondError(conn, req.ID, "loginctl manager not initialized")
@@ -206,6 +230,42 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "battery.") {
+		if upowerManager == nil {
+			models.RespondError(conn, req.ID, "battery manager not initialized")
+			return
+		}
+		upower.HandleRequest(conn, req, upowerManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "powerprofile.") {
+		if powerProfileManager == nil {
+			models.RespondError(conn, req.ID, "power profile manager not initialized")
+			return
+		}
+		powerprofile.HandleRequest(conn, req, powerProfileManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "mpris.") {
+		if mprisManager == nil {
+			models.RespondError(conn, req.ID, "mpris manager not initialized")
+			return
+		}
+		mpris.HandleRequest(conn, req, mprisManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "audio.") {
+		if audioManager == nil {
+			models.RespondError(conn, req.ID, "audio manager not initialized")
+			return
+		}
+		audio.HandleRequest(conn, req, audioManager)
+		return
+	}
+
 	switch req.Method {
 	case "ping":
 		models.Respond(conn, req.ID, "pong")
@@ -0,0 +1,126 @@
+package screenrecord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	switch req.Method {
+	case "screenrecord.getState":
+		handleGetState(conn, req, manager)
+	case "screenrecord.start":
+		handleStart(conn, req, manager)
+	case "screenrecord.stop":
+		handleStop(conn, req, manager)
+	case "screenrecord.toggle":
+		handleToggle(conn, req, manager)
+	case "screenrecord.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func recordConfig(p map[string]any) (screenshot.RecordConfig, error) {
+	cfg := screenshot.DefaultRecordConfig()
+
+	mode, err := ParseMode(params.StringOpt(p, "mode", "region"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Mode = mode
+
+	cfg.OutputName = params.StringOpt(p, "output", "")
+	if cfg.Mode == screenshot.ModeOutput && cfg.OutputName == "" {
+		return cfg, fmt.Errorf("missing or invalid 'output' parameter")
+	}
+
+	format, err := screenshot.ParseRecordFormat(params.StringOpt(p, "format", "auto"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Format = format
+
+	if !params.BoolOpt(p, "cursor", true) {
+		cfg.Cursor = screenshot.CursorOff
+	}
+	cfg.FPS = params.IntOpt(p, "fps", 0)
+	cfg.Path = params.StringOpt(p, "path", "")
+	cfg.Reset = params.BoolOpt(p, "reset", false)
+	if secs := params.FloatOpt(p, "duration", 0); secs > 0 {
+		cfg.MaxDuration = time.Duration(secs * float64(time.Second))
+	}
+	return cfg, nil
+}
+
+func handleGetState(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleStart(conn net.Conn, req models.Request, manager *Manager) {
+	cfg, err := recordConfig(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	state, err := manager.Start(cfg)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleStop(conn net.Conn, req models.Request, manager *Manager) {
+	result, err := manager.Stop()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleToggle(conn net.Conn, req models.Request, manager *Manager) {
+	cfg, err := recordConfig(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	state, err := manager.Toggle(cfg)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package screenrecord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+func NewManager() *Manager {
+	return &Manager{run: runRecorder}
+}
+
+func runRecorder(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error) {
+	return screenshot.NewRecorder(cfg).Run(ctx, started)
+}
+
+func ParseMode(s string) (screenshot.Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "region":
+		return screenshot.ModeRegion, nil
+	case "last":
+		return screenshot.ModeLastRegion, nil
+	case "full":
+		return screenshot.ModeFullScreen, nil
+	case "output":
+		return screenshot.ModeOutput, nil
+	case "window":
+		return screenshot.ModeWindow, nil
+	}
+	return screenshot.ModeRegion, fmt.Errorf("unknown recording mode %q (region, last, full, output, window)", s)
+}
+
+func modeName(m screenshot.Mode) string {
+	switch m {
+	case screenshot.ModeLastRegion:
+		return "last"
+	case screenshot.ModeFullScreen:
+		return "full"
+	case screenshot.ModeOutput:
+		return "output"
+	case screenshot.ModeWindow:
+		return "window"
+	default:
+		return "region"
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state
+}
+
+func (m *Manager) IsRecording() bool {
+	return m.GetState().Recording
+}
+
+// Start begins a recording and returns once the first frame is captured.
+// In region mode that includes the interactive selection.
+func (m *Manager) Start(cfg screenshot.RecordConfig) (State, error) {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.mu.Unlock()
+		return State{}, fmt.Errorf("a recording is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.cancel, m.done = cancel, done
+	m.mu.Unlock()
+
+	started := make(chan struct{}, 1)
+	var runErr error
+
+	go func() {
+		defer close(done)
+		res, err := m.run(ctx, cfg, func(rec screenshot.Recording) {
+			// Set here rather than in Start so finish, which runs on this
+			// goroutine, always sees the recording it has to clear.
+			m.stateMutex.Lock()
+			m.state.Recording = true
+			m.state.Mode = modeName(cfg.Mode)
+			m.state.Format = rec.Format.String()
+			m.state.Path = rec.Path
+			m.state.Region = &rec.Region
+			m.state.StartedAt = &rec.StartedAt
+			m.state.LastError = ""
+			m.stateMutex.Unlock()
+			m.notifySubscribers()
+
+			log.Infof("Screen recording started: %s", rec.Path)
+			started <- struct{}{}
+		})
+		runErr = err
+		m.finish(res, err)
+	}()
+
+	select {
+	case <-started:
+		select {
+		case <-done:
+			if runErr != nil {
+				return State{}, runErr
+			}
+		default:
+		}
+		return m.GetState(), nil
+
+	case <-done:
+		if runErr != nil {
+			return State{}, runErr
+		}
+		return State{}, fmt.Errorf("recording cancelled")
+	}
+}
+
+func (m *Manager) finish(res *screenshot.RecordResult, err error) {
+	m.mu.Lock()
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	m.stateMutex.Lock()
+	wasRecording := m.state.Recording
+	m.state.Recording = false
+	m.state.Mode = ""
+	m.state.Format = ""
+	m.state.Path = ""
+	m.state.Region = nil
+	m.state.StartedAt = nil
+	if res != nil {
+		m.state.LastRecording = &Result{
+			Path:     res.Path,
+			Format:   res.Format.String(),
+			Frames:   res.Frames,
+			Duration: res.Duration.Seconds(),
+		}
+	}
+	m.state.LastError = ""
+	if err != nil {
+		m.state.LastError = err.Error()
+	}
+	m.stateMutex.Unlock()
+
+	switch {
+	case err != nil:
+		log.Warnf("Screen recording failed: %v", err)
+	case res != nil:
+		log.Infof("Screen recording saved: %s (%d frames, %.1fs)", res.Path, res.Frames, res.Duration.Seconds())
+	}
+
+	if wasRecording {
+		m.notifySubscribers()
+	}
+}
+
+// Stop ends the running recording and waits for the file to be finished.
+func (m *Manager) Stop() (*Result, error) {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return nil, fmt.Errorf("no recording in progress")
+	}
+	cancel()
+	<-done
+
+	state := m.GetState()
+	if state.LastError != "" {
+		return state.LastRecording, fmt.Errorf("%s", state.LastError)
+	}
+	return state.LastRecording, nil
+}
+
+// Toggle stops a running recording or starts a new one, so a single
+// keybind can drive both.
+func (m *Manager) Toggle(cfg screenshot.RecordConfig) (State, error) {
+	m.mu.Lock()
+	running := m.cancel != nil
+	m.mu.Unlock()
+
+	if !running {
+		return m.Start(cfg)
+	}
+	if _, err := m.Stop(); err != nil {
+		return m.GetState(), err
+	}
+	return m.GetState(), nil
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if val, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(val)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	state := m.GetState()
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+		}
+		return true
+	})
+}
+
+func (m *Manager) Close() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,111 @@
+package screenrecord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+// fakeRun records until cancelled, like the real recorder.
+func fakeRun(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error) {
+	start := time.Now()
+	started(screenshot.Recording{Path: "/tmp/rec.gif", Format: screenshot.RecordGIF, StartedAt: start})
+	<-ctx.Done()
+	return &screenshot.RecordResult{Path: "/tmp/rec.gif", Format: screenshot.RecordGIF, Frames: 3, Duration: time.Since(start)}, nil
+}
+
+func TestManager_StartStop(t *testing.T) {
+	m := &Manager{run: fakeRun}
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	state, err := m.Start(screenshot.DefaultRecordConfig())
+	require.NoError(t, err)
+	assert.True(t, state.Recording)
+	assert.Equal(t, "gif", state.Format)
+	assert.NotNil(t, state.StartedAt)
+	assert.True(t, (<-ch).Recording)
+
+	_, err = m.Start(screenshot.DefaultRecordConfig())
+	assert.ErrorContains(t, err, "already running")
+
+	res, err := m.Stop()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/rec.gif", res.Path)
+	assert.Equal(t, 3, res.Frames)
+
+	final := <-ch
+	assert.False(t, final.Recording)
+	assert.Nil(t, final.StartedAt)
+	assert.Equal(t, res, final.LastRecording)
+
+	_, err = m.Stop()
+	assert.ErrorContains(t, err, "no recording")
+}
+
+func TestManager_StartReportsFailures(t *testing.T) {
+	m := &Manager{run: func(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error) {
+		return nil, errors.New("compositor does not support wlr-screencopy-unstable-v1")
+	}}
+
+	_, err := m.Start(screenshot.DefaultRecordConfig())
+	assert.ErrorContains(t, err, "wlr-screencopy")
+	assert.False(t, m.IsRecording())
+
+	// A cancelled region selection is not an error worth surfacing.
+	m.run = func(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error) {
+		return nil, nil
+	}
+	_, err = m.Start(screenshot.DefaultRecordConfig())
+	assert.ErrorContains(t, err, "cancelled")
+	assert.Empty(t, m.GetState().LastError)
+}
+
+func TestManager_FailureAfterStart(t *testing.T) {
+	m := &Manager{run: func(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error) {
+		started(screenshot.Recording{Path: "/tmp/rec.gif", Format: screenshot.RecordGIF, StartedAt: time.Now()})
+		return nil, errors.New("output disconnected")
+	}}
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	for range 50 {
+		_, _ = m.Start(screenshot.DefaultRecordConfig())
+
+		assert.True(t, (<-ch).Recording)
+		assert.False(t, (<-ch).Recording)
+		assert.False(t, m.IsRecording())
+		assert.Equal(t, "output disconnected", m.GetState().LastError)
+	}
+}
+
+func TestManager_Toggle(t *testing.T) {
+	m := &Manager{run: fakeRun}
+
+	state, err := m.Toggle(screenshot.DefaultRecordConfig())
+	require.NoError(t, err)
+	assert.True(t, state.Recording)
+
+	state, err = m.Toggle(screenshot.DefaultRecordConfig())
+	require.NoError(t, err)
+	assert.False(t, state.Recording)
+	require.NotNil(t, state.LastRecording)
+}
+
+func TestRecordConfig(t *testing.T) {
+	cfg, err := recordConfig(map[string]any{"mode": "output", "output": "DP-1", "format": "apng", "cursor": false, "duration": 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, screenshot.ModeOutput, cfg.Mode)
+	assert.Equal(t, screenshot.RecordAPNG, cfg.Format)
+	assert.Equal(t, screenshot.CursorOff, cfg.Cursor)
+	assert.Equal(t, 1500*time.Millisecond, cfg.MaxDuration)
+
+	_, err = recordConfig(map[string]any{"mode": "output"})
+	assert.ErrorContains(t, err, "output")
+}
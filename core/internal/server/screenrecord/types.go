@@ -0,0 +1,46 @@
+package screenrecord
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Result struct {
+	Path     string  `json:"path"`
+	Format   string  `json:"format"`
+	Frames   int     `json:"frames"`
+	Duration float64 `json:"duration"`
+}
+
+// State is what a bar indicator needs: whether a recording is running and
+// since when, plus the outcome of the last one.
+type State struct {
+	Recording     bool               `json:"recording"`
+	Mode          string             `json:"mode,omitempty"`
+	Format        string             `json:"format,omitempty"`
+	Path          string             `json:"path,omitempty"`
+	Region        *screenshot.Region `json:"region,omitempty"`
+	StartedAt     *time.Time         `json:"startedAt,omitempty"`
+	LastRecording *Result            `json:"lastRecording,omitempty"`
+	LastError     string             `json:"lastError,omitempty"`
+}
+
+type runFunc func(ctx context.Context, cfg screenshot.RecordConfig, started func(screenshot.Recording)) (*screenshot.RecordResult, error)
+
+type Manager struct {
+	state      State
+	stateMutex sync.RWMutex
+
+	subscribers syncmap.Map[string, chan State]
+
+	run runFunc
+
+	// mu guards the running recording; cancel is nil when idle.
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
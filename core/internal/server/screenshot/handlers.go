@@ -0,0 +1,194 @@
+package screenshot
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	capture "github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	switch req.Method {
+	case "screenshot.capture":
+		handleCapture(conn, req, manager)
+	case "screenshot.cancel":
+		handleCancel(conn, req, manager)
+	case "screenshot.history.list":
+		handleHistoryList(conn, req)
+	case "screenshot.history.delete":
+		handleHistoryDelete(conn, req)
+	case "screenshot.history.copy":
+		handleHistoryCopy(conn, req)
+	case "screenshot.history.open":
+		handleHistoryOpen(conn, req)
+	case "screenshot.history.prune":
+		handleHistoryPrune(conn, req)
+	case "screenshot.history.setPolicy":
+		handleHistorySetPolicy(conn, req)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+// captureConfig maps request params onto a capture config. Keys follow
+// the CLI flags; stdout makes no sense over the socket and is never set.
+func captureConfig(p map[string]any) (capture.Config, error) {
+	cfg := capture.DefaultConfig()
+
+	mode, err := capture.ParseMode(params.StringOpt(p, "mode", "region"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Mode = mode
+
+	cfg.OutputName = params.StringOpt(p, "output", "")
+	if cfg.Mode == capture.ModeOutput && cfg.OutputName == "" {
+		return cfg, fmt.Errorf("missing or invalid 'output' parameter")
+	}
+
+	format, err := capture.ParseFormat(params.StringOpt(p, "format", "png"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Format = format
+	cfg.Quality = min(max(params.IntOpt(p, "quality", cfg.Quality), 1), 100)
+	cfg.Lossless = params.BoolOpt(p, "lossless", false)
+	cfg.Metadata = params.BoolOpt(p, "metadata", false)
+
+	switch v := p["maxSize"].(type) {
+	case string:
+		if cfg.MaxSize, err = capture.ParseSize(v); err != nil {
+			return cfg, err
+		}
+	case float64:
+		cfg.MaxSize = int64(v)
+	}
+
+	if params.BoolOpt(p, "cursor", false) {
+		cfg.Cursor = capture.CursorOn
+	}
+	cfg.OutputDir = params.StringOpt(p, "dir", "")
+	cfg.Filename = params.StringOpt(p, "filename", "")
+	cfg.Template = params.StringOpt(p, "template", "")
+	cfg.Clipboard = params.BoolOpt(p, "clipboard", true)
+	cfg.SaveFile = params.BoolOpt(p, "save", true)
+	cfg.Notify = params.BoolOpt(p, "notify", true)
+	cfg.NoConfirm = params.BoolOpt(p, "noConfirm", false)
+	cfg.Reset = params.BoolOpt(p, "reset", false)
+	cfg.Annotate = params.BoolOpt(p, "annotate", false)
+	cfg.History = params.BoolOpt(p, "history", true)
+
+	cfg.Delay = seconds(params.FloatOpt(p, "delay", 0))
+	cfg.Countdown = params.BoolOpt(p, "countdown", true)
+	cfg.Repeat = max(params.IntOpt(p, "repeat", 1), 1)
+	cfg.Interval = seconds(params.FloatOpt(p, "interval", cfg.Interval.Seconds()))
+	return cfg, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(max(s, 0) * float64(time.Second))
+}
+
+func handleCapture(conn net.Conn, req models.Request, manager *Manager) {
+	cfg, err := captureConfig(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	result, err := manager.Capture(cfg)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleCancel(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, models.SuccessResult{Success: manager.Cancel()})
+}
+
+func handleHistoryList(conn net.Conn, req models.Request) {
+	history, err := capture.LoadHistory()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	entries, err := capture.ListHistory(params.IntOpt(req.Params, "limit", 0))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, HistoryResult{Entries: entries, Policy: history.Policy})
+}
+
+func handleHistoryDelete(conn net.Conn, req models.Request) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if err := capture.DeleteHistory(id, params.BoolOpt(req.Params, "deleteFile", false)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "deleted"})
+}
+
+func handleHistoryCopy(conn net.Conn, req models.Request) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if err := capture.CopyHistory(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "copied to clipboard"})
+}
+
+func handleHistoryOpen(conn net.Conn, req models.Request) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if err := capture.OpenHistory(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "opened"})
+}
+
+func handleHistoryPrune(conn net.Conn, req models.Request) {
+	removed, err := capture.PruneHistory()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, PruneResult{Removed: removed})
+}
+
+func handleHistorySetPolicy(conn net.Conn, req models.Request) {
+	history, err := capture.LoadHistory()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	policy := history.Policy
+	policy.MaxEntries = max(params.IntOpt(req.Params, "maxEntries", policy.MaxEntries), 0)
+	policy.MaxAgeDays = max(params.IntOpt(req.Params, "maxAgeDays", policy.MaxAgeDays), 0)
+	policy.DeleteFiles = params.BoolOpt(req.Params, "deleteFiles", policy.DeleteFiles)
+
+	removed, err := capture.SetHistoryPolicy(policy)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, PruneResult{Removed: removed, Policy: &policy})
+}
@@ -0,0 +1,67 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	capture "github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+func NewManager() *Manager {
+	return &Manager{take: capture.Take}
+}
+
+// Capture runs one capture request to completion. Only one runs at a time
+// since region selection and annotation grab the whole seat.
+func (m *Manager) Capture(cfg capture.Config) (*CaptureResult, error) {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("a capture is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.cancel = nil
+		m.mu.Unlock()
+		cancel()
+	}()
+
+	shots, err := m.take(ctx, cfg)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &CaptureResult{Cancelled: true, Shots: shots}, nil
+	case err != nil:
+		log.Warnf("Screenshot failed: %v", err)
+		return nil, err
+	}
+
+	result := &CaptureResult{Shots: shots, Cancelled: len(shots) == 0}
+	if len(shots) > 0 {
+		last := shots[len(shots)-1]
+		result.Path, result.Width, result.Height = last.Path, last.Width, last.Height
+		log.Infof("Screenshot taken: %dx%d %s", last.Width, last.Height, last.Path)
+	}
+	return result, nil
+}
+
+// Cancel aborts a pending delay or burst. A capture already on screen
+// finishes first.
+func (m *Manager) Cancel() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel == nil {
+		return false
+	}
+	m.cancel()
+	return true
+}
+
+func (m *Manager) Close() {
+	m.Cancel()
+}
@@ -0,0 +1,114 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	capture "github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+func TestManager_Capture(t *testing.T) {
+	m := &Manager{take: func(ctx context.Context, cfg capture.Config) ([]capture.Shot, error) {
+		shots := make([]capture.Shot, cfg.Repeat)
+		for i := range shots {
+			shots[i] = capture.Shot{Path: "/tmp/shot.png", Width: 640 + i, Height: 480}
+		}
+		return shots, nil
+	}}
+
+	cfg := capture.DefaultConfig()
+	cfg.Repeat = 3
+	res, err := m.Capture(cfg)
+	require.NoError(t, err)
+	assert.False(t, res.Cancelled)
+	assert.Len(t, res.Shots, 3)
+	assert.Equal(t, "/tmp/shot.png", res.Path)
+	assert.Equal(t, 642, res.Width)
+	assert.Equal(t, 480, res.Height)
+
+	m.take = func(ctx context.Context, cfg capture.Config) ([]capture.Shot, error) {
+		return nil, nil
+	}
+	res, err = m.Capture(cfg)
+	require.NoError(t, err)
+	assert.True(t, res.Cancelled)
+
+	m.take = func(ctx context.Context, cfg capture.Config) ([]capture.Shot, error) {
+		return nil, errors.New("no outputs available")
+	}
+	_, err = m.Capture(cfg)
+	assert.ErrorContains(t, err, "no outputs")
+}
+
+func TestManager_CancelDuringDelay(t *testing.T) {
+	started := make(chan struct{})
+	m := &Manager{take: func(ctx context.Context, cfg capture.Config) ([]capture.Shot, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	assert.False(t, m.Cancel())
+
+	done := make(chan *CaptureResult)
+	go func() {
+		res, err := m.Capture(capture.DefaultConfig())
+		assert.NoError(t, err)
+		done <- res
+	}()
+	<-started
+
+	_, err := m.Capture(capture.DefaultConfig())
+	assert.ErrorContains(t, err, "already running")
+
+	assert.True(t, m.Cancel())
+	select {
+	case res := <-done:
+		assert.True(t, res.Cancelled)
+	case <-time.After(time.Second):
+		t.Fatal("capture was not cancelled")
+	}
+}
+
+func TestCaptureConfig(t *testing.T) {
+	cfg, err := captureConfig(map[string]any{
+		"mode":     "output",
+		"output":   "DP-1",
+		"format":   "webp",
+		"lossless": true,
+		"maxSize":  "2M",
+		"delay":    2.5,
+		"repeat":   float64(4),
+		"interval": 0.25,
+		"notify":   false,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, capture.ModeOutput, cfg.Mode)
+	assert.Equal(t, capture.FormatWebP, cfg.Format)
+	assert.True(t, cfg.Lossless)
+	assert.Equal(t, int64(2<<20), cfg.MaxSize)
+	assert.Equal(t, 2500*time.Millisecond, cfg.Delay)
+	assert.True(t, cfg.Countdown)
+	assert.Equal(t, 4, cfg.Repeat)
+	assert.Equal(t, 250*time.Millisecond, cfg.Interval)
+	assert.False(t, cfg.Notify)
+	assert.True(t, cfg.Clipboard)
+	assert.False(t, cfg.Stdout)
+
+	cfg, err = captureConfig(map[string]any{"mode": "all", "maxSize": float64(1000)})
+	require.NoError(t, err)
+	assert.Equal(t, capture.ModeAllScreens, cfg.Mode)
+	assert.Equal(t, int64(1000), cfg.MaxSize)
+	assert.Equal(t, 1, cfg.Repeat)
+
+	_, err = captureConfig(map[string]any{"mode": "output"})
+	assert.ErrorContains(t, err, "output")
+	_, err = captureConfig(map[string]any{"mode": "screen"})
+	assert.Error(t, err)
+	_, err = captureConfig(map[string]any{"format": "bmp"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package screenshot
+
+import (
+	"context"
+	"sync"
+
+	capture "github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+// CaptureResult is the response to screenshot.capture. Path, Width and
+// Height repeat the last shot so single captures need not dig into Shots.
+type CaptureResult struct {
+	Cancelled bool           `json:"cancelled"`
+	Path      string         `json:"path,omitempty"`
+	Width     int            `json:"width,omitempty"`
+	Height    int            `json:"height,omitempty"`
+	Shots     []capture.Shot `json:"shots"`
+}
+
+type HistoryResult struct {
+	Entries []capture.HistoryEntry `json:"entries"`
+	Policy  capture.HistoryPolicy  `json:"policy"`
+}
+
+type PruneResult struct {
+	Removed int                    `json:"removed"`
+	Policy  *capture.HistoryPolicy `json:"policy,omitempty"`
+}
+
+type takeFunc func(ctx context.Context, cfg capture.Config) ([]capture.Shot, error)
+
+type Manager struct {
+	take takeFunc
+
+	// mu guards the running capture; cancel is nil when idle.
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
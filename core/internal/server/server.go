@@ -17,6 +17,7 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/geolocation"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/apppicker"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/audio"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/bluez"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/clipboard"
@@ -28,18 +29,23 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/location"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/mpris"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/network"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/powerprofile"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenrecord"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/sysupdate"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/tailscale"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/thememode"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/trayrecovery"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/upower"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wayland"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlroutput"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
 )
 
-const APIVersion = 24
+const APIVersion = 42
 
 var CLIVersion = "dev"
 
@@ -74,9 +80,15 @@ var clipboardManager *clipboard.Manager
 var dbusManager *serverDbus.Manager
 var wlContext *wlcontext.SharedContext
 var themeModeManager *thememode.Manager
+var screenRecordManager *screenrecord.Manager
+var screenshotManager *screenshot.Manager
 var trayRecoveryManager *trayrecovery.Manager
 var locationManager *location.Manager
 var sysUpdateManager *sysupdate.Manager
+var upowerManager *upower.Manager
+var powerProfileManager *powerprofile.Manager
+var mprisManager *mpris.Manager
+var audioManager *audio.Manager
 var geoClientInstance geolocation.Client
 
 const dbusClientID = "dms-dbus-client"
@@ -372,6 +384,20 @@ func InitializeThemeModeManager() error {
 	return nil
 }
 
+func InitializeScreenRecordManager() error {
+	screenRecordManager = screenrecord.NewManager()
+
+	log.Info("Screen recording manager initialized")
+	return nil
+}
+
+func InitializeScreenshotManager() error {
+	screenshotManager = screenshot.NewManager()
+
+	log.Info("Screenshot manager initialized")
+	return nil
+}
+
 func InitializeTrayRecoveryManager() error {
 	manager, err := trayrecovery.NewManager()
 	if err != nil {
@@ -410,6 +436,58 @@ func InitializeSysUpdateManager() error {
 	return nil
 }
 
+func InitializeUPowerManager() error {
+	manager, err := upower.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize upower manager: %v", err)
+		return err
+	}
+
+	upowerManager = manager
+
+	log.Info("UPower manager initialized")
+	return nil
+}
+
+func InitializePowerProfileManager() error {
+	manager, err := powerprofile.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize power profile manager: %v", err)
+		return err
+	}
+
+	powerProfileManager = manager
+
+	log.Info("Power profile manager initialized")
+	return nil
+}
+
+func InitializeMprisManager() error {
+	manager, err := mpris.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize MPRIS manager: %v", err)
+		return err
+	}
+
+	mprisManager = manager
+
+	log.Info("MPRIS manager initialized")
+	return nil
+}
+
+func InitializeAudioManager() error {
+	manager, err := audio.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize audio manager: %v", err)
+		return err
+	}
+
+	audioManager = manager
+
+	log.Info("Audio manager initialized")
+	return nil
+}
+
 func handleConnection(conn net.Conn) {
 	defer conn.Close()
 
@@ -491,6 +569,14 @@ func getCapabilities() Capabilities {
 		caps = append(caps, "theme.auto")
 	}
 
+	if screenRecordManager != nil {
+		caps = append(caps, "screenrecord")
+	}
+
+	if screenshotManager != nil {
+		caps = append(caps, "screenshot")
+	}
+
 	if dbusManager != nil {
 		caps = append(caps, "dbus")
 	}
@@ -499,6 +585,22 @@ func getCapabilities() Capabilities {
 		caps = append(caps, "sysupdate")
 	}
 
+	if upowerManager != nil {
+		caps = append(caps, "battery")
+	}
+
+	if powerProfileManager != nil {
+		caps = append(caps, "powerprofile")
+	}
+
+	if mprisManager != nil {
+		caps = append(caps, "mpris")
+	}
+
+	if audioManager != nil {
+		caps = append(caps, "audio")
+	}
+
 	return Capabilities{Capabilities: caps}
 }
 
@@ -561,6 +663,14 @@ func getServerInfo() ServerInfo {
 		caps = append(caps, "theme.auto")
 	}
 
+	if screenRecordManager != nil {
+		caps = append(caps, "screenrecord")
+	}
+
+	if screenshotManager != nil {
+		caps = append(caps, "screenshot")
+	}
+
 	if locationManager != nil {
 		caps = append(caps, "location")
 	}
@@ -573,6 +683,22 @@ func getServerInfo() ServerInfo {
 		caps = append(caps, "sysupdate")
 	}
 
+	if upowerManager != nil {
+		caps = append(caps, "battery")
+	}
+
+	if powerProfileManager != nil {
+		caps = append(caps, "powerprofile")
+	}
+
+	if mprisManager != nil {
+		caps = append(caps, "mpris")
+	}
+
+	if audioManager != nil {
+		caps = append(caps, "audio")
+	}
+
 	return ServerInfo{
 		APIVersion:   APIVersion,
 		CLIVersion:   CLIVersion,
@@ -713,6 +839,40 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
+	if shouldSubscribe("network.stats") && networkManager != nil {
+		if trafficChan := networkManager.SubscribeTraffic(clientID + "-traffic"); trafficChan != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer networkManager.UnsubscribeTraffic(clientID + "-traffic")
+
+				if initialStats, err := networkManager.GetTrafficStats(true, false); err == nil {
+					select {
+					case eventChan <- ServiceEvent{Service: "network.stats", Data: initialStats}:
+					case <-stopChan:
+						return
+					}
+				}
+
+				for {
+					select {
+					case stats, ok := <-trafficChan:
+						if !ok {
+							return
+						}
+						select {
+						case eventChan <- ServiceEvent{Service: "network.stats", Data: stats}:
+						case <-stopChan:
+							return
+						}
+					case <-stopChan:
+						return
+					}
+				}
+			}()
+		}
+	}
+
 	if shouldSubscribe("loginctl") && loginctlManager != nil {
 		wg.Add(1)
 		loginChan := loginctlManager.Subscribe(clientID + "-loginctl")
@@ -873,6 +1033,38 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
+	if shouldSubscribe("screenrecord") && screenRecordManager != nil {
+		wg.Add(1)
+		screenRecordChan := screenRecordManager.Subscribe(clientID + "-screenrecord")
+		go func() {
+			defer wg.Done()
+			defer screenRecordManager.Unsubscribe(clientID + "-screenrecord")
+
+			initialState := screenRecordManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "screenrecord", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-screenRecordChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "screenrecord", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
 	if shouldSubscribe("bluetooth") && bluezManager != nil {
 		wg.Add(1)
 		bluezChan := bluezManager.Subscribe(clientID + "-bluetooth")
@@ -1228,6 +1420,134 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
+	if shouldSubscribe("battery") && upowerManager != nil {
+		wg.Add(1)
+		batteryChan := upowerManager.Subscribe(clientID + "-battery")
+		go func() {
+			defer wg.Done()
+			defer upowerManager.Unsubscribe(clientID + "-battery")
+
+			initialState := upowerManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "battery", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-batteryChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "battery", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("powerprofile") && powerProfileManager != nil {
+		wg.Add(1)
+		powerProfileChan := powerProfileManager.Subscribe(clientID + "-powerprofile")
+		go func() {
+			defer wg.Done()
+			defer powerProfileManager.Unsubscribe(clientID + "-powerprofile")
+
+			initialState := powerProfileManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "powerprofile", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-powerProfileChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "powerprofile", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("mpris") && mprisManager != nil {
+		wg.Add(1)
+		mprisChan := mprisManager.Subscribe(clientID + "-mpris")
+		go func() {
+			defer wg.Done()
+			defer mprisManager.Unsubscribe(clientID + "-mpris")
+
+			initialState := mprisManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "mpris", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-mprisChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "mpris", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("audio") && audioManager != nil {
+		wg.Add(1)
+		audioChan := audioManager.Subscribe(clientID + "-audio")
+		go func() {
+			defer wg.Done()
+			defer audioManager.Unsubscribe(clientID + "-audio")
+
+			initialState := audioManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "audio", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-audioChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "audio", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
 	if shouldSubscribe("sysupdate") && sysUpdateManager != nil {
 		wg.Add(1)
 		sysupdateChan := sysUpdateManager.Subscribe(clientID + "-sysupdate")
@@ -1353,6 +1673,12 @@ func cleanupManagers() {
 	if themeModeManager != nil {
 		themeModeManager.Close()
 	}
+	if screenRecordManager != nil {
+		screenRecordManager.Close()
+	}
+	if screenshotManager != nil {
+		screenshotManager.Close()
+	}
 	if trayRecoveryManager != nil {
 		trayRecoveryManager.Close()
 	}
@@ -1365,6 +1691,18 @@ func cleanupManagers() {
 	if sysUpdateManager != nil {
 		sysUpdateManager.Close()
 	}
+	if powerProfileManager != nil {
+		powerProfileManager.Close()
+	}
+	if mprisManager != nil {
+		mprisManager.Close()
+	}
+	if audioManager != nil {
+		audioManager.Close()
+	}
+	if upowerManager != nil {
+		upowerManager.Close()
+	}
 	if geoClientInstance != nil {
 		geoClientInstance.Close()
 	}
@@ -1480,6 +1818,21 @@ func Start(printDocs bool) error {
 		log.Info(" theme.auto.setUseIPLocation           - Use IP location (params: use)")
 		log.Info(" theme.auto.trigger                    - Trigger immediate re-evaluation")
 		log.Info(" theme.auto.subscribe                  - Subscribe to theme automation state changes (streaming)")
+		log.Info("Screen recording:")
+		log.Info(" screenrecord.getState                 - Get recording state (recording, startedAt, path, last result)")
+		log.Info(" screenrecord.start                    - Start recording (params: mode? [region|last|full|output|window], output?, format? [auto|gif|apng|mp4|webm|mkv], fps?, cursor?, path?, duration?, reset?)")
+		log.Info(" screenrecord.stop                     - Stop recording and finish the file")
+		log.Info(" screenrecord.toggle                   - Stop if recording, otherwise start (params: same as start)")
+		log.Info(" screenrecord.subscribe                - Subscribe to recording state changes (streaming)")
+		log.Info("Screenshots:")
+		log.Info(" screenshot.capture                    - Take screenshots and return path/dimensions (params: mode? [region|window|full|all|output|last], output?, format?, quality?, lossless?, maxSize?, metadata?, cursor?, dir?, filename?, template?, clipboard?, save?, notify?, noConfirm?, reset?, annotate?, delay?, countdown?, repeat?, interval?, history?)")
+		log.Info(" screenshot.cancel                     - Abort a pending delay or burst")
+		log.Info(" screenshot.history.list               - List saved screenshots, newest first (params: limit?)")
+		log.Info(" screenshot.history.delete             - Remove a history entry (params: id, deleteFile?)")
+		log.Info(" screenshot.history.copy               - Copy a saved screenshot to the clipboard (params: id)")
+		log.Info(" screenshot.history.open               - Open a saved screenshot in the default viewer (params: id)")
+		log.Info(" screenshot.history.prune              - Apply the pruning policy and drop missing files")
+		log.Info(" screenshot.history.setPolicy          - Set pruning policy and prune (params: maxEntries?, maxAgeDays?, deleteFiles?)")
 		log.Info("Bluetooth:")
 		log.Info(" bluetooth.getState                    - Get current bluetooth state")
 		log.Info(" bluetooth.startDiscovery              - Start device discovery")
@@ -1520,6 +1873,11 @@ func Start(printDocs bool) error {
 		log.Info(" brightness.increment                  - Increment device brightness (params: device, step?)")
 		log.Info(" brightness.decrement                  - Decrement device brightness (params: device, step?)")
 		log.Info(" brightness.rescan                     - Rescan for brightness devices (e.g., after plugging in monitor)")
+		log.Info(" brightness.auto.getConfig             - Get ambient light auto-brightness configuration")
+		log.Info(" brightness.auto.setEnabled            - Enable/disable auto-brightness (params: enabled)")
+		log.Info(" brightness.auto.setCurve              - Set lux-to-percent curve (params: points, device?)")
+		log.Info(" brightness.auto.setConfig             - Set auto-brightness options (params: smoothing?, hysteresis?, devices?)")
+		log.Info(" brightness.auto.resume                - Resume auto-brightness after a manual change (params: device?)")
 		log.Info(" brightness.subscribe                  - Subscribe to brightness state changes (streaming)")
 		log.Info("   Subscription events:")
 		log.Info("     - brightness       : Full device list (on rescan, DDC discovery, device changes)")
@@ -1528,6 +1886,10 @@ func Start(printDocs bool) error {
 		log.Info(" wlroutput.getState                    - Get current output configuration state")
 		log.Info(" wlroutput.applyConfiguration          - Apply output configuration (params: heads)")
 		log.Info(" wlroutput.testConfiguration           - Test output configuration without applying (params: heads)")
+		log.Info(" wlroutput.profiles.list               - List saved display profiles and the active one")
+		log.Info(" wlroutput.profiles.save               - Save current layout as a profile (params: name)")
+		log.Info(" wlroutput.profiles.delete             - Delete a display profile (params: name)")
+		log.Info(" wlroutput.profiles.apply              - Apply a display profile (params: name)")
 		log.Info(" wlroutput.subscribe                   - Subscribe to output state changes (streaming)")
 		log.Info("   Head configuration params:")
 		log.Info("     - name         : Output name (required)")
@@ -1549,10 +1911,56 @@ func Start(printDocs bool) error {
 		log.Info(" clipboard.clearHistory                - Clear all clipboard history")
 		log.Info(" clipboard.copy                        - Copy text to clipboard (params: text)")
 		log.Info(" clipboard.paste                       - Get current clipboard text")
-		log.Info(" clipboard.search                      - Search history (params: query?, mimeType?, isImage?, limit?, offset?, before?, after?)")
+		log.Info(" clipboard.search                      - Search history, including text found in images (params: query?, mimeType?, isImage?, limit?, offset?, before?, after?)")
 		log.Info(" clipboard.getConfig                   - Get clipboard configuration")
-		log.Info(" clipboard.setConfig                   - Set configuration (params: maxHistory?, maxEntrySize?, autoClearDays?, clearAtStartup?)")
+		log.Info(" clipboard.setConfig                   - Set configuration (params: maxHistory?, maxEntrySize?, autoClearDays?, clearAtStartup?, rules?, mimeAllow?, mimeDeny?, sync?, ocr?)")
 		log.Info(" clipboard.subscribe                   - Subscribe to clipboard state changes (streaming)")
+		log.Info(" clipboard.getEncryption               - Get history encryption mode and lock state")
+		log.Info(" clipboard.unlock                      - Unlock encrypted history (params: passphrase?)")
+		log.Info(" clipboard.rekey                       - Re-encrypt history with a new key (params: mode, passphrase?)")
+		log.Info(" clipboard.sync.status                 - Get history sync status per peer")
+		log.Info(" clipboard.sync.now                    - Sync history with all peers now")
+		log.Info("Battery:")
+		log.Info(" battery.getState                      - Get UPower state (on battery, lid, display device, all batteries)")
+		log.Info(" battery.subscribe                     - Subscribe to battery state changes (streaming)")
+		log.Info("   Device fields include:")
+		log.Info("     - type         : battery, ups, line-power, mouse, keyboard, headset, ...")
+		log.Info("     - state        : charging, discharging, fully-charged, pending-charge, ...")
+		log.Info("     - percentage   : Charge level (0-100)")
+		log.Info("     - timeToEmpty  : Seconds until empty (0 if unknown)")
+		log.Info("     - timeToFull   : Seconds until full (0 if unknown)")
+		log.Info("     - capacity     : Battery health relative to design capacity (%)")
+		log.Info("     - warningLevel : none, low, critical, action (drives low-battery warnings)")
+		log.Info("Power profiles:")
+		log.Info(" powerprofile.getState                 - Get power-profiles-daemon state (active profile, profiles, holds, rules)")
+		log.Info(" powerprofile.set                      - Switch active profile (params: profile [power-saver|balanced|performance])")
+		log.Info(" powerprofile.listHolds                - List applications holding a profile")
+		log.Info(" powerprofile.getRules                 - Get automatic switching rules")
+		log.Info(" powerprofile.setRules                 - Set automatic switching rules (params: enabled?, onAC?, onBattery?, onIdle?)")
+		log.Info(" powerprofile.subscribe                - Subscribe to power profile state changes (streaming)")
+		log.Info("Media players (MPRIS):")
+		log.Info(" mpris.getState                        - Get all players and the active one (position in µs, positionTime in ms)")
+		log.Info(" mpris.playPause                       - Toggle playback (params: player?)")
+		log.Info(" mpris.play                            - Start playback (params: player?)")
+		log.Info(" mpris.pause                           - Pause playback (params: player?)")
+		log.Info(" mpris.stop                            - Stop playback (params: player?)")
+		log.Info(" mpris.next                            - Skip to next track (params: player?)")
+		log.Info(" mpris.previous                        - Skip to previous track (params: player?)")
+		log.Info(" mpris.seek                            - Seek relative to current position (params: offset [µs], player?)")
+		log.Info(" mpris.setPosition                     - Jump to absolute position (params: position [µs], player?)")
+		log.Info(" mpris.setVolume                       - Set player volume (params: volume [0-1], player?)")
+		log.Info(" mpris.setShuffle                      - Set shuffle (params: shuffle, player?)")
+		log.Info(" mpris.setLoopStatus                   - Set loop status (params: loopStatus [None|Track|Playlist], player?)")
+		log.Info(" mpris.setActive                       - Pin the player controlled by default (params: player)")
+		log.Info(" mpris.subscribe                       - Subscribe to player state changes (streaming)")
+		log.Info("Audio (PulseAudio / pipewire-pulse):")
+		log.Info(" audio.getState                        - Get sinks, sources, streams and defaults (volume 1.0 = 100%)")
+		log.Info(" audio.setVolume                       - Set volume (params: type? [sink|source|sinkInput|sourceOutput], name?, index?, volume | delta)")
+		log.Info(" audio.setMute                         - Set or toggle mute (params: type?, name?, index?, mute?)")
+		log.Info(" audio.setDefaultSink                  - Set default output (params: name)")
+		log.Info(" audio.setDefaultSource                - Set default input (params: name)")
+		log.Info(" audio.moveStream                      - Move a stream to another device (params: index, device, type? [sinkInput|sourceOutput])")
+		log.Info(" audio.subscribe                       - Subscribe to audio state changes (streaming)")
 		log.Info("Location:")
 		log.Info(" location.getState                      - Get current location state")
 		log.Info(" location.subscribe                     - Subscribe to location changes (streaming)")
@@ -1635,6 +2043,14 @@ func Start(printDocs bool) error {
 		log.Warnf("Wayland manager unavailable: %v", err)
 	}
 
+	if err := InitializeScreenRecordManager(); err != nil {
+		log.Warnf("Screen recording manager unavailable: %v", err)
+	}
+
+	if err := InitializeScreenshotManager(); err != nil {
+		log.Warnf("Screenshot manager unavailable: %v", err)
+	}
+
 	if err := InitializeThemeModeManager(); err != nil {
 		log.Warnf("Theme mode manager unavailable: %v", err)
 	} else {
@@ -1746,6 +2162,51 @@ func Start(printDocs bool) error {
 		}
 	}()
 
+	upowerReady := make(chan struct{})
+
+	go func() {
+		defer close(upowerReady)
+		if err := InitializeUPowerManager(); err != nil {
+			log.Debugf("UPower manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
+	go func() {
+		if err := InitializePowerProfileManager(); err != nil {
+			log.Debugf("Power profile manager unavailable: %v", err)
+			return
+		}
+		notifyCapabilityChange()
+
+		<-upowerReady
+		if upowerManager != nil {
+			powerProfileManager.WatchUPower(upowerManager)
+		}
+
+		<-loginctlReady
+		if loginctlManager != nil {
+			powerProfileManager.WatchLoginctl(loginctlManager)
+		}
+	}()
+
+	go func() {
+		if err := InitializeAudioManager(); err != nil {
+			log.Debugf("Audio manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
+	go func() {
+		if err := InitializeMprisManager(); err != nil {
+			log.Debugf("MPRIS manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
 	if err := InitializeSysUpdateManager(); err != nil {
 		log.Warnf("Sysupdate manager unavailable: %v", err)
 	}
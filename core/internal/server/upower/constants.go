@@ -0,0 +1,9 @@
+package upower
+
+const (
+	dbusDest            = "org.freedesktop.UPower"
+	dbusPath            = "/org/freedesktop/UPower"
+	dbusUPowerInterface = "org.freedesktop.UPower"
+	dbusDeviceInterface = "org.freedesktop.UPower.Device"
+	dbusPropsInterface  = "org.freedesktop.DBus.Properties"
+)
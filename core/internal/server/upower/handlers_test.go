@@ -0,0 +1,105 @@
+package upower
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNetConn struct {
+	net.Conn
+	readBuf  *bytes.Buffer
+	writeBuf *bytes.Buffer
+}
+
+func newMockNetConn() *mockNetConn {
+	return &mockNetConn{
+		readBuf:  &bytes.Buffer{},
+		writeBuf: &bytes.Buffer{},
+	}
+}
+
+func (m *mockNetConn) Read(b []byte) (n int, err error) {
+	return m.readBuf.Read(b)
+}
+
+func (m *mockNetConn) Write(b []byte) (n int, err error) {
+	return m.writeBuf.Write(b)
+}
+
+func TestHandleGetState(t *testing.T) {
+	manager := &Manager{
+		state: &BatteryState{
+			Available: true,
+			OnBattery: true,
+			Display:   &Device{Percentage: 15, WarningLevel: "low"},
+			Devices:   []Device{{Path: string(batteryPath), Type: "battery"}},
+		},
+	}
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{ID: 7, Method: "battery.getState"}, manager)
+
+	var resp models.Response[BatteryState]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+
+	assert.Equal(t, 7, resp.ID)
+	require.NotNil(t, resp.Result)
+	assert.True(t, resp.Result.OnBattery)
+	require.NotNil(t, resp.Result.Display)
+	assert.Equal(t, "low", resp.Result.Display.WarningLevel)
+	require.Len(t, resp.Result.Devices, 1)
+	assert.Equal(t, "battery", resp.Result.Devices[0].Type)
+}
+
+func TestHandleRequest_UnknownMethod(t *testing.T) {
+	manager := &Manager{state: &BatteryState{}}
+
+	conn := newMockNetConn()
+	HandleRequest(conn, models.Request{ID: 1, Method: "battery.unknown"}, manager)
+
+	var resp models.Response[any]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Equal(t, "unknown method: battery.unknown", resp.Error)
+}
+
+func TestHandleSubscribe(t *testing.T) {
+	manager := &Manager{state: &BatteryState{Available: true}}
+
+	conn := newMockNetConn()
+	done := make(chan struct{})
+	go func() {
+		handleSubscribe(conn, models.Request{ID: 3, Method: "battery.subscribe"}, manager)
+		close(done)
+	}()
+
+	var ch chan BatteryState
+	require.Eventually(t, func() bool {
+		manager.subscribers.Range(func(key string, c chan BatteryState) bool {
+			ch = c
+			return false
+		})
+		return ch != nil
+	}, time.Second, 10*time.Millisecond)
+
+	ch <- BatteryState{Available: true, OnBattery: true}
+	manager.Unsubscribe(fmt.Sprintf("client-%p", net.Conn(conn)))
+	<-done
+
+	dec := json.NewDecoder(conn.writeBuf)
+	var initial models.Response[BatteryState]
+	require.NoError(t, dec.Decode(&initial))
+	assert.Equal(t, 3, initial.ID)
+	assert.False(t, initial.Result.OnBattery)
+
+	var update models.Response[BatteryState]
+	require.NoError(t, dec.Decode(&update))
+	assert.True(t, update.Result.OnBattery)
+}
@@ -0,0 +1,466 @@
+package upower
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/dbusutil"
+	"github.com/godbus/dbus/v5"
+)
+
+func NewManager() (*Manager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	m, err := NewManagerWithConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func NewManagerWithConn(conn DBusConn) (*Manager, error) {
+	m := &Manager{
+		state:    &BatteryState{Devices: []Device{}},
+		stopChan: make(chan struct{}),
+		conn:     conn,
+		devices:  make(map[dbus.ObjectPath]*Device),
+		dirty:    make(chan struct{}, 1),
+		signals:  make(chan *dbus.Signal, 256),
+	}
+
+	m.upowerObj = conn.Object(dbusDest, dbus.ObjectPath(dbusPath))
+
+	if err := m.initialize(); err != nil {
+		return nil, err
+	}
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	if err := m.startSignalPump(); err != nil {
+		close(m.stopChan)
+		m.notifierWg.Wait()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Manager) initialize() error {
+	if err := m.updateDaemonState(); err != nil {
+		return fmt.Errorf("upower not available: %w", err)
+	}
+
+	var displayPath dbus.ObjectPath
+	if err := m.upowerObj.Call(dbusUPowerInterface+".GetDisplayDevice", 0).Store(&displayPath); err != nil {
+		log.Debugf("[UPower] no display device: %v", err)
+	}
+
+	var criticalAction string
+	if err := m.upowerObj.Call(dbusUPowerInterface+".GetCriticalAction", 0).Store(&criticalAction); err != nil {
+		log.Debugf("[UPower] failed to get critical action: %v", err)
+	}
+
+	var paths []dbus.ObjectPath
+	if err := m.upowerObj.Call(dbusUPowerInterface+".EnumerateDevices", 0).Store(&paths); err != nil {
+		return fmt.Errorf("failed to enumerate devices: %w", err)
+	}
+
+	devices := make(map[dbus.ObjectPath]*Device, len(paths))
+	for _, path := range paths {
+		dev, err := m.fetchDevice(path)
+		if err != nil {
+			log.Debugf("[UPower] skipping device %s: %v", path, err)
+			continue
+		}
+		devices[path] = dev
+	}
+
+	if displayPath != "" {
+		if dev, err := m.fetchDevice(displayPath); err == nil {
+			devices[displayPath] = dev
+		} else {
+			displayPath = ""
+		}
+	}
+
+	m.stateMutex.Lock()
+	m.displayPath = displayPath
+	m.devices = devices
+	m.state.Available = true
+	m.state.CriticalAction = criticalAction
+	m.rebuildDevicesLocked()
+	m.stateMutex.Unlock()
+
+	return nil
+}
+
+func (m *Manager) updateDaemonState() error {
+	var props map[string]dbus.Variant
+	if err := m.upowerObj.Call(dbusPropsInterface+".GetAll", 0, dbusUPowerInterface).Store(&props); err != nil {
+		return err
+	}
+
+	m.stateMutex.Lock()
+	m.applyDaemonPropsLocked(props)
+	m.stateMutex.Unlock()
+	return nil
+}
+
+func (m *Manager) applyDaemonPropsLocked(props map[string]dbus.Variant) bool {
+	changed := false
+	if v, ok := dbusutil.Get[string](props, "DaemonVersion"); ok {
+		m.state.DaemonVersion = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[bool](props, "OnBattery"); ok {
+		m.state.OnBattery = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[bool](props, "LidIsPresent"); ok {
+		m.state.LidIsPresent = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[bool](props, "LidIsClosed"); ok {
+		m.state.LidIsClosed = v
+		changed = true
+	}
+	return changed
+}
+
+func (m *Manager) fetchDevice(path dbus.ObjectPath) (*Device, error) {
+	obj := m.conn.Object(dbusDest, path)
+
+	var props map[string]dbus.Variant
+	if err := obj.Call(dbusPropsInterface+".GetAll", 0, dbusDeviceInterface).Store(&props); err != nil {
+		return nil, err
+	}
+
+	dev := &Device{Path: string(path)}
+	applyDeviceProps(dev, props)
+	return dev, nil
+}
+
+func applyDeviceProps(dev *Device, props map[string]dbus.Variant) bool {
+	changed := false
+	setString := func(key string, dst *string) {
+		if v, ok := dbusutil.Get[string](props, key); ok {
+			*dst = v
+			changed = true
+		}
+	}
+	setBool := func(key string, dst *bool) {
+		if v, ok := dbusutil.Get[bool](props, key); ok {
+			*dst = v
+			changed = true
+		}
+	}
+	setFloat := func(key string, dst *float64) {
+		if v, ok := dbusutil.Get[float64](props, key); ok {
+			*dst = v
+			changed = true
+		}
+	}
+	setEnum := func(key string, names map[uint32]string, dst *string) {
+		if v, ok := dbusutil.Get[uint32](props, key); ok {
+			*dst = enumName(names, v)
+			changed = true
+		}
+	}
+
+	setString("NativePath", &dev.NativePath)
+	setString("Vendor", &dev.Vendor)
+	setString("Model", &dev.Model)
+	setString("Serial", &dev.Serial)
+	setString("IconName", &dev.IconName)
+	setBool("PowerSupply", &dev.PowerSupply)
+	setBool("Online", &dev.Online)
+	setBool("IsPresent", &dev.IsPresent)
+	setBool("IsRechargeable", &dev.IsRechargeable)
+	setFloat("Percentage", &dev.Percentage)
+	setFloat("Energy", &dev.Energy)
+	setFloat("EnergyFull", &dev.EnergyFull)
+	setFloat("EnergyFullDesign", &dev.EnergyFullDesign)
+	setFloat("EnergyRate", &dev.EnergyRate)
+	setFloat("Voltage", &dev.Voltage)
+	setFloat("Temperature", &dev.Temperature)
+	setFloat("Capacity", &dev.Capacity)
+	setEnum("Type", deviceTypes, &dev.Type)
+	setEnum("State", deviceStates, &dev.State)
+	setEnum("Technology", technologies, &dev.Technology)
+	setEnum("WarningLevel", warningLevels, &dev.WarningLevel)
+	setEnum("BatteryLevel", batteryLevels, &dev.BatteryLevel)
+
+	if v, ok := dbusutil.Get[int64](props, "TimeToEmpty"); ok {
+		dev.TimeToEmpty = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[int64](props, "TimeToFull"); ok {
+		dev.TimeToFull = v
+		changed = true
+	}
+	if v, ok := dbusutil.Get[int32](props, "ChargeCycles"); ok {
+		dev.ChargeCycles = v
+		changed = true
+	}
+
+	// Older UPower releases don't export Capacity; derive health from the
+	// design capacity when the driver reports it.
+	if _, ok := props["Capacity"]; !ok && dev.EnergyFullDesign > 0 && dev.EnergyFull > 0 {
+		dev.Capacity = min(dev.EnergyFull/dev.EnergyFullDesign*100, 100)
+	}
+
+	return changed
+}
+
+// rebuildDevicesLocked regenerates the exported device list from the device
+// map. Callers must hold stateMutex for writing.
+func (m *Manager) rebuildDevicesLocked() {
+	devices := make([]Device, 0, len(m.devices))
+	m.state.Display = nil
+
+	for path, dev := range m.devices {
+		if path == m.displayPath {
+			display := *dev
+			m.state.Display = &display
+			continue
+		}
+		devices = append(devices, *dev)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].PowerSupply != devices[j].PowerSupply {
+			return devices[i].PowerSupply
+		}
+		return devices[i].Path < devices[j].Path
+	})
+
+	m.state.Devices = devices
+}
+
+func (m *Manager) addDevice(path dbus.ObjectPath) {
+	dev, err := m.fetchDevice(path)
+	if err != nil {
+		log.Debugf("[UPower] failed to fetch added device %s: %v", path, err)
+		return
+	}
+
+	m.stateMutex.Lock()
+	m.devices[path] = dev
+	m.rebuildDevicesLocked()
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) removeDevice(path dbus.ObjectPath) {
+	m.stateMutex.Lock()
+	if _, ok := m.devices[path]; !ok {
+		m.stateMutex.Unlock()
+		return
+	}
+	delete(m.devices, path)
+	m.rebuildDevicesLocked()
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) snapshotState() BatteryState {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	s := *m.state
+	s.Devices = append([]Device(nil), m.state.Devices...)
+	if m.state.Display != nil {
+		display := *m.state.Display
+		s.Display = &display
+	}
+	return s
+}
+
+func stateChanged(old, new *BatteryState) bool {
+	if old.Available != new.Available || old.OnBattery != new.OnBattery || old.LidIsClosed != new.LidIsClosed ||
+		old.LidIsPresent != new.LidIsPresent || old.CriticalAction != new.CriticalAction {
+		return true
+	}
+	if (old.Display == nil) != (new.Display == nil) {
+		return true
+	}
+	if old.Display != nil && *old.Display != *new.Display {
+		return true
+	}
+	if len(old.Devices) != len(new.Devices) {
+		return true
+	}
+	for i := range old.Devices {
+		if old.Devices[i] != new.Devices[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) GetState() BatteryState {
+	return m.snapshotState()
+}
+
+func (m *Manager) Subscribe(id string) chan BatteryState {
+	ch := make(chan BatteryState, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if val, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(val)
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+	const minGap = 200 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.snapshotState()
+
+			if m.lastNotifiedState != nil && !stateChanged(m.lastNotifiedState, &currentState) {
+				pending = false
+				continue
+			}
+
+			m.subscribers.Range(func(key string, ch chan BatteryState) bool {
+				select {
+				case ch <- currentState:
+				default:
+				}
+				return true
+			})
+
+			stateCopy := currentState
+			m.lastNotifiedState = &stateCopy
+			pending = false
+		}
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) signalMatches() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchSender(dbusDest),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		},
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(dbusPath)),
+			dbus.WithMatchInterface(dbusUPowerInterface),
+			dbus.WithMatchMember("DeviceAdded"),
+		},
+		{
+			dbus.WithMatchObjectPath(dbus.ObjectPath(dbusPath)),
+			dbus.WithMatchInterface(dbusUPowerInterface),
+			dbus.WithMatchMember("DeviceRemoved"),
+		},
+		{
+			dbus.WithMatchObjectPath("/org/freedesktop/DBus"),
+			dbus.WithMatchInterface("org.freedesktop.DBus"),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg(0, dbusDest),
+		},
+	}
+}
+
+func (m *Manager) startSignalPump() error {
+	m.conn.Signal(m.signals)
+
+	matches := m.signalMatches()
+	for i, match := range matches {
+		if err := m.conn.AddMatchSignal(match...); err != nil {
+			for _, added := range matches[:i] {
+				m.conn.RemoveMatchSignal(added...)
+			}
+			m.conn.RemoveSignal(m.signals)
+			return err
+		}
+	}
+
+	m.sigWG.Add(1)
+	go func() {
+		defer m.sigWG.Done()
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case sig, ok := <-m.signals:
+				if !ok {
+					return
+				}
+				if sig == nil {
+					continue
+				}
+				m.handleDBusSignal(sig)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) stopSignalPump() {
+	if m.conn == nil {
+		return
+	}
+	for _, match := range m.signalMatches() {
+		m.conn.RemoveMatchSignal(match...)
+	}
+	m.conn.RemoveSignal(m.signals)
+	close(m.signals)
+
+	m.sigWG.Wait()
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.notifierWg.Wait()
+
+	m.stopSignalPump()
+
+	m.subscribers.Range(func(key string, ch chan BatteryState) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+
+	if m.conn != nil {
+		m.conn.Close()
+	}
+}
@@ -0,0 +1,369 @@
+package upower
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	mu      sync.Mutex
+	objects map[dbus.ObjectPath]dbus.BusObject
+	matches int
+	closed  bool
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{objects: make(map[dbus.ObjectPath]dbus.BusObject)}
+}
+
+func (c *fakeConn) Object(dest string, path dbus.ObjectPath) dbus.BusObject {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.objects[path]
+}
+
+func (c *fakeConn) Signal(ch chan<- *dbus.Signal)       {}
+func (c *fakeConn) RemoveSignal(ch chan<- *dbus.Signal) {}
+
+func (c *fakeConn) AddMatchSignal(options ...dbus.MatchOption) error {
+	c.mu.Lock()
+	c.matches++
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) RemoveMatchSignal(options ...dbus.MatchOption) error {
+	c.mu.Lock()
+	c.matches--
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+const (
+	batteryPath = dbus.ObjectPath("/org/freedesktop/UPower/devices/battery_BAT0")
+	acPath      = dbus.ObjectPath("/org/freedesktop/UPower/devices/line_power_AC")
+	mousePath   = dbus.ObjectPath("/org/freedesktop/UPower/devices/mouse_hidpp_battery_0")
+	displayPath = dbus.ObjectPath("/org/freedesktop/UPower/devices/DisplayDevice")
+)
+
+func batteryProps() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"NativePath":       dbus.MakeVariant("BAT0"),
+		"Vendor":           dbus.MakeVariant("SMP"),
+		"Model":            dbus.MakeVariant("5B10W13930"),
+		"Type":             dbus.MakeVariant(uint32(2)),
+		"PowerSupply":      dbus.MakeVariant(true),
+		"IsPresent":        dbus.MakeVariant(true),
+		"IsRechargeable":   dbus.MakeVariant(true),
+		"State":            dbus.MakeVariant(uint32(2)),
+		"Percentage":       dbus.MakeVariant(42.0),
+		"Energy":           dbus.MakeVariant(21.0),
+		"EnergyFull":       dbus.MakeVariant(45.0),
+		"EnergyFullDesign": dbus.MakeVariant(50.0),
+		"EnergyRate":       dbus.MakeVariant(7.5),
+		"TimeToEmpty":      dbus.MakeVariant(int64(10080)),
+		"TimeToFull":       dbus.MakeVariant(int64(0)),
+		"Technology":       dbus.MakeVariant(uint32(2)),
+		"WarningLevel":     dbus.MakeVariant(uint32(1)),
+		"BatteryLevel":     dbus.MakeVariant(uint32(1)),
+		"ChargeCycles":     dbus.MakeVariant(int32(112)),
+		"IconName":         dbus.MakeVariant("battery-good-symbolic"),
+	}
+}
+
+func expectGetAll(obj *mockdbus.MockBusObject, iface string, props map[string]dbus.Variant) {
+	obj.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), iface).
+		Return(&dbus.Call{Body: []any{props}}).Maybe()
+}
+
+func newFakeUPower(t *testing.T) *fakeConn {
+	conn := newFakeConn()
+
+	upowerObj := mockdbus.NewMockBusObject(t)
+	expectGetAll(upowerObj, dbusUPowerInterface, map[string]dbus.Variant{
+		"DaemonVersion": dbus.MakeVariant("1.90.4"),
+		"OnBattery":     dbus.MakeVariant(true),
+		"LidIsPresent":  dbus.MakeVariant(true),
+		"LidIsClosed":   dbus.MakeVariant(false),
+	})
+	upowerObj.EXPECT().Call(dbusUPowerInterface+".GetDisplayDevice", dbus.Flags(0)).
+		Return(&dbus.Call{Body: []any{displayPath}}).Maybe()
+	upowerObj.EXPECT().Call(dbusUPowerInterface+".GetCriticalAction", dbus.Flags(0)).
+		Return(&dbus.Call{Body: []any{"HybridSleep"}}).Maybe()
+	upowerObj.EXPECT().Call(dbusUPowerInterface+".EnumerateDevices", dbus.Flags(0)).
+		Return(&dbus.Call{Body: []any{[]dbus.ObjectPath{batteryPath, acPath, mousePath}}}).Maybe()
+	conn.objects[dbusPath] = upowerObj
+
+	battery := mockdbus.NewMockBusObject(t)
+	expectGetAll(battery, dbusDeviceInterface, batteryProps())
+	conn.objects[batteryPath] = battery
+
+	ac := mockdbus.NewMockBusObject(t)
+	expectGetAll(ac, dbusDeviceInterface, map[string]dbus.Variant{
+		"NativePath":  dbus.MakeVariant("AC"),
+		"Type":        dbus.MakeVariant(uint32(1)),
+		"PowerSupply": dbus.MakeVariant(true),
+		"Online":      dbus.MakeVariant(false),
+	})
+	conn.objects[acPath] = ac
+
+	mouse := mockdbus.NewMockBusObject(t)
+	expectGetAll(mouse, dbusDeviceInterface, map[string]dbus.Variant{
+		"Model":        dbus.MakeVariant("MX Master 3"),
+		"Type":         dbus.MakeVariant(uint32(5)),
+		"PowerSupply":  dbus.MakeVariant(false),
+		"IsPresent":    dbus.MakeVariant(true),
+		"State":        dbus.MakeVariant(uint32(2)),
+		"Percentage":   dbus.MakeVariant(70.0),
+		"BatteryLevel": dbus.MakeVariant(uint32(6)),
+	})
+	conn.objects[mousePath] = mouse
+
+	display := mockdbus.NewMockBusObject(t)
+	expectGetAll(display, dbusDeviceInterface, map[string]dbus.Variant{
+		"Type":         dbus.MakeVariant(uint32(2)),
+		"IsPresent":    dbus.MakeVariant(true),
+		"State":        dbus.MakeVariant(uint32(2)),
+		"Percentage":   dbus.MakeVariant(42.0),
+		"TimeToEmpty":  dbus.MakeVariant(int64(10080)),
+		"WarningLevel": dbus.MakeVariant(uint32(1)),
+	})
+	conn.objects[displayPath] = display
+
+	return conn
+}
+
+func TestNewManagerWithConn(t *testing.T) {
+	conn := newFakeUPower(t)
+
+	manager, err := NewManagerWithConn(conn)
+	require.NoError(t, err)
+
+	state := manager.GetState()
+	assert.True(t, state.Available)
+	assert.Equal(t, "1.90.4", state.DaemonVersion)
+	assert.True(t, state.OnBattery)
+	assert.True(t, state.LidIsPresent)
+	assert.Equal(t, "HybridSleep", state.CriticalAction)
+
+	require.NotNil(t, state.Display)
+	assert.Equal(t, 42.0, state.Display.Percentage)
+	assert.Equal(t, "discharging", state.Display.State)
+	assert.Equal(t, int64(10080), state.Display.TimeToEmpty)
+
+	require.Len(t, state.Devices, 3)
+	assert.Equal(t, string(batteryPath), state.Devices[0].Path)
+	assert.Equal(t, string(acPath), state.Devices[1].Path)
+	assert.Equal(t, string(mousePath), state.Devices[2].Path)
+	assert.Equal(t, "mouse", state.Devices[2].Type)
+	assert.False(t, state.Devices[2].PowerSupply)
+
+	assert.Equal(t, 4, conn.matches)
+	manager.Close()
+	assert.Equal(t, 0, conn.matches)
+	assert.True(t, conn.closed)
+}
+
+func TestNewManagerWithConn_Unavailable(t *testing.T) {
+	conn := newFakeConn()
+	upowerObj := mockdbus.NewMockBusObject(t)
+	upowerObj.EXPECT().
+		Call(dbusPropsInterface+".GetAll", dbus.Flags(0), dbusUPowerInterface).
+		Return(&dbus.Call{Err: assert.AnError})
+	conn.objects[dbusPath] = upowerObj
+
+	manager, err := NewManagerWithConn(conn)
+	assert.Nil(t, manager)
+	assert.ErrorContains(t, err, "upower not available")
+}
+
+func TestApplyDeviceProps(t *testing.T) {
+	dev := &Device{Path: string(batteryPath)}
+	changed := applyDeviceProps(dev, batteryProps())
+
+	assert.True(t, changed)
+	assert.Equal(t, "BAT0", dev.NativePath)
+	assert.Equal(t, "battery", dev.Type)
+	assert.Equal(t, "discharging", dev.State)
+	assert.Equal(t, "lithium-polymer", dev.Technology)
+	assert.Equal(t, "none", dev.WarningLevel)
+	assert.Equal(t, int32(112), dev.ChargeCycles)
+	assert.InDelta(t, 90.0, dev.Capacity, 0.001)
+
+	t.Run("reported capacity wins", func(t *testing.T) {
+		props := batteryProps()
+		props["Capacity"] = dbus.MakeVariant(87.5)
+		dev := &Device{}
+		applyDeviceProps(dev, props)
+		assert.Equal(t, 87.5, dev.Capacity)
+	})
+
+	t.Run("no relevant properties", func(t *testing.T) {
+		dev := &Device{}
+		assert.False(t, applyDeviceProps(dev, map[string]dbus.Variant{"UpdateTime": dbus.MakeVariant(uint64(1))}))
+	})
+
+	t.Run("unknown enum values", func(t *testing.T) {
+		dev := &Device{}
+		applyDeviceProps(dev, map[string]dbus.Variant{
+			"Type":  dbus.MakeVariant(uint32(99)),
+			"State": dbus.MakeVariant(uint32(42)),
+		})
+		assert.Equal(t, "unknown", dev.Type)
+		assert.Equal(t, "unknown", dev.State)
+	})
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	manager := &Manager{state: &BatteryState{}}
+
+	ch := manager.Subscribe("test-client")
+	assert.NotNil(t, ch)
+	assert.Equal(t, 64, cap(ch))
+
+	manager.Unsubscribe("test-client")
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	assert.NotPanics(t, func() {
+		manager.Unsubscribe("non-existent")
+	})
+}
+
+func TestManager_NotifySubscribers(t *testing.T) {
+	manager := &Manager{
+		state:    &BatteryState{Available: true, OnBattery: true},
+		stopChan: make(chan struct{}),
+		dirty:    make(chan struct{}, 1),
+	}
+	manager.notifierWg.Add(1)
+	go manager.notifier()
+
+	ch := make(chan BatteryState, 10)
+	manager.subscribers.Store("test-client", ch)
+
+	manager.notifySubscribers()
+	manager.notifySubscribers()
+
+	select {
+	case state := <-ch:
+		assert.True(t, state.OnBattery)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("did not receive state update")
+	}
+
+	manager.notifySubscribers()
+	select {
+	case <-ch:
+		t.Fatal("unchanged state should not be re-broadcast")
+	case <-time.After(400 * time.Millisecond):
+	}
+
+	close(manager.stopChan)
+	manager.notifierWg.Wait()
+}
+
+func TestManager_SnapshotState(t *testing.T) {
+	manager := &Manager{
+		state: &BatteryState{
+			Display: &Device{Percentage: 50},
+			Devices: []Device{{Path: "a", Percentage: 50}},
+		},
+	}
+
+	snapshot := manager.snapshotState()
+	snapshot.Display.Percentage = 10
+	snapshot.Devices[0].Percentage = 10
+
+	assert.Equal(t, 50.0, manager.state.Display.Percentage)
+	assert.Equal(t, 50.0, manager.state.Devices[0].Percentage)
+}
+
+func TestStateChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      *BatteryState
+		new      *BatteryState
+		expected bool
+	}{
+		{
+			name:     "no change",
+			old:      &BatteryState{OnBattery: true, Devices: []Device{{Percentage: 50}}},
+			new:      &BatteryState{OnBattery: true, Devices: []Device{{Percentage: 50}}},
+			expected: false,
+		},
+		{
+			name:     "on battery changed",
+			old:      &BatteryState{OnBattery: true},
+			new:      &BatteryState{OnBattery: false},
+			expected: true,
+		},
+		{
+			name:     "display appeared",
+			old:      &BatteryState{},
+			new:      &BatteryState{Display: &Device{}},
+			expected: true,
+		},
+		{
+			name:     "display percentage changed",
+			old:      &BatteryState{Display: &Device{Percentage: 50}},
+			new:      &BatteryState{Display: &Device{Percentage: 49}},
+			expected: true,
+		},
+		{
+			name:     "device removed",
+			old:      &BatteryState{Devices: []Device{{}, {}}},
+			new:      &BatteryState{Devices: []Device{{}}},
+			expected: true,
+		},
+		{
+			name:     "device warning level changed",
+			old:      &BatteryState{Devices: []Device{{WarningLevel: "none"}}},
+			new:      &BatteryState{Devices: []Device{{WarningLevel: "low"}}},
+			expected: true,
+		},
+		{
+			name:     "lid presence changed",
+			old:      &BatteryState{},
+			new:      &BatteryState{LidIsPresent: true},
+			expected: true,
+		},
+		{
+			name:     "critical action changed",
+			old:      &BatteryState{CriticalAction: "PowerOff"},
+			new:      &BatteryState{CriticalAction: "Hibernate"},
+			expected: true,
+		},
+		{
+			name:     "daemon version is not meaningful",
+			old:      &BatteryState{DaemonVersion: "1.0"},
+			new:      &BatteryState{DaemonVersion: "2.0"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stateChanged(tt.old, tt.new))
+		})
+	}
+}
+
+func TestEnumName(t *testing.T) {
+	assert.Equal(t, "headset", enumName(deviceTypes, 17))
+	assert.Equal(t, "fully-charged", enumName(deviceStates, 4))
+	assert.Equal(t, "critical", enumName(warningLevels, 4))
+	assert.Equal(t, "unknown", enumName(batteryLevels, 2))
+}
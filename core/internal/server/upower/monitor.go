@@ -0,0 +1,97 @@
+package upower
+
+import (
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
+	switch sig.Name {
+	case dbusPropsInterface + ".PropertiesChanged":
+		m.handlePropertiesChanged(sig)
+
+	case dbusUPowerInterface + ".DeviceAdded":
+		if len(sig.Body) == 0 {
+			return
+		}
+		if path, ok := sig.Body[0].(dbus.ObjectPath); ok {
+			m.addDevice(path)
+		}
+
+	case dbusUPowerInterface + ".DeviceRemoved":
+		if len(sig.Body) == 0 {
+			return
+		}
+		if path, ok := sig.Body[0].(dbus.ObjectPath); ok {
+			m.removeDevice(path)
+		}
+
+	case "org.freedesktop.DBus.NameOwnerChanged":
+		if len(sig.Body) != 3 {
+			return
+		}
+		name, _ := sig.Body[0].(string)
+		newOwner, _ := sig.Body[2].(string)
+		if name != dbusDest {
+			return
+		}
+
+		if newOwner == "" {
+			log.Warn("[UPower] daemon went away")
+			m.stateMutex.Lock()
+			m.state.Available = false
+			m.devices = make(map[dbus.ObjectPath]*Device)
+			m.displayPath = ""
+			m.rebuildDevicesLocked()
+			m.stateMutex.Unlock()
+			m.notifySubscribers()
+			return
+		}
+
+		if err := m.initialize(); err != nil {
+			log.Warnf("[UPower] failed to reinitialize after restart: %v", err)
+			return
+		}
+		m.notifySubscribers()
+	}
+}
+
+func (m *Manager) handlePropertiesChanged(sig *dbus.Signal) {
+	if len(sig.Body) < 2 {
+		return
+	}
+
+	iface, ok := sig.Body[0].(string)
+	if !ok {
+		return
+	}
+
+	changes, ok := sig.Body[1].(map[string]dbus.Variant)
+	if !ok {
+		return
+	}
+
+	var needsUpdate bool
+
+	switch iface {
+	case dbusUPowerInterface:
+		if sig.Path != dbus.ObjectPath(dbusPath) {
+			return
+		}
+		m.stateMutex.Lock()
+		needsUpdate = m.applyDaemonPropsLocked(changes)
+		m.stateMutex.Unlock()
+
+	case dbusDeviceInterface:
+		m.stateMutex.Lock()
+		if dev, ok := m.devices[sig.Path]; ok && applyDeviceProps(dev, changes) {
+			m.rebuildDevicesLocked()
+			needsUpdate = true
+		}
+		m.stateMutex.Unlock()
+	}
+
+	if needsUpdate {
+		m.notifySubscribers()
+	}
+}
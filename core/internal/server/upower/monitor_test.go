@@ -0,0 +1,166 @@
+package upower
+
+import (
+	"testing"
+
+	mockdbus "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(conn DBusConn) *Manager {
+	return &Manager{
+		state:   &BatteryState{Available: true, Devices: []Device{}},
+		conn:    conn,
+		devices: make(map[dbus.ObjectPath]*Device),
+		dirty:   make(chan struct{}, 1),
+	}
+}
+
+func TestHandleDBusSignal_DevicePropertiesChanged(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+	manager.devices[batteryPath] = &Device{Path: string(batteryPath), Percentage: 42, State: "discharging"}
+	manager.rebuildDevicesLocked()
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: batteryPath,
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{
+			dbusDeviceInterface,
+			map[string]dbus.Variant{
+				"Percentage":   dbus.MakeVariant(9.0),
+				"WarningLevel": dbus.MakeVariant(uint32(3)),
+			},
+			[]string{},
+		},
+	})
+
+	state := manager.GetState()
+	require.Len(t, state.Devices, 1)
+	assert.Equal(t, 9.0, state.Devices[0].Percentage)
+	assert.Equal(t, "low", state.Devices[0].WarningLevel)
+	assert.Equal(t, "discharging", state.Devices[0].State)
+	assert.Len(t, manager.dirty, 1)
+}
+
+func TestHandleDBusSignal_DisplayDevicePropertiesChanged(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+	manager.displayPath = displayPath
+	manager.devices[displayPath] = &Device{Path: string(displayPath), State: "discharging"}
+	manager.rebuildDevicesLocked()
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: displayPath,
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{
+			dbusDeviceInterface,
+			map[string]dbus.Variant{"State": dbus.MakeVariant(uint32(1))},
+			[]string{},
+		},
+	})
+
+	state := manager.GetState()
+	require.NotNil(t, state.Display)
+	assert.Equal(t, "charging", state.Display.State)
+	assert.Empty(t, state.Devices)
+}
+
+func TestHandleDBusSignal_UnknownDevicePropertiesChanged(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: batteryPath,
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{
+			dbusDeviceInterface,
+			map[string]dbus.Variant{"Percentage": dbus.MakeVariant(9.0)},
+			[]string{},
+		},
+	})
+
+	assert.Empty(t, manager.GetState().Devices)
+	assert.Len(t, manager.dirty, 0)
+}
+
+func TestHandleDBusSignal_DaemonPropertiesChanged(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: dbusPath,
+		Name: dbusPropsInterface + ".PropertiesChanged",
+		Body: []any{
+			dbusUPowerInterface,
+			map[string]dbus.Variant{
+				"OnBattery":   dbus.MakeVariant(true),
+				"LidIsClosed": dbus.MakeVariant(true),
+			},
+			[]string{},
+		},
+	})
+
+	state := manager.GetState()
+	assert.True(t, state.OnBattery)
+	assert.True(t, state.LidIsClosed)
+	assert.Len(t, manager.dirty, 1)
+}
+
+func TestHandleDBusSignal_DeviceAddedRemoved(t *testing.T) {
+	conn := newFakeConn()
+	headset := mockdbus.NewMockBusObject(t)
+	expectGetAll(headset, dbusDeviceInterface, map[string]dbus.Variant{
+		"Model":      dbus.MakeVariant("WH-1000XM4"),
+		"Type":       dbus.MakeVariant(uint32(17)),
+		"Percentage": dbus.MakeVariant(80.0),
+	})
+	headsetPath := dbus.ObjectPath("/org/freedesktop/UPower/devices/headset_dev_00_11_22")
+	conn.objects[headsetPath] = headset
+
+	manager := newTestManager(conn)
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: dbusPath,
+		Name: dbusUPowerInterface + ".DeviceAdded",
+		Body: []any{headsetPath},
+	})
+
+	state := manager.GetState()
+	require.Len(t, state.Devices, 1)
+	assert.Equal(t, "headset", state.Devices[0].Type)
+	assert.Equal(t, "WH-1000XM4", state.Devices[0].Model)
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Path: dbusPath,
+		Name: dbusUPowerInterface + ".DeviceRemoved",
+		Body: []any{headsetPath},
+	})
+
+	assert.Empty(t, manager.GetState().Devices)
+}
+
+func TestHandleDBusSignal_DaemonVanished(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+	manager.devices[batteryPath] = &Device{Path: string(batteryPath)}
+	manager.rebuildDevicesLocked()
+
+	manager.handleDBusSignal(&dbus.Signal{
+		Name: "org.freedesktop.DBus.NameOwnerChanged",
+		Body: []any{dbusDest, ":1.42", ""},
+	})
+
+	state := manager.GetState()
+	assert.False(t, state.Available)
+	assert.Empty(t, state.Devices)
+	assert.Nil(t, state.Display)
+}
+
+func TestHandleDBusSignal_MalformedBodies(t *testing.T) {
+	manager := newTestManager(newFakeConn())
+
+	assert.NotPanics(t, func() {
+		manager.handleDBusSignal(&dbus.Signal{Name: dbusPropsInterface + ".PropertiesChanged"})
+		manager.handleDBusSignal(&dbus.Signal{Name: dbusUPowerInterface + ".DeviceAdded"})
+		manager.handleDBusSignal(&dbus.Signal{Name: dbusUPowerInterface + ".DeviceRemoved", Body: []any{"not-a-path"}})
+		manager.handleDBusSignal(&dbus.Signal{Name: "org.freedesktop.DBus.NameOwnerChanged", Body: []any{"x"}})
+	})
+}
@@ -0,0 +1,151 @@
+package upower
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+	"github.com/godbus/dbus/v5"
+)
+
+type DBusConn interface {
+	Object(dest string, path dbus.ObjectPath) dbus.BusObject
+	Signal(ch chan<- *dbus.Signal)
+	RemoveSignal(ch chan<- *dbus.Signal)
+	AddMatchSignal(options ...dbus.MatchOption) error
+	RemoveMatchSignal(options ...dbus.MatchOption) error
+	Close() error
+}
+
+type Device struct {
+	Path             string  `json:"path"`
+	NativePath       string  `json:"nativePath"`
+	Vendor           string  `json:"vendor"`
+	Model            string  `json:"model"`
+	Serial           string  `json:"serial"`
+	Type             string  `json:"type"`
+	PowerSupply      bool    `json:"powerSupply"`
+	Online           bool    `json:"online"`
+	IsPresent        bool    `json:"isPresent"`
+	IsRechargeable   bool    `json:"isRechargeable"`
+	State            string  `json:"state"`
+	Percentage       float64 `json:"percentage"`
+	Energy           float64 `json:"energy"`
+	EnergyFull       float64 `json:"energyFull"`
+	EnergyFullDesign float64 `json:"energyFullDesign"`
+	EnergyRate       float64 `json:"energyRate"`
+	Voltage          float64 `json:"voltage"`
+	Temperature      float64 `json:"temperature"`
+	TimeToEmpty      int64   `json:"timeToEmpty"`
+	TimeToFull       int64   `json:"timeToFull"`
+	Capacity         float64 `json:"capacity"`
+	ChargeCycles     int32   `json:"chargeCycles"`
+	Technology       string  `json:"technology"`
+	WarningLevel     string  `json:"warningLevel"`
+	BatteryLevel     string  `json:"batteryLevel"`
+	IconName         string  `json:"iconName"`
+}
+
+type BatteryState struct {
+	Available      bool     `json:"available"`
+	DaemonVersion  string   `json:"daemonVersion"`
+	OnBattery      bool     `json:"onBattery"`
+	LidIsPresent   bool     `json:"lidIsPresent"`
+	LidIsClosed    bool     `json:"lidIsClosed"`
+	CriticalAction string   `json:"criticalAction"`
+	Display        *Device  `json:"display,omitempty"`
+	Devices        []Device `json:"devices"`
+}
+
+type Manager struct {
+	state             *BatteryState
+	stateMutex        sync.RWMutex
+	subscribers       syncmap.Map[string, chan BatteryState]
+	stopChan          chan struct{}
+	conn              DBusConn
+	upowerObj         dbus.BusObject
+	displayPath       dbus.ObjectPath
+	devices           map[dbus.ObjectPath]*Device
+	dirty             chan struct{}
+	notifierWg        sync.WaitGroup
+	lastNotifiedState *BatteryState
+	signals           chan *dbus.Signal
+	sigWG             sync.WaitGroup
+}
+
+var deviceTypes = map[uint32]string{
+	0:  "unknown",
+	1:  "line-power",
+	2:  "battery",
+	3:  "ups",
+	4:  "monitor",
+	5:  "mouse",
+	6:  "keyboard",
+	7:  "pda",
+	8:  "phone",
+	9:  "media-player",
+	10: "tablet",
+	11: "computer",
+	12: "gaming-input",
+	13: "pen",
+	14: "touchpad",
+	15: "modem",
+	16: "network",
+	17: "headset",
+	18: "speakers",
+	19: "headphones",
+	20: "video",
+	21: "other-audio",
+	22: "remote-control",
+	23: "printer",
+	24: "scanner",
+	25: "camera",
+	26: "wearable",
+	27: "toy",
+	28: "bluetooth-generic",
+}
+
+var deviceStates = map[uint32]string{
+	0: "unknown",
+	1: "charging",
+	2: "discharging",
+	3: "empty",
+	4: "fully-charged",
+	5: "pending-charge",
+	6: "pending-discharge",
+}
+
+var technologies = map[uint32]string{
+	0: "unknown",
+	1: "lithium-ion",
+	2: "lithium-polymer",
+	3: "lithium-iron-phosphate",
+	4: "lead-acid",
+	5: "nickel-cadmium",
+	6: "nickel-metal-hydride",
+}
+
+var warningLevels = map[uint32]string{
+	0: "unknown",
+	1: "none",
+	2: "discharging",
+	3: "low",
+	4: "critical",
+	5: "action",
+}
+
+var batteryLevels = map[uint32]string{
+	0: "unknown",
+	1: "none",
+	3: "low",
+	4: "critical",
+	6: "normal",
+	7: "high",
+	8: "full",
+}
+
+func enumName(names map[uint32]string, v uint32) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return "unknown"
+}
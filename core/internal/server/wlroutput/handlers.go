@@ -9,6 +9,7 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_management"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
 )
 
 type HeadConfig struct {
@@ -44,6 +45,14 @@ func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
 		handleApplyConfiguration(conn, req, manager, false)
 	case "wlroutput.testConfiguration":
 		handleApplyConfiguration(conn, req, manager, true)
+	case "wlroutput.profiles.list":
+		handleListProfiles(conn, req, manager)
+	case "wlroutput.profiles.save":
+		handleSaveProfile(conn, req, manager)
+	case "wlroutput.profiles.delete":
+		handleDeleteProfile(conn, req, manager)
+	case "wlroutput.profiles.apply":
+		handleApplyProfile(conn, req, manager)
 	case "wlroutput.subscribe":
 		handleSubscribe(conn, req, manager)
 	default:
@@ -86,6 +95,58 @@ func handleApplyConfiguration(conn net.Conn, req models.Request, manager *Manage
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: msg})
 }
 
+func handleListProfiles(conn net.Conn, req models.Request, manager *Manager) {
+	list, err := manager.ListProfiles()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, list)
+}
+
+func handleSaveProfile(conn net.Conn, req models.Request, manager *Manager) {
+	name, err := params.StringNonEmpty(req.Params, "name")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	profile, err := manager.SaveProfile(name)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, profile)
+}
+
+func handleDeleteProfile(conn net.Conn, req models.Request, manager *Manager) {
+	name, err := params.StringNonEmpty(req.Params, "name")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.DeleteProfile(name); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "profile deleted"})
+}
+
+func handleApplyProfile(conn net.Conn, req models.Request, manager *Manager) {
+	name, err := params.StringNonEmpty(req.Params, "name")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.ApplyProfile(name); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "profile applied"})
+}
+
 func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
@@ -108,7 +169,20 @@ func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
 	}
 }
 
+// ApplyConfiguration applies or tests a manual layout. A manual layout no
+// longer matches any saved profile, so applying one clears the active
+// profile.
 func (m *Manager) ApplyConfiguration(heads []HeadConfig, test bool) error {
+	if err := m.applyConfiguration(heads, test); err != nil {
+		return err
+	}
+	if !test {
+		m.setActiveProfile("")
+	}
+	return nil
+}
+
+func (m *Manager) applyConfiguration(heads []HeadConfig, test bool) error {
 	if m.manager == nil {
 		return fmt.Errorf("output manager not initialized")
 	}
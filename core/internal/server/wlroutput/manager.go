@@ -19,6 +19,12 @@ func NewManager(display wlclient.WaylandDisplay) (*Manager, error) {
 		fatalError: make(chan error, 1),
 	}
 
+	if path, err := getProfilesPath(); err == nil {
+		m.profilesPath = path
+	} else {
+		log.Warnf("WlrOutput: display profiles unavailable: %v", err)
+	}
+
 	m.wg.Add(1)
 	go m.waylandActor()
 
@@ -108,6 +114,7 @@ func (m *Manager) setupRegistry() error {
 				m.serial = e.Serial
 				m.post(func() {
 					m.updateState()
+					m.checkConnected()
 				})
 			})
 
@@ -389,6 +396,7 @@ func (m *Manager) updateState() {
 	}
 
 	m.stateMutex.Lock()
+	newState.ActiveProfile = m.activeProfile
 	m.state = &newState
 	m.stateMutex.Unlock()
 
@@ -0,0 +1,421 @@
+package wlroutput
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+var errNoProfilesPath = errors.New("display profiles unavailable: no config directory")
+
+type ProfileMode struct {
+	Width   int32 `json:"width"`
+	Height  int32 `json:"height"`
+	Refresh int32 `json:"refresh"`
+}
+
+// ProfileOutput describes one head in a profile. Heads are identified by
+// make/model/serial so a profile follows a monitor across connectors; the
+// connector name is only used for heads that report none of those.
+type ProfileOutput struct {
+	Name         string       `json:"name"`
+	Make         string       `json:"make"`
+	Model        string       `json:"model"`
+	SerialNumber string       `json:"serialNumber"`
+	Enabled      bool         `json:"enabled"`
+	Mode         *ProfileMode `json:"mode,omitempty"`
+	X            int32        `json:"x"`
+	Y            int32        `json:"y"`
+	Transform    int32        `json:"transform"`
+	Scale        float64      `json:"scale"`
+	AdaptiveSync *uint32      `json:"adaptiveSync,omitempty"`
+}
+
+type Profile struct {
+	Name    string          `json:"name"`
+	Outputs []ProfileOutput `json:"outputs"`
+}
+
+type ProfileList struct {
+	Profiles []Profile `json:"profiles"`
+	Active   string    `json:"active"`
+}
+
+type profileFile struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+func getProfilesPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "display-profiles.json"), nil
+}
+
+func loadProfiles(path string) ([]Profile, error) {
+	if path == "" {
+		return nil, errNoProfilesPath
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return []Profile{}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var file profileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = []Profile{}
+	}
+	return file.Profiles, nil
+}
+
+func saveProfiles(path string, profiles []Profile) error {
+	if path == "" {
+		return errNoProfilesPath
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profileFile{Profiles: profiles}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func outputIdentity(name, make, model, serial string) string {
+	if make == "" && model == "" && serial == "" {
+		return "name:" + name
+	}
+	return make + "\x00" + model + "\x00" + serial
+}
+
+func (o ProfileOutput) identity() string {
+	return outputIdentity(o.Name, o.Make, o.Model, o.SerialNumber)
+}
+
+func (o Output) identity() string {
+	return outputIdentity(o.Name, o.Make, o.Model, o.SerialNumber)
+}
+
+// connectedKey is a stable fingerprint of the connected head set, used to
+// detect hotplug independently of head enumeration order.
+func connectedKey(outputs []Output) string {
+	ids := make([]string, len(outputs))
+	for i, out := range outputs {
+		ids[i] = out.identity()
+	}
+	slices.Sort(ids)
+	return strings.Join(ids, "\x01")
+}
+
+func (p Profile) key() string {
+	ids := make([]string, len(p.Outputs))
+	for i, out := range p.Outputs {
+		ids[i] = out.identity()
+	}
+	slices.Sort(ids)
+	return strings.Join(ids, "\x01")
+}
+
+// matchProfile returns the first profile whose heads are exactly the
+// connected set, like kanshi.
+func matchProfile(profiles []Profile, outputs []Output) *Profile {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	key := connectedKey(outputs)
+	for i := range profiles {
+		if profiles[i].key() == key {
+			return &profiles[i]
+		}
+	}
+	return nil
+}
+
+func profileFromOutputs(name string, outputs []Output) Profile {
+	profile := Profile{Name: name, Outputs: make([]ProfileOutput, 0, len(outputs))}
+
+	for _, out := range outputs {
+		po := ProfileOutput{
+			Name:         out.Name,
+			Make:         out.Make,
+			Model:        out.Model,
+			SerialNumber: out.SerialNumber,
+			Enabled:      out.Enabled,
+			X:            out.X,
+			Y:            out.Y,
+			Transform:    out.Transform,
+			Scale:        out.Scale,
+		}
+		if out.CurrentMode != nil {
+			po.Mode = &ProfileMode{
+				Width:   out.CurrentMode.Width,
+				Height:  out.CurrentMode.Height,
+				Refresh: out.CurrentMode.Refresh,
+			}
+		}
+		if out.AdaptiveSyncSupported {
+			adaptiveSync := out.AdaptiveSync
+			po.AdaptiveSync = &adaptiveSync
+		}
+		profile.Outputs = append(profile.Outputs, po)
+	}
+
+	return profile
+}
+
+// findMode picks the advertised mode for want: an exact match, otherwise the
+// same resolution with the closest refresh rate.
+func findMode(modes []OutputMode, want ProfileMode) *OutputMode {
+	var best *OutputMode
+	var bestDiff int32
+
+	for i := range modes {
+		mode := &modes[i]
+		if mode.Width != want.Width || mode.Height != want.Height {
+			continue
+		}
+		diff := mode.Refresh - want.Refresh
+		if diff < 0 {
+			diff = -diff
+		}
+		if best == nil || diff < bestDiff {
+			best = mode
+			bestDiff = diff
+		}
+	}
+	return best
+}
+
+// headConfigs resolves the profile against the connected outputs. Connected
+// heads the profile does not mention are left untouched.
+func (p Profile) headConfigs(outputs []Output) ([]HeadConfig, error) {
+	used := make(map[int]bool, len(outputs))
+	heads := make([]HeadConfig, 0, len(p.Outputs))
+
+	for _, po := range p.Outputs {
+		idx := -1
+		for i, out := range outputs {
+			if !used[i] && out.identity() == po.identity() {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("output not connected: %s", po.label())
+		}
+		used[idx] = true
+		out := outputs[idx]
+
+		head := HeadConfig{Name: out.Name, Enabled: po.Enabled}
+		if !po.Enabled {
+			heads = append(heads, head)
+			continue
+		}
+
+		if po.Mode != nil {
+			if mode := findMode(out.Modes, *po.Mode); mode != nil {
+				modeID := mode.ID
+				head.ModeID = &modeID
+			} else {
+				head.CustomMode = &struct {
+					Width   int32 `json:"width"`
+					Height  int32 `json:"height"`
+					Refresh int32 `json:"refresh"`
+				}{po.Mode.Width, po.Mode.Height, po.Mode.Refresh}
+			}
+		}
+
+		head.Position = &struct{ X, Y int32 }{po.X, po.Y}
+		transform := po.Transform
+		head.Transform = &transform
+		if po.Scale > 0 {
+			scale := po.Scale
+			head.Scale = &scale
+		}
+		if po.AdaptiveSync != nil && out.AdaptiveSyncSupported {
+			adaptiveSync := *po.AdaptiveSync
+			head.AdaptiveSync = &adaptiveSync
+		}
+
+		heads = append(heads, head)
+	}
+
+	return heads, nil
+}
+
+func (o ProfileOutput) label() string {
+	if o.Make == "" && o.Model == "" && o.SerialNumber == "" {
+		return o.Name
+	}
+	return strings.TrimSpace(strings.Join([]string{o.Make, o.Model, o.SerialNumber}, " "))
+}
+
+func (m *Manager) ListProfiles() (ProfileList, error) {
+	m.profileMutex.Lock()
+	defer m.profileMutex.Unlock()
+
+	profiles, err := loadProfiles(m.profilesPath)
+	if err != nil {
+		return ProfileList{}, err
+	}
+	return ProfileList{Profiles: profiles, Active: m.GetState().ActiveProfile}, nil
+}
+
+// SaveProfile stores the current layout under name, replacing any profile
+// with the same name.
+func (m *Manager) SaveProfile(name string) (Profile, error) {
+	outputs := m.GetState().Outputs
+	if len(outputs) == 0 {
+		return Profile{}, fmt.Errorf("no outputs connected")
+	}
+
+	m.profileMutex.Lock()
+	defer m.profileMutex.Unlock()
+
+	profiles, err := loadProfiles(m.profilesPath)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	profile := profileFromOutputs(name, outputs)
+	idx := slices.IndexFunc(profiles, func(p Profile) bool { return p.Name == name })
+	if idx >= 0 {
+		profiles[idx] = profile
+	} else {
+		profiles = append(profiles, profile)
+	}
+
+	if err := saveProfiles(m.profilesPath, profiles); err != nil {
+		return Profile{}, fmt.Errorf("failed to save profiles: %w", err)
+	}
+
+	m.setActiveProfile(name)
+	return profile, nil
+}
+
+func (m *Manager) DeleteProfile(name string) error {
+	m.profileMutex.Lock()
+	defer m.profileMutex.Unlock()
+
+	profiles, err := loadProfiles(m.profilesPath)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(profiles, func(p Profile) bool { return p.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	profiles = slices.Delete(profiles, idx, idx+1)
+
+	if err := saveProfiles(m.profilesPath, profiles); err != nil {
+		return fmt.Errorf("failed to save profiles: %w", err)
+	}
+
+	if m.GetState().ActiveProfile == name {
+		m.setActiveProfile("")
+	}
+	return nil
+}
+
+func (m *Manager) ApplyProfile(name string) error {
+	m.profileMutex.Lock()
+	profiles, err := loadProfiles(m.profilesPath)
+	m.profileMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(profiles, func(p Profile) bool { return p.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+
+	return m.applyProfile(profiles[idx])
+}
+
+func (m *Manager) applyProfile(profile Profile) error {
+	heads, err := profile.headConfigs(m.GetState().Outputs)
+	if err != nil {
+		return err
+	}
+
+	if err := m.applyConfiguration(heads, false); err != nil {
+		return err
+	}
+
+	m.setActiveProfile(profile.Name)
+	return nil
+}
+
+func (m *Manager) setActiveProfile(name string) {
+	m.stateMutex.Lock()
+	m.activeProfile = name
+	if m.state != nil {
+		m.state.ActiveProfile = name
+	}
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+// checkConnected runs on the wayland actor after each done event. When the
+// connected head set differs from the last one seen it applies the first
+// matching profile. The apply round-trips through the actor, so it runs on
+// its own goroutine.
+func (m *Manager) checkConnected() {
+	outputs := m.GetState().Outputs
+	key := connectedKey(outputs)
+	if key == m.connectedKey {
+		return
+	}
+	m.connectedKey = key
+
+	if m.profilesPath == "" || len(outputs) == 0 {
+		return
+	}
+
+	m.profileMutex.Lock()
+	profiles, err := loadProfiles(m.profilesPath)
+	m.profileMutex.Unlock()
+	if err != nil {
+		log.Warnf("WlrOutput: failed to load profiles: %v", err)
+		return
+	}
+
+	profile := matchProfile(profiles, outputs)
+	if profile == nil {
+		m.setActiveProfile("")
+		return
+	}
+
+	log.Infof("WlrOutput: connected outputs match profile %q, applying", profile.Name)
+	go func(profile Profile) {
+		if err := m.applyProfile(profile); err != nil {
+			log.Warnf("WlrOutput: failed to apply profile %q: %v", profile.Name, err)
+		}
+	}(*profile)
+}
@@ -0,0 +1,245 @@
+package wlroutput
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dockedOutputs() []Output {
+	return []Output{
+		{
+			Name:    "eDP-1",
+			Make:    "BOE",
+			Model:   "0x0ABC",
+			Enabled: true,
+			Scale:   1.5,
+			CurrentMode: &OutputMode{
+				Width: 2256, Height: 1504, Refresh: 60000, ID: 3,
+			},
+			Modes: []OutputMode{{Width: 2256, Height: 1504, Refresh: 60000, ID: 3}},
+		},
+		{
+			Name:                  "DP-3",
+			Make:                  "Dell Inc.",
+			Model:                 "DELL U2720Q",
+			SerialNumber:          "ABC123",
+			Enabled:               true,
+			X:                     1504,
+			Scale:                 1,
+			AdaptiveSyncSupported: true,
+			CurrentMode: &OutputMode{
+				Width: 3840, Height: 2160, Refresh: 59997, ID: 7,
+			},
+			Modes: []OutputMode{
+				{Width: 3840, Height: 2160, Refresh: 59997, ID: 7},
+				{Width: 3840, Height: 2160, Refresh: 29981, ID: 8},
+				{Width: 1920, Height: 1080, Refresh: 60000, ID: 9},
+			},
+		},
+	}
+}
+
+func newProfileManager(t *testing.T, outputs []Output) *Manager {
+	t.Helper()
+	return &Manager{
+		state:        &State{Outputs: outputs},
+		profilesPath: filepath.Join(t.TempDir(), "display-profiles.json"),
+	}
+}
+
+func TestConnectedKey_OrderIndependent(t *testing.T) {
+	outputs := dockedOutputs()
+	reversed := []Output{outputs[1], outputs[0]}
+	assert.Equal(t, connectedKey(outputs), connectedKey(reversed))
+	assert.NotEqual(t, connectedKey(outputs), connectedKey(outputs[:1]))
+}
+
+func TestOutputIdentity_FallsBackToName(t *testing.T) {
+	assert.Equal(t, "name:HEADLESS-1", Output{Name: "HEADLESS-1"}.identity())
+
+	// The same monitor on another connector keeps its identity.
+	a := Output{Name: "DP-1", Make: "Dell Inc.", Model: "U2720Q", SerialNumber: "1"}
+	b := Output{Name: "DP-2", Make: "Dell Inc.", Model: "U2720Q", SerialNumber: "1"}
+	assert.Equal(t, a.identity(), b.identity())
+}
+
+func TestMatchProfile(t *testing.T) {
+	outputs := dockedOutputs()
+	profiles := []Profile{
+		profileFromOutputs("laptop", outputs[:1]),
+		profileFromOutputs("docked", outputs),
+	}
+
+	match := matchProfile(profiles, outputs)
+	require.NotNil(t, match)
+	assert.Equal(t, "docked", match.Name)
+
+	match = matchProfile(profiles, outputs[:1])
+	require.NotNil(t, match)
+	assert.Equal(t, "laptop", match.Name)
+
+	assert.Nil(t, matchProfile(profiles, outputs[1:]))
+	assert.Nil(t, matchProfile(profiles, nil))
+}
+
+func TestProfileFromOutputs(t *testing.T) {
+	profile := profileFromOutputs("docked", dockedOutputs())
+
+	require.Len(t, profile.Outputs, 2)
+	assert.Nil(t, profile.Outputs[0].AdaptiveSync)
+	assert.Equal(t, &ProfileMode{Width: 2256, Height: 1504, Refresh: 60000}, profile.Outputs[0].Mode)
+
+	dell := profile.Outputs[1]
+	assert.Equal(t, "ABC123", dell.SerialNumber)
+	assert.Equal(t, int32(1504), dell.X)
+	require.NotNil(t, dell.AdaptiveSync)
+	assert.Equal(t, uint32(0), *dell.AdaptiveSync)
+}
+
+func TestProfileHeadConfigs(t *testing.T) {
+	outputs := dockedOutputs()
+	profile := Profile{
+		Name: "docked",
+		Outputs: []ProfileOutput{
+			{Make: "BOE", Model: "0x0ABC", Enabled: false},
+			{
+				Make: "Dell Inc.", Model: "DELL U2720Q", SerialNumber: "ABC123",
+				Enabled: true, Mode: &ProfileMode{Width: 3840, Height: 2160, Refresh: 30000},
+				Scale: 1.25, Transform: 1,
+			},
+		},
+	}
+
+	// The monitor moved to another connector since the profile was saved.
+	outputs[1].Name = "DP-5"
+
+	heads, err := profile.headConfigs(outputs)
+	require.NoError(t, err)
+	require.Len(t, heads, 2)
+
+	assert.Equal(t, HeadConfig{Name: "eDP-1"}, heads[0])
+
+	dell := heads[1]
+	assert.Equal(t, "DP-5", dell.Name)
+	require.NotNil(t, dell.ModeID)
+	assert.Equal(t, uint32(8), *dell.ModeID)
+	assert.Nil(t, dell.CustomMode)
+	assert.Equal(t, 1.25, *dell.Scale)
+	assert.Equal(t, int32(1), *dell.Transform)
+	assert.Equal(t, int32(0), dell.Position.X)
+}
+
+func TestProfileHeadConfigs_CustomModeAndMissing(t *testing.T) {
+	outputs := dockedOutputs()
+	profile := Profile{Outputs: []ProfileOutput{{
+		Make: "BOE", Model: "0x0ABC", Enabled: true,
+		Mode: &ProfileMode{Width: 1280, Height: 800, Refresh: 60000},
+	}}}
+
+	heads, err := profile.headConfigs(outputs)
+	require.NoError(t, err)
+	require.NotNil(t, heads[0].CustomMode)
+	assert.Equal(t, int32(1280), heads[0].CustomMode.Width)
+	assert.Nil(t, heads[0].Scale)
+
+	profile.Outputs[0].Model = "0xFFFF"
+	_, err = profile.headConfigs(outputs)
+	assert.ErrorContains(t, err, "output not connected: BOE 0xFFFF")
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "display-profiles.json")
+
+	profiles, err := loadProfiles(path)
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = loadProfiles(path)
+	assert.ErrorContains(t, err, "invalid profiles file")
+
+	_, err = loadProfiles("")
+	assert.ErrorIs(t, err, errNoProfilesPath)
+}
+
+func TestManager_SaveListDeleteProfile(t *testing.T) {
+	m := newProfileManager(t, dockedOutputs())
+
+	saved, err := m.SaveProfile("docked")
+	require.NoError(t, err)
+	assert.Len(t, saved.Outputs, 2)
+	assert.Equal(t, "docked", m.GetState().ActiveProfile)
+
+	// Saving again under the same name replaces the profile.
+	m.state.Outputs = dockedOutputs()[:1]
+	_, err = m.SaveProfile("docked")
+	require.NoError(t, err)
+	_, err = m.SaveProfile("laptop")
+	require.NoError(t, err)
+
+	list, err := m.ListProfiles()
+	require.NoError(t, err)
+	require.Len(t, list.Profiles, 2)
+	assert.Len(t, list.Profiles[0].Outputs, 1)
+	assert.Equal(t, "laptop", list.Active)
+
+	require.NoError(t, m.DeleteProfile("laptop"))
+	assert.Empty(t, m.GetState().ActiveProfile)
+	assert.ErrorContains(t, m.DeleteProfile("laptop"), "profile not found")
+
+	list, err = m.ListProfiles()
+	require.NoError(t, err)
+	require.Len(t, list.Profiles, 1)
+	assert.Equal(t, "docked", list.Profiles[0].Name)
+}
+
+func TestManager_SaveProfileNoOutputs(t *testing.T) {
+	m := newProfileManager(t, []Output{})
+
+	_, err := m.SaveProfile("empty")
+	assert.ErrorContains(t, err, "no outputs connected")
+}
+
+func TestManager_ApplyProfileErrors(t *testing.T) {
+	m := newProfileManager(t, dockedOutputs())
+
+	assert.ErrorContains(t, m.ApplyProfile("missing"), "profile not found")
+
+	_, err := m.SaveProfile("docked")
+	require.NoError(t, err)
+
+	m.state.Outputs = dockedOutputs()[:1]
+	assert.ErrorContains(t, m.ApplyProfile("docked"), "output not connected")
+}
+
+func TestManager_CheckConnectedClearsActiveProfile(t *testing.T) {
+	m := newProfileManager(t, dockedOutputs())
+
+	_, err := m.SaveProfile("docked")
+	require.NoError(t, err)
+
+	// Undocking to a set with no profile drops the active profile.
+	m.state.Outputs = dockedOutputs()[:1]
+	m.checkConnected()
+	assert.Empty(t, m.GetState().ActiveProfile)
+	assert.Equal(t, connectedKey(dockedOutputs()[:1]), m.connectedKey)
+
+	// An unchanged connected set is not re-evaluated.
+	m.setActiveProfile("manual")
+	m.checkConnected()
+	assert.Equal(t, "manual", m.GetState().ActiveProfile)
+}
+
+func TestManager_FailedManualApplyKeepsActiveProfile(t *testing.T) {
+	m := newProfileManager(t, dockedOutputs())
+
+	_, err := m.SaveProfile("docked")
+	require.NoError(t, err)
+
+	assert.Error(t, m.ApplyConfiguration([]HeadConfig{{Name: "eDP-1"}}, false))
+	assert.Equal(t, "docked", m.GetState().ActiveProfile)
+}
@@ -37,8 +37,9 @@ type Output struct {
 }
 
 type State struct {
-	Outputs []Output `json:"outputs"`
-	Serial  uint32   `json:"serial"`
+	Outputs       []Output `json:"outputs"`
+	Serial        uint32   `json:"serial"`
+	ActiveProfile string   `json:"activeProfile"`
 }
 
 type cmd struct {
@@ -66,8 +67,13 @@ type Manager struct {
 	notifierWg   sync.WaitGroup
 	lastNotified *State
 
-	stateMutex sync.RWMutex
-	state      *State
+	stateMutex    sync.RWMutex
+	state         *State
+	activeProfile string
+
+	profilesPath string
+	profileMutex sync.Mutex
+	connectedKey string
 
 	fatalError chan error
 }
@@ -150,7 +156,7 @@ func stateChanged(old, new *State) bool {
 	if old == nil || new == nil {
 		return true
 	}
-	if old.Serial != new.Serial {
+	if old.Serial != new.Serial || old.ActiveProfile != new.ActiveProfile {
 		return true
 	}
 	if len(old.Outputs) != len(new.Outputs) {
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+// LoadJSONState reads a JSON state file written by UpdateJSONState. A
+// missing file gives def; a file that doesn't parse is an error.
+func LoadJSONState[T any](path string, def T) (*T, error) {
+	state := def
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("corrupt state file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// UpdateJSONState runs fn on the loaded state under an exclusive lock and
+// saves the result atomically, so several processes can share the file. A
+// corrupt file is moved to path+".corrupt" and fn starts from def.
+func UpdateJSONState[T any](path string, def T, fn func(state *T) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	state, err := LoadJSONState(path, def)
+	if err != nil {
+		if _, statErr := os.Stat(path); statErr != nil {
+			return err
+		}
+		if renameErr := os.Rename(path, path+".corrupt"); renameErr != nil {
+			return err
+		}
+		log.Warnf("%v; moved to %s.corrupt", err, path)
+		fresh := def
+		state = &fresh
+	}
+
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
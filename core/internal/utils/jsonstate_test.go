@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	Limit int      `json:"limit"`
+	Items []string `json:"items"`
+}
+
+func TestJSONStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	def := testState{Limit: 10}
+
+	state, err := LoadJSONState(path, def)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*state, def) {
+		t.Errorf("expected defaults, got %+v", *state)
+	}
+
+	for _, item := range []string{"a", "b"} {
+		if err := UpdateJSONState(path, def, func(s *testState) error {
+			s.Items = append(s.Items, item)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	state, err = LoadJSONState(path, def)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := testState{Limit: 10, Items: []string{"a", "b"}}
+	if !reflect.DeepEqual(*state, expected) {
+		t.Errorf("expected %+v, got %+v", expected, *state)
+	}
+}
+
+func TestJSONStateCorruptFileKept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"items": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadJSONState(path, testState{}); err == nil || !strings.Contains(err.Error(), "corrupt state file") {
+		t.Errorf("expected corrupt state error, got %v", err)
+	}
+
+	err := UpdateJSONState(path, testState{Limit: 1}, func(s *testState) error {
+		if !reflect.DeepEqual(*s, testState{Limit: 1}) {
+			t.Errorf("expected fresh defaults, got %+v", *s)
+		}
+		s.Items = []string{"fresh"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	corrupt, err := os.ReadFile(path + ".corrupt")
+	if err != nil {
+		t.Fatalf("corrupt file not kept: %v", err)
+	}
+	if string(corrupt) != `{"items": [` {
+		t.Errorf("corrupt file changed: %s", corrupt)
+	}
+
+	state, err := LoadJSONState(path, testState{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(state.Items, []string{"fresh"}) {
+		t.Errorf("expected fresh items, got %v", state.Items)
+	}
+}
@@ -39,7 +39,16 @@ func XDGConfigHome() string {
 }
 
 func XDGPicturesDir() string {
-	if dir := os.Getenv("XDG_PICTURES_DIR"); dir != "" {
+	return xdgUserDir("XDG_PICTURES_DIR")
+}
+
+func XDGVideosDir() string {
+	return xdgUserDir("XDG_VIDEOS_DIR")
+}
+
+// xdgUserDir resolves a user-dirs.dirs entry, preferring the environment.
+func xdgUserDir(key string) string {
+	if dir := os.Getenv(key); dir != "" {
 		if expanded, err := ExpandPath(dir); err == nil {
 			return expanded
 		}
@@ -50,7 +59,7 @@ func XDGPicturesDir() string {
 		return ""
 	}
 
-	const prefix = "XDG_PICTURES_DIR="
+	prefix := key + "="
 	for line := range strings.SplitSeq(string(data), "\n") {
 		if len(line) == 0 || line[0] == '#' {
 			continue
@@ -0,0 +1,357 @@
+// Package imagecopy captures outputs and toplevels through the standard
+// ext-image-copy-capture-v1 protocol. Callers keep wlr-screencopy as the
+// fallback for compositors that don't advertise it.
+package imagecopy
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_foreign_toplevel_list"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_image_capture_source"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_image_copy_capture"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/shm"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+// preferredFormats lists the shm formats we ask for, best first. Anything
+// 32-bit can be used as is; the 24-bit ones get widened after the copy.
+var preferredFormats = []shm.PixelFormat{
+	shm.FormatXRGB8888,
+	shm.FormatARGB8888,
+	shm.FormatXBGR8888,
+	shm.FormatABGR8888,
+	shm.FormatRGB888,
+	shm.FormatBGR888,
+}
+
+// DoneFunc receives a finished capture. The buffer is upright, 32-bit and
+// owned by the caller; on error it is nil.
+type DoneFunc func(buf *shm.Buffer, err error)
+
+type Capturer struct {
+	ctx *client.Context
+
+	outputSources   *ext_image_capture_source.ExtOutputImageCaptureSourceManagerV1
+	toplevelSources *ext_image_capture_source.ExtForeignToplevelImageCaptureSourceManagerV1
+	manager         *ext_image_copy_capture.ExtImageCopyCaptureManagerV1
+
+	toplevelList *ext_foreign_toplevel_list.ExtForeignToplevelListV1
+	windowMgr    *wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1
+	toplevels    []*Toplevel
+	windows      []*window
+}
+
+func New(ctx *client.Context) *Capturer {
+	return &Capturer{ctx: ctx}
+}
+
+// HandleGlobal binds the capture related globals and reports whether e was
+// one of them, so callers can chain it in their registry handler.
+func (c *Capturer) HandleGlobal(registry *client.Registry, e client.RegistryGlobalEvent) bool {
+	switch e.Interface {
+	case ext_image_capture_source.ExtOutputImageCaptureSourceManagerV1InterfaceName:
+		mgr := ext_image_capture_source.NewExtOutputImageCaptureSourceManagerV1(c.ctx)
+		if err := registry.Bind(e.Name, e.Interface, 1, mgr); err == nil {
+			c.outputSources = mgr
+		}
+	case ext_image_capture_source.ExtForeignToplevelImageCaptureSourceManagerV1InterfaceName:
+		mgr := ext_image_capture_source.NewExtForeignToplevelImageCaptureSourceManagerV1(c.ctx)
+		if err := registry.Bind(e.Name, e.Interface, 1, mgr); err == nil {
+			c.toplevelSources = mgr
+		}
+	case ext_image_copy_capture.ExtImageCopyCaptureManagerV1InterfaceName:
+		mgr := ext_image_copy_capture.NewExtImageCopyCaptureManagerV1(c.ctx)
+		if err := registry.Bind(e.Name, e.Interface, 1, mgr); err == nil {
+			c.manager = mgr
+		}
+	case ext_foreign_toplevel_list.ExtForeignToplevelListV1InterfaceName:
+		list := ext_foreign_toplevel_list.NewExtForeignToplevelListV1(c.ctx)
+		if err := registry.Bind(e.Name, e.Interface, 1, list); err == nil {
+			c.toplevelList = list
+			list.SetToplevelHandler(func(e ext_foreign_toplevel_list.ExtForeignToplevelListV1ToplevelEvent) {
+				c.addToplevel(e.Toplevel)
+			})
+		}
+	case wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName:
+		mgr := wlr_foreign_toplevel.NewZwlrForeignToplevelManagerV1(c.ctx)
+		if err := registry.Bind(e.Name, e.Interface, min(e.Version, 3), mgr); err == nil {
+			c.windowMgr = mgr
+			mgr.SetToplevelHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1ToplevelEvent) {
+				c.addWindow(e.Toplevel)
+			})
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Available reports whether outputs can be captured with this protocol.
+func (c *Capturer) Available() bool {
+	return c.manager != nil && c.outputSources != nil
+}
+
+// CanCaptureToplevels reports whether individual windows can be captured.
+func (c *Capturer) CanCaptureToplevels() bool {
+	return c.manager != nil && c.toplevelSources != nil && c.toplevelList != nil
+}
+
+// CaptureOutputAsync starts a capture of output and calls done from the
+// dispatch loop once it finishes.
+func (c *Capturer) CaptureOutputAsync(wlShm *client.Shm, output *client.Output, cursor bool, done DoneFunc) {
+	if !c.Available() {
+		done(nil, fmt.Errorf("compositor does not support ext-image-copy-capture-v1"))
+		return
+	}
+	source, err := c.outputSources.CreateSource(output)
+	if err != nil {
+		done(nil, fmt.Errorf("create output source: %w", err))
+		return
+	}
+	c.captureSource(wlShm, source, cursor, done)
+}
+
+// CaptureOutput captures output, dispatching until the frame arrives.
+func (c *Capturer) CaptureOutput(wlShm *client.Shm, output *client.Output, cursor bool) (*shm.Buffer, error) {
+	return c.wait(func(done DoneFunc) {
+		c.CaptureOutputAsync(wlShm, output, cursor, done)
+	})
+}
+
+// CaptureToplevel captures the contents of a single window, regardless of
+// what is stacked on top of it.
+func (c *Capturer) CaptureToplevel(wlShm *client.Shm, t *Toplevel, cursor bool) (*shm.Buffer, error) {
+	if !c.CanCaptureToplevels() {
+		return nil, fmt.Errorf("compositor does not support toplevel capture")
+	}
+	if t.closed {
+		return nil, fmt.Errorf("toplevel %q is closed", t.Title)
+	}
+	source, err := c.toplevelSources.CreateSource(t.handle)
+	if err != nil {
+		return nil, fmt.Errorf("create toplevel source: %w", err)
+	}
+	return c.wait(func(done DoneFunc) {
+		c.captureSource(wlShm, source, cursor, done)
+	})
+}
+
+func (c *Capturer) wait(start func(DoneFunc)) (*shm.Buffer, error) {
+	var (
+		result   *shm.Buffer
+		err      error
+		finished bool
+	)
+	start(func(buf *shm.Buffer, e error) {
+		result, err, finished = buf, e, true
+	})
+	for !finished {
+		if dispatchErr := c.ctx.Dispatch(); dispatchErr != nil {
+			return nil, fmt.Errorf("dispatch: %w", dispatchErr)
+		}
+	}
+	return result, err
+}
+
+// captureSource runs one session for a single frame. The session
+// advertises its buffer constraints first; the frame is only created once
+// those are complete.
+func (c *Capturer) captureSource(wlShm *client.Shm, source *ext_image_capture_source.ExtImageCaptureSourceV1, cursor bool, done DoneFunc) {
+	var options uint32
+	if cursor {
+		options = uint32(ext_image_copy_capture.ExtImageCopyCaptureManagerV1OptionsPaintCursors)
+	}
+
+	session, err := c.manager.CreateSession(source, options)
+	if err != nil {
+		source.Destroy()
+		done(nil, fmt.Errorf("create capture session: %w", err))
+		return
+	}
+
+	var (
+		width, height uint32
+		formats       []shm.PixelFormat
+		transform     int32
+		buf           *shm.Buffer
+		pool          *client.ShmPool
+		wlBuf         *client.Buffer
+		frame         *ext_image_copy_capture.ExtImageCopyCaptureFrameV1
+		finished      bool
+	)
+
+	finish := func(err error) {
+		if finished {
+			return
+		}
+		finished = true
+		if frame != nil {
+			frame.Destroy()
+		}
+		if wlBuf != nil {
+			wlBuf.Destroy()
+		}
+		if pool != nil {
+			pool.Destroy()
+		}
+		session.Destroy()
+		source.Destroy()
+
+		if err != nil {
+			if buf != nil {
+				buf.Close()
+			}
+			done(nil, err)
+			return
+		}
+
+		done(normalize(buf, transform))
+	}
+
+	session.SetBufferSizeHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureSessionV1BufferSizeEvent) {
+		width, height = e.Width, e.Height
+	})
+	session.SetShmFormatHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureSessionV1ShmFormatEvent) {
+		formats = append(formats, shm.PixelFormat(e.Format))
+	})
+	session.SetStoppedHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureSessionV1StoppedEvent) {
+		finish(fmt.Errorf("capture session stopped"))
+	})
+	session.SetDoneHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureSessionV1DoneEvent) {
+		// Constraints are resent whenever they change; one frame is all we need.
+		if frame != nil || finished {
+			return
+		}
+
+		format, ok := pickFormat(formats)
+		switch {
+		case !ok:
+			finish(fmt.Errorf("no supported shm format offered"))
+			return
+		case width == 0 || height == 0:
+			finish(fmt.Errorf("invalid buffer size %dx%d", width, height))
+			return
+		}
+
+		var err error
+		buf, err = shm.CreateBuffer(int(width), int(height), int(width)*format.BytesPerPixel())
+		if err != nil {
+			finish(fmt.Errorf("create buffer: %w", err))
+			return
+		}
+		buf.Format = format
+
+		pool, err = wlShm.CreatePool(buf.Fd(), int32(buf.Size()))
+		if err != nil {
+			finish(fmt.Errorf("create pool: %w", err))
+			return
+		}
+		wlBuf, err = pool.CreateBuffer(0, int32(buf.Width), int32(buf.Height), int32(buf.Stride), uint32(format))
+		if err != nil {
+			finish(fmt.Errorf("create wl_buffer: %w", err))
+			return
+		}
+
+		frame, err = session.CreateFrame()
+		if err != nil {
+			finish(fmt.Errorf("create frame: %w", err))
+			return
+		}
+		frame.SetTransformHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureFrameV1TransformEvent) {
+			transform = int32(e.Transform)
+		})
+		frame.SetReadyHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureFrameV1ReadyEvent) {
+			finish(nil)
+		})
+		frame.SetFailedHandler(func(e ext_image_copy_capture.ExtImageCopyCaptureFrameV1FailedEvent) {
+			reason := ext_image_copy_capture.ExtImageCopyCaptureFrameV1FailureReason(e.Reason)
+			finish(fmt.Errorf("frame capture failed: %s", reason.Name()))
+		})
+
+		if err := frame.AttachBuffer(wlBuf); err != nil {
+			finish(fmt.Errorf("attach buffer: %w", err))
+			return
+		}
+		if err := frame.DamageBuffer(0, 0, int32(width), int32(height)); err != nil {
+			finish(fmt.Errorf("damage buffer: %w", err))
+			return
+		}
+		if err := frame.Capture(); err != nil {
+			finish(fmt.Errorf("capture: %w", err))
+		}
+	})
+}
+
+func pickFormat(offered []shm.PixelFormat) (shm.PixelFormat, bool) {
+	for _, f := range preferredFormats {
+		if slices.Contains(offered, f) {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// normalize widens 24-bit frames and undoes the transform the compositor
+// reported, so the result matches what is seen on screen. It takes ownership
+// of buf and closes it on failure.
+func normalize(buf *shm.Buffer, transform int32) (*shm.Buffer, error) {
+	if buf.Format.Is24Bit() {
+		converted, format, err := buf.ConvertTo32Bit(buf.Format)
+		if err != nil {
+			buf.Close()
+			return nil, fmt.Errorf("convert 24-bit to 32-bit: %w", err)
+		}
+		if converted != buf {
+			buf.Close()
+			buf = converted
+		}
+		buf.Format = format
+	}
+
+	if transform == shm.TransformNormal {
+		return buf, nil
+	}
+
+	transformed, err := buf.ApplyTransform(shm.InverseTransform(transform))
+	if err != nil {
+		buf.Close()
+		return nil, fmt.Errorf("apply transform: %w", err)
+	}
+	if transformed != buf {
+		buf.Close()
+	}
+	return transformed, nil
+}
+
+func (c *Capturer) Destroy() {
+	for _, t := range c.toplevels {
+		t.handle.Destroy()
+	}
+	for _, w := range c.windows {
+		w.handle.Destroy()
+	}
+	c.toplevels, c.windows = nil, nil
+
+	if c.toplevelList != nil {
+		c.toplevelList.Stop()
+		c.toplevelList = nil
+	}
+	if c.windowMgr != nil {
+		c.windowMgr.Stop()
+		c.windowMgr = nil
+	}
+	if c.manager != nil {
+		c.manager.Destroy()
+		c.manager = nil
+	}
+	if c.outputSources != nil {
+		c.outputSources.Destroy()
+		c.outputSources = nil
+	}
+	if c.toplevelSources != nil {
+		c.toplevelSources.Destroy()
+		c.toplevelSources = nil
+	}
+}
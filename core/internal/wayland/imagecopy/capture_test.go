@@ -0,0 +1,45 @@
+package imagecopy
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/shm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickFormat(t *testing.T) {
+	f, ok := pickFormat([]shm.PixelFormat{shm.FormatBGR888, shm.FormatABGR8888})
+	assert.True(t, ok)
+	assert.Equal(t, shm.FormatABGR8888, f)
+
+	f, ok = pickFormat([]shm.PixelFormat{shm.FormatRGB888})
+	assert.True(t, ok)
+	assert.Equal(t, shm.FormatRGB888, f)
+
+	_, ok = pickFormat([]shm.PixelFormat{0x30335241}) // AR30
+	assert.False(t, ok)
+}
+
+func TestHasState(t *testing.T) {
+	states := binary.LittleEndian.AppendUint32(nil, uint32(wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateMaximized))
+	assert.False(t, hasState(states, wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated))
+
+	states = binary.LittleEndian.AppendUint32(states, uint32(wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated))
+	assert.True(t, hasState(states, wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated))
+	assert.False(t, hasState(states[:3], wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateMaximized))
+}
+
+func TestMatchToplevel(t *testing.T) {
+	term := &Toplevel{AppID: "foot", Title: "~"}
+	editor := &Toplevel{AppID: "foot", Title: "nvim"}
+	browser := &Toplevel{AppID: "firefox", Title: "nvim"}
+	toplevels := []*Toplevel{term, editor, browser}
+
+	assert.Same(t, editor, matchToplevel(toplevels, "foot", "nvim"))
+	assert.Nil(t, matchToplevel(toplevels, "foot", "htop"))
+
+	twin := &Toplevel{AppID: "foot", Title: "~"}
+	assert.Nil(t, matchToplevel(append(toplevels, twin), "foot", "~"), "identical windows are ambiguous")
+}
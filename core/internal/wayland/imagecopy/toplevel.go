@@ -0,0 +1,112 @@
+package imagecopy
+
+import (
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_foreign_toplevel_list"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+// Toplevel is a window announced by ext-foreign-toplevel-list, which is
+// what toplevel capture sources are created from.
+type Toplevel struct {
+	handle *ext_foreign_toplevel_list.ExtForeignToplevelHandleV1
+	closed bool
+
+	Identifier string
+	Title      string
+	AppID      string
+}
+
+// window tracks wlr-foreign-toplevel-management state. The ext list has no
+// notion of focus, so this is only used to find the active window.
+type window struct {
+	handle    *wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1
+	title     string
+	appID     string
+	activated bool
+	closed    bool
+}
+
+func (c *Capturer) addToplevel(handle *ext_foreign_toplevel_list.ExtForeignToplevelHandleV1) {
+	t := &Toplevel{handle: handle}
+	c.toplevels = append(c.toplevels, t)
+
+	handle.SetIdentifierHandler(func(e ext_foreign_toplevel_list.ExtForeignToplevelHandleV1IdentifierEvent) {
+		t.Identifier = e.Identifier
+	})
+	handle.SetTitleHandler(func(e ext_foreign_toplevel_list.ExtForeignToplevelHandleV1TitleEvent) {
+		t.Title = e.Title
+	})
+	handle.SetAppIdHandler(func(e ext_foreign_toplevel_list.ExtForeignToplevelHandleV1AppIdEvent) {
+		t.AppID = e.AppId
+	})
+	handle.SetClosedHandler(func(e ext_foreign_toplevel_list.ExtForeignToplevelHandleV1ClosedEvent) {
+		t.closed = true
+	})
+}
+
+func (c *Capturer) addWindow(handle *wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1) {
+	w := &window{handle: handle}
+	c.windows = append(c.windows, w)
+
+	handle.SetTitleHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1TitleEvent) {
+		w.title = e.Title
+	})
+	handle.SetAppIdHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1AppIdEvent) {
+		w.appID = e.AppId
+	})
+	handle.SetStateHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateEvent) {
+		w.activated = hasState(e.State, wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated)
+	})
+	handle.SetClosedHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1ClosedEvent) {
+		w.closed = true
+	})
+}
+
+func hasState(states []byte, want wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1State) bool {
+	for i := 0; i+4 <= len(states); i += 4 {
+		if client.Uint32(states[i:i+4]) == uint32(want) {
+			return true
+		}
+	}
+	return false
+}
+
+// Toplevels returns the windows that are currently open.
+func (c *Capturer) Toplevels() []*Toplevel {
+	open := make([]*Toplevel, 0, len(c.toplevels))
+	for _, t := range c.toplevels {
+		if !t.closed {
+			open = append(open, t)
+		}
+	}
+	return open
+}
+
+// ActiveToplevel returns the focused window, or nil when the compositor
+// doesn't report focus or the match is ambiguous.
+func (c *Capturer) ActiveToplevel() *Toplevel {
+	for _, w := range c.windows {
+		if w.activated && !w.closed {
+			return matchToplevel(c.Toplevels(), w.appID, w.title)
+		}
+	}
+	return nil
+}
+
+// matchToplevel pairs a window from the wlr list with its ext handle. Both
+// lists are fed from the same compositor state, so app_id and title agree;
+// two identical windows can't be told apart and yield nil.
+func matchToplevel(toplevels []*Toplevel, appID, title string) *Toplevel {
+	var found *Toplevel
+	for _, t := range toplevels {
+		if t.AppID != appID || t.Title != title {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = t
+	}
+	return found
+}